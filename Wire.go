@@ -27,6 +27,7 @@ import (
 	appStoreDeployment "github.com/devtron-labs/devtron/api/appStore/deployment"
 	appStoreDiscover "github.com/devtron-labs/devtron/api/appStore/discover"
 	appStoreValues "github.com/devtron-labs/devtron/api/appStore/values"
+	"github.com/devtron-labs/devtron/api/auditLog"
 	chartRepo "github.com/devtron-labs/devtron/api/chartRepo"
 	"github.com/devtron-labs/devtron/api/cluster"
 	"github.com/devtron-labs/devtron/api/connector"
@@ -69,6 +70,7 @@ import (
 	security2 "github.com/devtron-labs/devtron/internal/sql/repository/security"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/internal/util/ArgoUtil"
+	apiToken2 "github.com/devtron-labs/devtron/pkg/apiToken"
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/appClone"
 	"github.com/devtron-labs/devtron/pkg/appClone/batch"
@@ -78,6 +80,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/appStore/deployment/service"
 	appStoreDeploymentGitopsTool "github.com/devtron-labs/devtron/pkg/appStore/deployment/tool/gitops"
 	"github.com/devtron-labs/devtron/pkg/appWorkflow"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion"
 	"github.com/devtron-labs/devtron/pkg/attributes"
 	"github.com/devtron-labs/devtron/pkg/bulkAction"
 	"github.com/devtron-labs/devtron/pkg/chart"
@@ -129,6 +132,7 @@ func InitializeApp() (*App, error) {
 		server.ServerWireSet,
 		module.ModuleWireSet,
 		apiToken.ApiTokenWireSet,
+		auditLog.AuditLogWireSet,
 		webhookHelm.WebhookHelmWireSet,
 		// -------wireset end ----------
 		gitSensor.GetGitSensorConfig,
@@ -173,6 +177,8 @@ func InitializeApp() (*App, error) {
 
 		bulkUpdate.NewBulkUpdateRepository,
 		wire.Bind(new(bulkUpdate.BulkUpdateRepository), new(*bulkUpdate.BulkUpdateRepositoryImpl)),
+		bulkUpdate.NewBulkOperationJobRepositoryImpl,
+		wire.Bind(new(bulkUpdate.BulkOperationJobRepository), new(*bulkUpdate.BulkOperationJobRepositoryImpl)),
 
 		chartConfig.NewEnvConfigOverrideRepository,
 		wire.Bind(new(chartConfig.EnvConfigOverrideRepository), new(*chartConfig.EnvConfigOverrideRepositoryImpl)),
@@ -755,6 +761,152 @@ func InitializeApp() (*App, error) {
 		wire.Bind(new(pipeline.GlobalCMCSService), new(*pipeline.GlobalCMCSServiceImpl)),
 		repository.NewGlobalCMCSRepositoryImpl,
 		wire.Bind(new(repository.GlobalCMCSRepository), new(*repository.GlobalCMCSRepositoryImpl)),
+
+		router.NewDeploymentApprovalRouterImpl,
+		wire.Bind(new(router.DeploymentApprovalRouter), new(*router.DeploymentApprovalRouterImpl)),
+		restHandler.NewDeploymentApprovalRestHandlerImpl,
+		wire.Bind(new(restHandler.DeploymentApprovalRestHandler), new(*restHandler.DeploymentApprovalRestHandlerImpl)),
+		pipeline.NewDeploymentApprovalServiceImpl,
+		wire.Bind(new(pipeline.DeploymentApprovalService), new(*pipeline.DeploymentApprovalServiceImpl)),
+		pipelineConfig.NewDeploymentApprovalRepositoryImpl,
+		wire.Bind(new(pipelineConfig.DeploymentApprovalRepository), new(*pipelineConfig.DeploymentApprovalRepositoryImpl)),
+
+		router.NewDeploymentWindowRouterImpl,
+		wire.Bind(new(router.DeploymentWindowRouter), new(*router.DeploymentWindowRouterImpl)),
+		restHandler.NewDeploymentWindowRestHandlerImpl,
+		wire.Bind(new(restHandler.DeploymentWindowRestHandler), new(*restHandler.DeploymentWindowRestHandlerImpl)),
+		pipeline.NewDeploymentWindowServiceImpl,
+		wire.Bind(new(pipeline.DeploymentWindowService), new(*pipeline.DeploymentWindowServiceImpl)),
+		pipelineConfig.NewDeploymentWindowRepositoryImpl,
+		wire.Bind(new(pipelineConfig.DeploymentWindowRepository), new(*pipelineConfig.DeploymentWindowRepositoryImpl)),
+
+		router.NewAutoRollbackRouterImpl,
+		wire.Bind(new(router.AutoRollbackRouter), new(*router.AutoRollbackRouterImpl)),
+		restHandler.NewAutoRollbackRestHandlerImpl,
+		wire.Bind(new(restHandler.AutoRollbackRestHandler), new(*restHandler.AutoRollbackRestHandlerImpl)),
+		pipeline.NewAutoRollbackServiceImpl,
+		wire.Bind(new(pipeline.AutoRollbackService), new(*pipeline.AutoRollbackServiceImpl)),
+		pipelineConfig.NewAutoRollbackRepositoryImpl,
+		wire.Bind(new(pipelineConfig.AutoRollbackRepository), new(*pipelineConfig.AutoRollbackRepositoryImpl)),
+
+		router.NewArtifactPromotionRouterImpl,
+		wire.Bind(new(router.ArtifactPromotionRouter), new(*router.ArtifactPromotionRouterImpl)),
+		restHandler.NewArtifactPromotionRestHandlerImpl,
+		wire.Bind(new(restHandler.ArtifactPromotionRestHandler), new(*restHandler.ArtifactPromotionRestHandlerImpl)),
+		artifactPromotion.NewArtifactPromotionServiceImpl,
+		wire.Bind(new(artifactPromotion.ArtifactPromotionService), new(*artifactPromotion.ArtifactPromotionServiceImpl)),
+		pipelineConfig.NewArtifactPromotionRepositoryImpl,
+		wire.Bind(new(pipelineConfig.ArtifactPromotionRepository), new(*pipelineConfig.ArtifactPromotionRepositoryImpl)),
+		pipeline.NewArtifactPromotionPolicyServiceImpl,
+		wire.Bind(new(pipeline.ArtifactPromotionPolicyService), new(*pipeline.ArtifactPromotionPolicyServiceImpl)),
+
+		cron.GetCiTriggerCronConfig,
+		cron.NewCiTriggerCronImpl,
+		wire.Bind(new(cron.CiTriggerCron), new(*cron.CiTriggerCronImpl)),
+		pipeline.NewCiScheduleServiceImpl,
+		wire.Bind(new(pipeline.CiScheduleService), new(*pipeline.CiScheduleServiceImpl)),
+		pipelineConfig.NewCiPipelineScheduleRepositoryImpl,
+		wire.Bind(new(pipelineConfig.CiPipelineScheduleRepository), new(*pipelineConfig.CiPipelineScheduleRepositoryImpl)),
+
+		router.NewHibernationScheduleRouterImpl,
+		wire.Bind(new(router.HibernationScheduleRouter), new(*router.HibernationScheduleRouterImpl)),
+		restHandler.NewHibernationScheduleRestHandlerImpl,
+		wire.Bind(new(restHandler.HibernationScheduleRestHandler), new(*restHandler.HibernationScheduleRestHandlerImpl)),
+		bulkAction.NewHibernationScheduleServiceImpl,
+		wire.Bind(new(bulkAction.HibernationScheduleService), new(*bulkAction.HibernationScheduleServiceImpl)),
+		bulkUpdate.NewHibernationScheduleRepositoryImpl,
+		wire.Bind(new(bulkUpdate.HibernationScheduleRepository), new(*bulkUpdate.HibernationScheduleRepositoryImpl)),
+		cron.GetHibernationScheduleCronConfig,
+		cron.NewHibernationScheduleCronImpl,
+		wire.Bind(new(cron.HibernationScheduleCron), new(*cron.HibernationScheduleCronImpl)),
+		cron.GetBulkOperationJobCronConfig,
+		cron.NewBulkOperationJobCronImpl,
+		wire.Bind(new(cron.BulkOperationJobCron), new(*cron.BulkOperationJobCronImpl)),
+
+		router.NewConfigDriftRouterImpl,
+		wire.Bind(new(router.ConfigDriftRouter), new(*router.ConfigDriftRouterImpl)),
+		restHandler.NewConfigDriftRestHandlerImpl,
+		wire.Bind(new(restHandler.ConfigDriftRestHandler), new(*restHandler.ConfigDriftRestHandlerImpl)),
+		pipeline.NewConfigDriftServiceImpl,
+		wire.Bind(new(pipeline.ConfigDriftService), new(*pipeline.ConfigDriftServiceImpl)),
+		pipelineConfig.NewConfigDriftRepositoryImpl,
+		wire.Bind(new(pipelineConfig.ConfigDriftRepository), new(*pipelineConfig.ConfigDriftRepositoryImpl)),
+		cron.GetConfigDriftCronConfig,
+		cron.NewConfigDriftCronImpl,
+		wire.Bind(new(cron.ConfigDriftCron), new(*cron.ConfigDriftCronImpl)),
+
+		history3.NewConfigComparisonServiceImpl,
+		wire.Bind(new(history3.ConfigComparisonService), new(*history3.ConfigComparisonServiceImpl)),
+
+		pipeline.NewConfigSnapshotRestoreServiceImpl,
+		wire.Bind(new(pipeline.ConfigSnapshotRestoreService), new(*pipeline.ConfigSnapshotRestoreServiceImpl)),
+
+		pipelineConfig.NewGitOpsPullRequestRepositoryImpl,
+		wire.Bind(new(pipelineConfig.GitOpsPullRequestRepository), new(*pipelineConfig.GitOpsPullRequestRepositoryImpl)),
+		pipeline.NewGitOpsPullRequestServiceImpl,
+		wire.Bind(new(pipeline.GitOpsPullRequestService), new(*pipeline.GitOpsPullRequestServiceImpl)),
+		cron.GetGitOpsPullRequestCronConfig,
+		cron.NewGitOpsPullRequestCronImpl,
+		wire.Bind(new(cron.GitOpsPullRequestCron), new(*cron.GitOpsPullRequestCronImpl)),
+
+		pipelineConfig.NewCdFanOutRepositoryImpl,
+		wire.Bind(new(pipelineConfig.CdFanOutRepository), new(*pipelineConfig.CdFanOutRepositoryImpl)),
+		pipeline.NewCdFanOutServiceImpl,
+		wire.Bind(new(pipeline.CdFanOutService), new(*pipeline.CdFanOutServiceImpl)),
+		router.NewCdFanOutRouterImpl,
+		wire.Bind(new(router.CdFanOutRouter), new(*router.CdFanOutRouterImpl)),
+		restHandler.NewCdFanOutRestHandlerImpl,
+		wire.Bind(new(restHandler.CdFanOutRestHandler), new(*restHandler.CdFanOutRestHandlerImpl)),
+		cron.GetCdFanOutCronConfig,
+		cron.NewCdFanOutCronImpl,
+		wire.Bind(new(cron.CdFanOutCron), new(*cron.CdFanOutCronImpl)),
+		pipelineConfig.NewCanaryAnalysisRepositoryImpl,
+		wire.Bind(new(pipelineConfig.CanaryAnalysisRepository), new(*pipelineConfig.CanaryAnalysisRepositoryImpl)),
+		pipeline.NewCanaryAnalysisServiceImpl,
+		wire.Bind(new(pipeline.CanaryAnalysisService), new(*pipeline.CanaryAnalysisServiceImpl)),
+		router.NewCanaryAnalysisRouterImpl,
+		wire.Bind(new(router.CanaryAnalysisRouter), new(*router.CanaryAnalysisRouterImpl)),
+		restHandler.NewCanaryAnalysisRestHandlerImpl,
+		wire.Bind(new(restHandler.CanaryAnalysisRestHandler), new(*restHandler.CanaryAnalysisRestHandlerImpl)),
+		cron.GetCanaryAnalysisCronConfig,
+		cron.NewCanaryAnalysisCronImpl,
+		wire.Bind(new(cron.CanaryAnalysisCron), new(*cron.CanaryAnalysisCronImpl)),
+		security2.NewImageSignatureRepositoryImpl,
+		wire.Bind(new(security2.ImageSignatureRepository), new(*security2.ImageSignatureRepositoryImpl)),
+		pipeline.NewImageSignatureServiceImpl,
+		wire.Bind(new(pipeline.ImageSignatureService), new(*pipeline.ImageSignatureServiceImpl)),
+		router.NewImageSignatureRouterImpl,
+		wire.Bind(new(router.ImageSignatureRouter), new(*router.ImageSignatureRouterImpl)),
+		restHandler.NewImageSignatureRestHandlerImpl,
+		wire.Bind(new(restHandler.ImageSignatureRestHandler), new(*restHandler.ImageSignatureRestHandlerImpl)),
+		security2.NewSbomRepositoryImpl,
+		wire.Bind(new(security2.SbomRepository), new(*security2.SbomRepositoryImpl)),
+		pipeline.NewSbomServiceImpl,
+		wire.Bind(new(pipeline.SbomService), new(*pipeline.SbomServiceImpl)),
+		router.NewSbomRouterImpl,
+		wire.Bind(new(router.SbomRouter), new(*router.SbomRouterImpl)),
+		restHandler.NewSbomRestHandlerImpl,
+		wire.Bind(new(restHandler.SbomRestHandler), new(*restHandler.SbomRestHandlerImpl)),
+		security2.NewCveExceptionRepositoryImpl,
+		wire.Bind(new(security2.CveExceptionRepository), new(*security2.CveExceptionRepositoryImpl)),
+		security.GetCveExceptionConfig,
+		security.NewCveExceptionServiceImpl,
+		wire.Bind(new(security.CveExceptionService), new(*security.CveExceptionServiceImpl)),
+		router.NewCveExceptionRouterImpl,
+		wire.Bind(new(router.CveExceptionRouter), new(*router.CveExceptionRouterImpl)),
+		restHandler.NewCveExceptionRestHandlerImpl,
+		wire.Bind(new(restHandler.CveExceptionRestHandler), new(*restHandler.CveExceptionRestHandlerImpl)),
+		cron.GetCveExceptionCronConfig,
+		cron.NewCveExceptionCronImpl,
+		wire.Bind(new(cron.CveExceptionCron), new(*cron.CveExceptionCronImpl)),
+		cron.GetUserRoleGrantCronConfig,
+		cron.NewUserRoleGrantCronImpl,
+		wire.Bind(new(cron.UserRoleGrantCron), new(*cron.UserRoleGrantCronImpl)),
+		apiToken2.NewApiTokenNotificationServiceImpl,
+		wire.Bind(new(apiToken2.ApiTokenNotificationService), new(*apiToken2.ApiTokenNotificationServiceImpl)),
+		cron.GetApiTokenExpiryCronConfig,
+		cron.NewApiTokenExpiryCronImpl,
+		wire.Bind(new(cron.ApiTokenExpiryCron), new(*cron.ApiTokenExpiryCronImpl)),
 	)
 	return &App{}, nil
 }
@@ -0,0 +1,70 @@
+package apiToken
+
+import (
+	"net/http"
+
+	"github.com/devtron-labs/authenticator/middleware"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/constants"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/apiToken"
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+)
+
+type ApiTokenMiddleware interface {
+	// RejectInactiveTokens rejects every request made with an api token which is deleted or rotated past its grace period
+	RejectInactiveTokens(next http.Handler) http.Handler
+}
+
+type ApiTokenMiddlewareImpl struct {
+	logger          *zap.SugaredLogger
+	apiTokenService apiToken.ApiTokenService
+}
+
+func NewApiTokenMiddlewareImpl(logger *zap.SugaredLogger, apiTokenService apiToken.ApiTokenService) *ApiTokenMiddlewareImpl {
+	return &ApiTokenMiddlewareImpl{
+		logger:          logger,
+		apiTokenService: apiTokenService,
+	}
+}
+
+func (impl ApiTokenMiddlewareImpl) RejectInactiveTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("token")
+		if !isApiToken(token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		isActive, err := impl.apiTokenService.IsActiveApiToken(token)
+		if err != nil {
+			impl.logger.Errorw("error in checking if api token is active", "err", err)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		if !isActive {
+			err = &util.ApiError{
+				HttpStatusCode:  http.StatusUnauthorized,
+				Code:            constants.UserApiTokenNotActive,
+				InternalMessage: "api token is rotated or deleted",
+				UserMessage:     "api token is no longer active, please use the latest token",
+			}
+			common.WriteJsonResp(w, err, nil, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isApiToken checks the issuer of the token without verifying it, the signature is verified by the authorizer
+func isApiToken(token string) bool {
+	if len(token) == 0 {
+		return false
+	}
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	if err != nil {
+		return false
+	}
+	return claims["iss"] == middleware.ApiTokenClaimIssuer
+}
@@ -38,6 +38,7 @@ type ApiTokenRestHandler interface {
 	CreateApiToken(w http.ResponseWriter, r *http.Request)
 	UpdateApiToken(w http.ResponseWriter, r *http.Request)
 	DeleteApiToken(w http.ResponseWriter, r *http.Request)
+	RotateApiToken(w http.ResponseWriter, r *http.Request)
 }
 
 type ApiTokenRestHandlerImpl struct {
@@ -120,7 +121,7 @@ func (impl ApiTokenRestHandlerImpl) CreateApiToken(w http.ResponseWriter, r *htt
 	}
 
 	// service call
-	res, err := impl.apiTokenService.CreateApiToken(request, userId, impl.checkManagerAuth)
+	res, err := impl.apiTokenService.CreateApiToken(request, userId, token, impl.checkManagerAuth)
 	if err != nil {
 		impl.logger.Errorw("service err, CreateApiToken", "err", err, "payload", request)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -170,7 +171,7 @@ func (impl ApiTokenRestHandlerImpl) UpdateApiToken(w http.ResponseWriter, r *htt
 		return
 	}
 
-	res, err := impl.apiTokenService.UpdateApiToken(apiTokenId, request, userId)
+	res, err := impl.apiTokenService.UpdateApiToken(apiTokenId, request, userId, token, impl.checkManagerAuth)
 	if err != nil {
 		impl.logger.Errorw("service err, UpdateApiToken", "err", err, "apiTokenId", apiTokenId, "request", request)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -211,6 +212,49 @@ func (impl ApiTokenRestHandlerImpl) DeleteApiToken(w http.ResponseWriter, r *htt
 	common.WriteJsonResp(w, err, res, http.StatusOK)
 }
 
+func (impl ApiTokenRestHandlerImpl) RotateApiToken(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+
+	// handle super-admin RBAC
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+
+	// get api-token Id
+	vars := mux.Vars(r)
+	apiTokenId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		impl.logger.Errorw("request err in getting apiTokenId in RotateApiToken", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// decode request, body is optional and server defaults are used for missing fields
+	request := &openapi.RotateApiTokenRequest{}
+	if r.ContentLength > 0 {
+		err = json.NewDecoder(r.Body).Decode(request)
+		if err != nil {
+			impl.logger.Errorw("err in decoding request, RotateApiToken", "err", err)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := impl.apiTokenService.RotateApiToken(apiTokenId, request, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, RotateApiToken", "err", err, "apiTokenId", apiTokenId, "request", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, err, res, http.StatusOK)
+}
+
 func (handler ApiTokenRestHandlerImpl) checkManagerAuth(token string, object string) bool {
 	if ok := handler.enforcer.Enforce(token, casbin.ResourceUser, casbin.ActionUpdate, strings.ToLower(object)); !ok {
 		return false
@@ -8,8 +8,11 @@ import (
 var ApiTokenWireSet = wire.NewSet(
 	apiToken.NewApiTokenRepositoryImpl,
 	wire.Bind(new(apiToken.ApiTokenRepository), new(*apiToken.ApiTokenRepositoryImpl)),
+	apiToken.GetApiTokenConfig,
 	apiToken.NewApiTokenServiceImpl,
 	wire.Bind(new(apiToken.ApiTokenService), new(*apiToken.ApiTokenServiceImpl)),
+	NewApiTokenMiddlewareImpl,
+	wire.Bind(new(ApiTokenMiddleware), new(*ApiTokenMiddlewareImpl)),
 	NewApiTokenRestHandlerImpl,
 	wire.Bind(new(ApiTokenRestHandler), new(*ApiTokenRestHandlerImpl)),
 	NewApiTokenRouterImpl,
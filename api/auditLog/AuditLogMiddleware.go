@@ -0,0 +1,137 @@
+package auditLog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/auditLog"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/util"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// maxCapturedBodySize bounds the request body held in memory for the audit log, larger bodies are served as is but
+// not captured as redaction needs the complete json
+const maxCapturedBodySize = 1 << 20
+
+// resourceIdPathVars are the path variables checked in order for the id of the mutated resource
+var resourceIdPathVars = []string{"appId", "app-id", "pipelineId", "cd_pipeline_id", "clusterId", "id", "envId", "environmentId"}
+
+type AuditLogMiddleware interface {
+	// Audit records every mutating request served by the router along with its caller and response status
+	Audit(next http.Handler) http.Handler
+}
+
+type AuditLogMiddlewareImpl struct {
+	logger          *zap.SugaredLogger
+	userService     user.UserService
+	auditLogService auditLog.AuditLogService
+}
+
+func NewAuditLogMiddlewareImpl(logger *zap.SugaredLogger,
+	userService user.UserService,
+	auditLogService auditLog.AuditLogService) *AuditLogMiddlewareImpl {
+	return &AuditLogMiddlewareImpl{
+		logger:          logger,
+		userService:     userService,
+		auditLogService: auditLogService,
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (impl AuditLogMiddlewareImpl) Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !impl.auditLogService.IsEnabled() || !isMutatingMethod(r.Method) || !impl.auditLogService.IsAuditedPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		requestBody := impl.readRequestBody(r)
+		// token is read before serving as handlers are free to modify the request
+		token := r.Header.Get("token")
+		model := &auditLog.AuditLog{
+			ResourceType: auditLog.GetResourceTypeForPath(r.URL.Path),
+			ResourceId:   getResourceId(mux.Vars(r)),
+			Action:       r.Method,
+			Method:       r.Method,
+			Path:         r.URL.Path,
+			ClientIp:     util.GetClientIP(r),
+			CreatedOn:    time.Now(),
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		model.StatusCode = recorder.status
+		model.DurationMs = time.Since(model.CreatedOn).Milliseconds()
+		go impl.save(model, token, requestBody)
+	})
+}
+
+func (impl AuditLogMiddlewareImpl) save(model *auditLog.AuditLog, token string, requestBody []byte) {
+	var emailId string
+	if len(token) > 0 {
+		// requests with invalid tokens are still recorded, without the caller
+		emailId, _ = impl.userService.GetEmailFromToken(token)
+	}
+	impl.auditLogService.SaveApiAuditLog(model, emailId, requestBody)
+}
+
+// readRequestBody reads the body for the audit log and restores it for the handler, file uploads and bodies larger
+// than maxCapturedBodySize are not captured
+func (impl AuditLogMiddlewareImpl) readRequestBody(r *http.Request) []byte {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	contentType := r.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "multipart/") || strings.HasPrefix(contentType, "application/octet-stream") {
+		return nil
+	}
+	if r.ContentLength > maxCapturedBodySize {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCapturedBodySize+1))
+	if err != nil {
+		impl.logger.Errorw("error in reading request body for audit log", "err", err, "path", r.URL.Path)
+	}
+	// the unread remainder of a large body is chained after the part read, so that the handler still gets all of it
+	r.Body = &restoredBody{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+	if err != nil || len(body) > maxCapturedBodySize {
+		return nil
+	}
+	return body
+}
+
+type restoredBody struct {
+	io.Reader
+	io.Closer
+}
+
+func isMutatingMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+func getResourceId(vars map[string]string) string {
+	for _, pathVar := range resourceIdPathVars {
+		if value, ok := vars[pathVar]; ok && len(value) > 0 {
+			return value
+		}
+	}
+	return ""
+}
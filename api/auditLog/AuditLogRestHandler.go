@@ -0,0 +1,197 @@
+package auditLog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAuditLogPageSize = 20
+	maxAuditLogPageSize     = 500
+	exportFormatCsv         = "csv"
+	exportFormatJson        = "json"
+)
+
+var auditLogCsvHeader = []string{"id", "createdOn", "source", "userId", "emailId", "resourceType", "resourceId", "action",
+	"method", "path", "statusCode", "clientIp", "durationMs", "requestBody", "beforeState", "afterState"}
+
+type AuditLogRestHandler interface {
+	GetAuditLogs(w http.ResponseWriter, r *http.Request)
+	ExportAuditLogs(w http.ResponseWriter, r *http.Request)
+}
+
+type AuditLogRestHandlerImpl struct {
+	logger          *zap.SugaredLogger
+	userService     user.UserService
+	enforcer        casbin.Enforcer
+	auditLogService auditLog.AuditLogService
+}
+
+func NewAuditLogRestHandlerImpl(logger *zap.SugaredLogger,
+	userService user.UserService,
+	enforcer casbin.Enforcer,
+	auditLogService auditLog.AuditLogService) *AuditLogRestHandlerImpl {
+	return &AuditLogRestHandlerImpl{
+		logger:          logger,
+		userService:     userService,
+		enforcer:        enforcer,
+		auditLogService: auditLogService,
+	}
+}
+
+func (handler AuditLogRestHandlerImpl) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	if !handler.isAuthorised(w, r) {
+		return
+	}
+	filter, err := getAuditLogFilter(r)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.auditLogService.GetAuditLogs(filter)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAuditLogs", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler AuditLogRestHandlerImpl) ExportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	if !handler.isAuthorised(w, r) {
+		return
+	}
+	filter, err := getAuditLogFilter(r)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if len(format) == 0 {
+		format = exportFormatCsv
+	}
+	if format != exportFormatCsv && format != exportFormatJson {
+		common.WriteJsonResp(w, errors.New("format must be one of csv, json"), nil, http.StatusBadRequest)
+		return
+	}
+	auditLogs, err := handler.auditLogService.ExportAuditLogs(filter)
+	if err != nil {
+		handler.logger.Errorw("service err, ExportAuditLogs", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	fileName := "audit-log-" + time.Now().Format("20060102150405") + "." + format
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileName)
+	if format == exportFormatJson {
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(auditLogs)
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+		err = writeAuditLogCsv(w, auditLogs)
+	}
+	if err != nil {
+		handler.logger.Errorw("error in writing audit log export", "err", err, "format", format)
+	}
+}
+
+// isAuthorised allows only super admins to read audit logs and writes the error response otherwise
+func (handler AuditLogRestHandlerImpl) isAuthorised(w http.ResponseWriter, r *http.Request) bool {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return false
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func getAuditLogFilter(r *http.Request) (*auditLog.AuditLogFilter, error) {
+	v := r.URL.Query()
+	filter := &auditLog.AuditLogFilter{
+		Source:       auditLog.AuditLogSource(v.Get("source")),
+		EmailId:      v.Get("emailId"),
+		ResourceType: auditLog.ResourceType(v.Get("resourceType")),
+		ResourceId:   v.Get("resourceId"),
+		Action:       v.Get("action"),
+		Size:         defaultAuditLogPageSize,
+	}
+	var err error
+	if userId := v.Get("userId"); len(userId) > 0 {
+		id, err := strconv.Atoi(userId)
+		if err != nil {
+			return nil, err
+		}
+		filter.UserId = int32(id)
+	}
+	if from := v.Get("from"); len(from) > 0 {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, err
+		}
+	}
+	if to := v.Get("to"); len(to) > 0 {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, err
+		}
+	}
+	if offset := v.Get("offset"); len(offset) > 0 {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil {
+			return nil, err
+		}
+	}
+	if size := v.Get("size"); len(size) > 0 {
+		if filter.Size, err = strconv.Atoi(size); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Size <= 0 || filter.Size > maxAuditLogPageSize {
+		filter.Size = defaultAuditLogPageSize
+	}
+	return filter, nil
+}
+
+func writeAuditLogCsv(w http.ResponseWriter, auditLogs []*auditLog.AuditLogDto) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(auditLogCsvHeader)
+	if err != nil {
+		return err
+	}
+	for _, log := range auditLogs {
+		err = writer.Write([]string{
+			strconv.Itoa(log.Id),
+			log.CreatedOn.Format(time.RFC3339),
+			string(log.Source),
+			strconv.Itoa(int(log.UserId)),
+			log.EmailId,
+			string(log.ResourceType),
+			log.ResourceId,
+			log.Action,
+			log.Method,
+			log.Path,
+			strconv.Itoa(log.StatusCode),
+			log.ClientIp,
+			strconv.FormatInt(log.DurationMs, 10),
+			log.RequestBody,
+			string(log.BeforeState),
+			string(log.AfterState),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
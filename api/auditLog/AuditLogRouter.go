@@ -0,0 +1,22 @@
+package auditLog
+
+import (
+	"github.com/gorilla/mux"
+)
+
+type AuditLogRouter interface {
+	InitAuditLogRouter(auditLogRouter *mux.Router)
+}
+
+type AuditLogRouterImpl struct {
+	auditLogRestHandler AuditLogRestHandler
+}
+
+func NewAuditLogRouterImpl(auditLogRestHandler AuditLogRestHandler) *AuditLogRouterImpl {
+	return &AuditLogRouterImpl{auditLogRestHandler: auditLogRestHandler}
+}
+
+func (impl AuditLogRouterImpl) InitAuditLogRouter(auditLogRouter *mux.Router) {
+	auditLogRouter.Path("").HandlerFunc(impl.auditLogRestHandler.GetAuditLogs).Methods("GET")
+	auditLogRouter.Path("/export").HandlerFunc(impl.auditLogRestHandler.ExportAuditLogs).Methods("GET")
+}
@@ -12,5 +12,11 @@ type GitOpsConfigDto struct {
 	AzureProjectName     string `json:"azureProjectName"`
 	BitBucketWorkspaceId string `json:"bitBucketWorkspaceId"`
 	BitBucketProjectKey  string `json:"bitBucketProjectKey"`
+	GiteaOrgId           string `json:"giteaOrgId"`
+	SshPrivateKey        string `json:"sshPrivateKey,omitempty"`
+	SshKnownHosts        string `json:"sshKnownHosts,omitempty"`  //known_hosts entries of the git server, required with ssh private key
+	DryRunRepoName       string `json:"dryRunRepoName,omitempty"` //pre-created repo used for validating generic git provider
+	RepoLayout           string `json:"repoLayout"`               //REPO_PER_APP, MONO_REPO or REPO_PER_TEAM
+	MonoRepoName         string `json:"monoRepoName"`
 	UserId               int32  `json:"-"`
 }
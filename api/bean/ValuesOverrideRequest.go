@@ -29,6 +29,7 @@ const (
 	CD_WORKFLOW_TYPE_PRE              WorkflowType                = "PRE"
 	CD_WORKFLOW_TYPE_POST             WorkflowType                = "POST"
 	CD_WORKFLOW_TYPE_DEPLOY           WorkflowType                = "DEPLOY"
+	CD_WORKFLOW_TYPE_FAN_OUT_DEPLOY   WorkflowType                = "FAN_OUT_DEPLOY"
 	CI_WORKFLOW_TYPE                  WorkflowType                = "CI"
 	DEPLOYMENT_CONFIG_TYPE_LAST_SAVED DeploymentConfigurationType = "LAST_SAVED_CONFIG"
 	//latest trigger is not being used because this is being handled at FE and we anyhow identify latest trigger as
@@ -49,8 +50,13 @@ type ValuesOverrideRequest struct {
 	WfrIdForDeploymentWithSpecificTrigger int                         `json:"wfrIdForDeploymentWithSpecificTrigger"`
 	CdWorkflowType                        WorkflowType                `json:"cdWorkflowType,notnull"`
 	CdWorkflowId                          int                         `json:"cdWorkflowId"`
+	OverrideDeploymentWindow              bool                        `json:"overrideDeploymentWindow"`
+	DeploymentWindowOverrideReason        string                      `json:"deploymentWindowOverrideReason"`
+	IsAutoRollback                        bool                        `json:"-"`
 	UserId                                int32                       `json:"-"`
 	DeploymentType                        models.DeploymentType       `json:"-"`
+	TargetEnvironmentId                   int                         `json:"-"` // deploys the pipeline on an additional environment of its fan-out rollout
+	TargetDeploymentAppCreated            bool                        `json:"-"`
 }
 
 type ReleaseStatusUpdateRequest struct {
@@ -279,14 +279,13 @@ func (impl EnvironmentRestHandlerImpl) GetEnvironmentListForAutocomplete(w http.
 	start = time.Now()
 	if !impl.cfg.IgnoreAuthCheck {
 		grantedEnvironment = make([]request.EnvironmentBean, 0)
-		emailId, _ := impl.userService.GetEmailFromToken(token)
 		// RBAC enforcer applying
 		var envIdentifierList []string
 		for _, item := range environments {
 			envIdentifierList = append(envIdentifierList, strings.ToLower(item.EnvironmentIdentifier))
 		}
 
-		result := impl.enforcer.EnforceByEmailInBatch(emailId, casbin.ResourceGlobalEnvironment, casbin.ActionGet, envIdentifierList)
+		result := impl.enforcer.EnforceInBatch(token, casbin.ResourceGlobalEnvironment, casbin.ActionGet, envIdentifierList)
 		for _, item := range environments {
 			if hasAccess := result[strings.ToLower(item.EnvironmentIdentifier)]; hasAccess {
 				grantedEnvironment = append(grantedEnvironment, item)
@@ -36,6 +36,12 @@ type ApiToken struct {
 	LastUsedByIp *string `json:"lastUsedByIp,omitempty"`
 	// token last updatedAt
 	UpdatedAt *string `json:"updatedAt,omitempty"`
+	// Role filters the api-token is restricted to, the token is not scoped if empty
+	RoleFilters *[]ApiTokenRoleFilter `json:"roleFilters,omitempty"`
+	// IPs or CIDRs the api-token can be used from, any ip is allowed if empty
+	AllowedIps *[]string `json:"allowedIps,omitempty"`
+	// Casbin roles a scoped api-token is restricted to
+	ScopedRoles *[]string `json:"scopedRoles,omitempty"`
 }
 
 // NewApiToken instantiates a new ApiToken object
@@ -375,6 +381,102 @@ func (o *ApiToken) SetUpdatedAt(v string) {
 	o.UpdatedAt = &v
 }
 
+// GetRoleFilters returns the RoleFilters field value if set, zero value otherwise.
+func (o *ApiToken) GetRoleFilters() []ApiTokenRoleFilter {
+	if o == nil || o.RoleFilters == nil {
+		var ret []ApiTokenRoleFilter
+		return ret
+	}
+	return *o.RoleFilters
+}
+
+// GetRoleFiltersOk returns a tuple with the RoleFilters field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiToken) GetRoleFiltersOk() (*[]ApiTokenRoleFilter, bool) {
+	if o == nil || o.RoleFilters == nil {
+		return nil, false
+	}
+	return o.RoleFilters, true
+}
+
+// HasRoleFilters returns a boolean if a field has been set.
+func (o *ApiToken) HasRoleFilters() bool {
+	if o != nil && o.RoleFilters != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetRoleFilters gets a reference to the given []ApiTokenRoleFilter and assigns it to the RoleFilters field.
+func (o *ApiToken) SetRoleFilters(v []ApiTokenRoleFilter) {
+	o.RoleFilters = &v
+}
+
+// GetAllowedIps returns the AllowedIps field value if set, zero value otherwise.
+func (o *ApiToken) GetAllowedIps() []string {
+	if o == nil || o.AllowedIps == nil {
+		var ret []string
+		return ret
+	}
+	return *o.AllowedIps
+}
+
+// GetAllowedIpsOk returns a tuple with the AllowedIps field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiToken) GetAllowedIpsOk() (*[]string, bool) {
+	if o == nil || o.AllowedIps == nil {
+		return nil, false
+	}
+	return o.AllowedIps, true
+}
+
+// HasAllowedIps returns a boolean if a field has been set.
+func (o *ApiToken) HasAllowedIps() bool {
+	if o != nil && o.AllowedIps != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetAllowedIps gets a reference to the given []string and assigns it to the AllowedIps field.
+func (o *ApiToken) SetAllowedIps(v []string) {
+	o.AllowedIps = &v
+}
+
+// GetScopedRoles returns the ScopedRoles field value if set, zero value otherwise.
+func (o *ApiToken) GetScopedRoles() []string {
+	if o == nil || o.ScopedRoles == nil {
+		var ret []string
+		return ret
+	}
+	return *o.ScopedRoles
+}
+
+// GetScopedRolesOk returns a tuple with the ScopedRoles field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiToken) GetScopedRolesOk() (*[]string, bool) {
+	if o == nil || o.ScopedRoles == nil {
+		return nil, false
+	}
+	return o.ScopedRoles, true
+}
+
+// HasScopedRoles returns a boolean if a field has been set.
+func (o *ApiToken) HasScopedRoles() bool {
+	if o != nil && o.ScopedRoles != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetScopedRoles gets a reference to the given []string and assigns it to the ScopedRoles field.
+func (o *ApiToken) SetScopedRoles(v []string) {
+	o.ScopedRoles = &v
+}
+
 func (o ApiToken) MarshalJSON() ([]byte, error) {
 	toSerialize := map[string]interface{}{}
 	if o.Id != nil {
@@ -407,6 +509,15 @@ func (o ApiToken) MarshalJSON() ([]byte, error) {
 	if o.UpdatedAt != nil {
 		toSerialize["updatedAt"] = o.UpdatedAt
 	}
+	if o.RoleFilters != nil {
+		toSerialize["roleFilters"] = o.RoleFilters
+	}
+	if o.AllowedIps != nil {
+		toSerialize["allowedIps"] = o.AllowedIps
+	}
+	if o.ScopedRoles != nil {
+		toSerialize["scopedRoles"] = o.ScopedRoles
+	}
 	return json.Marshal(toSerialize)
 }
 
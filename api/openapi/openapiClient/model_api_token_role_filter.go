@@ -0,0 +1,301 @@
+/*
+Devtron Labs
+
+No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+
+API version: 1.0.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package openapi
+
+import (
+	"encoding/json"
+)
+
+// ApiTokenRoleFilter struct for ApiTokenRoleFilter
+type ApiTokenRoleFilter struct {
+	// Entity of the role filter, empty for devtron apps
+	Entity *string `json:"entity,omitempty"`
+	// Project of the role filter
+	Team *string `json:"team,omitempty"`
+	// Comma separated app names, empty for all apps
+	EntityName *string `json:"entityName,omitempty"`
+	// Comma separated environment names, empty for all environments
+	Environment *string `json:"environment,omitempty"`
+	// Action of the role filter e.g. view, trigger, admin
+	Action *string `json:"action,omitempty"`
+	// Access type of the role filter, empty for devtron apps
+	AccessType *string `json:"accessType,omitempty"`
+}
+
+// NewApiTokenRoleFilter instantiates a new ApiTokenRoleFilter object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewApiTokenRoleFilter() *ApiTokenRoleFilter {
+	this := ApiTokenRoleFilter{}
+	return &this
+}
+
+// NewApiTokenRoleFilterWithDefaults instantiates a new ApiTokenRoleFilter object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewApiTokenRoleFilterWithDefaults() *ApiTokenRoleFilter {
+	this := ApiTokenRoleFilter{}
+	return &this
+}
+
+// GetEntity returns the Entity field value if set, zero value otherwise.
+func (o *ApiTokenRoleFilter) GetEntity() string {
+	if o == nil || o.Entity == nil {
+		var ret string
+		return ret
+	}
+	return *o.Entity
+}
+
+// GetEntityOk returns a tuple with the Entity field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiTokenRoleFilter) GetEntityOk() (*string, bool) {
+	if o == nil || o.Entity == nil {
+		return nil, false
+	}
+	return o.Entity, true
+}
+
+// HasEntity returns a boolean if a field has been set.
+func (o *ApiTokenRoleFilter) HasEntity() bool {
+	if o != nil && o.Entity != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetEntity gets a reference to the given string and assigns it to the Entity field.
+func (o *ApiTokenRoleFilter) SetEntity(v string) {
+	o.Entity = &v
+}
+
+// GetTeam returns the Team field value if set, zero value otherwise.
+func (o *ApiTokenRoleFilter) GetTeam() string {
+	if o == nil || o.Team == nil {
+		var ret string
+		return ret
+	}
+	return *o.Team
+}
+
+// GetTeamOk returns a tuple with the Team field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiTokenRoleFilter) GetTeamOk() (*string, bool) {
+	if o == nil || o.Team == nil {
+		return nil, false
+	}
+	return o.Team, true
+}
+
+// HasTeam returns a boolean if a field has been set.
+func (o *ApiTokenRoleFilter) HasTeam() bool {
+	if o != nil && o.Team != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetTeam gets a reference to the given string and assigns it to the Team field.
+func (o *ApiTokenRoleFilter) SetTeam(v string) {
+	o.Team = &v
+}
+
+// GetEntityName returns the EntityName field value if set, zero value otherwise.
+func (o *ApiTokenRoleFilter) GetEntityName() string {
+	if o == nil || o.EntityName == nil {
+		var ret string
+		return ret
+	}
+	return *o.EntityName
+}
+
+// GetEntityNameOk returns a tuple with the EntityName field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiTokenRoleFilter) GetEntityNameOk() (*string, bool) {
+	if o == nil || o.EntityName == nil {
+		return nil, false
+	}
+	return o.EntityName, true
+}
+
+// HasEntityName returns a boolean if a field has been set.
+func (o *ApiTokenRoleFilter) HasEntityName() bool {
+	if o != nil && o.EntityName != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetEntityName gets a reference to the given string and assigns it to the EntityName field.
+func (o *ApiTokenRoleFilter) SetEntityName(v string) {
+	o.EntityName = &v
+}
+
+// GetEnvironment returns the Environment field value if set, zero value otherwise.
+func (o *ApiTokenRoleFilter) GetEnvironment() string {
+	if o == nil || o.Environment == nil {
+		var ret string
+		return ret
+	}
+	return *o.Environment
+}
+
+// GetEnvironmentOk returns a tuple with the Environment field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiTokenRoleFilter) GetEnvironmentOk() (*string, bool) {
+	if o == nil || o.Environment == nil {
+		return nil, false
+	}
+	return o.Environment, true
+}
+
+// HasEnvironment returns a boolean if a field has been set.
+func (o *ApiTokenRoleFilter) HasEnvironment() bool {
+	if o != nil && o.Environment != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetEnvironment gets a reference to the given string and assigns it to the Environment field.
+func (o *ApiTokenRoleFilter) SetEnvironment(v string) {
+	o.Environment = &v
+}
+
+// GetAction returns the Action field value if set, zero value otherwise.
+func (o *ApiTokenRoleFilter) GetAction() string {
+	if o == nil || o.Action == nil {
+		var ret string
+		return ret
+	}
+	return *o.Action
+}
+
+// GetActionOk returns a tuple with the Action field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiTokenRoleFilter) GetActionOk() (*string, bool) {
+	if o == nil || o.Action == nil {
+		return nil, false
+	}
+	return o.Action, true
+}
+
+// HasAction returns a boolean if a field has been set.
+func (o *ApiTokenRoleFilter) HasAction() bool {
+	if o != nil && o.Action != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetAction gets a reference to the given string and assigns it to the Action field.
+func (o *ApiTokenRoleFilter) SetAction(v string) {
+	o.Action = &v
+}
+
+// GetAccessType returns the AccessType field value if set, zero value otherwise.
+func (o *ApiTokenRoleFilter) GetAccessType() string {
+	if o == nil || o.AccessType == nil {
+		var ret string
+		return ret
+	}
+	return *o.AccessType
+}
+
+// GetAccessTypeOk returns a tuple with the AccessType field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *ApiTokenRoleFilter) GetAccessTypeOk() (*string, bool) {
+	if o == nil || o.AccessType == nil {
+		return nil, false
+	}
+	return o.AccessType, true
+}
+
+// HasAccessType returns a boolean if a field has been set.
+func (o *ApiTokenRoleFilter) HasAccessType() bool {
+	if o != nil && o.AccessType != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetAccessType gets a reference to the given string and assigns it to the AccessType field.
+func (o *ApiTokenRoleFilter) SetAccessType(v string) {
+	o.AccessType = &v
+}
+
+func (o ApiTokenRoleFilter) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if o.Entity != nil {
+		toSerialize["entity"] = o.Entity
+	}
+	if o.Team != nil {
+		toSerialize["team"] = o.Team
+	}
+	if o.EntityName != nil {
+		toSerialize["entityName"] = o.EntityName
+	}
+	if o.Environment != nil {
+		toSerialize["environment"] = o.Environment
+	}
+	if o.Action != nil {
+		toSerialize["action"] = o.Action
+	}
+	if o.AccessType != nil {
+		toSerialize["accessType"] = o.AccessType
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableApiTokenRoleFilter struct {
+	value *ApiTokenRoleFilter
+	isSet bool
+}
+
+func (v NullableApiTokenRoleFilter) Get() *ApiTokenRoleFilter {
+	return v.value
+}
+
+func (v *NullableApiTokenRoleFilter) Set(val *ApiTokenRoleFilter) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableApiTokenRoleFilter) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableApiTokenRoleFilter) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableApiTokenRoleFilter(val *ApiTokenRoleFilter) *NullableApiTokenRoleFilter {
+	return &NullableApiTokenRoleFilter{value: val, isSet: true}
+}
+
+func (v NullableApiTokenRoleFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableApiTokenRoleFilter) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
+
+
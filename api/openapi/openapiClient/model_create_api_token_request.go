@@ -23,6 +23,10 @@ type CreateApiTokenRequest struct {
 	Description *string `json:"description,omitempty,notnull" validate:"required"`
 	// Expiration time of api-token in milliseconds
 	ExpireAtInMs *int64 `json:"expireAtInMs,omitempty"`
+	// Role filters the api-token is restricted to, the token is not scoped if empty
+	RoleFilters *[]ApiTokenRoleFilter `json:"roleFilters,omitempty"`
+	// IPs or CIDRs the api-token can be used from, any ip is allowed if empty
+	AllowedIps *[]string `json:"allowedIps,omitempty"`
 }
 
 // NewCreateApiTokenRequest instantiates a new CreateApiTokenRequest object
@@ -138,6 +142,70 @@ func (o *CreateApiTokenRequest) SetExpireAtInMs(v int64) {
 	o.ExpireAtInMs = &v
 }
 
+// GetRoleFilters returns the RoleFilters field value if set, zero value otherwise.
+func (o *CreateApiTokenRequest) GetRoleFilters() []ApiTokenRoleFilter {
+	if o == nil || o.RoleFilters == nil {
+		var ret []ApiTokenRoleFilter
+		return ret
+	}
+	return *o.RoleFilters
+}
+
+// GetRoleFiltersOk returns a tuple with the RoleFilters field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *CreateApiTokenRequest) GetRoleFiltersOk() (*[]ApiTokenRoleFilter, bool) {
+	if o == nil || o.RoleFilters == nil {
+		return nil, false
+	}
+	return o.RoleFilters, true
+}
+
+// HasRoleFilters returns a boolean if a field has been set.
+func (o *CreateApiTokenRequest) HasRoleFilters() bool {
+	if o != nil && o.RoleFilters != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetRoleFilters gets a reference to the given []ApiTokenRoleFilter and assigns it to the RoleFilters field.
+func (o *CreateApiTokenRequest) SetRoleFilters(v []ApiTokenRoleFilter) {
+	o.RoleFilters = &v
+}
+
+// GetAllowedIps returns the AllowedIps field value if set, zero value otherwise.
+func (o *CreateApiTokenRequest) GetAllowedIps() []string {
+	if o == nil || o.AllowedIps == nil {
+		var ret []string
+		return ret
+	}
+	return *o.AllowedIps
+}
+
+// GetAllowedIpsOk returns a tuple with the AllowedIps field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *CreateApiTokenRequest) GetAllowedIpsOk() (*[]string, bool) {
+	if o == nil || o.AllowedIps == nil {
+		return nil, false
+	}
+	return o.AllowedIps, true
+}
+
+// HasAllowedIps returns a boolean if a field has been set.
+func (o *CreateApiTokenRequest) HasAllowedIps() bool {
+	if o != nil && o.AllowedIps != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetAllowedIps gets a reference to the given []string and assigns it to the AllowedIps field.
+func (o *CreateApiTokenRequest) SetAllowedIps(v []string) {
+	o.AllowedIps = &v
+}
+
 func (o CreateApiTokenRequest) MarshalJSON() ([]byte, error) {
 	toSerialize := map[string]interface{}{}
 	if o.Name != nil {
@@ -149,6 +217,12 @@ func (o CreateApiTokenRequest) MarshalJSON() ([]byte, error) {
 	if o.ExpireAtInMs != nil {
 		toSerialize["expireAtInMs"] = o.ExpireAtInMs
 	}
+	if o.RoleFilters != nil {
+		toSerialize["roleFilters"] = o.RoleFilters
+	}
+	if o.AllowedIps != nil {
+		toSerialize["allowedIps"] = o.AllowedIps
+	}
 	return json.Marshal(toSerialize)
 }
 
@@ -0,0 +1,153 @@
+/*
+Devtron Labs
+
+No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+
+API version: 1.0.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package openapi
+
+import (
+	"encoding/json"
+)
+
+// RotateApiTokenRequest struct for RotateApiTokenRequest
+type RotateApiTokenRequest struct {
+	// Minutes for which the previous token stays valid, server default is used if not set
+	GracePeriodInMins *int32 `json:"gracePeriodInMins,omitempty"`
+	// Expiration time of the new token in milliseconds, current expiration is kept if not set
+	ExpireAtInMs *int64 `json:"expireAtInMs,omitempty"`
+}
+
+// NewRotateApiTokenRequest instantiates a new RotateApiTokenRequest object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewRotateApiTokenRequest() *RotateApiTokenRequest {
+	this := RotateApiTokenRequest{}
+	return &this
+}
+
+// NewRotateApiTokenRequestWithDefaults instantiates a new RotateApiTokenRequest object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewRotateApiTokenRequestWithDefaults() *RotateApiTokenRequest {
+	this := RotateApiTokenRequest{}
+	return &this
+}
+
+// GetGracePeriodInMins returns the GracePeriodInMins field value if set, zero value otherwise.
+func (o *RotateApiTokenRequest) GetGracePeriodInMins() int32 {
+	if o == nil || o.GracePeriodInMins == nil {
+		var ret int32
+		return ret
+	}
+	return *o.GracePeriodInMins
+}
+
+// GetGracePeriodInMinsOk returns a tuple with the GracePeriodInMins field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *RotateApiTokenRequest) GetGracePeriodInMinsOk() (*int32, bool) {
+	if o == nil || o.GracePeriodInMins == nil {
+		return nil, false
+	}
+	return o.GracePeriodInMins, true
+}
+
+// HasGracePeriodInMins returns a boolean if a field has been set.
+func (o *RotateApiTokenRequest) HasGracePeriodInMins() bool {
+	if o != nil && o.GracePeriodInMins != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetGracePeriodInMins gets a reference to the given int32 and assigns it to the GracePeriodInMins field.
+func (o *RotateApiTokenRequest) SetGracePeriodInMins(v int32) {
+	o.GracePeriodInMins = &v
+}
+
+// GetExpireAtInMs returns the ExpireAtInMs field value if set, zero value otherwise.
+func (o *RotateApiTokenRequest) GetExpireAtInMs() int64 {
+	if o == nil || o.ExpireAtInMs == nil {
+		var ret int64
+		return ret
+	}
+	return *o.ExpireAtInMs
+}
+
+// GetExpireAtInMsOk returns a tuple with the ExpireAtInMs field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *RotateApiTokenRequest) GetExpireAtInMsOk() (*int64, bool) {
+	if o == nil || o.ExpireAtInMs == nil {
+		return nil, false
+	}
+	return o.ExpireAtInMs, true
+}
+
+// HasExpireAtInMs returns a boolean if a field has been set.
+func (o *RotateApiTokenRequest) HasExpireAtInMs() bool {
+	if o != nil && o.ExpireAtInMs != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetExpireAtInMs gets a reference to the given int64 and assigns it to the ExpireAtInMs field.
+func (o *RotateApiTokenRequest) SetExpireAtInMs(v int64) {
+	o.ExpireAtInMs = &v
+}
+
+func (o RotateApiTokenRequest) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if o.GracePeriodInMins != nil {
+		toSerialize["gracePeriodInMins"] = o.GracePeriodInMins
+	}
+	if o.ExpireAtInMs != nil {
+		toSerialize["expireAtInMs"] = o.ExpireAtInMs
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableRotateApiTokenRequest struct {
+	value *RotateApiTokenRequest
+	isSet bool
+}
+
+func (v NullableRotateApiTokenRequest) Get() *RotateApiTokenRequest {
+	return v.value
+}
+
+func (v *NullableRotateApiTokenRequest) Set(val *RotateApiTokenRequest) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableRotateApiTokenRequest) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableRotateApiTokenRequest) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableRotateApiTokenRequest(val *RotateApiTokenRequest) *NullableRotateApiTokenRequest {
+	return &NullableRotateApiTokenRequest{value: val, isSet: true}
+}
+
+func (v NullableRotateApiTokenRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableRotateApiTokenRequest) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
+
+
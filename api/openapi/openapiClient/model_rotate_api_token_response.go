@@ -0,0 +1,190 @@
+/*
+Devtron Labs
+
+No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+
+API version: 1.0.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package openapi
+
+import (
+	"encoding/json"
+)
+
+// RotateApiTokenResponse struct for RotateApiTokenResponse
+type RotateApiTokenResponse struct {
+	// success or failure
+	Success *bool `json:"success,omitempty"`
+	// Token of that api-token
+	Token *string `json:"token,omitempty"`
+	// Time in milliseconds till which the previous token stays valid
+	PreviousTokenExpireAtInMs *int64 `json:"previousTokenExpireAtInMs,omitempty"`
+}
+
+// NewRotateApiTokenResponse instantiates a new RotateApiTokenResponse object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewRotateApiTokenResponse() *RotateApiTokenResponse {
+	this := RotateApiTokenResponse{}
+	return &this
+}
+
+// NewRotateApiTokenResponseWithDefaults instantiates a new RotateApiTokenResponse object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewRotateApiTokenResponseWithDefaults() *RotateApiTokenResponse {
+	this := RotateApiTokenResponse{}
+	return &this
+}
+
+// GetSuccess returns the Success field value if set, zero value otherwise.
+func (o *RotateApiTokenResponse) GetSuccess() bool {
+	if o == nil || o.Success == nil {
+		var ret bool
+		return ret
+	}
+	return *o.Success
+}
+
+// GetSuccessOk returns a tuple with the Success field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *RotateApiTokenResponse) GetSuccessOk() (*bool, bool) {
+	if o == nil || o.Success == nil {
+		return nil, false
+	}
+	return o.Success, true
+}
+
+// HasSuccess returns a boolean if a field has been set.
+func (o *RotateApiTokenResponse) HasSuccess() bool {
+	if o != nil && o.Success != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetSuccess gets a reference to the given bool and assigns it to the Success field.
+func (o *RotateApiTokenResponse) SetSuccess(v bool) {
+	o.Success = &v
+}
+
+// GetToken returns the Token field value if set, zero value otherwise.
+func (o *RotateApiTokenResponse) GetToken() string {
+	if o == nil || o.Token == nil {
+		var ret string
+		return ret
+	}
+	return *o.Token
+}
+
+// GetTokenOk returns a tuple with the Token field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *RotateApiTokenResponse) GetTokenOk() (*string, bool) {
+	if o == nil || o.Token == nil {
+		return nil, false
+	}
+	return o.Token, true
+}
+
+// HasToken returns a boolean if a field has been set.
+func (o *RotateApiTokenResponse) HasToken() bool {
+	if o != nil && o.Token != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetToken gets a reference to the given string and assigns it to the Token field.
+func (o *RotateApiTokenResponse) SetToken(v string) {
+	o.Token = &v
+}
+
+// GetPreviousTokenExpireAtInMs returns the PreviousTokenExpireAtInMs field value if set, zero value otherwise.
+func (o *RotateApiTokenResponse) GetPreviousTokenExpireAtInMs() int64 {
+	if o == nil || o.PreviousTokenExpireAtInMs == nil {
+		var ret int64
+		return ret
+	}
+	return *o.PreviousTokenExpireAtInMs
+}
+
+// GetPreviousTokenExpireAtInMsOk returns a tuple with the PreviousTokenExpireAtInMs field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *RotateApiTokenResponse) GetPreviousTokenExpireAtInMsOk() (*int64, bool) {
+	if o == nil || o.PreviousTokenExpireAtInMs == nil {
+		return nil, false
+	}
+	return o.PreviousTokenExpireAtInMs, true
+}
+
+// HasPreviousTokenExpireAtInMs returns a boolean if a field has been set.
+func (o *RotateApiTokenResponse) HasPreviousTokenExpireAtInMs() bool {
+	if o != nil && o.PreviousTokenExpireAtInMs != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetPreviousTokenExpireAtInMs gets a reference to the given int64 and assigns it to the PreviousTokenExpireAtInMs field.
+func (o *RotateApiTokenResponse) SetPreviousTokenExpireAtInMs(v int64) {
+	o.PreviousTokenExpireAtInMs = &v
+}
+
+func (o RotateApiTokenResponse) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if o.Success != nil {
+		toSerialize["success"] = o.Success
+	}
+	if o.Token != nil {
+		toSerialize["token"] = o.Token
+	}
+	if o.PreviousTokenExpireAtInMs != nil {
+		toSerialize["previousTokenExpireAtInMs"] = o.PreviousTokenExpireAtInMs
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableRotateApiTokenResponse struct {
+	value *RotateApiTokenResponse
+	isSet bool
+}
+
+func (v NullableRotateApiTokenResponse) Get() *RotateApiTokenResponse {
+	return v.value
+}
+
+func (v *NullableRotateApiTokenResponse) Set(val *RotateApiTokenResponse) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableRotateApiTokenResponse) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableRotateApiTokenResponse) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableRotateApiTokenResponse(val *RotateApiTokenResponse) *NullableRotateApiTokenResponse {
+	return &NullableRotateApiTokenResponse{value: val, isSet: true}
+}
+
+func (v NullableRotateApiTokenResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableRotateApiTokenResponse) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
+
+
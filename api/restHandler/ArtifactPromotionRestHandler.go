@@ -0,0 +1,206 @@
+package restHandler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type ArtifactPromotionRestHandler interface {
+	GetPolicy(w http.ResponseWriter, r *http.Request)
+	SavePolicy(w http.ResponseWriter, r *http.Request)
+	CheckPromotion(w http.ResponseWriter, r *http.Request)
+	PromoteArtifact(w http.ResponseWriter, r *http.Request)
+	GetAudits(w http.ResponseWriter, r *http.Request)
+}
+
+type ArtifactPromotionRestHandlerImpl struct {
+	logger                   *zap.SugaredLogger
+	userAuthService          user.UserService
+	validator                *validator.Validate
+	enforcer                 casbin.Enforcer
+	enforcerUtil             rbac.EnforcerUtil
+	argoUserService          argo.ArgoUserService
+	artifactPromotionService artifactPromotion.ArtifactPromotionService
+}
+
+func NewArtifactPromotionRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	argoUserService argo.ArgoUserService,
+	artifactPromotionService artifactPromotion.ArtifactPromotionService) *ArtifactPromotionRestHandlerImpl {
+	return &ArtifactPromotionRestHandlerImpl{
+		logger:                   logger,
+		userAuthService:          userAuthService,
+		validator:                validator,
+		enforcer:                 enforcer,
+		enforcerUtil:             enforcerUtil,
+		argoUserService:          argoUserService,
+		artifactPromotionService: artifactPromotionService,
+	}
+}
+
+func (handler *ArtifactPromotionRestHandlerImpl) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.artifactPromotionService.GetPolicy(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPolicy", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionRestHandlerImpl) SavePolicy(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean artifactPromotion.ArtifactPromotionPolicyDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SavePolicy", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, bean.PipelineId, casbin.ActionUpdate); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.artifactPromotionService.SavePolicy(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionRestHandlerImpl) CheckPromotion(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	request, ok := handler.decodePromotionRequest(w, r, userId)
+	if !ok {
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, request.PipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.artifactPromotionService.CheckPromotion(request)
+	if err != nil {
+		handler.logger.Errorw("service err, CheckPromotion", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionRestHandlerImpl) PromoteArtifact(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	request, ok := handler.decodePromotionRequest(w, r, userId)
+	if !ok {
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, request.PipelineId, casbin.ActionTrigger); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	acdToken, err := handler.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		handler.logger.Errorw("error in getting acd token", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	ctx := context.WithValue(r.Context(), "token", acdToken)
+	res, err := handler.artifactPromotionService.PromoteArtifact(request, ctx)
+	if err != nil {
+		handler.logger.Errorw("service err, PromoteArtifact", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionRestHandlerImpl) GetAudits(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.artifactPromotionService.GetAudits(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAudits", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionRestHandlerImpl) decodePromotionRequest(w http.ResponseWriter, r *http.Request, userId int32) (*artifactPromotion.ArtifactPromotionRequest, bool) {
+	decoder := json.NewDecoder(r.Body)
+	var request artifactPromotion.ArtifactPromotionRequest
+	err := decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, artifact promotion", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return nil, false
+	}
+	request.UserId = userId
+	handler.logger.Infow("request payload, artifact promotion", "payload", request)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, artifact promotion", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return nil, false
+	}
+	return &request, true
+}
@@ -0,0 +1,124 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type AutoRollbackRestHandler interface {
+	GetPolicy(w http.ResponseWriter, r *http.Request)
+	SavePolicy(w http.ResponseWriter, r *http.Request)
+	GetAudits(w http.ResponseWriter, r *http.Request)
+}
+
+type AutoRollbackRestHandlerImpl struct {
+	logger              *zap.SugaredLogger
+	userAuthService     user.UserService
+	validator           *validator.Validate
+	enforcer            casbin.Enforcer
+	enforcerUtil        rbac.EnforcerUtil
+	autoRollbackService pipeline.AutoRollbackService
+}
+
+func NewAutoRollbackRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	autoRollbackService pipeline.AutoRollbackService) *AutoRollbackRestHandlerImpl {
+	return &AutoRollbackRestHandlerImpl{
+		logger:              logger,
+		userAuthService:     userAuthService,
+		validator:           validator,
+		enforcer:            enforcer,
+		enforcerUtil:        enforcerUtil,
+		autoRollbackService: autoRollbackService,
+	}
+}
+
+func (handler *AutoRollbackRestHandlerImpl) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.autoRollbackService.GetPolicy(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPolicy", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AutoRollbackRestHandlerImpl) SavePolicy(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean pipeline.AutoRollbackPolicyDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SavePolicy", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, bean.PipelineId, casbin.ActionUpdate); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.autoRollbackService.SavePolicy(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AutoRollbackRestHandlerImpl) GetAudits(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.autoRollbackService.GetAudits(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAudits", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
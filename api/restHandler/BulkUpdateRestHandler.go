@@ -39,6 +39,10 @@ type BulkUpdateRestHandler interface {
 	BulkDeploy(w http.ResponseWriter, r *http.Request)
 	BulkBuildTrigger(w http.ResponseWriter, r *http.Request)
 
+	GetBulkOperationJob(w http.ResponseWriter, r *http.Request)
+	CancelBulkOperationJob(w http.ResponseWriter, r *http.Request)
+	RevertBulkOperationJob(w http.ResponseWriter, r *http.Request)
+
 	HandleCdPipelineBulkAction(w http.ResponseWriter, r *http.Request)
 }
 type BulkUpdateRestHandlerImpl struct {
@@ -222,6 +226,12 @@ func (handler BulkUpdateRestHandlerImpl) BulkUpdate(w http.ResponseWriter, r *ht
 		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
 		return
 	}
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	script.Spec.UserId = userId
 	token := r.Header.Get("token")
 	impactedApps, err := handler.bulkUpdateService.GetBulkAppName(script.Spec)
 	if err != nil {
@@ -233,21 +243,29 @@ func (handler BulkUpdateRestHandlerImpl) BulkUpdate(w http.ResponseWriter, r *ht
 		ok := handler.CheckAuthForBulkUpdate(deploymentTemplateImpactedApp.AppId, deploymentTemplateImpactedApp.EnvId, deploymentTemplateImpactedApp.AppName, rbacObjects, token)
 		if !ok {
 			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
 		}
 	}
 	for _, configMapImpactedApp := range impactedApps.ConfigMap {
 		ok := handler.CheckAuthForBulkUpdate(configMapImpactedApp.AppId, configMapImpactedApp.EnvId, configMapImpactedApp.AppName, rbacObjects, token)
 		if !ok {
 			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
 		}
 	}
 	for _, secretImpactedApp := range impactedApps.Secret {
 		ok := handler.CheckAuthForBulkUpdate(secretImpactedApp.AppId, secretImpactedApp.EnvId, secretImpactedApp.AppName, rbacObjects, token)
 		if !ok {
 			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
 		}
 	}
 
+	if script.Spec.DryRun {
+		dryRunResponse := handler.bulkUpdateService.BulkUpdateDryRun(script.Spec)
+		common.WriteJsonResp(w, nil, dryRunResponse, http.StatusOK)
+		return
+	}
 	response := handler.bulkUpdateService.BulkUpdate(script.Spec)
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
@@ -279,9 +297,10 @@ func (handler BulkUpdateRestHandlerImpl) BulkHibernate(w http.ResponseWriter, r
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	ctx := context.WithValue(r.Context(), "token", acdToken)
+	//job keeps running after the request is served, so it should not inherit request's cancellation
+	ctx := context.WithValue(context.Background(), "token", acdToken)
 	token := r.Header.Get("token")
-	response, err := handler.bulkUpdateService.BulkHibernate(&request, ctx, w, token, handler.checkAuthForBulkActions)
+	response, err := handler.bulkUpdateService.BulkHibernate(&request, ctx, token, handler.checkAuthForBulkActions)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
@@ -316,9 +335,10 @@ func (handler BulkUpdateRestHandlerImpl) BulkUnHibernate(w http.ResponseWriter,
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	ctx := context.WithValue(r.Context(), "token", acdToken)
+	//job keeps running after the request is served, so it should not inherit request's cancellation
+	ctx := context.WithValue(context.Background(), "token", acdToken)
 	token := r.Header.Get("token")
-	response, err := handler.bulkUpdateService.BulkUnHibernate(&request, ctx, w, token, handler.checkAuthForBulkActions)
+	response, err := handler.bulkUpdateService.BulkUnHibernate(&request, ctx, token, handler.checkAuthForBulkActions)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
@@ -352,9 +372,10 @@ func (handler BulkUpdateRestHandlerImpl) BulkDeploy(w http.ResponseWriter, r *ht
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	ctx := context.WithValue(r.Context(), "token", acdToken)
+	//job keeps running after the request is served, so it should not inherit request's cancellation
+	ctx := context.WithValue(context.Background(), "token", acdToken)
 	token := r.Header.Get("token")
-	response, err := handler.bulkUpdateService.BulkDeploy(&request, ctx, w, token, handler.checkAuthForBulkActions)
+	response, err := handler.bulkUpdateService.BulkDeploy(&request, ctx, token, handler.checkAuthForBulkActions)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
@@ -388,16 +409,127 @@ func (handler BulkUpdateRestHandlerImpl) BulkBuildTrigger(w http.ResponseWriter,
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	ctx := context.WithValue(r.Context(), "token", acdToken)
+	//job keeps running after the request is served, so it should not inherit request's cancellation
+	ctx := context.WithValue(context.Background(), "token", acdToken)
 	token := r.Header.Get("token")
-	response, err := handler.bulkUpdateService.BulkBuildTrigger(&request, ctx, w, token, handler.checkAuthForBulkActions)
+	response, err := handler.bulkUpdateService.BulkBuildTrigger(&request, ctx, token, handler.checkAuthForBulkActions)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+func (handler BulkUpdateRestHandlerImpl) GetBulkOperationJob(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	jobId, err := strconv.Atoi(mux.Vars(r)["jobId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	response, err := handler.bulkUpdateService.GetBulkOperationJob(jobId)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
+	token := r.Header.Get("token")
+	if ok := handler.checkAuthForBulkOperationJob(userId, token, response, casbin.ActionGet); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
 
+func (handler BulkUpdateRestHandlerImpl) CancelBulkOperationJob(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	jobId, err := strconv.Atoi(mux.Vars(r)["jobId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	job, err := handler.bulkUpdateService.GetBulkOperationJob(jobId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.checkAuthForBulkOperationJob(userId, token, job, casbin.ActionUpdate); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	response, err := handler.bulkUpdateService.CancelBulkOperationJob(jobId, userId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+func (handler BulkUpdateRestHandlerImpl) RevertBulkOperationJob(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	jobId, err := strconv.Atoi(mux.Vars(r)["jobId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	job, err := handler.bulkUpdateService.GetBulkOperationJob(jobId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.checkAuthForBulkOperationJob(userId, token, job, casbin.ActionUpdate); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	response, err := handler.bulkUpdateService.RevertBulkOperationJob(jobId, userId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+// checkAuthForBulkOperationJob requires the caller to be the creator of the job or a super-admin, along with access on
+// every app and env touched by the job
+func (handler BulkUpdateRestHandlerImpl) checkAuthForBulkOperationJob(userId int32, token string, job *bulkAction.BulkOperationJobResponse, action string) bool {
+	if job.CreatedBy != userId {
+		isSuperAdmin, err := handler.userAuthService.IsSuperAdmin(int(userId))
+		if err != nil {
+			handler.logger.Errorw("error in checking if user is super admin", "err", err, "userId", userId)
+			return false
+		}
+		if !isSuperAdmin {
+			return false
+		}
+	}
+	for _, item := range job.Items {
+		appObject := handler.enforcerUtil.GetAppRBACNameByAppId(item.AppId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, strings.ToLower(appObject)); !ok {
+			return false
+		}
+		if item.EnvId > 0 {
+			envObject := handler.enforcerUtil.GetEnvRBACNameByAppId(item.AppId, item.EnvId)
+			if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, strings.ToLower(envObject)); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (handler BulkUpdateRestHandlerImpl) checkAuthForBulkActions(token string, appObject string, envObject string) bool {
 	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, strings.ToLower(appObject)); !ok {
 		return false
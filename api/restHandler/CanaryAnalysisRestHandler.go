@@ -0,0 +1,124 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type CanaryAnalysisRestHandler interface {
+	GetConfig(w http.ResponseWriter, r *http.Request)
+	SaveConfig(w http.ResponseWriter, r *http.Request)
+	GetRuns(w http.ResponseWriter, r *http.Request)
+}
+
+type CanaryAnalysisRestHandlerImpl struct {
+	logger                *zap.SugaredLogger
+	userAuthService       user.UserService
+	validator             *validator.Validate
+	enforcer              casbin.Enforcer
+	enforcerUtil          rbac.EnforcerUtil
+	canaryAnalysisService pipeline.CanaryAnalysisService
+}
+
+func NewCanaryAnalysisRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	canaryAnalysisService pipeline.CanaryAnalysisService) *CanaryAnalysisRestHandlerImpl {
+	return &CanaryAnalysisRestHandlerImpl{
+		logger:                logger,
+		userAuthService:       userAuthService,
+		validator:             validator,
+		enforcer:              enforcer,
+		enforcerUtil:          enforcerUtil,
+		canaryAnalysisService: canaryAnalysisService,
+	}
+}
+
+func (handler *CanaryAnalysisRestHandlerImpl) GetConfig(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.canaryAnalysisService.GetConfig(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetConfig", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CanaryAnalysisRestHandlerImpl) SaveConfig(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean pipeline.CanaryAnalysisConfigDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SaveConfig", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, bean.PipelineId, casbin.ActionUpdate); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.canaryAnalysisService.SaveConfig(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CanaryAnalysisRestHandlerImpl) GetRuns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.canaryAnalysisService.GetRuns(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetRuns", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
@@ -0,0 +1,195 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type CdFanOutRestHandler interface {
+	GetConfig(w http.ResponseWriter, r *http.Request)
+	SaveConfig(w http.ResponseWriter, r *http.Request)
+	GetLatestExecution(w http.ResponseWriter, r *http.Request)
+	ResumeExecution(w http.ResponseWriter, r *http.Request)
+}
+
+type CdFanOutRestHandlerImpl struct {
+	logger          *zap.SugaredLogger
+	userAuthService user.UserService
+	validator       *validator.Validate
+	enforcer        casbin.Enforcer
+	enforcerUtil    rbac.EnforcerUtil
+	cdFanOutService pipeline.CdFanOutService
+}
+
+func NewCdFanOutRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	cdFanOutService pipeline.CdFanOutService) *CdFanOutRestHandlerImpl {
+	return &CdFanOutRestHandlerImpl{
+		logger:          logger,
+		userAuthService: userAuthService,
+		validator:       validator,
+		enforcer:        enforcer,
+		enforcerUtil:    enforcerUtil,
+		cdFanOutService: cdFanOutService,
+	}
+}
+
+func (handler *CdFanOutRestHandlerImpl) GetConfig(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.cdFanOutService.GetConfig(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetConfig", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CdFanOutRestHandlerImpl) SaveConfig(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean pipeline.CdFanOutConfigDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SaveConfig", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	cdPipeline, err := handler.enforcerUtil.CheckCdPipelineAuth(token, bean.PipelineId, casbin.ActionUpdate)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	for _, target := range bean.Targets {
+		object := handler.enforcerUtil.GetEnvRBACNameByAppId(cdPipeline.AppId, target.EnvironmentId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionCreate, object); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	// targets left out of the request are removed along with their deployed apps
+	existingConfig, err := handler.cdFanOutService.GetConfig(bean.PipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetConfig", "err", err, "pipelineId", bean.PipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	requestedEnvs := make(map[int]bool)
+	for _, target := range bean.Targets {
+		requestedEnvs[target.EnvironmentId] = true
+	}
+	for _, target := range existingConfig.Targets {
+		if requestedEnvs[target.EnvironmentId] {
+			continue
+		}
+		object := handler.enforcerUtil.GetEnvRBACNameByAppId(cdPipeline.AppId, target.EnvironmentId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionDelete, object); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	res, err := handler.cdFanOutService.SaveConfig(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CdFanOutRestHandlerImpl) GetLatestExecution(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.cdFanOutService.GetLatestExecution(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetLatestExecution", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CdFanOutRestHandlerImpl) ResumeExecution(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	cdPipeline, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionTrigger)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	envIds, err := handler.cdFanOutService.GetNextWaveEnvironmentIds(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetNextWaveEnvironmentIds", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	for _, envId := range envIds {
+		object := handler.enforcerUtil.GetEnvRBACNameByAppId(cdPipeline.AppId, envId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionTrigger, object); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	res, err := handler.cdFanOutService.ResumeExecution(pipelineId, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, ResumeExecution", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
@@ -0,0 +1,140 @@
+package restHandler
+
+import (
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type ConfigDriftRestHandler interface {
+	GetLatestScan(w http.ResponseWriter, r *http.Request)
+	GetScanHistory(w http.ResponseWriter, r *http.Request)
+	ScanPipeline(w http.ResponseWriter, r *http.Request)
+	GetLatestScansByAppId(w http.ResponseWriter, r *http.Request)
+}
+
+type ConfigDriftRestHandlerImpl struct {
+	logger             *zap.SugaredLogger
+	userAuthService    user.UserService
+	enforcer           casbin.Enforcer
+	enforcerUtil       rbac.EnforcerUtil
+	configDriftService pipeline.ConfigDriftService
+}
+
+func NewConfigDriftRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	configDriftService pipeline.ConfigDriftService) *ConfigDriftRestHandlerImpl {
+	return &ConfigDriftRestHandlerImpl{
+		logger:             logger,
+		userAuthService:    userAuthService,
+		enforcer:           enforcer,
+		enforcerUtil:       enforcerUtil,
+		configDriftService: configDriftService,
+	}
+}
+
+func (handler *ConfigDriftRestHandlerImpl) GetLatestScan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.configDriftService.GetLatestScan(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetLatestScan", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ConfigDriftRestHandlerImpl) GetScanHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.configDriftService.GetScanHistory(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetScanHistory", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ConfigDriftRestHandlerImpl) ScanPipeline(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.configDriftService.ScanPipeline(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, ScanPipeline", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ConfigDriftRestHandlerImpl) GetLatestScansByAppId(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	scans, err := handler.configDriftService.GetLatestScansByAppId(appId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetLatestScansByAppId", "err", err, "appId", appId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	res := make([]*pipeline.ConfigDriftScanDto, 0, len(scans))
+	for _, scan := range scans {
+		object = handler.enforcerUtil.GetEnvRBACNameByAppId(appId, scan.EnvironmentId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionGet, object); ok {
+			res = append(res, scan)
+		}
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
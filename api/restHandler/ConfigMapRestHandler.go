@@ -591,7 +591,7 @@ func (handler ConfigMapRestHandlerImpl) CSGlobalFetchForEdit(w http.ResponseWrit
 	}
 	//RBAC END
 
-	res, err := handler.configMapService.CSGlobalFetchForEdit(name, id)
+	res, err := handler.configMapService.CSGlobalFetchForEdit(name, id, userId)
 	if err != nil {
 		handler.Logger.Errorw("service err, CSGlobalFetchForEdit", "err", err, "appId", appId, "id", id, "name", name)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -642,7 +642,7 @@ func (handler ConfigMapRestHandlerImpl) CSEnvironmentFetchForEdit(w http.Respons
 	}
 	//RBAC END
 
-	res, err := handler.configMapService.CSEnvironmentFetchForEdit(name, id, appId, envId)
+	res, err := handler.configMapService.CSEnvironmentFetchForEdit(name, id, appId, envId, userId)
 	if err != nil {
 		handler.Logger.Errorw("service err, CSEnvironmentFetchForEdit", "err", err, "appId", appId, "envId", envId, "id", id)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -209,7 +209,7 @@ func (handler CoreAppRestHandlerImpl) GetAppAllDetail(w http.ResponseWriter, r *
 	//get/build global config maps ends
 
 	//get/build global secrets starts
-	globalSecretsResp, err, statusCode := handler.buildAppGlobalSecrets(appId)
+	globalSecretsResp, err, statusCode := handler.buildAppGlobalSecrets(appId, userId)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, statusCode)
 		return
@@ -217,7 +217,7 @@ func (handler CoreAppRestHandlerImpl) GetAppAllDetail(w http.ResponseWriter, r *
 	//get/build global secrets ends
 
 	//get/build environment override starts
-	environmentOverrides, err, statusCode := handler.buildEnvironmentOverrides(appId, token)
+	environmentOverrides, err, statusCode := handler.buildEnvironmentOverrides(appId, token, userId)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, statusCode)
 		return
@@ -877,7 +877,7 @@ func (handler CoreAppRestHandlerImpl) buildAppConfigMaps(appId int, envId int, c
 }
 
 // get/build global secrets
-func (handler CoreAppRestHandlerImpl) buildAppGlobalSecrets(appId int) ([]*appBean.Secret, error, int) {
+func (handler CoreAppRestHandlerImpl) buildAppGlobalSecrets(appId int, userId int32) ([]*appBean.Secret, error, int) {
 	handler.logger.Debugw("Getting app detail - global secret", "appId", appId)
 
 	secretData, err := handler.configMapService.CSGlobalFetch(appId)
@@ -890,7 +890,7 @@ func (handler CoreAppRestHandlerImpl) buildAppGlobalSecrets(appId int) ([]*appBe
 	if secretData != nil && len(secretData.ConfigData) > 0 {
 
 		for _, secretConfig := range secretData.ConfigData {
-			secretDataWithData, err := handler.configMapService.CSGlobalFetchForEdit(secretConfig.Name, secretData.Id)
+			secretDataWithData, err := handler.configMapService.CSGlobalFetchForEdit(secretConfig.Name, secretData.Id, userId)
 			if err != nil {
 				handler.logger.Errorw("service err, CSGlobalFetch-CSGlobalFetchForEdit in GetAppAllDetail", "err", err, "appId", appId)
 				return nil, err, http.StatusInternalServerError
@@ -912,7 +912,7 @@ func (handler CoreAppRestHandlerImpl) buildAppGlobalSecrets(appId int) ([]*appBe
 }
 
 // get/build environment secrets
-func (handler CoreAppRestHandlerImpl) buildAppEnvironmentSecrets(appId int, envId int) ([]*appBean.Secret, error, int) {
+func (handler CoreAppRestHandlerImpl) buildAppEnvironmentSecrets(appId int, envId int, userId int32) ([]*appBean.Secret, error, int) {
 	handler.logger.Debugw("Getting app detail - env secrets", "appId", appId, "envId", envId)
 
 	secretData, err := handler.configMapService.CSEnvironmentFetch(appId, envId)
@@ -925,7 +925,7 @@ func (handler CoreAppRestHandlerImpl) buildAppEnvironmentSecrets(appId int, envI
 	if secretData != nil && len(secretData.ConfigData) > 0 {
 
 		for _, secretConfig := range secretData.ConfigData {
-			secretDataWithData, err := handler.configMapService.CSEnvironmentFetchForEdit(secretConfig.Name, secretData.Id, appId, envId)
+			secretDataWithData, err := handler.configMapService.CSEnvironmentFetchForEdit(secretConfig.Name, secretData.Id, appId, envId, userId)
 			if err != nil {
 				handler.logger.Errorw("service err, CSEnvironmentFetchForEdit in GetAppAllDetail", "err", err, "appId", appId, "envId", envId)
 				return nil, err, http.StatusInternalServerError
@@ -1015,7 +1015,7 @@ func (handler CoreAppRestHandlerImpl) buildAppSecrets(appId int, envId int, secr
 }
 
 // get/build environment overrides
-func (handler CoreAppRestHandlerImpl) buildEnvironmentOverrides(appId int, token string) (map[string]*appBean.EnvironmentOverride, error, int) {
+func (handler CoreAppRestHandlerImpl) buildEnvironmentOverrides(appId int, token string, userId int32) (map[string]*appBean.EnvironmentOverride, error, int) {
 	handler.logger.Debugw("Getting app detail - env override", "appId", appId)
 
 	appEnvironments, err := handler.appListingService.FetchOtherEnvironment(appId)
@@ -1043,7 +1043,7 @@ func (handler CoreAppRestHandlerImpl) buildEnvironmentOverrides(appId int, token
 				return nil, err, statusCode
 			}
 
-			envSecretsResp, err, statusCode := handler.buildAppEnvironmentSecrets(appId, envId)
+			envSecretsResp, err, statusCode := handler.buildAppEnvironmentSecrets(appId, envId, userId)
 			if err != nil {
 				return nil, err, statusCode
 			}
@@ -2083,7 +2083,7 @@ func (handler CoreAppRestHandlerImpl) GetAppWorkflow(w http.ResponseWriter, r *h
 	//get/build app workflows ends
 
 	//get/build environment override starts
-	environmentOverrides, err, statusCode := handler.buildEnvironmentOverrides(appId, token)
+	environmentOverrides, err, statusCode := handler.buildEnvironmentOverrides(appId, token, userId)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, statusCode)
 		return
@@ -2132,7 +2132,7 @@ func (handler CoreAppRestHandlerImpl) GetAppWorkflowAndOverridesSample(w http.Re
 	//get/build app workflows ends
 
 	//get/build environment override starts
-	environmentOverrides, err, statusCode := handler.buildEnvironmentOverrides(appId, token)
+	environmentOverrides, err, statusCode := handler.buildEnvironmentOverrides(appId, token, userId)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, statusCode)
 		return
@@ -0,0 +1,257 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	security2 "github.com/devtron-labs/devtron/internal/sql/repository/security"
+	"github.com/devtron-labs/devtron/pkg/security"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type CveExceptionRestHandler interface {
+	RequestException(w http.ResponseWriter, r *http.Request)
+	ReviewException(w http.ResponseWriter, r *http.Request)
+	RevokeException(w http.ResponseWriter, r *http.Request)
+	GetException(w http.ResponseWriter, r *http.Request)
+	GetExceptions(w http.ResponseWriter, r *http.Request)
+	GetExpiringExceptions(w http.ResponseWriter, r *http.Request)
+}
+
+type CveExceptionRestHandlerImpl struct {
+	logger              *zap.SugaredLogger
+	userAuthService     user.UserService
+	validator           *validator.Validate
+	enforcer            casbin.Enforcer
+	enforcerUtil        rbac.EnforcerUtil
+	cveExceptionService security.CveExceptionService
+}
+
+func NewCveExceptionRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	cveExceptionService security.CveExceptionService) *CveExceptionRestHandlerImpl {
+	return &CveExceptionRestHandlerImpl{
+		logger:              logger,
+		userAuthService:     userAuthService,
+		validator:           validator,
+		enforcer:            enforcer,
+		enforcerUtil:        enforcerUtil,
+		cveExceptionService: cveExceptionService,
+	}
+}
+
+func (handler *CveExceptionRestHandlerImpl) RequestException(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean security.CveExceptionDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, RequestException", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, RequestException", "cveName", bean.CveName, "appId", bean.AppId, "envId", bean.EnvId)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, RequestException", "err", err, "cveName", bean.CveName)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.isAuthorized(token, casbin.ActionCreate, bean.AppId, bean.EnvId) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.cveExceptionService.RequestException(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, RequestException", "err", err, "cveName", bean.CveName)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CveExceptionRestHandlerImpl) ReviewException(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean security.CveExceptionReviewDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, ReviewException", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, ReviewException", "id", bean.Id, "approve", bean.Approve)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, ReviewException", "err", err, "id", bean.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.cveExceptionService.ReviewException(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, ReviewException", "err", err, "id", bean.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CveExceptionRestHandlerImpl) RevokeException(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	exception, err := handler.cveExceptionService.GetException(id)
+	if err != nil {
+		handler.logger.Errorw("service err, RevokeException", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.isAuthorized(token, casbin.ActionDelete, exception.AppId, exception.EnvId) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.cveExceptionService.RevokeException(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, RevokeException", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CveExceptionRestHandlerImpl) GetException(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.cveExceptionService.GetException(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetException", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.isAuthorized(token, casbin.ActionGet, res.AppId, res.EnvId) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *CveExceptionRestHandlerImpl) GetExceptions(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	v := r.URL.Query()
+	filter := &security2.CveExceptionFilter{
+		CveName: v.Get("cveName"),
+		Status:  security2.CveExceptionStatus(v.Get("status")),
+	}
+	if appId := v.Get("appId"); len(appId) > 0 {
+		filter.AppId, err = strconv.Atoi(appId)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if envId := v.Get("envId"); len(envId) > 0 {
+		filter.EnvId, err = strconv.Atoi(envId)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	res, err := handler.cveExceptionService.GetExceptions(filter)
+	if err != nil {
+		handler.logger.Errorw("service err, GetExceptions", "err", err, "filter", filter)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	common.WriteJsonResp(w, nil, handler.filterAuthorized(token, res), http.StatusOK)
+}
+
+func (handler *CveExceptionRestHandlerImpl) GetExpiringExceptions(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	days := 0
+	if daysParam := r.URL.Query().Get("days"); len(daysParam) > 0 {
+		days, err = strconv.Atoi(daysParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	res, err := handler.cveExceptionService.GetExpiringExceptions(days)
+	if err != nil {
+		handler.logger.Errorw("service err, GetExpiringExceptions", "err", err, "days", days)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	common.WriteJsonResp(w, nil, handler.filterAuthorized(token, res), http.StatusOK)
+}
+
+func (handler *CveExceptionRestHandlerImpl) isAuthorized(token string, action string, appId int, envId int) bool {
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, object); !ok {
+		return false
+	}
+	object = handler.enforcerUtil.GetEnvRBACNameByAppId(appId, envId)
+	return handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, object)
+}
+
+func (handler *CveExceptionRestHandlerImpl) filterAuthorized(token string, exceptions []*security.CveExceptionDto) []*security.CveExceptionDto {
+	authorizedExceptions := make([]*security.CveExceptionDto, 0)
+	for _, exception := range exceptions {
+		if handler.isAuthorized(token, casbin.ActionGet, exception.AppId, exception.EnvId) {
+			authorizedExceptions = append(authorizedExceptions, exception)
+		}
+	}
+	return authorizedExceptions
+}
@@ -0,0 +1,249 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type DeploymentApprovalRestHandler interface {
+	GetApprovalConfig(w http.ResponseWriter, r *http.Request)
+	SaveApprovalConfig(w http.ResponseWriter, r *http.Request)
+	RaiseApprovalRequest(w http.ResponseWriter, r *http.Request)
+	GetApprovalRequests(w http.ResponseWriter, r *http.Request)
+	ActOnApprovalRequest(w http.ResponseWriter, r *http.Request)
+	CancelApprovalRequest(w http.ResponseWriter, r *http.Request)
+}
+
+// approvalConfigAction is only held by roles allowed every action on the app and env, i.e. admins and managers
+const approvalConfigAction = "*"
+
+type DeploymentApprovalRestHandlerImpl struct {
+	logger                    *zap.SugaredLogger
+	userAuthService           user.UserService
+	validator                 *validator.Validate
+	enforcer                  casbin.Enforcer
+	enforcerUtil              rbac.EnforcerUtil
+	deploymentApprovalService pipeline.DeploymentApprovalService
+}
+
+func NewDeploymentApprovalRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	deploymentApprovalService pipeline.DeploymentApprovalService) *DeploymentApprovalRestHandlerImpl {
+	return &DeploymentApprovalRestHandlerImpl{
+		logger:                    logger,
+		userAuthService:           userAuthService,
+		validator:                 validator,
+		enforcer:                  enforcer,
+		enforcerUtil:              enforcerUtil,
+		deploymentApprovalService: deploymentApprovalService,
+	}
+}
+
+func (handler *DeploymentApprovalRestHandlerImpl) GetApprovalConfig(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.deploymentApprovalService.GetApprovalConfig(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetApprovalConfig", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentApprovalRestHandlerImpl) SaveApprovalConfig(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean pipeline.DeploymentApprovalConfigDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveApprovalConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SaveApprovalConfig", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveApprovalConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	// approvers gate who can deploy, so only admins and managers of the app and env can change them
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, bean.PipelineId, approvalConfigAction); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.deploymentApprovalService.SaveApprovalConfig(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveApprovalConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentApprovalRestHandlerImpl) RaiseApprovalRequest(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean pipeline.DeploymentApprovalRequestDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, RaiseApprovalRequest", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, RaiseApprovalRequest", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, RaiseApprovalRequest", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, bean.PipelineId, casbin.ActionTrigger); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.deploymentApprovalService.RaiseApprovalRequest(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, RaiseApprovalRequest", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentApprovalRestHandlerImpl) GetApprovalRequests(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, err := handler.enforcerUtil.CheckCdPipelineAuth(token, pipelineId, casbin.ActionGet); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.deploymentApprovalService.GetApprovalRequests(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetApprovalRequests", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentApprovalRestHandlerImpl) ActOnApprovalRequest(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	requestId, err := strconv.Atoi(vars["requestId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var bean pipeline.DeploymentApprovalActionDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, ActOnApprovalRequest", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.ApprovalRequestId = requestId
+	bean.UserId = userId
+	handler.logger.Infow("request payload, ActOnApprovalRequest", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, ActOnApprovalRequest", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	approvalRequest, err := handler.deploymentApprovalService.GetApprovalRequestById(requestId)
+	if err != nil {
+		handler.logger.Errorw("service err, ActOnApprovalRequest", "err", err, "requestId", requestId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcerUtil.CheckAppEnvAuth(token, approvalRequest.AppId, approvalRequest.EnvId, casbin.ActionTrigger); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.deploymentApprovalService.ActOnApprovalRequest(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, ActOnApprovalRequest", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentApprovalRestHandlerImpl) CancelApprovalRequest(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	requestId, err := strconv.Atoi(vars["requestId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	approvalRequest, err := handler.deploymentApprovalService.GetApprovalRequestById(requestId)
+	if err != nil {
+		handler.logger.Errorw("service err, CancelApprovalRequest", "err", err, "requestId", requestId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcerUtil.CheckAppEnvAuth(token, approvalRequest.AppId, approvalRequest.EnvId, casbin.ActionTrigger); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.deploymentApprovalService.CancelApprovalRequest(requestId, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, CancelApprovalRequest", "err", err, "requestId", requestId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
@@ -0,0 +1,205 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type DeploymentWindowRestHandler interface {
+	SavePolicy(w http.ResponseWriter, r *http.Request)
+	GetAllPolicies(w http.ResponseWriter, r *http.Request)
+	GetPolicy(w http.ResponseWriter, r *http.Request)
+	DeletePolicy(w http.ResponseWriter, r *http.Request)
+	GetOverrideAudits(w http.ResponseWriter, r *http.Request)
+	GetPipelineWindowState(w http.ResponseWriter, r *http.Request)
+}
+
+type DeploymentWindowRestHandlerImpl struct {
+	logger                  *zap.SugaredLogger
+	userAuthService         user.UserService
+	validator               *validator.Validate
+	enforcer                casbin.Enforcer
+	enforcerUtil            rbac.EnforcerUtil
+	pipelineRepository      pipelineConfig.PipelineRepository
+	deploymentWindowService pipeline.DeploymentWindowService
+}
+
+func NewDeploymentWindowRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	deploymentWindowService pipeline.DeploymentWindowService) *DeploymentWindowRestHandlerImpl {
+	return &DeploymentWindowRestHandlerImpl{
+		logger:                  logger,
+		userAuthService:         userAuthService,
+		validator:               validator,
+		enforcer:                enforcer,
+		enforcerUtil:            enforcerUtil,
+		pipelineRepository:      pipelineRepository,
+		deploymentWindowService: deploymentWindowService,
+	}
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) SavePolicy(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean pipeline.DeploymentWindowPolicyDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SavePolicy", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	res, err := handler.deploymentWindowService.CreateOrUpdatePolicy(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SavePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) GetAllPolicies(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.deploymentWindowService.GetAllPolicies()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllPolicies", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	policyId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.deploymentWindowService.GetPolicy(policyId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPolicy", "err", err, "policyId", policyId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	policyId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	err = handler.deploymentWindowService.DeletePolicy(policyId, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeletePolicy", "err", err, "policyId", policyId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, policyId, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) GetOverrideAudits(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	policyId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.deploymentWindowService.GetOverrideAudits(policyId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetOverrideAudits", "err", err, "policyId", policyId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) GetPipelineWindowState(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	cdPipeline, err := handler.pipelineRepository.FindById(pipelineId)
+	if err != nil {
+		handler.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(cdPipeline.AppId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.deploymentWindowService.GetDeploymentWindowState(cdPipeline)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPipelineWindowState", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
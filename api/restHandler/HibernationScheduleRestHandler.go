@@ -0,0 +1,206 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/bulkAction"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type HibernationScheduleRestHandler interface {
+	SaveSchedule(w http.ResponseWriter, r *http.Request)
+	GetAllSchedules(w http.ResponseWriter, r *http.Request)
+	GetSchedule(w http.ResponseWriter, r *http.Request)
+	DeleteSchedule(w http.ResponseWriter, r *http.Request)
+	SkipSchedule(w http.ResponseWriter, r *http.Request)
+	GetRuns(w http.ResponseWriter, r *http.Request)
+}
+
+type HibernationScheduleRestHandlerImpl struct {
+	logger                     *zap.SugaredLogger
+	userAuthService            user.UserService
+	validator                  *validator.Validate
+	enforcer                   casbin.Enforcer
+	hibernationScheduleService bulkAction.HibernationScheduleService
+}
+
+func NewHibernationScheduleRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	hibernationScheduleService bulkAction.HibernationScheduleService) *HibernationScheduleRestHandlerImpl {
+	return &HibernationScheduleRestHandlerImpl{
+		logger:                     logger,
+		userAuthService:            userAuthService,
+		validator:                  validator,
+		enforcer:                   enforcer,
+		hibernationScheduleService: hibernationScheduleService,
+	}
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) SaveSchedule(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean bulkAction.HibernationScheduleDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SaveSchedule", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	res, err := handler.hibernationScheduleService.CreateOrUpdateSchedule(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) GetAllSchedules(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.hibernationScheduleService.GetAllSchedules()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllSchedules", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	scheduleId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.hibernationScheduleService.GetSchedule(scheduleId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetSchedule", "err", err, "scheduleId", scheduleId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	scheduleId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	err = handler.hibernationScheduleService.DeleteSchedule(scheduleId, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteSchedule", "err", err, "scheduleId", scheduleId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, scheduleId, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) SkipSchedule(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	scheduleId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var bean bulkAction.HibernationScheduleSkipRequest
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SkipSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.ScheduleId = scheduleId
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SkipSchedule", "payload", bean)
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.hibernationScheduleService.SkipSchedule(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SkipSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) GetRuns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	scheduleId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.hibernationScheduleService.GetRuns(scheduleId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetRuns", "err", err, "scheduleId", scheduleId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
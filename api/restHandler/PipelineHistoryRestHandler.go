@@ -1,8 +1,10 @@
 package restHandler
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
 	history2 "github.com/devtron-labs/devtron/pkg/pipeline/history"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
@@ -19,6 +21,8 @@ type PipelineHistoryRestHandler interface {
 	FetchDeployedHistoryComponentDetail(w http.ResponseWriter, r *http.Request)
 	GetAllDeployedConfigurationHistoryForLatestWfrIdForPipeline(w http.ResponseWriter, r *http.Request)
 	GetAllDeployedConfigurationHistoryForSpecificWfrIdForPipeline(w http.ResponseWriter, r *http.Request)
+	CompareConfigurations(w http.ResponseWriter, r *http.Request)
+	RestoreConfigurationSnapshot(w http.ResponseWriter, r *http.Request)
 }
 
 type PipelineHistoryRestHandlerImpl struct {
@@ -32,6 +36,8 @@ type PipelineHistoryRestHandlerImpl struct {
 	prePostCdScriptHistoryService       history2.PrePostCdScriptHistoryService
 	enforcerUtil                        rbac.EnforcerUtil
 	deployedConfigurationHistoryService history2.DeployedConfigurationHistoryService
+	configComparisonService             history2.ConfigComparisonService
+	configSnapshotRestoreService        pipeline.ConfigSnapshotRestoreService
 }
 
 func NewPipelineHistoryRestHandlerImpl(logger *zap.SugaredLogger, userAuthService user.UserService,
@@ -41,7 +47,9 @@ func NewPipelineHistoryRestHandlerImpl(logger *zap.SugaredLogger, userAuthServic
 	prePostCiScriptHistoryService history2.PrePostCiScriptHistoryService,
 	prePostCdScriptHistoryService history2.PrePostCdScriptHistoryService,
 	enforcerUtil rbac.EnforcerUtil,
-	deployedConfigurationHistoryService history2.DeployedConfigurationHistoryService) *PipelineHistoryRestHandlerImpl {
+	deployedConfigurationHistoryService history2.DeployedConfigurationHistoryService,
+	configComparisonService history2.ConfigComparisonService,
+	configSnapshotRestoreService pipeline.ConfigSnapshotRestoreService) *PipelineHistoryRestHandlerImpl {
 	return &PipelineHistoryRestHandlerImpl{
 		logger:                              logger,
 		userAuthService:                     userAuthService,
@@ -53,6 +61,8 @@ func NewPipelineHistoryRestHandlerImpl(logger *zap.SugaredLogger, userAuthServic
 		prePostCiScriptHistoryService:       prePostCiScriptHistoryService,
 		enforcerUtil:                        enforcerUtil,
 		deployedConfigurationHistoryService: deployedConfigurationHistoryService,
+		configComparisonService:             configComparisonService,
+		configSnapshotRestoreService:        configSnapshotRestoreService,
 	}
 }
 
@@ -304,3 +314,130 @@ func (handler *PipelineHistoryRestHandlerImpl) GetAllDeployedConfigurationHistor
 	}
 	common.WriteJsonResp(w, err, res, http.StatusOK)
 }
+
+func (handler *PipelineHistoryRestHandlerImpl) CompareConfigurations(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		handler.logger.Errorw("request err, CompareConfigurations", "err", err, "appId", appId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var request history2.ConfigCompareRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil || request.Left == nil || request.Right == nil {
+		handler.logger.Errorw("request err, CompareConfigurations", "err", err, "payload", request)
+		common.WriteJsonResp(w, fmt.Errorf("left and right configurations are required"), nil, http.StatusBadRequest)
+		return
+	}
+	request.AppId = appId
+	handler.logger.Debugw("request payload, CompareConfigurations", "payload", request)
+
+	//RBAC START
+	token := r.Header.Get("token")
+	resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, resourceName); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	for _, envId := range []int{request.Left.EnvironmentId, request.Right.EnvironmentId} {
+		if envId == 0 {
+			continue
+		}
+		envObject := handler.enforcerUtil.GetEnvRBACNameByAppId(appId, envId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionGet, envObject); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	//RBAC END
+	//checking if user has admin access
+	request.UserHasAdminAccess = handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, resourceName)
+	res, err := handler.configComparisonService.CompareConfigurations(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, CompareConfigurations", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *PipelineHistoryRestHandlerImpl) RestoreConfigurationSnapshot(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		handler.logger.Errorw("request err, RestoreConfigurationSnapshot", "err", err, "appId", appId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		handler.logger.Errorw("request err, RestoreConfigurationSnapshot", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	wfrId, err := strconv.Atoi(vars["wfrId"])
+	if err != nil {
+		handler.logger.Errorw("request err, RestoreConfigurationSnapshot", "err", err, "wfrId", wfrId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	deploy := false
+	if deployParam := r.URL.Query().Get("deploy"); len(deployParam) > 0 {
+		deploy, err = strconv.ParseBool(deployParam)
+		if err != nil {
+			handler.logger.Errorw("request err, RestoreConfigurationSnapshot", "err", err, "deploy", deployParam)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	request := &pipeline.ConfigSnapshotRestoreRequest{
+		AppId:      appId,
+		PipelineId: pipelineId,
+		WfrId:      wfrId,
+		Deploy:     deploy,
+		UserId:     userId,
+	}
+	handler.logger.Infow("request payload, RestoreConfigurationSnapshot", "payload", request)
+
+	//RBAC START
+	token := r.Header.Get("token")
+	resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	envObject := handler.enforcerUtil.GetAppRBACByAppIdAndPipelineId(appId, pipelineId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, resourceName); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionUpdate, envObject); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	if deploy {
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionTrigger, resourceName); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionTrigger, envObject); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	//RBAC END
+	res, err := handler.configSnapshotRestoreService.RestoreSnapshot(request, r.Context())
+	if err != nil {
+		handler.logger.Errorw("service err, RestoreConfigurationSnapshot", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
@@ -0,0 +1,198 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/security"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type SbomRestHandler interface {
+	UploadSbom(w http.ResponseWriter, r *http.Request)
+	DownloadSbom(w http.ResponseWriter, r *http.Request)
+	SearchDeployedPackages(w http.ResponseWriter, r *http.Request)
+}
+
+type SbomRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userAuthService      user.UserService
+	validator            *validator.Validate
+	enforcer             casbin.Enforcer
+	enforcerUtil         rbac.EnforcerUtil
+	sbomService          pipeline.SbomService
+	ciArtifactRepository repository.CiArtifactRepository
+	ciPipelineRepository pipelineConfig.CiPipelineRepository
+}
+
+func NewSbomRestHandlerImpl(logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	sbomService pipeline.SbomService,
+	ciArtifactRepository repository.CiArtifactRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository) *SbomRestHandlerImpl {
+	return &SbomRestHandlerImpl{
+		logger:               logger,
+		userAuthService:      userAuthService,
+		validator:            validator,
+		enforcer:             enforcer,
+		enforcerUtil:         enforcerUtil,
+		sbomService:          sbomService,
+		ciArtifactRepository: ciArtifactRepository,
+		ciPipelineRepository: ciPipelineRepository,
+	}
+}
+
+func (handler *SbomRestHandlerImpl) UploadSbom(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	artifactId, err := strconv.Atoi(mux.Vars(r)["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	document, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		handler.logger.Errorw("request err, UploadSbom", "err", err, "artifactId", artifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	appId, err := handler.getAppIdByArtifactId(artifactId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.sbomService.SaveSbom(artifactId, document, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, UploadSbom", "err", err, "artifactId", artifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *SbomRestHandlerImpl) DownloadSbom(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	artifactId, err := strconv.Atoi(mux.Vars(r)["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	appId, err := handler.getAppIdByArtifactId(artifactId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	sbom, err := handler.sbomService.GetSbom(artifactId)
+	if err != nil {
+		handler.logger.Errorw("service err, DownloadSbom", "err", err, "artifactId", artifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=sbom-"+strconv.Itoa(artifactId)+".json")
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write([]byte(sbom.Document))
+	if err != nil {
+		handler.logger.Errorw("error in writing sbom", "err", err, "artifactId", artifactId)
+	}
+}
+
+func (handler *SbomRestHandlerImpl) SearchDeployedPackages(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request security.SbomPackageSearchRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, SearchDeployedPackages", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, SearchDeployedPackages", "err", err, "request", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// packages are paged after the rbac filter, so that unauthorized rows do not shrink or skip a page
+	searchRequest := request
+	searchRequest.Offset = 0
+	searchRequest.Size = 0
+	packages, err := handler.sbomService.SearchDeployedPackages(&searchRequest)
+	if err != nil {
+		handler.logger.Errorw("service err, SearchDeployedPackages", "err", err, "request", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	//RBAC
+	token := r.Header.Get("token")
+	authorizedPackages := make([]*security.DeployedSbomPackage, 0)
+	for _, item := range packages {
+		object := handler.enforcerUtil.GetAppRBACNameByAppId(item.AppId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+			continue
+		}
+		object = handler.enforcerUtil.GetEnvRBACNameByAppId(item.AppId, item.EnvId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionGet, object); ok {
+			authorizedPackages = append(authorizedPackages, item)
+		}
+	}
+	//RBAC
+	if request.Offset > len(authorizedPackages) {
+		request.Offset = len(authorizedPackages)
+	}
+	authorizedPackages = authorizedPackages[request.Offset:]
+	if request.Size > 0 && request.Size < len(authorizedPackages) {
+		authorizedPackages = authorizedPackages[:request.Size]
+	}
+	common.WriteJsonResp(w, nil, authorizedPackages, http.StatusOK)
+}
+
+func (handler *SbomRestHandlerImpl) getAppIdByArtifactId(artifactId int) (int, error) {
+	artifact, err := handler.ciArtifactRepository.Get(artifactId)
+	if err != nil {
+		handler.logger.Errorw("error in getting ci artifact", "err", err, "artifactId", artifactId)
+		return 0, err
+	}
+	ciPipeline, err := handler.ciPipelineRepository.FindById(artifact.PipelineId)
+	if err != nil {
+		handler.logger.Errorw("error in getting ci pipeline", "err", err, "ciPipelineId", artifact.PipelineId)
+		return 0, err
+	}
+	return ciPipeline.AppId, nil
+}
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ArtifactPromotionRouter interface {
+	initArtifactPromotionRouter(promotionRouter *mux.Router)
+}
+
+type ArtifactPromotionRouterImpl struct {
+	restHandler restHandler.ArtifactPromotionRestHandler
+}
+
+func NewArtifactPromotionRouterImpl(restHandler restHandler.ArtifactPromotionRestHandler) *ArtifactPromotionRouterImpl {
+	return &ArtifactPromotionRouterImpl{restHandler: restHandler}
+}
+
+func (router ArtifactPromotionRouterImpl) initArtifactPromotionRouter(promotionRouter *mux.Router) {
+	promotionRouter.Path("").
+		HandlerFunc(router.restHandler.PromoteArtifact).Methods("POST")
+	promotionRouter.Path("/check").
+		HandlerFunc(router.restHandler.CheckPromotion).Methods("POST")
+	promotionRouter.Path("/policy/{pipelineId}").
+		HandlerFunc(router.restHandler.GetPolicy).Methods("GET")
+	promotionRouter.Path("/policy").
+		HandlerFunc(router.restHandler.SavePolicy).Methods("POST")
+	promotionRouter.Path("/audit/{pipelineId}").
+		HandlerFunc(router.restHandler.GetAudits).Methods("GET")
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type AutoRollbackRouter interface {
+	initAutoRollbackRouter(autoRollbackRouter *mux.Router)
+}
+
+type AutoRollbackRouterImpl struct {
+	restHandler restHandler.AutoRollbackRestHandler
+}
+
+func NewAutoRollbackRouterImpl(restHandler restHandler.AutoRollbackRestHandler) *AutoRollbackRouterImpl {
+	return &AutoRollbackRouterImpl{restHandler: restHandler}
+}
+
+func (router AutoRollbackRouterImpl) initAutoRollbackRouter(autoRollbackRouter *mux.Router) {
+	autoRollbackRouter.Path("/config/{pipelineId}").
+		HandlerFunc(router.restHandler.GetPolicy).Methods("GET")
+	autoRollbackRouter.Path("/config").
+		HandlerFunc(router.restHandler.SavePolicy).Methods("POST")
+	autoRollbackRouter.Path("/audit/{pipelineId}").
+		HandlerFunc(router.restHandler.GetAudits).Methods("GET")
+}
@@ -28,6 +28,9 @@ func (router BulkUpdateRouterImpl) initBulkUpdateRouter(bulkRouter *mux.Router)
 	bulkRouter.Path("/v1beta1/unhibernate").HandlerFunc(router.restHandler.BulkUnHibernate).Methods("POST")
 	bulkRouter.Path("/v1beta1/deploy").HandlerFunc(router.restHandler.BulkDeploy).Methods("POST")
 	bulkRouter.Path("/v1beta1/build").HandlerFunc(router.restHandler.BulkBuildTrigger).Methods("POST")
+	bulkRouter.Path("/v1beta1/job/{jobId}").HandlerFunc(router.restHandler.GetBulkOperationJob).Methods("GET")
+	bulkRouter.Path("/v1beta1/job/{jobId}/cancel").HandlerFunc(router.restHandler.CancelBulkOperationJob).Methods("POST")
+	bulkRouter.Path("/v1beta1/job/{jobId}/revert").HandlerFunc(router.restHandler.RevertBulkOperationJob).Methods("POST")
 	bulkRouter.Path("/v1beta1/cd-pipeline").HandlerFunc(router.restHandler.HandleCdPipelineBulkAction).Methods("POST")
 
 }
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type CanaryAnalysisRouter interface {
+	initCanaryAnalysisRouter(canaryAnalysisRouter *mux.Router)
+}
+
+type CanaryAnalysisRouterImpl struct {
+	restHandler restHandler.CanaryAnalysisRestHandler
+}
+
+func NewCanaryAnalysisRouterImpl(restHandler restHandler.CanaryAnalysisRestHandler) *CanaryAnalysisRouterImpl {
+	return &CanaryAnalysisRouterImpl{restHandler: restHandler}
+}
+
+func (router CanaryAnalysisRouterImpl) initCanaryAnalysisRouter(canaryAnalysisRouter *mux.Router) {
+	canaryAnalysisRouter.Path("/config/{pipelineId}").
+		HandlerFunc(router.restHandler.GetConfig).Methods("GET")
+	canaryAnalysisRouter.Path("/config").
+		HandlerFunc(router.restHandler.SaveConfig).Methods("POST")
+	canaryAnalysisRouter.Path("/runs/{pipelineId}").
+		HandlerFunc(router.restHandler.GetRuns).Methods("GET")
+}
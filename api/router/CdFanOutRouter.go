@@ -0,0 +1,29 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type CdFanOutRouter interface {
+	initCdFanOutRouter(cdFanOutRouter *mux.Router)
+}
+
+type CdFanOutRouterImpl struct {
+	restHandler restHandler.CdFanOutRestHandler
+}
+
+func NewCdFanOutRouterImpl(restHandler restHandler.CdFanOutRestHandler) *CdFanOutRouterImpl {
+	return &CdFanOutRouterImpl{restHandler: restHandler}
+}
+
+func (router CdFanOutRouterImpl) initCdFanOutRouter(cdFanOutRouter *mux.Router) {
+	cdFanOutRouter.Path("/config/{pipelineId}").
+		HandlerFunc(router.restHandler.GetConfig).Methods("GET")
+	cdFanOutRouter.Path("/config").
+		HandlerFunc(router.restHandler.SaveConfig).Methods("POST")
+	cdFanOutRouter.Path("/execution/{pipelineId}").
+		HandlerFunc(router.restHandler.GetLatestExecution).Methods("GET")
+	cdFanOutRouter.Path("/execution/{pipelineId}/resume").
+		HandlerFunc(router.restHandler.ResumeExecution).Methods("POST")
+}
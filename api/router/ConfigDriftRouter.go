@@ -0,0 +1,29 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ConfigDriftRouter interface {
+	initConfigDriftRouter(configDriftRouter *mux.Router)
+}
+
+type ConfigDriftRouterImpl struct {
+	restHandler restHandler.ConfigDriftRestHandler
+}
+
+func NewConfigDriftRouterImpl(restHandler restHandler.ConfigDriftRestHandler) *ConfigDriftRouterImpl {
+	return &ConfigDriftRouterImpl{restHandler: restHandler}
+}
+
+func (router ConfigDriftRouterImpl) initConfigDriftRouter(configDriftRouter *mux.Router) {
+	configDriftRouter.Path("/app/{appId}").
+		HandlerFunc(router.restHandler.GetLatestScansByAppId).Methods("GET")
+	configDriftRouter.Path("/{pipelineId}").
+		HandlerFunc(router.restHandler.GetLatestScan).Methods("GET")
+	configDriftRouter.Path("/{pipelineId}/history").
+		HandlerFunc(router.restHandler.GetScanHistory).Methods("GET")
+	configDriftRouter.Path("/{pipelineId}/scan").
+		HandlerFunc(router.restHandler.ScanPipeline).Methods("POST")
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type CveExceptionRouter interface {
+	initCveExceptionRouter(cveExceptionRouter *mux.Router)
+}
+
+type CveExceptionRouterImpl struct {
+	restHandler restHandler.CveExceptionRestHandler
+}
+
+func NewCveExceptionRouterImpl(restHandler restHandler.CveExceptionRestHandler) *CveExceptionRouterImpl {
+	return &CveExceptionRouterImpl{restHandler: restHandler}
+}
+
+func (router CveExceptionRouterImpl) initCveExceptionRouter(cveExceptionRouter *mux.Router) {
+	cveExceptionRouter.Path("/request").
+		HandlerFunc(router.restHandler.RequestException).Methods("POST")
+	cveExceptionRouter.Path("/review").
+		HandlerFunc(router.restHandler.ReviewException).Methods("POST")
+	cveExceptionRouter.Path("/revoke/{id}").
+		HandlerFunc(router.restHandler.RevokeException).Methods("POST")
+	cveExceptionRouter.Path("/list").
+		HandlerFunc(router.restHandler.GetExceptions).Methods("GET")
+	cveExceptionRouter.Path("/expiring").
+		HandlerFunc(router.restHandler.GetExpiringExceptions).Methods("GET")
+	cveExceptionRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetException).Methods("GET")
+}
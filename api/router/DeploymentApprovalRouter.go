@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type DeploymentApprovalRouter interface {
+	initDeploymentApprovalRouter(approvalRouter *mux.Router)
+}
+
+type DeploymentApprovalRouterImpl struct {
+	restHandler restHandler.DeploymentApprovalRestHandler
+}
+
+func NewDeploymentApprovalRouterImpl(restHandler restHandler.DeploymentApprovalRestHandler) *DeploymentApprovalRouterImpl {
+	return &DeploymentApprovalRouterImpl{restHandler: restHandler}
+}
+
+func (router DeploymentApprovalRouterImpl) initDeploymentApprovalRouter(approvalRouter *mux.Router) {
+	approvalRouter.Path("/config/{pipelineId}").
+		HandlerFunc(router.restHandler.GetApprovalConfig).Methods("GET")
+	approvalRouter.Path("/config").
+		HandlerFunc(router.restHandler.SaveApprovalConfig).Methods("POST")
+	approvalRouter.Path("/request").
+		HandlerFunc(router.restHandler.RaiseApprovalRequest).Methods("POST")
+	approvalRouter.Path("/pipeline/{pipelineId}").
+		HandlerFunc(router.restHandler.GetApprovalRequests).Methods("GET")
+	approvalRouter.Path("/{requestId}/action").
+		HandlerFunc(router.restHandler.ActOnApprovalRequest).Methods("POST")
+	approvalRouter.Path("/{requestId}/cancel").
+		HandlerFunc(router.restHandler.CancelApprovalRequest).Methods("POST")
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type DeploymentWindowRouter interface {
+	initDeploymentWindowRouter(windowRouter *mux.Router)
+}
+
+type DeploymentWindowRouterImpl struct {
+	restHandler restHandler.DeploymentWindowRestHandler
+}
+
+func NewDeploymentWindowRouterImpl(restHandler restHandler.DeploymentWindowRestHandler) *DeploymentWindowRouterImpl {
+	return &DeploymentWindowRouterImpl{restHandler: restHandler}
+}
+
+func (router DeploymentWindowRouterImpl) initDeploymentWindowRouter(windowRouter *mux.Router) {
+	windowRouter.Path("").
+		HandlerFunc(router.restHandler.GetAllPolicies).Methods("GET")
+	windowRouter.Path("").
+		HandlerFunc(router.restHandler.SavePolicy).Methods("POST")
+	windowRouter.Path("/pipeline/{pipelineId}/state").
+		HandlerFunc(router.restHandler.GetPipelineWindowState).Methods("GET")
+	windowRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetPolicy).Methods("GET")
+	windowRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.DeletePolicy).Methods("DELETE")
+	windowRouter.Path("/{id}/override-audit").
+		HandlerFunc(router.restHandler.GetOverrideAudits).Methods("GET")
+}
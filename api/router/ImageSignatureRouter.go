@@ -0,0 +1,35 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ImageSignatureRouter interface {
+	initImageSignatureRouter(imageSignatureRouter *mux.Router)
+}
+
+type ImageSignatureRouterImpl struct {
+	restHandler restHandler.ImageSignatureRestHandler
+}
+
+func NewImageSignatureRouterImpl(restHandler restHandler.ImageSignatureRestHandler) *ImageSignatureRouterImpl {
+	return &ImageSignatureRouterImpl{restHandler: restHandler}
+}
+
+func (router ImageSignatureRouterImpl) initImageSignatureRouter(imageSignatureRouter *mux.Router) {
+	imageSignatureRouter.Path("/key").
+		HandlerFunc(router.restHandler.GetKeys).Methods("GET")
+	imageSignatureRouter.Path("/key").
+		HandlerFunc(router.restHandler.CreateKey).Methods("POST")
+	imageSignatureRouter.Path("/key").
+		HandlerFunc(router.restHandler.UpdateKey).Methods("PUT")
+	imageSignatureRouter.Path("/key/{id}").
+		HandlerFunc(router.restHandler.DeleteKey).Methods("DELETE")
+	imageSignatureRouter.Path("/policy").
+		HandlerFunc(router.restHandler.GetPolicies).Methods("GET")
+	imageSignatureRouter.Path("/policy").
+		HandlerFunc(router.restHandler.SavePolicy).Methods("POST")
+	imageSignatureRouter.Path("/policy/{id}").
+		HandlerFunc(router.restHandler.DeletePolicy).Methods("DELETE")
+}
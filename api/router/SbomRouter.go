@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type SbomRouter interface {
+	initSbomRouter(sbomRouter *mux.Router)
+}
+
+type SbomRouterImpl struct {
+	restHandler restHandler.SbomRestHandler
+}
+
+func NewSbomRouterImpl(restHandler restHandler.SbomRestHandler) *SbomRouterImpl {
+	return &SbomRouterImpl{restHandler: restHandler}
+}
+
+func (router SbomRouterImpl) initSbomRouter(sbomRouter *mux.Router) {
+	sbomRouter.Path("/artifact/{artifactId}").
+		HandlerFunc(router.restHandler.UploadSbom).Methods("POST")
+	sbomRouter.Path("/artifact/{artifactId}/download").
+		HandlerFunc(router.restHandler.DownloadSbom).Methods("GET")
+	sbomRouter.Path("/search").
+		HandlerFunc(router.restHandler.SearchDeployedPackages).Methods("POST")
+}
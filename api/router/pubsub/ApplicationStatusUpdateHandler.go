@@ -22,6 +22,7 @@ import (
 	"time"
 
 	v1alpha12 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
 	"github.com/devtron-labs/devtron/client/pubsub"
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/appStore/deployment/service"
@@ -42,16 +43,19 @@ type ApplicationStatusUpdateHandlerImpl struct {
 	appService          app.AppService
 	workflowDagExecutor pipeline.WorkflowDagExecutor
 	installedAppService service.InstalledAppService
+	autoRollbackService pipeline.AutoRollbackService
 }
 
 func NewApplicationStatusUpdateHandlerImpl(logger *zap.SugaredLogger, pubsubClient *pubsub.PubSubClient, appService app.AppService,
-	workflowDagExecutor pipeline.WorkflowDagExecutor, installedAppService service.InstalledAppService) *ApplicationStatusUpdateHandlerImpl {
+	workflowDagExecutor pipeline.WorkflowDagExecutor, installedAppService service.InstalledAppService,
+	autoRollbackService pipeline.AutoRollbackService) *ApplicationStatusUpdateHandlerImpl {
 	appStatusUpdateHandlerImpl := &ApplicationStatusUpdateHandlerImpl{
 		logger:              logger,
 		pubsubClient:        pubsubClient,
 		appService:          appService,
 		workflowDagExecutor: workflowDagExecutor,
 		installedAppService: installedAppService,
+		autoRollbackService: autoRollbackService,
 	}
 	err := util.AddStream(appStatusUpdateHandlerImpl.pubsubClient.JetStrCtxt, util.KUBEWATCH_STREAM)
 	if err != nil {
@@ -124,6 +128,9 @@ func (impl *ApplicationStatusUpdateHandlerImpl) Subscribe() error {
 				return
 			}
 		}
+		if !isHealthy && newApp.Status.Health.Status == health.HealthStatusDegraded {
+			go impl.autoRollbackService.HandleDegradedArgoApp(newApp.Name)
+		}
 		impl.logger.Debugw("application status update completed", "app", newApp.Name)
 	}, nats.Durable(util.APPLICATION_STATUS_UPDATE_DURABLE), nats.DeliverLast(), nats.ManualAck(), nats.BindStream(util.KUBEWATCH_STREAM))
 
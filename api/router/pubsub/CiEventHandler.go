@@ -50,6 +50,9 @@ type CiCompleteEvent struct {
 	PipelineName     string                      `json:"pipelineName"`
 	DataSource       string                      `json:"dataSource"`
 	MaterialType     string                      `json:"materialType" validate:"required"`
+	Sbom             json.RawMessage             `json:"sbom,omitempty"`
+	Signature        string                      `json:"signature,omitempty"`
+	SignaturePayload string                      `json:"signaturePayload,omitempty"`
 }
 
 func NewCiEventHandlerImpl(logger *zap.SugaredLogger, pubsubClient *pubsub.PubSubClient, webhookService pipeline.WebhookService) *CiEventHandlerImpl {
@@ -86,6 +89,7 @@ func (impl *CiEventHandlerImpl) Subscribe() error {
 		if err != nil {
 			return
 		}
+		req.DevtronCiBuild = true
 		resp, err := impl.webhookService.SaveCiArtifactWebhook(ciCompleteEvent.PipelineId, req)
 		if err != nil {
 			impl.logger.Error(err)
@@ -155,13 +159,16 @@ func (impl *CiEventHandlerImpl) BuildCiArtifactRequest(event CiCompleteEvent) (*
 	}
 
 	request := &pipeline.CiArtifactWebhookRequest{
-		Image:        event.DockerImage,
-		ImageDigest:  event.Digest,
-		DataSource:   event.DataSource,
-		PipelineName: event.PipelineName,
-		MaterialInfo: rawMaterialInfo,
-		UserId:       event.TriggeredBy,
-		WorkflowId:   event.WorkflowId,
+		Image:            event.DockerImage,
+		ImageDigest:      event.Digest,
+		DataSource:       event.DataSource,
+		PipelineName:     event.PipelineName,
+		MaterialInfo:     rawMaterialInfo,
+		UserId:           event.TriggeredBy,
+		WorkflowId:       event.WorkflowId,
+		Sbom:             event.Sbom,
+		Signature:        event.Signature,
+		SignaturePayload: event.SignaturePayload,
 	}
 	return request, nil
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/devtron-labs/devtron/api/apiToken"
 	"github.com/devtron-labs/devtron/api/appStore"
 	appStoreDeployment "github.com/devtron-labs/devtron/api/appStore/deployment"
+	"github.com/devtron-labs/devtron/api/auditLog"
 	"github.com/devtron-labs/devtron/api/chartRepo"
 	"github.com/devtron-labs/devtron/api/cluster"
 	"github.com/devtron-labs/devtron/api/dashboardEvent"
@@ -41,6 +42,7 @@ import (
 	pubsub2 "github.com/devtron-labs/devtron/client/pubsub"
 	"github.com/devtron-labs/devtron/client/telemetry"
 	"github.com/devtron-labs/devtron/pkg/terminal"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	"github.com/devtron-labs/devtron/util"
 	"github.com/devtron-labs/devtron/util/k8s"
 	"github.com/gorilla/mux"
@@ -109,10 +111,35 @@ type MuxRouter struct {
 	moduleRouter                       module.ModuleRouter
 	serverRouter                       server.ServerRouter
 	apiTokenRouter                     apiToken.ApiTokenRouter
+	auditLogRouter                     auditLog.AuditLogRouter
+	auditLogMiddleware                 auditLog.AuditLogMiddleware
+	enforcer                           casbin.Enforcer
+	apiTokenMiddleware                 apiToken.ApiTokenMiddleware
 	helmApplicationStatusUpdateHandler cron.CdApplicationStatusUpdateHandler
 	k8sCapacityRouter                  k8s.K8sCapacityRouter
 	webhookHelmRouter                  webhookHelm.WebhookHelmRouter
 	globalCMCSRouter                   GlobalCMCSRouter
+	deploymentApprovalRouter           DeploymentApprovalRouter
+	deploymentWindowRouter             DeploymentWindowRouter
+	autoRollbackRouter                 AutoRollbackRouter
+	artifactPromotionRouter            ArtifactPromotionRouter
+	ciTriggerCron                      cron.CiTriggerCron
+	hibernationScheduleRouter          HibernationScheduleRouter
+	hibernationScheduleCron            cron.HibernationScheduleCron
+	bulkOperationJobCron               cron.BulkOperationJobCron
+	configDriftRouter                  ConfigDriftRouter
+	configDriftCron                    cron.ConfigDriftCron
+	gitOpsPullRequestCron              cron.GitOpsPullRequestCron
+	cdFanOutRouter                     CdFanOutRouter
+	cdFanOutCron                       cron.CdFanOutCron
+	canaryAnalysisRouter               CanaryAnalysisRouter
+	canaryAnalysisCron                 cron.CanaryAnalysisCron
+	imageSignatureRouter               ImageSignatureRouter
+	sbomRouter                         SbomRouter
+	cveExceptionRouter                 CveExceptionRouter
+	cveExceptionCron                   cron.CveExceptionCron
+	userRoleGrantCron                  cron.UserRoleGrantCron
+	apiTokenExpiryCron                 cron.ApiTokenExpiryCron
 }
 
 func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, PipelineConfigRouter PipelineConfigRouter,
@@ -139,7 +166,20 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 	globalPluginRouter GlobalPluginRouter, moduleRouter module.ModuleRouter,
 	serverRouter server.ServerRouter, apiTokenRouter apiToken.ApiTokenRouter,
 	helmApplicationStatusUpdateHandler cron.CdApplicationStatusUpdateHandler, k8sCapacityRouter k8s.K8sCapacityRouter,
-	webhookHelmRouter webhookHelm.WebhookHelmRouter, globalCMCSRouter GlobalCMCSRouter) *MuxRouter {
+	webhookHelmRouter webhookHelm.WebhookHelmRouter, globalCMCSRouter GlobalCMCSRouter,
+	deploymentApprovalRouter DeploymentApprovalRouter, deploymentWindowRouter DeploymentWindowRouter,
+	autoRollbackRouter AutoRollbackRouter, artifactPromotionRouter ArtifactPromotionRouter,
+	ciTriggerCron cron.CiTriggerCron, hibernationScheduleRouter HibernationScheduleRouter,
+	hibernationScheduleCron cron.HibernationScheduleCron, bulkOperationJobCron cron.BulkOperationJobCron,
+	configDriftRouter ConfigDriftRouter,
+	configDriftCron cron.ConfigDriftCron, gitOpsPullRequestCron cron.GitOpsPullRequestCron,
+	cdFanOutRouter CdFanOutRouter, cdFanOutCron cron.CdFanOutCron,
+	canaryAnalysisRouter CanaryAnalysisRouter, canaryAnalysisCron cron.CanaryAnalysisCron,
+	imageSignatureRouter ImageSignatureRouter, sbomRouter SbomRouter,
+	cveExceptionRouter CveExceptionRouter, cveExceptionCron cron.CveExceptionCron,
+	userRoleGrantCron cron.UserRoleGrantCron, auditLogRouter auditLog.AuditLogRouter,
+	auditLogMiddleware auditLog.AuditLogMiddleware, apiTokenExpiryCron cron.ApiTokenExpiryCron,
+	enforcer casbin.Enforcer, apiTokenMiddleware apiToken.ApiTokenMiddleware) *MuxRouter {
 	r := &MuxRouter{
 		Router:                             mux.NewRouter(),
 		HelmRouter:                         HelmRouter,
@@ -204,6 +244,31 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 		k8sCapacityRouter:                  k8sCapacityRouter,
 		webhookHelmRouter:                  webhookHelmRouter,
 		globalCMCSRouter:                   globalCMCSRouter,
+		deploymentApprovalRouter:           deploymentApprovalRouter,
+		deploymentWindowRouter:             deploymentWindowRouter,
+		autoRollbackRouter:                 autoRollbackRouter,
+		artifactPromotionRouter:            artifactPromotionRouter,
+		ciTriggerCron:                      ciTriggerCron,
+		hibernationScheduleRouter:          hibernationScheduleRouter,
+		hibernationScheduleCron:            hibernationScheduleCron,
+		bulkOperationJobCron:               bulkOperationJobCron,
+		configDriftRouter:                  configDriftRouter,
+		configDriftCron:                    configDriftCron,
+		gitOpsPullRequestCron:              gitOpsPullRequestCron,
+		cdFanOutRouter:                     cdFanOutRouter,
+		cdFanOutCron:                       cdFanOutCron,
+		canaryAnalysisRouter:               canaryAnalysisRouter,
+		canaryAnalysisCron:                 canaryAnalysisCron,
+		imageSignatureRouter:               imageSignatureRouter,
+		sbomRouter:                         sbomRouter,
+		cveExceptionRouter:                 cveExceptionRouter,
+		cveExceptionCron:                   cveExceptionCron,
+		userRoleGrantCron:                  userRoleGrantCron,
+		auditLogRouter:                     auditLogRouter,
+		auditLogMiddleware:                 auditLogMiddleware,
+		apiTokenExpiryCron:                 apiTokenExpiryCron,
+		enforcer:                           enforcer,
+		apiTokenMiddleware:                 apiTokenMiddleware,
 	}
 	return r
 }
@@ -321,6 +386,13 @@ func (r MuxRouter) Init() {
 	policyRouter := r.Router.PathPrefix("/orchestrator/security/policy").Subrouter()
 	r.policyRouter.InitPolicyRouter(policyRouter)
 
+	imageSignatureRouter := r.Router.PathPrefix("/orchestrator/security/image-signature").Subrouter()
+	r.imageSignatureRouter.initImageSignatureRouter(imageSignatureRouter)
+	sbomRouter := r.Router.PathPrefix("/orchestrator/security/sbom").Subrouter()
+	r.sbomRouter.initSbomRouter(sbomRouter)
+	cveExceptionRouter := r.Router.PathPrefix("/orchestrator/security/cve-exception").Subrouter()
+	r.cveExceptionRouter.initCveExceptionRouter(cveExceptionRouter)
+
 	gitOpsRouter := r.Router.PathPrefix("/orchestrator/gitops").Subrouter()
 	r.gitOpsConfigRouter.InitGitOpsConfigRouter(gitOpsRouter)
 
@@ -395,6 +467,15 @@ func (r MuxRouter) Init() {
 	apiTokenRouter := r.Router.PathPrefix("/orchestrator/api-token").Subrouter()
 	r.apiTokenRouter.InitApiTokenRouter(apiTokenRouter)
 
+	// audit log router, mutating requests on every route are recorded by the audit middleware
+	r.Router.Use(r.auditLogMiddleware.Audit)
+	// client ip of api token requests, checked against the ip allow-list of scoped tokens on enforce
+	r.Router.Use(r.enforcer.ClientIpMiddleware)
+	// api tokens which are deleted or rotated past their grace period are rejected on every route
+	r.Router.Use(r.apiTokenMiddleware.RejectInactiveTokens)
+	auditLogRouter := r.Router.PathPrefix("/orchestrator/audit-log").Subrouter()
+	r.auditLogRouter.InitAuditLogRouter(auditLogRouter)
+
 	k8sCapacityApp := r.Router.PathPrefix("/orchestrator/k8s/capacity").Subrouter()
 	r.k8sCapacityRouter.InitK8sCapacityRouter(k8sCapacityApp)
 
@@ -404,4 +485,28 @@ func (r MuxRouter) Init() {
 
 	globalCMCSRouter := r.Router.PathPrefix("/orchestrator/global/cm-cs").Subrouter()
 	r.globalCMCSRouter.initGlobalCMCSRouter(globalCMCSRouter)
+
+	deploymentApprovalRouter := r.Router.PathPrefix("/orchestrator/app/cd-pipeline/approval").Subrouter()
+	r.deploymentApprovalRouter.initDeploymentApprovalRouter(deploymentApprovalRouter)
+
+	deploymentWindowRouter := r.Router.PathPrefix("/orchestrator/deployment-window").Subrouter()
+	r.deploymentWindowRouter.initDeploymentWindowRouter(deploymentWindowRouter)
+
+	autoRollbackRouter := r.Router.PathPrefix("/orchestrator/app/cd-pipeline/auto-rollback").Subrouter()
+	r.autoRollbackRouter.initAutoRollbackRouter(autoRollbackRouter)
+
+	artifactPromotionRouter := r.Router.PathPrefix("/orchestrator/app/cd-pipeline/promote").Subrouter()
+	r.artifactPromotionRouter.initArtifactPromotionRouter(artifactPromotionRouter)
+
+	hibernationScheduleRouter := r.Router.PathPrefix("/orchestrator/hibernation-schedule").Subrouter()
+	r.hibernationScheduleRouter.initHibernationScheduleRouter(hibernationScheduleRouter)
+
+	configDriftRouter := r.Router.PathPrefix("/orchestrator/app/cd-pipeline/config-drift").Subrouter()
+	r.configDriftRouter.initConfigDriftRouter(configDriftRouter)
+
+	cdFanOutRouter := r.Router.PathPrefix("/orchestrator/app/cd-pipeline/fan-out").Subrouter()
+	r.cdFanOutRouter.initCdFanOutRouter(cdFanOutRouter)
+
+	canaryAnalysisRouter := r.Router.PathPrefix("/orchestrator/app/cd-pipeline/canary-analysis").Subrouter()
+	r.canaryAnalysisRouter.initCanaryAnalysisRouter(canaryAnalysisRouter)
 }
@@ -0,0 +1,257 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type UserRbacRestHandler interface {
+	CreateCustomRole(w http.ResponseWriter, r *http.Request)
+	UpdateCustomRole(w http.ResponseWriter, r *http.Request)
+	GetCustomRole(w http.ResponseWriter, r *http.Request)
+	GetAllCustomRoles(w http.ResponseWriter, r *http.Request)
+	DeleteCustomRole(w http.ResponseWriter, r *http.Request)
+	CreateDenyRule(w http.ResponseWriter, r *http.Request)
+	GetDenyRule(w http.ResponseWriter, r *http.Request)
+	GetAllDenyRules(w http.ResponseWriter, r *http.Request)
+	DeleteDenyRule(w http.ResponseWriter, r *http.Request)
+}
+
+type UserRbacRestHandlerImpl struct {
+	logger            *zap.SugaredLogger
+	userService       user.UserService
+	validator         *validator.Validate
+	customRoleService user.CustomRoleService
+	denyRuleService   user.DenyRuleService
+}
+
+func NewUserRbacRestHandlerImpl(logger *zap.SugaredLogger,
+	userService user.UserService,
+	validator *validator.Validate,
+	customRoleService user.CustomRoleService,
+	denyRuleService user.DenyRuleService) *UserRbacRestHandlerImpl {
+	return &UserRbacRestHandlerImpl{
+		logger:            logger,
+		userService:       userService,
+		validator:         validator,
+		customRoleService: customRoleService,
+		denyRuleService:   denyRuleService,
+	}
+}
+
+func (handler UserRbacRestHandlerImpl) CreateCustomRole(w http.ResponseWriter, r *http.Request) {
+	userId, ok := handler.authorizeSuperAdmin(w, r)
+	if !ok {
+		return
+	}
+	var request user.CustomRoleDto
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, CreateCustomRole", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	handler.logger.Infow("request payload, CreateCustomRole", "name", request.Name, "permissions", request.Permissions)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, CreateCustomRole", "err", err, "name", request.Name)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.customRoleService.CreateCustomRole(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, CreateCustomRole", "err", err, "name", request.Name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) UpdateCustomRole(w http.ResponseWriter, r *http.Request) {
+	userId, ok := handler.authorizeSuperAdmin(w, r)
+	if !ok {
+		return
+	}
+	var request user.CustomRoleDto
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, UpdateCustomRole", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	handler.logger.Infow("request payload, UpdateCustomRole", "id", request.Id, "permissions", request.Permissions)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, UpdateCustomRole", "err", err, "id", request.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.customRoleService.UpdateCustomRole(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, UpdateCustomRole", "err", err, "id", request.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) GetCustomRole(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorizeSuperAdmin(w, r); !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.customRoleService.GetCustomRole(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetCustomRole", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) GetAllCustomRoles(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorizeSuperAdmin(w, r); !ok {
+		return
+	}
+	res, err := handler.customRoleService.GetAllCustomRoles()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllCustomRoles", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) DeleteCustomRole(w http.ResponseWriter, r *http.Request) {
+	userId, ok := handler.authorizeSuperAdmin(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.customRoleService.DeleteCustomRole(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteCustomRole", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, true, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) CreateDenyRule(w http.ResponseWriter, r *http.Request) {
+	userId, ok := handler.authorizeSuperAdmin(w, r)
+	if !ok {
+		return
+	}
+	var request user.DenyRuleDto
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, CreateDenyRule", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	handler.logger.Infow("request payload, CreateDenyRule", "subjectType", request.SubjectType, "subject", request.Subject,
+		"resource", request.Resource, "action", request.Action)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, CreateDenyRule", "err", err, "subject", request.Subject)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.denyRuleService.CreateDenyRule(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, CreateDenyRule", "err", err, "subject", request.Subject)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) GetDenyRule(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorizeSuperAdmin(w, r); !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.denyRuleService.GetDenyRule(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetDenyRule", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) GetAllDenyRules(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorizeSuperAdmin(w, r); !ok {
+		return
+	}
+	res, err := handler.denyRuleService.GetAllDenyRules()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllDenyRules", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRbacRestHandlerImpl) DeleteDenyRule(w http.ResponseWriter, r *http.Request) {
+	userId, ok := handler.authorizeSuperAdmin(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.denyRuleService.DeleteDenyRule(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteDenyRule", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, true, http.StatusOK)
+}
+
+// authorizeSuperAdmin writes the error response unless the logged in user is a super admin, custom roles and deny
+// rules apply across teams so only super admins can manage them
+func (handler UserRbacRestHandlerImpl) authorizeSuperAdmin(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return 0, false
+	}
+	isSuperAdmin, err := handler.userService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("error in checking superAdmin access of user", "err", err)
+		common.WriteJsonResp(w, err, "", http.StatusInternalServerError)
+		return 0, false
+	}
+	if !isSuperAdmin {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return 0, false
+	}
+	return userId, true
+}
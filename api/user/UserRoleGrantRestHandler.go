@@ -0,0 +1,276 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type UserRoleGrantRestHandler interface {
+	RequestGrant(w http.ResponseWriter, r *http.Request)
+	ReviewGrant(w http.ResponseWriter, r *http.Request)
+	RevokeGrant(w http.ResponseWriter, r *http.Request)
+	GetGrant(w http.ResponseWriter, r *http.Request)
+	GetGrants(w http.ResponseWriter, r *http.Request)
+	GetGrantAudits(w http.ResponseWriter, r *http.Request)
+}
+
+type UserRoleGrantRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userService          user.UserService
+	validator            *validator.Validate
+	enforcer             casbin.Enforcer
+	userRoleGrantService user.UserRoleGrantService
+}
+
+func NewUserRoleGrantRestHandlerImpl(logger *zap.SugaredLogger,
+	userService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	userRoleGrantService user.UserRoleGrantService) *UserRoleGrantRestHandlerImpl {
+	return &UserRoleGrantRestHandlerImpl{
+		logger:               logger,
+		userService:          userService,
+		validator:            validator,
+		enforcer:             enforcer,
+		userRoleGrantService: userRoleGrantService,
+	}
+}
+
+type userRoleGrantRevokeRequest struct {
+	Comment string `json:"comment"`
+}
+
+func (handler UserRoleGrantRestHandlerImpl) RequestGrant(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request user.UserRoleGrantRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, RequestGrant", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.RequestedBy = userId
+	handler.logger.Infow("request payload, RequestGrant", "userId", request.UserId, "roleFilter", request.RoleFilter, "durationMinutes", request.DurationMinutes)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, RequestGrant", "err", err, "userId", request.UserId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	request.CanGrant = handler.canGrant(token, request.RoleFilter)
+	// without access to assign the role filter a user can only ask elevation for themselves
+	if !request.CanGrant && request.UserId != userId {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	res, err := handler.userRoleGrantService.RequestGrant(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, RequestGrant", "err", err, "userId", request.UserId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRoleGrantRestHandlerImpl) ReviewGrant(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request user.UserRoleGrantReviewRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, ReviewGrant", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.ReviewedBy = userId
+	handler.logger.Infow("request payload, ReviewGrant", "id", request.Id, "approve", request.Approve)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, ReviewGrant", "err", err, "id", request.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	isSuperAdmin, err := handler.userService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("error in checking superAdmin access of user", "err", err)
+		common.WriteJsonResp(w, err, "", http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	res, err := handler.userRoleGrantService.ReviewGrant(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, ReviewGrant", "err", err, "id", request.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRoleGrantRestHandlerImpl) RevokeGrant(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var request userRoleGrantRevokeRequest
+	if r.ContentLength > 0 {
+		err = json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			handler.logger.Errorw("request err, RevokeGrant", "err", err, "id", id)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	grant, err := handler.userRoleGrantService.GetGrant(id)
+	if err != nil {
+		handler.logger.Errorw("service err, RevokeGrant", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if grant.UserId != userId && !handler.canGrant(token, grant.RoleFilter) {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	res, err := handler.userRoleGrantService.RevokeGrant(id, userId, request.Comment)
+	if err != nil {
+		handler.logger.Errorw("service err, RevokeGrant", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRoleGrantRestHandlerImpl) GetGrant(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.userRoleGrantService.GetGrant(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetGrant", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if res.UserId != userId && !handler.enforcer.Enforce(token, casbin.ResourceUser, casbin.ActionGet, "*") {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRoleGrantRestHandlerImpl) GetGrants(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	v := r.URL.Query()
+	var grantUserId int32
+	if userIdParam := v.Get("userId"); len(userIdParam) > 0 {
+		id, err := strconv.Atoi(userIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+		grantUserId = int32(id)
+	}
+	// users without user read access only see their own grants
+	token := r.Header.Get("token")
+	if !handler.enforcer.Enforce(token, casbin.ResourceUser, casbin.ActionGet, "*") {
+		grantUserId = userId
+	}
+	res, err := handler.userRoleGrantService.GetGrants(grantUserId, repository.UserRoleGrantStatus(v.Get("status")))
+	if err != nil {
+		handler.logger.Errorw("service err, GetGrants", "err", err, "userId", grantUserId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler UserRoleGrantRestHandlerImpl) GetGrantAudits(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	grant, err := handler.userRoleGrantService.GetGrant(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetGrantAudits", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if grant.UserId != userId && !handler.enforcer.Enforce(token, casbin.ResourceUser, casbin.ActionGet, "*") {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	res, err := handler.userRoleGrantService.GetGrantAudits(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetGrantAudits", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// canGrant checks the same access as assigning the role filter through user creation
+func (handler UserRoleGrantRestHandlerImpl) canGrant(token string, roleFilter bean.RoleFilter) bool {
+	isActionUserSuperAdmin := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*")
+	if roleFilter.AccessType == bean.APP_ACCESS_TYPE_HELM && !isActionUserSuperAdmin {
+		return false
+	}
+	if len(roleFilter.Team) > 0 {
+		return handler.enforcer.Enforce(token, casbin.ResourceUser, casbin.ActionCreate, strings.ToLower(roleFilter.Team))
+	}
+	return handler.enforcer.Enforce(token, casbin.ResourceUser, casbin.ActionCreate, "*")
+}
@@ -26,12 +26,17 @@ type UserRouter interface {
 }
 
 type UserRouterImpl struct {
-	userRestHandler UserRestHandler
+	userRestHandler          UserRestHandler
+	userRoleGrantRestHandler UserRoleGrantRestHandler
+	userRbacRestHandler      UserRbacRestHandler
 }
 
-func NewUserRouterImpl(userRestHandler UserRestHandler) *UserRouterImpl {
+func NewUserRouterImpl(userRestHandler UserRestHandler, userRoleGrantRestHandler UserRoleGrantRestHandler,
+	userRbacRestHandler UserRbacRestHandler) *UserRouterImpl {
 	router := &UserRouterImpl{
-		userRestHandler: userRestHandler,
+		userRestHandler:          userRestHandler,
+		userRoleGrantRestHandler: userRoleGrantRestHandler,
+		userRbacRestHandler:      userRbacRestHandler,
 	}
 	return router
 }
@@ -78,4 +83,38 @@ func (router UserRouterImpl) InitUserRouter(userAuthRouter *mux.Router) {
 		HandlerFunc(router.userRestHandler.GetRoleCacheDump).Methods("GET")
 	userAuthRouter.Path("/role/cache/invalidate").
 		HandlerFunc(router.userRestHandler.InvalidateRoleCache).Methods("GET")
+
+	//Time bound role grants
+	userAuthRouter.Path("/role/grant").
+		HandlerFunc(router.userRoleGrantRestHandler.RequestGrant).Methods("POST")
+	userAuthRouter.Path("/role/grant").
+		HandlerFunc(router.userRoleGrantRestHandler.GetGrants).Methods("GET")
+	userAuthRouter.Path("/role/grant/review").
+		HandlerFunc(router.userRoleGrantRestHandler.ReviewGrant).Methods("POST")
+	userAuthRouter.Path("/role/grant/{id}").
+		HandlerFunc(router.userRoleGrantRestHandler.GetGrant).Methods("GET")
+	userAuthRouter.Path("/role/grant/{id}/revoke").
+		HandlerFunc(router.userRoleGrantRestHandler.RevokeGrant).Methods("POST")
+	userAuthRouter.Path("/role/grant/{id}/audit").
+		HandlerFunc(router.userRoleGrantRestHandler.GetGrantAudits).Methods("GET")
+
+	//Custom roles and deny rules
+	userAuthRouter.Path("/role/custom").
+		HandlerFunc(router.userRbacRestHandler.CreateCustomRole).Methods("POST")
+	userAuthRouter.Path("/role/custom").
+		HandlerFunc(router.userRbacRestHandler.UpdateCustomRole).Methods("PUT")
+	userAuthRouter.Path("/role/custom").
+		HandlerFunc(router.userRbacRestHandler.GetAllCustomRoles).Methods("GET")
+	userAuthRouter.Path("/role/custom/{id}").
+		HandlerFunc(router.userRbacRestHandler.GetCustomRole).Methods("GET")
+	userAuthRouter.Path("/role/custom/{id}").
+		HandlerFunc(router.userRbacRestHandler.DeleteCustomRole).Methods("DELETE")
+	userAuthRouter.Path("/role/deny").
+		HandlerFunc(router.userRbacRestHandler.CreateDenyRule).Methods("POST")
+	userAuthRouter.Path("/role/deny").
+		HandlerFunc(router.userRbacRestHandler.GetAllDenyRules).Methods("GET")
+	userAuthRouter.Path("/role/deny/{id}").
+		HandlerFunc(router.userRbacRestHandler.GetDenyRule).Methods("GET")
+	userAuthRouter.Path("/role/deny/{id}").
+		HandlerFunc(router.userRbacRestHandler.DeleteDenyRule).Methods("DELETE")
 }
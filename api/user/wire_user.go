@@ -44,4 +44,23 @@ var UserWireSet = wire.NewSet(
 
 	user.NewUserCommonServiceImpl,
 	wire.Bind(new(user.UserCommonService), new(*user.UserCommonServiceImpl)),
+
+	NewUserRoleGrantRestHandlerImpl,
+	wire.Bind(new(UserRoleGrantRestHandler), new(*UserRoleGrantRestHandlerImpl)),
+	user.GetUserRoleGrantConfig,
+	user.NewUserRoleGrantServiceImpl,
+	wire.Bind(new(user.UserRoleGrantService), new(*user.UserRoleGrantServiceImpl)),
+	repository.NewUserRoleGrantRepositoryImpl,
+	wire.Bind(new(repository.UserRoleGrantRepository), new(*repository.UserRoleGrantRepositoryImpl)),
+
+	NewUserRbacRestHandlerImpl,
+	wire.Bind(new(UserRbacRestHandler), new(*UserRbacRestHandlerImpl)),
+	user.NewCustomRoleServiceImpl,
+	wire.Bind(new(user.CustomRoleService), new(*user.CustomRoleServiceImpl)),
+	repository.NewCustomRoleRepositoryImpl,
+	wire.Bind(new(repository.CustomRoleRepository), new(*repository.CustomRoleRepositoryImpl)),
+	user.NewDenyRuleServiceImpl,
+	wire.Bind(new(user.DenyRuleService), new(*user.DenyRuleServiceImpl)),
+	repository.NewDenyRuleRepositoryImpl,
+	wire.Bind(new(repository.DenyRuleRepository), new(*repository.DenyRuleRepositoryImpl)),
 )
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/apiToken"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type ApiTokenExpiryCron interface {
+	NotifyExpiringApiTokens()
+}
+
+type ApiTokenExpiryCronImpl struct {
+	logger                      *zap.SugaredLogger
+	cron                        *cron.Cron
+	cfg                         *ApiTokenExpiryCronConfig
+	apiTokenNotificationService apiToken.ApiTokenNotificationService
+}
+
+type ApiTokenExpiryCronConfig struct {
+	ApiTokenExpiryCronTime string `env:"API_TOKEN_EXPIRY_CRON_TIME" envDefault:"@every 1h"`
+}
+
+func GetApiTokenExpiryCronConfig() (*ApiTokenExpiryCronConfig, error) {
+	cfg := &ApiTokenExpiryCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse api token expiry cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewApiTokenExpiryCronImpl(logger *zap.SugaredLogger, cfg *ApiTokenExpiryCronConfig, apiTokenNotificationService apiToken.ApiTokenNotificationService) *ApiTokenExpiryCronImpl {
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	cron.Start()
+	impl := &ApiTokenExpiryCronImpl{
+		logger:                      logger,
+		cron:                        cron,
+		cfg:                         cfg,
+		apiTokenNotificationService: apiTokenNotificationService,
+	}
+	_, err := cron.AddFunc(cfg.ApiTokenExpiryCronTime, impl.NotifyExpiringApiTokens)
+	if err != nil {
+		logger.Errorw("error in starting api token expiry cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *ApiTokenExpiryCronImpl) NotifyExpiringApiTokens() {
+	impl.apiTokenNotificationService.NotifyExpiringApiTokens()
+}
@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/bulkAction"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"time"
+)
+
+type BulkOperationJobCron interface {
+	FailStaleBulkOperationJobs()
+}
+
+type BulkOperationJobCronImpl struct {
+	logger            *zap.SugaredLogger
+	cron              *cron.Cron
+	cfg               *BulkOperationJobCronConfig
+	bulkUpdateService bulkAction.BulkUpdateService
+}
+
+type BulkOperationJobCronConfig struct {
+	BulkOperationJobCronTime string `env:"BULK_OPERATION_JOB_CRON_TIME" envDefault:"@every 5m"`
+	// a job with no item updated for this long was interrupted by a restart
+	BulkOperationJobStaleMins int `env:"BULK_OPERATION_JOB_STALE_MINS" envDefault:"30"`
+}
+
+func GetBulkOperationJobCronConfig() (*BulkOperationJobCronConfig, error) {
+	cfg := &BulkOperationJobCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse bulk operation job cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewBulkOperationJobCronImpl(logger *zap.SugaredLogger, cfg *BulkOperationJobCronConfig, bulkUpdateService bulkAction.BulkUpdateService) *BulkOperationJobCronImpl {
+	cron := cron.New(
+		cron.WithChain())
+	cron.Start()
+	impl := &BulkOperationJobCronImpl{
+		logger:            logger,
+		cron:              cron,
+		cfg:               cfg,
+		bulkUpdateService: bulkUpdateService,
+	}
+	_, err := cron.AddFunc(cfg.BulkOperationJobCronTime, impl.FailStaleBulkOperationJobs)
+	if err != nil {
+		logger.Errorw("error in starting bulk operation job cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *BulkOperationJobCronImpl) FailStaleBulkOperationJobs() {
+	impl.bulkUpdateService.FailStaleBulkOperationJobs(time.Duration(impl.cfg.BulkOperationJobStaleMins) * time.Minute)
+}
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type CanaryAnalysisCron interface {
+	ProcessCanaryAnalysis()
+}
+
+type CanaryAnalysisCronImpl struct {
+	logger                *zap.SugaredLogger
+	cron                  *cron.Cron
+	cfg                   *CanaryAnalysisCronConfig
+	canaryAnalysisService pipeline.CanaryAnalysisService
+}
+
+type CanaryAnalysisCronConfig struct {
+	CanaryAnalysisCronTime string `env:"CANARY_ANALYSIS_CRON_TIME" envDefault:"@every 1m"`
+}
+
+func GetCanaryAnalysisCronConfig() (*CanaryAnalysisCronConfig, error) {
+	cfg := &CanaryAnalysisCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse canary analysis cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewCanaryAnalysisCronImpl(logger *zap.SugaredLogger, cfg *CanaryAnalysisCronConfig, canaryAnalysisService pipeline.CanaryAnalysisService) *CanaryAnalysisCronImpl {
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	cron.Start()
+	impl := &CanaryAnalysisCronImpl{
+		logger:                logger,
+		cron:                  cron,
+		cfg:                   cfg,
+		canaryAnalysisService: canaryAnalysisService,
+	}
+	_, err := cron.AddFunc(cfg.CanaryAnalysisCronTime, impl.ProcessCanaryAnalysis)
+	if err != nil {
+		logger.Errorw("error in starting canary analysis cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *CanaryAnalysisCronImpl) ProcessCanaryAnalysis() {
+	impl.canaryAnalysisService.ProcessCanaryAnalysis()
+}
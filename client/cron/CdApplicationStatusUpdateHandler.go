@@ -97,6 +97,16 @@ func NewCdApplicationStatusUpdateHandlerImpl(logger *zap.SugaredLogger, appServi
 		logger.Errorw("error in starting argo application status update cron job", "err", err)
 		return nil
 	}
+	_, err = cron.AddFunc("@every 1m", impl.workflowDagExecutor.TriggerQueuedDeployments)
+	if err != nil {
+		logger.Errorw("error in starting queued deployments trigger cron job", "err", err)
+		return nil
+	}
+	_, err = cron.AddFunc("@every 1m", impl.workflowDagExecutor.TriggerApprovedDeployments)
+	if err != nil {
+		logger.Errorw("error in starting approved deployments trigger cron job", "err", err)
+		return nil
+	}
 	return impl
 }
 
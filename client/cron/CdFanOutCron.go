@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type CdFanOutCron interface {
+	ProcessFanOutDeployments()
+}
+
+type CdFanOutCronImpl struct {
+	logger          *zap.SugaredLogger
+	cron            *cron.Cron
+	cfg             *CdFanOutCronConfig
+	cdFanOutService pipeline.CdFanOutService
+}
+
+type CdFanOutCronConfig struct {
+	CdFanOutCronTime string `env:"CD_FAN_OUT_CRON_TIME" envDefault:"@every 1m"`
+}
+
+func GetCdFanOutCronConfig() (*CdFanOutCronConfig, error) {
+	cfg := &CdFanOutCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse cd fan-out cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewCdFanOutCronImpl(logger *zap.SugaredLogger, cfg *CdFanOutCronConfig, cdFanOutService pipeline.CdFanOutService) *CdFanOutCronImpl {
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	cron.Start()
+	impl := &CdFanOutCronImpl{
+		logger:          logger,
+		cron:            cron,
+		cfg:             cfg,
+		cdFanOutService: cdFanOutService,
+	}
+	_, err := cron.AddFunc(cfg.CdFanOutCronTime, impl.ProcessFanOutDeployments)
+	if err != nil {
+		logger.Errorw("error in starting cd fan-out cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *CdFanOutCronImpl) ProcessFanOutDeployments() {
+	impl.cdFanOutService.ProcessFanOutDeployments()
+}
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type CiTriggerCron interface {
+	TriggerScheduledCi()
+}
+
+type CiTriggerCronImpl struct {
+	logger            *zap.SugaredLogger
+	cron              *cron.Cron
+	cfg               *CiTriggerCronConfig
+	ciScheduleService pipeline.CiScheduleService
+}
+
+type CiTriggerCronConfig struct {
+	CiScheduleCronTime string `env:"CI_SCHEDULE_CRON_TIME" envDefault:"@every 1m"`
+}
+
+func GetCiTriggerCronConfig() (*CiTriggerCronConfig, error) {
+	cfg := &CiTriggerCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse ci trigger cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewCiTriggerCronImpl(logger *zap.SugaredLogger, cfg *CiTriggerCronConfig, ciScheduleService pipeline.CiScheduleService) *CiTriggerCronImpl {
+	cron := cron.New(
+		cron.WithChain())
+	cron.Start()
+	impl := &CiTriggerCronImpl{
+		logger:            logger,
+		cron:              cron,
+		cfg:               cfg,
+		ciScheduleService: ciScheduleService,
+	}
+	_, err := cron.AddFunc(cfg.CiScheduleCronTime, impl.TriggerScheduledCi)
+	if err != nil {
+		logger.Errorw("error in starting scheduled ci trigger cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *CiTriggerCronImpl) TriggerScheduledCi() {
+	impl.ciScheduleService.TriggerDueSchedules()
+}
@@ -0,0 +1,60 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type ConfigDriftCron interface {
+	DetectConfigDrift()
+}
+
+type ConfigDriftCronImpl struct {
+	logger             *zap.SugaredLogger
+	cron               *cron.Cron
+	cfg                *ConfigDriftCronConfig
+	configDriftService pipeline.ConfigDriftService
+}
+
+type ConfigDriftCronConfig struct {
+	ConfigDriftCronTime         string `env:"CONFIG_DRIFT_CRON_TIME" envDefault:"@every 30m"`
+	ConfigDriftDetectionEnabled bool   `env:"CONFIG_DRIFT_DETECTION_ENABLED" envDefault:"true"`
+}
+
+func GetConfigDriftCronConfig() (*ConfigDriftCronConfig, error) {
+	cfg := &ConfigDriftCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse config drift cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewConfigDriftCronImpl(logger *zap.SugaredLogger, cfg *ConfigDriftCronConfig, configDriftService pipeline.ConfigDriftService) *ConfigDriftCronImpl {
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	cron.Start()
+	impl := &ConfigDriftCronImpl{
+		logger:             logger,
+		cron:               cron,
+		cfg:                cfg,
+		configDriftService: configDriftService,
+	}
+	if !cfg.ConfigDriftDetectionEnabled {
+		return impl
+	}
+	_, err := cron.AddFunc(cfg.ConfigDriftCronTime, impl.DetectConfigDrift)
+	if err != nil {
+		logger.Errorw("error in starting config drift cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *ConfigDriftCronImpl) DetectConfigDrift() {
+	impl.configDriftService.ScanAllPipelines()
+}
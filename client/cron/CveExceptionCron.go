@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/security"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type CveExceptionCron interface {
+	NotifyExpiringExceptions()
+}
+
+type CveExceptionCronImpl struct {
+	logger              *zap.SugaredLogger
+	cron                *cron.Cron
+	cfg                 *CveExceptionCronConfig
+	cveExceptionService security.CveExceptionService
+}
+
+type CveExceptionCronConfig struct {
+	CveExceptionCronTime string `env:"CVE_EXCEPTION_CRON_TIME" envDefault:"@every 1h"`
+}
+
+func GetCveExceptionCronConfig() (*CveExceptionCronConfig, error) {
+	cfg := &CveExceptionCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse cve exception cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewCveExceptionCronImpl(logger *zap.SugaredLogger, cfg *CveExceptionCronConfig, cveExceptionService security.CveExceptionService) *CveExceptionCronImpl {
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	cron.Start()
+	impl := &CveExceptionCronImpl{
+		logger:              logger,
+		cron:                cron,
+		cfg:                 cfg,
+		cveExceptionService: cveExceptionService,
+	}
+	_, err := cron.AddFunc(cfg.CveExceptionCronTime, impl.NotifyExpiringExceptions)
+	if err != nil {
+		logger.Errorw("error in starting cve exception cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *CveExceptionCronImpl) NotifyExpiringExceptions() {
+	impl.cveExceptionService.NotifyExpiringExceptions()
+}
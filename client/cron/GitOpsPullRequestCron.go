@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type GitOpsPullRequestCron interface {
+	SyncPullRequests()
+}
+
+type GitOpsPullRequestCronImpl struct {
+	logger                   *zap.SugaredLogger
+	cron                     *cron.Cron
+	cfg                      *GitOpsPullRequestCronConfig
+	gitOpsPullRequestService pipeline.GitOpsPullRequestService
+}
+
+type GitOpsPullRequestCronConfig struct {
+	GitOpsPullRequestCronTime string `env:"GITOPS_PR_CRON_TIME" envDefault:"@every 2m"`
+}
+
+func GetGitOpsPullRequestCronConfig() (*GitOpsPullRequestCronConfig, error) {
+	cfg := &GitOpsPullRequestCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse gitops pull request cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewGitOpsPullRequestCronImpl(logger *zap.SugaredLogger, cfg *GitOpsPullRequestCronConfig, gitOpsPullRequestService pipeline.GitOpsPullRequestService) *GitOpsPullRequestCronImpl {
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	cron.Start()
+	impl := &GitOpsPullRequestCronImpl{
+		logger:                   logger,
+		cron:                     cron,
+		cfg:                      cfg,
+		gitOpsPullRequestService: gitOpsPullRequestService,
+	}
+	_, err := cron.AddFunc(cfg.GitOpsPullRequestCronTime, impl.SyncPullRequests)
+	if err != nil {
+		logger.Errorw("error in starting gitops pull request cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *GitOpsPullRequestCronImpl) SyncPullRequests() {
+	impl.gitOpsPullRequestService.SyncOpenPullRequests()
+}
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/bulkAction"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type HibernationScheduleCron interface {
+	ExecuteHibernationSchedules()
+}
+
+type HibernationScheduleCronImpl struct {
+	logger                     *zap.SugaredLogger
+	cron                       *cron.Cron
+	cfg                        *HibernationScheduleCronConfig
+	hibernationScheduleService bulkAction.HibernationScheduleService
+}
+
+type HibernationScheduleCronConfig struct {
+	HibernationScheduleCronTime string `env:"HIBERNATION_SCHEDULE_CRON_TIME" envDefault:"@every 1m"`
+}
+
+func GetHibernationScheduleCronConfig() (*HibernationScheduleCronConfig, error) {
+	cfg := &HibernationScheduleCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse hibernation schedule cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewHibernationScheduleCronImpl(logger *zap.SugaredLogger, cfg *HibernationScheduleCronConfig, hibernationScheduleService bulkAction.HibernationScheduleService) *HibernationScheduleCronImpl {
+	cron := cron.New(
+		cron.WithChain())
+	cron.Start()
+	impl := &HibernationScheduleCronImpl{
+		logger:                     logger,
+		cron:                       cron,
+		cfg:                        cfg,
+		hibernationScheduleService: hibernationScheduleService,
+	}
+	_, err := cron.AddFunc(cfg.HibernationScheduleCronTime, impl.ExecuteHibernationSchedules)
+	if err != nil {
+		logger.Errorw("error in starting hibernation schedule cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *HibernationScheduleCronImpl) ExecuteHibernationSchedules() {
+	impl.hibernationScheduleService.ExecuteDueSchedules()
+}
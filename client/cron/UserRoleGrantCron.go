@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type UserRoleGrantCron interface {
+	ExpireGrants()
+}
+
+type UserRoleGrantCronImpl struct {
+	logger               *zap.SugaredLogger
+	cron                 *cron.Cron
+	cfg                  *UserRoleGrantCronConfig
+	userRoleGrantService user.UserRoleGrantService
+}
+
+type UserRoleGrantCronConfig struct {
+	UserRoleGrantCronTime string `env:"USER_ROLE_GRANT_CRON_TIME" envDefault:"@every 1m"`
+}
+
+func GetUserRoleGrantCronConfig() (*UserRoleGrantCronConfig, error) {
+	cfg := &UserRoleGrantCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse user role grant cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func NewUserRoleGrantCronImpl(logger *zap.SugaredLogger, cfg *UserRoleGrantCronConfig, userRoleGrantService user.UserRoleGrantService) *UserRoleGrantCronImpl {
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	cron.Start()
+	impl := &UserRoleGrantCronImpl{
+		logger:               logger,
+		cron:                 cron,
+		cfg:                  cfg,
+		userRoleGrantService: userRoleGrantService,
+	}
+	_, err := cron.AddFunc(cfg.UserRoleGrantCronTime, impl.ExpireGrants)
+	if err != nil {
+		logger.Errorw("error in starting user role grant cron job", "err", err)
+		return nil
+	}
+	return impl
+}
+
+func (impl *UserRoleGrantCronImpl) ExpireGrants() {
+	impl.userRoleGrantService.ExpireGrants()
+}
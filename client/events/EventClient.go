@@ -33,6 +33,8 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/attributes"
+	"github.com/devtron-labs/devtron/pkg/notifier"
+	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	util1 "github.com/devtron-labs/devtron/util"
 	util "github.com/devtron-labs/devtron/util/event"
 	"github.com/nats-io/nats.go"
@@ -91,6 +93,12 @@ type Payload struct {
 	DownloadLink          string               `json:"downloadLink"`
 	BuildHistoryLink      string               `json:"buildHistoryLink"`
 	MaterialTriggerInfo   *MaterialTriggerInfo `json:"material"`
+	CveName               string               `json:"cveName,omitempty"`
+	ExpiresOn             string               `json:"expiresOn,omitempty"`
+	ApiTokenName          string               `json:"apiTokenName,omitempty"`
+	ApprovalStatus        string               `json:"approvalStatus,omitempty"`
+	// Providers are sent to directly for events which are not configured through notification settings
+	Providers []*notifier.Provider `json:"providers,omitempty"`
 }
 
 type CiPipelineMaterialResponse struct {
@@ -116,22 +124,24 @@ type MaterialTriggerInfo struct {
 }
 
 type EventRESTClientImpl struct {
-	logger               *zap.SugaredLogger
-	client               *http.Client
-	config               *EventClientConfig
-	pubsubClient         *pubsub.PubSubClient
-	ciPipelineRepository pipelineConfig.CiPipelineRepository
-	pipelineRepository   pipelineConfig.PipelineRepository
-	attributesRepository repository.AttributesRepository
-	moduleService        module.ModuleService
+	logger                  *zap.SugaredLogger
+	client                  *http.Client
+	config                  *EventClientConfig
+	pubsubClient            *pubsub.PubSubClient
+	ciPipelineRepository    pipelineConfig.CiPipelineRepository
+	pipelineRepository      pipelineConfig.PipelineRepository
+	attributesRepository    repository.AttributesRepository
+	moduleService           module.ModuleService
+	pipelineStageRepository repository2.PipelineStageRepository
 }
 
 func NewEventRESTClientImpl(logger *zap.SugaredLogger, client *http.Client, config *EventClientConfig, pubsubClient *pubsub.PubSubClient,
 	ciPipelineRepository pipelineConfig.CiPipelineRepository, pipelineRepository pipelineConfig.PipelineRepository,
-	attributesRepository repository.AttributesRepository, moduleService module.ModuleService) *EventRESTClientImpl {
+	attributesRepository repository.AttributesRepository, moduleService module.ModuleService,
+	pipelineStageRepository repository2.PipelineStageRepository) *EventRESTClientImpl {
 	return &EventRESTClientImpl{logger: logger, client: client, config: config, pubsubClient: pubsubClient,
 		ciPipelineRepository: ciPipelineRepository, pipelineRepository: pipelineRepository,
-		attributesRepository: attributesRepository, moduleService: moduleService}
+		attributesRepository: attributesRepository, moduleService: moduleService, pipelineStageRepository: pipelineStageRepository}
 }
 
 func (impl *EventRESTClientImpl) buildFinalPayload(event Event, cdPipeline *pipelineConfig.Pipeline, ciPipeline *pipelineConfig.CiPipeline) *Payload {
@@ -200,13 +210,9 @@ func (impl *EventRESTClientImpl) WriteNotificationEvent(event Event) (bool, erro
 	payload := impl.buildFinalPayload(event, cdPipeline, ciPipeline)
 	event.Payload = payload
 
-	isPreStageExist := false
-	isPostStageExist := false
-	if cdPipeline != nil && len(cdPipeline.PreStageConfig) > 0 {
-		isPreStageExist = true
-	}
-	if cdPipeline != nil && len(cdPipeline.PostStageConfig) > 0 {
-		isPostStageExist = true
+	isPreStageExist, isPostStageExist, err := impl.isPrePostStageConfigured(cdPipeline)
+	if err != nil {
+		return false, err
 	}
 
 	attribute, err := impl.attributesRepository.FindByKey(attributes.HostUrlKey)
@@ -243,6 +249,32 @@ func (impl *EventRESTClientImpl) WriteNotificationEvent(event Event) (bool, erro
 	return true, err
 }
 
+// isPrePostStageConfigured checks for pre and post cd stages configured either as yaml or as steps
+func (impl *EventRESTClientImpl) isPrePostStageConfigured(cdPipeline *pipelineConfig.Pipeline) (bool, bool, error) {
+	if cdPipeline == nil {
+		return false, false, nil
+	}
+	isPreStageExist := len(cdPipeline.PreStageConfig) > 0
+	isPostStageExist := len(cdPipeline.PostStageConfig) > 0
+	if !isPreStageExist {
+		ids, err := impl.pipelineStageRepository.GetCdPipelineIdsHavingStageSteps([]int{cdPipeline.Id}, repository2.PIPELINE_STAGE_TYPE_PRE_CD)
+		if err != nil {
+			impl.logger.Errorw("error in checking pre cd stage steps", "err", err, "cdPipelineId", cdPipeline.Id)
+			return false, false, err
+		}
+		isPreStageExist = len(ids) > 0
+	}
+	if !isPostStageExist {
+		ids, err := impl.pipelineStageRepository.GetCdPipelineIdsHavingStageSteps([]int{cdPipeline.Id}, repository2.PIPELINE_STAGE_TYPE_POST_CD)
+		if err != nil {
+			impl.logger.Errorw("error in checking post cd stage steps", "err", err, "cdPipelineId", cdPipeline.Id)
+			return false, false, err
+		}
+		isPostStageExist = len(ids) > 0
+	}
+	return isPreStageExist, isPostStageExist, nil
+}
+
 // do not call this method if notification module is not installed
 func (impl *EventRESTClientImpl) sendEvent(event Event) (bool, error) {
 	impl.logger.Debugw("event before send", "event", event)
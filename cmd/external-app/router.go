@@ -6,6 +6,7 @@ import (
 	appStoreDeployment "github.com/devtron-labs/devtron/api/appStore/deployment"
 	appStoreDiscover "github.com/devtron-labs/devtron/api/appStore/discover"
 	appStoreValues "github.com/devtron-labs/devtron/api/appStore/values"
+	"github.com/devtron-labs/devtron/api/auditLog"
 	"github.com/devtron-labs/devtron/api/chartRepo"
 	"github.com/devtron-labs/devtron/api/cluster"
 	"github.com/devtron-labs/devtron/api/dashboardEvent"
@@ -20,6 +21,7 @@ import (
 	"github.com/devtron-labs/devtron/api/user"
 	webhookHelm "github.com/devtron-labs/devtron/api/webhook/helm"
 	"github.com/devtron-labs/devtron/client/dashboard"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	"github.com/devtron-labs/devtron/util"
 	"github.com/devtron-labs/devtron/util/k8s"
 	"github.com/gorilla/mux"
@@ -53,6 +55,10 @@ type MuxRouter struct {
 	webhookHelmRouter        webhookHelm.WebhookHelmRouter
 	userAttributesRouter     router.UserAttributesRouter
 	telemetryRouter          router.TelemetryRouter
+	auditLogRouter           auditLog.AuditLogRouter
+	auditLogMiddleware       auditLog.AuditLogMiddleware
+	enforcer                 casbin.Enforcer
+	apiTokenMiddleware       apiToken.ApiTokenMiddleware
 }
 
 func NewMuxRouter(
@@ -79,6 +85,10 @@ func NewMuxRouter(
 	webhookHelmRouter webhookHelm.WebhookHelmRouter,
 	userAttributesRouter router.UserAttributesRouter,
 	telemetryRouter router.TelemetryRouter,
+	auditLogRouter auditLog.AuditLogRouter,
+	auditLogMiddleware auditLog.AuditLogMiddleware,
+	enforcer casbin.Enforcer,
+	apiTokenMiddleware apiToken.ApiTokenMiddleware,
 ) *MuxRouter {
 	r := &MuxRouter{
 		Router:                   mux.NewRouter(),
@@ -106,6 +116,10 @@ func NewMuxRouter(
 		webhookHelmRouter:        webhookHelmRouter,
 		userAttributesRouter:     userAttributesRouter,
 		telemetryRouter:          telemetryRouter,
+		auditLogRouter:           auditLogRouter,
+		auditLogMiddleware:       auditLogMiddleware,
+		enforcer:                 enforcer,
+		apiTokenMiddleware:       apiTokenMiddleware,
 	}
 	return r
 }
@@ -210,6 +224,15 @@ func (r *MuxRouter) Init() {
 	apiTokenRouter := r.Router.PathPrefix("/orchestrator/api-token").Subrouter()
 	r.apiTokenRouter.InitApiTokenRouter(apiTokenRouter)
 
+	// audit log router, mutating requests on every route are recorded by the audit middleware
+	r.Router.Use(r.auditLogMiddleware.Audit)
+	// client ip of api token requests, checked against the ip allow-list of scoped tokens on enforce
+	r.Router.Use(r.enforcer.ClientIpMiddleware)
+	// api tokens which are deleted or rotated past their grace period are rejected on every route
+	r.Router.Use(r.apiTokenMiddleware.RejectInactiveTokens)
+	auditLogRouter := r.Router.PathPrefix("/orchestrator/audit-log").Subrouter()
+	r.auditLogRouter.InitAuditLogRouter(auditLogRouter)
+
 	// webhook helm app router
 	webhookHelmRouter := r.Router.PathPrefix("/orchestrator/webhook/helm").Subrouter()
 	r.webhookHelmRouter.InitWebhookHelmRouter(webhookHelmRouter)
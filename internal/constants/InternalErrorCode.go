@@ -72,6 +72,8 @@ const (
 	UserNotFoundForToken                 string = "6006"
 	UserCreateFetchRoleFailed            string = "6007"
 	UserUpdateFetchRoleFailed            string = "6008"
+	UserApiTokenIpNotAllowed             string = "6009"
+	UserApiTokenNotActive                string = "6010"
 
 	AppDetailResourceTreeNotFound string = "7000"
 
@@ -31,21 +31,25 @@ import (
 )
 
 type CiArtifact struct {
-	tableName        struct{}  `sql:"ci_artifact" pg:",discard_unknown_columns"`
-	Id               int       `sql:"id,pk"`
-	PipelineId       int       `sql:"pipeline_id,notnull"` //id of the ci pipeline from which this webhook was triggered
-	Image            string    `sql:"image,notnull"`
-	ImageDigest      string    `sql:"image_digest,notnull"`
-	MaterialInfo     string    `sql:"material_info"` //git material metadata json array string
-	DataSource       string    `sql:"data_source,notnull"`
-	WorkflowId       *int      `sql:"ci_workflow_id"`
-	ParentCiArtifact int       `sql:"parent_ci_artifact"`
-	ScanEnabled      bool      `sql:"scan_enabled,notnull"`
-	Scanned          bool      `sql:"scanned,notnull"`
-	DeployedTime     time.Time `sql:"-"`
-	Deployed         bool      `sql:"-"`
-	Latest           bool      `sql:"-"`
-	RunningOnParent  bool      `sql:"-"`
+	tableName             struct{}  `sql:"ci_artifact" pg:",discard_unknown_columns"`
+	Id                    int       `sql:"id,pk"`
+	PipelineId            int       `sql:"pipeline_id,notnull"` //id of the ci pipeline from which this webhook was triggered
+	Image                 string    `sql:"image,notnull"`
+	ImageDigest           string    `sql:"image_digest,notnull"`
+	MaterialInfo          string    `sql:"material_info"` //git material metadata json array string
+	DataSource            string    `sql:"data_source,notnull"`
+	WorkflowId            *int      `sql:"ci_workflow_id"`
+	ParentCiArtifact      int       `sql:"parent_ci_artifact"`
+	ScanEnabled           bool      `sql:"scan_enabled,notnull"`
+	Scanned               bool      `sql:"scanned,notnull"`
+	Signature             string    `sql:"signature"`
+	SignaturePayload      string    `sql:"signature_payload"` //signed simple signing json referencing the image digest
+	SigningKeyFingerprint string    `sql:"signing_key_fingerprint"`
+	SignedOn              time.Time `sql:"signed_on"`
+	DeployedTime          time.Time `sql:"-"`
+	Deployed              bool      `sql:"-"`
+	Latest                bool      `sql:"-"`
+	RunningOnParent       bool      `sql:"-"`
 	sql.AuditLog
 }
 
@@ -54,6 +54,11 @@ type GitOpsConfig struct {
 	BitBucketWorkspaceId string   `sql:"bitbucket_workspace_id"`
 	BitBucketProjectKey  string   `sql:"bitbucket_project_key"`
 	EmailId              string   `sql:"email_id"`
+	GiteaOrgId           string   `sql:"gitea_org_id"`
+	SshPrivateKey        string   `sql:"ssh_private_key"`
+	SshKnownHosts        string   `sql:"ssh_known_hosts"`
+	RepoLayout           string   `sql:"repo_layout"`
+	MonoRepoName         string   `sql:"mono_repo_name"`
 	sql.AuditLog
 }
 
@@ -0,0 +1,213 @@
+package bulkUpdate
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type BulkOperationType string
+
+const (
+	BULK_OPERATION_HIBERNATE     BulkOperationType = "HIBERNATE"
+	BULK_OPERATION_UNHIBERNATE   BulkOperationType = "UNHIBERNATE"
+	BULK_OPERATION_DEPLOY        BulkOperationType = "DEPLOY"
+	BULK_OPERATION_BUILD_TRIGGER BulkOperationType = "BUILD_TRIGGER"
+	BULK_OPERATION_EDIT          BulkOperationType = "BULK_EDIT"
+	BULK_OPERATION_REVERT        BulkOperationType = "REVERT"
+)
+
+type BulkOperationJobStatus string
+
+const (
+	BULK_JOB_IN_PROGRESS BulkOperationJobStatus = "IN_PROGRESS"
+	BULK_JOB_COMPLETED   BulkOperationJobStatus = "COMPLETED"
+	BULK_JOB_CANCELLED   BulkOperationJobStatus = "CANCELLED"
+)
+
+type BulkOperationItemType string
+
+const (
+	BULK_ITEM_CD_PIPELINE         BulkOperationItemType = "CD_PIPELINE"
+	BULK_ITEM_CI_PIPELINE         BulkOperationItemType = "CI_PIPELINE"
+	BULK_ITEM_DEPLOYMENT_TEMPLATE BulkOperationItemType = "DEPLOYMENT_TEMPLATE"
+	BULK_ITEM_CONFIG_MAP          BulkOperationItemType = "CONFIG_MAP"
+	BULK_ITEM_SECRET              BulkOperationItemType = "SECRET"
+)
+
+type BulkOperationItemStatus string
+
+const (
+	BULK_ITEM_PENDING     BulkOperationItemStatus = "PENDING"
+	BULK_ITEM_IN_PROGRESS BulkOperationItemStatus = "IN_PROGRESS"
+	BULK_ITEM_SUCCEEDED   BulkOperationItemStatus = "SUCCEEDED"
+	BULK_ITEM_FAILED      BulkOperationItemStatus = "FAILED"
+	BULK_ITEM_SKIPPED     BulkOperationItemStatus = "SKIPPED"
+	BULK_ITEM_CANCELLED   BulkOperationItemStatus = "CANCELLED"
+)
+
+type BulkOperationJob struct {
+	tableName     struct{}               `sql:"bulk_operation_job" pg:",discard_unknown_columns"`
+	Id            int                    `sql:"id,pk"`
+	Operation     BulkOperationType      `sql:"operation,notnull"`
+	Status        BulkOperationJobStatus `sql:"status,notnull"`
+	Payload       string                 `sql:"payload"`
+	RevertedJobId int                    `sql:"reverted_job_id"`
+	sql.AuditLog
+}
+
+type BulkOperationJobItem struct {
+	tableName    struct{}                `sql:"bulk_operation_job_item" pg:",discard_unknown_columns"`
+	Id           int                     `sql:"id,pk"`
+	JobId        int                     `sql:"job_id,notnull"`
+	ItemType     BulkOperationItemType   `sql:"item_type,notnull"`
+	AppId        int                     `sql:"app_id"`
+	AppName      string                  `sql:"app_name"`
+	EnvId        int                     `sql:"env_id"`
+	PipelineId   int                     `sql:"pipeline_id"`
+	PipelineName string                  `sql:"pipeline_name"`
+	Names        string                  `sql:"names"`
+	HistoryId    int                     `sql:"history_id"`
+	Status       BulkOperationItemStatus `sql:"status,notnull"`
+	Message      string                  `sql:"message"`
+	sql.AuditLog
+}
+
+type BulkOperationJobRepository interface {
+	GetConnection() *pg.DB
+	SaveJob(job *BulkOperationJob, tx *pg.Tx) error
+	UpdateJobStatusIfInProgress(jobId int, status BulkOperationJobStatus, userId int32) (bool, error)
+	FindJobById(jobId int) (*BulkOperationJob, error)
+	SaveItems(items []*BulkOperationJobItem, tx *pg.Tx) error
+	UpdateItem(item *BulkOperationJobItem) error
+	MarkItemInProgressIfPending(itemId int, userId int32) (bool, error)
+	CancelPendingItemsByJobId(jobId int, userId int32) error
+	FindItemsByJobId(jobId int) ([]*BulkOperationJobItem, error)
+	// FindStaleInProgressJobs returns the jobs in progress with no item updated since staleBefore
+	FindStaleInProgressJobs(staleBefore time.Time) ([]*BulkOperationJob, error)
+	FailInProgressItemsByJobId(jobId int, message string, userId int32) error
+	// LockBulkEdit holds an advisory lock till the tx ends, serializing bulk edits across replicas
+	LockBulkEdit(tx *pg.Tx) error
+}
+
+// bulkEditLockKey is the postgres advisory lock key taken by bulk edits
+const bulkEditLockKey = 90001
+
+type BulkOperationJobRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewBulkOperationJobRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *BulkOperationJobRepositoryImpl {
+	return &BulkOperationJobRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl BulkOperationJobRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl BulkOperationJobRepositoryImpl) SaveJob(job *BulkOperationJob, tx *pg.Tx) error {
+	return tx.Insert(job)
+}
+
+// UpdateJobStatusIfInProgress moves a job to a terminal status, jobs already completed or cancelled are left untouched
+func (impl BulkOperationJobRepositoryImpl) UpdateJobStatusIfInProgress(jobId int, status BulkOperationJobStatus, userId int32) (bool, error) {
+	res, err := impl.dbConnection.Model(&BulkOperationJob{}).
+		Set("status = ?", status).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("id = ?", jobId).
+		Where("status = ?", BULK_JOB_IN_PROGRESS).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (impl BulkOperationJobRepositoryImpl) FindJobById(jobId int) (*BulkOperationJob, error) {
+	job := &BulkOperationJob{}
+	err := impl.dbConnection.Model(job).
+		Where("id = ?", jobId).
+		Select()
+	return job, err
+}
+
+func (impl BulkOperationJobRepositoryImpl) SaveItems(items []*BulkOperationJobItem, tx *pg.Tx) error {
+	if len(items) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&items).Insert()
+	return err
+}
+
+func (impl BulkOperationJobRepositoryImpl) UpdateItem(item *BulkOperationJobItem) error {
+	return impl.dbConnection.Update(item)
+}
+
+// MarkItemInProgressIfPending moves an item to in progress only if it is still pending, this guards items against
+// being picked after the job has been cancelled
+func (impl BulkOperationJobRepositoryImpl) MarkItemInProgressIfPending(itemId int, userId int32) (bool, error) {
+	res, err := impl.dbConnection.Model(&BulkOperationJobItem{}).
+		Set("status = ?", BULK_ITEM_IN_PROGRESS).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("id = ?", itemId).
+		Where("status = ?", BULK_ITEM_PENDING).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (impl BulkOperationJobRepositoryImpl) CancelPendingItemsByJobId(jobId int, userId int32) error {
+	_, err := impl.dbConnection.Model(&BulkOperationJobItem{}).
+		Set("status = ?", BULK_ITEM_CANCELLED).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("job_id = ?", jobId).
+		Where("status = ?", BULK_ITEM_PENDING).
+		Update()
+	return err
+}
+
+func (impl BulkOperationJobRepositoryImpl) FindItemsByJobId(jobId int) ([]*BulkOperationJobItem, error) {
+	var items []*BulkOperationJobItem
+	err := impl.dbConnection.Model(&items).
+		Where("job_id = ?", jobId).
+		Order("id ASC").
+		Select()
+	return items, err
+}
+
+func (impl BulkOperationJobRepositoryImpl) FindStaleInProgressJobs(staleBefore time.Time) ([]*BulkOperationJob, error) {
+	var jobs []*BulkOperationJob
+	err := impl.dbConnection.Model(&jobs).
+		Where("status = ?", BULK_JOB_IN_PROGRESS).
+		Where("updated_on < ?", staleBefore).
+		Where("NOT EXISTS (SELECT 1 FROM bulk_operation_job_item item WHERE item.job_id = bulk_operation_job.id AND item.updated_on >= ?)", staleBefore).
+		Select()
+	return jobs, err
+}
+
+func (impl BulkOperationJobRepositoryImpl) FailInProgressItemsByJobId(jobId int, message string, userId int32) error {
+	_, err := impl.dbConnection.Model(&BulkOperationJobItem{}).
+		Set("status = ?", BULK_ITEM_FAILED).
+		Set("message = ?", message).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("job_id = ?", jobId).
+		Where("status = ?", BULK_ITEM_IN_PROGRESS).
+		Update()
+	return err
+}
+
+func (impl BulkOperationJobRepositoryImpl) LockBulkEdit(tx *pg.Tx) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock(?)", bulkEditLockKey)
+	return err
+}
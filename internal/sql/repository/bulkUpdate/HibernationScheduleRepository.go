@@ -0,0 +1,161 @@
+package bulkUpdate
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"go.uber.org/zap"
+	"time"
+)
+
+type HibernationAction string
+
+const (
+	HIBERNATION_ACTION_HIBERNATE HibernationAction = "HIBERNATE"
+	HIBERNATION_ACTION_WAKE      HibernationAction = "WAKE"
+)
+
+type HibernationRunStatus string
+
+const (
+	HIBERNATION_RUN_TRIGGERED HibernationRunStatus = "TRIGGERED"
+	HIBERNATION_RUN_SUCCEEDED HibernationRunStatus = "SUCCEEDED"
+	HIBERNATION_RUN_FAILED    HibernationRunStatus = "FAILED"
+	HIBERNATION_RUN_SKIPPED   HibernationRunStatus = "SKIPPED"
+)
+
+type HibernationSchedule struct {
+	tableName        struct{}          `sql:"hibernation_schedule" pg:",discard_unknown_columns"`
+	Id               int               `sql:"id,pk"`
+	Name             string            `sql:"name,notnull"`
+	EnvironmentId    int               `sql:"environment_id,notnull"`
+	AppLabelSelector map[string]string `sql:"app_label_selector"`
+	HibernateCron    string            `sql:"hibernate_cron,notnull"`
+	WakeCron         string            `sql:"wake_cron"`
+	Timezone         string            `sql:"timezone,notnull"`
+	SkipUntil        time.Time         `sql:"skip_until"`
+	NextHibernateOn  time.Time         `sql:"next_hibernate_on"`
+	NextWakeOn       time.Time         `sql:"next_wake_on"`
+	Active           bool              `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type HibernationScheduleRun struct {
+	tableName          struct{}             `sql:"hibernation_schedule_run" pg:",discard_unknown_columns"`
+	Id                 int                  `sql:"id,pk"`
+	ScheduleId         int                  `sql:"schedule_id,notnull"`
+	Action             HibernationAction    `sql:"action,notnull"`
+	ScheduledOn        time.Time            `sql:"scheduled_on,notnull"`
+	Status             HibernationRunStatus `sql:"status,notnull"`
+	BulkOperationJobId int                  `sql:"bulk_operation_job_id"`
+	Message            string               `sql:"message"`
+	sql.AuditLog
+}
+
+type HibernationScheduleRepository interface {
+	SaveSchedule(schedule *HibernationSchedule) error
+	UpdateSchedule(schedule *HibernationSchedule) error
+	FindActiveScheduleById(id int) (*HibernationSchedule, error)
+	FindAllActiveSchedules() ([]*HibernationSchedule, error)
+	FindDueSchedules(now time.Time) ([]*HibernationSchedule, error)
+	// ClaimRun moves the next run of the action forward only if it is still the value read, so that a run
+	// is picked by a single orchestrator instance
+	ClaimRun(id int, action HibernationAction, currentRunOn time.Time, nextRunOn time.Time) (bool, error)
+	SaveRun(run *HibernationScheduleRun) error
+	UpdateRun(run *HibernationScheduleRun) error
+	FindRunsByStatus(status HibernationRunStatus) ([]*HibernationScheduleRun, error)
+	FindRunsByScheduleId(scheduleId int, limit int) ([]*HibernationScheduleRun, error)
+}
+
+type HibernationScheduleRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewHibernationScheduleRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *HibernationScheduleRepositoryImpl {
+	return &HibernationScheduleRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl HibernationScheduleRepositoryImpl) SaveSchedule(schedule *HibernationSchedule) error {
+	return impl.dbConnection.Insert(schedule)
+}
+
+func (impl HibernationScheduleRepositoryImpl) UpdateSchedule(schedule *HibernationSchedule) error {
+	return impl.dbConnection.Update(schedule)
+}
+
+func (impl HibernationScheduleRepositoryImpl) FindActiveScheduleById(id int) (*HibernationSchedule, error) {
+	schedule := &HibernationSchedule{}
+	err := impl.dbConnection.Model(schedule).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return schedule, err
+}
+
+func (impl HibernationScheduleRepositoryImpl) FindAllActiveSchedules() ([]*HibernationSchedule, error) {
+	var schedules []*HibernationSchedule
+	err := impl.dbConnection.Model(&schedules).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return schedules, err
+}
+
+func (impl HibernationScheduleRepositoryImpl) FindDueSchedules(now time.Time) ([]*HibernationSchedule, error) {
+	var schedules []*HibernationSchedule
+	err := impl.dbConnection.Model(&schedules).
+		Where("active = ?", true).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("next_hibernate_on <= ?", now).
+				WhereOr("next_wake_on <= ?", now)
+			return q, nil
+		}).
+		Select()
+	return schedules, err
+}
+
+func (impl HibernationScheduleRepositoryImpl) ClaimRun(id int, action HibernationAction, currentRunOn time.Time, nextRunOn time.Time) (bool, error) {
+	column := "next_hibernate_on"
+	if action == HIBERNATION_ACTION_WAKE {
+		column = "next_wake_on"
+	}
+	res, err := impl.dbConnection.Model((*HibernationSchedule)(nil)).
+		Set(column+" = ?", nextRunOn).
+		Where("id = ?", id).
+		Where(column+" = ?", currentRunOn).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl HibernationScheduleRepositoryImpl) SaveRun(run *HibernationScheduleRun) error {
+	return impl.dbConnection.Insert(run)
+}
+
+func (impl HibernationScheduleRepositoryImpl) UpdateRun(run *HibernationScheduleRun) error {
+	return impl.dbConnection.Update(run)
+}
+
+func (impl HibernationScheduleRepositoryImpl) FindRunsByStatus(status HibernationRunStatus) ([]*HibernationScheduleRun, error) {
+	var runs []*HibernationScheduleRun
+	err := impl.dbConnection.Model(&runs).
+		Where("status = ?", status).
+		Select()
+	return runs, err
+}
+
+func (impl HibernationScheduleRepositoryImpl) FindRunsByScheduleId(scheduleId int, limit int) ([]*HibernationScheduleRun, error) {
+	var runs []*HibernationScheduleRun
+	err := impl.dbConnection.Model(&runs).
+		Where("schedule_id = ?", scheduleId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return runs, err
+}
@@ -33,6 +33,8 @@ type ConfigMapRepository interface {
 	GetByIdEnvLevel(id int) (*ConfigMapEnvModel, error)
 	GetAllEnvLevel() ([]ConfigMapEnvModel, error)
 	UpdateEnvLevel(model *ConfigMapEnvModel) (*ConfigMapEnvModel, error)
+	CreateEnvLevelWithTxn(model *ConfigMapEnvModel, tx *pg.Tx) (*ConfigMapEnvModel, error)
+	UpdateEnvLevelWithTxn(model *ConfigMapEnvModel, tx *pg.Tx) (*ConfigMapEnvModel, error)
 
 	GetByAppIdAppLevel(appId int) (*ConfigMapAppModel, error)
 	GetByAppIdAndEnvIdEnvLevel(appId int, envId int) (*ConfigMapEnvModel, error)
@@ -133,6 +135,24 @@ func (impl ConfigMapRepositoryImpl) UpdateEnvLevel(model *ConfigMapEnvModel) (*C
 	return model, nil
 }
 
+func (impl ConfigMapRepositoryImpl) CreateEnvLevelWithTxn(model *ConfigMapEnvModel, tx *pg.Tx) (*ConfigMapEnvModel, error) {
+	err := tx.Insert(model)
+	if err != nil {
+		impl.Logger.Errorw("err on config map ", "err;", err)
+		return model, err
+	}
+	return model, nil
+}
+
+func (impl ConfigMapRepositoryImpl) UpdateEnvLevelWithTxn(model *ConfigMapEnvModel, tx *pg.Tx) (*ConfigMapEnvModel, error) {
+	err := tx.Update(model)
+	if err != nil {
+		impl.Logger.Errorw("err on config map ", "err;", err)
+		return model, err
+	}
+	return model, nil
+}
+
 func (impl ConfigMapRepositoryImpl) GetByAppIdAndEnvIdEnvLevel(appId int, envId int) (*ConfigMapEnvModel, error) {
 	var model ConfigMapEnvModel
 	err := impl.dbConnection.Model(&model).Where("app_id = ?", appId).Where("environment_id = ?", envId).Select()
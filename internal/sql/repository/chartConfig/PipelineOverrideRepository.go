@@ -58,6 +58,8 @@ type PipelineOverrideRepository interface {
 	GetLatestConfigByEnvironmentConfigOverrideId(envConfigOverrideId int) (pipelineOverride *PipelineOverride, err error)
 	Update(pipelineOverride *PipelineOverride) error
 	GetCurrentPipelineReleaseCounter(pipelineId int) (releaseCounter int, err error)
+	// GetCurrentPipelineEnvReleaseCounter returns the counter of the latest release on the pipeline's own environment
+	GetCurrentPipelineEnvReleaseCounter(pipelineId int) (releaseCounter int, err error)
 	GetByPipelineIdAndReleaseNo(pipelineId, releaseNo int) (pipelineOverrides []*PipelineOverride, err error)
 	GetAllRelease(appId, environmentId int) (pipelineOverrides []*PipelineOverride, err error)
 	FindByPipelineTriggerGitHash(gitHash string) (pipelineOverride *PipelineOverride, err error)
@@ -70,6 +72,9 @@ type PipelineOverrideRepository interface {
 	FindLatestByAppIdAndEnvId(appId, environmentId int) (pipelineOverrides *PipelineOverride, err error)
 }
 
+// pipelineEnvReleaseCondition skips releases of fan-out rollouts, which deploy a pipeline on environments other than its own
+const pipelineEnvReleaseCondition = "pipeline_override.env_config_override_id IN (SELECT ceco.id FROM chart_env_config_override ceco WHERE ceco.target_environment = pipeline.environment_id)"
+
 type PipelineOverrideRepositoryImpl struct {
 	dbConnection *pg.DB
 }
@@ -132,6 +137,25 @@ func (impl PipelineOverrideRepositoryImpl) GetCurrentPipelineReleaseCounter(pipe
 	}
 }
 
+func (impl PipelineOverrideRepositoryImpl) GetCurrentPipelineEnvReleaseCounter(pipelineId int) (releaseCounter int, err error) {
+	var counter int
+	err = impl.dbConnection.Model((*PipelineOverride)(nil)).
+		Column("pipeline_override.pipeline_release_counter").
+		Join("INNER JOIN pipeline ON pipeline.id = pipeline_override.pipeline_id").
+		Where("pipeline_override.pipeline_id = ?", pipelineId).
+		Where(pipelineEnvReleaseCondition).
+		Order("pipeline_override.id DESC").
+		Limit(1).
+		Select(&counter)
+	if err != nil && util.IsErrNoRows(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	} else {
+		return counter, nil
+	}
+}
+
 func (impl PipelineOverrideRepositoryImpl) GetByPipelineIdAndReleaseNo(pipelineId, releaseNo int) (pipelineOverrides []*PipelineOverride, err error) {
 	var overrides []*PipelineOverride
 	err = impl.dbConnection.Model(&overrides).
@@ -152,6 +176,7 @@ func (impl PipelineOverrideRepositoryImpl) GetAllRelease(appId, environmentId in
 		Column("pipeline_override.*", "Pipeline", "CiArtifact").
 		Where("pipeline.app_id =? ", appId).
 		Where("pipeline.environment_id =?", environmentId).
+		Where(pipelineEnvReleaseCondition).
 		Order("id ASC").
 		Select()
 	return overrides, err
@@ -163,6 +188,7 @@ func (impl PipelineOverrideRepositoryImpl) GetByDeployedImage(appId, environment
 		Column("pipeline_override.*", "Pipeline", "CiArtifact").
 		Where("pipeline.app_id =? ", appId).
 		Where("pipeline.environment_id =?", environmentId).
+		Where(pipelineEnvReleaseCondition).
 		Where("ci_artifact.image in (?)", pg.In(images)).
 		Order("id Desc").
 		Limit(1).
@@ -176,6 +202,7 @@ func (impl PipelineOverrideRepositoryImpl) GetLatestRelease(appId, environmentId
 		Column("pipeline_override.*", "Pipeline", "CiArtifact").
 		Where("pipeline.app_id =? ", appId).
 		Where("pipeline.environment_id =?", environmentId).
+		Where(pipelineEnvReleaseCondition).
 		Order("id DESC").
 		Limit(1).
 		Select()
@@ -243,6 +270,7 @@ func (impl PipelineOverrideRepositoryImpl) FindLatestByAppIdAndEnvId(appId, envi
 		Column("pipeline_override.*", "Pipeline", "CiArtifact").
 		Where("pipeline.app_id =? ", appId).
 		Where("pipeline.environment_id =?", environmentId).
+		Where(pipelineEnvReleaseCondition).
 		Order("id DESC").Limit(1).
 		Select()
 	return &override, err
@@ -0,0 +1,88 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ArtifactPromotionStatus string
+
+const (
+	ARTIFACT_PROMOTION_STATUS_PROMOTED ArtifactPromotionStatus = "PROMOTED"
+	ARTIFACT_PROMOTION_STATUS_BLOCKED  ArtifactPromotionStatus = "BLOCKED"
+	ARTIFACT_PROMOTION_STATUS_FAILED   ArtifactPromotionStatus = "FAILED"
+)
+
+type ArtifactPromotionPolicy struct {
+	tableName            struct{} `sql:"artifact_promotion_policy" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	PipelineId           int      `sql:"pipeline_id,notnull"`
+	SourceEnvironmentIds []int    `sql:"source_environment_ids" pg:",array"`
+	RequirePostCd        bool     `sql:"require_post_cd,notnull"`
+	RequireImageScan     bool     `sql:"require_image_scan,notnull"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type ArtifactPromotionAudit struct {
+	tableName    struct{}                `sql:"artifact_promotion_audit" pg:",discard_unknown_columns"`
+	Id           int                     `sql:"id,pk"`
+	PipelineId   int                     `sql:"pipeline_id,notnull"`
+	CiArtifactId int                     `sql:"ci_artifact_id,notnull"`
+	Status       ArtifactPromotionStatus `sql:"status,notnull"`
+	Reason       string                  `sql:"reason"`
+	sql.AuditLog
+}
+
+type ArtifactPromotionRepository interface {
+	FindActivePolicyByPipelineId(pipelineId int) (*ArtifactPromotionPolicy, error)
+	SavePolicy(policy *ArtifactPromotionPolicy) error
+	UpdatePolicy(policy *ArtifactPromotionPolicy) error
+	SaveAudit(audit *ArtifactPromotionAudit) error
+	FindAuditsByPipelineId(pipelineId int, limit int) ([]*ArtifactPromotionAudit, error)
+}
+
+type ArtifactPromotionRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewArtifactPromotionRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ArtifactPromotionRepositoryImpl {
+	return &ArtifactPromotionRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *ArtifactPromotionRepositoryImpl) FindActivePolicyByPipelineId(pipelineId int) (*ArtifactPromotionPolicy, error) {
+	policy := &ArtifactPromotionPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("pipeline_id = ?", pipelineId).
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return policy, err
+}
+
+func (impl *ArtifactPromotionRepositoryImpl) SavePolicy(policy *ArtifactPromotionPolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl *ArtifactPromotionRepositoryImpl) UpdatePolicy(policy *ArtifactPromotionPolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl *ArtifactPromotionRepositoryImpl) SaveAudit(audit *ArtifactPromotionAudit) error {
+	return impl.dbConnection.Insert(audit)
+}
+
+func (impl *ArtifactPromotionRepositoryImpl) FindAuditsByPipelineId(pipelineId int, limit int) ([]*ArtifactPromotionAudit, error) {
+	var audits []*ArtifactPromotionAudit
+	err := impl.dbConnection.Model(&audits).
+		Where("pipeline_id = ?", pipelineId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return audits, err
+}
@@ -0,0 +1,111 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type AutoRollbackStatus string
+
+const (
+	AUTO_ROLLBACK_STATUS_TRIGGERED AutoRollbackStatus = "TRIGGERED"
+	AUTO_ROLLBACK_STATUS_FAILED    AutoRollbackStatus = "FAILED"
+	AUTO_ROLLBACK_STATUS_SKIPPED   AutoRollbackStatus = "SKIPPED"
+)
+
+type AutoRollbackPolicy struct {
+	tableName     struct{} `sql:"auto_rollback_policy" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	PipelineId    int      `sql:"pipeline_id,notnull"`
+	OnDegraded    bool     `sql:"on_degraded,notnull"`
+	OnFailed      bool     `sql:"on_failed,notnull"`
+	WindowMinutes int      `sql:"window_minutes,notnull"`
+	Active        bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type AutoRollbackAudit struct {
+	tableName                  struct{}           `sql:"auto_rollback_audit" pg:",discard_unknown_columns"`
+	Id                         int                `sql:"id,pk"`
+	PipelineId                 int                `sql:"pipeline_id,notnull"`
+	SourceCdWorkflowRunnerId   int                `sql:"source_cd_workflow_runner_id,notnull"`
+	TargetCdWorkflowRunnerId   int                `sql:"target_cd_workflow_runner_id"`
+	RollbackCdWorkflowRunnerId int                `sql:"rollback_cd_workflow_runner_id"`
+	CiArtifactId               int                `sql:"ci_artifact_id"`
+	TriggerStatus              TimelineStatus     `sql:"trigger_status,notnull"`
+	Status                     AutoRollbackStatus `sql:"status,notnull"`
+	Message                    string             `sql:"message"`
+	sql.AuditLog
+}
+
+type AutoRollbackRepository interface {
+	FindActivePolicyByPipelineId(pipelineId int) (*AutoRollbackPolicy, error)
+	SavePolicy(policy *AutoRollbackPolicy) error
+	UpdatePolicy(policy *AutoRollbackPolicy) error
+	SaveAudit(audit *AutoRollbackAudit) error
+	UpdateAudit(audit *AutoRollbackAudit) error
+	ExistsAuditBySourceWfrId(wfrId int) (bool, error)
+	ExistsAuditByRollbackWfrId(wfrId int) (bool, error)
+	FindAuditsByPipelineId(pipelineId int, limit int) ([]*AutoRollbackAudit, error)
+}
+
+type AutoRollbackRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewAutoRollbackRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *AutoRollbackRepositoryImpl {
+	return &AutoRollbackRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *AutoRollbackRepositoryImpl) FindActivePolicyByPipelineId(pipelineId int) (*AutoRollbackPolicy, error) {
+	policy := &AutoRollbackPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("pipeline_id = ?", pipelineId).
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return policy, err
+}
+
+func (impl *AutoRollbackRepositoryImpl) SavePolicy(policy *AutoRollbackPolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl *AutoRollbackRepositoryImpl) UpdatePolicy(policy *AutoRollbackPolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl *AutoRollbackRepositoryImpl) SaveAudit(audit *AutoRollbackAudit) error {
+	return impl.dbConnection.Insert(audit)
+}
+
+func (impl *AutoRollbackRepositoryImpl) UpdateAudit(audit *AutoRollbackAudit) error {
+	return impl.dbConnection.Update(audit)
+}
+
+func (impl *AutoRollbackRepositoryImpl) ExistsAuditBySourceWfrId(wfrId int) (bool, error) {
+	return impl.dbConnection.Model((*AutoRollbackAudit)(nil)).
+		Where("source_cd_workflow_runner_id = ?", wfrId).
+		Exists()
+}
+
+func (impl *AutoRollbackRepositoryImpl) ExistsAuditByRollbackWfrId(wfrId int) (bool, error) {
+	return impl.dbConnection.Model((*AutoRollbackAudit)(nil)).
+		Where("rollback_cd_workflow_runner_id = ?", wfrId).
+		Exists()
+}
+
+func (impl *AutoRollbackRepositoryImpl) FindAuditsByPipelineId(pipelineId int, limit int) ([]*AutoRollbackAudit, error) {
+	var audits []*AutoRollbackAudit
+	err := impl.dbConnection.Model(&audits).
+		Where("pipeline_id = ?", pipelineId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return audits, err
+}
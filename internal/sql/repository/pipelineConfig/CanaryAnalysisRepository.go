@@ -0,0 +1,153 @@
+package pipelineConfig
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type CanaryAnalysisRunStatus string
+
+const (
+	CANARY_ANALYSIS_RUN_STATUS_RUNNING   CanaryAnalysisRunStatus = "RUNNING"
+	CANARY_ANALYSIS_RUN_STATUS_PROMOTED  CanaryAnalysisRunStatus = "PROMOTED"
+	CANARY_ANALYSIS_RUN_STATUS_ABORTED   CanaryAnalysisRunStatus = "ABORTED"
+	CANARY_ANALYSIS_RUN_STATUS_FAILED    CanaryAnalysisRunStatus = "FAILED"
+	CANARY_ANALYSIS_RUN_STATUS_CANCELLED CanaryAnalysisRunStatus = "CANCELLED"
+)
+
+// CanaryAnalysisConfig holds the success criteria of canary deployments of a pipeline, metrics is a json list of
+// PromQL queries with the threshold each of them is compared against
+type CanaryAnalysisConfig struct {
+	tableName       struct{} `sql:"canary_analysis_config" pg:",discard_unknown_columns"`
+	Id              int      `sql:"id,pk"`
+	PipelineId      int      `sql:"pipeline_id,notnull"`
+	IntervalSeconds int      `sql:"interval_seconds,notnull"`
+	IntervalCount   int      `sql:"interval_count,notnull"`
+	FailureLimit    int      `sql:"failure_limit,notnull"`
+	Metrics         string   `sql:"metrics,notnull"`
+	Active          bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type CanaryAnalysisRun struct {
+	tableName                  struct{}                `sql:"canary_analysis_run" pg:",discard_unknown_columns"`
+	Id                         int                     `sql:"id,pk"`
+	PipelineId                 int                     `sql:"pipeline_id,notnull"`
+	CdWorkflowRunnerId         int                     `sql:"cd_workflow_runner_id,notnull"`
+	RollbackCdWorkflowRunnerId int                     `sql:"rollback_cd_workflow_runner_id"`
+	Status                     CanaryAnalysisRunStatus `sql:"status,notnull"`
+	IntervalsCompleted         int                     `sql:"intervals_completed,notnull"`
+	IntervalsFailed            int                     `sql:"intervals_failed,notnull"`
+	NextAnalysisOn             time.Time               `sql:"next_analysis_on,notnull"`
+	Message                    string                  `sql:"message"`
+	sql.AuditLog
+}
+
+type CanaryAnalysisRepository interface {
+	FindActiveConfigByPipelineId(pipelineId int) (*CanaryAnalysisConfig, error)
+	FindAllActiveConfigs() ([]*CanaryAnalysisConfig, error)
+	SaveConfig(config *CanaryAnalysisConfig) error
+	UpdateConfig(config *CanaryAnalysisConfig) error
+	SaveRun(run *CanaryAnalysisRun) error
+	UpdateRun(run *CanaryAnalysisRun) error
+	// ClaimRun moves next_analysis_on of a running analysis forward only if it is still the value read, so that
+	// an interval is analysed by a single orchestrator instance
+	ClaimRun(id int, currentNextAnalysisOn time.Time, nextAnalysisOn time.Time) (bool, error)
+	FindRunsByStatus(status CanaryAnalysisRunStatus) ([]*CanaryAnalysisRun, error)
+	FindRunsByPipelineId(pipelineId int, limit int) ([]*CanaryAnalysisRun, error)
+	ExistsRunByWfrId(wfrId int) (bool, error)
+	ExistsRunByRollbackWfrId(wfrId int) (bool, error)
+}
+
+type CanaryAnalysisRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewCanaryAnalysisRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *CanaryAnalysisRepositoryImpl {
+	return &CanaryAnalysisRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) FindActiveConfigByPipelineId(pipelineId int) (*CanaryAnalysisConfig, error) {
+	config := &CanaryAnalysisConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("pipeline_id = ?", pipelineId).
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return config, err
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) FindAllActiveConfigs() ([]*CanaryAnalysisConfig, error) {
+	var configs []*CanaryAnalysisConfig
+	err := impl.dbConnection.Model(&configs).
+		Where("active = ?", true).
+		Select()
+	return configs, err
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) SaveConfig(config *CanaryAnalysisConfig) error {
+	return impl.dbConnection.Insert(config)
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) UpdateConfig(config *CanaryAnalysisConfig) error {
+	return impl.dbConnection.Update(config)
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) SaveRun(run *CanaryAnalysisRun) error {
+	return impl.dbConnection.Insert(run)
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) UpdateRun(run *CanaryAnalysisRun) error {
+	return impl.dbConnection.Update(run)
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) ClaimRun(id int, currentNextAnalysisOn time.Time, nextAnalysisOn time.Time) (bool, error) {
+	res, err := impl.dbConnection.Model((*CanaryAnalysisRun)(nil)).
+		Set("next_analysis_on = ?", nextAnalysisOn).
+		Where("id = ?", id).
+		Where("status = ?", CANARY_ANALYSIS_RUN_STATUS_RUNNING).
+		Where("next_analysis_on = ?", currentNextAnalysisOn).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) FindRunsByStatus(status CanaryAnalysisRunStatus) ([]*CanaryAnalysisRun, error) {
+	var runs []*CanaryAnalysisRun
+	err := impl.dbConnection.Model(&runs).
+		Where("status = ?", status).
+		Order("id ASC").
+		Select()
+	return runs, err
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) FindRunsByPipelineId(pipelineId int, limit int) ([]*CanaryAnalysisRun, error) {
+	var runs []*CanaryAnalysisRun
+	err := impl.dbConnection.Model(&runs).
+		Where("pipeline_id = ?", pipelineId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return runs, err
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) ExistsRunByWfrId(wfrId int) (bool, error) {
+	return impl.dbConnection.Model(&CanaryAnalysisRun{}).
+		Where("cd_workflow_runner_id = ?", wfrId).
+		Exists()
+}
+
+func (impl *CanaryAnalysisRepositoryImpl) ExistsRunByRollbackWfrId(wfrId int) (bool, error) {
+	return impl.dbConnection.Model(&CanaryAnalysisRun{}).
+		Where("rollback_cd_workflow_runner_id = ?", wfrId).
+		Exists()
+}
@@ -0,0 +1,272 @@
+package pipelineConfig
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type CdFanOutStrategy string
+
+const (
+	CD_FAN_OUT_STRATEGY_SEQUENTIAL CdFanOutStrategy = "SEQUENTIAL"
+	CD_FAN_OUT_STRATEGY_PARALLEL   CdFanOutStrategy = "PARALLEL"
+)
+
+type CdFanOutExecutionStatus string
+
+const (
+	CD_FAN_OUT_EXECUTION_STATUS_RUNNING    CdFanOutExecutionStatus = "RUNNING"
+	CD_FAN_OUT_EXECUTION_STATUS_PAUSED     CdFanOutExecutionStatus = "PAUSED"
+	CD_FAN_OUT_EXECUTION_STATUS_SUCCEEDED  CdFanOutExecutionStatus = "SUCCEEDED"
+	CD_FAN_OUT_EXECUTION_STATUS_FAILED     CdFanOutExecutionStatus = "FAILED"
+	CD_FAN_OUT_EXECUTION_STATUS_SUPERSEDED CdFanOutExecutionStatus = "SUPERSEDED"
+)
+
+type CdFanOutTargetStatus string
+
+const (
+	CD_FAN_OUT_TARGET_STATUS_PENDING   CdFanOutTargetStatus = "PENDING"
+	CD_FAN_OUT_TARGET_STATUS_TRIGGERED CdFanOutTargetStatus = "TRIGGERED"
+	CD_FAN_OUT_TARGET_STATUS_SUCCEEDED CdFanOutTargetStatus = "SUCCEEDED"
+	CD_FAN_OUT_TARGET_STATUS_FAILED    CdFanOutTargetStatus = "FAILED"
+	CD_FAN_OUT_TARGET_STATUS_SKIPPED   CdFanOutTargetStatus = "SKIPPED"
+)
+
+// CdFanOutConfig makes a cd pipeline deploy to additional environments, the pipeline's own environment is wave 0
+type CdFanOutConfig struct {
+	tableName              struct{}         `sql:"cd_fan_out_config" pg:",discard_unknown_columns"`
+	Id                     int              `sql:"id,pk"`
+	PipelineId             int              `sql:"pipeline_id,notnull"`
+	Strategy               CdFanOutStrategy `sql:"strategy,notnull"`
+	PauseBetweenWaves      bool             `sql:"pause_between_waves,notnull"`
+	LastCdWorkflowRunnerId int              `sql:"last_cd_workflow_runner_id"`
+	Active                 bool             `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// CdFanOutTarget is an additional environment of a fan-out pipeline, it is deployed by the fan-out pipeline itself
+// with the environment's override and has its own deployment app
+type CdFanOutTarget struct {
+	tableName            struct{} `sql:"cd_fan_out_target" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	CdFanOutConfigId     int      `sql:"cd_fan_out_config_id,notnull"`
+	EnvironmentId        int      `sql:"environment_id,notnull"`
+	Wave                 int      `sql:"wave,notnull"`
+	DeploymentAppCreated bool     `sql:"deployment_app_created,notnull"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// CdFanOutExecution tracks one fan-out rollout against the cd workflow of the fan-out pipeline's deployment
+type CdFanOutExecution struct {
+	tableName          struct{}                `sql:"cd_fan_out_execution" pg:",discard_unknown_columns"`
+	Id                 int                     `sql:"id,pk"`
+	CdFanOutConfigId   int                     `sql:"cd_fan_out_config_id,notnull"`
+	CdWorkflowId       int                     `sql:"cd_workflow_id,notnull"`
+	CdWorkflowRunnerId int                     `sql:"cd_workflow_runner_id,notnull"`
+	CiArtifactId       int                     `sql:"ci_artifact_id,notnull"`
+	CurrentWave        int                     `sql:"current_wave,notnull"`
+	Status             CdFanOutExecutionStatus `sql:"status,notnull"`
+	sql.AuditLog
+}
+
+// CdFanOutExecutionTarget is the deployment of one target environment in a rollout, recorded as a fan-out runner
+// of the rollout's cd workflow
+type CdFanOutExecutionTarget struct {
+	tableName           struct{}             `sql:"cd_fan_out_execution_target" pg:",discard_unknown_columns"`
+	Id                  int                  `sql:"id,pk"`
+	CdFanOutExecutionId int                  `sql:"cd_fan_out_execution_id,notnull"`
+	EnvironmentId       int                  `sql:"environment_id,notnull"`
+	Wave                int                  `sql:"wave,notnull"`
+	CdWorkflowRunnerId  int                  `sql:"cd_workflow_runner_id"`
+	PipelineOverrideId  int                  `sql:"pipeline_override_id"`
+	Status              CdFanOutTargetStatus `sql:"status,notnull"`
+	Message             string               `sql:"message"`
+	sql.AuditLog
+}
+
+type CdFanOutRepository interface {
+	SaveConfig(config *CdFanOutConfig) error
+	UpdateConfig(config *CdFanOutConfig) error
+	FindActiveConfigByPipelineId(pipelineId int) (*CdFanOutConfig, error)
+	FindActiveConfigsByPipelineIds(pipelineIds []int) ([]*CdFanOutConfig, error)
+	FindAllActiveConfigs() ([]*CdFanOutConfig, error)
+	FindConfigById(id int) (*CdFanOutConfig, error)
+	// ClaimDeployment records the deployment as fanned out, false if another instance claimed it first
+	ClaimDeployment(configId int, lastCdWorkflowRunnerId int, cdWorkflowRunnerId int) (bool, error)
+
+	SaveTarget(target *CdFanOutTarget) error
+	UpdateTarget(target *CdFanOutTarget) error
+	FindActiveTargetsByConfigId(configId int) ([]*CdFanOutTarget, error)
+	FindActiveTargetsByAppIdAndEnvId(appId int, envId int) ([]*CdFanOutTarget, error)
+
+	SaveExecution(execution *CdFanOutExecution) error
+	UpdateExecution(execution *CdFanOutExecution) error
+	// ClaimExecution bumps updated_on of the execution if it was not updated since it was read, only the instance
+	// which claims the execution moves it forward
+	ClaimExecution(id int, currentUpdatedOn time.Time, updatedOn time.Time) (bool, error)
+	FindExecutionsByStatus(statuses []CdFanOutExecutionStatus) ([]*CdFanOutExecution, error)
+	FindLatestExecutionByConfigId(configId int) (*CdFanOutExecution, error)
+
+	SaveExecutionTarget(target *CdFanOutExecutionTarget) error
+	UpdateExecutionTarget(target *CdFanOutExecutionTarget) error
+	FindExecutionTargetsByExecutionId(executionId int) ([]*CdFanOutExecutionTarget, error)
+}
+
+type CdFanOutRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewCdFanOutRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *CdFanOutRepositoryImpl {
+	return &CdFanOutRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *CdFanOutRepositoryImpl) SaveConfig(config *CdFanOutConfig) error {
+	return impl.dbConnection.Insert(config)
+}
+
+func (impl *CdFanOutRepositoryImpl) UpdateConfig(config *CdFanOutConfig) error {
+	return impl.dbConnection.Update(config)
+}
+
+func (impl *CdFanOutRepositoryImpl) FindActiveConfigByPipelineId(pipelineId int) (*CdFanOutConfig, error) {
+	config := &CdFanOutConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("pipeline_id = ?", pipelineId).
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return config, err
+}
+
+func (impl *CdFanOutRepositoryImpl) FindActiveConfigsByPipelineIds(pipelineIds []int) ([]*CdFanOutConfig, error) {
+	var configs []*CdFanOutConfig
+	err := impl.dbConnection.Model(&configs).
+		Where("pipeline_id in (?)", pg.In(pipelineIds)).
+		Where("active = ?", true).
+		Select()
+	return configs, err
+}
+
+func (impl *CdFanOutRepositoryImpl) FindAllActiveConfigs() ([]*CdFanOutConfig, error) {
+	var configs []*CdFanOutConfig
+	err := impl.dbConnection.Model(&configs).
+		Where("active = ?", true).
+		Select()
+	return configs, err
+}
+
+func (impl *CdFanOutRepositoryImpl) FindConfigById(id int) (*CdFanOutConfig, error) {
+	config := &CdFanOutConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("id = ?", id).
+		Select()
+	return config, err
+}
+
+func (impl *CdFanOutRepositoryImpl) ClaimDeployment(configId int, lastCdWorkflowRunnerId int, cdWorkflowRunnerId int) (bool, error) {
+	res, err := impl.dbConnection.Model((*CdFanOutConfig)(nil)).
+		Set("last_cd_workflow_runner_id = ?", cdWorkflowRunnerId).
+		Where("id = ?", configId).
+		Where("COALESCE(last_cd_workflow_runner_id, 0) = ?", lastCdWorkflowRunnerId).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *CdFanOutRepositoryImpl) SaveTarget(target *CdFanOutTarget) error {
+	return impl.dbConnection.Insert(target)
+}
+
+func (impl *CdFanOutRepositoryImpl) UpdateTarget(target *CdFanOutTarget) error {
+	return impl.dbConnection.Update(target)
+}
+
+func (impl *CdFanOutRepositoryImpl) FindActiveTargetsByConfigId(configId int) ([]*CdFanOutTarget, error) {
+	var targets []*CdFanOutTarget
+	err := impl.dbConnection.Model(&targets).
+		Where("cd_fan_out_config_id = ?", configId).
+		Where("active = ?", true).
+		Order("wave ASC", "id ASC").
+		Select()
+	return targets, err
+}
+
+func (impl *CdFanOutRepositoryImpl) FindActiveTargetsByAppIdAndEnvId(appId int, envId int) ([]*CdFanOutTarget, error) {
+	var targets []*CdFanOutTarget
+	err := impl.dbConnection.Model(&targets).
+		Join("INNER JOIN cd_fan_out_config cfc ON cfc.id = cd_fan_out_target.cd_fan_out_config_id").
+		Join("INNER JOIN pipeline p ON p.id = cfc.pipeline_id").
+		Where("p.app_id = ?", appId).
+		Where("p.deleted = ?", false).
+		Where("cfc.active = ?", true).
+		Where("cd_fan_out_target.environment_id = ?", envId).
+		Where("cd_fan_out_target.active = ?", true).
+		Select()
+	return targets, err
+}
+
+func (impl *CdFanOutRepositoryImpl) SaveExecution(execution *CdFanOutExecution) error {
+	return impl.dbConnection.Insert(execution)
+}
+
+func (impl *CdFanOutRepositoryImpl) UpdateExecution(execution *CdFanOutExecution) error {
+	return impl.dbConnection.Update(execution)
+}
+
+func (impl *CdFanOutRepositoryImpl) ClaimExecution(id int, currentUpdatedOn time.Time, updatedOn time.Time) (bool, error) {
+	res, err := impl.dbConnection.Model((*CdFanOutExecution)(nil)).
+		Set("updated_on = ?", updatedOn).
+		Where("id = ?", id).
+		Where("updated_on = ?", currentUpdatedOn).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *CdFanOutRepositoryImpl) FindExecutionsByStatus(statuses []CdFanOutExecutionStatus) ([]*CdFanOutExecution, error) {
+	var executions []*CdFanOutExecution
+	err := impl.dbConnection.Model(&executions).
+		Where("status in (?)", pg.In(statuses)).
+		Order("id ASC").
+		Select()
+	return executions, err
+}
+
+func (impl *CdFanOutRepositoryImpl) FindLatestExecutionByConfigId(configId int) (*CdFanOutExecution, error) {
+	execution := &CdFanOutExecution{}
+	err := impl.dbConnection.Model(execution).
+		Where("cd_fan_out_config_id = ?", configId).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	return execution, err
+}
+
+func (impl *CdFanOutRepositoryImpl) SaveExecutionTarget(target *CdFanOutExecutionTarget) error {
+	return impl.dbConnection.Insert(target)
+}
+
+func (impl *CdFanOutRepositoryImpl) UpdateExecutionTarget(target *CdFanOutExecutionTarget) error {
+	return impl.dbConnection.Update(target)
+}
+
+func (impl *CdFanOutRepositoryImpl) FindExecutionTargetsByExecutionId(executionId int) ([]*CdFanOutExecutionTarget, error) {
+	var targets []*CdFanOutExecutionTarget
+	err := impl.dbConnection.Model(&targets).
+		Where("cd_fan_out_execution_id = ?", executionId).
+		Order("wave ASC", "id ASC").
+		Select()
+	return targets, err
+}
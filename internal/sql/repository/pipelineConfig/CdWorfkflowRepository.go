@@ -40,6 +40,8 @@ type CdWorkflowRepository interface {
 	UpdateWorkFlowRunners(wfr []*CdWorkflowRunner) error
 	FindWorkflowRunnerByCdWorkflowId(wfIds []int) ([]*CdWorkflowRunner, error)
 	FindPreviousCdWfRunnerByStatus(pipelineId int, currentWFRunnerId int, status []string) ([]*CdWorkflowRunner, error)
+	FindLatestCdWfRunnerWithStatusBeforeId(pipelineId int, currentWFRunnerId int, status []string) (*CdWorkflowRunner, error)
+	ExistsByPipelineIdAndArtifactIdAndRunnerStatus(pipelineId int, artifactId int, runnerType bean.WorkflowType, status []string) (bool, error)
 	FindConfigByPipelineId(pipelineId int) (*CdWorkflowConfig, error)
 	FindWorkflowRunnerById(wfrId int) (*CdWorkflowRunner, error)
 	FindLatestWfrByAppIdAndEnvironmentId(appId int, environmentId int) (CdWorkflowRunner, error)
@@ -180,6 +182,17 @@ type CdWorkflowStatus struct {
 	PostStatus   string `json:"post_status"`
 	WorkflowType string `json:"workflow_type,omitempty"`
 	WfrId        int    `json:"wfr_id,omitempty"`
+	// FanOutStatus is the status of the latest rollout of a fan-out pipeline, DeployStatus then reflects all of its environments
+	FanOutStatus  string                `json:"fan_out_status,omitempty"`
+	FanOutTargets []*FanOutTargetStatus `json:"fan_out_targets,omitempty"`
+}
+
+type FanOutTargetStatus struct {
+	EnvironmentId int    `json:"environment_id"`
+	Wave          int    `json:"wave"`
+	WfrId         int    `json:"wfr_id,omitempty"`
+	Status        string `json:"status"`
+	DeployStatus  string `json:"deploy_status"`
 }
 
 type CiWorkflowStatus struct {
@@ -210,6 +223,32 @@ func (impl *CdWorkflowRepositoryImpl) FindPreviousCdWfRunnerByStatus(pipelineId
 	return runner, err
 }
 
+func (impl *CdWorkflowRepositoryImpl) FindLatestCdWfRunnerWithStatusBeforeId(pipelineId int, currentWFRunnerId int, status []string) (*CdWorkflowRunner, error) {
+	runner := &CdWorkflowRunner{}
+	err := impl.dbConnection.
+		Model(runner).
+		Column("cd_workflow_runner.*", "CdWorkflow", "CdWorkflow.CiArtifact").
+		Where("cd_workflow.pipeline_id = ?", pipelineId).
+		Where("cd_workflow_runner.id < ?", currentWFRunnerId).
+		Where("workflow_type = ? ", bean.CD_WORKFLOW_TYPE_DEPLOY).
+		Where("cd_workflow_runner.status in (?) ", pg.In(status)).
+		Order("cd_workflow_runner.id DESC").
+		Limit(1).
+		Select()
+	return runner, err
+}
+
+func (impl *CdWorkflowRepositoryImpl) ExistsByPipelineIdAndArtifactIdAndRunnerStatus(pipelineId int, artifactId int, runnerType bean.WorkflowType, status []string) (bool, error) {
+	return impl.dbConnection.
+		Model((*CdWorkflowRunner)(nil)).
+		Join("inner join cd_workflow cw on cw.id = cd_workflow_runner.cd_workflow_id").
+		Where("cw.pipeline_id = ?", pipelineId).
+		Where("cw.ci_artifact_id = ?", artifactId).
+		Where("cd_workflow_runner.workflow_type = ?", runnerType).
+		Where("cd_workflow_runner.status in (?)", pg.In(status)).
+		Exists()
+}
+
 func (impl *CdWorkflowRepositoryImpl) SaveWorkFlow(wf *CdWorkflow) error {
 	err := impl.dbConnection.Insert(wf)
 	return err
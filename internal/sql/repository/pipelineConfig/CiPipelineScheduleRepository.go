@@ -0,0 +1,112 @@
+package pipelineConfig
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type CiPipelineSchedule struct {
+	tableName        struct{}  `sql:"ci_pipeline_schedule" pg:",discard_unknown_columns"`
+	Id               int       `sql:"id,pk"`
+	CiPipelineId     int       `sql:"ci_pipeline_id,notnull"`
+	CronExpression   string    `sql:"cron_expression,notnull"`
+	Timezone         string    `sql:"timezone,notnull"`
+	Branch           string    `sql:"branch"`
+	OnlyOnNewCommits bool      `sql:"only_on_new_commits,notnull"`
+	Active           bool      `sql:"active,notnull"`
+	NextRunOn        time.Time `sql:"next_run_on"`
+	LastTriggeredOn  time.Time `sql:"last_triggered_on"`
+	LastCiWorkflowId int       `sql:"last_ci_workflow_id"`
+	LastCommitHashes string    `sql:"last_commit_hashes"`
+	sql.AuditLog
+}
+
+type CiPipelineScheduleRepository interface {
+	Save(schedule *CiPipelineSchedule) error
+	Update(schedule *CiPipelineSchedule) error
+	FindByCiPipelineId(ciPipelineId int) (*CiPipelineSchedule, error)
+	FindActiveByCiPipelineIds(ciPipelineIds []int) ([]*CiPipelineSchedule, error)
+	FindDueSchedules(now time.Time) ([]*CiPipelineSchedule, error)
+	// ClaimRun moves next_run_on forward only if it is still the value read, so that a run is
+	// picked by a single orchestrator instance
+	ClaimRun(id int, currentNextRunOn time.Time, nextRunOn time.Time) (bool, error)
+	// UpdateRunDetails only writes the bookkeeping of the last run, so that schedule changes saved while the
+	// build was being triggered are not overwritten
+	UpdateRunDetails(id int, lastCiWorkflowId int, lastCommitHashes string, lastTriggeredOn time.Time) error
+}
+
+type CiPipelineScheduleRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewCiPipelineScheduleRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *CiPipelineScheduleRepositoryImpl {
+	return &CiPipelineScheduleRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *CiPipelineScheduleRepositoryImpl) Save(schedule *CiPipelineSchedule) error {
+	return impl.dbConnection.Insert(schedule)
+}
+
+func (impl *CiPipelineScheduleRepositoryImpl) Update(schedule *CiPipelineSchedule) error {
+	return impl.dbConnection.Update(schedule)
+}
+
+func (impl *CiPipelineScheduleRepositoryImpl) FindByCiPipelineId(ciPipelineId int) (*CiPipelineSchedule, error) {
+	schedule := &CiPipelineSchedule{}
+	err := impl.dbConnection.Model(schedule).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Select()
+	return schedule, err
+}
+
+func (impl *CiPipelineScheduleRepositoryImpl) FindActiveByCiPipelineIds(ciPipelineIds []int) ([]*CiPipelineSchedule, error) {
+	var schedules []*CiPipelineSchedule
+	if len(ciPipelineIds) == 0 {
+		return schedules, nil
+	}
+	err := impl.dbConnection.Model(&schedules).
+		Where("ci_pipeline_id in (?)", pg.In(ciPipelineIds)).
+		Where("active = ?", true).
+		Select()
+	return schedules, err
+}
+
+func (impl *CiPipelineScheduleRepositoryImpl) FindDueSchedules(now time.Time) ([]*CiPipelineSchedule, error) {
+	var schedules []*CiPipelineSchedule
+	err := impl.dbConnection.Model(&schedules).
+		Where("active = ?", true).
+		Where("next_run_on <= ?", now).
+		Order("next_run_on ASC").
+		Select()
+	return schedules, err
+}
+
+func (impl *CiPipelineScheduleRepositoryImpl) ClaimRun(id int, currentNextRunOn time.Time, nextRunOn time.Time) (bool, error) {
+	res, err := impl.dbConnection.Model((*CiPipelineSchedule)(nil)).
+		Set("next_run_on = ?", nextRunOn).
+		Where("id = ?", id).
+		Where("next_run_on = ?", currentNextRunOn).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *CiPipelineScheduleRepositoryImpl) UpdateRunDetails(id int, lastCiWorkflowId int, lastCommitHashes string, lastTriggeredOn time.Time) error {
+	_, err := impl.dbConnection.Model((*CiPipelineSchedule)(nil)).
+		Set("last_ci_workflow_id = ?", lastCiWorkflowId).
+		Set("last_commit_hashes = ?", lastCommitHashes).
+		Set("last_triggered_on = ?", lastTriggeredOn).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Update()
+	return err
+}
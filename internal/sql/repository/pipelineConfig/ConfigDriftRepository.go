@@ -0,0 +1,132 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ConfigDriftStatus string
+
+const (
+	CONFIG_DRIFT_STATUS_IN_SYNC ConfigDriftStatus = "IN_SYNC"
+	CONFIG_DRIFT_STATUS_DRIFTED ConfigDriftStatus = "DRIFTED"
+	CONFIG_DRIFT_STATUS_MISSING ConfigDriftStatus = "MISSING"
+	CONFIG_DRIFT_STATUS_FAILED  ConfigDriftStatus = "FAILED"
+)
+
+type ConfigDriftScan struct {
+	tableName            struct{}          `sql:"config_drift_scan" pg:",discard_unknown_columns"`
+	Id                   int               `sql:"id,pk"`
+	PipelineId           int               `sql:"pipeline_id,notnull"`
+	AppId                int               `sql:"app_id,notnull"`
+	EnvironmentId        int               `sql:"environment_id,notnull"`
+	Status               ConfigDriftStatus `sql:"status,notnull"`
+	DriftedResourceCount int               `sql:"drifted_resource_count,notnull"`
+	Message              string            `sql:"message"`
+	sql.AuditLog
+}
+
+type ConfigDriftResource struct {
+	tableName     struct{}          `sql:"config_drift_resource" pg:",discard_unknown_columns"`
+	Id            int               `sql:"id,pk"`
+	ScanId        int               `sql:"scan_id,notnull"`
+	Group         string            `sql:"group"`
+	Version       string            `sql:"version,notnull"`
+	Kind          string            `sql:"kind,notnull"`
+	Name          string            `sql:"name,notnull"`
+	Namespace     string            `sql:"namespace"`
+	Status        ConfigDriftStatus `sql:"status,notnull"`
+	DriftedFields []string          `sql:"drifted_fields" pg:",array"`
+}
+
+type ConfigDriftRepository interface {
+	SaveScan(scan *ConfigDriftScan, resources []*ConfigDriftResource) error
+	FindLatestScanByPipelineId(pipelineId int) (*ConfigDriftScan, error)
+	FindLatestScansByAppId(appId int) ([]*ConfigDriftScan, error)
+	FindScansByPipelineId(pipelineId int, limit int) ([]*ConfigDriftScan, error)
+	FindResourcesByScanId(scanId int) ([]*ConfigDriftResource, error)
+	// DeleteScansBeforeLatest keeps only the latest n scans of the pipeline
+	DeleteScansBeforeLatest(pipelineId int, n int) error
+}
+
+type ConfigDriftRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewConfigDriftRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ConfigDriftRepositoryImpl {
+	return &ConfigDriftRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *ConfigDriftRepositoryImpl) SaveScan(scan *ConfigDriftScan, resources []*ConfigDriftResource) error {
+	tx, err := impl.dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = tx.Insert(scan)
+	if err != nil {
+		return err
+	}
+	if len(resources) > 0 {
+		for _, resource := range resources {
+			resource.ScanId = scan.Id
+		}
+		err = tx.Insert(&resources)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (impl *ConfigDriftRepositoryImpl) FindLatestScanByPipelineId(pipelineId int) (*ConfigDriftScan, error) {
+	scan := &ConfigDriftScan{}
+	err := impl.dbConnection.Model(scan).
+		Where("pipeline_id = ?", pipelineId).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	return scan, err
+}
+
+func (impl *ConfigDriftRepositoryImpl) FindLatestScansByAppId(appId int) ([]*ConfigDriftScan, error) {
+	var scans []*ConfigDriftScan
+	err := impl.dbConnection.Model(&scans).
+		Where("id IN (SELECT max(id) FROM config_drift_scan WHERE app_id = ? GROUP BY pipeline_id)", appId).
+		Order("environment_id ASC").
+		Select()
+	return scans, err
+}
+
+func (impl *ConfigDriftRepositoryImpl) FindScansByPipelineId(pipelineId int, limit int) ([]*ConfigDriftScan, error) {
+	var scans []*ConfigDriftScan
+	err := impl.dbConnection.Model(&scans).
+		Where("pipeline_id = ?", pipelineId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return scans, err
+}
+
+func (impl *ConfigDriftRepositoryImpl) FindResourcesByScanId(scanId int) ([]*ConfigDriftResource, error) {
+	var resources []*ConfigDriftResource
+	err := impl.dbConnection.Model(&resources).
+		Where("scan_id = ?", scanId).
+		Order("id ASC").
+		Select()
+	return resources, err
+}
+
+func (impl *ConfigDriftRepositoryImpl) DeleteScansBeforeLatest(pipelineId int, n int) error {
+	_, err := impl.dbConnection.Model((*ConfigDriftScan)(nil)).
+		Where("pipeline_id = ?", pipelineId).
+		Where("id NOT IN (SELECT id FROM config_drift_scan WHERE pipeline_id = ? ORDER BY id DESC LIMIT ?)", pipelineId, n).
+		Delete()
+	return err
+}
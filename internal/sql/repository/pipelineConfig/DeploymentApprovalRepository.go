@@ -0,0 +1,209 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type DeploymentApprovalStatus string
+
+const (
+	DEPLOYMENT_APPROVAL_REQUESTED DeploymentApprovalStatus = "REQUESTED"
+	DEPLOYMENT_APPROVAL_APPROVED  DeploymentApprovalStatus = "APPROVED"
+	DEPLOYMENT_APPROVAL_REJECTED  DeploymentApprovalStatus = "REJECTED"
+	DEPLOYMENT_APPROVAL_CANCELLED DeploymentApprovalStatus = "CANCELLED"
+	// DEPLOYMENT_APPROVAL_EXPIRED is never persisted, requests past their expiry are reported as expired
+	DEPLOYMENT_APPROVAL_EXPIRED DeploymentApprovalStatus = "EXPIRED"
+)
+
+type DeploymentApprovalAction string
+
+const (
+	DEPLOYMENT_APPROVAL_ACTION_APPROVE DeploymentApprovalAction = "APPROVE"
+	DEPLOYMENT_APPROVAL_ACTION_REJECT  DeploymentApprovalAction = "REJECT"
+)
+
+type DeploymentApprovalConfig struct {
+	tableName            struct{} `sql:"deployment_approval_config" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	PipelineId           int      `sql:"pipeline_id,notnull"`
+	RequiredApprovals    int      `sql:"required_approvals,notnull"`
+	ApproverUserIds      []int    `sql:"approver_user_ids" pg:",array"`
+	ApproverRoleGroupIds []int    `sql:"approver_role_group_ids" pg:",array"`
+	RequestExpiryHours   int      `sql:"request_expiry_hours,notnull"`
+	AllowSelfApproval    bool     `sql:"allow_self_approval,notnull"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// DeploymentApprovalRequest AutoTrigger is set on requests parking an auto triggered deployment, AutoTriggered is set
+// once the deployment is resumed after approval
+type DeploymentApprovalRequest struct {
+	tableName     struct{}                 `sql:"deployment_approval_request" pg:",discard_unknown_columns"`
+	Id            int                      `sql:"id,pk"`
+	PipelineId    int                      `sql:"pipeline_id,notnull"`
+	CiArtifactId  int                      `sql:"ci_artifact_id,notnull"`
+	Status        DeploymentApprovalStatus `sql:"status,notnull"`
+	Comment       string                   `sql:"comment"`
+	ExpiresOn     time.Time                `sql:"expires_on,notnull"`
+	AutoTrigger   bool                     `sql:"auto_trigger,notnull"`
+	AutoTriggered bool                     `sql:"auto_triggered,notnull"`
+	sql.AuditLog
+}
+
+type DeploymentApprovalUserAction struct {
+	tableName         struct{}                 `sql:"deployment_approval_user_action" pg:",discard_unknown_columns"`
+	Id                int                      `sql:"id,pk"`
+	ApprovalRequestId int                      `sql:"approval_request_id,notnull"`
+	UserId            int32                    `sql:"user_id,notnull"`
+	Action            DeploymentApprovalAction `sql:"action,notnull"`
+	Comment           string                   `sql:"comment"`
+	sql.AuditLog
+}
+
+type DeploymentApprovalRepository interface {
+	GetConnection() *pg.DB
+	FindActiveConfigByPipelineId(pipelineId int) (*DeploymentApprovalConfig, error)
+	SaveConfig(config *DeploymentApprovalConfig) error
+	UpdateConfig(config *DeploymentApprovalConfig) error
+	SaveRequest(request *DeploymentApprovalRequest) error
+	UpdateRequest(request *DeploymentApprovalRequest, tx *pg.Tx) error
+	FindRequestById(id int) (*DeploymentApprovalRequest, error)
+	FindRequestByIdForUpdate(id int, tx *pg.Tx) (*DeploymentApprovalRequest, error)
+	FindRequestsByPipelineId(pipelineId int, limit int) ([]*DeploymentApprovalRequest, error)
+	FindLatestRequestByPipelineIdAndArtifactId(pipelineId int, ciArtifactId int) (*DeploymentApprovalRequest, error)
+	FindApprovedAutoTriggerRequests() ([]*DeploymentApprovalRequest, error)
+	MarkAutoTriggered(id int) (bool, error)
+	SaveUserAction(userAction *DeploymentApprovalUserAction, tx *pg.Tx) error
+	FindUserActionsByRequestIds(requestIds []int) ([]*DeploymentApprovalUserAction, error)
+}
+
+type DeploymentApprovalRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewDeploymentApprovalRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *DeploymentApprovalRepositoryImpl {
+	return &DeploymentApprovalRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindActiveConfigByPipelineId(pipelineId int) (*DeploymentApprovalConfig, error) {
+	config := &DeploymentApprovalConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("pipeline_id = ?", pipelineId).
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return config, err
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) SaveConfig(config *DeploymentApprovalConfig) error {
+	return impl.dbConnection.Insert(config)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) UpdateConfig(config *DeploymentApprovalConfig) error {
+	return impl.dbConnection.Update(config)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) SaveRequest(request *DeploymentApprovalRequest) error {
+	return impl.dbConnection.Insert(request)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) UpdateRequest(request *DeploymentApprovalRequest, tx *pg.Tx) error {
+	if tx != nil {
+		return tx.Update(request)
+	}
+	return impl.dbConnection.Update(request)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindRequestById(id int) (*DeploymentApprovalRequest, error) {
+	request := &DeploymentApprovalRequest{}
+	err := impl.dbConnection.Model(request).
+		Where("id = ?", id).
+		Select()
+	return request, err
+}
+
+// FindRequestByIdForUpdate locks the request row so that concurrent approvals are counted one after another
+func (impl *DeploymentApprovalRepositoryImpl) FindRequestByIdForUpdate(id int, tx *pg.Tx) (*DeploymentApprovalRequest, error) {
+	request := &DeploymentApprovalRequest{}
+	err := tx.Model(request).
+		Where("id = ?", id).
+		For("UPDATE").
+		Select()
+	return request, err
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindRequestsByPipelineId(pipelineId int, limit int) ([]*DeploymentApprovalRequest, error) {
+	var requests []*DeploymentApprovalRequest
+	err := impl.dbConnection.Model(&requests).
+		Where("pipeline_id = ?", pipelineId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return requests, err
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindLatestRequestByPipelineIdAndArtifactId(pipelineId int, ciArtifactId int) (*DeploymentApprovalRequest, error) {
+	request := &DeploymentApprovalRequest{}
+	err := impl.dbConnection.Model(request).
+		Where("pipeline_id = ?", pipelineId).
+		Where("ci_artifact_id = ?", ciArtifactId).
+		Where("status != ?", DEPLOYMENT_APPROVAL_CANCELLED).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	return request, err
+}
+
+// FindApprovedAutoTriggerRequests returns the approved requests of parked auto triggers not yet deployed, latest first
+func (impl *DeploymentApprovalRepositoryImpl) FindApprovedAutoTriggerRequests() ([]*DeploymentApprovalRequest, error) {
+	var requests []*DeploymentApprovalRequest
+	err := impl.dbConnection.Model(&requests).
+		Where("status = ?", DEPLOYMENT_APPROVAL_APPROVED).
+		Where("auto_trigger = ?", true).
+		Where("auto_triggered = ?", false).
+		Where("expires_on > ?", time.Now()).
+		Order("id DESC").
+		Select()
+	return requests, err
+}
+
+// MarkAutoTriggered sets auto_triggered only if no one has set it yet, so that a single replica resumes the deployment
+func (impl *DeploymentApprovalRepositoryImpl) MarkAutoTriggered(id int) (bool, error) {
+	res, err := impl.dbConnection.Model((*DeploymentApprovalRequest)(nil)).
+		Set("auto_triggered = ?", true).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Where("auto_triggered = ?", false).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) SaveUserAction(userAction *DeploymentApprovalUserAction, tx *pg.Tx) error {
+	return tx.Insert(userAction)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindUserActionsByRequestIds(requestIds []int) ([]*DeploymentApprovalUserAction, error) {
+	var userActions []*DeploymentApprovalUserAction
+	if len(requestIds) == 0 {
+		return userActions, nil
+	}
+	err := impl.dbConnection.Model(&userActions).
+		Where("approval_request_id in (?)", pg.In(requestIds)).
+		Order("id ASC").
+		Select()
+	return userActions, err
+}
@@ -0,0 +1,252 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type DeploymentWindowType string
+
+const (
+	DEPLOYMENT_WINDOW_TYPE_ALLOWED  DeploymentWindowType = "ALLOWED"
+	DEPLOYMENT_WINDOW_TYPE_BLACKOUT DeploymentWindowType = "BLACKOUT"
+)
+
+type DeploymentWindowQueueStatus string
+
+const (
+	DEPLOYMENT_WINDOW_QUEUE_QUEUED     DeploymentWindowQueueStatus = "QUEUED"
+	DEPLOYMENT_WINDOW_QUEUE_TRIGGERED  DeploymentWindowQueueStatus = "TRIGGERED"
+	DEPLOYMENT_WINDOW_QUEUE_FAILED     DeploymentWindowQueueStatus = "FAILED"
+	DEPLOYMENT_WINDOW_QUEUE_SUPERSEDED DeploymentWindowQueueStatus = "SUPERSEDED"
+	DEPLOYMENT_WINDOW_QUEUE_PARKED     DeploymentWindowQueueStatus = "PARKED"
+)
+
+type DeploymentWindowPolicy struct {
+	tableName     struct{} `sql:"deployment_window_policy" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	Name          string   `sql:"name,notnull"`
+	EnvironmentId int      `sql:"environment_id"`
+	ClusterId     int      `sql:"cluster_id"`
+	Timezone      string   `sql:"timezone,notnull"`
+	Active        bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// DeploymentWindow is either a recurring window, starting at every cron activation and lasting for duration minutes,
+// or a fixed date range given by start and end time
+type DeploymentWindow struct {
+	tableName       struct{}             `sql:"deployment_window" pg:",discard_unknown_columns"`
+	Id              int                  `sql:"id,pk"`
+	PolicyId        int                  `sql:"policy_id,notnull"`
+	WindowType      DeploymentWindowType `sql:"window_type,notnull"`
+	CronExpression  string               `sql:"cron_expression"`
+	DurationMinutes int                  `sql:"duration_minutes"`
+	StartTime       time.Time            `sql:"start_time"`
+	EndTime         time.Time            `sql:"end_time"`
+	Description     string               `sql:"description"`
+	Active          bool                 `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type DeploymentWindowOverrideAudit struct {
+	tableName    struct{} `sql:"deployment_window_override_audit" pg:",discard_unknown_columns"`
+	Id           int      `sql:"id,pk"`
+	PolicyId     int      `sql:"policy_id,notnull"`
+	PipelineId   int      `sql:"pipeline_id,notnull"`
+	CiArtifactId int      `sql:"ci_artifact_id,notnull"`
+	Reason       string   `sql:"reason,notnull"`
+	sql.AuditLog
+}
+
+type DeploymentWindowQueue struct {
+	tableName    struct{}                    `sql:"deployment_window_queue" pg:",discard_unknown_columns"`
+	Id           int                         `sql:"id,pk"`
+	PipelineId   int                         `sql:"pipeline_id,notnull"`
+	CiArtifactId int                         `sql:"ci_artifact_id,notnull"`
+	TriggeredBy  int32                       `sql:"triggered_by,notnull"`
+	Status       DeploymentWindowQueueStatus `sql:"status,notnull"`
+	Message      string                      `sql:"message"`
+	sql.AuditLog
+}
+
+type DeploymentWindowRepository interface {
+	GetConnection() *pg.DB
+	SavePolicy(policy *DeploymentWindowPolicy, tx *pg.Tx) error
+	UpdatePolicy(policy *DeploymentWindowPolicy, tx *pg.Tx) error
+	FindPolicyById(id int) (*DeploymentWindowPolicy, error)
+	FindAllActivePolicies() ([]*DeploymentWindowPolicy, error)
+	FindActivePolicyByEnvironmentId(environmentId int) (*DeploymentWindowPolicy, error)
+	FindActivePolicyByClusterId(clusterId int) (*DeploymentWindowPolicy, error)
+	SaveWindows(windows []*DeploymentWindow, tx *pg.Tx) error
+	DeactivateWindowsByPolicyId(policyId int, userId int32, tx *pg.Tx) error
+	FindActiveWindowsByPolicyId(policyId int) ([]*DeploymentWindow, error)
+	SaveOverrideAudit(audit *DeploymentWindowOverrideAudit) error
+	FindOverrideAuditsByPolicyId(policyId int, limit int) ([]*DeploymentWindowOverrideAudit, error)
+	ExistsOverrideAuditAfter(pipelineId int, ciArtifactId int, after time.Time) (bool, error)
+	SaveQueueItem(item *DeploymentWindowQueue) error
+	UpdateQueueItem(item *DeploymentWindowQueue) error
+	UpdateQueueItemIfQueued(item *DeploymentWindowQueue) (bool, error)
+	FindQueuedItems() ([]*DeploymentWindowQueue, error)
+	FindQueuedItemsByPipelineId(pipelineId int) ([]*DeploymentWindowQueue, error)
+}
+
+type DeploymentWindowRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewDeploymentWindowRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *DeploymentWindowRepositoryImpl {
+	return &DeploymentWindowRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *DeploymentWindowRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *DeploymentWindowRepositoryImpl) SavePolicy(policy *DeploymentWindowPolicy, tx *pg.Tx) error {
+	return tx.Insert(policy)
+}
+
+func (impl *DeploymentWindowRepositoryImpl) UpdatePolicy(policy *DeploymentWindowPolicy, tx *pg.Tx) error {
+	return tx.Update(policy)
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindPolicyById(id int) (*DeploymentWindowPolicy, error) {
+	policy := &DeploymentWindowPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindAllActivePolicies() ([]*DeploymentWindowPolicy, error) {
+	var policies []*DeploymentWindowPolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return policies, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindActivePolicyByEnvironmentId(environmentId int) (*DeploymentWindowPolicy, error) {
+	policy := &DeploymentWindowPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("environment_id = ?", environmentId).
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return policy, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindActivePolicyByClusterId(clusterId int) (*DeploymentWindowPolicy, error) {
+	policy := &DeploymentWindowPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("cluster_id = ?", clusterId).
+		Where("environment_id IS NULL").
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return policy, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) SaveWindows(windows []*DeploymentWindow, tx *pg.Tx) error {
+	if len(windows) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&windows).Insert()
+	return err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) DeactivateWindowsByPolicyId(policyId int, userId int32, tx *pg.Tx) error {
+	_, err := tx.Model((*DeploymentWindow)(nil)).
+		Set("active = ?", false).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("policy_id = ?", policyId).
+		Where("active = ?", true).
+		Update()
+	return err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindActiveWindowsByPolicyId(policyId int) ([]*DeploymentWindow, error) {
+	var windows []*DeploymentWindow
+	err := impl.dbConnection.Model(&windows).
+		Where("policy_id = ?", policyId).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return windows, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) SaveOverrideAudit(audit *DeploymentWindowOverrideAudit) error {
+	return impl.dbConnection.Insert(audit)
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindOverrideAuditsByPolicyId(policyId int, limit int) ([]*DeploymentWindowOverrideAudit, error) {
+	var audits []*DeploymentWindowOverrideAudit
+	err := impl.dbConnection.Model(&audits).
+		Where("policy_id = ?", policyId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return audits, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) ExistsOverrideAuditAfter(pipelineId int, ciArtifactId int, after time.Time) (bool, error) {
+	return impl.dbConnection.Model((*DeploymentWindowOverrideAudit)(nil)).
+		Where("pipeline_id = ?", pipelineId).
+		Where("ci_artifact_id = ?", ciArtifactId).
+		Where("created_on > ?", after).
+		Exists()
+}
+
+func (impl *DeploymentWindowRepositoryImpl) SaveQueueItem(item *DeploymentWindowQueue) error {
+	return impl.dbConnection.Insert(item)
+}
+
+func (impl *DeploymentWindowRepositoryImpl) UpdateQueueItem(item *DeploymentWindowQueue) error {
+	return impl.dbConnection.Update(item)
+}
+
+// UpdateQueueItemIfQueued updates the status of the item only while it is still queued, returns false when the item
+// was already picked up meanwhile
+func (impl *DeploymentWindowRepositoryImpl) UpdateQueueItemIfQueued(item *DeploymentWindowQueue) (bool, error) {
+	res, err := impl.dbConnection.Model((*DeploymentWindowQueue)(nil)).
+		Set("status = ?", item.Status).
+		Set("message = ?", item.Message).
+		Set("updated_on = ?", item.UpdatedOn).
+		Set("updated_by = ?", item.UpdatedBy).
+		Where("id = ?", item.Id).
+		Where("status = ?", DEPLOYMENT_WINDOW_QUEUE_QUEUED).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindQueuedItems() ([]*DeploymentWindowQueue, error) {
+	var items []*DeploymentWindowQueue
+	err := impl.dbConnection.Model(&items).
+		Where("status = ?", DEPLOYMENT_WINDOW_QUEUE_QUEUED).
+		Order("id ASC").
+		Select()
+	return items, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindQueuedItemsByPipelineId(pipelineId int) ([]*DeploymentWindowQueue, error) {
+	var items []*DeploymentWindowQueue
+	err := impl.dbConnection.Model(&items).
+		Where("pipeline_id = ?", pipelineId).
+		Where("status = ?", DEPLOYMENT_WINDOW_QUEUE_QUEUED).
+		Order("id ASC").
+		Select()
+	return items, err
+}
@@ -0,0 +1,110 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type GitOpsPullRequestStatus string
+
+const (
+	GITOPS_PULL_REQUEST_STATUS_OPEN GitOpsPullRequestStatus = "OPEN"
+	// GITOPS_PULL_REQUEST_STATUS_MERGING is held by the orchestrator instance deploying a merged pull request
+	GITOPS_PULL_REQUEST_STATUS_MERGING   GitOpsPullRequestStatus = "MERGING"
+	GITOPS_PULL_REQUEST_STATUS_MERGED    GitOpsPullRequestStatus = "MERGED"
+	GITOPS_PULL_REQUEST_STATUS_CLOSED    GitOpsPullRequestStatus = "CLOSED"
+	GITOPS_PULL_REQUEST_STATUS_TIMED_OUT GitOpsPullRequestStatus = "TIMED_OUT"
+	// GITOPS_PULL_REQUEST_STATUS_SUPERSEDED is set when a later deployment of the pipeline opens a new pull request
+	GITOPS_PULL_REQUEST_STATUS_SUPERSEDED GitOpsPullRequestStatus = "SUPERSEDED"
+)
+
+type GitOpsPullRequest struct {
+	tableName          struct{}                `sql:"gitops_pull_request" pg:",discard_unknown_columns"`
+	Id                 int                     `sql:"id,pk"`
+	CdWorkflowRunnerId int                     `sql:"cd_workflow_runner_id,notnull"`
+	PipelineOverrideId int                     `sql:"pipeline_override_id,notnull"`
+	PipelineId         int                     `sql:"pipeline_id,notnull"`
+	RepoName           string                  `sql:"repo_name,notnull"`
+	Branch             string                  `sql:"branch,notnull"`
+	PrNumber           int                     `sql:"pr_number,notnull"`
+	PrUrl              string                  `sql:"pr_url"`
+	Status             GitOpsPullRequestStatus `sql:"status,notnull"`
+	MergeCommitHash    string                  `sql:"merge_commit_hash"`
+	sql.AuditLog
+}
+
+type GitOpsPullRequestRepository interface {
+	Save(pullRequest *GitOpsPullRequest) error
+	Update(pullRequest *GitOpsPullRequest) error
+	FindByStatus(status GitOpsPullRequestStatus) ([]*GitOpsPullRequest, error)
+	FindByCdWorkflowRunnerId(wfrId int) (*GitOpsPullRequest, error)
+	// UpdateStatus moves the pull request to status only if it is still in currentStatus, so that a pull request is
+	// processed by a single orchestrator instance
+	UpdateStatus(id int, currentStatus GitOpsPullRequestStatus, status GitOpsPullRequestStatus, updatedBy int32) (bool, error)
+	// ReleaseStaleClaims moves pull requests held in claimedStatus since before the given time back to status
+	ReleaseStaleClaims(claimedStatus GitOpsPullRequestStatus, status GitOpsPullRequestStatus, before time.Time) error
+}
+
+type GitOpsPullRequestRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewGitOpsPullRequestRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *GitOpsPullRequestRepositoryImpl {
+	return &GitOpsPullRequestRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *GitOpsPullRequestRepositoryImpl) Save(pullRequest *GitOpsPullRequest) error {
+	return impl.dbConnection.Insert(pullRequest)
+}
+
+func (impl *GitOpsPullRequestRepositoryImpl) Update(pullRequest *GitOpsPullRequest) error {
+	return impl.dbConnection.Update(pullRequest)
+}
+
+func (impl *GitOpsPullRequestRepositoryImpl) FindByStatus(status GitOpsPullRequestStatus) ([]*GitOpsPullRequest, error) {
+	var pullRequests []*GitOpsPullRequest
+	err := impl.dbConnection.Model(&pullRequests).
+		Where("status = ?", status).
+		Order("id ASC").
+		Select()
+	return pullRequests, err
+}
+
+func (impl *GitOpsPullRequestRepositoryImpl) FindByCdWorkflowRunnerId(wfrId int) (*GitOpsPullRequest, error) {
+	pullRequest := &GitOpsPullRequest{}
+	err := impl.dbConnection.Model(pullRequest).
+		Where("cd_workflow_runner_id = ?", wfrId).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	return pullRequest, err
+}
+
+func (impl *GitOpsPullRequestRepositoryImpl) UpdateStatus(id int, currentStatus GitOpsPullRequestStatus, status GitOpsPullRequestStatus, updatedBy int32) (bool, error) {
+	res, err := impl.dbConnection.Model((*GitOpsPullRequest)(nil)).
+		Set("status = ?", status).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", updatedBy).
+		Where("id = ?", id).
+		Where("status = ?", currentStatus).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *GitOpsPullRequestRepositoryImpl) ReleaseStaleClaims(claimedStatus GitOpsPullRequestStatus, status GitOpsPullRequestStatus, before time.Time) error {
+	_, err := impl.dbConnection.Model((*GitOpsPullRequest)(nil)).
+		Set("status = ?", status).
+		Where("status = ?", claimedStatus).
+		Where("updated_on < ?", before).
+		Update()
+	return err
+}
@@ -95,6 +95,7 @@ type PipelineRepository interface {
 	UpdateCdPipeline(pipeline *Pipeline) error
 	FindNumberOfAppsWithCdPipeline(appIds []int) (count int, err error)
 	GetAppAndEnvDetailsForDeploymentAppTypePipeline(deploymentAppType string, clusterIds []int) ([]*Pipeline, error)
+	ExistsByDeploymentAppTypeAndDeploymentAppCreated(deploymentAppType string) (bool, error)
 	GetPipelineIdsHavingStatusTimelinesPendingAfterKubectlApplyStatus(pendingSinceSeconds int) ([]int, error)
 	FindIdsByAppIdsAndEnvironmentIds(appIds, environmentIds []int) (ids []int, err error)
 	FindIdsByProjectIdsAndEnvironmentIds(projectIds, environmentIds []int) ([]int, error)
@@ -440,6 +441,15 @@ func (impl PipelineRepositoryImpl) GetAppAndEnvDetailsForDeploymentAppTypePipeli
 	return pipelines, err
 }
 
+func (impl PipelineRepositoryImpl) ExistsByDeploymentAppTypeAndDeploymentAppCreated(deploymentAppType string) (bool, error) {
+	exists, err := impl.dbConnection.Model((*Pipeline)(nil)).
+		Where("deleted = ?", false).
+		Where("deployment_app_type = ?", deploymentAppType).
+		Where("deployment_app_created = ?", true).
+		Exists()
+	return exists, err
+}
+
 func (impl PipelineRepositoryImpl) GetPipelineIdsHavingStatusTimelinesPendingAfterKubectlApplyStatus(pendingSinceSeconds int) ([]int, error) {
 	var pipelineIds []int
 	queryString := `select p.id from pipeline p inner join app a on p.app_id = a.id  
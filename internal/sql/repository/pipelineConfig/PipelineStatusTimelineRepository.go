@@ -10,14 +10,20 @@ import (
 type TimelineStatus string
 
 const (
-	TIMELINE_STATUS_DEPLOYMENT_INITIATED  TimelineStatus = "DEPLOYMENT_INITIATED"
-	TIMELINE_STATUS_GIT_COMMIT            TimelineStatus = "GIT_COMMIT"
-	TIMELINE_STATUS_GIT_COMMIT_FAILED     TimelineStatus = "GIT_COMMIT_FAILED"
-	TIMELINE_STATUS_KUBECTL_APPLY_STARTED TimelineStatus = "KUBECTL_APPLY_STARTED"
-	TIMELINE_STATUS_KUBECTL_APPLY_SYNCED  TimelineStatus = "KUBECTL_APPLY_SYNCED"
-	TIMELINE_STATUS_APP_HEALTHY           TimelineStatus = "HEALTHY"
-	TIMELINE_STATUS_APP_DEGRADED          TimelineStatus = "DEGRADED"
-	TIMELINE_STATUS_DEPLOYMENT_FAILED     TimelineStatus = "FAILED"
+	TIMELINE_STATUS_DEPLOYMENT_INITIATED         TimelineStatus = "DEPLOYMENT_INITIATED"
+	TIMELINE_STATUS_GIT_COMMIT                   TimelineStatus = "GIT_COMMIT"
+	TIMELINE_STATUS_GIT_COMMIT_FAILED            TimelineStatus = "GIT_COMMIT_FAILED"
+	TIMELINE_STATUS_GIT_COMMIT_WAITING_FOR_MERGE TimelineStatus = "GIT_COMMIT_WAITING_FOR_MERGE"
+	TIMELINE_STATUS_KUBECTL_APPLY_STARTED        TimelineStatus = "KUBECTL_APPLY_STARTED"
+	TIMELINE_STATUS_KUBECTL_APPLY_SYNCED         TimelineStatus = "KUBECTL_APPLY_SYNCED"
+	TIMELINE_STATUS_APP_HEALTHY                  TimelineStatus = "HEALTHY"
+	TIMELINE_STATUS_APP_DEGRADED                 TimelineStatus = "DEGRADED"
+	TIMELINE_STATUS_DEPLOYMENT_FAILED            TimelineStatus = "FAILED"
+	TIMELINE_STATUS_AUTO_ROLLBACK                TimelineStatus = "AUTO_ROLLBACK_TRIGGERED"
+	TIMELINE_STATUS_CANARY_ANALYSIS_PASSED       TimelineStatus = "CANARY_ANALYSIS_PASSED"
+	TIMELINE_STATUS_CANARY_ANALYSIS_FAILED       TimelineStatus = "CANARY_ANALYSIS_FAILED"
+	TIMELINE_STATUS_CANARY_PROMOTED              TimelineStatus = "CANARY_PROMOTED"
+	TIMELINE_STATUS_CANARY_ABORTED               TimelineStatus = "CANARY_ABORTED"
 )
 
 type PipelineStatusTimelineRepository interface {
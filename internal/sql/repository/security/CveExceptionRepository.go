@@ -0,0 +1,130 @@
+package security
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type CveExceptionStatus string
+
+const (
+	CVE_EXCEPTION_STATUS_PENDING  CveExceptionStatus = "PENDING"
+	CVE_EXCEPTION_STATUS_APPROVED CveExceptionStatus = "APPROVED"
+	CVE_EXCEPTION_STATUS_REJECTED CveExceptionStatus = "REJECTED"
+	CVE_EXCEPTION_STATUS_REVOKED  CveExceptionStatus = "REVOKED"
+)
+
+// CveException allows a cve for an app on an environment until it expires, only approved exceptions
+// which have not expired are considered while enforcing cve policies
+type CveException struct {
+	tableName      struct{}           `sql:"cve_exception" pg:",discard_unknown_columns"`
+	Id             int                `sql:"id,pk"`
+	CveStoreName   string             `sql:"cve_store_name,notnull"`
+	AppId          int                `sql:"app_id,notnull"`
+	EnvironmentId  int                `sql:"environment_id,notnull"`
+	Justification  string             `sql:"justification,notnull"`
+	Status         CveExceptionStatus `sql:"status,notnull"`
+	RequestedBy    int32              `sql:"requested_by,notnull"`
+	ReviewedBy     int32              `sql:"reviewed_by"`
+	ReviewedOn     time.Time          `sql:"reviewed_on"`
+	ReviewComment  string             `sql:"review_comment"`
+	ExpiresOn      time.Time          `sql:"expires_on,notnull"`
+	ExpiryNotified bool               `sql:"expiry_notified,notnull"`
+	sql.AuditLog
+}
+
+type CveExceptionFilter struct {
+	CveName string
+	AppId   int
+	EnvId   int
+	Status  CveExceptionStatus
+}
+
+type CveExceptionRepository interface {
+	Save(exception *CveException) error
+	Update(exception *CveException) error
+	FindById(id int) (*CveException, error)
+	FindByFilter(filter *CveExceptionFilter) ([]*CveException, error)
+	// FindOpenByScope returns the pending or unexpired approved exception of the cve for the app and environment
+	FindOpenByScope(cveName string, appId int, envId int) ([]*CveException, error)
+	FindActiveByAppAndEnv(appId int, envId int) ([]*CveException, error)
+	FindActiveExpiringBefore(expiresBefore time.Time) ([]*CveException, error)
+}
+
+type CveExceptionRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewCveExceptionRepositoryImpl(dbConnection *pg.DB) *CveExceptionRepositoryImpl {
+	return &CveExceptionRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *CveExceptionRepositoryImpl) Save(exception *CveException) error {
+	return impl.dbConnection.Insert(exception)
+}
+
+func (impl *CveExceptionRepositoryImpl) Update(exception *CveException) error {
+	return impl.dbConnection.Update(exception)
+}
+
+func (impl *CveExceptionRepositoryImpl) FindById(id int) (*CveException, error) {
+	exception := &CveException{}
+	err := impl.dbConnection.Model(exception).
+		Where("id = ?", id).
+		Select()
+	return exception, err
+}
+
+func (impl *CveExceptionRepositoryImpl) FindByFilter(filter *CveExceptionFilter) ([]*CveException, error) {
+	var exceptions []*CveException
+	query := impl.dbConnection.Model(&exceptions)
+	if len(filter.CveName) > 0 {
+		query = query.Where("cve_store_name = ?", filter.CveName)
+	}
+	if filter.AppId > 0 {
+		query = query.Where("app_id = ?", filter.AppId)
+	}
+	if filter.EnvId > 0 {
+		query = query.Where("environment_id = ?", filter.EnvId)
+	}
+	if len(filter.Status) > 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+	err := query.Order("id DESC").Select()
+	return exceptions, err
+}
+
+func (impl *CveExceptionRepositoryImpl) FindOpenByScope(cveName string, appId int, envId int) ([]*CveException, error) {
+	var exceptions []*CveException
+	err := impl.dbConnection.Model(&exceptions).
+		Where("cve_store_name = ?", cveName).
+		Where("app_id = ?", appId).
+		Where("environment_id = ?", envId).
+		Where("status = ? OR (status = ? AND expires_on > ?)", CVE_EXCEPTION_STATUS_PENDING, CVE_EXCEPTION_STATUS_APPROVED, time.Now()).
+		Select()
+	return exceptions, err
+}
+
+func (impl *CveExceptionRepositoryImpl) FindActiveByAppAndEnv(appId int, envId int) ([]*CveException, error) {
+	var exceptions []*CveException
+	err := impl.dbConnection.Model(&exceptions).
+		Where("app_id = ?", appId).
+		Where("environment_id = ?", envId).
+		Where("status = ?", CVE_EXCEPTION_STATUS_APPROVED).
+		Where("expires_on > ?", time.Now()).
+		Select()
+	return exceptions, err
+}
+
+func (impl *CveExceptionRepositoryImpl) FindActiveExpiringBefore(expiresBefore time.Time) ([]*CveException, error) {
+	var exceptions []*CveException
+	err := impl.dbConnection.Model(&exceptions).
+		Where("status = ?", CVE_EXCEPTION_STATUS_APPROVED).
+		Where("expires_on > ?", time.Now()).
+		Where("expires_on <= ?", expiresBefore).
+		Order("expires_on ASC").
+		Select()
+	return exceptions, err
+}
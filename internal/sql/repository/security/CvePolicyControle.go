@@ -114,11 +114,12 @@ type CvePolicyRepository interface {
 	GetBlockedCVEList(cves []*CveStore, clusterId, envId, appId int, isAppstore bool) ([]*CveStore, error)
 }
 type CvePolicyRepositoryImpl struct {
-	dbConnection *pg.DB
+	dbConnection           *pg.DB
+	cveExceptionRepository CveExceptionRepository
 }
 
-func NewPolicyRepositoryImpl(dbConnection *pg.DB) *CvePolicyRepositoryImpl {
-	return &CvePolicyRepositoryImpl{dbConnection: dbConnection}
+func NewPolicyRepositoryImpl(dbConnection *pg.DB, cveExceptionRepository CveExceptionRepository) *CvePolicyRepositoryImpl {
+	return &CvePolicyRepositoryImpl{dbConnection: dbConnection, cveExceptionRepository: cveExceptionRepository}
 }
 func (impl *CvePolicyRepositoryImpl) GetGlobalPolicies() (policies []*CvePolicy, err error) {
 	err = impl.dbConnection.Model(&policies).
@@ -274,7 +275,30 @@ func (impl *CvePolicyRepositoryImpl) getApplicablePolicy(clusterId, envId, appId
 	}
 
 	cvePolicy, severityPolicy, err := impl.getPolicies(policyLevel, clusterId, envId, appId)
-	return cvePolicy, severityPolicy, err
+	if err != nil {
+		return nil, nil, err
+	}
+	if appId > 0 && envId > 0 {
+		exceptions, err := impl.cveExceptionRepository.FindActiveByAppAndEnv(appId, envId)
+		if err != nil {
+			return nil, nil, err
+		}
+		ApplyCveExceptions(cvePolicy, exceptions)
+	}
+	return cvePolicy, severityPolicy, nil
+}
+
+// ApplyCveExceptions allows the cves of active exceptions, an exception takes precedence over the cve and
+// severity policies of every level
+func ApplyCveExceptions(cvePolicy map[string]*CvePolicy, exceptions []*CveException) {
+	for _, exception := range exceptions {
+		cvePolicy[exception.CveStoreName] = &CvePolicy{
+			AppId:         exception.AppId,
+			EnvironmentId: exception.EnvironmentId,
+			CVEStoreId:    exception.CveStoreName,
+			Action:        Allow,
+		}
+	}
 }
 
 func (impl *CvePolicyRepositoryImpl) getPolicies(policyLevel PolicyLevel, clusterId, environmentId, appId int) (map[string]*CvePolicy, map[Severity]*CvePolicy, error) {
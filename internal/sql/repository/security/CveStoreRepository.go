@@ -55,6 +55,9 @@ type VulnerabilityExposure struct {
 	Blocked       bool `json:"blocked"`
 	PipelineEnvId int  `json:"-"`
 	ChartEnvId    int  `json:"-"`
+	// set from the sbom of the artifact deployed on the environment
+	ContainsPackage bool   `json:"containsPackage"`
+	PackageVersion  string `json:"packageVersion,omitempty"`
 }
 
 type VulnerabilityExposureListingResponse struct {
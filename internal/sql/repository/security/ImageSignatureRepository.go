@@ -0,0 +1,177 @@
+package security
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"go.uber.org/zap"
+)
+
+// ImageSigningKey is a key trusted for image signatures, the private key of keys generated by devtron is kept in
+// the devtron secret and never in db
+type ImageSigningKey struct {
+	tableName       struct{} `sql:"image_signing_key" pg:",discard_unknown_columns"`
+	Id              int      `sql:"id,pk"`
+	Name            string   `sql:"name,notnull"`
+	PublicKey       string   `sql:"public_key,notnull"`
+	Fingerprint     string   `sql:"fingerprint,notnull"`
+	HasPrivateKey   bool     `sql:"has_private_key,notnull"`
+	SignCiArtifacts bool     `sql:"sign_ci_artifacts,notnull"`
+	Active          bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// ImageSignaturePolicy requires artifacts deployed on a cluster or an environment to be signed by one of the
+// trusted keys, an environment policy takes precedence over the policy of its cluster
+type ImageSignaturePolicy struct {
+	tableName     struct{} `sql:"image_signature_policy" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	ClusterId     int      `sql:"cluster_id"`
+	EnvironmentId int      `sql:"environment_id"`
+	Enforce       bool     `sql:"enforce,notnull"`
+	TrustedKeyIds []int    `sql:"trusted_key_ids" pg:",array"`
+	Active        bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type ImageSignatureRepository interface {
+	SaveKey(key *ImageSigningKey) error
+	UpdateKey(key *ImageSigningKey) error
+	FindActiveKeys() ([]*ImageSigningKey, error)
+	FindActiveKeyById(id int) (*ImageSigningKey, error)
+	FindActiveKeysByIds(ids []int) ([]*ImageSigningKey, error)
+	FindActiveKeyByName(name string) (*ImageSigningKey, error)
+	FindCiArtifactSigningKey() (*ImageSigningKey, error)
+
+	SavePolicy(policy *ImageSignaturePolicy) error
+	UpdatePolicy(policy *ImageSignaturePolicy) error
+	FindActivePolicies() ([]*ImageSignaturePolicy, error)
+	FindActivePolicyById(id int) (*ImageSignaturePolicy, error)
+	FindActivePolicyByScope(clusterId int, environmentId int) (*ImageSignaturePolicy, error)
+	FindActivePoliciesForEnv(clusterId int, environmentId int) ([]*ImageSignaturePolicy, error)
+}
+
+type ImageSignatureRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewImageSignatureRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ImageSignatureRepositoryImpl {
+	return &ImageSignatureRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *ImageSignatureRepositoryImpl) SaveKey(key *ImageSigningKey) error {
+	return impl.dbConnection.Insert(key)
+}
+
+func (impl *ImageSignatureRepositoryImpl) UpdateKey(key *ImageSigningKey) error {
+	return impl.dbConnection.Update(key)
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActiveKeys() ([]*ImageSigningKey, error) {
+	var keys []*ImageSigningKey
+	err := impl.dbConnection.Model(&keys).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return keys, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActiveKeyById(id int) (*ImageSigningKey, error) {
+	key := &ImageSigningKey{}
+	err := impl.dbConnection.Model(key).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return key, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActiveKeysByIds(ids []int) ([]*ImageSigningKey, error) {
+	var keys []*ImageSigningKey
+	if len(ids) == 0 {
+		return keys, nil
+	}
+	err := impl.dbConnection.Model(&keys).
+		Where("id in (?)", pg.In(ids)).
+		Where("active = ?", true).
+		Select()
+	return keys, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActiveKeyByName(name string) (*ImageSigningKey, error) {
+	key := &ImageSigningKey{}
+	err := impl.dbConnection.Model(key).
+		Where("name = ?", name).
+		Where("active = ?", true).
+		Select()
+	return key, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindCiArtifactSigningKey() (*ImageSigningKey, error) {
+	key := &ImageSigningKey{}
+	err := impl.dbConnection.Model(key).
+		Where("sign_ci_artifacts = ?", true).
+		Where("has_private_key = ?", true).
+		Where("active = ?", true).
+		Limit(1).
+		Select()
+	return key, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) SavePolicy(policy *ImageSignaturePolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl *ImageSignatureRepositoryImpl) UpdatePolicy(policy *ImageSignaturePolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActivePolicies() ([]*ImageSignaturePolicy, error) {
+	var policies []*ImageSignaturePolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return policies, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActivePolicyById(id int) (*ImageSignaturePolicy, error) {
+	policy := &ImageSignaturePolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActivePolicyByScope(clusterId int, environmentId int) (*ImageSignaturePolicy, error) {
+	policy := &ImageSignaturePolicy{}
+	query := impl.dbConnection.Model(policy).
+		Where("active = ?", true)
+	if environmentId > 0 {
+		query = query.Where("environment_id = ?", environmentId)
+	} else {
+		query = query.Where("cluster_id = ?", clusterId).Where("environment_id is null")
+	}
+	err := query.Limit(1).Select()
+	return policy, err
+}
+
+func (impl *ImageSignatureRepositoryImpl) FindActivePoliciesForEnv(clusterId int, environmentId int) ([]*ImageSignaturePolicy, error) {
+	var policies []*ImageSignaturePolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("active = ?", true).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("environment_id = ?", environmentId).
+				WhereOrGroup(func(sq *orm.Query) (*orm.Query, error) {
+					sq = sq.Where("cluster_id = ?", clusterId).Where("environment_id is null")
+					return sq, nil
+				})
+			return q, nil
+		}).
+		Select()
+	return policies, err
+}
@@ -0,0 +1,160 @@
+package security
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	SBOM_FORMAT_CYCLONEDX = "cyclonedx-json"
+	SBOM_FORMAT_SPDX      = "spdx-json"
+)
+
+// CiArtifactSbom is the software bill of materials of the image built by ci, artifacts of linked ci pipelines
+// share the sbom of their parent artifact
+type CiArtifactSbom struct {
+	tableName    struct{} `sql:"ci_artifact_sbom" pg:",discard_unknown_columns"`
+	Id           int      `sql:"id,pk"`
+	CiArtifactId int      `sql:"ci_artifact_id,notnull"`
+	Format       string   `sql:"format,notnull"`
+	SpecVersion  string   `sql:"spec_version"`
+	Document     string   `sql:"document,notnull"`
+	PackageCount int      `sql:"package_count,notnull"`
+	sql.AuditLog
+}
+
+type CiArtifactSbomPackage struct {
+	tableName        struct{} `sql:"ci_artifact_sbom_package" pg:",discard_unknown_columns"`
+	Id               int      `sql:"id,pk"`
+	CiArtifactSbomId int      `sql:"ci_artifact_sbom_id,notnull"`
+	CiArtifactId     int      `sql:"ci_artifact_id,notnull"`
+	Name             string   `sql:"name,notnull"`
+	Version          string   `sql:"version"`
+	PackageType      string   `sql:"package_type"`
+	Purl             string   `sql:"purl"`
+}
+
+type SbomPackageSearchRequest struct {
+	PackageName string `json:"packageName" validate:"required"`
+	Version     string `json:"version"`
+	EnvIds      []int  `json:"envIds"`
+	ClusterIds  []int  `json:"clusterIds"`
+	Offset      int    `json:"offset" validate:"min=0"`
+	Size        int    `json:"size" validate:"min=0"`
+}
+
+// DeployedSbomPackage is a package found in the artifact currently deployed on a cd pipeline
+type DeployedSbomPackage struct {
+	PipelineId     int    `json:"pipelineId"`
+	AppId          int    `json:"appId"`
+	AppName        string `json:"appName"`
+	EnvId          int    `json:"envId"`
+	EnvName        string `json:"envName"`
+	CiArtifactId   int    `json:"ciArtifactId"`
+	Image          string `json:"image"`
+	PackageName    string `json:"packageName"`
+	PackageVersion string `json:"packageVersion"`
+	PackageType    string `json:"packageType"`
+	Purl           string `json:"purl"`
+}
+
+type SbomRepository interface {
+	// SaveSbom replaces any sbom stored for the artifact along with its packages
+	SaveSbom(sbom *CiArtifactSbom, packages []*CiArtifactSbomPackage) error
+	FindByCiArtifactId(ciArtifactId int) (*CiArtifactSbom, error)
+	FindDeployedPackages(request *SbomPackageSearchRequest) ([]*DeployedSbomPackage, error)
+}
+
+type SbomRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewSbomRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *SbomRepositoryImpl {
+	return &SbomRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *SbomRepositoryImpl) SaveSbom(sbom *CiArtifactSbom, packages []*CiArtifactSbomPackage) error {
+	return impl.dbConnection.RunInTransaction(func(tx *pg.Tx) error {
+		_, err := tx.Model((*CiArtifactSbomPackage)(nil)).
+			Where("ci_artifact_id = ?", sbom.CiArtifactId).
+			Delete()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Model((*CiArtifactSbom)(nil)).
+			Where("ci_artifact_id = ?", sbom.CiArtifactId).
+			Delete()
+		if err != nil {
+			return err
+		}
+		err = tx.Insert(sbom)
+		if err != nil {
+			return err
+		}
+		for _, sbomPackage := range packages {
+			sbomPackage.CiArtifactSbomId = sbom.Id
+			sbomPackage.CiArtifactId = sbom.CiArtifactId
+		}
+		if len(packages) > 0 {
+			err = tx.Insert(&packages)
+		}
+		return err
+	})
+}
+
+func (impl *SbomRepositoryImpl) FindByCiArtifactId(ciArtifactId int) (*CiArtifactSbom, error) {
+	sbom := &CiArtifactSbom{}
+	err := impl.dbConnection.Model(sbom).
+		Where("ci_artifact_id = ?", ciArtifactId).
+		Select()
+	return sbom, err
+}
+
+func (impl *SbomRepositoryImpl) FindDeployedPackages(request *SbomPackageSearchRequest) ([]*DeployedSbomPackage, error) {
+	var items []*DeployedSbomPackage
+	var queryParams []interface{}
+	// latest deployment of each pipeline which was not failed or aborted, sbom is looked up on the parent artifact for linked ci
+	query := "SELECT d.*, sp.name as package_name, sp.version as package_version, sp.package_type, sp.purl FROM (" +
+		" SELECT DISTINCT ON (p.id) p.id as pipeline_id, a.id as app_id, a.app_name, env.id as env_id, env.environment_name as env_name," +
+		" cia.id as ci_artifact_id, cia.image, CASE WHEN cia.parent_ci_artifact > 0 THEN cia.parent_ci_artifact ELSE cia.id END as sbom_artifact_id" +
+		" FROM pipeline p" +
+		" INNER JOIN app a ON a.id = p.app_id" +
+		" INNER JOIN environment env ON env.id = p.environment_id" +
+		" INNER JOIN cd_workflow cw ON cw.pipeline_id = p.id" +
+		" INNER JOIN cd_workflow_runner cwr ON cwr.cd_workflow_id = cw.id" +
+		" INNER JOIN ci_artifact cia ON cia.id = cw.ci_artifact_id" +
+		" WHERE p.deleted = false AND a.active = true AND env.active = true" +
+		" AND cwr.workflow_type = 'DEPLOY' AND cwr.status NOT IN ('Failed', 'Aborted')"
+	if len(request.EnvIds) > 0 {
+		query = query + " AND env.id IN (?)"
+		queryParams = append(queryParams, pg.In(request.EnvIds))
+	}
+	if len(request.ClusterIds) > 0 {
+		query = query + " AND env.cluster_id IN (?)"
+		queryParams = append(queryParams, pg.In(request.ClusterIds))
+	}
+	query = query + " ORDER BY p.id, cwr.id DESC) d" +
+		" INNER JOIN ci_artifact_sbom_package sp ON sp.ci_artifact_id = d.sbom_artifact_id" +
+		" WHERE LOWER(sp.name) = LOWER(?)"
+	queryParams = append(queryParams, request.PackageName)
+	if len(request.Version) > 0 {
+		query = query + " AND sp.version = ?"
+		queryParams = append(queryParams, request.Version)
+	}
+	query = query + " ORDER BY d.app_name, d.env_name"
+	if request.Size > 0 {
+		query = query + " LIMIT ? OFFSET ?"
+		queryParams = append(queryParams, request.Size, request.Offset)
+	}
+	_, err := impl.dbConnection.Query(&items, query, queryParams...)
+	if err != nil {
+		impl.logger.Errorw("error in searching deployed sbom packages", "err", err, "request", request)
+		return nil, err
+	}
+	return items, nil
+}
@@ -28,6 +28,7 @@ import (
 	appStoreBean "github.com/devtron-labs/devtron/pkg/appStore/bean"
 	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
 	"github.com/devtron-labs/devtron/util"
+	"github.com/go-pg/pg"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
@@ -41,6 +42,7 @@ import (
 	"github.com/ghodss/yaml"
 	dirCopy "github.com/otiai10/copy"
 	"go.uber.org/zap"
+	"gopkg.in/src-d/go-git.v4"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 )
@@ -50,6 +52,14 @@ type ChartWorkingDir string
 const PIPELINE_DEPLOYMENT_TYPE_ACD string = "argo_cd"
 const PIPELINE_DEPLOYMENT_TYPE_HELM string = "helm"
 
+// gitops repository layouts, REPO_PER_APP creates a repository for every app while MONO_REPO and REPO_PER_TEAM
+// keep all apps (of a team) in a shared repository under <team>/<app>/<env> directories
+const (
+	GITOPS_REPO_LAYOUT_PER_APP   = "REPO_PER_APP"
+	GITOPS_REPO_LAYOUT_MONO_REPO = "MONO_REPO"
+	GITOPS_REPO_LAYOUT_PER_TEAM  = "REPO_PER_TEAM"
+)
+
 type ChartTemplateService interface {
 	FetchValuesFromReferenceChart(chartMetaData *chart.Metadata, refChartLocation string, templateName string, userId int32) (*ChartValues, *ChartGitAttribute, error)
 	GetChartVersion(location string) (string, error)
@@ -61,9 +71,13 @@ type ChartTemplateService interface {
 	GetGitOpsRepoNameFromUrl(gitRepoUrl string) string
 	CreateGitRepositoryForApp(gitOpsRepoName, baseTemplateName, version string, userId int32) (chartGitAttribute *ChartGitAttribute, err error)
 	RegisterInArgo(chartGitAttribute *ChartGitAttribute, ctx context.Context) error
-	BuildChartAndPushToGitRepo(chartMetaData *chart.Metadata, referenceTemplatePath string, gitOpsRepoName, referenceTemplate, version, repoUrl string, userId int32) error
+	BuildChartAndPushToGitRepo(chartMetaData *chart.Metadata, referenceTemplatePath string, gitOpsRepoName, chartLocation, repoUrl string, userId int32) error
 	GetByteArrayRefChart(chartMetaData *chart.Metadata, referenceTemplatePath string) ([]byte, error)
 	CreateReadmeInGitRepo(gitOpsRepoName string, userId int32) error
+	IsSharedGitOpsRepoLayout() (bool, error)
+	GetGitOpsRepoNameForApp(appName, teamName string) (string, error)
+	GetGitOpsChartLocation(teamName, appName, envName, referenceTemplate, version string) (string, error)
+	DeleteDirInGitRepo(repoUrl, dirPath string, userId int32) error
 }
 type ChartTemplateServiceImpl struct {
 	randSource             rand.Source
@@ -181,8 +195,8 @@ func (impl ChartTemplateServiceImpl) FetchValuesFromReferenceChart(chartMetaData
 	return values, chartGitAttr, nil
 }
 
-func (impl ChartTemplateServiceImpl) BuildChartAndPushToGitRepo(chartMetaData *chart.Metadata, referenceTemplatePath string, gitOpsRepoName, referenceTemplate, version, repoUrl string, userId int32) error {
-	impl.logger.Debugw("package chart and push to git", "gitOpsRepoName", gitOpsRepoName, "version", version, "referenceTemplate", referenceTemplate, "repoUrl", repoUrl)
+func (impl ChartTemplateServiceImpl) BuildChartAndPushToGitRepo(chartMetaData *chart.Metadata, referenceTemplatePath string, gitOpsRepoName, chartLocation, repoUrl string, userId int32) error {
+	impl.logger.Debugw("package chart and push to git", "gitOpsRepoName", gitOpsRepoName, "chartLocation", chartLocation, "repoUrl", repoUrl)
 	chartMetaData.ApiVersion = "v1" // ensure always v1
 	dir := impl.GetDir()
 	tempReferenceTemplateDir := filepath.Join(string(impl.chartWorkingDir), dir)
@@ -205,7 +219,7 @@ func (impl ChartTemplateServiceImpl) BuildChartAndPushToGitRepo(chartMetaData *c
 		return err
 	}
 
-	err = impl.pushChartToGitRepo(gitOpsRepoName, referenceTemplate, version, tempReferenceTemplateDir, repoUrl, userId)
+	err = impl.pushChartToGitRepo(gitOpsRepoName, chartLocation, tempReferenceTemplateDir, repoUrl, userId)
 	if err != nil {
 		impl.logger.Errorw("error in pushing chart to git ", "err", err)
 		return err
@@ -234,7 +248,7 @@ func (impl ChartTemplateServiceImpl) CreateGitRepositoryForApp(gitOpsRepoName, b
 	return &ChartGitAttribute{RepoUrl: repoUrl, ChartLocation: filepath.Join(baseTemplateName, version)}, nil
 }
 
-func (impl ChartTemplateServiceImpl) pushChartToGitRepo(gitOpsRepoName, referenceTemplate, version, tempReferenceTemplateDir string, repoUrl string, userId int32) (err error) {
+func (impl ChartTemplateServiceImpl) pushChartToGitRepo(gitOpsRepoName, chartLocation, tempReferenceTemplateDir string, repoUrl string, userId int32) (err error) {
 	chartDir := fmt.Sprintf("%s-%s", gitOpsRepoName, impl.GetDir())
 	clonedDir := impl.gitFactory.gitService.GetCloneDirectory(chartDir)
 	if _, err := os.Stat(clonedDir); os.IsNotExist(err) {
@@ -251,7 +265,7 @@ func (impl ChartTemplateServiceImpl) pushChartToGitRepo(gitOpsRepoName, referenc
 		}
 	}
 
-	dir := filepath.Join(clonedDir, referenceTemplate, version)
+	dir := filepath.Join(clonedDir, chartLocation)
 	pushChartToGit := true
 
 	//if chart already exists don't overrides it by reference template
@@ -598,6 +612,95 @@ func (impl ChartTemplateServiceImpl) GetGitOpsRepoName(appName string) string {
 	return repoName
 }
 
+func (impl ChartTemplateServiceImpl) getGitOpsRepoLayout() (layout string, monoRepoName string, err error) {
+	gitOpsConfig, err := impl.gitOpsConfigRepository.GetGitOpsConfigActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching active gitops config", "err", err)
+		return "", "", err
+	}
+	if err == pg.ErrNoRows || len(gitOpsConfig.RepoLayout) == 0 {
+		return GITOPS_REPO_LAYOUT_PER_APP, "", nil
+	}
+	return gitOpsConfig.RepoLayout, gitOpsConfig.MonoRepoName, nil
+}
+
+// IsSharedGitOpsRepoLayout returns true if apps are kept in a shared gitops repository instead of a repository per app
+func (impl ChartTemplateServiceImpl) IsSharedGitOpsRepoLayout() (bool, error) {
+	layout, _, err := impl.getGitOpsRepoLayout()
+	if err != nil {
+		return false, err
+	}
+	return layout != GITOPS_REPO_LAYOUT_PER_APP, nil
+}
+
+// GetGitOpsRepoNameForApp returns name of the gitops repository holding the app as per configured repo layout
+func (impl ChartTemplateServiceImpl) GetGitOpsRepoNameForApp(appName, teamName string) (string, error) {
+	layout, monoRepoName, err := impl.getGitOpsRepoLayout()
+	if err != nil {
+		return "", err
+	}
+	switch layout {
+	case GITOPS_REPO_LAYOUT_MONO_REPO:
+		return monoRepoName, nil
+	case GITOPS_REPO_LAYOUT_PER_TEAM:
+		return impl.GetGitOpsRepoName(teamName), nil
+	default:
+		return impl.GetGitOpsRepoName(appName), nil
+	}
+}
+
+// GetGitOpsChartLocation returns path of the chart within gitops repository, for shared repo layouts chart is
+// placed under <team>/<app>/<env> directory so that every app env is isolated within the repository
+func (impl ChartTemplateServiceImpl) GetGitOpsChartLocation(teamName, appName, envName, referenceTemplate, version string) (string, error) {
+	isShared, err := impl.IsSharedGitOpsRepoLayout()
+	if err != nil {
+		return "", err
+	}
+	if !isShared {
+		return filepath.Join(referenceTemplate, version), nil
+	}
+	return filepath.Join(teamName, appName, envName, referenceTemplate, version), nil
+}
+
+// DeleteDirInGitRepo removes a directory from the gitops repository, used for cleaning up app env directories in shared repositories
+func (impl ChartTemplateServiceImpl) DeleteDirInGitRepo(repoUrl, dirPath string, userId int32) error {
+	gitOpsRepoName := impl.GetGitOpsRepoNameFromUrl(repoUrl)
+	chartDir := fmt.Sprintf("%s-%s", gitOpsRepoName, impl.GetDir())
+	clonedDir, err := impl.gitFactory.gitService.Clone(repoUrl, chartDir)
+	if err != nil {
+		impl.logger.Errorw("error in cloning repo", "url", repoUrl, "err", err)
+		return err
+	}
+	defer impl.CleanDir(clonedDir)
+	dir := filepath.Join(clonedDir, dirPath)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		impl.logger.Infow("dir not found in git repo, skipping delete", "url", repoUrl, "dir", dirPath)
+		return nil
+	}
+	//removing through worktree so that deletion is staged, plain add does not pick up deleted files
+	repo, err := git.PlainOpen(clonedDir)
+	if err != nil {
+		return err
+	}
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	_, err = workTree.Remove(dirPath)
+	if err != nil {
+		impl.logger.Errorw("error in deleting dir", "dir", dir, "err", err)
+		return err
+	}
+	userEmailId, userName := impl.GetUserEmailIdAndNameForGitOpsCommit(userId)
+	commit, err := impl.gitFactory.gitService.CommitAndPushAllChanges(clonedDir, fmt.Sprintf("delete %s", dirPath), userName, userEmailId)
+	if err != nil {
+		impl.logger.Errorw("error in pushing git", "url", repoUrl, "dir", dirPath, "err", err)
+		return err
+	}
+	impl.logger.Infow("dir deleted from git repo", "url", repoUrl, "dir", dirPath, "commit", commit)
+	return nil
+}
+
 func (impl ChartTemplateServiceImpl) GetGitOpsRepoNameFromUrl(gitRepoUrl string) string {
 	gitRepoUrl = gitRepoUrl[strings.LastIndex(gitRepoUrl, "/")+1:]
 	gitRepoUrl = strings.ReplaceAll(gitRepoUrl, ".git", "")
@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/url"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	bean2 "github.com/devtron-labs/devtron/api/bean"
@@ -30,9 +32,12 @@ import (
 	"github.com/go-pg/pg"
 	"github.com/xanzy/go-gitlab"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh/knownhosts"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
 )
 
 const (
@@ -46,6 +51,8 @@ const (
 	GITHUB_PROVIDER       = "GITHUB"
 	AZURE_DEVOPS_PROVIDER = "AZURE_DEVOPS"
 	BITBUCKET_PROVIDER    = "BITBUCKET_CLOUD"
+	GITEA_PROVIDER        = "GITEA"
+	GENERIC_GIT_PROVIDER  = "GENERIC_GIT"
 	GITHUB_API_V3         = "api/v3"
 	GITHUB_HOST           = "github.com"
 )
@@ -57,6 +64,10 @@ type GitClient interface {
 	DeleteRepository(name string) error
 	CreateReadme(name, userName, userEmailId string) (string, error)
 	GetCommits(repoName, projectName string) ([]*GitCommitDto, error)
+	CommitValuesToBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error)
+	CreatePullRequest(repoName, branch, title, description string) (*PullRequestDto, error)
+	GetPullRequest(repoName string, number int) (*PullRequestDto, error)
+	ClosePullRequest(repoName string, number int) error
 }
 
 type GitFactory struct {
@@ -81,6 +92,19 @@ type GitCommitDto struct {
 	CommitTime time.Time `json:"commitTime"`
 }
 
+const (
+	PULL_REQUEST_STATE_OPEN   = "OPEN"
+	PULL_REQUEST_STATE_MERGED = "MERGED"
+	PULL_REQUEST_STATE_CLOSED = "CLOSED"
+)
+
+type PullRequestDto struct {
+	Number          int    `json:"number"`
+	Url             string `json:"url"`
+	State           string `json:"state"`
+	MergeCommitHash string `json:"mergeCommitHash"`
+}
+
 func (factory *GitFactory) Reload() error {
 	logger.Infow("reloading gitops details")
 	cfg, err := GetGitConfig(factory.gitOpsRepository)
@@ -141,6 +165,9 @@ func (factory *GitFactory) NewClientForValidation(gitOpsConfig *bean2.GitOpsConf
 		GitHost:            gitOpsConfig.Host,
 		AzureToken:         gitOpsConfig.Token,
 		AzureProject:       gitOpsConfig.AzureProjectName,
+		GiteaOrganization:  gitOpsConfig.GiteaOrgId,
+		SshPrivateKey:      gitOpsConfig.SshPrivateKey,
+		SshKnownHosts:      gitOpsConfig.SshKnownHosts,
 	}
 	gitService := NewGitServiceImpl(cfg, logger, factory.gitCliUtil)
 	//factory.gitService = gitService
@@ -187,6 +214,9 @@ type GitConfig struct {
 	AzureProject         string
 	BitbucketWorkspaceId string
 	BitbucketProjectKey  string
+	GiteaOrganization    string
+	SshPrivateKey        string //used for pushing over ssh, only for generic git provider
+	SshKnownHosts        string //known_hosts entries the host key of the git server is verified against
 }
 
 func GetGitConfig(gitOpsRepository repository.GitOpsConfigRepository) (*GitConfig, error) {
@@ -216,6 +246,9 @@ func GetGitConfig(gitOpsRepository repository.GitOpsConfigRepository) (*GitConfi
 		AzureProject:         gitOpsConfig.AzureProject,
 		BitbucketWorkspaceId: gitOpsConfig.BitBucketWorkspaceId,
 		BitbucketProjectKey:  gitOpsConfig.BitBucketProjectKey,
+		GiteaOrganization:    gitOpsConfig.GiteaOrgId,
+		SshPrivateKey:        gitOpsConfig.SshPrivateKey,
+		SshKnownHosts:        gitOpsConfig.SshKnownHosts,
 	}
 	return cfg, err
 }
@@ -233,6 +266,12 @@ func NewGitOpsClient(config *GitConfig, logger *zap.SugaredLogger, gitService Gi
 	} else if config.GitProvider == BITBUCKET_PROVIDER {
 		gitBitbucketClient := NewGitBitbucketClient(config.GitUserName, config.GitToken, config.GitHost, logger, gitService, gitOpsConfigRepository)
 		return gitBitbucketClient, nil
+	} else if config.GitProvider == GITEA_PROVIDER {
+		gitGiteaClient, err := NewGitGiteaClient(config.GitHost, config.GitToken, config.GiteaOrganization, config.GitUserName, logger, gitService)
+		return gitGiteaClient, err
+	} else if config.GitProvider == GENERIC_GIT_PROVIDER {
+		gitGenericClient, err := NewGitGenericClient(config.GitHost, logger, gitService)
+		return gitGenericClient, err
 	} else {
 		logger.Errorw("no gitops config provided, gitops will not work ")
 		return nil, nil
@@ -262,6 +301,8 @@ type GitService interface {
 }
 type GitServiceImpl struct {
 	Auth       *http.BasicAuth
+	sshAuth    *ssh.PublicKeys
+	sshAuthErr error
 	config     *GitConfig
 	logger     *zap.SugaredLogger
 	gitCliUtil *GitCliUtil
@@ -269,14 +310,56 @@ type GitServiceImpl struct {
 
 func NewGitServiceImpl(config *GitConfig, logger *zap.SugaredLogger, GitCliUtil *GitCliUtil) *GitServiceImpl {
 	auth := &http.BasicAuth{Password: config.GitToken, Username: config.GitUserName}
+	var sshAuth *ssh.PublicKeys
+	var sshAuthErr error
+	if len(config.SshPrivateKey) > 0 {
+		// not falling back to basic auth, git operations fail with this error till the config is fixed
+		sshAuth, sshAuthErr = NewGitOpsSshAuth(config.SshPrivateKey, config.SshKnownHosts)
+		if sshAuthErr != nil {
+			logger.Errorw("error in building gitops ssh auth", "err", sshAuthErr)
+		}
+	}
 	return &GitServiceImpl{
 		Auth:       auth,
+		sshAuth:    sshAuth,
+		sshAuthErr: sshAuthErr,
 		logger:     logger,
 		config:     config,
 		gitCliUtil: GitCliUtil,
 	}
 }
 
+// NewGitOpsSshAuth parses the ssh private key of the gitops config, the host key of the git server is verified
+// against the known_hosts entries of the config
+func NewGitOpsSshAuth(sshPrivateKey string, sshKnownHosts string) (*ssh.PublicKeys, error) {
+	sshAuth, err := ssh.NewPublicKeys("git", []byte(sshPrivateKey), "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh private key: %v", err)
+	}
+	if len(strings.TrimSpace(sshKnownHosts)) == 0 {
+		return nil, fmt.Errorf("ssh known hosts are required to verify the git server")
+	}
+	knownHostsFile, err := ioutil.TempFile("", "gitops_known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(knownHostsFile.Name())
+	_, err = knownHostsFile.WriteString(sshKnownHosts)
+	closeErr := knownHostsFile.Close()
+	if err != nil {
+		return nil, err
+	} else if closeErr != nil {
+		return nil, closeErr
+	}
+	//entries are read when the callback is built, so the file is not needed afterwards
+	hostKeyCallback, err := knownhosts.New(knownHostsFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh known hosts: %v", err)
+	}
+	sshAuth.HostKeyCallback = hostKeyCallback
+	return sshAuth, nil
+}
+
 func (impl GitServiceImpl) GetCloneDirectory(targetDir string) (clonedDir string) {
 	clonedDir = filepath.Join(impl.config.GitWorkingDir, targetDir)
 	return clonedDir
@@ -285,6 +368,17 @@ func (impl GitServiceImpl) GetCloneDirectory(targetDir string) (clonedDir string
 func (impl GitServiceImpl) Clone(url, targetDir string) (clonedDir string, err error) {
 	impl.logger.Debugw("git checkout ", "url", url, "dir", targetDir)
 	clonedDir = filepath.Join(impl.config.GitWorkingDir, targetDir)
+	if impl.sshAuthErr != nil {
+		return "", impl.sshAuthErr
+	}
+	if impl.sshAuth != nil {
+		err = impl.cloneOverSsh(url, clonedDir)
+		if err != nil {
+			impl.logger.Errorw("error in git checkout over ssh", "url", url, "targetDir", targetDir, "err", err)
+			return "", err
+		}
+		return clonedDir, nil
+	}
 	_, errorMsg, err := impl.gitCliUtil.Clone(clonedDir, url, impl.Auth.Username, impl.Auth.Password)
 	if err != nil {
 		impl.logger.Errorw("error in git checkout", "url", url, "targetDir", targetDir, "err", err)
@@ -296,6 +390,29 @@ func (impl GitServiceImpl) Clone(url, targetDir string) (clonedDir string, err e
 	return clonedDir, nil
 }
 
+func (impl GitServiceImpl) cloneOverSsh(url, clonedDir string) error {
+	err := os.RemoveAll(clonedDir)
+	if err != nil {
+		return err
+	}
+	_, err = git.PlainClone(clonedDir, false, &git.CloneOptions{URL: url, Auth: impl.sshAuth})
+	if err == transport.ErrEmptyRemoteRepository {
+		//pre-created repositories can be empty, initialising with remote so that first commit can be pushed
+		return impl.gitCliUtil.Init(clonedDir, url, false)
+	}
+	return err
+}
+
+func (impl GitServiceImpl) getAuth() (transport.AuthMethod, error) {
+	if impl.sshAuthErr != nil {
+		return nil, impl.sshAuthErr
+	}
+	if impl.sshAuth != nil {
+		return impl.sshAuth, nil
+	}
+	return impl.Auth, nil
+}
+
 func (impl GitServiceImpl) CommitAndPushAllChanges(repoRoot, commitMsg, name, emailId string) (commitHash string, err error) {
 	repo, workTree, err := impl.getRepoAndWorktree(repoRoot)
 	if err != nil {
@@ -323,8 +440,12 @@ func (impl GitServiceImpl) CommitAndPushAllChanges(repoRoot, commitMsg, name, em
 	}
 	impl.logger.Debugw("git hash", "repo", repoRoot, "hash", commit.String())
 	//-----------push
+	auth, err := impl.getAuth()
+	if err != nil {
+		return "", err
+	}
 	err = repo.Push(&git.PushOptions{
-		Auth: impl.Auth,
+		Auth: auth,
 	})
 
 	return commit.String(), err
@@ -348,8 +469,12 @@ func (impl GitServiceImpl) ForceResetHead(repoRoot string) (err error) {
 	if err != nil {
 		return err
 	}
+	auth, err := impl.getAuth()
+	if err != nil {
+		return err
+	}
 	err = workTree.Pull(&git.PullOptions{
-		Auth:         impl.Auth,
+		Auth:         auth,
 		Force:        true,
 		SingleBranch: true,
 	})
@@ -376,8 +501,12 @@ func (impl GitServiceImpl) Pull(repoRoot string) (err error) {
 		return err
 	}
 	//-----------pull
+	auth, err := impl.getAuth()
+	if err != nil {
+		return err
+	}
 	err = workTree.PullContext(context.Background(), &git.PullOptions{
-		Auth: impl.Auth,
+		Auth: auth,
 	})
 	if err != nil && err.Error() == "already up-to-date" {
 		err = nil
@@ -330,3 +330,19 @@ func (impl GitAzureClient) GetCommits(repoName, projectName string) ([]*GitCommi
 	}
 	return gitCommitsDto, nil
 }
+
+func (impl GitAzureClient) CommitValuesToBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error) {
+	return "", time.Time{}, fmt.Errorf("pull request based commits are not supported for azure devops")
+}
+
+func (impl GitAzureClient) CreatePullRequest(repoName, branch, title, description string) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for azure devops")
+}
+
+func (impl GitAzureClient) GetPullRequest(repoName string, number int) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for azure devops")
+}
+
+func (impl GitAzureClient) ClosePullRequest(repoName string, number int) error {
+	return fmt.Errorf("pull requests are not supported for azure devops")
+}
@@ -341,3 +341,19 @@ func (impl GitBitbucketClient) GetCommits(repoName, projectName string) ([]*GitC
 	}
 	return gitCommitsDto, nil
 }
+
+func (impl GitBitbucketClient) CommitValuesToBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error) {
+	return "", time.Time{}, fmt.Errorf("pull request based commits are not supported for bitbucket")
+}
+
+func (impl GitBitbucketClient) CreatePullRequest(repoName, branch, title, description string) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for bitbucket")
+}
+
+func (impl GitBitbucketClient) GetPullRequest(repoName string, number int) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for bitbucket")
+}
+
+func (impl GitBitbucketClient) ClosePullRequest(repoName string, number int) error {
+	return fmt.Errorf("pull requests are not supported for bitbucket")
+}
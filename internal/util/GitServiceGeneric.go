@@ -0,0 +1,167 @@
+package util
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const GENERIC_GITOPS_DIR = "genericGitOps"
+
+// GitGenericClient works with any git server reachable over https or ssh. As there is no api for managing
+// repositories, repos have to be pre-created as <host>/<repo-name>.git and are only cloned, committed to and pushed.
+type GitGenericClient struct {
+	host       string
+	logger     *zap.SugaredLogger
+	gitService GitService
+}
+
+func NewGitGenericClient(host string, logger *zap.SugaredLogger, gitService GitService) (GitGenericClient, error) {
+	if len(host) == 0 {
+		return GitGenericClient{}, fmt.Errorf("host is required for generic git provider")
+	}
+	return GitGenericClient{
+		host:       strings.TrimSuffix(host, "/"),
+		logger:     logger,
+		gitService: gitService,
+	}, nil
+}
+
+func (impl GitGenericClient) buildRepoUrl(repoName string) string {
+	return fmt.Sprintf("%s/%s.git", impl.host, repoName)
+}
+
+func (impl GitGenericClient) getCloneDir(repoName string) string {
+	return filepath.Join(GENERIC_GITOPS_DIR, fmt.Sprintf("%s-%d", repoName, time.Now().UnixNano()))
+}
+
+func (impl GitGenericClient) cleanDir(targetDir string) {
+	err := os.RemoveAll(impl.gitService.GetCloneDirectory(targetDir))
+	if err != nil {
+		impl.logger.Warnw("error in deleting dir", "dir", targetDir, "err", err)
+	}
+}
+
+func (impl GitGenericClient) DeleteRepository(name string) error {
+	return fmt.Errorf("repository %s is managed outside devtron, deleting repositories is not supported for generic git provider", name)
+}
+
+func (impl GitGenericClient) GetRepoUrl(projectName string) (repoUrl string, err error) {
+	repoUrl = impl.buildRepoUrl(projectName)
+	targetDir := impl.getCloneDir(projectName)
+	defer impl.cleanDir(targetDir)
+	_, err = impl.gitService.Clone(repoUrl, targetDir)
+	if err != nil {
+		impl.logger.Errorw("error in reaching generic git repo", "repoUrl", repoUrl, "err", err)
+		return "", err
+	}
+	return repoUrl, nil
+}
+
+func (impl GitGenericClient) CreateRepository(name, description, userName, userEmailId string) (url string, isNew bool, detailedErrorGitOpsConfigActions DetailedErrorGitOpsConfigActions) {
+	detailedErrorGitOpsConfigActions.StageErrorMap = make(map[string]error)
+	url, err := impl.GetRepoUrl(name)
+	if err != nil {
+		detailedErrorGitOpsConfigActions.StageErrorMap[GetRepoUrlStage] = fmt.Errorf("repository %s is not reachable, repositories have to be pre-created for generic git provider: %s", impl.buildRepoUrl(name), err.Error())
+		return "", false, detailedErrorGitOpsConfigActions
+	}
+	detailedErrorGitOpsConfigActions.SuccessfulStages = append(detailedErrorGitOpsConfigActions.SuccessfulStages, GetRepoUrlStage)
+	return url, false, detailedErrorGitOpsConfigActions
+}
+
+func (impl GitGenericClient) CreateReadme(repoName, userName, userEmailId string) (string, error) {
+	cfg := &ChartConfig{
+		ChartName:      repoName,
+		ChartLocation:  "",
+		FileName:       "README.md",
+		FileContent:    "@devtron",
+		ReleaseMessage: "readme",
+		ChartRepoName:  repoName,
+		UserName:       userName,
+		UserEmailId:    userEmailId,
+	}
+	hash, _, err := impl.CommitValues(cfg)
+	if err != nil {
+		impl.logger.Errorw("error in creating readme generic git", "repo", repoName, "err", err)
+	}
+	return hash, err
+}
+
+func (impl GitGenericClient) CommitValues(config *ChartConfig) (commitHash string, commitTime time.Time, err error) {
+	repoUrl := impl.buildRepoUrl(config.ChartRepoName)
+	targetDir := impl.getCloneDir(config.ChartRepoName)
+	defer impl.cleanDir(targetDir)
+	clonedDir, err := impl.gitService.Clone(repoUrl, targetDir)
+	if err != nil {
+		impl.logger.Errorw("error in cloning generic git repo", "repoUrl", repoUrl, "err", err)
+		return "", time.Time{}, err
+	}
+	fileDir := filepath.Join(clonedDir, config.ChartLocation)
+	err = os.MkdirAll(fileDir, 0755)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	err = ioutil.WriteFile(filepath.Join(fileDir, config.FileName), []byte(config.FileContent), 0600)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	commitTime = time.Now()
+	commitHash, err = impl.gitService.CommitAndPushAllChanges(clonedDir, config.ReleaseMessage, config.UserName, config.UserEmailId)
+	if err != nil {
+		impl.logger.Errorw("error in commit generic git", "err", err, "config", config)
+		return "", time.Time{}, err
+	}
+	return commitHash, commitTime, nil
+}
+
+func (impl GitGenericClient) GetCommits(repoName, projectName string) ([]*GitCommitDto, error) {
+	repoUrl := impl.buildRepoUrl(repoName)
+	targetDir := impl.getCloneDir(repoName)
+	defer impl.cleanDir(targetDir)
+	clonedDir, err := impl.gitService.Clone(repoUrl, targetDir)
+	if err != nil {
+		impl.logger.Errorw("error in cloning generic git repo", "repoUrl", repoUrl, "err", err)
+		return nil, err
+	}
+	repo, err := git.PlainOpen(clonedDir)
+	if err != nil {
+		return nil, err
+	}
+	commitIterator, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		impl.logger.Errorw("error in getting commits", "err", err, "repoName", repoName)
+		return nil, err
+	}
+	var gitCommitsDto []*GitCommitDto
+	err = commitIterator.ForEach(func(commit *object.Commit) error {
+		gitCommitsDto = append(gitCommitsDto, &GitCommitDto{
+			CommitHash: commit.Hash.String(),
+			AuthorName: commit.Author.Name,
+			CommitTime: commit.Author.When,
+		})
+		return nil
+	})
+	return gitCommitsDto, err
+}
+
+func (impl GitGenericClient) CommitValuesToBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error) {
+	return "", time.Time{}, fmt.Errorf("pull request based commits are not supported for generic git")
+}
+
+func (impl GitGenericClient) CreatePullRequest(repoName, branch, title, description string) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for generic git")
+}
+
+func (impl GitGenericClient) GetPullRequest(repoName string, number int) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for generic git")
+}
+
+func (impl GitGenericClient) ClosePullRequest(repoName string, number int) error {
+	return fmt.Errorf("pull requests are not supported for generic git")
+}
@@ -0,0 +1,399 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	http2 "net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	GITEA_API_V1         = "api/v1"
+	GITEA_DEFAULT_BRANCH = "master"
+)
+
+type GitGiteaClient struct {
+	httpClient *http2.Client
+	apiUrl     string
+	token      string
+	// owner is the organisation under which repos are created, user's own namespace is used if org is not provided
+	owner      string
+	isOrg      bool
+	logger     *zap.SugaredLogger
+	gitService GitService
+}
+
+type GiteaApiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *GiteaApiError) Error() string {
+	return fmt.Sprintf("gitea api error, status: %d, message: %s", err.StatusCode, err.Message)
+}
+
+type giteaRepository struct {
+	Name     string `json:"name"`
+	CloneUrl string `json:"clone_url"`
+	SshUrl   string `json:"ssh_url"`
+	Empty    bool   `json:"empty"`
+}
+
+type giteaCreateRepoRequest struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Private       bool   `json:"private"`
+	AutoInit      bool   `json:"auto_init"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type giteaIdentity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type giteaCommitDates struct {
+	Author    time.Time `json:"author"`
+	Committer time.Time `json:"committer"`
+}
+
+type giteaFileOptions struct {
+	Content   string           `json:"content"`
+	Message   string           `json:"message"`
+	Branch    string           `json:"branch"`
+	Sha       string           `json:"sha,omitempty"`
+	Author    giteaIdentity    `json:"author"`
+	Committer giteaIdentity    `json:"committer"`
+	Dates     giteaCommitDates `json:"dates"`
+}
+
+type giteaCommitUser struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+type giteaFileCommit struct {
+	Sha       string          `json:"sha"`
+	Author    giteaCommitUser `json:"author"`
+	Committer giteaCommitUser `json:"committer"`
+}
+
+type giteaFileResponse struct {
+	Commit giteaFileCommit `json:"commit"`
+}
+
+type giteaContent struct {
+	Sha string `json:"sha"`
+}
+
+type giteaCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Author giteaCommitUser `json:"author"`
+	} `json:"commit"`
+}
+
+func NewGitGiteaClient(host, token, org, username string, logger *zap.SugaredLogger, gitService GitService) (GitGiteaClient, error) {
+	hostUrl, err := url.Parse(host)
+	if err != nil || len(hostUrl.Host) == 0 {
+		logger.Errorw("error in creating gitea client", "host", host, "err", err)
+		return GitGiteaClient{}, fmt.Errorf("invalid gitea host %s", host)
+	}
+	hostUrl.Path = path.Join(hostUrl.Path, GITEA_API_V1)
+	owner := org
+	if len(owner) == 0 {
+		owner = username
+	}
+	return GitGiteaClient{
+		httpClient: &http2.Client{Timeout: 60 * time.Second},
+		apiUrl:     hostUrl.String(),
+		token:      token,
+		owner:      owner,
+		isOrg:      len(org) > 0,
+		logger:     logger,
+		gitService: gitService,
+	}, nil
+}
+
+func (impl GitGiteaClient) doRequest(method, apiPath string, body interface{}, response interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(bodyBytes)
+	}
+	req, err := http2.NewRequest(method, impl.apiUrl+apiPath, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+impl.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := impl.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http2.StatusOK || resp.StatusCode >= http2.StatusMultipleChoices {
+		apiErr := &GiteaApiError{StatusCode: resp.StatusCode, Message: string(respBody)}
+		errResponse := struct {
+			Message string `json:"message"`
+		}{}
+		if json.Unmarshal(respBody, &errResponse) == nil && len(errResponse.Message) > 0 {
+			apiErr.Message = errResponse.Message
+		}
+		return apiErr
+	}
+	if response != nil && len(respBody) > 0 {
+		return json.Unmarshal(respBody, response)
+	}
+	return nil
+}
+
+func isGiteaNotFoundError(err error) bool {
+	apiErr, ok := err.(*GiteaApiError)
+	return ok && apiErr.StatusCode == http2.StatusNotFound
+}
+
+func (impl GitGiteaClient) repoPath(repoName string) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(impl.owner), url.PathEscape(repoName))
+}
+
+func (impl GitGiteaClient) createRepoPath() string {
+	if impl.isOrg {
+		return fmt.Sprintf("/orgs/%s/repos", url.PathEscape(impl.owner))
+	}
+	return "/user/repos"
+}
+
+func (impl GitGiteaClient) contentPath(repoName, filePath string) string {
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(filePath), "/") {
+		if len(segment) > 0 {
+			segments = append(segments, url.PathEscape(segment))
+		}
+	}
+	return fmt.Sprintf("%s/contents/%s", impl.repoPath(repoName), strings.Join(segments, "/"))
+}
+
+func (impl GitGiteaClient) DeleteRepository(name string) error {
+	err := impl.doRequest(http2.MethodDelete, impl.repoPath(name), nil, nil)
+	if err != nil {
+		impl.logger.Errorw("repo deletion failed for gitea", "repo", name, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl GitGiteaClient) GetRepoUrl(projectName string) (repoUrl string, err error) {
+	repo := &giteaRepository{}
+	err = impl.doRequest(http2.MethodGet, impl.repoPath(projectName), nil, repo)
+	if err != nil {
+		return "", err
+	}
+	return repo.CloneUrl, nil
+}
+
+func (impl GitGiteaClient) CreateRepository(name, description, userName, userEmailId string) (url string, isNew bool, detailedErrorGitOpsConfigActions DetailedErrorGitOpsConfigActions) {
+	detailedErrorGitOpsConfigActions.StageErrorMap = make(map[string]error)
+	url, err := impl.GetRepoUrl(name)
+	if err == nil {
+		detailedErrorGitOpsConfigActions.SuccessfulStages = append(detailedErrorGitOpsConfigActions.SuccessfulStages, GetRepoUrlStage)
+		return url, false, detailedErrorGitOpsConfigActions
+	} else if !isGiteaNotFoundError(err) {
+		impl.logger.Errorw("error in getting gitea repo", "repo", name, "err", err)
+		detailedErrorGitOpsConfigActions.StageErrorMap[GetRepoUrlStage] = err
+		return "", false, detailedErrorGitOpsConfigActions
+	}
+	repo := &giteaRepository{}
+	err = impl.doRequest(http2.MethodPost, impl.createRepoPath(), &giteaCreateRepoRequest{
+		Name:          name,
+		Description:   description,
+		Private:       true,
+		AutoInit:      true,
+		DefaultBranch: GITEA_DEFAULT_BRANCH,
+	}, repo)
+	if err != nil {
+		impl.logger.Errorw("error in creating gitea repo", "repo", name, "err", err)
+		detailedErrorGitOpsConfigActions.StageErrorMap[CreateRepoStage] = err
+		return "", true, detailedErrorGitOpsConfigActions
+	}
+	impl.logger.Infow("gitea repo created", "repo", repo.CloneUrl)
+	detailedErrorGitOpsConfigActions.SuccessfulStages = append(detailedErrorGitOpsConfigActions.SuccessfulStages, CreateRepoStage)
+
+	validated, err := impl.ensureProjectAvailabilityOnHttp(name)
+	if err != nil {
+		impl.logger.Errorw("error in ensuring project availability gitea", "project", name, "err", err)
+		detailedErrorGitOpsConfigActions.StageErrorMap[CloneHttpStage] = err
+		return repo.CloneUrl, true, detailedErrorGitOpsConfigActions
+	}
+	if !validated {
+		detailedErrorGitOpsConfigActions.StageErrorMap[CloneHttpStage] = fmt.Errorf("unable to validate project:%s in given time", name)
+		return "", true, detailedErrorGitOpsConfigActions
+	}
+	detailedErrorGitOpsConfigActions.SuccessfulStages = append(detailedErrorGitOpsConfigActions.SuccessfulStages, CloneHttpStage)
+
+	_, err = impl.CreateReadme(name, userName, userEmailId)
+	if err != nil {
+		impl.logger.Errorw("error in creating readme gitea", "project", name, "err", err)
+		detailedErrorGitOpsConfigActions.StageErrorMap[CreateReadmeStage] = err
+		return repo.CloneUrl, true, detailedErrorGitOpsConfigActions
+	}
+	detailedErrorGitOpsConfigActions.SuccessfulStages = append(detailedErrorGitOpsConfigActions.SuccessfulStages, CreateReadmeStage)
+
+	validated, err = impl.ensureProjectAvailabilityOnSsh(name, repo.CloneUrl)
+	if err != nil {
+		impl.logger.Errorw("error in ensuring project availability gitea", "project", name, "err", err)
+		detailedErrorGitOpsConfigActions.StageErrorMap[CloneSshStage] = err
+		return repo.CloneUrl, true, detailedErrorGitOpsConfigActions
+	}
+	if !validated {
+		detailedErrorGitOpsConfigActions.StageErrorMap[CloneSshStage] = fmt.Errorf("unable to validate project:%s in given time", name)
+		return "", true, detailedErrorGitOpsConfigActions
+	}
+	detailedErrorGitOpsConfigActions.SuccessfulStages = append(detailedErrorGitOpsConfigActions.SuccessfulStages, CloneSshStage)
+	return repo.CloneUrl, true, detailedErrorGitOpsConfigActions
+}
+
+func (impl GitGiteaClient) CreateReadme(repoName, userName, userEmailId string) (string, error) {
+	cfg := &ChartConfig{
+		ChartName:      repoName,
+		ChartLocation:  "",
+		FileName:       "README.md",
+		FileContent:    "@devtron",
+		ReleaseMessage: "readme",
+		ChartRepoName:  repoName,
+		UserName:       userName,
+		UserEmailId:    userEmailId,
+	}
+	hash, _, err := impl.CommitValues(cfg)
+	if err != nil {
+		impl.logger.Errorw("error in creating readme gitea", "repo", repoName, "err", err)
+	}
+	return hash, err
+}
+
+func (impl GitGiteaClient) CommitValues(config *ChartConfig) (commitHash string, commitTime time.Time, err error) {
+	filePath := filepath.Join(config.ChartLocation, config.FileName)
+	contentPath := impl.contentPath(config.ChartRepoName, filePath)
+	existing := &giteaContent{}
+	newFile := false
+	err = impl.doRequest(http2.MethodGet, fmt.Sprintf("%s?ref=%s", contentPath, GITEA_DEFAULT_BRANCH), nil, existing)
+	if err != nil {
+		if !isGiteaNotFoundError(err) {
+			impl.logger.Errorw("error in getting file from gitea", "err", err, "config", config)
+			return "", time.Time{}, err
+		}
+		newFile = true
+	}
+	timeNow := time.Now()
+	identity := giteaIdentity{Name: config.UserName, Email: config.UserEmailId}
+	options := &giteaFileOptions{
+		Content:   base64.StdEncoding.EncodeToString([]byte(config.FileContent)),
+		Message:   config.ReleaseMessage,
+		Branch:    GITEA_DEFAULT_BRANCH,
+		Author:    identity,
+		Committer: identity,
+		Dates:     giteaCommitDates{Author: timeNow, Committer: timeNow},
+	}
+	method := http2.MethodPost
+	if !newFile {
+		method = http2.MethodPut
+		options.Sha = existing.Sha
+	}
+	fileResponse := &giteaFileResponse{}
+	err = impl.doRequest(method, contentPath, options, fileResponse)
+	if err != nil {
+		impl.logger.Errorw("error in commit gitea", "err", err, "config", config)
+		return "", time.Time{}, err
+	}
+	commitTime = fileResponse.Commit.Author.Date
+	if commitTime.IsZero() {
+		commitTime = timeNow
+	}
+	return fileResponse.Commit.Sha, commitTime, nil
+}
+
+func (impl GitGiteaClient) ensureProjectAvailabilityOnHttp(projectName string) (bool, error) {
+	count := 0
+	for count < 3 {
+		count = count + 1
+		_, err := impl.GetRepoUrl(projectName)
+		if err == nil {
+			return true, nil
+		}
+		if !isGiteaNotFoundError(err) {
+			impl.logger.Errorw("error in validating repo gitea", "project", projectName, "err", err)
+			return false, err
+		}
+		impl.logger.Errorw("error in validating repo gitea", "project", projectName, "err", err)
+		time.Sleep(10 * time.Second)
+	}
+	return false, nil
+}
+
+func (impl GitGiteaClient) ensureProjectAvailabilityOnSsh(projectName string, repoUrl string) (bool, error) {
+	count := 0
+	for count < 3 {
+		count = count + 1
+		_, err := impl.gitService.Clone(repoUrl, fmt.Sprintf("/ensure-clone/%s", projectName))
+		if err == nil {
+			impl.logger.Infow("gitea ensureProjectAvailability clone passed", "try count", count, "repoUrl", repoUrl)
+			return true, nil
+		}
+		impl.logger.Errorw("gitea ensureProjectAvailability clone failed", "try count", count, "err", err)
+		time.Sleep(10 * time.Second)
+	}
+	return false, nil
+}
+
+func (impl GitGiteaClient) GetCommits(repoName, projectName string) ([]*GitCommitDto, error) {
+	var giteaCommits []*giteaCommit
+	err := impl.doRequest(http2.MethodGet, fmt.Sprintf("%s/commits?sha=%s", impl.repoPath(repoName), GITEA_DEFAULT_BRANCH), nil, &giteaCommits)
+	if err != nil {
+		impl.logger.Errorw("error in getting commits", "err", err, "repoName", repoName)
+		return nil, err
+	}
+	var gitCommitsDto []*GitCommitDto
+	for _, commit := range giteaCommits {
+		gitCommitDto := &GitCommitDto{
+			CommitHash: commit.Sha,
+			AuthorName: commit.Commit.Author.Name,
+			CommitTime: commit.Commit.Author.Date,
+		}
+		gitCommitsDto = append(gitCommitsDto, gitCommitDto)
+	}
+	return gitCommitsDto, nil
+}
+
+func (impl GitGiteaClient) CommitValuesToBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error) {
+	return "", time.Time{}, fmt.Errorf("pull request based commits are not supported for gitea")
+}
+
+func (impl GitGiteaClient) CreatePullRequest(repoName, branch, title, description string) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for gitea")
+}
+
+func (impl GitGiteaClient) GetPullRequest(repoName string, number int) (*PullRequestDto, error) {
+	return nil, fmt.Errorf("pull requests are not supported for gitea")
+}
+
+func (impl GitGiteaClient) ClosePullRequest(repoName string, number int) error {
+	return fmt.Errorf("pull requests are not supported for gitea")
+}
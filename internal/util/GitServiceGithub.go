@@ -165,7 +165,88 @@ func (impl GitHubClient) CreateReadme(repoName, userName, userEmailId string) (s
 }
 
 func (impl GitHubClient) CommitValues(config *ChartConfig) (commitHash string, commitTime time.Time, err error) {
-	branch := "master"
+	return impl.commitValuesOnBranch(config, "master")
+}
+
+// CommitValuesToBranch commits values on the given branch, branch is created from master if not present
+func (impl GitHubClient) CommitValuesToBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error) {
+	err = impl.ensureBranch(config.ChartRepoName, branch)
+	if err != nil {
+		impl.logger.Errorw("error in creating branch github", "repo", config.ChartRepoName, "branch", branch, "err", err)
+		return "", time.Time{}, err
+	}
+	return impl.commitValuesOnBranch(config, branch)
+}
+
+func (impl GitHubClient) ensureBranch(repoName, branch string) error {
+	ctx := context.Background()
+	_, _, err := impl.client.Git.GetRef(ctx, impl.org, repoName, "refs/heads/"+branch)
+	if err == nil {
+		return nil
+	}
+	responseErr, ok := err.(*github.ErrorResponse)
+	if !ok || responseErr.Response.StatusCode != 404 {
+		return err
+	}
+	baseRef, _, err := impl.client.Git.GetRef(ctx, impl.org, repoName, "refs/heads/master")
+	if err != nil {
+		return err
+	}
+	ref := "refs/heads/" + branch
+	_, _, err = impl.client.Git.CreateRef(ctx, impl.org, repoName, &github.Reference{Ref: &ref, Object: baseRef.Object})
+	return err
+}
+
+func (impl GitHubClient) CreatePullRequest(repoName, branch, title, description string) (*PullRequestDto, error) {
+	base := "master"
+	pr, _, err := impl.client.PullRequests.Create(context.Background(), impl.org, repoName, &github.NewPullRequest{
+		Title: &title,
+		Head:  &branch,
+		Base:  &base,
+		Body:  &description,
+	})
+	if err != nil {
+		impl.logger.Errorw("error in creating pull request github", "repo", repoName, "branch", branch, "err", err)
+		return nil, err
+	}
+	return impl.toPullRequestDto(pr), nil
+}
+
+func (impl GitHubClient) GetPullRequest(repoName string, number int) (*PullRequestDto, error) {
+	pr, _, err := impl.client.PullRequests.Get(context.Background(), impl.org, repoName, number)
+	if err != nil {
+		impl.logger.Errorw("error in getting pull request github", "repo", repoName, "number", number, "err", err)
+		return nil, err
+	}
+	return impl.toPullRequestDto(pr), nil
+}
+
+func (impl GitHubClient) ClosePullRequest(repoName string, number int) error {
+	state := "closed"
+	_, _, err := impl.client.PullRequests.Edit(context.Background(), impl.org, repoName, number, &github.PullRequest{State: &state})
+	if err != nil {
+		impl.logger.Errorw("error in closing pull request github", "repo", repoName, "number", number, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl GitHubClient) toPullRequestDto(pr *github.PullRequest) *PullRequestDto {
+	state := PULL_REQUEST_STATE_OPEN
+	if pr.GetMerged() {
+		state = PULL_REQUEST_STATE_MERGED
+	} else if pr.GetState() == "closed" {
+		state = PULL_REQUEST_STATE_CLOSED
+	}
+	return &PullRequestDto{
+		Number:          pr.GetNumber(),
+		Url:             pr.GetHTMLURL(),
+		State:           state,
+		MergeCommitHash: pr.GetMergeCommitSHA(),
+	}
+}
+
+func (impl GitHubClient) commitValuesOnBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error) {
 	path := filepath.Join(config.ChartLocation, config.FileName)
 	ctx := context.Background()
 	newFile := false
@@ -243,9 +243,85 @@ func (impl GitLabClient) checkIfFileExists(projectName, ref, file string) (exist
 }
 
 func (impl GitLabClient) CommitValues(config *ChartConfig) (commitHash string, commitTime time.Time, err error) {
-	branch := "master"
+	return impl.commitValuesOnBranch(config, "master", "")
+}
+
+// CommitValuesToBranch commits values on the given branch, branch is created from master if not present
+func (impl GitLabClient) CommitValuesToBranch(config *ChartConfig, branch string) (commitHash string, commitTime time.Time, err error) {
+	_, _, err = impl.client.Branches.GetBranch(fmt.Sprintf("%s/%s", impl.config.GitlabGroupPath, config.ChartRepoName), branch)
+	if err == nil {
+		return impl.commitValuesOnBranch(config, branch, "")
+	}
+	return impl.commitValuesOnBranch(config, branch, "master")
+}
+
+func (impl GitLabClient) CreatePullRequest(repoName, branch, title, description string) (*PullRequestDto, error) {
+	targetBranch := "master"
+	mr, _, err := impl.client.MergeRequests.CreateMergeRequest(fmt.Sprintf("%s/%s", impl.config.GitlabGroupPath, repoName), &gitlab.CreateMergeRequestOptions{
+		Title:        &title,
+		Description:  &description,
+		SourceBranch: &branch,
+		TargetBranch: &targetBranch,
+	})
+	if err != nil {
+		impl.logger.Errorw("error in creating merge request gitlab", "repo", repoName, "branch", branch, "err", err)
+		return nil, err
+	}
+	return impl.toPullRequestDto(mr), nil
+}
+
+func (impl GitLabClient) GetPullRequest(repoName string, number int) (*PullRequestDto, error) {
+	mr, _, err := impl.client.MergeRequests.GetMergeRequest(fmt.Sprintf("%s/%s", impl.config.GitlabGroupPath, repoName), number, nil)
+	if err != nil {
+		impl.logger.Errorw("error in getting merge request gitlab", "repo", repoName, "number", number, "err", err)
+		return nil, err
+	}
+	return impl.toPullRequestDto(mr), nil
+}
+
+func (impl GitLabClient) ClosePullRequest(repoName string, number int) error {
+	stateEvent := "close"
+	_, _, err := impl.client.MergeRequests.UpdateMergeRequest(fmt.Sprintf("%s/%s", impl.config.GitlabGroupPath, repoName), number, &gitlab.UpdateMergeRequestOptions{
+		StateEvent: &stateEvent,
+	})
+	if err != nil {
+		impl.logger.Errorw("error in closing merge request gitlab", "repo", repoName, "number", number, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl GitLabClient) toPullRequestDto(mr *gitlab.MergeRequest) *PullRequestDto {
+	state := PULL_REQUEST_STATE_OPEN
+	if mr.State == "merged" {
+		state = PULL_REQUEST_STATE_MERGED
+	} else if mr.State == "closed" {
+		state = PULL_REQUEST_STATE_CLOSED
+	}
+	mergeCommitHash := mr.MergeCommitSHA
+	if len(mergeCommitHash) == 0 {
+		mergeCommitHash = mr.SquashCommitSHA
+	}
+	if len(mergeCommitHash) == 0 {
+		//fast-forward merges do not create a merge commit
+		mergeCommitHash = mr.SHA
+	}
+	return &PullRequestDto{
+		Number:          mr.IID,
+		Url:             mr.WebURL,
+		State:           state,
+		MergeCommitHash: mergeCommitHash,
+	}
+}
+
+// commitValuesOnBranch commits on branch, startBranch is used for creating the branch when it does not exist
+func (impl GitLabClient) commitValuesOnBranch(config *ChartConfig, branch, startBranch string) (commitHash string, commitTime time.Time, err error) {
 	path := filepath.Join(config.ChartLocation, config.FileName)
-	exists, err := impl.checkIfFileExists(config.ChartRepoName, branch, path)
+	fileRef := branch
+	if len(startBranch) > 0 {
+		fileRef = startBranch
+	}
+	exists, err := impl.checkIfFileExists(config.ChartRepoName, fileRef, path)
 	var fileAction gitlab.FileActionValue
 	if exists {
 		fileAction = gitlab.FileUpdate
@@ -259,6 +335,9 @@ func (impl GitLabClient) CommitValues(config *ChartConfig) (commitHash string, c
 		AuthorEmail:   &config.UserEmailId,
 		AuthorName:    &config.UserName,
 	}
+	if len(startBranch) > 0 {
+		actions.StartBranch = &startBranch
+	}
 	c, _, err := impl.client.Commits.CreateCommit(fmt.Sprintf("%s/%s", impl.config.GitlabGroupPath, config.ChartRepoName), actions)
 	if err != nil {
 		return "", time.Time{}, err
@@ -0,0 +1,131 @@
+package apiToken
+
+import (
+	"fmt"
+	client "github.com/devtron-labs/devtron/client/events"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/pkg/notifier"
+	"github.com/devtron-labs/devtron/pkg/user"
+	util "github.com/devtron-labs/devtron/util/event"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const apiTokenSystemUserId = int32(1)
+
+type ApiTokenNotificationService interface {
+	// NotifyExpiringApiTokens notifies the owners of api tokens expiring within the configured days, once per expiry
+	NotifyExpiringApiTokens()
+}
+
+type ApiTokenNotificationServiceImpl struct {
+	logger                     *zap.SugaredLogger
+	cfg                        *ApiTokenConfig
+	apiTokenRepository         ApiTokenRepository
+	userService                user.UserService
+	sesNotificationRepository  repository.SESNotificationRepository
+	smtpNotificationRepository repository.SMTPNotificationRepository
+	slackRepository            repository.SlackNotificationRepository
+	eventClient                client.EventClient
+	eventFactory               client.EventFactory
+}
+
+func NewApiTokenNotificationServiceImpl(logger *zap.SugaredLogger, cfg *ApiTokenConfig, apiTokenRepository ApiTokenRepository,
+	userService user.UserService, sesNotificationRepository repository.SESNotificationRepository,
+	smtpNotificationRepository repository.SMTPNotificationRepository, slackRepository repository.SlackNotificationRepository,
+	eventClient client.EventClient, eventFactory client.EventFactory) *ApiTokenNotificationServiceImpl {
+	return &ApiTokenNotificationServiceImpl{
+		logger:                     logger,
+		cfg:                        cfg,
+		apiTokenRepository:         apiTokenRepository,
+		userService:                userService,
+		sesNotificationRepository:  sesNotificationRepository,
+		smtpNotificationRepository: smtpNotificationRepository,
+		slackRepository:            slackRepository,
+		eventClient:                eventClient,
+		eventFactory:               eventFactory,
+	}
+}
+
+func (impl *ApiTokenNotificationServiceImpl) NotifyExpiringApiTokens() {
+	now := time.Now()
+	apiTokens, err := impl.apiTokenRepository.FindAllActiveExpiringBetween(now.UnixMilli(), now.AddDate(0, 0, impl.cfg.ExpiryNotifyDays).UnixMilli())
+	if err != nil {
+		impl.logger.Errorw("error in fetching expiring api tokens", "err", err)
+		return
+	}
+	for _, apiToken := range apiTokens {
+		if apiToken.User == nil || !apiToken.User.Active {
+			continue
+		}
+		err = impl.sendExpiryNotification(apiToken)
+		if err != nil {
+			// left un-notified so that the next run retries it
+			impl.logger.Errorw("error in sending api token expiry notification", "err", err, "apiTokenId", apiToken.Id)
+			continue
+		}
+		_, err = impl.apiTokenRepository.MarkExpiryNotified(apiToken.Id, apiTokenSystemUserId)
+		if err != nil {
+			impl.logger.Errorw("error in marking api token expiry notified", "err", err, "apiTokenId", apiToken.Id)
+		}
+	}
+}
+
+// sendExpiryNotification sends the event to the owner of the token, by email through the default ses or smtp config
+// and on the slack channels configured by the owner, it errors when the event could not be sent to any channel
+func (impl *ApiTokenNotificationServiceImpl) sendExpiryNotification(apiToken *ApiToken) error {
+	ownerId := apiToken.CreatedBy
+	providers := impl.getSlackProviders(ownerId)
+	owner, err := impl.userService.GetById(ownerId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching owner of api token", "err", err, "apiTokenId", apiToken.Id, "ownerId", ownerId)
+	} else if strings.Contains(owner.EmailId, "@") {
+		if emailProvider := impl.getEmailProvider(owner.EmailId); emailProvider != nil {
+			providers = append(providers, emailProvider)
+		}
+	}
+	if len(providers) == 0 {
+		return fmt.Errorf("no notification channel found for owner %d", ownerId)
+	}
+	event := impl.eventFactory.Build(util.ApiTokenExpiring, nil, 0, nil, "")
+	event.UserId = int(apiTokenSystemUserId)
+	event.Payload = &client.Payload{
+		ApiTokenName: apiToken.Name,
+		ExpiresOn:    time.UnixMilli(apiToken.ExpireAtInMs).Format(time.RFC1123),
+		Providers:    providers,
+	}
+	_, err = impl.eventClient.WriteNotificationEvent(event)
+	return err
+}
+
+func (impl *ApiTokenNotificationServiceImpl) getSlackProviders(ownerId int32) []*notifier.Provider {
+	var providers []*notifier.Provider
+	slackConfigs, err := impl.slackRepository.FindByTeamIdOrOwnerId(ownerId, nil)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching slack configs of api token owner", "err", err, "ownerId", ownerId)
+		return providers
+	}
+	for _, slackConfig := range slackConfigs {
+		providers = append(providers, &notifier.Provider{Destination: util.Slack, ConfigId: slackConfig.Id})
+	}
+	return providers
+}
+
+// getEmailProvider prefers the default ses config over the default smtp config
+func (impl *ApiTokenNotificationServiceImpl) getEmailProvider(emailId string) *notifier.Provider {
+	sesConfig, err := impl.sesNotificationRepository.FindDefault()
+	if err == nil && sesConfig.Id > 0 {
+		return &notifier.Provider{Destination: util.SES, ConfigId: sesConfig.Id, Recipient: emailId}
+	} else if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching default ses config", "err", err)
+	}
+	smtpConfig, err := impl.smtpNotificationRepository.FindDefault()
+	if err == nil && smtpConfig.Id > 0 {
+		return &notifier.Provider{Destination: util.SMTP, ConfigId: smtpConfig.Id, Recipient: emailId}
+	} else if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching default smtp config", "err", err)
+	}
+	return nil
+}
@@ -18,21 +18,30 @@
 package apiToken
 
 import (
+	"github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user/repository"
 	"github.com/go-pg/pg"
 	"github.com/go-pg/pg/orm"
+	"time"
 )
 
 type ApiToken struct {
-	tableName    struct{} `sql:"api_token"`
-	Id           int      `sql:"id,pk"`
-	UserId       int32    `sql:"user_id, notnull"`
-	Name         string   `sql:"name, notnull"`
-	Description  string   `sql:"description, notnull"`
-	ExpireAtInMs int64    `sql:"expire_at_in_ms"`
-	Token        string   `sql:"token, notnull"`
-	User         *repository.UserModel
+	tableName    struct{}          `sql:"api_token"`
+	Id           int               `sql:"id,pk"`
+	UserId       int32             `sql:"user_id, notnull"`
+	Name         string            `sql:"name, notnull"`
+	Description  string            `sql:"description, notnull"`
+	ExpireAtInMs int64             `sql:"expire_at_in_ms"`
+	Token        string            `sql:"token, notnull"`
+	RoleFilters  []bean.RoleFilter `sql:"role_filters"`
+	ScopedRoles  []string          `sql:"scoped_roles" pg:",array"`
+	AllowedIps   []string          `sql:"allowed_ips" pg:",array"`
+	// PreviousToken stays valid till PreviousTokenExpireAt after the token is rotated
+	PreviousToken         string    `sql:"previous_token"`
+	PreviousTokenExpireAt time.Time `sql:"previous_token_expire_at"`
+	ExpiryNotified        bool      `sql:"expiry_notified,notnull"`
+	User                  *repository.UserModel
 	sql.AuditLog
 }
 
@@ -42,6 +51,11 @@ type ApiTokenRepository interface {
 	FindAllActive() ([]*ApiToken, error)
 	FindActiveById(id int) (*ApiToken, error)
 	FindByName(name string) (*ApiToken, error)
+	FindAllActiveExpiringBetween(fromInMs int64, toInMs int64) ([]*ApiToken, error)
+	FindActiveByToken(token string) (*ApiToken, error)
+	// MarkExpiryNotified sets only the expiry notified flag of the token, it returns false when the token was
+	// already marked
+	MarkExpiryNotified(id int, updatedBy int32) (bool, error)
 }
 
 type ApiTokenRepositoryImpl struct {
@@ -91,3 +105,45 @@ func (impl ApiTokenRepositoryImpl) FindByName(name string) (*ApiToken, error) {
 		Select()
 	return apiToken, err
 }
+
+// FindAllActiveExpiringBetween returns active tokens, not notified of expiry yet, which expire in the given window
+func (impl ApiTokenRepositoryImpl) FindAllActiveExpiringBetween(fromInMs int64, toInMs int64) ([]*ApiToken, error) {
+	var apiTokens []*ApiToken
+	err := impl.dbConnection.Model(&apiTokens).
+		Column("api_token.*", "User").
+		Relation("User", func(q *orm.Query) (query *orm.Query, err error) {
+			return q.Where("active IS TRUE"), nil
+		}).
+		Where("api_token.expire_at_in_ms > ?", fromInMs).
+		Where("api_token.expire_at_in_ms <= ?", toInMs).
+		Where("api_token.expiry_notified = ?", false).
+		Select()
+	return apiTokens, err
+}
+
+// FindActiveByToken returns the api token whose current or previous token is the given token, along with its user
+func (impl ApiTokenRepositoryImpl) FindActiveByToken(token string) (*ApiToken, error) {
+	apiToken := &ApiToken{}
+	err := impl.dbConnection.Model(apiToken).
+		Column("api_token.*", "User").
+		Relation("User", func(q *orm.Query) (query *orm.Query, err error) {
+			return q.Where("active IS TRUE"), nil
+		}).
+		Where("api_token.token = ? OR api_token.previous_token = ?", token, token).
+		Select()
+	return apiToken, err
+}
+
+func (impl ApiTokenRepositoryImpl) MarkExpiryNotified(id int, updatedBy int32) (bool, error) {
+	res, err := impl.dbConnection.Model((*ApiToken)(nil)).
+		Set("expiry_notified = ?", true).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", updatedBy).
+		Where("id = ?", id).
+		Where("expiry_notified = ?", false).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
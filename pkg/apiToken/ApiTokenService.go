@@ -20,14 +20,18 @@ package apiToken
 import (
 	"errors"
 	"fmt"
+	"github.com/caarlos0/env"
 	"github.com/devtron-labs/authenticator/middleware"
 	"github.com/devtron-labs/devtron/api/bean"
 	openapi "github.com/devtron-labs/devtron/api/openapi/openapiClient"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/go-pg/pg"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/patrickmn/go-cache"
 	"go.uber.org/zap"
+	"net"
 	"regexp"
 	"strconv"
 	"time"
@@ -35,27 +39,53 @@ import (
 
 type ApiTokenService interface {
 	GetAllActiveApiTokens() ([]*openapi.ApiToken, error)
-	CreateApiToken(request *openapi.CreateApiTokenRequest, createdBy int32, managerAuth func(token string, object string) bool) (*openapi.CreateApiTokenResponse, error)
-	UpdateApiToken(apiTokenId int, request *openapi.UpdateApiTokenRequest, updatedBy int32) (*openapi.UpdateApiTokenResponse, error)
+	CreateApiToken(request *openapi.CreateApiTokenRequest, createdBy int32, token string, managerAuth func(token string, object string) bool) (*openapi.CreateApiTokenResponse, error)
+	UpdateApiToken(apiTokenId int, request *openapi.UpdateApiTokenRequest, updatedBy int32, token string, managerAuth func(token string, object string) bool) (*openapi.UpdateApiTokenResponse, error)
 	DeleteApiToken(apiTokenId int, deletedBy int32) (*openapi.ActionResponse, error)
+	// RotateApiToken issues a new token, the previous token stays valid for the grace period so that consumers can move over
+	RotateApiToken(apiTokenId int, request *openapi.RotateApiTokenRequest, updatedBy int32) (*openapi.RotateApiTokenResponse, error)
+	// IsActiveApiToken checks that the token is the current token of an active api token or its previous token within
+	// the grace period, lookups are cached for ActiveTokenCacheSecs
+	IsActiveApiToken(token string) (bool, error)
+}
+
+type ApiTokenConfig struct {
+	RotationGracePeriodMins    int `env:"API_TOKEN_ROTATION_GRACE_PERIOD_MINS" envDefault:"1440"`
+	RotationMaxGracePeriodMins int `env:"API_TOKEN_ROTATION_MAX_GRACE_PERIOD_MINS" envDefault:"10080"`
+	ExpiryNotifyDays           int `env:"API_TOKEN_EXPIRY_NOTIFY_DAYS" envDefault:"7"`
+	// ActiveTokenCacheSecs bounds how long a token rotated or deleted on another instance keeps being accepted
+	ActiveTokenCacheSecs int `env:"API_TOKEN_ACTIVE_CACHE_SECS" envDefault:"60"`
+}
+
+func GetApiTokenConfig() (*ApiTokenConfig, error) {
+	cfg := &ApiTokenConfig{}
+	err := env.Parse(cfg)
+	return cfg, err
 }
 
 type ApiTokenServiceImpl struct {
 	logger                *zap.SugaredLogger
+	cfg                   *ApiTokenConfig
 	apiTokenSecretService ApiTokenSecretService
 	userService           user.UserService
 	userAuditService      user.UserAuditService
 	apiTokenRepository    ApiTokenRepository
+	auditLogService       auditLog.AuditLogService
+	// activeTokenCache holds the api token looked up for a token, an empty api token when none is found
+	activeTokenCache *cache.Cache
 }
 
 func NewApiTokenServiceImpl(logger *zap.SugaredLogger, apiTokenSecretService ApiTokenSecretService, userService user.UserService, userAuditService user.UserAuditService,
-	apiTokenRepository ApiTokenRepository) *ApiTokenServiceImpl {
+	apiTokenRepository ApiTokenRepository, auditLogService auditLog.AuditLogService, cfg *ApiTokenConfig) *ApiTokenServiceImpl {
 	return &ApiTokenServiceImpl{
 		logger:                logger,
+		cfg:                   cfg,
 		apiTokenSecretService: apiTokenSecretService,
 		userService:           userService,
 		userAuditService:      userAuditService,
 		apiTokenRepository:    apiTokenRepository,
+		auditLogService:       auditLogService,
+		activeTokenCache:      cache.New(time.Duration(cfg.ActiveTokenCacheSecs)*time.Second, 5*time.Minute),
 	}
 }
 
@@ -64,7 +94,9 @@ const API_TOKEN_USER_EMAIL_PREFIX = "API-TOKEN:"
 var invalidCharsInApiTokenName = regexp.MustCompile("[,\\s]")
 
 type ApiTokenCustomClaims struct {
-	Email string `json:"email"`
+	Email       string   `json:"email"`
+	ScopedRoles []string `json:"scopedRoles,omitempty"`
+	AllowedIps  []string `json:"allowedIps,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -97,6 +129,16 @@ func (impl ApiTokenServiceImpl) GetAllActiveApiTokens() ([]*openapi.ApiToken, er
 			Token:          &apiTokenFromDb.Token,
 			UpdatedAt:      &updatedAtStr,
 		}
+		if len(apiTokenFromDb.RoleFilters) > 0 {
+			roleFilters := adaptToApiTokenRoleFilters(apiTokenFromDb.RoleFilters)
+			apiToken.RoleFilters = &roleFilters
+		}
+		if len(apiTokenFromDb.ScopedRoles) > 0 {
+			apiToken.ScopedRoles = &apiTokenFromDb.ScopedRoles
+		}
+		if len(apiTokenFromDb.AllowedIps) > 0 {
+			apiToken.AllowedIps = &apiTokenFromDb.AllowedIps
+		}
 		if latestAuditLog != nil {
 			lastUsedAtStr := latestAuditLog.CreatedOn.String()
 			apiToken.LastUsedAt = &lastUsedAtStr
@@ -108,7 +150,7 @@ func (impl ApiTokenServiceImpl) GetAllActiveApiTokens() ([]*openapi.ApiToken, er
 	return apiTokens, nil
 }
 
-func (impl ApiTokenServiceImpl) CreateApiToken(request *openapi.CreateApiTokenRequest, createdBy int32, managerAuth func(token string, object string) bool) (*openapi.CreateApiTokenResponse, error) {
+func (impl ApiTokenServiceImpl) CreateApiToken(request *openapi.CreateApiTokenRequest, createdBy int32, token string, managerAuth func(token string, object string) bool) (*openapi.CreateApiTokenResponse, error) {
 	impl.logger.Infow("Creating API token", "request", request, "createdBy", createdBy)
 
 	name := request.GetName()
@@ -116,6 +158,12 @@ func (impl ApiTokenServiceImpl) CreateApiToken(request *openapi.CreateApiTokenRe
 	if invalidCharsInApiTokenName.MatchString(name) {
 		return nil, errors.New(fmt.Sprintf("name '%s' contains either white-space or comma, which is not allowed", name))
 	}
+	allowedIps := request.GetAllowedIps()
+	err := validateAllowedIps(allowedIps)
+	if err != nil {
+		return nil, err
+	}
+	roleFilters := adaptFromApiTokenRoleFilters(request.GetRoleFilters())
 
 	// step-1 - check if the name exists, if exists with active user - throw error
 	apiToken, err := impl.apiTokenRepository.FindByName(name)
@@ -136,17 +184,12 @@ func (impl ApiTokenServiceImpl) CreateApiToken(request *openapi.CreateApiTokenRe
 	// step-2 - Build email
 	email := fmt.Sprintf("%s%s", API_TOKEN_USER_EMAIL_PREFIX, name)
 
-	// step-3 - Build token
-	token, err := impl.createApiJwtToken(email, *request.ExpireAtInMs)
-	if err != nil {
-		return nil, err
-	}
-
-	// step-4 - Create user using email
+	// step-3 - Create user using email, role filters of a scoped token are assigned to the user directly
 	createUserRequest := bean.UserInfo{
-		UserId:   createdBy,
-		EmailId:  email,
-		UserType: bean.USER_TYPE_API_TOKEN,
+		UserId:      createdBy,
+		EmailId:     email,
+		UserType:    bean.USER_TYPE_API_TOKEN,
+		RoleFilters: roleFilters,
 	}
 	createUserResponse, err := impl.userService.CreateUser(&createUserRequest, token, managerAuth)
 	if err != nil {
@@ -159,13 +202,33 @@ func (impl ApiTokenServiceImpl) CreateApiToken(request *openapi.CreateApiTokenRe
 	}
 	userId := createUserResponse[0].Id
 
+	// step-4 - Build token, a scoped token carries the roles of its role filters so that it is never allowed more
+	var scopedRoles []string
+	if len(roleFilters) > 0 {
+		scopedRoles, err = impl.userService.CheckUserRoles(userId)
+		if err != nil {
+			impl.logger.Errorw("error while getting roles of api-token user", "userId", userId, "error", err)
+			return nil, err
+		}
+		if len(scopedRoles) == 0 {
+			return nil, errors.New("no roles found for the role filters of api-token")
+		}
+	}
+	apiJwtToken, err := impl.createApiJwtToken(email, *request.ExpireAtInMs, scopedRoles, allowedIps)
+	if err != nil {
+		return nil, err
+	}
+
 	// step-5 - Save API token (update or save)
 	apiTokenSaveRequest := &ApiToken{
 		UserId:       userId,
 		Name:         name,
 		Description:  *request.Description,
 		ExpireAtInMs: *request.ExpireAtInMs,
-		Token:        token,
+		Token:        apiJwtToken,
+		RoleFilters:  roleFilters,
+		ScopedRoles:  scopedRoles,
+		AllowedIps:   allowedIps,
 		AuditLog:     sql.AuditLog{UpdatedOn: time.Now()},
 	}
 	if apiTokenExists {
@@ -183,17 +246,18 @@ func (impl ApiTokenServiceImpl) CreateApiToken(request *openapi.CreateApiTokenRe
 		impl.logger.Errorw("error while saving api-token into DB", "error", err)
 		return nil, err
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_API_TOKEN, strconv.Itoa(apiTokenSaveRequest.Id), auditLog.AUDIT_ACTION_CREATE, nil, apiTokenSaveRequest, createdBy)
 
 	success := true
 	return &openapi.CreateApiTokenResponse{
 		Success:        &success,
-		Token:          &token,
+		Token:          &apiJwtToken,
 		UserId:         &userId,
 		UserIdentifier: &email,
 	}, nil
 }
 
-func (impl ApiTokenServiceImpl) UpdateApiToken(apiTokenId int, request *openapi.UpdateApiTokenRequest, updatedBy int32) (*openapi.UpdateApiTokenResponse, error) {
+func (impl ApiTokenServiceImpl) UpdateApiToken(apiTokenId int, request *openapi.UpdateApiTokenRequest, updatedBy int32, token string, managerAuth func(token string, object string) bool) (*openapi.UpdateApiTokenResponse, error) {
 	impl.logger.Infow("Updating API token", "request", request, "updatedBy", updatedBy, "apiTokenId", apiTokenId)
 
 	// step-1 - check if the api-token exists, if not exists - throw error
@@ -206,19 +270,68 @@ func (impl ApiTokenServiceImpl) UpdateApiToken(apiTokenId int, request *openapi.
 		return nil, errors.New(fmt.Sprintf("api-token corresponds to apiTokenId '%d' is not found", apiTokenId))
 	}
 
-	// step-2 - If expires_at is not same, then token needs to be generated again
-	if *request.ExpireAtInMs != apiToken.ExpireAtInMs {
+	before := *apiToken
+
+	allowedIps := apiToken.AllowedIps
+	if request.AllowedIps != nil {
+		allowedIps = request.GetAllowedIps()
+		err = validateAllowedIps(allowedIps)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// step-2 - re-assign the roles of the api-token user if role filters are given, the token is not scoped if empty
+	roleFilters := apiToken.RoleFilters
+	scopedRoles := apiToken.ScopedRoles
+	scopeChanged := false
+	if request.RoleFilters != nil {
+		roleFilters = adaptFromApiTokenRoleFilters(request.GetRoleFilters())
+		updateUserRequest := &bean.UserInfo{
+			Id:          apiToken.UserId,
+			UserId:      updatedBy,
+			EmailId:     apiToken.User.EmailId,
+			UserType:    bean.USER_TYPE_API_TOKEN,
+			RoleFilters: roleFilters,
+		}
+		_, _, _, _, err = impl.userService.UpdateUser(updateUserRequest, token, managerAuth)
+		if err != nil {
+			impl.logger.Errorw("error while updating roles of api-token user", "userId", apiToken.UserId, "error", err)
+			return nil, err
+		}
+		scopedRoles = nil
+		if len(roleFilters) > 0 {
+			scopedRoles, err = impl.userService.CheckUserRoles(apiToken.UserId)
+			if err != nil {
+				impl.logger.Errorw("error while getting roles of api-token user", "userId", apiToken.UserId, "error", err)
+				return nil, err
+			}
+			if len(scopedRoles) == 0 {
+				return nil, errors.New("no roles found for the role filters of api-token")
+			}
+		}
+		scopeChanged = !isSameStrings(scopedRoles, apiToken.ScopedRoles)
+	}
+
+	// step-3 - If expires_at, scope or allowed ips are not same, then token needs to be generated again
+	if *request.ExpireAtInMs != apiToken.ExpireAtInMs || scopeChanged || !isSameStrings(allowedIps, apiToken.AllowedIps) {
 		// regenerate token
-		token, err := impl.createApiJwtToken(apiToken.User.EmailId, *request.ExpireAtInMs)
+		apiJwtToken, err := impl.createApiJwtToken(apiToken.User.EmailId, *request.ExpireAtInMs, scopedRoles, allowedIps)
 		if err != nil {
 			return nil, err
 		}
-		apiToken.Token = token
+		apiToken.Token = apiJwtToken
 	}
 
-	// step-3 - update in DB
+	// step-4 - update in DB
 	apiToken.Description = *request.Description
+	if *request.ExpireAtInMs != apiToken.ExpireAtInMs {
+		apiToken.ExpiryNotified = false
+	}
 	apiToken.ExpireAtInMs = *request.ExpireAtInMs
+	apiToken.RoleFilters = roleFilters
+	apiToken.ScopedRoles = scopedRoles
+	apiToken.AllowedIps = allowedIps
 	apiToken.UpdatedBy = updatedBy
 	apiToken.UpdatedOn = time.Now()
 	err = impl.apiTokenRepository.Update(apiToken)
@@ -226,6 +339,7 @@ func (impl ApiTokenServiceImpl) UpdateApiToken(apiTokenId int, request *openapi.
 		impl.logger.Errorw("error while updating api-token", "apiTokenId", apiTokenId, "error", err)
 		return nil, err
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_API_TOKEN, strconv.Itoa(apiTokenId), auditLog.AUDIT_ACTION_UPDATE, before, apiToken, updatedBy)
 
 	success := true
 	return &openapi.UpdateApiTokenResponse{
@@ -260,6 +374,8 @@ func (impl ApiTokenServiceImpl) DeleteApiToken(apiTokenId int, deletedBy int32)
 	if !success {
 		return nil, errors.New(fmt.Sprintf("Couldn't in-activate user corresponds to apiTokenId '%d'", apiTokenId))
 	}
+	impl.evictActiveTokens(apiToken)
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_API_TOKEN, strconv.Itoa(apiTokenId), auditLog.AUDIT_ACTION_DELETE, apiToken, nil, deletedBy)
 
 	return &openapi.ActionResponse{
 		Success: &success,
@@ -267,7 +383,110 @@ func (impl ApiTokenServiceImpl) DeleteApiToken(apiTokenId int, deletedBy int32)
 
 }
 
-func (impl ApiTokenServiceImpl) createApiJwtToken(email string, expireAtInMs int64) (string, error) {
+func (impl ApiTokenServiceImpl) RotateApiToken(apiTokenId int, request *openapi.RotateApiTokenRequest, updatedBy int32) (*openapi.RotateApiTokenResponse, error) {
+	impl.logger.Infow("Rotating API token", "request", request, "updatedBy", updatedBy, "apiTokenId", apiTokenId)
+
+	// step-1 - check if the api-token exists, if not exists - throw error
+	apiToken, err := impl.apiTokenRepository.FindActiveById(apiTokenId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error while getting api token by id", "apiTokenId", apiTokenId, "error", err)
+		return nil, err
+	}
+	if apiToken == nil || apiToken.Id == 0 {
+		return nil, errors.New(fmt.Sprintf("api-token corresponds to apiTokenId '%d' is not found", apiTokenId))
+	}
+
+	gracePeriodInMins := impl.cfg.RotationGracePeriodMins
+	if request.GracePeriodInMins != nil {
+		gracePeriodInMins = int(request.GetGracePeriodInMins())
+	}
+	if gracePeriodInMins < 0 || gracePeriodInMins > impl.cfg.RotationMaxGracePeriodMins {
+		return nil, errors.New(fmt.Sprintf("grace period should be between 0 and %d minutes", impl.cfg.RotationMaxGracePeriodMins))
+	}
+	expireAtInMs := apiToken.ExpireAtInMs
+	if request.ExpireAtInMs != nil {
+		expireAtInMs = request.GetExpireAtInMs()
+	}
+	if expireAtInMs > 0 && expireAtInMs <= time.Now().UnixMilli() {
+		return nil, errors.New("expiration time of the rotated token should be in future")
+	}
+
+	before := *apiToken
+
+	// step-2 - issue a new token with the same scope, the current one is kept as previous token for the grace period
+	token, err := impl.createApiJwtToken(apiToken.User.EmailId, expireAtInMs, apiToken.ScopedRoles, apiToken.AllowedIps)
+	if err != nil {
+		return nil, err
+	}
+	previousTokenExpireAt := time.Now().Add(time.Duration(gracePeriodInMins) * time.Minute)
+	// the previous token can not outlive its own expiry
+	if apiToken.ExpireAtInMs > 0 && previousTokenExpireAt.UnixMilli() > apiToken.ExpireAtInMs {
+		previousTokenExpireAt = time.UnixMilli(apiToken.ExpireAtInMs)
+	}
+	apiToken.PreviousToken = apiToken.Token
+	apiToken.PreviousTokenExpireAt = previousTokenExpireAt
+	apiToken.Token = token
+	if expireAtInMs != apiToken.ExpireAtInMs {
+		apiToken.ExpiryNotified = false
+	}
+	apiToken.ExpireAtInMs = expireAtInMs
+	apiToken.UpdatedBy = updatedBy
+	apiToken.UpdatedOn = time.Now()
+
+	// step-3 - update in DB
+	err = impl.apiTokenRepository.Update(apiToken)
+	if err != nil {
+		impl.logger.Errorw("error while rotating api-token", "apiTokenId", apiTokenId, "error", err)
+		return nil, err
+	}
+	impl.evictActiveTokens(&before)
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_API_TOKEN, strconv.Itoa(apiTokenId), auditLog.AUDIT_ACTION_UPDATE, before, apiToken, updatedBy)
+
+	success := true
+	previousTokenExpireAtInMs := previousTokenExpireAt.UnixMilli()
+	return &openapi.RotateApiTokenResponse{
+		Success:                   &success,
+		Token:                     &apiToken.Token,
+		PreviousTokenExpireAtInMs: &previousTokenExpireAtInMs,
+	}, nil
+}
+
+func (impl ApiTokenServiceImpl) IsActiveApiToken(token string) (bool, error) {
+	if cached, found := impl.activeTokenCache.Get(token); found {
+		return isActiveApiToken(cached.(*ApiToken), token, time.Now()), nil
+	}
+	apiToken, err := impl.apiTokenRepository.FindActiveByToken(token)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error while getting api token by token", "error", err)
+		return false, err
+	}
+	if err == pg.ErrNoRows {
+		apiToken = &ApiToken{}
+	}
+	impl.activeTokenCache.SetDefault(token, apiToken)
+	return isActiveApiToken(apiToken, token, time.Now()), nil
+}
+
+// evictActiveTokens drops the cached lookups of the tokens of an api token which is rotated or deleted
+func (impl ApiTokenServiceImpl) evictActiveTokens(apiToken *ApiToken) {
+	impl.activeTokenCache.Delete(apiToken.Token)
+	if len(apiToken.PreviousToken) > 0 {
+		impl.activeTokenCache.Delete(apiToken.PreviousToken)
+	}
+}
+
+// isActiveApiToken is evaluated on every lookup, so that a cached previous token is rejected once its grace period is over
+func isActiveApiToken(apiToken *ApiToken, token string, now time.Time) bool {
+	if apiToken == nil || apiToken.Id == 0 || apiToken.User == nil || !apiToken.User.Active {
+		return false
+	}
+	if apiToken.Token == token {
+		return true
+	}
+	return apiToken.PreviousToken == token && now.Before(apiToken.PreviousTokenExpireAt)
+}
+
+func (impl ApiTokenServiceImpl) createApiJwtToken(email string, expireAtInMs int64, scopedRoles []string, allowedIps []string) (string, error) {
 	secretByteArr, err := impl.apiTokenSecretService.GetApiTokenSecretByteArr()
 	if err != nil {
 		impl.logger.Errorw("error while getting api token secret", "error", err)
@@ -283,6 +502,8 @@ func (impl ApiTokenServiceImpl) createApiJwtToken(email string, expireAtInMs int
 
 	claims := &ApiTokenCustomClaims{
 		email,
+		scopedRoles,
+		allowedIps,
 		registeredClaims,
 	}
 	unsignedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -293,3 +514,58 @@ func (impl ApiTokenServiceImpl) createApiJwtToken(email string, expireAtInMs int
 	}
 	return token, nil
 }
+
+// validateAllowedIps checks that every entry of an ip allow-list is either an ip or a cidr
+func validateAllowedIps(allowedIps []string) error {
+	for _, allowedIp := range allowedIps {
+		if net.ParseIP(allowedIp) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(allowedIp); err != nil {
+			return errors.New(fmt.Sprintf("'%s' is neither a valid ip nor a valid cidr", allowedIp))
+		}
+	}
+	return nil
+}
+
+func isSameStrings(values []string, otherValues []string) bool {
+	if len(values) != len(otherValues) {
+		return false
+	}
+	for i := range values {
+		if values[i] != otherValues[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func adaptFromApiTokenRoleFilters(apiTokenRoleFilters []openapi.ApiTokenRoleFilter) []bean.RoleFilter {
+	var roleFilters []bean.RoleFilter
+	for _, apiTokenRoleFilter := range apiTokenRoleFilters {
+		roleFilters = append(roleFilters, bean.RoleFilter{
+			Entity:      apiTokenRoleFilter.GetEntity(),
+			Team:        apiTokenRoleFilter.GetTeam(),
+			EntityName:  apiTokenRoleFilter.GetEntityName(),
+			Environment: apiTokenRoleFilter.GetEnvironment(),
+			Action:      apiTokenRoleFilter.GetAction(),
+			AccessType:  apiTokenRoleFilter.GetAccessType(),
+		})
+	}
+	return roleFilters
+}
+
+func adaptToApiTokenRoleFilters(roleFilters []bean.RoleFilter) []openapi.ApiTokenRoleFilter {
+	var apiTokenRoleFilters []openapi.ApiTokenRoleFilter
+	for _, roleFilter := range roleFilters {
+		apiTokenRoleFilter := openapi.ApiTokenRoleFilter{}
+		apiTokenRoleFilter.SetEntity(roleFilter.Entity)
+		apiTokenRoleFilter.SetTeam(roleFilter.Team)
+		apiTokenRoleFilter.SetEntityName(roleFilter.EntityName)
+		apiTokenRoleFilter.SetEnvironment(roleFilter.Environment)
+		apiTokenRoleFilter.SetAction(roleFilter.Action)
+		apiTokenRoleFilter.SetAccessType(roleFilter.AccessType)
+		apiTokenRoleFilters = append(apiTokenRoleFilters, apiTokenRoleFilter)
+	}
+	return apiTokenRoleFilters
+}
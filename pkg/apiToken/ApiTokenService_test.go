@@ -0,0 +1,87 @@
+package apiToken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type fakeApiTokenRepository struct {
+	ApiTokenRepository
+	apiTokens []*ApiToken
+	lookups   int
+}
+
+func (impl *fakeApiTokenRepository) FindActiveByToken(token string) (*ApiToken, error) {
+	impl.lookups++
+	for _, apiToken := range impl.apiTokens {
+		if apiToken.Token == token || apiToken.PreviousToken == token {
+			return apiToken, nil
+		}
+	}
+	return &ApiToken{}, pg.ErrNoRows
+}
+
+func TestIsActiveApiToken(t *testing.T) {
+	now := time.Now()
+	activeUser := &repository.UserModel{Id: 2, Active: true}
+	rotated := &ApiToken{Id: 1, Token: "current", PreviousToken: "previous", PreviousTokenExpireAt: now.Add(time.Hour), User: activeUser}
+	tests := []struct {
+		name     string
+		apiToken *ApiToken
+		token    string
+		now      time.Time
+		want     bool
+	}{
+		{name: "current token", apiToken: rotated, token: "current", now: now, want: true},
+		{name: "previous token within grace period", apiToken: rotated, token: "previous", now: now, want: true},
+		{name: "previous token after grace period", apiToken: rotated, token: "previous", now: now.Add(2 * time.Hour), want: false},
+		{name: "current token after grace period", apiToken: rotated, token: "current", now: now.Add(2 * time.Hour), want: true},
+		{name: "unknown token", apiToken: &ApiToken{}, token: "unknown", now: now, want: false},
+		{name: "inactive user", apiToken: &ApiToken{Id: 1, Token: "current", User: &repository.UserModel{Id: 2}}, token: "current", now: now, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isActiveApiToken(tt.apiToken, tt.token, tt.now); got != tt.want {
+				t.Errorf("isActiveApiToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsActiveApiTokenRejectsRotatedTokenAfterGracePeriod(t *testing.T) {
+	gracePeriod := 50 * time.Millisecond
+	apiTokenRepository := &fakeApiTokenRepository{apiTokens: []*ApiToken{{
+		Id:                    1,
+		Token:                 "current",
+		PreviousToken:         "previous",
+		PreviousTokenExpireAt: time.Now().Add(gracePeriod),
+		User:                  &repository.UserModel{Id: 2, Active: true},
+	}}}
+	impl := NewApiTokenServiceImpl(zap.NewNop().Sugar(), nil, nil, nil, apiTokenRepository, nil, &ApiTokenConfig{ActiveTokenCacheSecs: 60})
+
+	isActive, err := impl.IsActiveApiToken("previous")
+	if err != nil || !isActive {
+		t.Fatalf("previous token within grace period: isActive = %v, err = %v, want active", isActive, err)
+	}
+	time.Sleep(2 * gracePeriod)
+	isActive, err = impl.IsActiveApiToken("previous")
+	if err != nil || isActive {
+		t.Errorf("previous token after grace period: isActive = %v, err = %v, want inactive", isActive, err)
+	}
+	isActive, err = impl.IsActiveApiToken("current")
+	if err != nil || !isActive {
+		t.Errorf("current token: isActive = %v, err = %v, want active", isActive, err)
+	}
+	isActive, err = impl.IsActiveApiToken("deleted")
+	if err != nil || isActive {
+		t.Errorf("unknown token: isActive = %v, err = %v, want inactive", isActive, err)
+	}
+	// the lookup of the previous token is served from the cache after the first request
+	if apiTokenRepository.lookups != 3 {
+		t.Errorf("repository lookups = %d, want 3", apiTokenRepository.lookups)
+	}
+}
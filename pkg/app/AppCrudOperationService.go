@@ -22,10 +22,12 @@ import (
 	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
 	"github.com/devtron-labs/devtron/pkg/bean"
 	"github.com/devtron-labs/devtron/pkg/user/repository"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
+	"strconv"
 	"time"
 )
 
@@ -45,15 +47,18 @@ type AppCrudOperationServiceImpl struct {
 	appLabelRepository pipelineConfig.AppLabelRepository
 	appRepository      app.AppRepository
 	userRepository     repository.UserRepository
+	auditLogService    auditLog.AuditLogService
 }
 
 func NewAppCrudOperationServiceImpl(appLabelRepository pipelineConfig.AppLabelRepository,
-	logger *zap.SugaredLogger, appRepository app.AppRepository, userRepository repository.UserRepository) *AppCrudOperationServiceImpl {
+	logger *zap.SugaredLogger, appRepository app.AppRepository, userRepository repository.UserRepository,
+	auditLogService auditLog.AuditLogService) *AppCrudOperationServiceImpl {
 	return &AppCrudOperationServiceImpl{
 		appLabelRepository: appLabelRepository,
 		logger:             logger,
 		appRepository:      appRepository,
 		userRepository:     userRepository,
+		auditLogService:    auditLogService,
 	}
 }
 
@@ -66,6 +71,11 @@ func (impl AppCrudOperationServiceImpl) UpdateApp(request *bean.CreateAppDTO) (*
 	// Rollback tx on error.
 	defer tx.Rollback()
 
+	before, err := impl.GetAppMetaInfo(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching app meta info", "error", err)
+		return nil, err
+	}
 	app, err := impl.appRepository.FindById(request.Id)
 	if err != nil {
 		impl.logger.Errorw("error in fetching app", "error", err)
@@ -91,7 +101,12 @@ func (impl AppCrudOperationServiceImpl) UpdateApp(request *bean.CreateAppDTO) (*
 		impl.logger.Errorw("error in commit db transaction", "error", err)
 		return nil, err
 	}
-
+	after, err := impl.GetAppMetaInfo(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching app meta info for audit log", "error", err)
+	} else {
+		impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_APP, strconv.Itoa(request.Id), auditLog.AUDIT_ACTION_UPDATE, before, after, request.UserId)
+	}
 	return request, nil
 }
 
@@ -25,6 +25,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	repository3 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"github.com/devtron-labs/devtron/util/argo"
 	errors2 "github.com/juju/errors"
 	"net/http"
@@ -147,6 +148,7 @@ type AppListingServiceImpl struct {
 	argoUserService            argo.ArgoUserService
 	envOverrideRepository      chartConfig.EnvConfigOverrideRepository
 	chartRepository            chartRepoRepository.ChartRepository
+	pipelineStageRepository    repository3.PipelineStageRepository
 }
 
 func NewAppListingServiceImpl(Logger *zap.SugaredLogger, appListingRepository repository.AppListingRepository,
@@ -156,7 +158,7 @@ func NewAppListingServiceImpl(Logger *zap.SugaredLogger, appListingRepository re
 	envLevelMetricsRepository repository.EnvLevelAppMetricsRepository, cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
 	pipelineOverrideRepository chartConfig.PipelineOverrideRepository, environmentRepository repository2.EnvironmentRepository,
 	argoUserService argo.ArgoUserService, envOverrideRepository chartConfig.EnvConfigOverrideRepository,
-	chartRepository chartRepoRepository.ChartRepository) *AppListingServiceImpl {
+	chartRepository chartRepoRepository.ChartRepository, pipelineStageRepository repository3.PipelineStageRepository) *AppListingServiceImpl {
 	serviceImpl := &AppListingServiceImpl{
 		Logger:                     Logger,
 		appListingRepository:       appListingRepository,
@@ -173,6 +175,7 @@ func NewAppListingServiceImpl(Logger *zap.SugaredLogger, appListingRepository re
 		argoUserService:            argoUserService,
 		envOverrideRepository:      envOverrideRepository,
 		chartRepository:            chartRepository,
+		pipelineStageRepository:    pipelineStageRepository,
 	}
 	return serviceImpl
 }
@@ -266,6 +269,30 @@ func (impl AppListingServiceImpl) ISLastReleaseStopTypeV2(pipelineIds []int) (ma
 	return releaseMap, nil
 }
 
+// getPipelineIdsHavingStageSteps returns the pipelines having pre and post cd stages configured as steps, stages
+// configured as yaml are read from the pipeline itself
+func (impl AppListingServiceImpl) getPipelineIdsHavingStageSteps(pipelineIds []int) (map[int]bool, map[int]bool, error) {
+	preStagePipelineIds := make(map[int]bool)
+	postStagePipelineIds := make(map[int]bool)
+	preIds, err := impl.pipelineStageRepository.GetCdPipelineIdsHavingStageSteps(pipelineIds, repository3.PIPELINE_STAGE_TYPE_PRE_CD)
+	if err != nil {
+		impl.Logger.Errorw("error in getting pipelines having pre cd stage steps", "err", err, "pipelineIds", pipelineIds)
+		return nil, nil, err
+	}
+	for _, id := range preIds {
+		preStagePipelineIds[id] = true
+	}
+	postIds, err := impl.pipelineStageRepository.GetCdPipelineIdsHavingStageSteps(pipelineIds, repository3.PIPELINE_STAGE_TYPE_POST_CD)
+	if err != nil {
+		impl.Logger.Errorw("error in getting pipelines having post cd stage steps", "err", err, "pipelineIds", pipelineIds)
+		return nil, nil, err
+	}
+	for _, id := range postIds {
+		postStagePipelineIds[id] = true
+	}
+	return preStagePipelineIds, postStagePipelineIds, nil
+}
+
 func (impl AppListingServiceImpl) GetReleaseCount(appId, envId int) (int, error) {
 	override, err := impl.pipelineOverrideRepository.GetAllRelease(appId, envId)
 	if err != nil && !util.IsErrNoRows(err) {
@@ -383,6 +410,10 @@ func (impl AppListingServiceImpl) fetchACDAppStatus(fetchAppListingRequest Fetch
 		}
 	}
 	releaseMap, _ := impl.ISLastReleaseStopTypeV2(pipelineIds)
+	preStagePipelineIds, postStagePipelineIds, err := impl.getPipelineIdsHavingStageSteps(pipelineIds)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, env := range existingAppEnvContainers {
 		appKey := strconv.Itoa(env.AppId) + "_" + env.AppName
@@ -430,7 +461,7 @@ func (impl AppListingServiceImpl) fetchACDAppStatus(fetchAppListingRequest Fetch
 		}
 
 		if latestTriggeredWf.WorkflowStatus == pipelineConfig.WF_STARTED || latestTriggeredWf.WorkflowStatus == pipelineConfig.WF_UNKNOWN {
-			if pipeline.PreStageConfig != "" {
+			if pipeline.PreStageConfig != "" || preStagePipelineIds[pipeline.Id] {
 				if preCdStageRunner != nil && preCdStageRunner.Id != 0 {
 					env.PreStageStatus = &preCdStageRunner.Status
 				} else {
@@ -438,7 +469,7 @@ func (impl AppListingServiceImpl) fetchACDAppStatus(fetchAppListingRequest Fetch
 					env.PreStageStatus = &status
 				}
 			}
-			if pipeline.PostStageConfig != "" {
+			if pipeline.PostStageConfig != "" || postStagePipelineIds[pipeline.Id] {
 				if postCdStageRunner != nil && postCdStageRunner.Id != 0 {
 					env.PostStageStatus = &postCdStageRunner.Status
 				} else {
@@ -462,7 +493,7 @@ func (impl AppListingServiceImpl) fetchACDAppStatus(fetchAppListingRequest Fetch
 				env.CdStageStatus = &status
 			}
 		} else {
-			if pipeline.PreStageConfig != "" {
+			if pipeline.PreStageConfig != "" || preStagePipelineIds[pipeline.Id] {
 				if preCdStageRunner != nil && preCdStageRunner.Id != 0 {
 					var status string = latestTriggeredWf.WorkflowStatus.String()
 					env.PreStageStatus = &status
@@ -471,7 +502,7 @@ func (impl AppListingServiceImpl) fetchACDAppStatus(fetchAppListingRequest Fetch
 					env.PreStageStatus = &status
 				}
 			}
-			if pipeline.PostStageConfig != "" {
+			if pipeline.PostStageConfig != "" || postStagePipelineIds[pipeline.Id] {
 				if postCdStageRunner != nil && postCdStageRunner.Id != 0 {
 					var status string = latestTriggeredWf.WorkflowStatus.String()
 					env.PostStageStatus = &status
@@ -115,6 +115,7 @@ type AppServiceImpl struct {
 	configMapHistoryRepository          repository3.ConfigMapHistoryRepository
 	strategyHistoryRepository           repository3.PipelineStrategyHistoryRepository
 	deploymentTemplateHistoryRepository repository3.DeploymentTemplateHistoryRepository
+	gitOpsPullRequestRepository         pipelineConfig.GitOpsPullRequestRepository
 }
 
 type AppService interface {
@@ -127,6 +128,8 @@ type AppService interface {
 	GetCmSecretNew(appId int, envId int) (*bean.ConfigMapJson, *bean.ConfigSecretJson, error)
 	MarkImageScanDeployed(appId int, envId int, imageDigest string, clusterId int) error
 	GetChartRepoName(gitRepoUrl string) string
+	// DeployMergedPullRequest updates argo cd and records the deployment once the pull request of the values is merged
+	DeployMergedPullRequest(pipelineOverride *chartConfig.PipelineOverride) error
 }
 
 func NewAppService(
@@ -163,7 +166,8 @@ func NewAppService(
 	appCrudOperationService AppCrudOperationService,
 	configMapHistoryRepository repository3.ConfigMapHistoryRepository,
 	strategyHistoryRepository repository3.PipelineStrategyHistoryRepository,
-	deploymentTemplateHistoryRepository repository3.DeploymentTemplateHistoryRepository) *AppServiceImpl {
+	deploymentTemplateHistoryRepository repository3.DeploymentTemplateHistoryRepository,
+	gitOpsPullRequestRepository pipelineConfig.GitOpsPullRequestRepository) *AppServiceImpl {
 	appServiceImpl := &AppServiceImpl{
 		environmentConfigRepository:         environmentConfigRepository,
 		mergeUtil:                           mergeUtil,
@@ -209,6 +213,7 @@ func NewAppService(
 		configMapHistoryRepository:          configMapHistoryRepository,
 		strategyHistoryRepository:           strategyHistoryRepository,
 		deploymentTemplateHistoryRepository: deploymentTemplateHistoryRepository,
+		gitOpsPullRequestRepository:         gitOpsPullRequestRepository,
 	}
 	return appServiceImpl
 }
@@ -221,7 +226,7 @@ const (
 func (impl AppServiceImpl) getValuesFileForEnv(environmentId int) string {
 	return fmt.Sprintf("_%d-values.yaml", environmentId) //-{envId}-values.yaml
 }
-func (impl AppServiceImpl) createArgoApplicationIfRequired(appId int, appName string, envConfigOverride *chartConfig.EnvConfigOverride, pipeline *pipelineConfig.Pipeline, userId int32) (string, error) {
+func (impl AppServiceImpl) createArgoApplicationIfRequired(appId int, appName string, envConfigOverride *chartConfig.EnvConfigOverride, deploymentAppCreated bool, userId int32) (string, error) {
 	//repo has been registered while helm create
 	chart, err := impl.chartRepository.FindLatestChartForAppByAppId(appId)
 	if err != nil {
@@ -233,7 +238,7 @@ func (impl AppServiceImpl) createArgoApplicationIfRequired(appId int, appName st
 		return "", err
 	}
 	argoAppName := fmt.Sprintf("%s-%s", appName, envModel.Name)
-	if pipeline.DeploymentAppCreated {
+	if deploymentAppCreated {
 		return argoAppName, nil
 	} else {
 		//create
@@ -242,6 +247,15 @@ func (impl AppServiceImpl) createArgoApplicationIfRequired(appId int, appName st
 			appNamespace = "default"
 		}
 		namespace := argocdServer.DevtronInstalationNs
+		repoPath := chart.ChartLocation
+		isSharedRepo, err := impl.chartTemplateService.IsSharedGitOpsRepoLayout()
+		if err != nil {
+			return "", err
+		}
+		if isSharedRepo {
+			//chart location of env is resolved on trigger for shared repo layouts
+			repoPath = envConfigOverride.Chart.ChartLocation
+		}
 		appRequest := &argocdServer.AppTemplate{
 			ApplicationName: argoAppName,
 			Namespace:       namespace,
@@ -249,7 +263,7 @@ func (impl AppServiceImpl) createArgoApplicationIfRequired(appId int, appName st
 			TargetServer:    envModel.Cluster.ServerUrl,
 			Project:         "default",
 			ValuesFile:      impl.getValuesFileForEnv(envModel.Id),
-			RepoPath:        chart.ChartLocation,
+			RepoPath:        repoPath,
 			RepoUrl:         chart.GitRepoUrl,
 		}
 
@@ -341,7 +355,7 @@ func (impl AppServiceImpl) UpdateApplicationStatusAndCheckIsHealthy(newApp, oldA
 				return isHealthy, nil
 			}
 
-			releaseCounter, err := impl.pipelineOverrideRepository.GetCurrentPipelineReleaseCounter(pipelineOverride.PipelineId)
+			releaseCounter, err := impl.pipelineOverrideRepository.GetCurrentPipelineEnvReleaseCounter(pipelineOverride.PipelineId)
 			if err != nil {
 				impl.logger.Errorw("error on update application status", "releaseCounter", releaseCounter, "gitHash", gitHash, "pipelineOverride", pipelineOverride, "dbApp", dbApp, "err", err)
 				return isHealthy, err
@@ -714,6 +728,11 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 		impl.logger.Errorw("invalid req", "err", err, "req", overrideRequest)
 		return 0, err
 	}
+	envId := pipeline.EnvironmentId
+	if overrideRequest.TargetEnvironmentId > 0 {
+		// fan-out rollouts deploy the pipeline on additional environments with the override of that environment
+		envId = overrideRequest.TargetEnvironmentId
+	}
 	envOverride := &chartConfig.EnvConfigOverride{}
 	var appMetrics *bool
 	strategy := &chartConfig.PipelineStrategy{}
@@ -735,9 +754,9 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 			return 0, err
 		}
 		//assuming that if a chartVersion is deployed then it's envConfigOverride will be available
-		envOverride, err = impl.environmentConfigRepository.GetByAppIdEnvIdAndChartRefId(pipeline.AppId, pipeline.EnvironmentId, chartRef.Id)
+		envOverride, err = impl.environmentConfigRepository.GetByAppIdEnvIdAndChartRefId(pipeline.AppId, envId, chartRef.Id)
 		if err != nil {
-			impl.logger.Errorw("error in getting envConfigOverride for pipeline for specific chartVersion", "err", err, "appId", pipeline.AppId, "envId", pipeline.EnvironmentId, "chartRefId", chartRef.Id)
+			impl.logger.Errorw("error in getting envConfigOverride for pipeline for specific chartVersion", "err", err, "appId", pipeline.AppId, "envId", envId, "chartRefId", chartRef.Id)
 			return 0, err
 		}
 		//updating historical data in envConfigOverride and appMetrics flag
@@ -753,7 +772,7 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 		strategy.Config = strategyHistory.Config
 		strategy.PipelineId = pipeline.Id
 	} else if overrideRequest.DeploymentWithConfig == bean.DEPLOYMENT_CONFIG_TYPE_LAST_SAVED {
-		envOverride, err = impl.environmentConfigRepository.ActiveEnvConfigOverride(overrideRequest.AppId, pipeline.EnvironmentId)
+		envOverride, err = impl.environmentConfigRepository.ActiveEnvConfigOverride(overrideRequest.AppId, envId)
 		if err != nil {
 			impl.logger.Errorw("invalid state", "err", err, "req", overrideRequest)
 			return 0, err
@@ -764,7 +783,7 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 				impl.logger.Errorw("invalid state", "err", err, "req", overrideRequest)
 				return 0, err
 			}
-			envOverride, err = impl.environmentConfigRepository.FindChartByAppIdAndEnvIdAndChartRefId(overrideRequest.AppId, pipeline.EnvironmentId, chart.ChartRefId)
+			envOverride, err = impl.environmentConfigRepository.FindChartByAppIdAndEnvIdAndChartRefId(overrideRequest.AppId, envId, chart.ChartRefId)
 			if err != nil && !errors2.IsNotFound(err) {
 				impl.logger.Errorw("invalid state", "err", err, "req", overrideRequest)
 				return 0, err
@@ -772,7 +791,7 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 
 			//creating new env override config
 			if errors2.IsNotFound(err) || envOverride == nil {
-				environment, err := impl.envRepository.FindById(pipeline.EnvironmentId)
+				environment, err := impl.envRepository.FindById(envId)
 				if err != nil && !IsErrNoRows(err) {
 					return 0, err
 				}
@@ -780,7 +799,7 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 					Active:            true,
 					ManualReviewed:    true,
 					Status:            models.CHARTSTATUS_SUCCESS,
-					TargetEnvironment: pipeline.EnvironmentId,
+					TargetEnvironment: envId,
 					ChartId:           chart.Id,
 					AuditLog:          sql.AuditLog{UpdatedBy: overrideRequest.UserId, UpdatedOn: triggeredAt, CreatedOn: triggeredAt, CreatedBy: overrideRequest.UserId},
 					Namespace:         environment.Namespace,
@@ -813,7 +832,7 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 		}
 		appMetrics = &appLevelMetrics.AppMetrics
 
-		envLevelMetrics, err := impl.envLevelMetricsRepository.FindByAppIdAndEnvId(pipeline.AppId, pipeline.EnvironmentId)
+		envLevelMetrics, err := impl.envLevelMetricsRepository.FindByAppIdAndEnvId(pipeline.AppId, envId)
 		if err != nil && !IsErrNoRows(err) {
 			impl.logger.Errorw("err", err)
 			return 0, &ApiError{InternalMessage: "unable to fetch env level metrics flag"}
@@ -849,10 +868,13 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 			return 0, err
 		}
 	}
-	err = impl.CreateHistoriesForDeploymentTrigger(pipeline, strategy, envOverride, envOverride.Chart.ImageDescriptorTemplate, triggeredAt, deployedBy)
-	if err != nil {
-		impl.logger.Errorw("error in creating history entries for deployment trigger", "err", err)
-		return 0, err
+	// deployment histories are kept for the pipeline's own environment, fan-out deployments are tracked by their runners
+	if overrideRequest.TargetEnvironmentId == 0 {
+		err = impl.CreateHistoriesForDeploymentTrigger(pipeline, strategy, envOverride, envOverride.Chart.ImageDescriptorTemplate, triggeredAt, deployedBy)
+		if err != nil {
+			impl.logger.Errorw("error in creating history entries for deployment trigger", "err", err)
+			return 0, err
+		}
 	}
 
 	// auto-healing :  data corruption fix - if ChartLocation in chart is not correct, need correction
@@ -880,7 +902,20 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 	referenceTemplatePath := path.Join(string(impl.refChartDir), envOverride.Chart.ReferenceTemplate)
 	if IsAcdApp(pipeline.DeploymentAppType) {
 		// CHART COMMIT and PUSH STARTS HERE, it will push latest version, if found modified on deployment template and overrides
-		gitOpsRepoName := impl.chartTemplateService.GetGitOpsRepoName(pipeline.App.AppName)
+		app, err := impl.appRepository.FindAppAndProjectByAppId(pipeline.AppId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching app and project", "appId", pipeline.AppId, "err", err)
+			return 0, err
+		}
+		gitOpsRepoName, err := impl.chartTemplateService.GetGitOpsRepoNameForApp(app.AppName, app.Team.Name)
+		if err != nil {
+			impl.logger.Errorw("error in getting gitops repo name", "appId", pipeline.AppId, "err", err)
+			return 0, err
+		}
+		err = impl.setEnvChartLocation(app, env.Name, envOverride)
+		if err != nil {
+			return 0, err
+		}
 
 		chartData, err = impl.chartRefRepository.FindById(envOverride.Chart.ChartRefId)
 		if err != nil {
@@ -896,7 +931,7 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 		userUploaded = chartData.UserUploaded
 		var gitCommitStatus pipelineConfig.TimelineStatus
 		var gitCommitStatusDetail string
-		err = impl.chartTemplateService.BuildChartAndPushToGitRepo(chartMetaData, referenceTemplatePath, gitOpsRepoName, envOverride.Chart.ReferenceTemplate, envOverride.Chart.ChartVersion, envOverride.Chart.GitRepoUrl, overrideRequest.UserId)
+		err = impl.chartTemplateService.BuildChartAndPushToGitRepo(chartMetaData, referenceTemplatePath, gitOpsRepoName, envOverride.Chart.ChartLocation, envOverride.Chart.GitRepoUrl, overrideRequest.UserId)
 		if err != nil {
 			impl.logger.Errorw("Ref chart commit error on cd trigger", "err", err, "req", overrideRequest)
 			gitCommitStatus = pipelineConfig.TIMELINE_STATUS_GIT_COMMIT_FAILED
@@ -919,7 +954,8 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 				impl.logger.Errorw("error in creating timeline status for git commit", "err", timelineErr, "timeline", timeline)
 			}
 			return 0, err
-		} else {
+		} else if !env.GitOpsPrRequired {
+			// with pull requests the git commit is recorded once the pull request is merged
 			gitCommitStatus = pipelineConfig.TIMELINE_STATUS_GIT_COMMIT
 			gitCommitStatusDetail = "Git commit done successfully."
 			// creating cd pipeline status timeline for git commit
@@ -943,7 +979,11 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 
 		// ACD app creation STARTS HERE, it will use existing if already created
 		impl.logger.Debugw("new pipeline found", "pipeline", pipeline)
-		name, err := impl.createArgoApplicationIfRequired(overrideRequest.AppId, pipeline.App.AppName, envOverride, pipeline, deployedBy)
+		deploymentAppCreated := pipeline.DeploymentAppCreated
+		if overrideRequest.TargetEnvironmentId > 0 {
+			deploymentAppCreated = overrideRequest.TargetDeploymentAppCreated
+		}
+		name, err := impl.createArgoApplicationIfRequired(overrideRequest.AppId, pipeline.App.AppName, envOverride, deploymentAppCreated, deployedBy)
 		if err != nil {
 			impl.logger.Errorw("acd application create error on cd trigger", "err", err, "req", overrideRequest)
 			return 0, err
@@ -988,46 +1028,14 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 		impl.logger.Errorw("error in fetching app labels for gitOps commit", "err", err)
 		appLabelJsonByte = nil
 	}
-	releaseId, pipelineOverrideId, mergeAndSave, saveErr := impl.mergeAndSave(envOverride, overrideRequest, dbMigrationOverride, artifact, pipeline, configMapJson, appLabelJsonByte, strategy, ctx, triggeredAt, deployedBy, appMetrics)
+	releaseId, pipelineOverrideId, mergeAndSave, saveErr := impl.mergeAndSave(envOverride, overrideRequest, dbMigrationOverride, artifact, pipeline, configMapJson, appLabelJsonByte, strategy, ctx, triggeredAt, deployedBy, appMetrics, wfrId)
 	if releaseId != 0 {
-		//updating the acd app with updated values and sync operation
-		if IsAcdApp(pipeline.DeploymentAppType) {
-			updateAppInArgocd, err := impl.updateArgoPipeline(overrideRequest.AppId, pipeline.Name, envOverride, ctx)
+		// with pull requests argo cd is updated once the pull request is merged, see DeployMergedPullRequest
+		if !(IsAcdApp(pipeline.DeploymentAppType) && envOverride.Environment.GitOpsPrRequired) {
+			err = impl.updateArgoAppAndSaveNewDeployment(pipeline, envOverride, ctx, triggeredAt)
 			if err != nil {
-				impl.logger.Errorw("error in updating argocd  app ", "err", err)
 				return 0, err
 			}
-			if updateAppInArgocd {
-				impl.logger.Debug("argo-cd successfully updated")
-			} else {
-				impl.logger.Debug("argo-cd failed to update, ignoring it")
-			}
-			//	impl.synchCD(pipeline, ctx, overrideRequest, envOverride)
-		}
-
-		deploymentStatus := &repository.DeploymentStatus{
-			AppName:   pipeline.App.AppName + "-" + envOverride.Environment.Name,
-			AppId:     pipeline.AppId,
-			EnvId:     pipeline.EnvironmentId,
-			Status:    repository.NewDeployment,
-			CreatedOn: triggeredAt,
-			UpdatedOn: triggeredAt,
-		}
-		dbConnection := impl.pipelineRepository.GetConnection()
-		tx, err := dbConnection.Begin()
-		if err != nil {
-			return 0, err
-		}
-		// Rollback tx on error.
-		defer tx.Rollback()
-		err = impl.appListingRepository.SaveNewDeployment(deploymentStatus, tx)
-		if err != nil {
-			impl.logger.Errorw("error in saving new deployment history", "req", overrideRequest, "err", err)
-			return 0, err
-		}
-		err = tx.Commit()
-		if err != nil {
-			return 0, err
 		}
 
 		//for helm type cd pipeline, create install helm application, update deployment status, update workflow runner for app detail status.
@@ -1039,22 +1047,126 @@ func (impl AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRe
 			}
 		}
 
-		//update cd pipeline to mark deployment app created
-		_, err = impl.updatePipeline(pipeline, overrideRequest.UserId)
-		if err != nil {
-			impl.logger.Errorw("error in update cd pipeline for deployment app created or not", "err", err)
-			return 0, err
+		//update cd pipeline to mark deployment app created, fan-out rollouts track deployment apps of their environments
+		if overrideRequest.TargetEnvironmentId == 0 {
+			_, err = impl.updatePipeline(pipeline, overrideRequest.UserId)
+			if err != nil {
+				impl.logger.Errorw("error in update cd pipeline for deployment app created or not", "err", err)
+				return 0, err
+			}
 		}
 
 		go impl.WriteCDTriggerEvent(overrideRequest, pipeline, envOverride, materialInfoMap, artifact, releaseId, pipelineOverrideId)
 		if artifact.ScanEnabled {
-			_ = impl.MarkImageScanDeployed(overrideRequest.AppId, envOverride.TargetEnvironment, artifact.ImageDigest, pipeline.Environment.ClusterId)
+			_ = impl.MarkImageScanDeployed(overrideRequest.AppId, envOverride.TargetEnvironment, artifact.ImageDigest, envOverride.Environment.ClusterId)
 		}
 	}
 	middleware.CdTriggerCounter.WithLabelValues(strconv.Itoa(pipeline.AppId), strconv.Itoa(pipeline.EnvironmentId), strconv.Itoa(pipeline.Id)).Inc()
 	return releaseId, saveErr
 }
 
+// updateArgoAppAndSaveNewDeployment updates the acd app with the committed values and records the new deployment
+func (impl AppServiceImpl) updateArgoAppAndSaveNewDeployment(pipeline *pipelineConfig.Pipeline, envOverride *chartConfig.EnvConfigOverride, ctx context.Context, triggeredAt time.Time) error {
+	//updating the acd app with updated values and sync operation
+	if IsAcdApp(pipeline.DeploymentAppType) {
+		updateAppInArgocd, err := impl.updateArgoPipeline(pipeline.AppId, pipeline.Name, envOverride, ctx)
+		if err != nil {
+			impl.logger.Errorw("error in updating argocd  app ", "err", err)
+			return err
+		}
+		if updateAppInArgocd {
+			impl.logger.Debug("argo-cd successfully updated")
+		} else {
+			impl.logger.Debug("argo-cd failed to update, ignoring it")
+		}
+		//	impl.synchCD(pipeline, ctx, overrideRequest, envOverride)
+	}
+
+	deploymentStatus := &repository.DeploymentStatus{
+		AppName:   pipeline.App.AppName + "-" + envOverride.Environment.Name,
+		AppId:     pipeline.AppId,
+		EnvId:     envOverride.TargetEnvironment,
+		Status:    repository.NewDeployment,
+		CreatedOn: triggeredAt,
+		UpdatedOn: triggeredAt,
+	}
+	dbConnection := impl.pipelineRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.appListingRepository.SaveNewDeployment(deploymentStatus, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving new deployment history", "pipelineId", pipeline.Id, "err", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl AppServiceImpl) DeployMergedPullRequest(pipelineOverride *chartConfig.PipelineOverride) error {
+	pipeline, err := impl.pipelineRepository.FindById(pipelineOverride.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline", "pipelineId", pipelineOverride.PipelineId, "err", err)
+		return err
+	}
+	envOverride, err := impl.environmentConfigRepository.Get(pipelineOverride.EnvConfigOverrideId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching env config override", "id", pipelineOverride.EnvConfigOverrideId, "err", err)
+		return err
+	}
+	if !envOverride.IsOverride {
+		// same as on trigger, envs without override deploy the latest app chart
+		chart, err := impl.chartRepository.FindLatestChartForAppByAppId(pipeline.AppId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching latest chart", "appId", pipeline.AppId, "err", err)
+			return err
+		}
+		envOverride.Chart = chart
+	}
+	env, err := impl.envRepository.FindById(envOverride.TargetEnvironment)
+	if err != nil {
+		impl.logger.Errorw("unable to find env", "envId", envOverride.TargetEnvironment, "err", err)
+		return err
+	}
+	envOverride.Environment = env
+	app, err := impl.appRepository.FindAppAndProjectByAppId(pipeline.AppId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching app and project", "appId", pipeline.AppId, "err", err)
+		return err
+	}
+	err = impl.setEnvChartLocation(app, env.Name, envOverride)
+	if err != nil {
+		return err
+	}
+	ctx, err := impl.buildACDContext()
+	if err != nil {
+		return err
+	}
+	return impl.updateArgoAppAndSaveNewDeployment(pipeline, envOverride, ctx, time.Now())
+}
+
+// setEnvChartLocation sets the env's chart directory in shared repo layouts, the location is not persisted as chart is
+// common for all envs
+func (impl AppServiceImpl) setEnvChartLocation(app *app.App, envName string, envOverride *chartConfig.EnvConfigOverride) error {
+	isSharedRepo, err := impl.chartTemplateService.IsSharedGitOpsRepoLayout()
+	if err != nil {
+		impl.logger.Errorw("error in getting gitops repo layout", "err", err)
+		return err
+	}
+	if !isSharedRepo {
+		return nil
+	}
+	chartLocation, err := impl.chartTemplateService.GetGitOpsChartLocation(app.Team.Name, app.AppName, envName, envOverride.Chart.ReferenceTemplate, envOverride.Chart.ChartVersion)
+	if err != nil {
+		impl.logger.Errorw("error in getting gitops chart location", "appId", app.Id, "err", err)
+		return err
+	}
+	envOverride.Chart.ChartLocation = chartLocation
+	return nil
+}
+
 func (impl AppServiceImpl) autoHealChartLocationInChart(envOverride *chartConfig.EnvConfigOverride) error {
 	chartId := envOverride.Chart.Id
 	impl.logger.Infow("auto-healing: Chart location in chart not correct. modifying ", "chartId", chartId,
@@ -1475,7 +1587,7 @@ func (impl AppServiceImpl) getReleaseOverride(envOverride *chartConfig.EnvConfig
 	imageTag := strings.Split(artifactImage, ":")
 
 	appId := strconv.Itoa(pipeline.App.Id)
-	envId := strconv.Itoa(pipeline.EnvironmentId)
+	envId := strconv.Itoa(envOverride.TargetEnvironment)
 
 	deploymentStrategy := ""
 	if strategy != nil {
@@ -1520,7 +1632,7 @@ func (impl AppServiceImpl) mergeAndSave(envOverride *chartConfig.EnvConfigOverri
 	dbMigrationOverride []byte,
 	artifact *repository.CiArtifact,
 	pipeline *pipelineConfig.Pipeline, configMapJson, appLabelJsonByte []byte, strategy *chartConfig.PipelineStrategy, ctx context.Context,
-	triggeredAt time.Time, deployedBy int32, appMetrics *bool) (releaseId int, overrideId int, mergedValues string, err error) {
+	triggeredAt time.Time, deployedBy int32, appMetrics *bool, wfrId int) (releaseId int, overrideId int, mergedValues string, err error) {
 
 	//register release , obtain release id TODO: populate releaseId to template
 	override, err := impl.savePipelineOverride(overrideRequest, envOverride.Id, triggeredAt)
@@ -1598,7 +1710,12 @@ func (impl AppServiceImpl) mergeAndSave(envOverride *chartConfig.EnvConfigOverri
 			UserName:       userName,
 			UserEmailId:    userEmailId,
 		}
-		commitHash, commitTime, err = impl.gitFactory.Client.CommitValues(chartGitAttr)
+		if envOverride.Environment != nil && envOverride.Environment.GitOpsPrRequired {
+			// git hash is set to the merge commit once the pull request is merged
+			err = impl.commitValuesThroughPullRequest(chartGitAttr, override.Id, wfrId, overrideRequest.PipelineId, deployedBy)
+		} else {
+			commitHash, commitTime, err = impl.gitFactory.Client.CommitValues(chartGitAttr)
+		}
 		if err != nil {
 			impl.logger.Errorw("error in git commit", "err", err)
 			return 0, 0, "", err
@@ -1623,6 +1740,54 @@ func (impl AppServiceImpl) mergeAndSave(envOverride *chartConfig.EnvConfigOverri
 	return override.PipelineReleaseCounter, override.Id, mergedValues, nil
 }
 
+// commitValuesThroughPullRequest commits values on a release branch and opens a pull request against master,
+// argo cd keeps syncing master so the deployment continues only once the pull request is merged
+func (impl AppServiceImpl) commitValuesThroughPullRequest(chartGitAttr *ChartConfig, overrideId, wfrId, pipelineId int, userId int32) error {
+	if wfrId == 0 {
+		return fmt.Errorf("workflow runner not found for pull request based deployment")
+	}
+	branch := fmt.Sprintf("devtron/release-%d", overrideId)
+	_, _, err := impl.gitFactory.Client.CommitValuesToBranch(chartGitAttr, branch)
+	if err != nil {
+		impl.logger.Errorw("error in git commit on branch", "branch", branch, "err", err)
+		return err
+	}
+	pr, err := impl.gitFactory.Client.CreatePullRequest(chartGitAttr.ChartRepoName, branch, chartGitAttr.ReleaseMessage,
+		fmt.Sprintf("Values update of %s for %s, deployment continues once this pull request is merged.", chartGitAttr.FileName, chartGitAttr.ChartLocation))
+	if err != nil {
+		impl.logger.Errorw("error in creating pull request", "branch", branch, "err", err)
+		return err
+	}
+	pullRequest := &pipelineConfig.GitOpsPullRequest{
+		CdWorkflowRunnerId: wfrId,
+		PipelineOverrideId: overrideId,
+		PipelineId:         pipelineId,
+		RepoName:           chartGitAttr.ChartRepoName,
+		Branch:             branch,
+		PrNumber:           pr.Number,
+		PrUrl:              pr.Url,
+		Status:             pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN,
+		AuditLog:           sql.AuditLog{CreatedBy: userId, CreatedOn: time.Now(), UpdatedBy: userId, UpdatedOn: time.Now()},
+	}
+	err = impl.gitOpsPullRequestRepository.Save(pullRequest)
+	if err != nil {
+		impl.logger.Errorw("error in saving gitops pull request", "pullRequest", pullRequest, "err", err)
+		return err
+	}
+	timeline := &pipelineConfig.PipelineStatusTimeline{
+		CdWorkflowRunnerId: wfrId,
+		Status:             pipelineConfig.TIMELINE_STATUS_GIT_COMMIT_WAITING_FOR_MERGE,
+		StatusDetail:       fmt.Sprintf("Waiting for pull request %s to be merged.", pr.Url),
+		StatusTime:         time.Now(),
+		AuditLog:           sql.AuditLog{CreatedBy: userId, CreatedOn: time.Now(), UpdatedBy: userId, UpdatedOn: time.Now()},
+	}
+	err = impl.cdPipelineStatusTimelineRepo.SaveTimeline(timeline)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for pull request", "err", err, "timeline", timeline)
+	}
+	return nil
+}
+
 func (impl AppServiceImpl) savePipelineOverride(overrideRequest *bean.ValuesOverrideRequest, envOverrideId int, triggeredAt time.Time) (override *chartConfig.PipelineOverride, err error) {
 	currentReleaseNo, err := impl.pipelineOverrideRepository.GetCurrentPipelineReleaseCounter(overrideRequest.PipelineId)
 	if err != nil {
@@ -0,0 +1,310 @@
+package artifactPromotion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	repository3 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/security"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const promotionAuditListLimit = 50
+
+type ArtifactPromotionPolicyDto struct {
+	Id                   int   `json:"id"`
+	PipelineId           int   `json:"pipelineId" validate:"required"`
+	SourceEnvironmentIds []int `json:"sourceEnvironmentIds"`
+	RequirePostCd        bool  `json:"requirePostCd"`
+	RequireImageScan     bool  `json:"requireImageScan"`
+	Enabled              bool  `json:"enabled"`
+	UserId               int32 `json:"-"`
+}
+
+type ArtifactPromotionRequest struct {
+	PipelineId                     int    `json:"pipelineId" validate:"required"`
+	CiArtifactId                   int    `json:"ciArtifactId" validate:"required"`
+	OverrideDeploymentWindow       bool   `json:"overrideDeploymentWindow"`
+	DeploymentWindowOverrideReason string `json:"deploymentWindowOverrideReason"`
+	UserId                         int32  `json:"-"`
+}
+
+type ArtifactPromotionResult struct {
+	PipelineId     int      `json:"pipelineId"`
+	CiArtifactId   int      `json:"ciArtifactId"`
+	Image          string   `json:"image"`
+	Eligible       bool     `json:"eligible"`
+	Promoted       bool     `json:"promoted"`
+	BlockedReasons []string `json:"blockedReasons"`
+}
+
+type ArtifactPromotionAuditDto struct {
+	Id           int                                    `json:"id"`
+	PipelineId   int                                    `json:"pipelineId"`
+	CiArtifactId int                                    `json:"ciArtifactId"`
+	Status       pipelineConfig.ArtifactPromotionStatus `json:"status"`
+	Reason       string                                 `json:"reason"`
+	CreatedBy    int32                                  `json:"createdBy"`
+	CreatedOn    time.Time                              `json:"createdOn"`
+}
+
+type ArtifactPromotionService interface {
+	GetPolicy(pipelineId int) (*ArtifactPromotionPolicyDto, error)
+	SavePolicy(policyDto *ArtifactPromotionPolicyDto) (*ArtifactPromotionPolicyDto, error)
+	// CheckPromotion evaluates the target pipeline's promotion policy for the artifact without triggering anything
+	CheckPromotion(request *ArtifactPromotionRequest) (*ArtifactPromotionResult, error)
+	// PromoteArtifact triggers the target pipeline with the artifact if the promotion policy allows it, otherwise
+	// returns an error carrying the blocked reasons
+	PromoteArtifact(request *ArtifactPromotionRequest, ctx context.Context) (*ArtifactPromotionResult, error)
+	GetAudits(pipelineId int) ([]*ArtifactPromotionAuditDto, error)
+}
+
+type ArtifactPromotionServiceImpl struct {
+	logger                      *zap.SugaredLogger
+	artifactPromotionRepository pipelineConfig.ArtifactPromotionRepository
+	pipelineRepository          pipelineConfig.PipelineRepository
+	ciArtifactRepository        repository.CiArtifactRepository
+	environmentRepository       repository2.EnvironmentRepository
+	pipelineStageService        pipeline.PipelineStageService
+	promotionPolicyService      pipeline.ArtifactPromotionPolicyService
+	policyService               security.PolicyService
+	workflowDagExecutor         pipeline.WorkflowDagExecutor
+}
+
+func NewArtifactPromotionServiceImpl(logger *zap.SugaredLogger,
+	artifactPromotionRepository pipelineConfig.ArtifactPromotionRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	ciArtifactRepository repository.CiArtifactRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	pipelineStageService pipeline.PipelineStageService,
+	promotionPolicyService pipeline.ArtifactPromotionPolicyService,
+	policyService security.PolicyService,
+	workflowDagExecutor pipeline.WorkflowDagExecutor) *ArtifactPromotionServiceImpl {
+	return &ArtifactPromotionServiceImpl{
+		logger:                      logger,
+		artifactPromotionRepository: artifactPromotionRepository,
+		pipelineRepository:          pipelineRepository,
+		ciArtifactRepository:        ciArtifactRepository,
+		environmentRepository:       environmentRepository,
+		pipelineStageService:        pipelineStageService,
+		promotionPolicyService:      promotionPolicyService,
+		policyService:               policyService,
+		workflowDagExecutor:         workflowDagExecutor,
+	}
+}
+
+func (impl *ArtifactPromotionServiceImpl) GetPolicy(pipelineId int) (*ArtifactPromotionPolicyDto, error) {
+	policy, err := impl.artifactPromotionRepository.FindActivePolicyByPipelineId(pipelineId)
+	if err == pg.ErrNoRows {
+		return &ArtifactPromotionPolicyDto{PipelineId: pipelineId, SourceEnvironmentIds: []int{}, RequirePostCd: true}, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting artifact promotion policy", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	return adaptPromotionPolicy(policy), nil
+}
+
+func (impl *ArtifactPromotionServiceImpl) SavePolicy(policyDto *ArtifactPromotionPolicyDto) (*ArtifactPromotionPolicyDto, error) {
+	cdPipeline, err := impl.pipelineRepository.FindById(policyDto.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", policyDto.PipelineId)
+		return nil, err
+	}
+	for _, envId := range policyDto.SourceEnvironmentIds {
+		if envId == cdPipeline.EnvironmentId {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "target environment in source environments", UserMessage: "target environment can not be a required source environment"}
+		}
+	}
+	policy, err := impl.artifactPromotionRepository.FindActivePolicyByPipelineId(policyDto.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting artifact promotion policy", "err", err, "pipelineId", policyDto.PipelineId)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		if !policyDto.Enabled {
+			return policyDto, nil
+		}
+		policy = &pipelineConfig.ArtifactPromotionPolicy{
+			PipelineId: policyDto.PipelineId,
+			AuditLog:   sql.AuditLog{CreatedOn: time.Now(), CreatedBy: policyDto.UserId},
+		}
+	}
+	policy.SourceEnvironmentIds = policyDto.SourceEnvironmentIds
+	policy.RequirePostCd = policyDto.RequirePostCd
+	policy.RequireImageScan = policyDto.RequireImageScan
+	policy.Active = policyDto.Enabled
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = policyDto.UserId
+	if policy.Id == 0 {
+		err = impl.artifactPromotionRepository.SavePolicy(policy)
+	} else {
+		err = impl.artifactPromotionRepository.UpdatePolicy(policy)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving artifact promotion policy", "err", err, "policy", policy)
+		return nil, err
+	}
+	return adaptPromotionPolicy(policy), nil
+}
+
+func (impl *ArtifactPromotionServiceImpl) CheckPromotion(request *ArtifactPromotionRequest) (*ArtifactPromotionResult, error) {
+	result, _, err := impl.evaluatePromotion(request)
+	return result, err
+}
+
+func (impl *ArtifactPromotionServiceImpl) PromoteArtifact(request *ArtifactPromotionRequest, ctx context.Context) (*ArtifactPromotionResult, error) {
+	result, cdPipeline, err := impl.evaluatePromotion(request)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Eligible {
+		impl.saveAudit(request, pipelineConfig.ARTIFACT_PROMOTION_STATUS_BLOCKED, strings.Join(result.BlockedReasons, "; "))
+		return result, &util.ApiError{HttpStatusCode: http.StatusPreconditionFailed, InternalMessage: "artifact promotion blocked", UserMessage: result}
+	}
+	preStageExists, err := impl.pipelineStageService.IsCdPipelineStageConfigured(cdPipeline, repository3.PIPELINE_STAGE_TYPE_PRE_CD)
+	if err != nil {
+		return nil, err
+	}
+	cdWorkflowType := bean.CD_WORKFLOW_TYPE_DEPLOY
+	if preStageExists {
+		cdWorkflowType = bean.CD_WORKFLOW_TYPE_PRE
+	}
+	overrideRequest := &bean.ValuesOverrideRequest{
+		PipelineId:                     cdPipeline.Id,
+		AppId:                          cdPipeline.AppId,
+		CiArtifactId:                   request.CiArtifactId,
+		CdWorkflowType:                 cdWorkflowType,
+		OverrideDeploymentWindow:       request.OverrideDeploymentWindow,
+		DeploymentWindowOverrideReason: request.DeploymentWindowOverrideReason,
+		UserId:                         request.UserId,
+	}
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("error in triggering promoted artifact", "err", err, "overrideRequest", overrideRequest)
+		impl.saveAudit(request, pipelineConfig.ARTIFACT_PROMOTION_STATUS_FAILED, err.Error())
+		return nil, err
+	}
+	impl.saveAudit(request, pipelineConfig.ARTIFACT_PROMOTION_STATUS_PROMOTED, "")
+	result.Promoted = true
+	return result, nil
+}
+
+func (impl *ArtifactPromotionServiceImpl) GetAudits(pipelineId int) ([]*ArtifactPromotionAuditDto, error) {
+	audits, err := impl.artifactPromotionRepository.FindAuditsByPipelineId(pipelineId, promotionAuditListLimit)
+	if err != nil {
+		impl.logger.Errorw("error in getting artifact promotion audits", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	auditDtos := make([]*ArtifactPromotionAuditDto, 0, len(audits))
+	for _, audit := range audits {
+		auditDtos = append(auditDtos, &ArtifactPromotionAuditDto{
+			Id:           audit.Id,
+			PipelineId:   audit.PipelineId,
+			CiArtifactId: audit.CiArtifactId,
+			Status:       audit.Status,
+			Reason:       audit.Reason,
+			CreatedBy:    audit.CreatedBy,
+			CreatedOn:    audit.CreatedOn,
+		})
+	}
+	return auditDtos, nil
+}
+
+// evaluatePromotion collects every reason blocking the artifact from the target pipeline, the vulnerability policy
+// is checked here on top of the promotion gate so that a blocked image is reported before the trigger fails on it
+func (impl *ArtifactPromotionServiceImpl) evaluatePromotion(request *ArtifactPromotionRequest) (*ArtifactPromotionResult, *pipelineConfig.Pipeline, error) {
+	cdPipeline, err := impl.pipelineRepository.FindById(request.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", request.PipelineId)
+		return nil, nil, err
+	}
+	artifact, err := impl.ciArtifactRepository.Get(request.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci artifact", "err", err, "artifactId", request.CiArtifactId)
+		return nil, nil, err
+	}
+	result := &ArtifactPromotionResult{
+		PipelineId:     cdPipeline.Id,
+		CiArtifactId:   artifact.Id,
+		Image:          artifact.Image,
+		BlockedReasons: []string{},
+	}
+	blockedReasons, policy, err := impl.promotionPolicyService.GetBlockedReasons(cdPipeline, artifact)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.BlockedReasons = append(result.BlockedReasons, blockedReasons...)
+	if policy != nil && policy.RequireImageScan && artifact.Scanned {
+		reasons, err := impl.checkImageScan(cdPipeline, artifact)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.BlockedReasons = append(result.BlockedReasons, reasons...)
+	}
+	result.Eligible = len(result.BlockedReasons) == 0
+	return result, cdPipeline, nil
+}
+
+func (impl *ArtifactPromotionServiceImpl) checkImageScan(cdPipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact) ([]string, error) {
+	env, err := impl.environmentRepository.FindById(cdPipeline.EnvironmentId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environment", "err", err, "envId", cdPipeline.EnvironmentId)
+		return nil, err
+	}
+	verifyImageRequest := &security.VerifyImageRequest{
+		Images:      []string{artifact.Image},
+		ReleaseName: fmt.Sprintf("%s-%s", cdPipeline.App.AppName, env.Name),
+		Namespace:   env.Namespace,
+		ClusterName: env.Cluster.ClusterName,
+	}
+	blockedCves, err := impl.policyService.VerifyImage(verifyImageRequest)
+	if err != nil {
+		impl.logger.Errorw("error in verifying image against scan policy", "err", err, "image", artifact.Image)
+		return nil, err
+	}
+	var reasons []string
+	for _, cve := range blockedCves[artifact.Image] {
+		reasons = append(reasons, fmt.Sprintf("image blocked by scan policy, %s (%s) in package %s %s", cve.Name, cve.Severity, cve.Package, cve.Version))
+	}
+	return reasons, nil
+}
+
+func (impl *ArtifactPromotionServiceImpl) saveAudit(request *ArtifactPromotionRequest, status pipelineConfig.ArtifactPromotionStatus, reason string) {
+	audit := &pipelineConfig.ArtifactPromotionAudit{
+		PipelineId:   request.PipelineId,
+		CiArtifactId: request.CiArtifactId,
+		Status:       status,
+		Reason:       reason,
+		AuditLog:     sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	}
+	err := impl.artifactPromotionRepository.SaveAudit(audit)
+	if err != nil {
+		impl.logger.Errorw("error in saving artifact promotion audit", "err", err, "audit", audit)
+	}
+}
+
+func adaptPromotionPolicy(policy *pipelineConfig.ArtifactPromotionPolicy) *ArtifactPromotionPolicyDto {
+	sourceEnvironmentIds := policy.SourceEnvironmentIds
+	if sourceEnvironmentIds == nil {
+		sourceEnvironmentIds = []int{}
+	}
+	return &ArtifactPromotionPolicyDto{
+		Id:                   policy.Id,
+		PipelineId:           policy.PipelineId,
+		SourceEnvironmentIds: sourceEnvironmentIds,
+		RequirePostCd:        policy.RequirePostCd,
+		RequireImageScan:     policy.RequireImageScan,
+		Enabled:              policy.Active,
+	}
+}
@@ -0,0 +1,108 @@
+package auditLog
+
+import (
+	"time"
+
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+type AuditLogSource string
+
+const (
+	AUDIT_LOG_SOURCE_API     AuditLogSource = "API"
+	AUDIT_LOG_SOURCE_SERVICE AuditLogSource = "SERVICE"
+)
+
+// AuditLog is a single mutation, rows with source API are written by the middleware for every mutating request and
+// rows with source SERVICE are written by services with the state of the resource before and after the change
+type AuditLog struct {
+	tableName    struct{}       `sql:"audit_log" pg:",discard_unknown_columns"`
+	Id           int            `sql:"id,pk"`
+	Source       AuditLogSource `sql:"source,notnull"`
+	UserId       int32          `sql:"user_id"`
+	EmailId      string         `sql:"email_id"`
+	ResourceType ResourceType   `sql:"resource_type,notnull"`
+	ResourceId   string         `sql:"resource_id"`
+	Action       string         `sql:"action,notnull"`
+	Method       string         `sql:"method"`
+	Path         string         `sql:"path"`
+	StatusCode   int            `sql:"status_code"`
+	ClientIp     string         `sql:"client_ip"`
+	RequestBody  string         `sql:"request_body"`
+	BeforeState  string         `sql:"before_state"`
+	AfterState   string         `sql:"after_state"`
+	DurationMs   int64          `sql:"duration_ms"`
+	CreatedOn    time.Time      `sql:"created_on,notnull"`
+}
+
+type AuditLogFilter struct {
+	Source       AuditLogSource
+	UserId       int32
+	EmailId      string
+	ResourceType ResourceType
+	ResourceId   string
+	Action       string
+	From         time.Time
+	To           time.Time
+	Offset       int
+	Size         int
+}
+
+type AuditLogRepository interface {
+	Save(auditLog *AuditLog) error
+	FindByFilter(filter *AuditLogFilter) ([]*AuditLog, int, error)
+}
+
+type AuditLogRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewAuditLogRepositoryImpl(dbConnection *pg.DB) *AuditLogRepositoryImpl {
+	return &AuditLogRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl AuditLogRepositoryImpl) Save(auditLog *AuditLog) error {
+	return impl.dbConnection.Insert(auditLog)
+}
+
+// FindByFilter returns the page of logs matching the filter along with the total count of matching logs
+func (impl AuditLogRepositoryImpl) FindByFilter(filter *AuditLogFilter) ([]*AuditLog, int, error) {
+	var auditLogs []*AuditLog
+	query := impl.dbConnection.Model(&auditLogs)
+	query = impl.applyFilter(query, filter)
+	query = query.Order("id DESC").Offset(filter.Offset)
+	if filter.Size > 0 {
+		query = query.Limit(filter.Size)
+	}
+	count, err := query.SelectAndCount()
+	return auditLogs, count, err
+}
+
+func (impl AuditLogRepositoryImpl) applyFilter(query *orm.Query, filter *AuditLogFilter) *orm.Query {
+	if len(filter.Source) > 0 {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.UserId > 0 {
+		query = query.Where("user_id = ?", filter.UserId)
+	}
+	if len(filter.EmailId) > 0 {
+		query = query.Where("email_id = ?", filter.EmailId)
+	}
+	if len(filter.ResourceType) > 0 {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if len(filter.ResourceId) > 0 {
+		query = query.Where("resource_id = ?", filter.ResourceId)
+	}
+	if len(filter.Action) > 0 {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_on >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_on <= ?", filter.To)
+	}
+	return query
+}
@@ -0,0 +1,376 @@
+package auditLog
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ResourceType string
+
+const (
+	RESOURCE_APP                 ResourceType = "APP"
+	RESOURCE_PIPELINE            ResourceType = "PIPELINE"
+	RESOURCE_DEPLOYMENT_TEMPLATE ResourceType = "DEPLOYMENT_TEMPLATE"
+	RESOURCE_CONFIG_MAP          ResourceType = "CONFIG_MAP"
+	RESOURCE_SECRET              ResourceType = "SECRET"
+	RESOURCE_CLUSTER             ResourceType = "CLUSTER"
+	RESOURCE_ENVIRONMENT         ResourceType = "ENVIRONMENT"
+	RESOURCE_TEAM                ResourceType = "TEAM"
+	RESOURCE_USER                ResourceType = "USER"
+	RESOURCE_ROLE_GROUP          ResourceType = "ROLE_GROUP"
+	RESOURCE_POLICY              ResourceType = "POLICY"
+	RESOURCE_CVE_EXCEPTION       ResourceType = "CVE_EXCEPTION"
+	RESOURCE_API_TOKEN           ResourceType = "API_TOKEN"
+	RESOURCE_GIT_PROVIDER        ResourceType = "GIT_PROVIDER"
+	RESOURCE_CONTAINER_REGISTRY  ResourceType = "CONTAINER_REGISTRY"
+	RESOURCE_GITOPS              ResourceType = "GITOPS"
+	RESOURCE_SSO                 ResourceType = "SSO"
+	RESOURCE_NOTIFICATION        ResourceType = "NOTIFICATION"
+	RESOURCE_SESSION             ResourceType = "SESSION"
+	RESOURCE_OTHER               ResourceType = "OTHER"
+)
+
+const (
+	AUDIT_ACTION_CREATE = "CREATE"
+	AUDIT_ACTION_UPDATE = "UPDATE"
+	AUDIT_ACTION_DELETE = "DELETE"
+)
+
+const redactedValue = "*****"
+const truncatedSuffix = "...(truncated)"
+
+// resourcePathPrefixes maps api path prefixes to the resource they mutate, more specific prefixes are listed first
+var resourcePathPrefixes = []struct {
+	prefix       string
+	resourceType ResourceType
+}{
+	{"/orchestrator/app/ci-pipeline", RESOURCE_PIPELINE},
+	{"/orchestrator/app/cd-pipeline", RESOURCE_PIPELINE},
+	{"/orchestrator/app/app-wf", RESOURCE_PIPELINE},
+	{"/orchestrator/app/template", RESOURCE_DEPLOYMENT_TEMPLATE},
+	{"/orchestrator/app/env", RESOURCE_DEPLOYMENT_TEMPLATE},
+	{"/orchestrator/deployment/template", RESOURCE_DEPLOYMENT_TEMPLATE},
+	{"/orchestrator/app", RESOURCE_APP},
+	{"/orchestrator/core", RESOURCE_APP},
+	{"/orchestrator/config/global/cm", RESOURCE_CONFIG_MAP},
+	{"/orchestrator/config/environment/cm", RESOURCE_CONFIG_MAP},
+	{"/orchestrator/config/global/cs", RESOURCE_SECRET},
+	{"/orchestrator/config/environment/cs", RESOURCE_SECRET},
+	{"/orchestrator/config/bulk", RESOURCE_SECRET},
+	{"/orchestrator/global/cm-cs", RESOURCE_SECRET},
+	{"/orchestrator/cluster", RESOURCE_CLUSTER},
+	{"/orchestrator/env", RESOURCE_ENVIRONMENT},
+	{"/orchestrator/team", RESOURCE_TEAM},
+	{"/orchestrator/user/role/group", RESOURCE_ROLE_GROUP},
+	{"/orchestrator/user", RESOURCE_USER},
+	{"/orchestrator/security/cve-exception", RESOURCE_CVE_EXCEPTION},
+	{"/orchestrator/security/policy", RESOURCE_POLICY},
+	{"/orchestrator/api-token", RESOURCE_API_TOKEN},
+	{"/orchestrator/git", RESOURCE_GIT_PROVIDER},
+	{"/orchestrator/docker", RESOURCE_CONTAINER_REGISTRY},
+	{"/orchestrator/gitops", RESOURCE_GITOPS},
+	{"/orchestrator/sso", RESOURCE_SSO},
+	{"/orchestrator/notification", RESOURCE_NOTIFICATION},
+	{"/orchestrator/api/v1/session", RESOURCE_SESSION},
+}
+
+// sensitiveKeyParts are matched against json keys lower cased and stripped of '_' and '-', matching values are redacted
+var sensitiveKeyParts = []string{"password", "token", "privatekey", "clientkey", "accesskey", "secretkey", "apikey", "clientsecret", "credential", "keydata", "certdata"}
+
+// secretDataKeys additionally hold secret values in the payloads of secret resources
+var secretDataKeys = map[string]bool{"data": true, "defaultdata": true, "secretdata": true, "esosecretdata": true, "globaldata": true, "patchjson": true}
+
+// secretTaskKeys hold the secret part of payloads of other resources, e.g. spec.secret of a bulk update, their
+// values are redacted as those of secret resources
+var secretTaskKeys = map[string]bool{"secret": true}
+
+type AuditLogConfig struct {
+	Enabled       bool     `env:"AUDIT_LOG_ENABLED" envDefault:"true"`
+	MaxBodySize   int      `env:"AUDIT_LOG_MAX_BODY_SIZE" envDefault:"65536"`
+	ExportLimit   int      `env:"AUDIT_LOG_EXPORT_LIMIT" envDefault:"10000"`
+	ExcludedPaths []string `env:"AUDIT_LOG_EXCLUDED_PATHS" envDefault:"/orchestrator/webhook,/orchestrator/telemetry,/orchestrator/dashboard-event" envSeparator:","`
+}
+
+func GetAuditLogConfig() (*AuditLogConfig, error) {
+	cfg := &AuditLogConfig{}
+	err := env.Parse(cfg)
+	return cfg, err
+}
+
+type AuditLogService interface {
+	IsEnabled() bool
+	// IsAuditedPath returns false for paths excluded from api auditing like machine to machine webhooks
+	IsAuditedPath(path string) bool
+	// SaveApiAuditLog saves a request captured by the audit middleware, the request body is redacted and truncated
+	SaveApiAuditLog(auditLog *AuditLog, emailId string, requestBody []byte)
+	// SaveResourceAuditLog records the state of a resource before and after a change made by a service,
+	// before is nil for creation and after is nil for deletion
+	SaveResourceAuditLog(resourceType ResourceType, resourceId string, action string, before interface{}, after interface{}, userId int32)
+	GetAuditLogs(filter *AuditLogFilter) (*AuditLogListDto, error)
+	// ExportAuditLogs returns all logs matching the filter up to the configured export limit
+	ExportAuditLogs(filter *AuditLogFilter) ([]*AuditLogDto, error)
+}
+
+type AuditLogServiceImpl struct {
+	logger             *zap.SugaredLogger
+	cfg                *AuditLogConfig
+	auditLogRepository AuditLogRepository
+	userRepository     repository.UserRepository
+}
+
+func NewAuditLogServiceImpl(logger *zap.SugaredLogger,
+	cfg *AuditLogConfig,
+	auditLogRepository AuditLogRepository,
+	userRepository repository.UserRepository) *AuditLogServiceImpl {
+	return &AuditLogServiceImpl{
+		logger:             logger,
+		cfg:                cfg,
+		auditLogRepository: auditLogRepository,
+		userRepository:     userRepository,
+	}
+}
+
+type AuditLogDto struct {
+	Id           int             `json:"id"`
+	Source       AuditLogSource  `json:"source"`
+	UserId       int32           `json:"userId"`
+	EmailId      string          `json:"emailId"`
+	ResourceType ResourceType    `json:"resourceType"`
+	ResourceId   string          `json:"resourceId"`
+	Action       string          `json:"action"`
+	Method       string          `json:"method,omitempty"`
+	Path         string          `json:"path,omitempty"`
+	StatusCode   int             `json:"statusCode,omitempty"`
+	ClientIp     string          `json:"clientIp,omitempty"`
+	RequestBody  string          `json:"requestBody,omitempty"`
+	BeforeState  json.RawMessage `json:"beforeState,omitempty"`
+	AfterState   json.RawMessage `json:"afterState,omitempty"`
+	DurationMs   int64           `json:"durationMs,omitempty"`
+	CreatedOn    time.Time       `json:"createdOn"`
+}
+
+type AuditLogListDto struct {
+	TotalCount int            `json:"totalCount"`
+	AuditLogs  []*AuditLogDto `json:"auditLogs"`
+}
+
+func GetResourceTypeForPath(path string) ResourceType {
+	for _, resourcePath := range resourcePathPrefixes {
+		if strings.HasPrefix(path, resourcePath.prefix) {
+			return resourcePath.resourceType
+		}
+	}
+	return RESOURCE_OTHER
+}
+
+func (impl *AuditLogServiceImpl) IsEnabled() bool {
+	return impl.cfg.Enabled
+}
+
+func (impl *AuditLogServiceImpl) IsAuditedPath(path string) bool {
+	for _, excludedPath := range impl.cfg.ExcludedPaths {
+		if len(excludedPath) > 0 && strings.HasPrefix(path, excludedPath) {
+			return false
+		}
+	}
+	return true
+}
+
+func (impl *AuditLogServiceImpl) SaveApiAuditLog(auditLog *AuditLog, emailId string, requestBody []byte) {
+	if len(requestBody) > 0 {
+		auditLog.RequestBody = impl.redact(requestBody, auditLog.ResourceType)
+	}
+	if len(emailId) > 0 {
+		auditLog.EmailId = emailId
+		user, err := impl.userRepository.FetchActiveOrDeletedUserByEmail(emailId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching user for audit log", "err", err, "emailId", emailId)
+		} else if user != nil {
+			auditLog.UserId = user.Id
+		}
+	}
+	auditLog.Source = AUDIT_LOG_SOURCE_API
+	err := impl.auditLogRepository.Save(auditLog)
+	if err != nil {
+		impl.logger.Errorw("error in saving api audit log", "err", err, "path", auditLog.Path, "method", auditLog.Method)
+	}
+}
+
+func (impl *AuditLogServiceImpl) SaveResourceAuditLog(resourceType ResourceType, resourceId string, action string, before interface{}, after interface{}, userId int32) {
+	if !impl.cfg.Enabled {
+		return
+	}
+	// states are serialised before returning as callers may go on to mutate them
+	auditLog := &AuditLog{
+		Source:       AUDIT_LOG_SOURCE_SERVICE,
+		UserId:       userId,
+		ResourceType: resourceType,
+		ResourceId:   resourceId,
+		Action:       action,
+		BeforeState:  impl.marshalState(before, resourceType),
+		AfterState:   impl.marshalState(after, resourceType),
+		CreatedOn:    time.Now(),
+	}
+	go func() {
+		user, err := impl.userRepository.GetByIdIncludeDeleted(userId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching user for audit log", "err", err, "userId", userId)
+		} else if user != nil {
+			auditLog.EmailId = user.EmailId
+		}
+		err = impl.auditLogRepository.Save(auditLog)
+		if err != nil {
+			impl.logger.Errorw("error in saving resource audit log", "err", err, "resourceType", resourceType, "resourceId", resourceId)
+		}
+	}()
+}
+
+func (impl *AuditLogServiceImpl) GetAuditLogs(filter *AuditLogFilter) (*AuditLogListDto, error) {
+	auditLogs, count, err := impl.auditLogRepository.FindByFilter(filter)
+	if err != nil {
+		impl.logger.Errorw("error in fetching audit logs", "err", err, "filter", filter)
+		return nil, err
+	}
+	return &AuditLogListDto{TotalCount: count, AuditLogs: impl.toDtos(auditLogs)}, nil
+}
+
+func (impl *AuditLogServiceImpl) ExportAuditLogs(filter *AuditLogFilter) ([]*AuditLogDto, error) {
+	filter.Offset = 0
+	filter.Size = impl.cfg.ExportLimit
+	auditLogs, _, err := impl.auditLogRepository.FindByFilter(filter)
+	if err != nil {
+		impl.logger.Errorw("error in fetching audit logs for export", "err", err, "filter", filter)
+		return nil, err
+	}
+	return impl.toDtos(auditLogs), nil
+}
+
+func (impl *AuditLogServiceImpl) toDtos(auditLogs []*AuditLog) []*AuditLogDto {
+	dtos := make([]*AuditLogDto, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		dtos = append(dtos, &AuditLogDto{
+			Id:           auditLog.Id,
+			Source:       auditLog.Source,
+			UserId:       auditLog.UserId,
+			EmailId:      auditLog.EmailId,
+			ResourceType: auditLog.ResourceType,
+			ResourceId:   auditLog.ResourceId,
+			Action:       auditLog.Action,
+			Method:       auditLog.Method,
+			Path:         auditLog.Path,
+			StatusCode:   auditLog.StatusCode,
+			ClientIp:     auditLog.ClientIp,
+			RequestBody:  auditLog.RequestBody,
+			BeforeState:  rawState(auditLog.BeforeState),
+			AfterState:   rawState(auditLog.AfterState),
+			DurationMs:   auditLog.DurationMs,
+			CreatedOn:    auditLog.CreatedOn,
+		})
+	}
+	return dtos
+}
+
+func (impl *AuditLogServiceImpl) marshalState(state interface{}, resourceType ResourceType) string {
+	if state == nil {
+		return ""
+	}
+	stateJson, err := json.Marshal(state)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling state for audit log", "err", err, "resourceType", resourceType)
+		return ""
+	}
+	return impl.redact(stateJson, resourceType)
+}
+
+// redact masks sensitive values of a json payload and truncates it to the configured size, payloads which are not
+// json are only kept for resources which can not carry secrets
+func (impl *AuditLogServiceImpl) redact(payload []byte, resourceType ResourceType) string {
+	var value interface{}
+	err := json.Unmarshal(payload, &value)
+	if err != nil {
+		if resourceType == RESOURCE_SECRET {
+			return redactedValue
+		}
+		return impl.truncate(string(payload))
+	}
+	redacted, err := json.Marshal(redactValue(value, resourceType == RESOURCE_SECRET))
+	if err != nil {
+		impl.logger.Errorw("error in marshalling redacted payload for audit log", "err", err, "resourceType", resourceType)
+		return ""
+	}
+	return impl.truncate(string(redacted))
+}
+
+func (impl *AuditLogServiceImpl) truncate(payload string) string {
+	if impl.cfg.MaxBodySize > 0 && len(payload) > impl.cfg.MaxBodySize {
+		return payload[:impl.cfg.MaxBodySize] + truncatedSuffix
+	}
+	return payload
+}
+
+func redactValue(value interface{}, isSecret bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveKey(key, isSecret) {
+				v[key] = redactedValue
+			} else if secretTaskKeys[normaliseKey(key)] {
+				v[key] = redactSecretTask(item)
+			} else {
+				v[key] = redactValue(item, isSecret)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, isSecret)
+		}
+	}
+	return value
+}
+
+// redactSecretTask redacts a secret task as a secret payload, scalar values are secret values themselves
+func redactSecretTask(value interface{}) interface{} {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return redactValue(value, true)
+	case nil:
+		return value
+	}
+	return redactedValue
+}
+
+func isSensitiveKey(key string, isSecret bool) bool {
+	normalisedKey := normaliseKey(key)
+	if isSecret && secretDataKeys[normalisedKey] {
+		return true
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(normalisedKey, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func normaliseKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// rawState returns the stored state as raw json so that it is not double encoded in responses, truncated states are
+// no longer valid json and are returned as a json string instead
+func rawState(state string) json.RawMessage {
+	if len(state) == 0 {
+		return nil
+	}
+	if json.Valid([]byte(state)) {
+		return json.RawMessage(state)
+	}
+	quoted, _ := json.Marshal(state)
+	return quoted
+}
@@ -108,6 +108,18 @@ type CiPipeline struct {
 	TargetPlatform           string                 `json:"targetPlatform,omitempty"`
 	IsDockerConfigOverridden bool                   `json:"isDockerConfigOverridden"`
 	DockerConfigOverride     DockerConfigOverride   `json:"dockerConfigOverride,omitempty"`
+	Schedule                 *CiPipelineSchedule    `json:"schedule,omitempty"`
+}
+
+// CiPipelineSchedule triggers builds of the latest commits on a cron schedule, evaluated in Timezone
+type CiPipelineSchedule struct {
+	CronExpression   string     `json:"cronExpression"`
+	Timezone         string     `json:"timezone"`
+	Branch           string     `json:"branch"` //restricts the new commits check to materials on this branch, all branch materials if empty
+	OnlyOnNewCommits bool       `json:"onlyOnNewCommits"`
+	Active           bool       `json:"active"`
+	NextRunOn        *time.Time `json:"nextRunOn,omitempty"`
+	LastTriggeredOn  *time.Time `json:"lastTriggeredOn,omitempty"`
 }
 
 type DockerConfigOverride struct {
@@ -459,6 +471,8 @@ type CDPipelineConfigObject struct {
 	ParentPipelineId              int                               `json:"parentPipelineId"`
 	ParentPipelineType            string                            `json:"parentPipelineType"`
 	DeploymentAppType             string                            `json:"deploymentAppType"`
+	PreDeployStage                *bean.PipelineStageDto            `json:"preDeployStage,omitempty"`
+	PostDeployStage               *bean.PipelineStageDto            `json:"postDeployStage,omitempty"`
 	//Downstream         []int                             `json:"downstream"` //PipelineCounter of downstream	(for future reference only)
 }
 
@@ -0,0 +1,508 @@
+package bulkAction
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/repository/bulkUpdate"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository4 "github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/go-pg/pg"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type bulkOperationItemProcessor func(item *bulkUpdate.BulkOperationJobItem) (bulkUpdate.BulkOperationItemStatus, string)
+
+func (impl BulkUpdateServiceImpl) createBulkOperationJob(operation bulkUpdate.BulkOperationType, payload interface{}, items []*bulkUpdate.BulkOperationJobItem,
+	userId int32, revertedJobId int) (*bulkUpdate.BulkOperationJob, error) {
+	payloadJson, err := json.Marshal(payload)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling bulk operation payload", "err", err, "operation", operation)
+		return nil, err
+	}
+	dbConnection := impl.bulkOperationJobRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	job := &bulkUpdate.BulkOperationJob{
+		Operation:     operation,
+		Status:        bulkUpdate.BULK_JOB_IN_PROGRESS,
+		Payload:       string(payloadJson),
+		RevertedJobId: revertedJobId,
+		AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: userId, UpdatedOn: time.Now(), UpdatedBy: userId},
+	}
+	err = impl.bulkOperationJobRepository.SaveJob(job, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving bulk operation job", "err", err, "operation", operation)
+		return nil, err
+	}
+	for _, item := range items {
+		item.JobId = job.Id
+		item.AuditLog = sql.AuditLog{CreatedOn: time.Now(), CreatedBy: userId, UpdatedOn: time.Now(), UpdatedBy: userId}
+	}
+	err = impl.bulkOperationJobRepository.SaveItems(items, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving bulk operation job items", "err", err, "jobId", job.Id)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// processBulkOperationJob runs pending items one by one, items cancelled in between are not picked
+func (impl BulkUpdateServiceImpl) processBulkOperationJob(jobId int, items []*bulkUpdate.BulkOperationJobItem, userId int32, processItem bulkOperationItemProcessor) {
+	for _, item := range items {
+		if item.Status != bulkUpdate.BULK_ITEM_PENDING {
+			continue
+		}
+		picked, err := impl.bulkOperationJobRepository.MarkItemInProgressIfPending(item.Id, userId)
+		if err != nil {
+			impl.logger.Errorw("error in marking bulk operation item in progress", "err", err, "itemId", item.Id)
+			continue
+		}
+		if !picked {
+			//job cancelled
+			continue
+		}
+		item.Status, item.Message = processItem(item)
+		item.UpdatedOn = time.Now()
+		item.UpdatedBy = userId
+		err = impl.bulkOperationJobRepository.UpdateItem(item)
+		if err != nil {
+			impl.logger.Errorw("error in updating bulk operation item", "err", err, "itemId", item.Id)
+		}
+	}
+	_, err := impl.bulkOperationJobRepository.UpdateJobStatusIfInProgress(jobId, bulkUpdate.BULK_JOB_COMPLETED, userId)
+	if err != nil {
+		impl.logger.Errorw("error in marking bulk operation job completed", "err", err, "jobId", jobId)
+	}
+}
+
+func (impl BulkUpdateServiceImpl) buildBulkOperationJobResponse(job *bulkUpdate.BulkOperationJob, items []*bulkUpdate.BulkOperationJobItem) *BulkOperationJobResponse {
+	progress := &BulkOperationJobProgress{Total: len(items)}
+	itemDtos := make([]*BulkOperationJobItemDto, 0, len(items))
+	for _, item := range items {
+		switch item.Status {
+		case bulkUpdate.BULK_ITEM_PENDING:
+			progress.Pending++
+		case bulkUpdate.BULK_ITEM_IN_PROGRESS:
+			progress.InProgress++
+		case bulkUpdate.BULK_ITEM_SUCCEEDED:
+			progress.Succeeded++
+		case bulkUpdate.BULK_ITEM_FAILED:
+			progress.Failed++
+		case bulkUpdate.BULK_ITEM_SKIPPED:
+			progress.Skipped++
+		case bulkUpdate.BULK_ITEM_CANCELLED:
+			progress.Cancelled++
+		}
+		itemDto := &BulkOperationJobItemDto{
+			Id:           item.Id,
+			ItemType:     item.ItemType,
+			AppId:        item.AppId,
+			AppName:      item.AppName,
+			EnvId:        item.EnvId,
+			PipelineId:   item.PipelineId,
+			PipelineName: item.PipelineName,
+			Status:       item.Status,
+			Message:      item.Message,
+		}
+		if len(item.Names) > 0 {
+			itemDto.Names = strings.Split(item.Names, ",")
+		}
+		itemDtos = append(itemDtos, itemDto)
+	}
+	return &BulkOperationJobResponse{
+		JobId:         job.Id,
+		Operation:     job.Operation,
+		Status:        job.Status,
+		RevertedJobId: job.RevertedJobId,
+		Progress:      progress,
+		Items:         itemDtos,
+		CreatedBy:     job.CreatedBy,
+		CreatedOn:     job.CreatedOn,
+		UpdatedOn:     job.UpdatedOn,
+	}
+}
+
+// buildBulkApplicationForEnvironmentResponse keeps the response of bulk actions on an environment keyed as before
+// they were run through jobs, every item still pending is reported as true
+func buildBulkApplicationForEnvironmentResponse(request *BulkApplicationForEnvironmentPayload, job *bulkUpdate.BulkOperationJob,
+	items []*bulkUpdate.BulkOperationJobItem) *BulkApplicationForEnvironmentResponse {
+	response := make(map[string]map[string]bool)
+	for _, item := range items {
+		appKey := fmt.Sprintf("%d_%s", item.AppId, item.AppName)
+		pipelineKey := fmt.Sprintf("%d_%s", item.PipelineId, item.PipelineName)
+		if item.ItemType == bulkUpdate.BULK_ITEM_CI_PIPELINE {
+			pipelineKey = fmt.Sprintf("%d", item.PipelineId)
+		}
+		if _, ok := response[appKey]; !ok {
+			response[appKey] = make(map[string]bool)
+		}
+		response[appKey][pipelineKey] = item.Status == bulkUpdate.BULK_ITEM_PENDING
+	}
+	return &BulkApplicationForEnvironmentResponse{
+		BulkApplicationForEnvironmentPayload: *request,
+		Response:                             response,
+		JobId:                                job.Id,
+	}
+}
+
+func (impl BulkUpdateServiceImpl) GetBulkOperationJob(jobId int) (*BulkOperationJobResponse, error) {
+	job, err := impl.bulkOperationJobRepository.FindJobById(jobId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching bulk operation job", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	items, err := impl.bulkOperationJobRepository.FindItemsByJobId(jobId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching bulk operation job items", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	return impl.buildBulkOperationJobResponse(job, items), nil
+}
+
+// CancelBulkOperationJob stops the job from picking any further item, items already in progress are left to finish
+func (impl BulkUpdateServiceImpl) CancelBulkOperationJob(jobId int, userId int32) (*BulkOperationJobResponse, error) {
+	job, err := impl.bulkOperationJobRepository.FindJobById(jobId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching bulk operation job", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	cancelled, err := impl.bulkOperationJobRepository.UpdateJobStatusIfInProgress(jobId, bulkUpdate.BULK_JOB_CANCELLED, userId)
+	if err != nil {
+		impl.logger.Errorw("error in cancelling bulk operation job", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	if !cancelled {
+		return nil, &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: "bulk operation job is not in progress",
+			UserMessage:     fmt.Sprintf("bulk operation job %d is already finished", job.Id),
+		}
+	}
+	err = impl.bulkOperationJobRepository.CancelPendingItemsByJobId(jobId, userId)
+	if err != nil {
+		impl.logger.Errorw("error in cancelling pending items of bulk operation job", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	return impl.GetBulkOperationJob(jobId)
+}
+
+// FailStaleBulkOperationJobs completes the jobs left in progress by a restart, detected by no item being updated for
+// staleAfter. Pending items are cancelled first so that a job still running elsewhere stops picking them
+func (impl BulkUpdateServiceImpl) FailStaleBulkOperationJobs(staleAfter time.Duration) {
+	jobs, err := impl.bulkOperationJobRepository.FindStaleInProgressJobs(time.Now().Add(-staleAfter))
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching stale bulk operation jobs", "err", err)
+		return
+	}
+	for _, job := range jobs {
+		impl.logger.Infow("failing stale bulk operation job", "jobId", job.Id, "operation", job.Operation)
+		err = impl.bulkOperationJobRepository.CancelPendingItemsByJobId(job.Id, 1)
+		if err != nil {
+			impl.logger.Errorw("error in cancelling pending items of bulk operation job", "err", err, "jobId", job.Id)
+			continue
+		}
+		err = impl.bulkOperationJobRepository.FailInProgressItemsByJobId(job.Id, "interrupted before completion", 1)
+		if err != nil {
+			impl.logger.Errorw("error in failing in progress items of bulk operation job", "err", err, "jobId", job.Id)
+			continue
+		}
+		_, err = impl.bulkOperationJobRepository.UpdateJobStatusIfInProgress(job.Id, bulkUpdate.BULK_JOB_COMPLETED, 1)
+		if err != nil {
+			impl.logger.Errorw("error in marking bulk operation job completed", "err", err, "jobId", job.Id)
+		}
+	}
+}
+
+// RevertBulkOperationJob creates a job restoring the values held before a bulk edit job, from the history tables
+func (impl BulkUpdateServiceImpl) RevertBulkOperationJob(jobId int, userId int32) (*BulkOperationJobResponse, error) {
+	job, err := impl.bulkOperationJobRepository.FindJobById(jobId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching bulk operation job", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	if job.Operation != bulkUpdate.BULK_OPERATION_EDIT {
+		return nil, &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: "revert is only supported for bulk edit jobs",
+			UserMessage:     fmt.Sprintf("revert is not supported for %s jobs", job.Operation),
+		}
+	}
+	editedItems, err := impl.bulkOperationJobRepository.FindItemsByJobId(jobId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching bulk operation job items", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	var items []*bulkUpdate.BulkOperationJobItem
+	for _, editedItem := range editedItems {
+		if editedItem.Status != bulkUpdate.BULK_ITEM_SUCCEEDED {
+			continue
+		}
+		item := &bulkUpdate.BulkOperationJobItem{
+			ItemType:     editedItem.ItemType,
+			AppId:        editedItem.AppId,
+			AppName:      editedItem.AppName,
+			EnvId:        editedItem.EnvId,
+			PipelineId:   editedItem.PipelineId,
+			PipelineName: editedItem.PipelineName,
+			Names:        editedItem.Names,
+			HistoryId:    editedItem.HistoryId,
+			Status:       bulkUpdate.BULK_ITEM_PENDING,
+		}
+		if item.HistoryId == 0 {
+			item.Status = bulkUpdate.BULK_ITEM_SKIPPED
+			item.Message = "no previous version found in history"
+		}
+		items = append(items, item)
+	}
+	revertJob, err := impl.createBulkOperationJob(bulkUpdate.BULK_OPERATION_REVERT, map[string]int{"jobId": jobId}, items, userId, jobId)
+	if err != nil {
+		return nil, err
+	}
+	response := impl.buildBulkOperationJobResponse(revertJob, items)
+	go impl.processBulkOperationJob(revertJob.Id, items, userId, func(item *bulkUpdate.BulkOperationJobItem) (bulkUpdate.BulkOperationItemStatus, string) {
+		var err error
+		switch item.ItemType {
+		case bulkUpdate.BULK_ITEM_DEPLOYMENT_TEMPLATE:
+			err = impl.revertDeploymentTemplate(item)
+		case bulkUpdate.BULK_ITEM_CONFIG_MAP:
+			err = impl.revertCmAndSecret(item, repository4.CONFIGMAP_TYPE)
+		case bulkUpdate.BULK_ITEM_SECRET:
+			if impl.enforcerUtil.IsSecretActionDenied(userId, casbin.ActionUpdate, item.AppId, item.EnvId) {
+				return bulkUpdate.BULK_ITEM_FAILED, secretUpdateDeniedMessage
+			}
+			err = impl.revertCmAndSecret(item, repository4.SECRET_TYPE)
+		default:
+			return bulkUpdate.BULK_ITEM_SKIPPED, fmt.Sprintf("revert not supported for %s", item.ItemType)
+		}
+		if err != nil {
+			return bulkUpdate.BULK_ITEM_FAILED, err.Error()
+		}
+		return bulkUpdate.BULK_ITEM_SUCCEEDED, "Reverted Successfully"
+	})
+	return response, nil
+}
+
+func (impl BulkUpdateServiceImpl) revertDeploymentTemplate(item *bulkUpdate.BulkOperationJobItem) error {
+	history, err := impl.deploymentTemplateHistoryRepository.GetHistoryById(item.HistoryId)
+	if err != nil {
+		return err
+	}
+	if item.EnvId == 0 {
+		chart, err := impl.chartRepository.FindLatestChartForAppByAppId(item.AppId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching chart", "err", err, "appId", item.AppId)
+			return err
+		}
+		err = impl.bulkUpdateRepository.BulkUpdateChartsValuesYamlAndGlobalOverrideById(chart.Id, history.Template)
+		if err != nil {
+			impl.logger.Errorw("error in reverting chart values", "err", err, "chartId", chart.Id)
+			return err
+		}
+		impl.createGlobalDeploymentTemplateHistory(chart, history.Template)
+		return nil
+	}
+	chartEnv, err := impl.envOverrideRepository.FindLatestChartForAppByAppIdAndEnvId(item.AppId, item.EnvId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching env override", "err", err, "appId", item.AppId, "envId", item.EnvId)
+		return err
+	}
+	err = impl.bulkUpdateRepository.BulkUpdateChartsEnvYamlOverrideById(chartEnv.Id, history.Template)
+	if err != nil {
+		impl.logger.Errorw("error in reverting env override values", "err", err, "envOverrideId", chartEnv.Id)
+		return err
+	}
+	impl.createEnvDeploymentTemplateHistory(chartEnv, history.Template)
+	return nil
+}
+
+func (impl BulkUpdateServiceImpl) revertCmAndSecret(item *bulkUpdate.BulkOperationJobItem, configType repository4.ConfigType) error {
+	history, err := impl.configMapHistoryRepository.GetHistoryById(item.HistoryId)
+	if err != nil {
+		return err
+	}
+	listKey := "maps"
+	if configType == repository4.SECRET_TYPE {
+		listKey = "secrets"
+	}
+	names := strings.Split(item.Names, ",")
+	if item.EnvId == 0 {
+		configMapAppModel, err := impl.configMapRepository.GetByAppIdAppLevel(item.AppId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching app level config", "err", err, "appId", item.AppId)
+			return err
+		}
+		if configType == repository4.SECRET_TYPE {
+			configMapAppModel.SecretData, err = restoreCmAndSecretData(configMapAppModel.SecretData, history.Data, listKey, names)
+			if err == nil {
+				err = impl.bulkUpdateRepository.BulkUpdateSecretDataForGlobalById(configMapAppModel.Id, configMapAppModel.SecretData)
+			}
+		} else {
+			configMapAppModel.ConfigMapData, err = restoreCmAndSecretData(configMapAppModel.ConfigMapData, history.Data, listKey, names)
+			if err == nil {
+				err = impl.bulkUpdateRepository.BulkUpdateConfigMapDataForGlobalById(configMapAppModel.Id, configMapAppModel.ConfigMapData)
+			}
+		}
+		if err != nil {
+			impl.logger.Errorw("error in reverting app level config", "err", err, "appId", item.AppId)
+			return err
+		}
+		err = impl.configMapHistoryService.CreateHistoryFromAppLevelConfig(configMapAppModel, configType)
+		if err != nil {
+			impl.logger.Errorw("error in creating entry for configmap/secret history", "err", err)
+		}
+		return nil
+	}
+	configMapEnvModel, err := impl.configMapRepository.GetByAppIdAndEnvIdEnvLevel(item.AppId, item.EnvId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching env level config", "err", err, "appId", item.AppId, "envId", item.EnvId)
+		return err
+	}
+	if configType == repository4.SECRET_TYPE {
+		configMapEnvModel.SecretData, err = restoreCmAndSecretData(configMapEnvModel.SecretData, history.Data, listKey, names)
+		if err == nil {
+			err = impl.bulkUpdateRepository.BulkUpdateSecretDataForEnvById(configMapEnvModel.Id, configMapEnvModel.SecretData)
+		}
+	} else {
+		configMapEnvModel.ConfigMapData, err = restoreCmAndSecretData(configMapEnvModel.ConfigMapData, history.Data, listKey, names)
+		if err == nil {
+			err = impl.bulkUpdateRepository.BulkUpdateConfigMapDataForEnvById(configMapEnvModel.Id, configMapEnvModel.ConfigMapData)
+		}
+	}
+	if err != nil {
+		impl.logger.Errorw("error in reverting env level config", "err", err, "appId", item.AppId, "envId", item.EnvId)
+		return err
+	}
+	err = impl.configMapHistoryService.CreateHistoryFromEnvLevelConfig(configMapEnvModel, configType)
+	if err != nil {
+		impl.logger.Errorw("error in creating entry for configmap/secret history", "err", err)
+	}
+	return nil
+}
+
+// restoreCmAndSecretData replaces data of the named configmaps/secrets in current with their data in previous
+func restoreCmAndSecretData(current string, previous string, listKey string, names []string) (string, error) {
+	for _, name := range names {
+		previousData, found := gjson.Result{}, false
+		for _, config := range gjson.Get(previous, listKey).Array() {
+			if config.Get("name").String() == name {
+				previousData, found = config.Get("data"), true
+				break
+			}
+		}
+		if !found {
+			return current, fmt.Errorf("%s not found in history", name)
+		}
+		index := -1
+		for i, currentName := range gjson.Get(current, listKey+".#.name").Array() {
+			if currentName.String() == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return current, fmt.Errorf("%s not found", name)
+		}
+		var err error
+		current, err = sjson.SetRaw(current, fmt.Sprintf("%s.%d.data", listKey, index), previousData.Raw)
+		if err != nil {
+			return current, err
+		}
+	}
+	return current, nil
+}
+
+// recordBulkEditJob persists the objects changed by a bulk edit along with the history entry holding their previous
+// values, history entries newer than the watermarks were created by the edit itself
+func (impl BulkUpdateServiceImpl) recordBulkEditJob(bulkUpdatePayload *BulkUpdatePayload, bulkUpdateResponse *BulkUpdateResponse,
+	deploymentTemplateHistoryWatermark int, configMapHistoryWatermark int) (*bulkUpdate.BulkOperationJob, error) {
+	var items []*bulkUpdate.BulkOperationJobItem
+	if bulkUpdateResponse.DeploymentTemplate != nil {
+		for _, updated := range bulkUpdateResponse.DeploymentTemplate.Successful {
+			item := &bulkUpdate.BulkOperationJobItem{
+				ItemType: bulkUpdate.BULK_ITEM_DEPLOYMENT_TEMPLATE,
+				AppId:    updated.AppId,
+				AppName:  updated.AppName,
+				EnvId:    updated.EnvId,
+				Status:   bulkUpdate.BULK_ITEM_SUCCEEDED,
+				Message:  updated.Message,
+			}
+			history, err := impl.deploymentTemplateHistoryRepository.GetLatestSavedHistoryByAppIdAndEnvId(updated.AppId, updated.EnvId, deploymentTemplateHistoryWatermark)
+			if err != nil && err != pg.ErrNoRows {
+				return nil, err
+			} else if err == nil {
+				item.HistoryId = history.Id
+			}
+			items = append(items, item)
+		}
+	}
+	cmAndSecretResponses := map[bulkUpdate.BulkOperationItemType]*CmAndSecretBulkUpdateResponse{
+		bulkUpdate.BULK_ITEM_CONFIG_MAP: bulkUpdateResponse.ConfigMap,
+		bulkUpdate.BULK_ITEM_SECRET:     bulkUpdateResponse.Secret,
+	}
+	for itemType, response := range cmAndSecretResponses {
+		if response == nil {
+			continue
+		}
+		configType := repository4.CONFIGMAP_TYPE
+		if itemType == bulkUpdate.BULK_ITEM_SECRET {
+			configType = repository4.SECRET_TYPE
+		}
+		for _, updated := range response.Successful {
+			item := &bulkUpdate.BulkOperationJobItem{
+				ItemType: itemType,
+				AppId:    updated.AppId,
+				AppName:  updated.AppName,
+				EnvId:    updated.EnvId,
+				Names:    strings.Join(updated.Names, ","),
+				Status:   bulkUpdate.BULK_ITEM_SUCCEEDED,
+				Message:  updated.Message,
+			}
+			//env level history is maintained per pipeline
+			pipelineId := 0
+			if updated.EnvId > 0 {
+				pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(updated.AppId, updated.EnvId)
+				if err != nil && err != pg.ErrNoRows {
+					return nil, err
+				}
+				if len(pipelines) > 0 {
+					pipelineId = pipelines[0].Id
+				}
+			}
+			if updated.EnvId == 0 || pipelineId > 0 {
+				history, err := impl.configMapHistoryRepository.GetLatestSavedHistoryByAppIdAndPipelineId(updated.AppId, pipelineId, configType, configMapHistoryWatermark)
+				if err != nil && err != pg.ErrNoRows {
+					return nil, err
+				} else if err == nil {
+					item.HistoryId = history.Id
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	job, err := impl.createBulkOperationJob(bulkUpdate.BULK_OPERATION_EDIT, bulkUpdatePayload, items, bulkUpdatePayload.UserId, 0)
+	if err != nil {
+		return nil, err
+	}
+	_, err = impl.bulkOperationJobRepository.UpdateJobStatusIfInProgress(job.Id, bulkUpdate.BULK_JOB_COMPLETED, bulkUpdatePayload.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -1,6 +1,7 @@
 package bulkAction
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -24,16 +25,23 @@ import (
 	pipeline1 "github.com/devtron-labs/devtron/pkg/pipeline"
 	"github.com/devtron-labs/devtron/pkg/pipeline/history"
 	repository4 "github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	"github.com/devtron-labs/devtron/util/rbac"
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/go-pg/pg"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"go.uber.org/zap"
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 )
 
+// secretUpdateDeniedMessage is the failure of secrets the user is denied to update through deny rules
+const secretUpdateDeniedMessage = "Secret update is denied for the user"
+
 type BulkUpdateService interface {
 	FindBulkUpdateReadme(operation string) (response *BulkUpdateSeeExampleResponse, err error)
 	GetBulkAppName(bulkUpdateRequest *BulkUpdatePayload) (*ImpactedObjectsResponse, error)
@@ -42,47 +50,57 @@ type BulkUpdateService interface {
 	BulkUpdateConfigMap(bulkUpdatePayload *BulkUpdatePayload) *CmAndSecretBulkUpdateResponse
 	BulkUpdateSecret(bulkUpdatePayload *BulkUpdatePayload) *CmAndSecretBulkUpdateResponse
 	BulkUpdate(bulkUpdateRequest *BulkUpdatePayload) (bulkUpdateResponse *BulkUpdateResponse)
+	BulkUpdateDryRun(bulkUpdatePayload *BulkUpdatePayload) *BulkUpdateDryRunResponse
+
+	BulkHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
+	BulkUnHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
+	BulkDeploy(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
+	BulkBuildTrigger(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
 
-	BulkHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
-	BulkUnHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
-	BulkDeploy(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
-	BulkBuildTrigger(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
+	GetBulkOperationJob(jobId int) (*BulkOperationJobResponse, error)
+	CancelBulkOperationJob(jobId int, userId int32) (*BulkOperationJobResponse, error)
+	RevertBulkOperationJob(jobId int, userId int32) (*BulkOperationJobResponse, error)
+	FailStaleBulkOperationJobs(staleAfter time.Duration)
 
 	GetBulkActionImpactedPipelinesAndWfs(dto *CdBulkActionRequestDto) ([]*pipelineConfig.Pipeline, []int, []int, error)
 	PerformBulkActionOnCdPipelines(dto *CdBulkActionRequestDto, impactedPipelines []*pipelineConfig.Pipeline, ctx context.Context, dryRun bool, impactedAppWfIds []int, impactedCiPipelineIds []int) (*PipelineAndWfBulkActionResponseDto, error)
 }
 
 type BulkUpdateServiceImpl struct {
-	bulkUpdateRepository             bulkUpdate.BulkUpdateRepository
-	chartRepository                  chartRepoRepository.ChartRepository
-	logger                           *zap.SugaredLogger
-	repoRepository                   chartRepoRepository.ChartRepoRepository
-	chartTemplateService             util.ChartTemplateService
-	mergeUtil                        util.MergeUtil
-	repositoryService                repository.ServiceClient
-	defaultChart                     chart.DefaultChart
-	chartRefRepository               chartRepoRepository.ChartRefRepository
-	envOverrideRepository            chartConfig.EnvConfigOverrideRepository
-	pipelineConfigRepository         chartConfig.PipelineConfigRepository
-	configMapRepository              chartConfig.ConfigMapRepository
-	environmentRepository            repository2.EnvironmentRepository
-	pipelineRepository               pipelineConfig.PipelineRepository
-	appLevelMetricsRepository        repository3.AppLevelMetricsRepository
-	envLevelAppMetricsRepository     repository3.EnvLevelAppMetricsRepository
-	client                           *http.Client
-	appRepository                    app.AppRepository
-	deploymentTemplateHistoryService history.DeploymentTemplateHistoryService
-	configMapHistoryService          history.ConfigMapHistoryService
-	workflowDagExecutor              pipeline.WorkflowDagExecutor
-	cdWorkflowRepository             pipelineConfig.CdWorkflowRepository
-	pipelineBuilder                  pipeline.PipelineBuilder
-	helmAppService                   client.HelmAppService
-	enforcerUtil                     rbac.EnforcerUtil
-	enforcerUtilHelm                 rbac.EnforcerUtilHelm
-	ciHandler                        pipeline.CiHandler
-	ciPipelineRepository             pipelineConfig.CiPipelineRepository
-	appWorkflowRepository            appWorkflow.AppWorkflowRepository
-	appWorkflowService               appWorkflow2.AppWorkflowService
+	bulkUpdateRepository                bulkUpdate.BulkUpdateRepository
+	chartRepository                     chartRepoRepository.ChartRepository
+	logger                              *zap.SugaredLogger
+	repoRepository                      chartRepoRepository.ChartRepoRepository
+	chartTemplateService                util.ChartTemplateService
+	mergeUtil                           util.MergeUtil
+	repositoryService                   repository.ServiceClient
+	defaultChart                        chart.DefaultChart
+	chartRefRepository                  chartRepoRepository.ChartRefRepository
+	envOverrideRepository               chartConfig.EnvConfigOverrideRepository
+	pipelineConfigRepository            chartConfig.PipelineConfigRepository
+	configMapRepository                 chartConfig.ConfigMapRepository
+	environmentRepository               repository2.EnvironmentRepository
+	pipelineRepository                  pipelineConfig.PipelineRepository
+	appLevelMetricsRepository           repository3.AppLevelMetricsRepository
+	envLevelAppMetricsRepository        repository3.EnvLevelAppMetricsRepository
+	client                              *http.Client
+	appRepository                       app.AppRepository
+	deploymentTemplateHistoryService    history.DeploymentTemplateHistoryService
+	configMapHistoryService             history.ConfigMapHistoryService
+	workflowDagExecutor                 pipeline.WorkflowDagExecutor
+	cdWorkflowRepository                pipelineConfig.CdWorkflowRepository
+	pipelineBuilder                     pipeline.PipelineBuilder
+	helmAppService                      client.HelmAppService
+	enforcerUtil                        rbac.EnforcerUtil
+	enforcerUtilHelm                    rbac.EnforcerUtilHelm
+	ciHandler                           pipeline.CiHandler
+	ciPipelineRepository                pipelineConfig.CiPipelineRepository
+	appWorkflowRepository               appWorkflow.AppWorkflowRepository
+	appWorkflowService                  appWorkflow2.AppWorkflowService
+	chartService                        chart.ChartService
+	bulkOperationJobRepository          bulkUpdate.BulkOperationJobRepository
+	deploymentTemplateHistoryRepository repository4.DeploymentTemplateHistoryRepository
+	configMapHistoryRepository          repository4.ConfigMapHistoryRepository
 }
 
 func NewBulkUpdateServiceImpl(bulkUpdateRepository bulkUpdate.BulkUpdateRepository,
@@ -110,38 +128,46 @@ func NewBulkUpdateServiceImpl(bulkUpdateRepository bulkUpdate.BulkUpdateReposito
 	enforcerUtilHelm rbac.EnforcerUtilHelm, ciHandler pipeline.CiHandler,
 	ciPipelineRepository pipelineConfig.CiPipelineRepository,
 	appWorkflowRepository appWorkflow.AppWorkflowRepository,
-	appWorkflowService appWorkflow2.AppWorkflowService) *BulkUpdateServiceImpl {
+	appWorkflowService appWorkflow2.AppWorkflowService,
+	chartService chart.ChartService,
+	bulkOperationJobRepository bulkUpdate.BulkOperationJobRepository,
+	deploymentTemplateHistoryRepository repository4.DeploymentTemplateHistoryRepository,
+	configMapHistoryRepository repository4.ConfigMapHistoryRepository) *BulkUpdateServiceImpl {
 	return &BulkUpdateServiceImpl{
-		bulkUpdateRepository:             bulkUpdateRepository,
-		chartRepository:                  chartRepository,
-		logger:                           logger,
-		chartTemplateService:             chartTemplateService,
-		repoRepository:                   repoRepository,
-		mergeUtil:                        mergeUtil,
-		defaultChart:                     defaultChart,
-		repositoryService:                repositoryService,
-		chartRefRepository:               chartRefRepository,
-		envOverrideRepository:            envOverrideRepository,
-		pipelineConfigRepository:         pipelineConfigRepository,
-		configMapRepository:              configMapRepository,
-		environmentRepository:            environmentRepository,
-		pipelineRepository:               pipelineRepository,
-		appLevelMetricsRepository:        appLevelMetricsRepository,
-		envLevelAppMetricsRepository:     envLevelAppMetricsRepository,
-		client:                           client,
-		appRepository:                    appRepository,
-		deploymentTemplateHistoryService: deploymentTemplateHistoryService,
-		configMapHistoryService:          configMapHistoryService,
-		workflowDagExecutor:              workflowDagExecutor,
-		cdWorkflowRepository:             cdWorkflowRepository,
-		pipelineBuilder:                  pipelineBuilder,
-		helmAppService:                   helmAppService,
-		enforcerUtil:                     enforcerUtil,
-		enforcerUtilHelm:                 enforcerUtilHelm,
-		ciHandler:                        ciHandler,
-		ciPipelineRepository:             ciPipelineRepository,
-		appWorkflowRepository:            appWorkflowRepository,
-		appWorkflowService:               appWorkflowService,
+		bulkUpdateRepository:                bulkUpdateRepository,
+		chartRepository:                     chartRepository,
+		logger:                              logger,
+		chartTemplateService:                chartTemplateService,
+		repoRepository:                      repoRepository,
+		mergeUtil:                           mergeUtil,
+		defaultChart:                        defaultChart,
+		repositoryService:                   repositoryService,
+		chartRefRepository:                  chartRefRepository,
+		envOverrideRepository:               envOverrideRepository,
+		pipelineConfigRepository:            pipelineConfigRepository,
+		configMapRepository:                 configMapRepository,
+		environmentRepository:               environmentRepository,
+		pipelineRepository:                  pipelineRepository,
+		appLevelMetricsRepository:           appLevelMetricsRepository,
+		envLevelAppMetricsRepository:        envLevelAppMetricsRepository,
+		client:                              client,
+		appRepository:                       appRepository,
+		deploymentTemplateHistoryService:    deploymentTemplateHistoryService,
+		configMapHistoryService:             configMapHistoryService,
+		workflowDagExecutor:                 workflowDagExecutor,
+		cdWorkflowRepository:                cdWorkflowRepository,
+		pipelineBuilder:                     pipelineBuilder,
+		helmAppService:                      helmAppService,
+		enforcerUtil:                        enforcerUtil,
+		enforcerUtilHelm:                    enforcerUtilHelm,
+		ciHandler:                           ciHandler,
+		ciPipelineRepository:                ciPipelineRepository,
+		appWorkflowRepository:               appWorkflowRepository,
+		appWorkflowService:                  appWorkflowService,
+		chartService:                        chartService,
+		bulkOperationJobRepository:          bulkOperationJobRepository,
+		deploymentTemplateHistoryRepository: deploymentTemplateHistoryRepository,
+		configMapHistoryRepository:          configMapHistoryRepository,
 	}
 }
 
@@ -428,19 +454,7 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 							deploymentTemplateBulkUpdateResponse.Successful = append(deploymentTemplateBulkUpdateResponse.Successful, bulkUpdateSuccessResponse)
 
 							//creating history entry for deployment template
-							appLevelAppMetricsEnabled := false
-							appLevelMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chart.AppId)
-							if err != nil && err != pg.ErrNoRows {
-								impl.logger.Errorw("error in getting app level metrics app level", "error", err)
-							} else if err == nil {
-								appLevelAppMetricsEnabled = appLevelMetrics.AppMetrics
-							}
-							chart.GlobalOverride = modified
-							chart.Values = modified
-							err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromGlobalTemplate(chart, nil, appLevelAppMetricsEnabled)
-							if err != nil {
-								impl.logger.Errorw("error in creating entry for deployment template history", "err", err, "chart", chart)
-							}
+							impl.createGlobalDeploymentTemplateHistory(chart, modified)
 						}
 					}
 				}
@@ -490,25 +504,7 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 							deploymentTemplateBulkUpdateResponse.Successful = append(deploymentTemplateBulkUpdateResponse.Successful, bulkUpdateSuccessResponse)
 
 							//creating history entry for deployment template
-							envLevelAppMetricsEnabled := false
-							envLevelAppMetrics, err := impl.envLevelAppMetricsRepository.FindByAppIdAndEnvId(chartEnv.Chart.AppId, chartEnv.TargetEnvironment)
-							if err != nil && err != pg.ErrNoRows {
-								impl.logger.Errorw("error in getting env level app metrics", "err", err, "appId", chartEnv.Chart.AppId, "envId", chartEnv.TargetEnvironment)
-							} else if err == pg.ErrNoRows {
-								appLevelAppMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chartEnv.Chart.AppId)
-								if err != nil && err != pg.ErrNoRows {
-									impl.logger.Errorw("error in getting app level app metrics", "err", err, "appId", chartEnv.Chart.AppId)
-								} else if err == nil {
-									envLevelAppMetricsEnabled = appLevelAppMetrics.AppMetrics
-								}
-							} else {
-								envLevelAppMetricsEnabled = *envLevelAppMetrics.AppMetrics
-							}
-							chartEnv.EnvOverrideValues = modified
-							err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromEnvOverrideTemplate(chartEnv, nil, envLevelAppMetricsEnabled, 0)
-							if err != nil {
-								impl.logger.Errorw("error in creating entry for env deployment template history", "err", err, "envOverride", chartEnv)
-							}
+							impl.createEnvDeploymentTemplateHistory(chartEnv, modified)
 						}
 					}
 				}
@@ -521,6 +517,44 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 	return deploymentTemplateBulkUpdateResponse
 }
 
+func (impl BulkUpdateServiceImpl) createGlobalDeploymentTemplateHistory(chart *chartRepoRepository.Chart, values string) {
+	appLevelAppMetricsEnabled := false
+	appLevelMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chart.AppId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app level metrics app level", "error", err)
+	} else if err == nil {
+		appLevelAppMetricsEnabled = appLevelMetrics.AppMetrics
+	}
+	chart.GlobalOverride = values
+	chart.Values = values
+	err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromGlobalTemplate(chart, nil, appLevelAppMetricsEnabled)
+	if err != nil {
+		impl.logger.Errorw("error in creating entry for deployment template history", "err", err, "chart", chart)
+	}
+}
+
+func (impl BulkUpdateServiceImpl) createEnvDeploymentTemplateHistory(chartEnv *chartConfig.EnvConfigOverride, values string) {
+	envLevelAppMetricsEnabled := false
+	envLevelAppMetrics, err := impl.envLevelAppMetricsRepository.FindByAppIdAndEnvId(chartEnv.Chart.AppId, chartEnv.TargetEnvironment)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting env level app metrics", "err", err, "appId", chartEnv.Chart.AppId, "envId", chartEnv.TargetEnvironment)
+	} else if err == pg.ErrNoRows {
+		appLevelAppMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chartEnv.Chart.AppId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting app level app metrics", "err", err, "appId", chartEnv.Chart.AppId)
+		} else if err == nil {
+			envLevelAppMetricsEnabled = appLevelAppMetrics.AppMetrics
+		}
+	} else {
+		envLevelAppMetricsEnabled = *envLevelAppMetrics.AppMetrics
+	}
+	chartEnv.EnvOverrideValues = values
+	err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromEnvOverrideTemplate(chartEnv, nil, envLevelAppMetricsEnabled, 0)
+	if err != nil {
+		impl.logger.Errorw("error in creating entry for env deployment template history", "err", err, "envOverride", chartEnv)
+	}
+}
+
 func (impl BulkUpdateServiceImpl) BulkUpdateConfigMap(bulkUpdatePayload *BulkUpdatePayload) *CmAndSecretBulkUpdateResponse {
 	configMapBulkUpdateResponse := &CmAndSecretBulkUpdateResponse{}
 	var appNameIncludes []string
@@ -754,6 +788,13 @@ func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdate
 				secretBulkUpdateResponse.Message = append(secretBulkUpdateResponse.Message, "No matching apps to update globally")
 			} else {
 				for _, secretAppModel := range secretAppModels {
+					if impl.enforcerUtil.IsSecretActionDenied(bulkUpdatePayload.UserId, casbin.ActionUpdate, secretAppModel.AppId, 0) {
+						secretBulkUpdateResponse.Failure = append(secretBulkUpdateResponse.Failure, &CmAndSecretBulkUpdateResponseForOneApp{
+							AppId:   secretAppModel.AppId,
+							Message: secretUpdateDeniedMessage,
+						})
+						continue
+					}
 					secretNames := gjson.Get(secretAppModel.SecretData, "secrets.#.name")
 					messageSecretNamesMap := make(map[string][]string)
 					for i, secretName := range secretNames.Array() {
@@ -852,6 +893,14 @@ func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdate
 				secretBulkUpdateResponse.Message = append(secretBulkUpdateResponse.Message, fmt.Sprintf("No matching apps to update for envId : %d", envId))
 			} else {
 				for _, secretEnvModel := range secretEnvModels {
+					if impl.enforcerUtil.IsSecretActionDenied(bulkUpdatePayload.UserId, casbin.ActionUpdate, secretEnvModel.AppId, envId) {
+						secretBulkUpdateResponse.Failure = append(secretBulkUpdateResponse.Failure, &CmAndSecretBulkUpdateResponseForOneApp{
+							AppId:   secretEnvModel.AppId,
+							Message: secretUpdateDeniedMessage,
+							EnvId:   envId,
+						})
+						continue
+					}
 					secretNames := gjson.Get(secretEnvModel.SecretData, "secrets.#.name")
 					messageSecretNamesMap := make(map[string][]string)
 					for i, secretName := range secretNames.Array() {
@@ -948,6 +997,30 @@ func (impl BulkUpdateServiceImpl) BulkUpdate(bulkUpdatePayload *BulkUpdatePayloa
 	var deploymentTemplateBulkUpdateResponse *DeploymentTemplateBulkUpdateResponse
 	var configMapBulkUpdateResponse *CmAndSecretBulkUpdateResponse
 	var secretBulkUpdateResponse *CmAndSecretBulkUpdateResponse
+	//bulk edits run one at a time so that history entries written by another edit don't fall between the watermarks
+	//and the entries of this one
+	dbConnection := impl.bulkOperationJobRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		impl.logger.Errorw("error in starting bulk edit tx", "err", err)
+		return bulkUpdateResponse
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.bulkOperationJobRepository.LockBulkEdit(tx)
+	if err != nil {
+		impl.logger.Errorw("error in taking bulk edit lock", "err", err)
+		return bulkUpdateResponse
+	}
+	//history entries till these ids hold the values before this update, used for reverting it
+	deploymentTemplateHistoryWatermark, err := impl.deploymentTemplateHistoryRepository.GetLatestHistoryId()
+	if err != nil {
+		impl.logger.Errorw("error in getting latest deployment template history id", "err", err)
+	}
+	configMapHistoryWatermark, err := impl.configMapHistoryRepository.GetLatestHistoryId()
+	if err != nil {
+		impl.logger.Errorw("error in getting latest configmap/secret history id", "err", err)
+	}
 	if bulkUpdatePayload.DeploymentTemplate != nil && bulkUpdatePayload.DeploymentTemplate.Spec != nil && bulkUpdatePayload.DeploymentTemplate.Spec.PatchJson != "" {
 		deploymentTemplateBulkUpdateResponse = impl.BulkUpdateDeploymentTemplate(bulkUpdatePayload)
 	}
@@ -961,221 +1034,432 @@ func (impl BulkUpdateServiceImpl) BulkUpdate(bulkUpdatePayload *BulkUpdatePayloa
 	bulkUpdateResponse.DeploymentTemplate = deploymentTemplateBulkUpdateResponse
 	bulkUpdateResponse.ConfigMap = configMapBulkUpdateResponse
 	bulkUpdateResponse.Secret = secretBulkUpdateResponse
+	job, err := impl.recordBulkEditJob(bulkUpdatePayload, bulkUpdateResponse, deploymentTemplateHistoryWatermark, configMapHistoryWatermark)
+	if err != nil {
+		impl.logger.Errorw("error in recording bulk edit job", "err", err)
+	} else if job != nil {
+		bulkUpdateResponse.JobId = job.Id
+	}
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("error in releasing bulk edit lock", "err", err)
+	}
 	return bulkUpdateResponse
 }
 
-func (impl BulkUpdateServiceImpl) BulkHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
-	var pipelines []*pipelineConfig.Pipeline
-	var err error
-	if len(request.AppIdIncludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
-	} else if len(request.AppIdExcludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
-	} else {
-		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
+const maskedSecretValue = "********"
+const maskedUpdatedSecretValue = "******** (updated)"
+
+// BulkUpdateDryRun computes the result of a bulk update without persisting it, returning before/after documents
+// along with their unified diff for every impacted object
+func (impl BulkUpdateServiceImpl) BulkUpdateDryRun(bulkUpdatePayload *BulkUpdatePayload) *BulkUpdateDryRunResponse {
+	bulkUpdateDryRunResponse := &BulkUpdateDryRunResponse{}
+	if bulkUpdatePayload.DeploymentTemplate != nil && bulkUpdatePayload.DeploymentTemplate.Spec != nil && bulkUpdatePayload.DeploymentTemplate.Spec.PatchJson != "" {
+		bulkUpdateDryRunResponse.DeploymentTemplate = impl.DryRunDeploymentTemplate(bulkUpdatePayload)
+	}
+	if bulkUpdatePayload.ConfigMap != nil && bulkUpdatePayload.ConfigMap.Spec != nil && len(bulkUpdatePayload.ConfigMap.Spec.Names) != 0 && bulkUpdatePayload.ConfigMap.Spec.PatchJson != "" {
+		bulkUpdateDryRunResponse.ConfigMap = impl.DryRunCmAndSecret(bulkUpdatePayload, bulkUpdatePayload.ConfigMap.Spec, false)
+	}
+	if bulkUpdatePayload.Secret != nil && bulkUpdatePayload.Secret.Spec != nil && len(bulkUpdatePayload.Secret.Spec.Names) != 0 && bulkUpdatePayload.Secret.Spec.PatchJson != "" {
+		bulkUpdateDryRunResponse.Secret = impl.DryRunCmAndSecret(bulkUpdatePayload, bulkUpdatePayload.Secret.Spec, true)
+	}
+	return bulkUpdateDryRunResponse
+}
+
+func (impl BulkUpdateServiceImpl) DryRunDeploymentTemplate(bulkUpdatePayload *BulkUpdatePayload) *DeploymentTemplateDryRunResponse {
+	dryRunResponse := &DeploymentTemplateDryRunResponse{}
+	if bulkUpdatePayload.Includes == nil || len(bulkUpdatePayload.Includes.Names) == 0 {
+		dryRunResponse.Message = append(dryRunResponse.Message, "Please don't leave includes.names array empty")
+		return dryRunResponse
+	}
+	appNameIncludes := bulkUpdatePayload.Includes.Names
+	var appNameExcludes []string
+	if bulkUpdatePayload.Excludes != nil && len(bulkUpdatePayload.Excludes.Names) > 0 {
+		appNameExcludes = bulkUpdatePayload.Excludes.Names
 	}
+	deploymentTemplatePatch, err := jsonpatch.DecodePatch([]byte(bulkUpdatePayload.DeploymentTemplate.Spec.PatchJson))
 	if err != nil {
-		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
-		return nil, err
+		impl.logger.Errorw("error in decoding JSON patch", "err", err)
+		dryRunResponse.Message = append(dryRunResponse.Message, "The patch string you entered seems wrong, please check and try again")
+		return dryRunResponse
 	}
-	response := make(map[string]map[string]bool)
-	for _, pipeline := range pipelines {
-		appKey := fmt.Sprintf("%d_%s", pipeline.AppId, pipeline.App.AppName)
-		pipelineKey := fmt.Sprintf("%d_%s", pipeline.Id, pipeline.Name)
-		success := true
-		if _, ok := response[appKey]; !ok {
-			pResponse := make(map[string]bool)
-			pResponse[pipelineKey] = false
-			response[appKey] = pResponse
+	if bulkUpdatePayload.Global {
+		charts, err := impl.bulkUpdateRepository.FindBulkChartsByAppNameSubstring(appNameIncludes, appNameExcludes)
+		if err != nil {
+			impl.logger.Errorw("error in fetching charts by app name substring", "err", err)
+			dryRunResponse.Message = append(dryRunResponse.Message, fmt.Sprintf("Unable to fetch apps globally : %s", err.Error()))
+		} else if len(charts) == 0 {
+			dryRunResponse.Message = append(dryRunResponse.Message, "No matching apps to update globally")
+		} else {
+			for _, chart := range charts {
+				appDetailsByChart, err := impl.bulkUpdateRepository.FindAppByChartId(chart.Id)
+				if err != nil {
+					impl.logger.Errorw("error in fetching app by chart id", "err", err, "chartId", chart.Id)
+					continue
+				}
+				impactedObject := &DeploymentTemplateDryRunResponseForOneApp{
+					AppId:   appDetailsByChart.Id,
+					AppName: appDetailsByChart.AppName,
+				}
+				impl.buildDeploymentTemplateDryRunResult(impactedObject, deploymentTemplatePatch, chart.Values, chart.ChartRefId)
+				dryRunResponse.ImpactedObjects = append(dryRunResponse.ImpactedObjects, impactedObject)
+			}
 		}
-		appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
-		envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(pipeline.AppId, pipeline.EnvironmentId)
-		isValidAuth := checkAuthForBulkActions(token, appObject, envObject)
-		if !isValidAuth {
-			//skip hibernate for the app if user does not have access on that
-			pipelineResponse := response[appKey]
-			pipelineResponse[pipelineKey] = false
-			response[appKey] = pipelineResponse
-			continue
+	}
+	for _, envId := range bulkUpdatePayload.EnvIds {
+		chartsEnv, err := impl.bulkUpdateRepository.FindBulkChartsEnvByAppNameSubstring(appNameIncludes, appNameExcludes, envId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching charts(for env) by app name substring", "err", err, "envId", envId)
+			dryRunResponse.Message = append(dryRunResponse.Message, fmt.Sprintf("Unable to fetch apps for envId = %d , %s", envId, err.Error()))
+		} else if len(chartsEnv) == 0 {
+			dryRunResponse.Message = append(dryRunResponse.Message, fmt.Sprintf("No matching apps to update for envId = %d", envId))
+		} else {
+			for _, chartEnv := range chartsEnv {
+				appDetailsByChart, err := impl.bulkUpdateRepository.FindAppByChartEnvId(chartEnv.Id)
+				if err != nil {
+					impl.logger.Errorw("error in fetching app by chart env id", "err", err, "chartEnvId", chartEnv.Id)
+					continue
+				}
+				impactedObject := &DeploymentTemplateDryRunResponseForOneApp{
+					AppId:   appDetailsByChart.Id,
+					AppName: appDetailsByChart.AppName,
+					EnvId:   envId,
+				}
+				chartRefId := 0
+				if chartEnv.Chart != nil {
+					chartRefId = chartEnv.Chart.ChartRefId
+				}
+				impl.buildDeploymentTemplateDryRunResult(impactedObject, deploymentTemplatePatch, chartEnv.EnvOverrideValues, chartRefId)
+				dryRunResponse.ImpactedObjects = append(dryRunResponse.ImpactedObjects, impactedObject)
+			}
 		}
+	}
+	return dryRunResponse
+}
 
-		if pipeline.DeploymentAppType == util.PIPELINE_DEPLOYMENT_TYPE_ACD {
-			stopRequest := &pipeline1.StopAppRequest{
-				AppId:         pipeline.AppId,
-				EnvironmentId: pipeline.EnvironmentId,
-				UserId:        request.UserId,
-				RequestType:   pipeline1.STOP,
+func (impl BulkUpdateServiceImpl) buildDeploymentTemplateDryRunResult(impactedObject *DeploymentTemplateDryRunResponseForOneApp, patch jsonpatch.Patch, values string, chartRefId int) {
+	modified, err := impl.ApplyJsonPatch(patch, values)
+	if err != nil {
+		impactedObject.Message = fmt.Sprintf("Error in applying JSON patch : %s", err.Error())
+		return
+	}
+	impactedObject.Before, impactedObject.After, impactedObject.Diff, err = buildDocumentDiff(values, modified)
+	if err != nil {
+		impl.logger.Errorw("error in building diff for deployment template", "err", err, "appId", impactedObject.AppId, "envId", impactedObject.EnvId)
+		impactedObject.Message = fmt.Sprintf("Error in building diff : %s", err.Error())
+		return
+	}
+	var template map[string]interface{}
+	err = json.Unmarshal([]byte(modified), &template)
+	if err != nil {
+		impactedObject.Message = fmt.Sprintf("Error in parsing updated template : %s", err.Error())
+		return
+	}
+	impactedObject.Valid, err = impl.chartService.DeploymentTemplateValidate(template, chartRefId)
+	if err != nil {
+		impactedObject.ValidationErrors = strings.Split(strings.TrimSpace(err.Error()), "\n")
+	}
+}
+
+func (impl BulkUpdateServiceImpl) DryRunCmAndSecret(bulkUpdatePayload *BulkUpdatePayload, spec *CmAndSecretSpec, isSecret bool) *CmAndSecretDryRunResponse {
+	dryRunResponse := &CmAndSecretDryRunResponse{}
+	if bulkUpdatePayload.Includes == nil || len(bulkUpdatePayload.Includes.Names) == 0 {
+		dryRunResponse.Message = append(dryRunResponse.Message, "Please don't leave includes.names array empty")
+		return dryRunResponse
+	}
+	appNameIncludes := bulkUpdatePayload.Includes.Names
+	var appNameExcludes []string
+	if bulkUpdatePayload.Excludes != nil && len(bulkUpdatePayload.Excludes.Names) > 0 {
+		appNameExcludes = bulkUpdatePayload.Excludes.Names
+	}
+	if bulkUpdatePayload.Global {
+		var appModels []*chartConfig.ConfigMapAppModel
+		var err error
+		if isSecret {
+			appModels, err = impl.bulkUpdateRepository.FindSecretBulkAppModelForGlobal(appNameIncludes, appNameExcludes, spec.Names)
+		} else {
+			appModels, err = impl.bulkUpdateRepository.FindCMBulkAppModelForGlobal(appNameIncludes, appNameExcludes, spec.Names)
+		}
+		if err != nil {
+			impl.logger.Errorw("error in fetching bulk app model for global", "err", err)
+			dryRunResponse.Message = append(dryRunResponse.Message, fmt.Sprintf("Unable to fetch apps globally : %s", err.Error()))
+		} else if len(appModels) == 0 {
+			dryRunResponse.Message = append(dryRunResponse.Message, "No matching apps to update globally")
+		} else {
+			for _, appModel := range appModels {
+				data := appModel.ConfigMapData
+				if isSecret {
+					data = appModel.SecretData
+				}
+				impactedObject := impl.buildCmAndSecretDryRunResult(spec, data, appModel.AppId, 0, isSecret)
+				dryRunResponse.ImpactedObjects = append(dryRunResponse.ImpactedObjects, impactedObject)
 			}
-			_, err := impl.workflowDagExecutor.StopStartApp(stopRequest, ctx)
-			if err != nil {
-				impl.logger.Errorw("service err, StartStopApp", "err", err, "stopRequest", stopRequest)
-				pipelineResponse := response[appKey]
-				pipelineResponse[pipelineKey] = false
-				response[appKey] = pipelineResponse
-				continue
-				//here on any error comes for any pipeline will be skipped
-				//return nil, err
+		}
+	}
+	for _, envId := range bulkUpdatePayload.EnvIds {
+		var envModels []*chartConfig.ConfigMapEnvModel
+		var err error
+		if isSecret {
+			envModels, err = impl.bulkUpdateRepository.FindSecretBulkAppModelForEnv(appNameIncludes, appNameExcludes, envId, spec.Names)
+		} else {
+			envModels, err = impl.bulkUpdateRepository.FindCMBulkAppModelForEnv(appNameIncludes, appNameExcludes, envId, spec.Names)
+		}
+		if err != nil {
+			impl.logger.Errorw("error in fetching bulk app model for env", "err", err, "envId", envId)
+			dryRunResponse.Message = append(dryRunResponse.Message, fmt.Sprintf("Unable to fetch apps for env: %d , %s", envId, err.Error()))
+		} else if len(envModels) == 0 {
+			dryRunResponse.Message = append(dryRunResponse.Message, fmt.Sprintf("No matching apps to update for envId : %d", envId))
+		} else {
+			for _, envModel := range envModels {
+				data := envModel.ConfigMapData
+				if isSecret {
+					data = envModel.SecretData
+				}
+				impactedObject := impl.buildCmAndSecretDryRunResult(spec, data, envModel.AppId, envId, isSecret)
+				dryRunResponse.ImpactedObjects = append(dryRunResponse.ImpactedObjects, impactedObject)
 			}
-		} else if pipeline.DeploymentAppType == util.PIPELINE_DEPLOYMENT_TYPE_HELM {
-			//TODO
-			//initiate helm hibernate service
 		}
-		pipelineResponse := response[appKey]
-		pipelineResponse[pipelineKey] = success
-		response[appKey] = pipelineResponse
-	}
-	bulkOperationResponse := &BulkApplicationForEnvironmentResponse{}
-	bulkOperationResponse.BulkApplicationForEnvironmentPayload = *request
-	bulkOperationResponse.Response = response
-	return bulkOperationResponse, nil
+	}
+	return dryRunResponse
 }
-func (impl BulkUpdateServiceImpl) BulkUnHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
-	var pipelines []*pipelineConfig.Pipeline
-	var err error
-	if len(request.AppIdIncludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
-	} else if len(request.AppIdExcludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
-	} else {
-		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
+
+func (impl BulkUpdateServiceImpl) buildCmAndSecretDryRunResult(spec *CmAndSecretSpec, data string, appId int, envId int, isSecret bool) *CmAndSecretDryRunResponseForOneApp {
+	impactedObject := &CmAndSecretDryRunResponseForOneApp{
+		AppId: appId,
+		EnvId: envId,
 	}
+	appDetailsById, err := impl.appRepository.FindById(appId)
 	if err != nil {
-		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
-		return nil, err
+		impl.logger.Errorw("error in fetching app by id", "err", err, "appId", appId)
+	} else {
+		impactedObject.AppName = appDetailsById.AppName
 	}
-	response := make(map[string]map[string]bool)
-	for _, pipeline := range pipelines {
-		appKey := fmt.Sprintf("%d_%s", pipeline.AppId, pipeline.App.AppName)
-		pipelineKey := fmt.Sprintf("%d_%s", pipeline.Id, pipeline.Name)
-		success := true
-		if _, ok := response[appKey]; !ok {
-			pResponse := make(map[string]bool)
-			pResponse[pipelineKey] = false
-			response[appKey] = pResponse
-		}
-		appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
-		envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(pipeline.AppId, pipeline.EnvironmentId)
-		isValidAuth := checkAuthForBulkActions(token, appObject, envObject)
-		if !isValidAuth {
-			//skip hibernate for the app if user does not have access on that
-			pipelineResponse := response[appKey]
-			pipelineResponse[pipelineKey] = false
-			response[appKey] = pipelineResponse
+	listKey := "maps"
+	if isSecret {
+		listKey = "secrets"
+	}
+	specNames := make(map[string]bool)
+	for _, name := range spec.Names {
+		specNames[name] = true
+	}
+	modified := data
+	names := gjson.Get(data, fmt.Sprintf("%s.#.name", listKey))
+	for i, name := range names.Array() {
+		if !specNames[name.String()] {
 			continue
 		}
-
-		if pipeline.DeploymentAppType == util.PIPELINE_DEPLOYMENT_TYPE_ACD {
-			stopRequest := &pipeline1.StopAppRequest{
-				AppId:         pipeline.AppId,
-				EnvironmentId: pipeline.EnvironmentId,
-				UserId:        request.UserId,
-				RequestType:   pipeline1.START,
-			}
-			_, err := impl.workflowDagExecutor.StopStartApp(stopRequest, ctx)
-			if err != nil {
-				impl.logger.Errorw("service err, StartStopApp", "err", err, "stopRequest", stopRequest)
-				pipelineResponse := response[appKey]
-				pipelineResponse[pipelineKey] = false
-				response[appKey] = pipelineResponse
-				//return nil, err
+		patchJsonString := spec.PatchJson
+		keyNames := gjson.Get(patchJsonString, "#.path")
+		for j, keyName := range keyNames.Array() {
+			patchJsonString, _ = sjson.Set(patchJsonString, fmt.Sprintf("%d.path", j), fmt.Sprintf("/%s/%d/data%s", listKey, i, keyName.String()))
+		}
+		if isSecret {
+			//updating values to their base64 equivalent, same as done in actual bulk update
+			values := gjson.Get(patchJsonString, "#.value")
+			for j, value := range values.Array() {
+				patchJsonString, _ = sjson.Set(patchJsonString, fmt.Sprintf("%d.value", j), base64.StdEncoding.EncodeToString([]byte(value.String())))
 			}
-		} else if pipeline.DeploymentAppType == util.PIPELINE_DEPLOYMENT_TYPE_HELM {
-			//TODO
-			//initiate helm hibernate service
 		}
-		pipelineResponse := response[appKey]
-		pipelineResponse[pipelineKey] = success
-		response[appKey] = pipelineResponse
-	}
-	bulkOperationResponse := &BulkApplicationForEnvironmentResponse{}
-	bulkOperationResponse.BulkApplicationForEnvironmentPayload = *request
-	bulkOperationResponse.Response = response
-	return bulkOperationResponse, nil
+		patch, err := jsonpatch.DecodePatch([]byte(patchJsonString))
+		if err != nil {
+			impl.logger.Errorw("error in decoding JSON patch", "err", err)
+			impactedObject.Message = "The patch string you entered seems wrong, please check and try again"
+			return impactedObject
+		}
+		modified, err = impl.ApplyJsonPatch(patch, modified)
+		if err != nil {
+			impactedObject.Message = fmt.Sprintf("Error in applying JSON patch : %s", err.Error())
+			return impactedObject
+		}
+		impactedObject.Names = append(impactedObject.Names, name.String())
+	}
+	before, after := data, modified
+	if isSecret {
+		before, after, err = maskSecretData(data, modified)
+		if err != nil {
+			impl.logger.Errorw("error in masking secret data", "err", err, "appId", appId, "envId", envId)
+			impactedObject.Message = fmt.Sprintf("Error in masking secret data : %s", err.Error())
+			return impactedObject
+		}
+	}
+	impactedObject.Before, impactedObject.After, impactedObject.Diff, err = buildDocumentDiff(before, after)
+	if err != nil {
+		impl.logger.Errorw("error in building diff", "err", err, "appId", appId, "envId", envId)
+		impactedObject.Message = fmt.Sprintf("Error in building diff : %s", err.Error())
+		return impactedObject
+	}
+	impactedObject.Valid = true
+	return impactedObject
 }
-func (impl BulkUpdateServiceImpl) BulkDeploy(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
-	var pipelines []*pipelineConfig.Pipeline
-	var err error
-	if len(request.AppIdIncludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
-	} else if len(request.AppIdExcludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
-	} else {
-		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
+
+// buildDocumentDiff indents both json documents and returns them along with their unified diff
+func buildDocumentDiff(before string, after string) (string, string, string, error) {
+	indentedBefore, err := indentJson(before)
+	if err != nil {
+		return "", "", "", err
 	}
+	indentedAfter, err := indentJson(after)
 	if err != nil {
-		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
-		return nil, err
+		return "", "", "", err
 	}
-	response := make(map[string]map[string]bool)
-	for _, pipeline := range pipelines {
-		appKey := fmt.Sprintf("%d_%s", pipeline.AppId, pipeline.App.AppName)
-		pipelineKey := fmt.Sprintf("%d_%s", pipeline.Id, pipeline.Name)
-		success := true
-		if _, ok := response[appKey]; !ok {
-			pResponse := make(map[string]bool)
-			pResponse[pipelineKey] = false
-			response[appKey] = pResponse
-		}
-		appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
-		envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(pipeline.AppId, pipeline.EnvironmentId)
-		isValidAuth := checkAuthForBulkActions(token, appObject, envObject)
-		if !isValidAuth {
-			//skip hibernate for the app if user does not have access on that
-			pipelineResponse := response[appKey]
-			pipelineResponse[pipelineKey] = false
-			response[appKey] = pipelineResponse
-			continue
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(indentedBefore),
+		B:        difflib.SplitLines(indentedAfter),
+		FromFile: "before",
+		ToFile:   "after",
+		Context:  3,
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+	return indentedBefore, indentedAfter, diff, nil
+}
+
+func indentJson(document string) (string, error) {
+	if len(document) == 0 {
+		return document, nil
+	}
+	var out bytes.Buffer
+	err := json.Indent(&out, []byte(document), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	out.WriteString("\n")
+	return out.String(), nil
+}
+
+// maskSecretData masks all secret values of both documents, values changed by the patch get a distinct mask
+// so that the diff still reflects which keys are updated
+func maskSecretData(before string, after string) (string, string, error) {
+	beforeValues := make(map[string]string)
+	maskedBefore, err := maskSecretValues(before, func(secretName, key, value string) string {
+		beforeValues[secretName+"/"+key] = value
+		return maskedSecretValue
+	})
+	if err != nil {
+		return "", "", err
+	}
+	maskedAfter, err := maskSecretValues(after, func(secretName, key, value string) string {
+		if oldValue, ok := beforeValues[secretName+"/"+key]; ok && oldValue == value {
+			return maskedSecretValue
 		}
+		return maskedUpdatedSecretValue
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return maskedBefore, maskedAfter, nil
+}
 
-		artifactResponse, err := impl.pipelineBuilder.GetArtifactsByCDPipeline(pipeline.Id, bean.CD_WORKFLOW_TYPE_DEPLOY)
+func maskSecretValues(secretData string, mask func(secretName, key, value string) string) (string, error) {
+	masked := secretData
+	var err error
+	for i, secret := range gjson.Get(secretData, "secrets").Array() {
+		secretName := secret.Get("name").String()
+		secret.Get("data").ForEach(func(key, value gjson.Result) bool {
+			masked, err = sjson.Set(masked, fmt.Sprintf("secrets.%d.data.%s", i, escapeJsonPathKey(key.String())), mask(secretName, key.String(), value.String()))
+			return err == nil
+		})
 		if err != nil {
-			impl.logger.Errorw("service err, GetArtifactsByCDPipeline", "err", err, "cdPipelineId", pipeline.Id)
-			//return nil, err
-			pipelineResponse := response[appKey]
-			pipelineResponse[appKey] = false
-			response[appKey] = pipelineResponse
+			return "", err
 		}
+	}
+	return masked, nil
+}
 
-		artifacts := artifactResponse.CiArtifacts
-		if len(artifacts) == 0 {
-			//there is no artifacts found for this pipeline, skip cd trigger
-			pipelineResponse := response[appKey]
-			pipelineResponse[pipelineKey] = false
-			response[appKey] = pipelineResponse
+func escapeJsonPathKey(key string) string {
+	replacer := strings.NewReplacer(".", "\\.", "*", "\\*", "?", "\\?")
+	return replacer.Replace(key)
+}
+
+func (impl BulkUpdateServiceImpl) BulkHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
+	job, items, pipelines, err := impl.createCdPipelineBulkOperationJob(request, bulkUpdate.BULK_OPERATION_HIBERNATE, token, checkAuthForBulkActions)
+	if err != nil {
+		return nil, err
+	}
+	response := buildBulkApplicationForEnvironmentResponse(request, job, items)
+	go impl.processBulkOperationJob(job.Id, items, request.UserId, func(item *bulkUpdate.BulkOperationJobItem) (bulkUpdate.BulkOperationItemStatus, string) {
+		return impl.stopStartApp(pipelines[item.PipelineId], pipeline1.STOP, request.UserId, ctx)
+	})
+	return response, nil
+}
+
+func (impl BulkUpdateServiceImpl) BulkUnHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
+	job, items, pipelines, err := impl.createCdPipelineBulkOperationJob(request, bulkUpdate.BULK_OPERATION_UNHIBERNATE, token, checkAuthForBulkActions)
+	if err != nil {
+		return nil, err
+	}
+	response := buildBulkApplicationForEnvironmentResponse(request, job, items)
+	go impl.processBulkOperationJob(job.Id, items, request.UserId, func(item *bulkUpdate.BulkOperationJobItem) (bulkUpdate.BulkOperationItemStatus, string) {
+		return impl.stopStartApp(pipelines[item.PipelineId], pipeline1.START, request.UserId, ctx)
+	})
+	return response, nil
+}
+
+func (impl BulkUpdateServiceImpl) BulkDeploy(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
+	job, items, pipelines, err := impl.createCdPipelineBulkOperationJob(request, bulkUpdate.BULK_OPERATION_DEPLOY, token, checkAuthForBulkActions)
+	if err != nil {
+		return nil, err
+	}
+	response := buildBulkApplicationForEnvironmentResponse(request, job, items)
+	go impl.processBulkOperationJob(job.Id, items, request.UserId, func(item *bulkUpdate.BulkOperationJobItem) (bulkUpdate.BulkOperationItemStatus, string) {
+		return impl.deployLatestArtifact(pipelines[item.PipelineId], request.UserId, ctx)
+	})
+	return response, nil
+}
+
+func (impl BulkUpdateServiceImpl) BulkBuildTrigger(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
+	pipelines, err := impl.findPipelinesForBulkAction(request)
+	if err != nil {
+		return nil, err
+	}
+	//one item per ci pipeline, linked ci pipelines are triggered through their parent
+	var items []*bulkUpdate.BulkOperationJobItem
+	ciPipelineItems := make(map[int]bool)
+	for _, pipeline := range pipelines {
+		ciPipeline, err := impl.ciPipelineRepository.FindById(pipeline.CiPipelineId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching ci pipeline", "CiPipelineId", pipeline.CiPipelineId, "err", err)
+			return nil, err
+		}
+		ciPipelineId := ciPipeline.Id
+		if ciPipeline.IsExternal {
+			ciPipelineId = ciPipeline.ParentCiPipeline
+		}
+		if ciPipelineItems[ciPipelineId] {
 			continue
 		}
-		artifact := artifacts[0]
-		if pipeline.DeploymentAppType == util.PIPELINE_DEPLOYMENT_TYPE_ACD {
-			overrideRequest := &bean.ValuesOverrideRequest{
-				PipelineId:     pipeline.Id,
-				AppId:          pipeline.AppId,
-				CiArtifactId:   artifact.Id,
-				UserId:         request.UserId,
-				CdWorkflowType: bean.CD_WORKFLOW_TYPE_DEPLOY,
-			}
-			_, err := impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
-			if err != nil {
-				impl.logger.Errorw("request err, OverrideConfig", "err", err, "payload", overrideRequest)
-				pipelineResponse := response[appKey]
-				pipelineResponse[pipelineKey] = false
-				response[appKey] = pipelineResponse
-				//return nil, err
-			}
-		} else if pipeline.DeploymentAppType == util.PIPELINE_DEPLOYMENT_TYPE_HELM {
-			//TODO
-			//initiate helm hibernate service
+		ciPipelineItems[ciPipelineId] = true
+		item := &bulkUpdate.BulkOperationJobItem{
+			ItemType:     bulkUpdate.BULK_ITEM_CI_PIPELINE,
+			AppId:        pipeline.AppId,
+			AppName:      pipeline.App.AppName,
+			EnvId:        pipeline.EnvironmentId,
+			PipelineId:   ciPipelineId,
+			PipelineName: ciPipeline.Name,
+			Status:       bulkUpdate.BULK_ITEM_PENDING,
+		}
+		appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
+		envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(pipeline.AppId, pipeline.EnvironmentId)
+		if !checkAuthForBulkActions(token, appObject, envObject) {
+			//skip build for the app if user does not have access on that
+			item.Status = bulkUpdate.BULK_ITEM_SKIPPED
+			item.Message = "unauthorized user"
 		}
-		pipelineResponse := response[appKey]
-		pipelineResponse[pipelineKey] = success
-		response[appKey] = pipelineResponse
-	}
-	bulkOperationResponse := &BulkApplicationForEnvironmentResponse{}
-	bulkOperationResponse.BulkApplicationForEnvironmentPayload = *request
-	bulkOperationResponse.Response = response
-	return bulkOperationResponse, nil
+		items = append(items, item)
+	}
+	job, err := impl.createBulkOperationJob(bulkUpdate.BULK_OPERATION_BUILD_TRIGGER, request, items, request.UserId, 0)
+	if err != nil {
+		return nil, err
+	}
+	response := buildBulkApplicationForEnvironmentResponse(request, job, items)
+	go impl.processBulkOperationJob(job.Id, items, request.UserId, func(item *bulkUpdate.BulkOperationJobItem) (bulkUpdate.BulkOperationItemStatus, string) {
+		return impl.triggerBuildWithLatestCommit(item.PipelineId, request.UserId)
+	})
+	return response, nil
 }
 
-func (impl BulkUpdateServiceImpl) BulkBuildTrigger(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
+func (impl BulkUpdateServiceImpl) findPipelinesForBulkAction(request *BulkApplicationForEnvironmentPayload) ([]*pipelineConfig.Pipeline, error) {
 	var pipelines []*pipelineConfig.Pipeline
 	var err error
 	if len(request.AppIdIncludes) > 0 {
@@ -1189,97 +1473,126 @@ func (impl BulkUpdateServiceImpl) BulkBuildTrigger(request *BulkApplicationForEn
 		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
 		return nil, err
 	}
+	return pipelines, nil
+}
 
-	latestCommitsMap := map[int]bean2.CiTriggerRequest{}
-	ciCompletedStatus := map[int]bool{}
+func (impl BulkUpdateServiceImpl) createCdPipelineBulkOperationJob(request *BulkApplicationForEnvironmentPayload, operation bulkUpdate.BulkOperationType, token string,
+	checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*bulkUpdate.BulkOperationJob, []*bulkUpdate.BulkOperationJobItem, map[int]*pipelineConfig.Pipeline, error) {
+	pipelines, err := impl.findPipelinesForBulkAction(request)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var items []*bulkUpdate.BulkOperationJobItem
+	pipelinesById := make(map[int]*pipelineConfig.Pipeline)
 	for _, pipeline := range pipelines {
-		if _, ok := latestCommitsMap[pipeline.CiPipelineId]; !ok {
-			ciPipelineId := 0
-			ciPipeline, err := impl.ciPipelineRepository.FindById(pipeline.CiPipelineId)
-			if err != nil {
-				impl.logger.Errorw("error in fetching ci pipeline", "CiPipelineId", pipeline.CiPipelineId, "err", err)
-				return nil, err
-			}
-			ciPipelineId = ciPipeline.Id
-			if ciPipeline.IsExternal {
-				if _, ok := latestCommitsMap[ciPipeline.ParentCiPipeline]; ok {
-					//skip linked ci pipeline for fetching materials if its parent already fetched.
-					continue
-				}
-				ciPipelineId = ciPipeline.ParentCiPipeline
-			}
-			materialResponse, err := impl.ciHandler.FetchMaterialsByPipelineId(ciPipelineId)
-			if err != nil {
-				impl.logger.Errorw("error in fetching ci pipeline materials", "CiPipelineId", ciPipelineId, "err", err)
-				return nil, err
-			}
-			var materialId int
-			var commitHash string
-			for _, material := range materialResponse {
-				materialId = material.Id
-				if len(material.History) > 0 {
-					commitHash = material.History[0].Commit
-				}
-			}
-			var ciMaterials []bean2.CiPipelineMaterial
-			ciMaterials = append(ciMaterials, bean2.CiPipelineMaterial{
-				Id:        materialId,
-				GitCommit: bean2.GitCommit{Commit: commitHash},
-			})
-			ciTriggerRequest := bean2.CiTriggerRequest{
-				PipelineId:         ciPipelineId,
-				CiPipelineMaterial: ciMaterials,
-				TriggeredBy:        request.UserId,
-				InvalidateCache:    false,
-			}
-			latestCommitsMap[ciPipelineId] = ciTriggerRequest
-			ciCompletedStatus[ciPipelineId] = false
+		pipelinesById[pipeline.Id] = pipeline
+		item := &bulkUpdate.BulkOperationJobItem{
+			ItemType:     bulkUpdate.BULK_ITEM_CD_PIPELINE,
+			AppId:        pipeline.AppId,
+			AppName:      pipeline.App.AppName,
+			EnvId:        pipeline.EnvironmentId,
+			PipelineId:   pipeline.Id,
+			PipelineName: pipeline.Name,
+			Status:       bulkUpdate.BULK_ITEM_PENDING,
 		}
+		appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
+		envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(pipeline.AppId, pipeline.EnvironmentId)
+		if !checkAuthForBulkActions(token, appObject, envObject) {
+			//skip the app if user does not have access on that
+			item.Status = bulkUpdate.BULK_ITEM_SKIPPED
+			item.Message = "unauthorized user"
+		}
+		items = append(items, item)
+	}
+	job, err := impl.createBulkOperationJob(operation, request, items, request.UserId, 0)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	return job, items, pipelinesById, nil
+}
 
-	response := make(map[string]map[string]bool)
-	for _, pipeline := range pipelines {
-		ciCompleted := ciCompletedStatus[pipeline.CiPipelineId]
-		if !ciCompleted {
-			appKey := fmt.Sprintf("%d_%s", pipeline.AppId, pipeline.App.AppName)
-			pipelineKey := fmt.Sprintf("%d", pipeline.CiPipelineId)
-			success := true
-			if _, ok := response[appKey]; !ok {
-				pResponse := make(map[string]bool)
-				pResponse[pipelineKey] = false
-				response[appKey] = pResponse
-			}
-			appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
-			envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(pipeline.AppId, pipeline.EnvironmentId)
-			isValidAuth := checkAuthForBulkActions(token, appObject, envObject)
-			if !isValidAuth {
-				//skip hibernate for the app if user does not have access on that
-				pipelineResponse := response[appKey]
-				pipelineResponse[pipelineKey] = false
-				response[appKey] = pipelineResponse
-				continue
-			}
+func (impl BulkUpdateServiceImpl) stopStartApp(pipeline *pipelineConfig.Pipeline, requestType pipeline1.RequestType, userId int32, ctx context.Context) (bulkUpdate.BulkOperationItemStatus, string) {
+	if pipeline.DeploymentAppType != util.PIPELINE_DEPLOYMENT_TYPE_ACD {
+		//TODO
+		//initiate helm hibernate service
+		return bulkUpdate.BULK_ITEM_SKIPPED, fmt.Sprintf("not supported for deployment app type %s", pipeline.DeploymentAppType)
+	}
+	stopRequest := &pipeline1.StopAppRequest{
+		AppId:         pipeline.AppId,
+		EnvironmentId: pipeline.EnvironmentId,
+		UserId:        userId,
+		RequestType:   requestType,
+	}
+	_, err := impl.workflowDagExecutor.StopStartApp(stopRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("service err, StartStopApp", "err", err, "stopRequest", stopRequest)
+		return bulkUpdate.BULK_ITEM_FAILED, err.Error()
+	}
+	return bulkUpdate.BULK_ITEM_SUCCEEDED, ""
+}
 
-			ciTriggerRequest := latestCommitsMap[pipeline.CiPipelineId]
-			_, err = impl.ciHandler.HandleCIManual(ciTriggerRequest)
-			if err != nil {
-				impl.logger.Errorw("service err, HandleCIManual", "err", err, "ciTriggerRequest", ciTriggerRequest)
-				//return nil, err
-				pipelineResponse := response[appKey]
-				pipelineResponse[appKey] = false
-				response[appKey] = pipelineResponse
-			}
+func (impl BulkUpdateServiceImpl) deployLatestArtifact(pipeline *pipelineConfig.Pipeline, userId int32, ctx context.Context) (bulkUpdate.BulkOperationItemStatus, string) {
+	if pipeline.DeploymentAppType != util.PIPELINE_DEPLOYMENT_TYPE_ACD {
+		//TODO
+		//initiate helm deployment
+		return bulkUpdate.BULK_ITEM_SKIPPED, fmt.Sprintf("not supported for deployment app type %s", pipeline.DeploymentAppType)
+	}
+	artifactResponse, err := impl.pipelineBuilder.GetArtifactsByCDPipeline(pipeline.Id, bean.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil {
+		impl.logger.Errorw("service err, GetArtifactsByCDPipeline", "err", err, "cdPipelineId", pipeline.Id)
+		return bulkUpdate.BULK_ITEM_FAILED, err.Error()
+	}
+	if len(artifactResponse.CiArtifacts) == 0 {
+		//there is no artifacts found for this pipeline, skip cd trigger
+		return bulkUpdate.BULK_ITEM_SKIPPED, "no artifact found for deployment"
+	}
+	artifact := artifactResponse.CiArtifacts[0]
+	overrideRequest := &bean.ValuesOverrideRequest{
+		PipelineId:     pipeline.Id,
+		AppId:          pipeline.AppId,
+		CiArtifactId:   artifact.Id,
+		UserId:         userId,
+		CdWorkflowType: bean.CD_WORKFLOW_TYPE_DEPLOY,
+	}
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("request err, OverrideConfig", "err", err, "payload", overrideRequest)
+		return bulkUpdate.BULK_ITEM_FAILED, err.Error()
+	}
+	return bulkUpdate.BULK_ITEM_SUCCEEDED, ""
+}
 
-			pipelineResponse := response[appKey]
-			pipelineResponse[pipelineKey] = success
-			response[appKey] = pipelineResponse
-			ciCompletedStatus[pipeline.CiPipelineId] = true
+func (impl BulkUpdateServiceImpl) triggerBuildWithLatestCommit(ciPipelineId int, userId int32) (bulkUpdate.BulkOperationItemStatus, string) {
+	materialResponse, err := impl.ciHandler.FetchMaterialsByPipelineId(ciPipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline materials", "CiPipelineId", ciPipelineId, "err", err)
+		return bulkUpdate.BULK_ITEM_FAILED, err.Error()
+	}
+	var materialId int
+	var commitHash string
+	for _, material := range materialResponse {
+		materialId = material.Id
+		if len(material.History) > 0 {
+			commitHash = material.History[0].Commit
 		}
 	}
-	bulkOperationResponse := &BulkApplicationForEnvironmentResponse{}
-	bulkOperationResponse.BulkApplicationForEnvironmentPayload = *request
-	bulkOperationResponse.Response = response
-	return bulkOperationResponse, nil
+	var ciMaterials []bean2.CiPipelineMaterial
+	ciMaterials = append(ciMaterials, bean2.CiPipelineMaterial{
+		Id:        materialId,
+		GitCommit: bean2.GitCommit{Commit: commitHash},
+	})
+	ciTriggerRequest := bean2.CiTriggerRequest{
+		PipelineId:         ciPipelineId,
+		CiPipelineMaterial: ciMaterials,
+		TriggeredBy:        userId,
+		InvalidateCache:    false,
+	}
+	_, err = impl.ciHandler.HandleCIManual(ciTriggerRequest)
+	if err != nil {
+		impl.logger.Errorw("service err, HandleCIManual", "err", err, "ciTriggerRequest", ciTriggerRequest)
+		return bulkUpdate.BULK_ITEM_FAILED, err.Error()
+	}
+	return bulkUpdate.BULK_ITEM_SUCCEEDED, ""
 }
 
 func (impl BulkUpdateServiceImpl) GetBulkActionImpactedPipelinesAndWfs(dto *CdBulkActionRequestDto) ([]*pipelineConfig.Pipeline, []int, []int, error) {
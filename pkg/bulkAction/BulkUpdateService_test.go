@@ -0,0 +1,62 @@
+package bulkAction
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskSecretData(t *testing.T) {
+	before := `{"secrets":[{"name":"db-secret","data":{"password":"cGFzcw==","user":"YWRtaW4="}}]}`
+	after := `{"secrets":[{"name":"db-secret","data":{"password":"bmV3cGFzcw==","user":"YWRtaW4="}}]}`
+	maskedBefore, maskedAfter, err := maskSecretData(before, after)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, value := range []string{"cGFzcw==", "bmV3cGFzcw==", "YWRtaW4="} {
+		if strings.Contains(maskedBefore, value) || strings.Contains(maskedAfter, value) {
+			t.Errorf("secret value %s is not masked", value)
+		}
+	}
+	if !strings.Contains(maskedAfter, `"password":"`+maskedUpdatedSecretValue+`"`) {
+		t.Errorf("updated key is not marked as updated, got %s", maskedAfter)
+	}
+	if !strings.Contains(maskedAfter, `"user":"`+maskedSecretValue+`"`) {
+		t.Errorf("unchanged key is marked as updated, got %s", maskedAfter)
+	}
+}
+
+func TestBuildDocumentDiff(t *testing.T) {
+	_, _, diff, err := buildDocumentDiff(`{"replicaCount":1,"image":"nginx"}`, `{"replicaCount":2,"image":"nginx"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(diff, `-  "replicaCount": 1`) || !strings.Contains(diff, `+  "replicaCount": 2`) {
+		t.Errorf("unexpected diff: %s", diff)
+	}
+	_, _, diff, err = buildDocumentDiff(`{"replicaCount":1}`, `{"replicaCount":1}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff != "" {
+		t.Errorf("expected empty diff for unchanged document, got %s", diff)
+	}
+}
+
+func TestRestoreCmAndSecretData(t *testing.T) {
+	current := `{"maps":[{"name":"app-cm","data":{"LOG_LEVEL":"debug"}},{"name":"other-cm","data":{"KEY":"new"}}]}`
+	previous := `{"maps":[{"name":"other-cm","data":{"KEY":"old"}},{"name":"app-cm","data":{"LOG_LEVEL":"info"}}]}`
+	restored, err := restoreCmAndSecretData(current, previous, "maps", []string{"app-cm"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(restored, `"LOG_LEVEL":"info"`) {
+		t.Errorf("named configmap is not restored, got %s", restored)
+	}
+	if !strings.Contains(restored, `"KEY":"new"`) {
+		t.Errorf("configmap not part of the revert is modified, got %s", restored)
+	}
+	_, err = restoreCmAndSecretData(current, previous, "maps", []string{"missing-cm"})
+	if err == nil {
+		t.Errorf("expected error for configmap missing in history")
+	}
+}
@@ -0,0 +1,524 @@
+package bulkAction
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	client "github.com/devtron-labs/devtron/client/events"
+	"github.com/devtron-labs/devtron/internal/sql/repository/bulkUpdate"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	util2 "github.com/devtron-labs/devtron/util/event"
+	"github.com/go-pg/pg"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultHibernationTimezone = "UTC"
+	hibernationRunListLimit    = 50
+)
+
+type HibernationScheduleDto struct {
+	Id               int               `json:"id"`
+	Name             string            `json:"name" validate:"required"`
+	EnvironmentId    int               `json:"environmentId" validate:"required"`
+	EnvironmentName  string            `json:"environmentName"`
+	AppLabelSelector map[string]string `json:"appLabelSelector"`
+	HibernateCron    string            `json:"hibernateCron" validate:"required"`
+	WakeCron         string            `json:"wakeCron"`
+	Timezone         string            `json:"timezone"`
+	SkipUntil        *time.Time        `json:"skipUntil,omitempty"`
+	NextHibernateOn  *time.Time        `json:"nextHibernateOn,omitempty"`
+	NextWakeOn       *time.Time        `json:"nextWakeOn,omitempty"`
+	UserId           int32             `json:"-"`
+}
+
+type HibernationScheduleSkipRequest struct {
+	ScheduleId int       `json:"scheduleId" validate:"required"`
+	SkipUntil  time.Time `json:"skipUntil"`
+	UserId     int32     `json:"-"`
+}
+
+type HibernationScheduleRunDto struct {
+	Id                 int                             `json:"id"`
+	ScheduleId         int                             `json:"scheduleId"`
+	Action             bulkUpdate.HibernationAction    `json:"action"`
+	ScheduledOn        time.Time                       `json:"scheduledOn"`
+	Status             bulkUpdate.HibernationRunStatus `json:"status"`
+	BulkOperationJobId int                             `json:"bulkOperationJobId,omitempty"`
+	Message            string                          `json:"message"`
+}
+
+type HibernationScheduleService interface {
+	CreateOrUpdateSchedule(scheduleDto *HibernationScheduleDto) (*HibernationScheduleDto, error)
+	GetAllSchedules() ([]*HibernationScheduleDto, error)
+	GetSchedule(id int) (*HibernationScheduleDto, error)
+	DeleteSchedule(id int, userId int32) error
+	// SkipSchedule skips every run scheduled before the given time, clearing it when the time is zero
+	SkipSchedule(request *HibernationScheduleSkipRequest) (*HibernationScheduleDto, error)
+	GetRuns(scheduleId int) ([]*HibernationScheduleRunDto, error)
+	ExecuteDueSchedules()
+}
+
+type HibernationScheduleServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	hibernationScheduleRepository bulkUpdate.HibernationScheduleRepository
+	bulkOperationJobRepository    bulkUpdate.BulkOperationJobRepository
+	bulkUpdateService             BulkUpdateService
+	environmentRepository         repository2.EnvironmentRepository
+	appLabelRepository            pipelineConfig.AppLabelRepository
+	argoUserService               argo.ArgoUserService
+	eventFactory                  client.EventFactory
+	eventClient                   client.EventClient
+}
+
+func NewHibernationScheduleServiceImpl(logger *zap.SugaredLogger,
+	hibernationScheduleRepository bulkUpdate.HibernationScheduleRepository,
+	bulkOperationJobRepository bulkUpdate.BulkOperationJobRepository,
+	bulkUpdateService BulkUpdateService,
+	environmentRepository repository2.EnvironmentRepository,
+	appLabelRepository pipelineConfig.AppLabelRepository,
+	argoUserService argo.ArgoUserService,
+	eventFactory client.EventFactory,
+	eventClient client.EventClient) *HibernationScheduleServiceImpl {
+	return &HibernationScheduleServiceImpl{
+		logger:                        logger,
+		hibernationScheduleRepository: hibernationScheduleRepository,
+		bulkOperationJobRepository:    bulkOperationJobRepository,
+		bulkUpdateService:             bulkUpdateService,
+		environmentRepository:         environmentRepository,
+		appLabelRepository:            appLabelRepository,
+		argoUserService:               argoUserService,
+		eventFactory:                  eventFactory,
+		eventClient:                   eventClient,
+	}
+}
+
+func (impl HibernationScheduleServiceImpl) CreateOrUpdateSchedule(scheduleDto *HibernationScheduleDto) (*HibernationScheduleDto, error) {
+	env, err := impl.environmentRepository.FindById(scheduleDto.EnvironmentId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environment", "err", err, "envId", scheduleDto.EnvironmentId)
+		return nil, err
+	}
+	if len(scheduleDto.Timezone) == 0 {
+		scheduleDto.Timezone = defaultHibernationTimezone
+	}
+	now := time.Now()
+	nextHibernateOn, err := nextHibernationRun(scheduleDto.HibernateCron, scheduleDto.Timezone, now)
+	if err != nil {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: fmt.Sprintf("invalid hibernate schedule, %s", err.Error())}
+	}
+	var nextWakeOn time.Time
+	if len(scheduleDto.WakeCron) > 0 {
+		nextWakeOn, err = nextHibernationRun(scheduleDto.WakeCron, scheduleDto.Timezone, now)
+		if err != nil {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: fmt.Sprintf("invalid wake up schedule, %s", err.Error())}
+		}
+	}
+	schedule := &bulkUpdate.HibernationSchedule{}
+	if scheduleDto.Id > 0 {
+		schedule, err = impl.hibernationScheduleRepository.FindActiveScheduleById(scheduleDto.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting hibernation schedule", "err", err, "id", scheduleDto.Id)
+			return nil, err
+		}
+	} else {
+		schedule.Active = true
+		schedule.AuditLog = sql.AuditLog{CreatedOn: now, CreatedBy: scheduleDto.UserId}
+	}
+	schedule.Name = scheduleDto.Name
+	schedule.EnvironmentId = scheduleDto.EnvironmentId
+	schedule.AppLabelSelector = scheduleDto.AppLabelSelector
+	schedule.HibernateCron = scheduleDto.HibernateCron
+	schedule.WakeCron = scheduleDto.WakeCron
+	schedule.Timezone = scheduleDto.Timezone
+	schedule.NextHibernateOn = nextHibernateOn
+	schedule.NextWakeOn = nextWakeOn
+	schedule.UpdatedOn = now
+	schedule.UpdatedBy = scheduleDto.UserId
+	if schedule.Id == 0 {
+		err = impl.hibernationScheduleRepository.SaveSchedule(schedule)
+	} else {
+		err = impl.hibernationScheduleRepository.UpdateSchedule(schedule)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving hibernation schedule", "err", err, "schedule", schedule)
+		return nil, err
+	}
+	return adaptHibernationSchedule(schedule, env.Name), nil
+}
+
+func (impl HibernationScheduleServiceImpl) GetAllSchedules() ([]*HibernationScheduleDto, error) {
+	schedules, err := impl.hibernationScheduleRepository.FindAllActiveSchedules()
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedules", "err", err)
+		return nil, err
+	}
+	var envIds []*int
+	for _, schedule := range schedules {
+		envId := schedule.EnvironmentId
+		envIds = append(envIds, &envId)
+	}
+	envNames := make(map[int]string)
+	if len(envIds) > 0 {
+		envs, err := impl.environmentRepository.FindByIds(envIds)
+		if err != nil {
+			impl.logger.Errorw("error in getting environments", "err", err)
+			return nil, err
+		}
+		for _, env := range envs {
+			envNames[env.Id] = env.Name
+		}
+	}
+	scheduleDtos := make([]*HibernationScheduleDto, 0, len(schedules))
+	for _, schedule := range schedules {
+		scheduleDtos = append(scheduleDtos, adaptHibernationSchedule(schedule, envNames[schedule.EnvironmentId]))
+	}
+	return scheduleDtos, nil
+}
+
+func (impl HibernationScheduleServiceImpl) GetSchedule(id int) (*HibernationScheduleDto, error) {
+	schedule, err := impl.hibernationScheduleRepository.FindActiveScheduleById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "err", err, "id", id)
+		return nil, err
+	}
+	env, err := impl.environmentRepository.FindById(schedule.EnvironmentId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environment", "err", err, "envId", schedule.EnvironmentId)
+		return nil, err
+	}
+	return adaptHibernationSchedule(schedule, env.Name), nil
+}
+
+func (impl HibernationScheduleServiceImpl) DeleteSchedule(id int, userId int32) error {
+	schedule, err := impl.hibernationScheduleRepository.FindActiveScheduleById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "err", err, "id", id)
+		return err
+	}
+	schedule.Active = false
+	schedule.UpdatedOn = time.Now()
+	schedule.UpdatedBy = userId
+	err = impl.hibernationScheduleRepository.UpdateSchedule(schedule)
+	if err != nil {
+		impl.logger.Errorw("error in deleting hibernation schedule", "err", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+func (impl HibernationScheduleServiceImpl) SkipSchedule(request *HibernationScheduleSkipRequest) (*HibernationScheduleDto, error) {
+	schedule, err := impl.hibernationScheduleRepository.FindActiveScheduleById(request.ScheduleId)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "err", err, "id", request.ScheduleId)
+		return nil, err
+	}
+	schedule.SkipUntil = request.SkipUntil
+	schedule.UpdatedOn = time.Now()
+	schedule.UpdatedBy = request.UserId
+	err = impl.hibernationScheduleRepository.UpdateSchedule(schedule)
+	if err != nil {
+		impl.logger.Errorw("error in updating hibernation schedule skip", "err", err, "id", request.ScheduleId)
+		return nil, err
+	}
+	return impl.GetSchedule(schedule.Id)
+}
+
+func (impl HibernationScheduleServiceImpl) GetRuns(scheduleId int) ([]*HibernationScheduleRunDto, error) {
+	runs, err := impl.hibernationScheduleRepository.FindRunsByScheduleId(scheduleId, hibernationRunListLimit)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule runs", "err", err, "scheduleId", scheduleId)
+		return nil, err
+	}
+	runDtos := make([]*HibernationScheduleRunDto, 0, len(runs))
+	for _, run := range runs {
+		runDtos = append(runDtos, &HibernationScheduleRunDto{
+			Id:                 run.Id,
+			ScheduleId:         run.ScheduleId,
+			Action:             run.Action,
+			ScheduledOn:        run.ScheduledOn,
+			Status:             run.Status,
+			BulkOperationJobId: run.BulkOperationJobId,
+			Message:            run.Message,
+		})
+	}
+	return runDtos, nil
+}
+
+func (impl HibernationScheduleServiceImpl) ExecuteDueSchedules() {
+	impl.syncTriggeredRuns()
+	now := time.Now()
+	schedules, err := impl.hibernationScheduleRepository.FindDueSchedules(now)
+	if err != nil {
+		impl.logger.Errorw("error in getting due hibernation schedules", "err", err)
+		return
+	}
+	for _, schedule := range schedules {
+		hibernateDue := !schedule.NextHibernateOn.IsZero() && !schedule.NextHibernateOn.After(now)
+		wakeDue := len(schedule.WakeCron) > 0 && !schedule.NextWakeOn.IsZero() && !schedule.NextWakeOn.After(now)
+		hibernateRun, err := impl.claimRun(schedule, bulkUpdate.HIBERNATION_ACTION_HIBERNATE, hibernateDue, now)
+		if err != nil {
+			continue
+		}
+		wakeRun, err := impl.claimRun(schedule, bulkUpdate.HIBERNATION_ACTION_WAKE, wakeDue, now)
+		if err != nil {
+			continue
+		}
+		// both runs are due only when runs were missed, only the latest one reflects the state expected now
+		if hibernateRun != nil && wakeRun != nil {
+			if hibernateRun.ScheduledOn.After(wakeRun.ScheduledOn) {
+				impl.saveSkippedRun(wakeRun, "superseded by a later hibernate run")
+				wakeRun = nil
+			} else {
+				impl.saveSkippedRun(hibernateRun, "superseded by a later wake up run")
+				hibernateRun = nil
+			}
+		}
+		if hibernateRun != nil {
+			impl.executeRun(schedule, hibernateRun)
+		}
+		if wakeRun != nil {
+			impl.executeRun(schedule, wakeRun)
+		}
+	}
+}
+
+// claimRun moves the due action of the schedule to its next run and returns the run to execute, nil if the
+// action is not due or was claimed by another instance
+func (impl HibernationScheduleServiceImpl) claimRun(schedule *bulkUpdate.HibernationSchedule, action bulkUpdate.HibernationAction, due bool, now time.Time) (*bulkUpdate.HibernationScheduleRun, error) {
+	if !due {
+		return nil, nil
+	}
+	cronExpression, scheduledOn := schedule.HibernateCron, schedule.NextHibernateOn
+	if action == bulkUpdate.HIBERNATION_ACTION_WAKE {
+		cronExpression, scheduledOn = schedule.WakeCron, schedule.NextWakeOn
+	}
+	nextRunOn, err := nextHibernationRun(cronExpression, schedule.Timezone, now)
+	if err != nil {
+		impl.logger.Errorw("invalid hibernation schedule, skipping", "err", err, "scheduleId", schedule.Id)
+		return nil, err
+	}
+	claimed, err := impl.hibernationScheduleRepository.ClaimRun(schedule.Id, action, scheduledOn, nextRunOn)
+	if err != nil {
+		impl.logger.Errorw("error in claiming hibernation schedule run", "err", err, "scheduleId", schedule.Id, "action", action)
+		return nil, err
+	} else if !claimed {
+		return nil, nil
+	}
+	return &bulkUpdate.HibernationScheduleRun{
+		ScheduleId:  schedule.Id,
+		Action:      action,
+		ScheduledOn: scheduledOn,
+		AuditLog:    sql.AuditLog{CreatedOn: now, CreatedBy: 1, UpdatedOn: now, UpdatedBy: 1},
+	}, nil
+}
+
+func (impl HibernationScheduleServiceImpl) executeRun(schedule *bulkUpdate.HibernationSchedule, run *bulkUpdate.HibernationScheduleRun) {
+	if schedule.SkipUntil.After(run.ScheduledOn) {
+		impl.saveSkippedRun(run, fmt.Sprintf("skipped by override until %s", schedule.SkipUntil.Format(time.RFC3339)))
+		return
+	}
+	payload := &BulkApplicationForEnvironmentPayload{
+		EnvId:  schedule.EnvironmentId,
+		UserId: 1,
+	}
+	if len(schedule.AppLabelSelector) > 0 {
+		appIds, err := impl.findAppIdsByLabelSelector(schedule.AppLabelSelector)
+		if err != nil {
+			impl.saveFailedRun(run, schedule.EnvironmentId, err)
+			return
+		}
+		if len(appIds) == 0 {
+			impl.saveSkippedRun(run, "no application matches the label selector")
+			return
+		}
+		payload.AppIdIncludes = appIds
+	}
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		impl.saveFailedRun(run, schedule.EnvironmentId, err)
+		return
+	}
+	ctx := context.WithValue(context.Background(), "token", acdToken)
+	// the schedule is authorized when it is saved, every app matched by it is acted upon
+	checkAuth := func(token string, appObject string, envObject string) bool { return true }
+	var jobResponse *BulkApplicationForEnvironmentResponse
+	if run.Action == bulkUpdate.HIBERNATION_ACTION_HIBERNATE {
+		jobResponse, err = impl.bulkUpdateService.BulkHibernate(payload, ctx, "", checkAuth)
+	} else {
+		jobResponse, err = impl.bulkUpdateService.BulkUnHibernate(payload, ctx, "", checkAuth)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in executing hibernation schedule run", "err", err, "scheduleId", schedule.Id, "action", run.Action)
+		impl.saveFailedRun(run, schedule.EnvironmentId, err)
+		return
+	}
+	run.Status = bulkUpdate.HIBERNATION_RUN_TRIGGERED
+	run.BulkOperationJobId = jobResponse.JobId
+	err = impl.hibernationScheduleRepository.SaveRun(run)
+	if err != nil {
+		impl.logger.Errorw("error in saving hibernation schedule run", "err", err, "run", run)
+	}
+}
+
+// syncTriggeredRuns completes the runs whose bulk operation job has finished and notifies the failed apps
+func (impl HibernationScheduleServiceImpl) syncTriggeredRuns() {
+	runs, err := impl.hibernationScheduleRepository.FindRunsByStatus(bulkUpdate.HIBERNATION_RUN_TRIGGERED)
+	if err != nil {
+		impl.logger.Errorw("error in getting triggered hibernation schedule runs", "err", err)
+		return
+	}
+	for _, run := range runs {
+		job, err := impl.bulkOperationJobRepository.FindJobById(run.BulkOperationJobId)
+		if err != nil {
+			impl.logger.Errorw("error in getting bulk operation job", "err", err, "jobId", run.BulkOperationJobId)
+			continue
+		}
+		if job.Status == bulkUpdate.BULK_JOB_IN_PROGRESS {
+			continue
+		}
+		items, err := impl.bulkOperationJobRepository.FindItemsByJobId(job.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting bulk operation job items", "err", err, "jobId", job.Id)
+			continue
+		}
+		var failedItems []*bulkUpdate.BulkOperationJobItem
+		for _, item := range items {
+			if item.Status == bulkUpdate.BULK_ITEM_FAILED {
+				failedItems = append(failedItems, item)
+			}
+		}
+		run.Status = bulkUpdate.HIBERNATION_RUN_SUCCEEDED
+		run.Message = fmt.Sprintf("%d applications processed", len(items))
+		if job.Status == bulkUpdate.BULK_JOB_CANCELLED {
+			run.Status = bulkUpdate.HIBERNATION_RUN_FAILED
+			run.Message = "bulk operation job cancelled"
+		} else if len(failedItems) > 0 {
+			run.Status = bulkUpdate.HIBERNATION_RUN_FAILED
+			run.Message = fmt.Sprintf("%d of %d applications failed", len(failedItems), len(items))
+		}
+		run.UpdatedOn = time.Now()
+		err = impl.hibernationScheduleRepository.UpdateRun(run)
+		if err != nil {
+			impl.logger.Errorw("error in updating hibernation schedule run", "err", err, "run", run)
+			continue
+		}
+		for _, item := range failedItems {
+			pipelineId := item.PipelineId
+			impl.sendFailureNotification(&pipelineId, item.AppId, item.EnvId, run.Action)
+		}
+	}
+}
+
+func (impl HibernationScheduleServiceImpl) findAppIdsByLabelSelector(selector map[string]string) ([]int, error) {
+	var appIds []int
+	first := true
+	for key, value := range selector {
+		labels, err := impl.appLabelRepository.FindByLabelKey(key)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting app labels", "err", err, "key", key)
+			return nil, err
+		}
+		matched := make(map[int]bool)
+		for _, label := range labels {
+			if label.Value == value {
+				matched[label.AppId] = true
+			}
+		}
+		if first {
+			for appId := range matched {
+				appIds = append(appIds, appId)
+			}
+			first = false
+			continue
+		}
+		var intersection []int
+		for _, appId := range appIds {
+			if matched[appId] {
+				intersection = append(intersection, appId)
+			}
+		}
+		appIds = intersection
+	}
+	return appIds, nil
+}
+
+func (impl HibernationScheduleServiceImpl) saveSkippedRun(run *bulkUpdate.HibernationScheduleRun, message string) {
+	run.Status = bulkUpdate.HIBERNATION_RUN_SKIPPED
+	run.Message = message
+	err := impl.hibernationScheduleRepository.SaveRun(run)
+	if err != nil {
+		impl.logger.Errorw("error in saving hibernation schedule run", "err", err, "run", run)
+	}
+}
+
+func (impl HibernationScheduleServiceImpl) saveFailedRun(run *bulkUpdate.HibernationScheduleRun, envId int, cause error) {
+	run.Status = bulkUpdate.HIBERNATION_RUN_FAILED
+	run.Message = cause.Error()
+	err := impl.hibernationScheduleRepository.SaveRun(run)
+	if err != nil {
+		impl.logger.Errorw("error in saving hibernation schedule run", "err", err, "run", run)
+	}
+	impl.sendFailureNotification(nil, 0, envId, run.Action)
+}
+
+func (impl HibernationScheduleServiceImpl) sendFailureNotification(pipelineId *int, appId int, envId int, action bulkUpdate.HibernationAction) {
+	event := impl.eventFactory.Build(util2.HibernationFailed, pipelineId, appId, &envId, util2.CD)
+	event.UserId = 1
+	stage := "hibernation"
+	if action == bulkUpdate.HIBERNATION_ACTION_WAKE {
+		stage = "wake up"
+	}
+	event.Payload = &client.Payload{Stage: stage}
+	_, evtErr := impl.eventClient.WriteNotificationEvent(event)
+	if evtErr != nil {
+		impl.logger.Errorw("error in writing hibernation failure notification event", "err", evtErr, "appId", appId, "envId", envId)
+	}
+}
+
+// nextHibernationRun returns the first run of the cron expression after now, evaluated in the given timezone
+func nextHibernationRun(cronExpression string, timezone string, now time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %s", timezone)
+	}
+	schedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression %s", cronExpression)
+	}
+	return schedule.Next(now.In(loc)), nil
+}
+
+func adaptHibernationSchedule(schedule *bulkUpdate.HibernationSchedule, envName string) *HibernationScheduleDto {
+	scheduleDto := &HibernationScheduleDto{
+		Id:               schedule.Id,
+		Name:             schedule.Name,
+		EnvironmentId:    schedule.EnvironmentId,
+		EnvironmentName:  envName,
+		AppLabelSelector: schedule.AppLabelSelector,
+		HibernateCron:    schedule.HibernateCron,
+		WakeCron:         schedule.WakeCron,
+		Timezone:         schedule.Timezone,
+	}
+	if !schedule.SkipUntil.IsZero() {
+		skipUntil := schedule.SkipUntil
+		scheduleDto.SkipUntil = &skipUntil
+	}
+	if !schedule.NextHibernateOn.IsZero() {
+		nextHibernateOn := schedule.NextHibernateOn
+		scheduleDto.NextHibernateOn = &nextHibernateOn
+	}
+	if len(schedule.WakeCron) > 0 && !schedule.NextWakeOn.IsZero() {
+		nextWakeOn := schedule.NextWakeOn
+		scheduleDto.NextWakeOn = &nextWakeOn
+	}
+	return scheduleDto
+}
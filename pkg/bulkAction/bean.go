@@ -1,5 +1,10 @@
 package bulkAction
 
+import (
+	"github.com/devtron-labs/devtron/internal/sql/repository/bulkUpdate"
+	"time"
+)
+
 type NameIncludesExcludes struct {
 	Names []string `json:"names"`
 }
@@ -25,6 +30,8 @@ type BulkUpdatePayload struct {
 	DeploymentTemplate *DeploymentTemplateTask `json:"deploymentTemplate"`
 	ConfigMap          *CmAndSecretTask        `json:"configMap"`
 	Secret             *CmAndSecretTask        `json:"secret"`
+	DryRun             bool                    `json:"dryRun"`
+	UserId             int32                   `json:"-"`
 }
 type BulkUpdateScript struct {
 	ApiVersion string             `json:"apiVersion" validate:"required"`
@@ -69,6 +76,7 @@ type BulkUpdateResponse struct {
 	DeploymentTemplate *DeploymentTemplateBulkUpdateResponse `json:"deploymentTemplate"`
 	ConfigMap          *CmAndSecretBulkUpdateResponse        `json:"configMap"`
 	Secret             *CmAndSecretBulkUpdateResponse        `json:"secret"`
+	JobId              int                                   `json:"jobId,omitempty"`
 }
 type DeploymentTemplateBulkUpdateResponse struct {
 	Message    []string                                         `json:"message"`
@@ -80,6 +88,41 @@ type CmAndSecretBulkUpdateResponse struct {
 	Failure    []*CmAndSecretBulkUpdateResponseForOneApp `json:"failure"`
 	Successful []*CmAndSecretBulkUpdateResponseForOneApp `json:"successful"`
 }
+type BulkUpdateDryRunResponse struct {
+	DeploymentTemplate *DeploymentTemplateDryRunResponse `json:"deploymentTemplate"`
+	ConfigMap          *CmAndSecretDryRunResponse        `json:"configMap"`
+	Secret             *CmAndSecretDryRunResponse        `json:"secret"`
+}
+type DeploymentTemplateDryRunResponse struct {
+	Message         []string                                     `json:"message"`
+	ImpactedObjects []*DeploymentTemplateDryRunResponseForOneApp `json:"impactedObjects"`
+}
+type CmAndSecretDryRunResponse struct {
+	Message         []string                              `json:"message"`
+	ImpactedObjects []*CmAndSecretDryRunResponseForOneApp `json:"impactedObjects"`
+}
+type DeploymentTemplateDryRunResponseForOneApp struct {
+	AppId            int      `json:"appId"`
+	AppName          string   `json:"appName"`
+	EnvId            int      `json:"envId"`
+	Before           string   `json:"before"`
+	After            string   `json:"after"`
+	Diff             string   `json:"diff"`
+	Valid            bool     `json:"valid"`
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+	Message          string   `json:"message,omitempty"`
+}
+type CmAndSecretDryRunResponseForOneApp struct {
+	AppId   int      `json:"appId"`
+	AppName string   `json:"appName"`
+	EnvId   int      `json:"envId"`
+	Names   []string `json:"names"`
+	Before  string   `json:"before"`
+	After   string   `json:"after"`
+	Diff    string   `json:"diff"`
+	Valid   bool     `json:"valid"`
+	Message string   `json:"message,omitempty"`
+}
 
 type BulkApplicationForEnvironmentPayload struct {
 	AppIdIncludes []int `json:"appIdIncludes,omitempty"`
@@ -88,9 +131,47 @@ type BulkApplicationForEnvironmentPayload struct {
 	UserId        int32 `json:"-"`
 }
 
+// BulkApplicationForEnvironmentResponse holds every matched pipeline keyed by app and pipeline, the value is false for
+// pipelines skipped upfront, progress of the queued ones is tracked through the bulk operation job
 type BulkApplicationForEnvironmentResponse struct {
 	BulkApplicationForEnvironmentPayload
 	Response map[string]map[string]bool `json:"response"`
+	JobId    int                        `json:"jobId"`
+}
+
+type BulkOperationJobResponse struct {
+	JobId         int                               `json:"jobId"`
+	Operation     bulkUpdate.BulkOperationType      `json:"operation"`
+	Status        bulkUpdate.BulkOperationJobStatus `json:"status"`
+	RevertedJobId int                               `json:"revertedJobId,omitempty"`
+	Progress      *BulkOperationJobProgress         `json:"progress"`
+	Items         []*BulkOperationJobItemDto        `json:"items"`
+	CreatedBy     int32                             `json:"createdBy"`
+	CreatedOn     time.Time                         `json:"createdOn"`
+	UpdatedOn     time.Time                         `json:"updatedOn"`
+}
+
+type BulkOperationJobProgress struct {
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	InProgress int `json:"inProgress"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped"`
+	Cancelled  int `json:"cancelled"`
+}
+
+type BulkOperationJobItemDto struct {
+	Id           int                                `json:"id"`
+	ItemType     bulkUpdate.BulkOperationItemType   `json:"itemType"`
+	AppId        int                                `json:"appId"`
+	AppName      string                             `json:"appName"`
+	EnvId        int                                `json:"envId,omitempty"`
+	PipelineId   int                                `json:"pipelineId,omitempty"`
+	PipelineName string                             `json:"pipelineName,omitempty"`
+	Names        []string                           `json:"names,omitempty"`
+	Status       bulkUpdate.BulkOperationItemStatus `json:"status"`
+	Message      string                             `json:"message,omitempty"`
 }
 
 type CdBulkAction int
@@ -27,12 +27,14 @@ import (
 	"k8s.io/client-go/rest"
 	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	bean2 "github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/client/k8s/informer"
 	"github.com/devtron-labs/devtron/internal/constants"
 	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
 	util2 "github.com/devtron-labs/devtron/util"
 	"github.com/go-pg/pg"
@@ -96,15 +98,17 @@ type ClusterServiceImpl struct {
 	logger             *zap.SugaredLogger
 	K8sUtil            *util.K8sUtil
 	K8sInformerFactory informer.K8sInformerFactory
+	auditLogService    auditLog.AuditLogService
 }
 
 func NewClusterServiceImpl(repository repository.ClusterRepository, logger *zap.SugaredLogger,
-	K8sUtil *util.K8sUtil, K8sInformerFactory informer.K8sInformerFactory) *ClusterServiceImpl {
+	K8sUtil *util.K8sUtil, K8sInformerFactory informer.K8sInformerFactory, auditLogService auditLog.AuditLogService) *ClusterServiceImpl {
 	clusterService := &ClusterServiceImpl{
 		clusterRepository:  repository,
 		logger:             logger,
 		K8sUtil:            K8sUtil,
 		K8sInformerFactory: K8sInformerFactory,
+		auditLogService:    auditLogService,
 	}
 	go clusterService.buildInformer()
 	return clusterService
@@ -197,6 +201,9 @@ func (impl *ClusterServiceImpl) Save(parent context.Context, bean *ClusterBean,
 		}
 	}
 	bean.Id = model.Id
+	if err == nil {
+		impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_CLUSTER, strconv.Itoa(model.Id), auditLog.AUDIT_ACTION_CREATE, nil, model, userId)
+	}
 
 	//on successful creation of new cluster, update informer cache for namespace group by cluster
 	//here sync for ea mode only
@@ -353,6 +360,7 @@ func (impl *ClusterServiceImpl) Update(ctx context.Context, bean *ClusterBean, u
 		impl.logger.Errorw("error on fetching cluster, duplicate", "name", bean.ClusterName)
 		return nil, fmt.Errorf("cluster already exists")
 	}
+	before := *model
 
 	// check whether config modified or not, if yes create informer with updated config
 	dbConfig := model.Config["bearer_token"]
@@ -409,6 +417,7 @@ func (impl *ClusterServiceImpl) Update(ctx context.Context, bean *ClusterBean, u
 		return bean, err
 	}
 	bean.Id = model.Id
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_CLUSTER, strconv.Itoa(model.Id), auditLog.AUDIT_ACTION_UPDATE, before, model, userId)
 
 	//here sync for ea mode only
 	if bean.HasConfigOrUrlChanged && util2.IsBaseStack() {
@@ -436,7 +445,12 @@ func (impl *ClusterServiceImpl) Delete(bean *ClusterBean, userId int32) error {
 	if err != nil {
 		return err
 	}
-	return impl.clusterRepository.Delete(model)
+	err = impl.clusterRepository.Delete(model)
+	if err != nil {
+		return err
+	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_CLUSTER, strconv.Itoa(model.Id), auditLog.AUDIT_ACTION_DELETE, model, nil, userId)
+	return nil
 }
 
 func (impl *ClusterServiceImpl) FindAllForAutoComplete() ([]ClusterBean, error) {
@@ -492,6 +506,7 @@ func (impl ClusterServiceImpl) DeleteFromDb(bean *ClusterBean, userId int32) err
 		impl.logger.Errorw("error in deleting cluster", "id", bean.Id, "err", err)
 		return err
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_CLUSTER, strconv.Itoa(bean.Id), auditLog.AUDIT_ACTION_DELETE, existingCluster, nil, userId)
 	return nil
 }
 
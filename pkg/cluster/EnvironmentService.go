@@ -19,11 +19,13 @@ package cluster
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/devtron-labs/devtron/client/k8s/informer"
+	repository3 "github.com/devtron-labs/devtron/internal/sql/repository"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/devtron-labs/devtron/pkg/user"
@@ -44,6 +46,7 @@ type EnvironmentBean struct {
 	Namespace             string `json:"namespace,omitempty" validate:"name-space-component,max=50"`
 	CdArgoSetup           bool   `json:"isClusterCdActive"`
 	EnvironmentIdentifier string `json:"environmentIdentifier"`
+	GitOpsPrRequired      bool   `json:"gitOpsPrRequired"` //values are committed through a pull request which has to be merged for deployment
 }
 
 type EnvDto struct {
@@ -84,14 +87,16 @@ type EnvironmentServiceImpl struct {
 	K8sUtil               *util.K8sUtil
 	k8sInformerFactory    informer.K8sInformerFactory
 	//propertiesConfigService pipeline.PropertiesConfigService
-	userAuthService user.UserAuthService
+	userAuthService        user.UserAuthService
+	gitOpsConfigRepository repository3.GitOpsConfigRepository
 }
 
 func NewEnvironmentServiceImpl(environmentRepository repository.EnvironmentRepository,
 	clusterService ClusterService, logger *zap.SugaredLogger,
 	K8sUtil *util.K8sUtil, k8sInformerFactory informer.K8sInformerFactory,
 	//  propertiesConfigService pipeline.PropertiesConfigService,
-	userAuthService user.UserAuthService) *EnvironmentServiceImpl {
+	userAuthService user.UserAuthService,
+	gitOpsConfigRepository repository3.GitOpsConfigRepository) *EnvironmentServiceImpl {
 	return &EnvironmentServiceImpl{
 		environmentRepository: environmentRepository,
 		logger:                logger,
@@ -99,7 +104,8 @@ func NewEnvironmentServiceImpl(environmentRepository repository.EnvironmentRepos
 		K8sUtil:               K8sUtil,
 		k8sInformerFactory:    k8sInformerFactory,
 		//propertiesConfigService: propertiesConfigService,
-		userAuthService: userAuthService,
+		userAuthService:        userAuthService,
+		gitOpsConfigRepository: gitOpsConfigRepository,
 	}
 }
 
@@ -113,6 +119,10 @@ func (impl EnvironmentServiceImpl) Create(mappings *EnvironmentBean, userId int3
 	if err != nil {
 		return nil, err
 	}
+	err = impl.validateGitOpsPrRequired(mappings.GitOpsPrRequired)
+	if err != nil {
+		return nil, err
+	}
 
 	clusterBean, err := impl.clusterService.FindById(mappings.ClusterId)
 	if err != nil {
@@ -138,6 +148,7 @@ func (impl EnvironmentServiceImpl) Create(mappings *EnvironmentBean, userId int3
 		Namespace:             mappings.Namespace,
 		Default:               mappings.Default,
 		EnvironmentIdentifier: identifier,
+		GitOpsPrRequired:      mappings.GitOpsPrRequired,
 	}
 	model.CreatedBy = userId
 	model.UpdatedBy = userId
@@ -185,10 +196,31 @@ func (impl EnvironmentServiceImpl) FindOne(environment string) (*EnvironmentBean
 		Namespace:             model.Namespace,
 		Default:               model.Default,
 		EnvironmentIdentifier: model.EnvironmentIdentifier,
+		GitOpsPrRequired:      model.GitOpsPrRequired,
 	}
 	return bean, nil
 }
 
+// validateGitOpsPrRequired allows pull request based commits only for gitops providers supporting pull requests
+func (impl EnvironmentServiceImpl) validateGitOpsPrRequired(gitOpsPrRequired bool) error {
+	if !gitOpsPrRequired {
+		return nil
+	}
+	gitOpsConfig, err := impl.gitOpsConfigRepository.GetGitOpsConfigActive()
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in fetching active gitops config", "err", err)
+		return err
+	}
+	if util.IsErrNoRows(err) || (gitOpsConfig.Provider != util.GITHUB_PROVIDER && gitOpsConfig.Provider != util.GITLAB_PROVIDER) {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: "gitops provider does not support pull requests",
+			UserMessage:     "pull request based deployments are supported only with GitHub and GitLab gitops providers",
+		}
+	}
+	return nil
+}
+
 func (impl EnvironmentServiceImpl) GetAll() ([]EnvironmentBean, error) {
 	models, err := impl.environmentRepository.FindAll()
 	if err != nil {
@@ -207,6 +239,7 @@ func (impl EnvironmentServiceImpl) GetAll() ([]EnvironmentBean, error) {
 			Default:               model.Default,
 			CdArgoSetup:           model.Cluster.CdArgoSetup,
 			EnvironmentIdentifier: model.EnvironmentIdentifier,
+			GitOpsPrRequired:      model.GitOpsPrRequired,
 		})
 	}
 	return beans, nil
@@ -229,6 +262,7 @@ func (impl EnvironmentServiceImpl) GetAllActive() ([]EnvironmentBean, error) {
 			Namespace:             model.Namespace,
 			Default:               model.Default,
 			EnvironmentIdentifier: model.EnvironmentIdentifier,
+			GitOpsPrRequired:      model.GitOpsPrRequired,
 		})
 	}
 	return beans, nil
@@ -249,6 +283,7 @@ func (impl EnvironmentServiceImpl) FindById(id int) (*EnvironmentBean, error) {
 		Namespace:             model.Namespace,
 		Default:               model.Default,
 		EnvironmentIdentifier: model.EnvironmentIdentifier,
+		GitOpsPrRequired:      model.GitOpsPrRequired,
 	}
 
 	/*clusterBean := &ClusterBean{
@@ -269,6 +304,12 @@ func (impl EnvironmentServiceImpl) Update(mappings *EnvironmentBean, userId int3
 	if model.Namespace != mappings.Namespace {
 		isNamespaceChange = true
 	}*/
+	if mappings.GitOpsPrRequired && !model.GitOpsPrRequired {
+		err = impl.validateGitOpsPrRequired(mappings.GitOpsPrRequired)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	clusterBean, err := impl.clusterService.FindById(mappings.ClusterId)
 	if err != nil {
@@ -279,6 +320,7 @@ func (impl EnvironmentServiceImpl) Update(mappings *EnvironmentBean, userId int3
 	model.Active = mappings.Active
 	model.Namespace = mappings.Namespace
 	model.Default = mappings.Default
+	model.GitOpsPrRequired = mappings.GitOpsPrRequired
 	model.UpdatedBy = userId
 	model.UpdatedOn = time.Now()
 
@@ -394,6 +436,7 @@ func (impl EnvironmentServiceImpl) FindByIds(ids []*int) ([]*EnvironmentBean, er
 			Namespace:             model.Namespace,
 			Default:               model.Default,
 			EnvironmentIdentifier: model.EnvironmentIdentifier,
+			GitOpsPrRequired:      model.GitOpsPrRequired,
 			ClusterId:             model.ClusterId,
 		})
 	}
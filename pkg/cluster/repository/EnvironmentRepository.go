@@ -34,6 +34,7 @@ type Environment struct {
 	GrafanaDatasourceId   int    `sql:"grafana_datasource_id"`
 	Namespace             string `sql:"namespace"`
 	EnvironmentIdentifier string `sql:"environment_identifier"`
+	GitOpsPrRequired      bool   `sql:"gitops_pr_required,notnull"`
 	sql.AuditLog
 }
 
@@ -58,9 +58,11 @@ type EnvironmentAppListForDG struct {
 }
 
 type DeploymentGroupTriggerRequest struct {
-	DeploymentGroupId int   `json:"deploymentGroupId"`
-	UserId            int32 `json:"userId"`
-	CiArtifactId      int   `json:"ciArtifactId"`
+	DeploymentGroupId              int    `json:"deploymentGroupId"`
+	UserId                         int32  `json:"userId"`
+	CiArtifactId                   int    `json:"ciArtifactId"`
+	OverrideDeploymentWindow       bool   `json:"overrideDeploymentWindow"`
+	DeploymentWindowOverrideReason string `json:"deploymentWindowOverrideReason"`
 }
 
 type DeploymentGroupHibernateRequest struct {
@@ -500,7 +502,11 @@ func (impl *DeploymentGroupServiceImpl) TriggerReleaseForDeploymentGroup(trigger
 	}
 	//trigger
 	// apply mapping
-	_, err = impl.workflowDagExecutor.TriggerBulkDeploymentAsync(requests, triggerRequest.UserId)
+	windowOverride := &pipeline.DeploymentWindowOverride{
+		Override: triggerRequest.OverrideDeploymentWindow,
+		Reason:   triggerRequest.DeploymentWindowOverrideReason,
+	}
+	_, err = impl.workflowDagExecutor.TriggerBulkDeploymentAsync(requests, triggerRequest.UserId, windowOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +39,7 @@ import (
 	bean2 "github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/client/argocdServer"
 	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	util2 "github.com/devtron-labs/devtron/util"
@@ -77,6 +78,9 @@ const (
 	GITLAB_PROVIDER       = "GITLAB"
 	BITBUCKET_PROVIDER    = "BITBUCKET_CLOUD"
 	AZURE_DEVOPS_PROVIDER = "AZURE_DEVOPS"
+	GITEA_PROVIDER        = "GITEA"
+	GENERIC_GIT_PROVIDER  = "GENERIC_GIT"
+	GENERIC_DRY_RUN_REPO  = "devtron-gitops-dryrun"
 	BITBUCKET_API_HOST    = "https://api.bitbucket.org/2.0/"
 )
 
@@ -100,12 +104,14 @@ type GitOpsConfigServiceImpl struct {
 	chartTemplateService util.ChartTemplateService
 	argoUserService      argo.ArgoUserService
 	clusterServiceCD     cluster2.ServiceClient
+	pipelineRepository   pipelineConfig.PipelineRepository
 }
 
 func NewGitOpsConfigServiceImpl(Logger *zap.SugaredLogger,
 	gitOpsRepository repository.GitOpsConfigRepository, K8sUtil *util.K8sUtil, aCDAuthConfig *util3.ACDAuthConfig,
 	clusterService cluster.ClusterService, envService cluster.EnvironmentService, versionService argocdServer.VersionService,
-	gitFactory *util.GitFactory, chartTemplateService util.ChartTemplateService, argoUserService argo.ArgoUserService, clusterServiceCD cluster2.ServiceClient) *GitOpsConfigServiceImpl {
+	gitFactory *util.GitFactory, chartTemplateService util.ChartTemplateService, argoUserService argo.ArgoUserService, clusterServiceCD cluster2.ServiceClient,
+	pipelineRepository pipelineConfig.PipelineRepository) *GitOpsConfigServiceImpl {
 	return &GitOpsConfigServiceImpl{
 		randSource:           rand.NewSource(time.Now().UnixNano()),
 		logger:               Logger,
@@ -119,10 +125,23 @@ func NewGitOpsConfigServiceImpl(Logger *zap.SugaredLogger,
 		chartTemplateService: chartTemplateService,
 		argoUserService:      argoUserService,
 		clusterServiceCD:     clusterServiceCD,
+		pipelineRepository:   pipelineRepository,
 	}
 }
 
 func (impl *GitOpsConfigServiceImpl) ValidateAndCreateGitOpsConfig(config *bean2.GitOpsConfigDto) (DetailedErrorGitOpsConfigResponse, error) {
+	err := impl.validateRepoLayout(config)
+	if err != nil {
+		return DetailedErrorGitOpsConfigResponse{}, err
+	}
+	err = impl.validateRepoLayoutChange(config)
+	if err != nil {
+		return DetailedErrorGitOpsConfigResponse{}, err
+	}
+	err = impl.validateSshAuth(config)
+	if err != nil {
+		return DetailedErrorGitOpsConfigResponse{}, err
+	}
 	detailedErrorGitOpsConfigResponse := impl.GitOpsValidateDryRun(config)
 	if len(detailedErrorGitOpsConfigResponse.StageErrorMap) == 0 {
 		//create argo-cd user, if not created, here argo-cd integration has to be installed
@@ -137,6 +156,18 @@ func (impl *GitOpsConfigServiceImpl) ValidateAndCreateGitOpsConfig(config *bean2
 	return detailedErrorGitOpsConfigResponse, nil
 }
 func (impl *GitOpsConfigServiceImpl) ValidateAndUpdateGitOpsConfig(config *bean2.GitOpsConfigDto) (DetailedErrorGitOpsConfigResponse, error) {
+	err := impl.validateRepoLayout(config)
+	if err != nil {
+		return DetailedErrorGitOpsConfigResponse{}, err
+	}
+	err = impl.validateRepoLayoutChange(config)
+	if err != nil {
+		return DetailedErrorGitOpsConfigResponse{}, err
+	}
+	err = impl.validateSshAuth(config)
+	if err != nil {
+		return DetailedErrorGitOpsConfigResponse{}, err
+	}
 	detailedErrorGitOpsConfigResponse := impl.GitOpsValidateDryRun(config)
 	if len(detailedErrorGitOpsConfigResponse.StageErrorMap) == 0 {
 		err := impl.UpdateGitOpsConfig(config)
@@ -148,6 +179,83 @@ func (impl *GitOpsConfigServiceImpl) ValidateAndUpdateGitOpsConfig(config *bean2
 	return detailedErrorGitOpsConfigResponse, nil
 }
 
+// validateRepoLayout defaults repo layout to repo per app and checks that shared repo name is given for mono repo layout
+func (impl *GitOpsConfigServiceImpl) validateRepoLayout(config *bean2.GitOpsConfigDto) error {
+	switch config.RepoLayout {
+	case "":
+		config.RepoLayout = util.GITOPS_REPO_LAYOUT_PER_APP
+	case util.GITOPS_REPO_LAYOUT_PER_APP, util.GITOPS_REPO_LAYOUT_PER_TEAM:
+	case util.GITOPS_REPO_LAYOUT_MONO_REPO:
+		if len(config.MonoRepoName) == 0 {
+			return &util.ApiError{
+				HttpStatusCode:  http.StatusBadRequest,
+				InternalMessage: "mono repo name is required for MONO_REPO layout",
+				UserMessage:     "mono repo name is required for MONO_REPO layout",
+			}
+		}
+	default:
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: fmt.Sprintf("invalid repo layout %s", config.RepoLayout),
+			UserMessage:     fmt.Sprintf("invalid repo layout %s, supported layouts are REPO_PER_APP, MONO_REPO and REPO_PER_TEAM", config.RepoLayout),
+		}
+	}
+	return nil
+}
+
+// validateRepoLayoutChange rejects activating a different repo layout or mono repo while argo cd apps exist, their
+// charts are kept at the location of the layout they were created with and would not be found anymore
+func (impl *GitOpsConfigServiceImpl) validateRepoLayoutChange(config *bean2.GitOpsConfigDto) error {
+	if !config.Active {
+		return nil
+	}
+	activeConfig, err := impl.gitOpsRepository.GetGitOpsConfigActive()
+	if err == pg.ErrNoRows {
+		return nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting active gitops config", "err", err)
+		return err
+	}
+	activeLayout := activeConfig.RepoLayout
+	if len(activeLayout) == 0 {
+		activeLayout = util.GITOPS_REPO_LAYOUT_PER_APP
+	}
+	if activeLayout == config.RepoLayout && (config.RepoLayout != util.GITOPS_REPO_LAYOUT_MONO_REPO || activeConfig.MonoRepoName == config.MonoRepoName) {
+		return nil
+	}
+	acdAppsExist, err := impl.pipelineRepository.ExistsByDeploymentAppTypeAndDeploymentAppCreated(util.PIPELINE_DEPLOYMENT_TYPE_ACD)
+	if err != nil {
+		impl.logger.Errorw("error in checking argo cd apps", "err", err)
+		return err
+	}
+	if acdAppsExist {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusConflict,
+			InternalMessage: "repo layout change with existing argo cd apps",
+			UserMessage:     fmt.Sprintf("repo layout can not be changed from %s while argo cd deployments exist", activeLayout),
+		}
+	}
+	return nil
+}
+
+// validateSshAuth rejects a config whose ssh private key or known hosts can not be used, instead of saving it and
+// failing every later git operation
+func (impl *GitOpsConfigServiceImpl) validateSshAuth(config *bean2.GitOpsConfigDto) error {
+	if len(config.SshPrivateKey) == 0 {
+		return nil
+	}
+	_, err := util.NewGitOpsSshAuth(config.SshPrivateKey, config.SshKnownHosts)
+	if err != nil {
+		impl.logger.Errorw("invalid gitops ssh auth", "err", err, "provider", config.Provider)
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: err.Error(),
+			UserMessage:     err.Error(),
+		}
+	}
+	return nil
+}
+
 func (impl *GitOpsConfigServiceImpl) buildGithubOrgUrl(host, orgId string) (orgUrl string, err error) {
 	hostUrl, err := url.Parse(host)
 	if err != nil {
@@ -157,6 +265,15 @@ func (impl *GitOpsConfigServiceImpl) buildGithubOrgUrl(host, orgId string) (orgU
 	return hostUrl.String(), nil
 }
 
+// buildGiteaOwnerUrl returns url of the gitea org, or of the user's namespace when org is not provided
+func (impl *GitOpsConfigServiceImpl) buildGiteaOwnerUrl(request *bean2.GitOpsConfigDto) (string, error) {
+	owner := request.GiteaOrgId
+	if len(owner) == 0 {
+		owner = request.Username
+	}
+	return impl.buildGithubOrgUrl(request.Host, owner)
+}
+
 func (impl *GitOpsConfigServiceImpl) CreateGitOpsConfig(ctx context.Context, request *bean2.GitOpsConfigDto) (*bean2.GitOpsConfigDto, error) {
 	impl.logger.Debugw("gitops create request", "req", request)
 	dbConnection := impl.gitOpsRepository.GetConnection()
@@ -193,6 +310,11 @@ func (impl *GitOpsConfigServiceImpl) CreateGitOpsConfig(ctx context.Context, req
 		AzureProject:         request.AzureProjectName,
 		BitBucketWorkspaceId: request.BitBucketWorkspaceId,
 		BitBucketProjectKey:  request.BitBucketProjectKey,
+		GiteaOrgId:           request.GiteaOrgId,
+		SshPrivateKey:        request.SshPrivateKey,
+		SshKnownHosts:        request.SshKnownHosts,
+		RepoLayout:           request.RepoLayout,
+		MonoRepoName:         request.MonoRepoName,
 		AuditLog:             sql.AuditLog{CreatedBy: request.UserId, CreatedOn: time.Now(), UpdatedOn: time.Now(), UpdatedBy: request.UserId},
 	}
 	model, err = impl.gitOpsRepository.CreateGitOpsConfig(model, tx)
@@ -227,6 +349,9 @@ func (impl *GitOpsConfigServiceImpl) CreateGitOpsConfig(ctx context.Context, req
 	data := make(map[string][]byte)
 	data["username"] = []byte(request.Username)
 	data["password"] = []byte(request.Token)
+	if len(request.SshPrivateKey) > 0 {
+		data["sshPrivateKey"] = []byte(request.SshPrivateKey)
+	}
 	if secret == nil {
 		secret, err = impl.K8sUtil.CreateSecret(impl.aCDAuthConfig.ACDConfigMapNamespace, data, GitOpsSecretName, client)
 		if err != nil {
@@ -280,6 +405,13 @@ func (impl *GitOpsConfigServiceImpl) CreateGitOpsConfig(ctx context.Context, req
 	if strings.ToUpper(request.Provider) == BITBUCKET_PROVIDER {
 		request.Host = util.BITBUCKET_CLONE_BASE_URL + request.BitBucketWorkspaceId
 	}
+	if strings.ToUpper(request.Provider) == GITEA_PROVIDER {
+		orgUrl, err := impl.buildGiteaOwnerUrl(request)
+		if err != nil {
+			return nil, err
+		}
+		request.Host = orgUrl
+	}
 	operationComplete := false
 	retryCount := 0
 	for !operationComplete && retryCount < 3 {
@@ -403,6 +535,11 @@ func (impl *GitOpsConfigServiceImpl) UpdateGitOpsConfig(request *bean2.GitOpsCon
 	model.AzureProject = request.AzureProjectName
 	model.BitBucketWorkspaceId = request.BitBucketWorkspaceId
 	model.BitBucketProjectKey = request.BitBucketProjectKey
+	model.GiteaOrgId = request.GiteaOrgId
+	model.SshPrivateKey = request.SshPrivateKey
+	model.SshKnownHosts = request.SshKnownHosts
+	model.RepoLayout = request.RepoLayout
+	model.MonoRepoName = request.MonoRepoName
 	err = impl.gitOpsRepository.UpdateGitOpsConfig(model, tx)
 	if err != nil {
 		impl.logger.Errorw("error in updating team", "data", model, "err", err)
@@ -437,6 +574,9 @@ func (impl *GitOpsConfigServiceImpl) UpdateGitOpsConfig(request *bean2.GitOpsCon
 	data := make(map[string][]byte)
 	data["username"] = []byte(request.Username)
 	data["password"] = []byte(request.Token)
+	if len(request.SshPrivateKey) > 0 {
+		data["sshPrivateKey"] = []byte(request.SshPrivateKey)
+	}
 	if secret == nil {
 		secret, err = impl.K8sUtil.CreateSecret(impl.aCDAuthConfig.ACDConfigMapNamespace, data, GitOpsSecretName, client)
 		if err != nil {
@@ -490,6 +630,13 @@ func (impl *GitOpsConfigServiceImpl) UpdateGitOpsConfig(request *bean2.GitOpsCon
 	if strings.ToUpper(request.Provider) == BITBUCKET_PROVIDER {
 		request.Host = util.BITBUCKET_CLONE_BASE_URL + request.BitBucketWorkspaceId
 	}
+	if strings.ToUpper(request.Provider) == GITEA_PROVIDER {
+		orgUrl, err := impl.buildGiteaOwnerUrl(request)
+		if err != nil {
+			return err
+		}
+		request.Host = orgUrl
+	}
 	operationComplete := false
 	retryCount := 0
 	for !operationComplete && retryCount < 3 {
@@ -545,6 +692,11 @@ func (impl *GitOpsConfigServiceImpl) GetGitOpsConfigById(id int) (*bean2.GitOpsC
 		AzureProjectName:     model.AzureProject,
 		BitBucketWorkspaceId: model.BitBucketWorkspaceId,
 		BitBucketProjectKey:  model.BitBucketProjectKey,
+		GiteaOrgId:           model.GiteaOrgId,
+		SshPrivateKey:        model.SshPrivateKey,
+		SshKnownHosts:        model.SshKnownHosts,
+		RepoLayout:           model.RepoLayout,
+		MonoRepoName:         model.MonoRepoName,
 	}
 
 	return config, err
@@ -571,6 +723,11 @@ func (impl *GitOpsConfigServiceImpl) GetAllGitOpsConfig() ([]*bean2.GitOpsConfig
 			AzureProjectName:     model.AzureProject,
 			BitBucketWorkspaceId: model.BitBucketWorkspaceId,
 			BitBucketProjectKey:  model.BitBucketProjectKey,
+			GiteaOrgId:           model.GiteaOrgId,
+			SshPrivateKey:        model.SshPrivateKey,
+			SshKnownHosts:        model.SshKnownHosts,
+			RepoLayout:           model.RepoLayout,
+			MonoRepoName:         model.MonoRepoName,
 		}
 		configs = append(configs, config)
 	}
@@ -596,6 +753,11 @@ func (impl *GitOpsConfigServiceImpl) GetGitOpsConfigByProvider(provider string)
 		AzureProjectName:     model.AzureProject,
 		BitBucketWorkspaceId: model.BitBucketWorkspaceId,
 		BitBucketProjectKey:  model.BitBucketProjectKey,
+		GiteaOrgId:           model.GiteaOrgId,
+		SshPrivateKey:        model.SshPrivateKey,
+		SshKnownHosts:        model.SshKnownHosts,
+		RepoLayout:           model.RepoLayout,
+		MonoRepoName:         model.MonoRepoName,
 	}
 
 	return config, err
@@ -645,14 +807,18 @@ func (impl *GitOpsConfigServiceImpl) createRepoElement(secretName string, reques
 	passwordSecret := &KeyDto{Name: secretName, Key: "password"}
 	repoData.PasswordSecret = passwordSecret
 	repoData.UsernameSecret = usernameSecret
+	if len(request.SshPrivateKey) > 0 {
+		repoData.SshPrivateKeySecret = &KeyDto{Name: secretName, Key: "sshPrivateKey"}
+	}
 	repoData.Url = request.Host
 	return repoData
 }
 
 type RepositoryCredentialsDto struct {
-	Url            string  `json:"url,omitempty"`
-	UsernameSecret *KeyDto `json:"usernameSecret,omitempty"`
-	PasswordSecret *KeyDto `json:"passwordSecret,omitempty"`
+	Url                 string  `json:"url,omitempty"`
+	UsernameSecret      *KeyDto `json:"usernameSecret,omitempty"`
+	PasswordSecret      *KeyDto `json:"passwordSecret,omitempty"`
+	SshPrivateKeySecret *KeyDto `json:"sshPrivateKeySecret,omitempty"`
 }
 
 type KeyDto struct {
@@ -676,6 +842,9 @@ func (impl *GitOpsConfigServiceImpl) GetGitOpsConfigActive() (*bean2.GitOpsConfi
 		AzureProjectName:     model.AzureProject,
 		BitBucketWorkspaceId: model.BitBucketWorkspaceId,
 		BitBucketProjectKey:  model.BitBucketProjectKey,
+		GiteaOrgId:           model.GiteaOrgId,
+		RepoLayout:           model.RepoLayout,
+		MonoRepoName:         model.MonoRepoName,
 	}
 	return config, err
 }
@@ -699,6 +868,14 @@ func (impl *GitOpsConfigServiceImpl) GitOpsValidateDryRun(config *bean2.GitOpsCo
 		return detailedErrorGitOpsConfigResponse
 	}
 	appName := DryrunRepoName + util2.Generate(6)
+	isGenericGit := strings.ToUpper(config.Provider) == GENERIC_GIT_PROVIDER
+	if isGenericGit {
+		//repos can not be created on generic git servers, validating against a pre-created repo instead
+		appName = GENERIC_DRY_RUN_REPO
+		if len(config.DryRunRepoName) > 0 {
+			appName = config.DryRunRepoName
+		}
+	}
 	//getting user name & emailId for commit author data
 	userEmailId, userName := impl.chartTemplateService.GetUserEmailIdAndNameForGitOpsCommit(config.UserId)
 	repoUrl, _, detailedErrorCreateRepo := client.CreateRepository(appName, "sample dry-run repo", userName, userEmailId)
@@ -746,7 +923,12 @@ func (impl *GitOpsConfigServiceImpl) GitOpsValidateDryRun(config *bean2.GitOpsCo
 		detailedErrorGitOpsConfigActions.SuccessfulStages = append(detailedErrorGitOpsConfigActions.SuccessfulStages, CommitOnRestStage, PushStage)
 	}
 
-	err = client.DeleteRepository(appName)
+	if isGenericGit {
+		//pre-created dry run repo is owned by the user, not deleting it
+		err = nil
+	} else {
+		err = client.DeleteRepository(appName)
+	}
 	if err != nil {
 		impl.logger.Errorw("error in deleting repo", "err", err)
 		//here below the assignment of delete is removed for making this stage optional, and it's failure not preventing it from saving/updating gitOps config
@@ -781,6 +963,10 @@ func (impl *GitOpsConfigServiceImpl) extractErrorMessageByProvider(err error, pr
 			errorMessage := fmt.Errorf("%s", errorResponse.Message)
 			return errorMessage
 		}
+	} else if provider == GITEA_PROVIDER {
+		if errorResponse, ok := err.(*util.GiteaApiError); ok {
+			return fmt.Errorf("%s", errorResponse.Message)
+		}
 	} else if provider == AZURE_DEVOPS_PROVIDER {
 		if errorResponse, ok := err.(azuredevops.WrappedError); ok {
 			errorMessage := fmt.Errorf("%s", *errorResponse.Message)
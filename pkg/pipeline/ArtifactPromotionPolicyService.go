@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/client/argocdServer/application"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	repository3 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ArtifactPromotionPolicyService interface {
+	// GetBlockedReasons collects every reason blocking the artifact from the cd pipeline, so that all of them can be
+	// fixed at once instead of one per attempt. Image scan results are only checked for the scanned flag here, the
+	// vulnerability policy itself is applied at trigger
+	GetBlockedReasons(cdPipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact) ([]string, *pipelineConfig.ArtifactPromotionPolicy, error)
+	// CheckArtifactPromotion returns an error carrying the blocked reasons if the artifact can not be deployed on the cd pipeline
+	CheckArtifactPromotion(cdPipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact) error
+}
+
+type ArtifactPromotionPolicyServiceImpl struct {
+	logger                      *zap.SugaredLogger
+	artifactPromotionRepository pipelineConfig.ArtifactPromotionRepository
+	pipelineRepository          pipelineConfig.PipelineRepository
+	ciPipelineRepository        pipelineConfig.CiPipelineRepository
+	cdWorkflowRepository        pipelineConfig.CdWorkflowRepository
+	environmentRepository       repository2.EnvironmentRepository
+	pipelineStageService        PipelineStageService
+}
+
+func NewArtifactPromotionPolicyServiceImpl(logger *zap.SugaredLogger,
+	artifactPromotionRepository pipelineConfig.ArtifactPromotionRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	pipelineStageService PipelineStageService) *ArtifactPromotionPolicyServiceImpl {
+	return &ArtifactPromotionPolicyServiceImpl{
+		logger:                      logger,
+		artifactPromotionRepository: artifactPromotionRepository,
+		pipelineRepository:          pipelineRepository,
+		ciPipelineRepository:        ciPipelineRepository,
+		cdWorkflowRepository:        cdWorkflowRepository,
+		environmentRepository:       environmentRepository,
+		pipelineStageService:        pipelineStageService,
+	}
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) GetBlockedReasons(cdPipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact) ([]string, *pipelineConfig.ArtifactPromotionPolicy, error) {
+	blockedReasons := make([]string, 0)
+	ciPipeline, err := impl.ciPipelineRepository.FindById(artifact.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting ci pipeline of artifact", "err", err, "ciPipelineId", artifact.PipelineId)
+		return nil, nil, err
+	}
+	if err == pg.ErrNoRows || ciPipeline.AppId != cdPipeline.AppId {
+		// artifacts of other apps are never promoted, whatever the policy
+		blockedReasons = append(blockedReasons, "artifact is not built by a ci pipeline of this app")
+		return blockedReasons, nil, nil
+	}
+	policy, err := impl.artifactPromotionRepository.FindActivePolicyByPipelineId(cdPipeline.Id)
+	if err == pg.ErrNoRows {
+		return blockedReasons, nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting artifact promotion policy", "err", err, "pipelineId", cdPipeline.Id)
+		return nil, nil, err
+	}
+	for _, sourceEnvId := range policy.SourceEnvironmentIds {
+		reasons, err := impl.checkSourceEnvironment(cdPipeline.AppId, sourceEnvId, artifact.Id, policy.RequirePostCd)
+		if err != nil {
+			return nil, nil, err
+		}
+		blockedReasons = append(blockedReasons, reasons...)
+	}
+	if policy.RequireImageScan && !artifact.Scanned {
+		blockedReasons = append(blockedReasons, "image has not been scanned")
+	}
+	return blockedReasons, policy, nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) CheckArtifactPromotion(cdPipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact) error {
+	blockedReasons, _, err := impl.GetBlockedReasons(cdPipeline, artifact)
+	if err != nil {
+		return err
+	}
+	if len(blockedReasons) > 0 {
+		impl.logger.Infow("artifact blocked by promotion policy", "pipelineId", cdPipeline.Id, "artifactId", artifact.Id, "reasons", blockedReasons)
+		return &util.ApiError{HttpStatusCode: http.StatusPreconditionFailed, InternalMessage: "artifact promotion blocked", UserMessage: "artifact promotion blocked, " + strings.Join(blockedReasons, "; ")}
+	}
+	return nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) checkSourceEnvironment(appId int, envId int, artifactId int, requirePostCd bool) ([]string, error) {
+	env, err := impl.environmentRepository.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environment", "err", err, "envId", envId)
+		return nil, err
+	}
+	sourcePipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(appId, envId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting source cd pipeline", "err", err, "appId", appId, "envId", envId)
+		return nil, err
+	}
+	if len(sourcePipelines) == 0 {
+		return []string{fmt.Sprintf("no cd pipeline found in required source environment %s", env.Name)}, nil
+	}
+	sourcePipeline := sourcePipelines[0]
+	deployed, err := impl.cdWorkflowRepository.ExistsByPipelineIdAndArtifactIdAndRunnerStatus(sourcePipeline.Id, artifactId, bean.CD_WORKFLOW_TYPE_DEPLOY, []string{application.Healthy})
+	if err != nil {
+		impl.logger.Errorw("error in checking deployment in source environment", "err", err, "pipelineId", sourcePipeline.Id, "artifactId", artifactId)
+		return nil, err
+	}
+	if !deployed {
+		return []string{fmt.Sprintf("artifact has not been deployed successfully in environment %s", env.Name)}, nil
+	}
+	if !requirePostCd {
+		return nil, nil
+	}
+	postStageExists, err := impl.pipelineStageService.IsCdPipelineStageConfigured(sourcePipeline, repository3.PIPELINE_STAGE_TYPE_POST_CD)
+	if err != nil || !postStageExists {
+		return nil, err
+	}
+	postSucceeded, err := impl.cdWorkflowRepository.ExistsByPipelineIdAndArtifactIdAndRunnerStatus(sourcePipeline.Id, artifactId, bean.CD_WORKFLOW_TYPE_POST, []string{string(v1alpha1.NodeSucceeded)})
+	if err != nil {
+		impl.logger.Errorw("error in checking post cd stage in source environment", "err", err, "pipelineId", sourcePipeline.Id, "artifactId", artifactId)
+		return nil, err
+	}
+	if !postSucceeded {
+		return []string{fmt.Sprintf("post deployment stage has not succeeded for artifact in environment %s", env.Name)}, nil
+	}
+	return nil, nil
+}
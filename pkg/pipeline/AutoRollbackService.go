@@ -0,0 +1,369 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bean2 "github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/client/argocdServer/application"
+	client "github.com/devtron-labs/devtron/client/events"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	util2 "github.com/devtron-labs/devtron/util/event"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultAutoRollbackWindowMinutes = 30
+	autoRollbackAuditListLimit       = 50
+)
+
+type AutoRollbackService interface {
+	GetPolicy(pipelineId int) (*AutoRollbackPolicyDto, error)
+	SavePolicy(policyDto *AutoRollbackPolicyDto) (*AutoRollbackPolicyDto, error)
+	GetAudits(pipelineId int) ([]*AutoRollbackAuditDto, error)
+	// HandleDeploymentFailure rolls the pipeline back to its previous healthy deployment if the
+	// failed or degraded runner is covered by the pipeline's auto rollback policy
+	HandleDeploymentFailure(cdWfrId int, status pipelineConfig.TimelineStatus)
+	HandleDegradedArgoApp(argoAppName string)
+}
+
+type AutoRollbackServiceImpl struct {
+	logger                           *zap.SugaredLogger
+	autoRollbackRepository           pipelineConfig.AutoRollbackRepository
+	cdWorkflowRepository             pipelineConfig.CdWorkflowRepository
+	pipelineRepository               pipelineConfig.PipelineRepository
+	appListingRepository             repository.AppListingRepository
+	pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository
+	workflowDagExecutor              WorkflowDagExecutor
+	argoUserService                  argo.ArgoUserService
+	eventFactory                     client.EventFactory
+	eventClient                      client.EventClient
+}
+
+func NewAutoRollbackServiceImpl(logger *zap.SugaredLogger,
+	autoRollbackRepository pipelineConfig.AutoRollbackRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	appListingRepository repository.AppListingRepository,
+	pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository,
+	workflowDagExecutor WorkflowDagExecutor,
+	argoUserService argo.ArgoUserService,
+	eventFactory client.EventFactory,
+	eventClient client.EventClient) *AutoRollbackServiceImpl {
+	return &AutoRollbackServiceImpl{
+		logger:                           logger,
+		autoRollbackRepository:           autoRollbackRepository,
+		cdWorkflowRepository:             cdWorkflowRepository,
+		pipelineRepository:               pipelineRepository,
+		appListingRepository:             appListingRepository,
+		pipelineStatusTimelineRepository: pipelineStatusTimelineRepository,
+		workflowDagExecutor:              workflowDagExecutor,
+		argoUserService:                  argoUserService,
+		eventFactory:                     eventFactory,
+		eventClient:                      eventClient,
+	}
+}
+
+type AutoRollbackPolicyDto struct {
+	Id            int   `json:"id"`
+	PipelineId    int   `json:"pipelineId" validate:"required"`
+	Enabled       bool  `json:"enabled"`
+	OnDegraded    bool  `json:"onDegraded"`
+	OnFailed      bool  `json:"onFailed"`
+	WindowMinutes int   `json:"windowMinutes" validate:"min=0"`
+	UserId        int32 `json:"-"`
+}
+
+type AutoRollbackAuditDto struct {
+	Id                         int                               `json:"id"`
+	PipelineId                 int                               `json:"pipelineId"`
+	SourceCdWorkflowRunnerId   int                               `json:"sourceCdWorkflowRunnerId"`
+	TargetCdWorkflowRunnerId   int                               `json:"targetCdWorkflowRunnerId"`
+	RollbackCdWorkflowRunnerId int                               `json:"rollbackCdWorkflowRunnerId"`
+	CiArtifactId               int                               `json:"ciArtifactId"`
+	TriggerStatus              pipelineConfig.TimelineStatus     `json:"triggerStatus"`
+	Status                     pipelineConfig.AutoRollbackStatus `json:"status"`
+	Message                    string                            `json:"message"`
+	CreatedOn                  time.Time                         `json:"createdOn"`
+}
+
+func (impl *AutoRollbackServiceImpl) GetPolicy(pipelineId int) (*AutoRollbackPolicyDto, error) {
+	policy, err := impl.autoRollbackRepository.FindActivePolicyByPipelineId(pipelineId)
+	if err == pg.ErrNoRows {
+		return &AutoRollbackPolicyDto{PipelineId: pipelineId, OnDegraded: true, OnFailed: true, WindowMinutes: DefaultAutoRollbackWindowMinutes}, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting auto rollback policy", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	return adaptAutoRollbackPolicy(policy), nil
+}
+
+func (impl *AutoRollbackServiceImpl) SavePolicy(policyDto *AutoRollbackPolicyDto) (*AutoRollbackPolicyDto, error) {
+	_, err := impl.pipelineRepository.FindById(policyDto.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", policyDto.PipelineId)
+		return nil, err
+	}
+	if policyDto.WindowMinutes == 0 {
+		policyDto.WindowMinutes = DefaultAutoRollbackWindowMinutes
+	}
+	policy, err := impl.autoRollbackRepository.FindActivePolicyByPipelineId(policyDto.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting auto rollback policy", "err", err, "pipelineId", policyDto.PipelineId)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		if !policyDto.Enabled {
+			return policyDto, nil
+		}
+		policy = &pipelineConfig.AutoRollbackPolicy{
+			PipelineId: policyDto.PipelineId,
+			AuditLog:   sql.AuditLog{CreatedOn: time.Now(), CreatedBy: policyDto.UserId},
+		}
+	}
+	policy.OnDegraded = policyDto.OnDegraded
+	policy.OnFailed = policyDto.OnFailed
+	policy.WindowMinutes = policyDto.WindowMinutes
+	policy.Active = policyDto.Enabled
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = policyDto.UserId
+	if policy.Id == 0 {
+		err = impl.autoRollbackRepository.SavePolicy(policy)
+	} else {
+		err = impl.autoRollbackRepository.UpdatePolicy(policy)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving auto rollback policy", "err", err, "policy", policy)
+		return nil, err
+	}
+	return adaptAutoRollbackPolicy(policy), nil
+}
+
+func (impl *AutoRollbackServiceImpl) GetAudits(pipelineId int) ([]*AutoRollbackAuditDto, error) {
+	audits, err := impl.autoRollbackRepository.FindAuditsByPipelineId(pipelineId, autoRollbackAuditListLimit)
+	if err != nil {
+		impl.logger.Errorw("error in getting auto rollback audits", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	auditDtos := make([]*AutoRollbackAuditDto, 0, len(audits))
+	for _, audit := range audits {
+		auditDtos = append(auditDtos, &AutoRollbackAuditDto{
+			Id:                         audit.Id,
+			PipelineId:                 audit.PipelineId,
+			SourceCdWorkflowRunnerId:   audit.SourceCdWorkflowRunnerId,
+			TargetCdWorkflowRunnerId:   audit.TargetCdWorkflowRunnerId,
+			RollbackCdWorkflowRunnerId: audit.RollbackCdWorkflowRunnerId,
+			CiArtifactId:               audit.CiArtifactId,
+			TriggerStatus:              audit.TriggerStatus,
+			Status:                     audit.Status,
+			Message:                    audit.Message,
+			CreatedOn:                  audit.CreatedOn,
+		})
+	}
+	return auditDtos, nil
+}
+
+func (impl *AutoRollbackServiceImpl) HandleDegradedArgoApp(argoAppName string) {
+	deploymentStatus, err := impl.appListingRepository.FindLastDeployedStatus(argoAppName)
+	if err != nil {
+		impl.logger.Errorw("error in getting last deployed status", "err", err, "argoAppName", argoAppName)
+		return
+	}
+	cdWfr, err := impl.cdWorkflowRepository.FindCdWorkflowRunnerByEnvironmentIdAndRunnerType(deploymentStatus.AppId, deploymentStatus.EnvId, bean2.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil {
+		impl.logger.Errorw("error in getting latest deploy runner", "err", err, "appId", deploymentStatus.AppId, "envId", deploymentStatus.EnvId)
+		return
+	}
+	impl.HandleDeploymentFailure(cdWfr.Id, pipelineConfig.TIMELINE_STATUS_APP_DEGRADED)
+}
+
+func (impl *AutoRollbackServiceImpl) HandleDeploymentFailure(cdWfrId int, status pipelineConfig.TimelineStatus) {
+	cdWfr, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(cdWfrId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd workflow runner", "err", err, "cdWfrId", cdWfrId)
+		return
+	}
+	pipelineId := cdWfr.CdWorkflow.PipelineId
+	policy, err := impl.autoRollbackRepository.FindActivePolicyByPipelineId(pipelineId)
+	if err == pg.ErrNoRows {
+		return
+	} else if err != nil {
+		impl.logger.Errorw("error in getting auto rollback policy", "err", err, "pipelineId", pipelineId)
+		return
+	}
+	if !isAutoRollbackApplicable(policy, status, cdWfr.StartedOn, time.Now()) {
+		return
+	}
+	handled, err := impl.autoRollbackRepository.ExistsAuditBySourceWfrId(cdWfr.Id)
+	if err != nil {
+		impl.logger.Errorw("error in checking auto rollback audit", "err", err, "cdWfrId", cdWfr.Id)
+		return
+	}
+	if handled {
+		return
+	}
+	audit := &pipelineConfig.AutoRollbackAudit{
+		PipelineId:               pipelineId,
+		SourceCdWorkflowRunnerId: cdWfr.Id,
+		TriggerStatus:            status,
+		Status:                   pipelineConfig.AUTO_ROLLBACK_STATUS_TRIGGERED,
+		AuditLog:                 sql.AuditLog{CreatedOn: time.Now(), CreatedBy: 1, UpdatedOn: time.Now(), UpdatedBy: 1},
+	}
+	// a rollback which degrades again is not rolled back further, to avoid walking back the whole history
+	isRollback, err := impl.autoRollbackRepository.ExistsAuditByRollbackWfrId(cdWfr.Id)
+	if err != nil {
+		impl.logger.Errorw("error in checking auto rollback audit", "err", err, "cdWfrId", cdWfr.Id)
+		return
+	}
+	if isRollback {
+		impl.saveSkippedAudit(audit, "deployment is itself an auto rollback")
+		return
+	}
+	target, err := impl.cdWorkflowRepository.FindLatestCdWfRunnerWithStatusBeforeId(pipelineId, cdWfr.Id, []string{application.Healthy})
+	if err == pg.ErrNoRows {
+		impl.saveSkippedAudit(audit, "no previous healthy deployment found")
+		return
+	} else if err != nil {
+		impl.logger.Errorw("error in getting previous healthy deployment", "err", err, "pipelineId", pipelineId)
+		return
+	}
+	audit.TargetCdWorkflowRunnerId = target.Id
+	audit.CiArtifactId = target.CdWorkflow.CiArtifactId
+	// unique source runner on the audit guards against concurrent status events rolling back twice
+	err = impl.autoRollbackRepository.SaveAudit(audit)
+	if err != nil {
+		impl.logger.Errorw("error in saving auto rollback audit", "err", err, "audit", audit)
+		return
+	}
+	pipeline, err := impl.pipelineRepository.FindById(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", pipelineId)
+		impl.markAuditFailed(audit, err)
+		return
+	}
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		impl.markAuditFailed(audit, err)
+		return
+	}
+	ctx := context.WithValue(context.Background(), "token", acdToken)
+	overrideRequest := &bean2.ValuesOverrideRequest{
+		PipelineId:                            pipeline.Id,
+		AppId:                                 pipeline.AppId,
+		CiArtifactId:                          target.CdWorkflow.CiArtifactId,
+		CdWorkflowType:                        bean2.CD_WORKFLOW_TYPE_DEPLOY,
+		DeploymentWithConfig:                  bean2.DEPLOYMENT_CONFIG_TYPE_SPECIFIC_TRIGGER,
+		WfrIdForDeploymentWithSpecificTrigger: target.Id,
+		DeploymentType:                        models.DEPLOYMENTTYPE_ROLLBACK,
+		IsAutoRollback:                        true,
+		UserId:                                1,
+	}
+	impl.logger.Infow("triggering auto rollback", "pipelineId", pipelineId, "sourceWfrId", cdWfr.Id, "targetWfrId", target.Id)
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("error in triggering auto rollback", "err", err, "overrideRequest", overrideRequest)
+		impl.markAuditFailed(audit, err)
+		impl.saveAutoRollbackTimeline(cdWfr.Id, fmt.Sprintf("Auto rollback to previous healthy deployment failed: %s", err.Error()))
+		return
+	}
+	// the trigger sets the cd workflow it created on the request, its deploy runner is the rollback
+	rollbackWfr, err := impl.cdWorkflowRepository.FindByWorkflowIdAndRunnerType(overrideRequest.CdWorkflowId, bean2.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil {
+		impl.logger.Errorw("error in getting rollback runner", "err", err, "cdWorkflowId", overrideRequest.CdWorkflowId)
+	} else {
+		audit.RollbackCdWorkflowRunnerId = rollbackWfr.Id
+	}
+	audit.Message = fmt.Sprintf("rolled back to image %s", target.CdWorkflow.CiArtifact.Image)
+	audit.UpdatedOn = time.Now()
+	err = impl.autoRollbackRepository.UpdateAudit(audit)
+	if err != nil {
+		impl.logger.Errorw("error in updating auto rollback audit", "err", err, "audit", audit)
+	}
+	impl.saveAutoRollbackTimeline(cdWfr.Id, fmt.Sprintf("Deployment %s, auto rollback triggered to image %s.", cdWfr.Status, target.CdWorkflow.CiArtifact.Image))
+	impl.sendAutoRollbackNotification(pipeline, target, audit.RollbackCdWorkflowRunnerId)
+}
+
+func (impl *AutoRollbackServiceImpl) saveSkippedAudit(audit *pipelineConfig.AutoRollbackAudit, message string) {
+	audit.Status = pipelineConfig.AUTO_ROLLBACK_STATUS_SKIPPED
+	audit.Message = message
+	err := impl.autoRollbackRepository.SaveAudit(audit)
+	if err != nil {
+		impl.logger.Errorw("error in saving auto rollback audit", "err", err, "audit", audit)
+	}
+}
+
+func (impl *AutoRollbackServiceImpl) markAuditFailed(audit *pipelineConfig.AutoRollbackAudit, cause error) {
+	audit.Status = pipelineConfig.AUTO_ROLLBACK_STATUS_FAILED
+	audit.Message = cause.Error()
+	audit.UpdatedOn = time.Now()
+	err := impl.autoRollbackRepository.UpdateAudit(audit)
+	if err != nil {
+		impl.logger.Errorw("error in updating auto rollback audit", "err", err, "audit", audit)
+	}
+}
+
+func (impl *AutoRollbackServiceImpl) saveAutoRollbackTimeline(cdWfrId int, statusDetail string) {
+	timeline := &pipelineConfig.PipelineStatusTimeline{
+		CdWorkflowRunnerId: cdWfrId,
+		Status:             pipelineConfig.TIMELINE_STATUS_AUTO_ROLLBACK,
+		StatusDetail:       statusDetail,
+		StatusTime:         time.Now(),
+		AuditLog: sql.AuditLog{
+			CreatedBy: 1,
+			CreatedOn: time.Now(),
+			UpdatedBy: 1,
+			UpdatedOn: time.Now(),
+		},
+	}
+	err := impl.pipelineStatusTimelineRepository.SaveTimeline(timeline)
+	if err != nil {
+		impl.logger.Errorw("error in saving auto rollback timeline", "err", err, "timeline", timeline)
+	}
+}
+
+func (impl *AutoRollbackServiceImpl) sendAutoRollbackNotification(pipeline *pipelineConfig.Pipeline, target *pipelineConfig.CdWorkflowRunner, rollbackWfrId int) {
+	event := impl.eventFactory.Build(util2.AutoRollback, &pipeline.Id, pipeline.AppId, &pipeline.EnvironmentId, util2.CD)
+	event.CiArtifactId = target.CdWorkflow.CiArtifactId
+	event.CdWorkflowRunnerId = rollbackWfrId
+	event.UserId = 1
+	event.Payload = &client.Payload{DockerImageUrl: target.CdWorkflow.CiArtifact.Image}
+	_, evtErr := impl.eventClient.WriteNotificationEvent(event)
+	if evtErr != nil {
+		impl.logger.Errorw("error in writing auto rollback notification event", "err", evtErr, "pipelineId", pipeline.Id)
+	}
+}
+
+// isAutoRollbackApplicable checks the status against the policy triggers and that the deployment started within the policy window
+func isAutoRollbackApplicable(policy *pipelineConfig.AutoRollbackPolicy, status pipelineConfig.TimelineStatus, startedOn time.Time, now time.Time) bool {
+	switch status {
+	case pipelineConfig.TIMELINE_STATUS_APP_DEGRADED:
+		if !policy.OnDegraded {
+			return false
+		}
+	case pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_FAILED:
+		if !policy.OnFailed {
+			return false
+		}
+	default:
+		return false
+	}
+	return !startedOn.Before(now.Add(-time.Duration(policy.WindowMinutes) * time.Minute))
+}
+
+func adaptAutoRollbackPolicy(policy *pipelineConfig.AutoRollbackPolicy) *AutoRollbackPolicyDto {
+	return &AutoRollbackPolicyDto{
+		Id:            policy.Id,
+		PipelineId:    policy.PipelineId,
+		Enabled:       policy.Active,
+		OnDegraded:    policy.OnDegraded,
+		OnFailed:      policy.OnFailed,
+		WindowMinutes: policy.WindowMinutes,
+	}
+}
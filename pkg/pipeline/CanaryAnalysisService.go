@@ -0,0 +1,598 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	application2 "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	bean2 "github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/client/argocdServer/application"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/prometheus"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/go-pg/pg"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultCanaryAnalysisIntervalSeconds       = 60
+	DefaultCanaryAnalysisIntervalCount         = 5
+	canaryAnalysisRunListLimit                 = 50
+	canaryAnalysisSystemUserId           int32 = 1
+	canaryRolloutKind                          = "Rollout"
+	canaryRolloutPromoteAction                 = "promote-full"
+	// a claimed interval is not picked by other instances for this long, the analysis sets the actual next interval
+	canaryAnalysisClaimTimeout = 10 * time.Minute
+)
+
+// supported conditions to compare a metric value against its threshold
+const (
+	CANARY_METRIC_CONDITION_LT  = "<"
+	CANARY_METRIC_CONDITION_LTE = "<="
+	CANARY_METRIC_CONDITION_GT  = ">"
+	CANARY_METRIC_CONDITION_GTE = ">="
+)
+
+type CanaryAnalysisService interface {
+	GetConfig(pipelineId int) (*CanaryAnalysisConfigDto, error)
+	SaveConfig(configDto *CanaryAnalysisConfigDto) (*CanaryAnalysisConfigDto, error)
+	GetRuns(pipelineId int) ([]*CanaryAnalysisRunDto, error)
+	// ProcessCanaryAnalysis starts analysis for new canary deployments and evaluates the due interval of running ones,
+	// a canary meeting the success criteria for all intervals is promoted, otherwise it is aborted by rolling back
+	ProcessCanaryAnalysis()
+}
+
+type CanaryAnalysisServiceImpl struct {
+	logger                            *zap.SugaredLogger
+	canaryAnalysisRepository          pipelineConfig.CanaryAnalysisRepository
+	pipelineRepository                pipelineConfig.PipelineRepository
+	cdWorkflowRepository              pipelineConfig.CdWorkflowRepository
+	pipelineStatusTimelineRepository  pipelineConfig.PipelineStatusTimelineRepository
+	pipelineStrategyHistoryRepository repository.PipelineStrategyHistoryRepository
+	environmentRepository             repository2.EnvironmentRepository
+	application                       application.ServiceClient
+	argoUserService                   argo.ArgoUserService
+	workflowDagExecutor               WorkflowDagExecutor
+}
+
+func NewCanaryAnalysisServiceImpl(logger *zap.SugaredLogger,
+	canaryAnalysisRepository pipelineConfig.CanaryAnalysisRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository,
+	pipelineStrategyHistoryRepository repository.PipelineStrategyHistoryRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	application application.ServiceClient,
+	argoUserService argo.ArgoUserService,
+	workflowDagExecutor WorkflowDagExecutor) *CanaryAnalysisServiceImpl {
+	return &CanaryAnalysisServiceImpl{
+		logger:                            logger,
+		canaryAnalysisRepository:          canaryAnalysisRepository,
+		pipelineRepository:                pipelineRepository,
+		cdWorkflowRepository:              cdWorkflowRepository,
+		pipelineStatusTimelineRepository:  pipelineStatusTimelineRepository,
+		pipelineStrategyHistoryRepository: pipelineStrategyHistoryRepository,
+		environmentRepository:             environmentRepository,
+		application:                       application,
+		argoUserService:                   argoUserService,
+		workflowDagExecutor:               workflowDagExecutor,
+	}
+}
+
+type CanaryAnalysisConfigDto struct {
+	Id              int                `json:"id"`
+	PipelineId      int                `json:"pipelineId" validate:"required"`
+	Enabled         bool               `json:"enabled"`
+	IntervalSeconds int                `json:"intervalSeconds" validate:"min=0"`
+	IntervalCount   int                `json:"intervalCount" validate:"min=0"`
+	FailureLimit    int                `json:"failureLimit" validate:"min=0"`
+	Metrics         []*CanaryMetricDto `json:"metrics" validate:"dive"`
+	UserId          int32              `json:"-"`
+}
+
+// CanaryMetricDto is a success criterion of the canary, the query can use {{namespace}}, {{appName}}, {{envName}}
+// and {{releaseName}} placeholders which are replaced with the values of the deployment under analysis
+type CanaryMetricDto struct {
+	Name      string  `json:"name" validate:"required"`
+	Query     string  `json:"query" validate:"required"`
+	Condition string  `json:"condition" validate:"oneof=< <= > >="`
+	Threshold float64 `json:"threshold"`
+}
+
+type CanaryAnalysisRunDto struct {
+	Id                         int                                    `json:"id"`
+	PipelineId                 int                                    `json:"pipelineId"`
+	CdWorkflowRunnerId         int                                    `json:"cdWorkflowRunnerId"`
+	RollbackCdWorkflowRunnerId int                                    `json:"rollbackCdWorkflowRunnerId"`
+	Status                     pipelineConfig.CanaryAnalysisRunStatus `json:"status"`
+	IntervalsCompleted         int                                    `json:"intervalsCompleted"`
+	IntervalsFailed            int                                    `json:"intervalsFailed"`
+	Message                    string                                 `json:"message"`
+	CreatedOn                  time.Time                              `json:"createdOn"`
+	UpdatedOn                  time.Time                              `json:"updatedOn"`
+}
+
+func (impl *CanaryAnalysisServiceImpl) GetConfig(pipelineId int) (*CanaryAnalysisConfigDto, error) {
+	config, err := impl.canaryAnalysisRepository.FindActiveConfigByPipelineId(pipelineId)
+	if err == pg.ErrNoRows {
+		return &CanaryAnalysisConfigDto{
+			PipelineId:      pipelineId,
+			IntervalSeconds: DefaultCanaryAnalysisIntervalSeconds,
+			IntervalCount:   DefaultCanaryAnalysisIntervalCount,
+			Metrics:         []*CanaryMetricDto{},
+		}, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting canary analysis config", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	return impl.adaptConfig(config)
+}
+
+func (impl *CanaryAnalysisServiceImpl) SaveConfig(configDto *CanaryAnalysisConfigDto) (*CanaryAnalysisConfigDto, error) {
+	cdPipeline, err := impl.pipelineRepository.FindById(configDto.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", configDto.PipelineId)
+		return nil, err
+	}
+	if configDto.Enabled {
+		if !util.IsAcdApp(cdPipeline.DeploymentAppType) {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "canary analysis not supported for deployment app type " + cdPipeline.DeploymentAppType, UserMessage: "canary analysis is supported only for pipelines deploying through gitops"}
+		}
+		if len(configDto.Metrics) == 0 {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "no metrics in canary analysis config", UserMessage: "at least one metric is required for canary analysis"}
+		}
+	}
+	if configDto.IntervalSeconds == 0 {
+		configDto.IntervalSeconds = DefaultCanaryAnalysisIntervalSeconds
+	}
+	if configDto.IntervalCount == 0 {
+		configDto.IntervalCount = DefaultCanaryAnalysisIntervalCount
+	}
+	metrics, err := json.Marshal(configDto.Metrics)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling canary metrics", "err", err, "metrics", configDto.Metrics)
+		return nil, err
+	}
+	config, err := impl.canaryAnalysisRepository.FindActiveConfigByPipelineId(configDto.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting canary analysis config", "err", err, "pipelineId", configDto.PipelineId)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		if !configDto.Enabled {
+			return configDto, nil
+		}
+		config = &pipelineConfig.CanaryAnalysisConfig{
+			PipelineId: configDto.PipelineId,
+			AuditLog:   sql.AuditLog{CreatedOn: time.Now(), CreatedBy: configDto.UserId},
+		}
+	}
+	config.IntervalSeconds = configDto.IntervalSeconds
+	config.IntervalCount = configDto.IntervalCount
+	config.FailureLimit = configDto.FailureLimit
+	config.Metrics = string(metrics)
+	config.Active = configDto.Enabled
+	config.UpdatedOn = time.Now()
+	config.UpdatedBy = configDto.UserId
+	if config.Id == 0 {
+		err = impl.canaryAnalysisRepository.SaveConfig(config)
+	} else {
+		err = impl.canaryAnalysisRepository.UpdateConfig(config)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving canary analysis config", "err", err, "config", config)
+		return nil, err
+	}
+	return impl.adaptConfig(config)
+}
+
+func (impl *CanaryAnalysisServiceImpl) adaptConfig(config *pipelineConfig.CanaryAnalysisConfig) (*CanaryAnalysisConfigDto, error) {
+	metrics, err := parseCanaryMetrics(config.Metrics)
+	if err != nil {
+		impl.logger.Errorw("error in parsing canary metrics", "err", err, "configId", config.Id)
+		return nil, err
+	}
+	return &CanaryAnalysisConfigDto{
+		Id:              config.Id,
+		PipelineId:      config.PipelineId,
+		Enabled:         config.Active,
+		IntervalSeconds: config.IntervalSeconds,
+		IntervalCount:   config.IntervalCount,
+		FailureLimit:    config.FailureLimit,
+		Metrics:         metrics,
+	}, nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) GetRuns(pipelineId int) ([]*CanaryAnalysisRunDto, error) {
+	runs, err := impl.canaryAnalysisRepository.FindRunsByPipelineId(pipelineId, canaryAnalysisRunListLimit)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting canary analysis runs", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	runDtos := make([]*CanaryAnalysisRunDto, 0, len(runs))
+	for _, run := range runs {
+		runDtos = append(runDtos, &CanaryAnalysisRunDto{
+			Id:                         run.Id,
+			PipelineId:                 run.PipelineId,
+			CdWorkflowRunnerId:         run.CdWorkflowRunnerId,
+			RollbackCdWorkflowRunnerId: run.RollbackCdWorkflowRunnerId,
+			Status:                     run.Status,
+			IntervalsCompleted:         run.IntervalsCompleted,
+			IntervalsFailed:            run.IntervalsFailed,
+			Message:                    run.Message,
+			CreatedOn:                  run.CreatedOn,
+			UpdatedOn:                  run.UpdatedOn,
+		})
+	}
+	return runDtos, nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) ProcessCanaryAnalysis() {
+	configs, err := impl.canaryAnalysisRepository.FindAllActiveConfigs()
+	if err != nil {
+		impl.logger.Errorw("error in getting active canary analysis configs", "err", err)
+		return
+	}
+	for _, config := range configs {
+		err = impl.startRunIfRequired(config)
+		if err != nil {
+			impl.logger.Errorw("error in starting canary analysis", "err", err, "pipelineId", config.PipelineId)
+		}
+	}
+	runs, err := impl.canaryAnalysisRepository.FindRunsByStatus(pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_RUNNING)
+	if err != nil {
+		impl.logger.Errorw("error in getting running canary analysis", "err", err)
+		return
+	}
+	for _, run := range runs {
+		if time.Now().Before(run.NextAnalysisOn) {
+			continue
+		}
+		claimedTill := time.Now().Add(canaryAnalysisClaimTimeout)
+		claimed, err := impl.canaryAnalysisRepository.ClaimRun(run.Id, run.NextAnalysisOn, claimedTill)
+		if err != nil {
+			impl.logger.Errorw("error in claiming canary analysis run", "err", err, "runId", run.Id)
+			continue
+		} else if !claimed {
+			continue
+		}
+		run.NextAnalysisOn = claimedTill
+		err = impl.analyseRun(run)
+		if err != nil {
+			impl.logger.Errorw("error in canary analysis", "err", err, "runId", run.Id)
+		}
+	}
+}
+
+// startRunIfRequired starts analysis for the latest deployment of the pipeline if it was deployed with the canary strategy,
+// rollbacks done on aborting a canary are not analysed again
+func (impl *CanaryAnalysisServiceImpl) startRunIfRequired(config *pipelineConfig.CanaryAnalysisConfig) error {
+	wfr, err := impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(config.PipelineId, bean2.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting latest deploy runner", "err", err, "pipelineId", config.PipelineId)
+		return err
+	}
+	if wfr.Id == 0 || wfr.StartedOn.Before(config.UpdatedOn) || wfr.Status == WorkflowFailed || wfr.Status == WorkflowAborted {
+		return nil
+	}
+	exists, err := impl.canaryAnalysisRepository.ExistsRunByWfrId(wfr.Id)
+	if err != nil || exists {
+		return err
+	}
+	isRollback, err := impl.canaryAnalysisRepository.ExistsRunByRollbackWfrId(wfr.Id)
+	if err != nil || isRollback {
+		return err
+	}
+	strategyHistory, err := impl.pipelineStrategyHistoryRepository.GetHistoryByPipelineIdAndWfrId(config.PipelineId, wfr.Id)
+	if err != nil && err != pg.ErrNoRows {
+		return err
+	}
+	if strategyHistory.Strategy != pipelineConfig.DEPLOYMENT_TEMPLATE_CANARY {
+		return nil
+	}
+	run := &pipelineConfig.CanaryAnalysisRun{
+		PipelineId:         config.PipelineId,
+		CdWorkflowRunnerId: wfr.Id,
+		Status:             pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_RUNNING,
+		NextAnalysisOn:     wfr.StartedOn.Add(time.Duration(config.IntervalSeconds) * time.Second),
+		AuditLog:           sql.AuditLog{CreatedOn: time.Now(), CreatedBy: canaryAnalysisSystemUserId, UpdatedOn: time.Now(), UpdatedBy: canaryAnalysisSystemUserId},
+	}
+	err = impl.canaryAnalysisRepository.SaveRun(run)
+	if err != nil {
+		impl.logger.Errorw("error in saving canary analysis run", "err", err, "run", run)
+		return err
+	}
+	impl.logger.Infow("canary analysis started", "pipelineId", config.PipelineId, "wfrId", wfr.Id, "runId", run.Id)
+	return nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) analyseRun(run *pipelineConfig.CanaryAnalysisRun) error {
+	latestWfr, err := impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(run.PipelineId, bean2.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil {
+		impl.logger.Errorw("error in getting latest deploy runner", "err", err, "pipelineId", run.PipelineId)
+		return err
+	}
+	if latestWfr.Id != run.CdWorkflowRunnerId {
+		return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_CANCELLED, "superseded by a newer deployment")
+	}
+	if latestWfr.Status == WorkflowFailed || latestWfr.Status == WorkflowAborted {
+		return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_CANCELLED, "deployment "+latestWfr.Status)
+	}
+	config, err := impl.canaryAnalysisRepository.FindActiveConfigByPipelineId(run.PipelineId)
+	if err == pg.ErrNoRows {
+		return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_CANCELLED, "canary analysis disabled for pipeline")
+	} else if err != nil {
+		impl.logger.Errorw("error in getting canary analysis config", "err", err, "pipelineId", run.PipelineId)
+		return err
+	}
+	cdPipeline, err := impl.pipelineRepository.FindById(run.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", run.PipelineId)
+		return err
+	}
+	env, err := impl.environmentRepository.FindById(cdPipeline.EnvironmentId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environment", "err", err, "envId", cdPipeline.EnvironmentId)
+		return err
+	}
+	metrics, err := parseCanaryMetrics(config.Metrics)
+	if err != nil {
+		impl.logger.Errorw("error in parsing canary metrics", "err", err, "configId", config.Id)
+		return err
+	}
+
+	passed, detail := impl.evaluateMetrics(cdPipeline, env, metrics)
+	run.IntervalsCompleted = run.IntervalsCompleted + 1
+	timelineStatus := pipelineConfig.TIMELINE_STATUS_CANARY_ANALYSIS_PASSED
+	if !passed {
+		run.IntervalsFailed = run.IntervalsFailed + 1
+		timelineStatus = pipelineConfig.TIMELINE_STATUS_CANARY_ANALYSIS_FAILED
+	}
+	impl.saveTimeline(run.CdWorkflowRunnerId, timelineStatus, fmt.Sprintf("Canary analysis %d/%d: %s", run.IntervalsCompleted, config.IntervalCount, detail))
+
+	if run.IntervalsFailed > config.FailureLimit {
+		return impl.abortCanary(run, cdPipeline, fmt.Sprintf("%d of %d intervals failed", run.IntervalsFailed, run.IntervalsCompleted))
+	}
+	if run.IntervalsCompleted >= config.IntervalCount {
+		return impl.promoteCanary(run, cdPipeline, env)
+	}
+	run.NextAnalysisOn = time.Now().Add(time.Duration(config.IntervalSeconds) * time.Second)
+	return impl.updateRun(run)
+}
+
+// evaluateMetrics queries each metric on the prometheus of the environment's cluster, a metric without data fails the interval
+func (impl *CanaryAnalysisServiceImpl) evaluateMetrics(cdPipeline *pipelineConfig.Pipeline, env *repository2.Environment, metrics []*CanaryMetricDto) (bool, string) {
+	if env.Cluster == nil || len(env.Cluster.PrometheusEndpoint) == 0 {
+		return false, "prometheus endpoint not configured for cluster"
+	}
+	prometheusAPI, err := prometheus.ContextByEnv(env.Name, env.Cluster.PrometheusEndpoint)
+	if err != nil {
+		impl.logger.Errorw("error in getting prometheus api client", "err", err, "envId", env.Id)
+		return false, "prometheus not reachable"
+	}
+	replacer := strings.NewReplacer(
+		"{{namespace}}", env.Namespace,
+		"{{appName}}", cdPipeline.App.AppName,
+		"{{envName}}", env.Name,
+		"{{releaseName}}", fmt.Sprintf("%s-%s", cdPipeline.App.AppName, env.Name))
+	passed := true
+	var results []string
+	for _, metric := range metrics {
+		query := replacer.Replace(metric.Query)
+		ctx, cancel := context.WithTimeout(context.Background(), application.TimeoutSlow)
+		out, _, err := prometheusAPI.Query(ctx, query, time.Now())
+		cancel()
+		if err != nil {
+			impl.logger.Errorw("canary metric query failed in prometheus", "err", err, "query", query)
+			passed = false
+			results = append(results, fmt.Sprintf("%s query failed", metric.Name))
+			continue
+		}
+		value, ok := getCanaryMetricValue(out)
+		if !ok {
+			passed = false
+			results = append(results, fmt.Sprintf("%s no data", metric.Name))
+			continue
+		}
+		metricPassed := isCanaryMetricPassed(value, metric.Condition, metric.Threshold)
+		if !metricPassed {
+			passed = false
+		}
+		results = append(results, fmt.Sprintf("%s=%g (%s %g) %s", metric.Name, value, metric.Condition, metric.Threshold, canaryMetricResult(metricPassed)))
+	}
+	return passed, strings.Join(results, ", ")
+}
+
+func (impl *CanaryAnalysisServiceImpl) promoteCanary(run *pipelineConfig.CanaryAnalysisRun, cdPipeline *pipelineConfig.Pipeline, env *repository2.Environment) error {
+	ctx, err := impl.buildContext()
+	if err != nil {
+		return err
+	}
+	appName := fmt.Sprintf("%s-%s", cdPipeline.App.AppName, env.Name)
+	resp, err := impl.application.ResourceTree(ctx, &application2.ResourcesQuery{ApplicationName: &appName})
+	if err != nil {
+		impl.logger.Errorw("error in getting resource tree of acd", "err", err, "appName", appName)
+		return err
+	}
+	var promoteErr error
+	rolloutFound := false
+	for _, node := range resp.Nodes {
+		if node.Kind != canaryRolloutKind {
+			continue
+		}
+		rolloutFound = true
+		action := canaryRolloutPromoteAction
+		kind, name, namespace, group, version := node.Kind, node.Name, node.Namespace, node.Group, node.Version
+		_, err = impl.application.RunResourceAction(ctx, &application2.ResourceActionRunRequest{
+			Name:         &appName,
+			Namespace:    &namespace,
+			ResourceName: &name,
+			Version:      &version,
+			Group:        &group,
+			Kind:         &kind,
+			Action:       &action,
+		})
+		if err != nil {
+			impl.logger.Errorw("error in promoting canary rollout", "err", err, "appName", appName, "rollout", name)
+			promoteErr = err
+		}
+	}
+	if !rolloutFound {
+		impl.saveTimeline(run.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_CANARY_PROMOTED, "Canary analysis passed, no rollout found to promote.")
+		return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_FAILED, "no rollout found in application "+appName)
+	}
+	if promoteErr != nil {
+		impl.saveTimeline(run.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_CANARY_PROMOTED, "Canary analysis passed, promotion failed: "+promoteErr.Error())
+		return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_FAILED, promoteErr.Error())
+	}
+	impl.saveTimeline(run.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_CANARY_PROMOTED, "Canary analysis passed, canary promoted.")
+	return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_PROMOTED, fmt.Sprintf("%d of %d intervals failed", run.IntervalsFailed, run.IntervalsCompleted))
+}
+
+// abortCanary rolls the pipeline back to its previous healthy deployment, which replaces the canary with the stable version
+func (impl *CanaryAnalysisServiceImpl) abortCanary(run *pipelineConfig.CanaryAnalysisRun, cdPipeline *pipelineConfig.Pipeline, reason string) error {
+	target, err := impl.cdWorkflowRepository.FindLatestCdWfRunnerWithStatusBeforeId(cdPipeline.Id, run.CdWorkflowRunnerId, []string{application.Healthy})
+	if err == pg.ErrNoRows {
+		impl.saveTimeline(run.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_CANARY_ABORTED, fmt.Sprintf("Canary analysis failed (%s), no previous healthy deployment found to roll back to.", reason))
+		return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_FAILED, reason+", no previous healthy deployment found")
+	} else if err != nil {
+		impl.logger.Errorw("error in getting previous healthy deployment", "err", err, "pipelineId", cdPipeline.Id)
+		return err
+	}
+	ctx, err := impl.buildContext()
+	if err != nil {
+		return err
+	}
+	overrideRequest := &bean2.ValuesOverrideRequest{
+		PipelineId:                            cdPipeline.Id,
+		AppId:                                 cdPipeline.AppId,
+		CiArtifactId:                          target.CdWorkflow.CiArtifactId,
+		CdWorkflowType:                        bean2.CD_WORKFLOW_TYPE_DEPLOY,
+		DeploymentWithConfig:                  bean2.DEPLOYMENT_CONFIG_TYPE_SPECIFIC_TRIGGER,
+		WfrIdForDeploymentWithSpecificTrigger: target.Id,
+		DeploymentType:                        models.DEPLOYMENTTYPE_ROLLBACK,
+		IsAutoRollback:                        true,
+		UserId:                                canaryAnalysisSystemUserId,
+	}
+	// marking the run before triggering so that the rollback deployment is not picked up for analysis
+	run.Status = pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_ABORTED
+	run.Message = reason
+	err = impl.updateRun(run)
+	if err != nil {
+		return err
+	}
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("error in rolling back canary", "err", err, "overrideRequest", overrideRequest)
+		impl.saveTimeline(run.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_CANARY_ABORTED, fmt.Sprintf("Canary analysis failed (%s), rollback failed: %s", reason, err.Error()))
+		return impl.finishRun(run, pipelineConfig.CANARY_ANALYSIS_RUN_STATUS_FAILED, reason+", rollback failed: "+err.Error())
+	}
+	// the trigger sets the workflow it created, reading the latest runner of the pipeline could pick a concurrent deployment
+	rollbackWfr, err := impl.cdWorkflowRepository.FindByWorkflowIdAndRunnerType(overrideRequest.CdWorkflowId, bean2.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil {
+		impl.logger.Errorw("error in getting rollback runner", "err", err, "cdWorkflowId", overrideRequest.CdWorkflowId)
+	} else {
+		run.RollbackCdWorkflowRunnerId = rollbackWfr.Id
+	}
+	impl.saveTimeline(run.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_CANARY_ABORTED, fmt.Sprintf("Canary analysis failed (%s), canary aborted and rolled back to image %s.", reason, target.CdWorkflow.CiArtifact.Image))
+	return impl.updateRun(run)
+}
+
+func (impl *CanaryAnalysisServiceImpl) finishRun(run *pipelineConfig.CanaryAnalysisRun, status pipelineConfig.CanaryAnalysisRunStatus, message string) error {
+	run.Status = status
+	run.Message = message
+	impl.logger.Infow("canary analysis finished", "runId", run.Id, "status", status, "message", message)
+	return impl.updateRun(run)
+}
+
+func (impl *CanaryAnalysisServiceImpl) updateRun(run *pipelineConfig.CanaryAnalysisRun) error {
+	run.UpdatedOn = time.Now()
+	run.UpdatedBy = canaryAnalysisSystemUserId
+	err := impl.canaryAnalysisRepository.UpdateRun(run)
+	if err != nil {
+		impl.logger.Errorw("error in updating canary analysis run", "err", err, "run", run)
+	}
+	return err
+}
+
+func (impl *CanaryAnalysisServiceImpl) saveTimeline(wfrId int, status pipelineConfig.TimelineStatus, statusDetail string) {
+	timeline := &pipelineConfig.PipelineStatusTimeline{
+		CdWorkflowRunnerId: wfrId,
+		Status:             status,
+		StatusDetail:       statusDetail,
+		StatusTime:         time.Now(),
+		AuditLog: sql.AuditLog{
+			CreatedBy: canaryAnalysisSystemUserId,
+			CreatedOn: time.Now(),
+			UpdatedBy: canaryAnalysisSystemUserId,
+			UpdatedOn: time.Now(),
+		},
+	}
+	err := impl.pipelineStatusTimelineRepository.SaveTimeline(timeline)
+	if err != nil {
+		impl.logger.Errorw("error in saving canary analysis timeline", "err", err, "timeline", timeline)
+	}
+}
+
+func (impl *CanaryAnalysisServiceImpl) buildContext() (context.Context, error) {
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return nil, err
+	}
+	return context.WithValue(context.Background(), "token", acdToken), nil
+}
+
+func parseCanaryMetrics(metricsJson string) ([]*CanaryMetricDto, error) {
+	metrics := make([]*CanaryMetricDto, 0)
+	if len(metricsJson) == 0 {
+		return metrics, nil
+	}
+	err := json.Unmarshal([]byte(metricsJson), &metrics)
+	return metrics, err
+}
+
+// getCanaryMetricValue reads the value of a scalar result or the first sample of a vector result
+func getCanaryMetricValue(out model.Value) (float64, bool) {
+	switch result := out.(type) {
+	case *model.Scalar:
+		return float64(result.Value), true
+	case model.Vector:
+		if len(result) == 0 {
+			return 0, false
+		}
+		return float64(result[0].Value), true
+	}
+	return 0, false
+}
+
+func isCanaryMetricPassed(value float64, condition string, threshold float64) bool {
+	switch condition {
+	case CANARY_METRIC_CONDITION_LT:
+		return value < threshold
+	case CANARY_METRIC_CONDITION_LTE:
+		return value <= threshold
+	case CANARY_METRIC_CONDITION_GT:
+		return value > threshold
+	case CANARY_METRIC_CONDITION_GTE:
+		return value >= threshold
+	}
+	return false
+}
+
+func canaryMetricResult(passed bool) string {
+	if passed {
+		return "passed"
+	}
+	return "failed"
+}
@@ -0,0 +1,821 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	application2 "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	bean2 "github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/client/argocdServer/application"
+	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const cdFanOutSystemUserId int32 = 1
+
+type CdFanOutService interface {
+	GetConfig(pipelineId int) (*CdFanOutConfigDto, error)
+	// SaveConfig adds or removes the target environments of a fan-out pipeline as per the request,
+	// an empty target list turns fan-out off for the pipeline
+	SaveConfig(configDto *CdFanOutConfigDto) (*CdFanOutConfigDto, error)
+	GetLatestExecution(pipelineId int) (*CdFanOutExecutionDto, error)
+	// ResumeExecution deploys the next wave of the pipeline's latest rollout once the paused wave is verified
+	ResumeExecution(pipelineId int, userId int32) (*CdFanOutExecutionDto, error)
+	// GetNextWaveEnvironmentIds returns the target environments which are deployed on resuming the pipeline's latest rollout
+	GetNextWaveEnvironmentIds(pipelineId int) ([]int, error)
+	// ProcessFanOutDeployments starts rollouts for new deployments of fan-out pipelines and
+	// moves running rollouts forward as per the status of their current wave
+	ProcessFanOutDeployments()
+}
+
+type CdFanOutServiceImpl struct {
+	logger                     *zap.SugaredLogger
+	cdFanOutRepository         pipelineConfig.CdFanOutRepository
+	pipelineRepository         pipelineConfig.PipelineRepository
+	cdWorkflowRepository       pipelineConfig.CdWorkflowRepository
+	pipelineOverrideRepository chartConfig.PipelineOverrideRepository
+	environmentRepository      repository2.EnvironmentRepository
+	workflowDagExecutor        WorkflowDagExecutor
+	application                application.ServiceClient
+	argoUserService            argo.ArgoUserService
+}
+
+func NewCdFanOutServiceImpl(logger *zap.SugaredLogger,
+	cdFanOutRepository pipelineConfig.CdFanOutRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pipelineOverrideRepository chartConfig.PipelineOverrideRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	workflowDagExecutor WorkflowDagExecutor,
+	application application.ServiceClient,
+	argoUserService argo.ArgoUserService) *CdFanOutServiceImpl {
+	return &CdFanOutServiceImpl{
+		logger:                     logger,
+		cdFanOutRepository:         cdFanOutRepository,
+		pipelineRepository:         pipelineRepository,
+		cdWorkflowRepository:       cdWorkflowRepository,
+		pipelineOverrideRepository: pipelineOverrideRepository,
+		environmentRepository:      environmentRepository,
+		workflowDagExecutor:        workflowDagExecutor,
+		application:                application,
+		argoUserService:            argoUserService,
+	}
+}
+
+type CdFanOutConfigDto struct {
+	Id                int                             `json:"id"`
+	PipelineId        int                             `json:"pipelineId" validate:"required"`
+	Strategy          pipelineConfig.CdFanOutStrategy `json:"strategy" validate:"oneof=SEQUENTIAL PARALLEL"`
+	PauseBetweenWaves bool                            `json:"pauseBetweenWaves"`
+	Targets           []*CdFanOutTargetDto            `json:"targets" validate:"dive"`
+	UserId            int32                           `json:"-"`
+}
+
+// CdFanOutTargetDto is an additional environment of the fan-out pipeline, wave is ignored for parallel rollouts.
+// Values of the environment are overridden through the app's environment override of that environment.
+type CdFanOutTargetDto struct {
+	EnvironmentId   int    `json:"environmentId" validate:"required"`
+	EnvironmentName string `json:"environmentName"`
+	Wave            int    `json:"wave" validate:"min=0"`
+}
+
+type CdFanOutExecutionDto struct {
+	Id                 int                                    `json:"id"`
+	PipelineId         int                                    `json:"pipelineId"`
+	CdWorkflowId       int                                    `json:"cdWorkflowId"`
+	CdWorkflowRunnerId int                                    `json:"cdWorkflowRunnerId"`
+	CiArtifactId       int                                    `json:"ciArtifactId"`
+	Strategy           pipelineConfig.CdFanOutStrategy        `json:"strategy"`
+	CurrentWave        int                                    `json:"currentWave"`
+	Status             pipelineConfig.CdFanOutExecutionStatus `json:"status"`
+	Targets            []*CdFanOutExecutionTargetDto          `json:"targets"`
+	CreatedOn          time.Time                              `json:"createdOn"`
+}
+
+type CdFanOutExecutionTargetDto struct {
+	EnvironmentId      int                                 `json:"environmentId"`
+	EnvironmentName    string                              `json:"environmentName"`
+	Wave               int                                 `json:"wave"`
+	CdWorkflowRunnerId int                                 `json:"cdWorkflowRunnerId"`
+	Status             pipelineConfig.CdFanOutTargetStatus `json:"status"`
+	Message            string                              `json:"message"`
+}
+
+func (impl *CdFanOutServiceImpl) GetConfig(pipelineId int) (*CdFanOutConfigDto, error) {
+	config, err := impl.cdFanOutRepository.FindActiveConfigByPipelineId(pipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out config", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		return &CdFanOutConfigDto{PipelineId: pipelineId, Strategy: pipelineConfig.CD_FAN_OUT_STRATEGY_SEQUENTIAL, Targets: []*CdFanOutTargetDto{}}, nil
+	}
+	targets, err := impl.cdFanOutRepository.FindActiveTargetsByConfigId(config.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out targets", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	configDto := &CdFanOutConfigDto{
+		Id:                config.Id,
+		PipelineId:        config.PipelineId,
+		Strategy:          config.Strategy,
+		PauseBetweenWaves: config.PauseBetweenWaves,
+		Targets:           []*CdFanOutTargetDto{},
+	}
+	for _, target := range targets {
+		configDto.Targets = append(configDto.Targets, &CdFanOutTargetDto{
+			EnvironmentId:   target.EnvironmentId,
+			EnvironmentName: impl.getEnvironmentName(target.EnvironmentId),
+			Wave:            target.Wave,
+		})
+	}
+	return configDto, nil
+}
+
+func (impl *CdFanOutServiceImpl) SaveConfig(configDto *CdFanOutConfigDto) (*CdFanOutConfigDto, error) {
+	cdPipeline, err := impl.pipelineRepository.FindById(configDto.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd pipeline", "pipelineId", configDto.PipelineId, "err", err)
+		return nil, err
+	}
+	config, err := impl.cdFanOutRepository.FindActiveConfigByPipelineId(configDto.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out config", "pipelineId", configDto.PipelineId, "err", err)
+		return nil, err
+	}
+	err = impl.validateConfig(cdPipeline, config.Id, configDto)
+	if err != nil {
+		return nil, err
+	}
+	if config.Id > 0 {
+		execution, err := impl.cdFanOutRepository.FindLatestExecutionByConfigId(config.Id)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching latest fan-out execution", "configId", config.Id, "err", err)
+			return nil, err
+		}
+		if execution.Status == pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_RUNNING || execution.Status == pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_PAUSED {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "fan-out rollout in progress", UserMessage: "a fan-out rollout is in progress for this pipeline, please retry once it completes"}
+		}
+	}
+	config.PipelineId = configDto.PipelineId
+	config.Strategy = configDto.Strategy
+	config.PauseBetweenWaves = configDto.PauseBetweenWaves
+	config.Active = len(configDto.Targets) > 0
+	config.UpdatedOn = time.Now()
+	config.UpdatedBy = configDto.UserId
+	if config.Id == 0 {
+		if !config.Active {
+			return impl.GetConfig(configDto.PipelineId)
+		}
+		config.CreatedOn = time.Now()
+		config.CreatedBy = configDto.UserId
+		err = impl.cdFanOutRepository.SaveConfig(config)
+	} else {
+		err = impl.cdFanOutRepository.UpdateConfig(config)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving fan-out config", "config", config, "err", err)
+		return nil, err
+	}
+
+	existingTargets, err := impl.cdFanOutRepository.FindActiveTargetsByConfigId(config.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out targets", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	existingTargetMap := make(map[int]*pipelineConfig.CdFanOutTarget)
+	for _, target := range existingTargets {
+		existingTargetMap[target.EnvironmentId] = target
+	}
+	ctx, err := impl.buildContext()
+	if err != nil {
+		return nil, err
+	}
+	requestedEnvs := make(map[int]bool)
+	for _, targetDto := range configDto.Targets {
+		requestedEnvs[targetDto.EnvironmentId] = true
+		wave := targetDto.Wave
+		if configDto.Strategy == pipelineConfig.CD_FAN_OUT_STRATEGY_PARALLEL || wave == 0 {
+			wave = 1
+		}
+		if target, ok := existingTargetMap[targetDto.EnvironmentId]; ok {
+			if target.Wave != wave {
+				target.Wave = wave
+				target.UpdatedOn = time.Now()
+				target.UpdatedBy = configDto.UserId
+				err = impl.cdFanOutRepository.UpdateTarget(target)
+				if err != nil {
+					impl.logger.Errorw("error in updating fan-out target", "target", target, "err", err)
+					return nil, err
+				}
+			}
+			continue
+		}
+		target := &pipelineConfig.CdFanOutTarget{
+			CdFanOutConfigId: config.Id,
+			EnvironmentId:    targetDto.EnvironmentId,
+			Wave:             wave,
+			Active:           true,
+			AuditLog:         sql.AuditLog{CreatedOn: time.Now(), CreatedBy: configDto.UserId, UpdatedOn: time.Now(), UpdatedBy: configDto.UserId},
+		}
+		err = impl.cdFanOutRepository.SaveTarget(target)
+		if err != nil {
+			impl.logger.Errorw("error in saving fan-out target", "target", target, "err", err)
+			return nil, err
+		}
+	}
+	for envId, target := range existingTargetMap {
+		if requestedEnvs[envId] {
+			continue
+		}
+		err = impl.removeTarget(ctx, cdPipeline, target, configDto.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return impl.GetConfig(configDto.PipelineId)
+}
+
+func (impl *CdFanOutServiceImpl) validateConfig(cdPipeline *pipelineConfig.Pipeline, configId int, configDto *CdFanOutConfigDto) error {
+	if len(configDto.Targets) > 0 && !util.IsAcdApp(cdPipeline.DeploymentAppType) {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "fan-out on non gitops pipeline", UserMessage: "fan-out is supported for pipelines deploying through gitops only"}
+	}
+	envs := make(map[int]bool)
+	for _, target := range configDto.Targets {
+		if target.EnvironmentId == cdPipeline.EnvironmentId {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "fan-out target same as pipeline environment", UserMessage: "pipeline environment is deployed in wave 0 and can not be a fan-out target"}
+		}
+		if envs[target.EnvironmentId] {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "duplicate fan-out target", UserMessage: fmt.Sprintf("environment %d is added more than once", target.EnvironmentId)}
+		}
+		envs[target.EnvironmentId] = true
+		env, err := impl.environmentRepository.FindById(target.EnvironmentId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching environment", "envId", target.EnvironmentId, "err", err)
+			return err
+		}
+		if env.GitOpsPrRequired {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "fan-out target requires pull requests", UserMessage: fmt.Sprintf("environment %s deploys through pull requests and can not be a fan-out target", env.Name)}
+		}
+		// deployment app of an app on an environment is named after both, so the environment can not be deployed otherwise
+		envPipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(cdPipeline.AppId, target.EnvironmentId)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.logger.Errorw("error in fetching cd pipelines of environment", "appId", cdPipeline.AppId, "envId", target.EnvironmentId, "err", err)
+			return err
+		}
+		if len(envPipelines) > 0 {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "fan-out target has a cd pipeline", UserMessage: fmt.Sprintf("environment %s already has a cd pipeline for this app", env.Name)}
+		}
+		envTargets, err := impl.cdFanOutRepository.FindActiveTargetsByAppIdAndEnvId(cdPipeline.AppId, target.EnvironmentId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching fan-out targets of environment", "appId", cdPipeline.AppId, "envId", target.EnvironmentId, "err", err)
+			return err
+		}
+		for _, envTarget := range envTargets {
+			if envTarget.CdFanOutConfigId != configId {
+				return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "fan-out target of another pipeline", UserMessage: fmt.Sprintf("environment %s is a fan-out target of another pipeline of this app", env.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+// removeTarget deletes the deployment app of the target environment, if created, and removes it from fan-out
+func (impl *CdFanOutServiceImpl) removeTarget(ctx context.Context, cdPipeline *pipelineConfig.Pipeline, target *pipelineConfig.CdFanOutTarget, userId int32) error {
+	if target.DeploymentAppCreated {
+		deploymentAppName := fmt.Sprintf("%s-%s", cdPipeline.App.AppName, impl.getEnvironmentName(target.EnvironmentId))
+		cascadeDelete := true
+		req := &application2.ApplicationDeleteRequest{
+			Name:    &deploymentAppName,
+			Cascade: &cascadeDelete,
+		}
+		if _, err := impl.application.Delete(ctx, req); err != nil {
+			impl.logger.Errorw("error in deleting fan-out deployment app", "name", deploymentAppName, "err", err)
+			return &util.ApiError{HttpStatusCode: http.StatusInternalServerError, InternalMessage: err.Error(), UserMessage: fmt.Sprintf("could not delete application %s", deploymentAppName)}
+		}
+	}
+	target.Active = false
+	target.UpdatedOn = time.Now()
+	target.UpdatedBy = userId
+	err := impl.cdFanOutRepository.UpdateTarget(target)
+	if err != nil {
+		impl.logger.Errorw("error in updating fan-out target", "target", target, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *CdFanOutServiceImpl) GetLatestExecution(pipelineId int) (*CdFanOutExecutionDto, error) {
+	config, err := impl.cdFanOutRepository.FindActiveConfigByPipelineId(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching fan-out config", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	execution, err := impl.cdFanOutRepository.FindLatestExecutionByConfigId(config.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching latest fan-out execution", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	return impl.buildExecutionDto(config, execution)
+}
+
+func (impl *CdFanOutServiceImpl) ResumeExecution(pipelineId int, userId int32) (*CdFanOutExecutionDto, error) {
+	config, err := impl.cdFanOutRepository.FindActiveConfigByPipelineId(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching fan-out config", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	execution, err := impl.cdFanOutRepository.FindLatestExecutionByConfigId(config.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching latest fan-out execution", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	if execution.Status != pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_PAUSED {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "fan-out execution not paused", UserMessage: fmt.Sprintf("fan-out rollout is %s, only a paused rollout can be resumed", execution.Status)}
+	}
+	claimed, err := impl.claimExecution(execution)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "fan-out execution updated concurrently", UserMessage: "fan-out rollout was updated in the meantime, please refresh and retry"}
+	}
+	targets, err := impl.cdFanOutRepository.FindExecutionTargetsByExecutionId(execution.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching fan-out execution targets", "executionId", execution.Id, "err", err)
+		return nil, err
+	}
+	ctx, err := impl.buildContext()
+	if err != nil {
+		return nil, err
+	}
+	nextWave := impl.getNextWave(execution.CurrentWave, targets)
+	err = impl.triggerWave(ctx, execution, targets, nextWave, userId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.buildExecutionDto(config, execution)
+}
+
+func (impl *CdFanOutServiceImpl) GetNextWaveEnvironmentIds(pipelineId int) ([]int, error) {
+	config, err := impl.cdFanOutRepository.FindActiveConfigByPipelineId(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching fan-out config", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	execution, err := impl.cdFanOutRepository.FindLatestExecutionByConfigId(config.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching latest fan-out execution", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	targets, err := impl.cdFanOutRepository.FindExecutionTargetsByExecutionId(execution.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out execution targets", "executionId", execution.Id, "err", err)
+		return nil, err
+	}
+	nextWave := impl.getNextWave(execution.CurrentWave, targets)
+	envIds := make([]int, 0)
+	for _, target := range targets {
+		if nextWave > 0 && target.Wave == nextWave && target.Status == pipelineConfig.CD_FAN_OUT_TARGET_STATUS_PENDING {
+			envIds = append(envIds, target.EnvironmentId)
+		}
+	}
+	return envIds, nil
+}
+
+func (impl *CdFanOutServiceImpl) buildExecutionDto(config *pipelineConfig.CdFanOutConfig, execution *pipelineConfig.CdFanOutExecution) (*CdFanOutExecutionDto, error) {
+	targets, err := impl.cdFanOutRepository.FindExecutionTargetsByExecutionId(execution.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out execution targets", "executionId", execution.Id, "err", err)
+		return nil, err
+	}
+	executionDto := &CdFanOutExecutionDto{
+		Id:                 execution.Id,
+		PipelineId:         config.PipelineId,
+		CdWorkflowId:       execution.CdWorkflowId,
+		CdWorkflowRunnerId: execution.CdWorkflowRunnerId,
+		CiArtifactId:       execution.CiArtifactId,
+		Strategy:           config.Strategy,
+		CurrentWave:        execution.CurrentWave,
+		Status:             execution.Status,
+		Targets:            []*CdFanOutExecutionTargetDto{},
+		CreatedOn:          execution.CreatedOn,
+	}
+	for _, target := range targets {
+		executionDto.Targets = append(executionDto.Targets, &CdFanOutExecutionTargetDto{
+			EnvironmentId:      target.EnvironmentId,
+			EnvironmentName:    impl.getEnvironmentName(target.EnvironmentId),
+			Wave:               target.Wave,
+			CdWorkflowRunnerId: target.CdWorkflowRunnerId,
+			Status:             target.Status,
+			Message:            target.Message,
+		})
+	}
+	return executionDto, nil
+}
+
+func (impl *CdFanOutServiceImpl) ProcessFanOutDeployments() {
+	configs, err := impl.cdFanOutRepository.FindAllActiveConfigs()
+	if err != nil {
+		impl.logger.Errorw("error in fetching active fan-out configs", "err", err)
+		return
+	}
+	if len(configs) == 0 {
+		return
+	}
+	ctx, err := impl.buildContext()
+	if err != nil {
+		return
+	}
+	for _, config := range configs {
+		err = impl.startExecutionIfRequired(ctx, config)
+		if err != nil {
+			impl.logger.Errorw("error in starting fan-out execution", "config", config, "err", err)
+		}
+	}
+	executions, err := impl.cdFanOutRepository.FindExecutionsByStatus([]pipelineConfig.CdFanOutExecutionStatus{pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_RUNNING})
+	if err != nil {
+		impl.logger.Errorw("error in fetching running fan-out executions", "err", err)
+		return
+	}
+	for _, execution := range executions {
+		claimed, err := impl.claimExecution(execution)
+		if err != nil || !claimed {
+			continue
+		}
+		err = impl.processExecution(ctx, execution)
+		if err != nil {
+			impl.logger.Errorw("error in processing fan-out execution", "execution", execution, "err", err)
+		}
+	}
+}
+
+// claimExecution makes sure only one instance moves the rollout forward, the execution is claimed only if it was not
+// updated since it was read
+func (impl *CdFanOutServiceImpl) claimExecution(execution *pipelineConfig.CdFanOutExecution) (bool, error) {
+	claimedOn := time.Now()
+	claimed, err := impl.cdFanOutRepository.ClaimExecution(execution.Id, execution.UpdatedOn, claimedOn)
+	if err != nil {
+		impl.logger.Errorw("error in claiming fan-out execution", "executionId", execution.Id, "err", err)
+		return false, err
+	}
+	if claimed {
+		execution.UpdatedOn = claimedOn
+	}
+	return claimed, nil
+}
+
+// startExecutionIfRequired starts a rollout for a deployment of the fan-out pipeline which has not been fanned out yet,
+// a rollout still in progress for an older deployment is superseded by it
+func (impl *CdFanOutServiceImpl) startExecutionIfRequired(ctx context.Context, config *pipelineConfig.CdFanOutConfig) error {
+	wfr, err := impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(config.PipelineId, bean2.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in fetching latest deployment of pipeline", "pipelineId", config.PipelineId, "err", err)
+		return err
+	}
+	if wfr.Id == 0 || wfr.StartedOn.Before(config.CreatedOn) || wfr.Id <= config.LastCdWorkflowRunnerId {
+		return nil
+	}
+	claimed, err := impl.cdFanOutRepository.ClaimDeployment(config.Id, config.LastCdWorkflowRunnerId, wfr.Id)
+	if err != nil {
+		impl.logger.Errorw("error in claiming deployment for fan-out", "configId", config.Id, "wfrId", wfr.Id, "err", err)
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+	latestExecution, err := impl.cdFanOutRepository.FindLatestExecutionByConfigId(config.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching latest fan-out execution", "configId", config.Id, "err", err)
+		return err
+	}
+	if latestExecution.Status == pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_RUNNING || latestExecution.Status == pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_PAUSED {
+		err = impl.finishExecution(latestExecution, pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_SUPERSEDED, "superseded by a newer deployment")
+		if err != nil {
+			return err
+		}
+	}
+	targets, err := impl.cdFanOutRepository.FindActiveTargetsByConfigId(config.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out targets", "configId", config.Id, "err", err)
+		return err
+	}
+	execution := &pipelineConfig.CdFanOutExecution{
+		CdFanOutConfigId:   config.Id,
+		CdWorkflowId:       wfr.CdWorkflowId,
+		CdWorkflowRunnerId: wfr.Id,
+		CiArtifactId:       wfr.CdWorkflow.CiArtifactId,
+		CurrentWave:        0,
+		Status:             pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_RUNNING,
+		AuditLog:           sql.AuditLog{CreatedOn: time.Now(), CreatedBy: wfr.TriggeredBy, UpdatedOn: time.Now(), UpdatedBy: wfr.TriggeredBy},
+	}
+	err = impl.cdFanOutRepository.SaveExecution(execution)
+	if err != nil {
+		impl.logger.Errorw("error in saving fan-out execution", "execution", execution, "err", err)
+		return err
+	}
+	var executionTargets []*pipelineConfig.CdFanOutExecutionTarget
+	for _, target := range targets {
+		executionTarget := &pipelineConfig.CdFanOutExecutionTarget{
+			CdFanOutExecutionId: execution.Id,
+			EnvironmentId:       target.EnvironmentId,
+			Wave:                target.Wave,
+			Status:              pipelineConfig.CD_FAN_OUT_TARGET_STATUS_PENDING,
+			AuditLog:            sql.AuditLog{CreatedOn: time.Now(), CreatedBy: wfr.TriggeredBy, UpdatedOn: time.Now(), UpdatedBy: wfr.TriggeredBy},
+		}
+		err = impl.cdFanOutRepository.SaveExecutionTarget(executionTarget)
+		if err != nil {
+			impl.logger.Errorw("error in saving fan-out execution target", "target", executionTarget, "err", err)
+			return err
+		}
+		executionTargets = append(executionTargets, executionTarget)
+	}
+	impl.logger.Infow("fan-out rollout started", "pipelineId", config.PipelineId, "wfrId", wfr.Id, "executionId", execution.Id)
+	if config.Strategy == pipelineConfig.CD_FAN_OUT_STRATEGY_PARALLEL {
+		// parallel rollouts do not wait for the pipeline's own environment
+		return impl.triggerWave(ctx, execution, executionTargets, impl.getNextWave(0, executionTargets), wfr.TriggeredBy)
+	}
+	return nil
+}
+
+func (impl *CdFanOutServiceImpl) processExecution(ctx context.Context, execution *pipelineConfig.CdFanOutExecution) error {
+	config, err := impl.cdFanOutRepository.FindConfigById(execution.CdFanOutConfigId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching fan-out config", "id", execution.CdFanOutConfigId, "err", err)
+		return err
+	}
+	targets, err := impl.cdFanOutRepository.FindExecutionTargetsByExecutionId(execution.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out execution targets", "executionId", execution.Id, "err", err)
+		return err
+	}
+	if execution.CurrentWave == 0 && config.Strategy == pipelineConfig.CD_FAN_OUT_STRATEGY_SEQUENTIAL {
+		wfr, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(execution.CdWorkflowRunnerId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching cd workflow runner", "wfrId", execution.CdWorkflowRunnerId, "err", err)
+			return err
+		}
+		if isFanOutDeploymentFailed(wfr.Status) {
+			return impl.finishExecution(execution, pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_FAILED, fmt.Sprintf("deployment of pipeline environment %s", wfr.Status))
+		}
+		if !isFanOutDeploymentSucceeded(wfr.Status) {
+			return nil
+		}
+	} else {
+		cdPipeline, err := impl.pipelineRepository.FindById(config.PipelineId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching cd pipeline", "pipelineId", config.PipelineId, "err", err)
+			return err
+		}
+		waveCompleted := true
+		for _, target := range targets {
+			if target.Wave != execution.CurrentWave || target.Status != pipelineConfig.CD_FAN_OUT_TARGET_STATUS_TRIGGERED {
+				continue
+			}
+			wfr, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(target.CdWorkflowRunnerId)
+			if err != nil {
+				impl.logger.Errorw("error in fetching cd workflow runner", "wfrId", target.CdWorkflowRunnerId, "err", err)
+				return err
+			}
+			if !isFanOutDeploymentSucceeded(wfr.Status) && !isFanOutDeploymentFailed(wfr.Status) {
+				err = impl.syncTargetDeploymentStatus(ctx, cdPipeline, target, wfr)
+				if err != nil {
+					return err
+				}
+			}
+			if isFanOutDeploymentSucceeded(wfr.Status) {
+				err = impl.updateExecutionTarget(target, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_SUCCEEDED, "")
+			} else if isFanOutDeploymentFailed(wfr.Status) {
+				err = impl.updateExecutionTarget(target, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_FAILED, fmt.Sprintf("deployment %s", wfr.Status))
+			} else {
+				waveCompleted = false
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, target := range targets {
+			if target.Status == pipelineConfig.CD_FAN_OUT_TARGET_STATUS_FAILED {
+				return impl.finishExecution(execution, pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_FAILED, "skipped as a previous environment failed")
+			}
+		}
+		if !waveCompleted {
+			return nil
+		}
+	}
+	nextWave := impl.getNextWave(execution.CurrentWave, targets)
+	if nextWave == 0 {
+		return impl.finishExecution(execution, pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_SUCCEEDED, "")
+	}
+	if config.PauseBetweenWaves {
+		execution.Status = pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_PAUSED
+		return impl.updateExecution(execution, cdFanOutSystemUserId)
+	}
+	return impl.triggerWave(ctx, execution, targets, nextWave, execution.CreatedBy)
+}
+
+// syncTargetDeploymentStatus updates the fan-out runner of a target with the health of its deployment app, once the app
+// has synced the release of the runner. Status of the pipeline's own deployment app is updated by the app status listener.
+func (impl *CdFanOutServiceImpl) syncTargetDeploymentStatus(ctx context.Context, cdPipeline *pipelineConfig.Pipeline, target *pipelineConfig.CdFanOutExecutionTarget, wfr *pipelineConfig.CdWorkflowRunner) error {
+	pipelineOverride, err := impl.pipelineOverrideRepository.FindById(target.PipelineOverrideId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching release of fan-out target", "pipelineOverrideId", target.PipelineOverrideId, "err", err)
+		return err
+	}
+	deploymentAppName := fmt.Sprintf("%s-%s", cdPipeline.App.AppName, impl.getEnvironmentName(target.EnvironmentId))
+	deploymentApp, err := impl.application.Get(ctx, &application2.ApplicationQuery{Name: &deploymentAppName})
+	if err != nil {
+		impl.logger.Errorw("error in fetching fan-out deployment app", "name", deploymentAppName, "err", err)
+		return err
+	}
+	if deploymentApp.Status.Sync.Revision != pipelineOverride.GitHash {
+		return nil
+	}
+	healthStatus := deploymentApp.Status.Health.Status
+	if healthStatus != health.HealthStatusHealthy && healthStatus != health.HealthStatusDegraded {
+		return nil
+	}
+	wfr.Status = string(healthStatus)
+	wfr.FinishedOn = time.Now()
+	err = impl.cdWorkflowRepository.UpdateWorkFlowRunner(wfr)
+	if err != nil {
+		impl.logger.Errorw("error in updating fan-out cd workflow runner", "wfrId", wfr.Id, "err", err)
+		return err
+	}
+	return nil
+}
+
+// getNextWave returns the lowest wave after the current one having pending targets, 0 if there is none
+func (impl *CdFanOutServiceImpl) getNextWave(currentWave int, targets []*pipelineConfig.CdFanOutExecutionTarget) int {
+	nextWave := 0
+	for _, target := range targets {
+		if target.Status != pipelineConfig.CD_FAN_OUT_TARGET_STATUS_PENDING || target.Wave <= currentWave {
+			continue
+		}
+		if nextWave == 0 || target.Wave < nextWave {
+			nextWave = target.Wave
+		}
+	}
+	return nextWave
+}
+
+func (impl *CdFanOutServiceImpl) triggerWave(ctx context.Context, execution *pipelineConfig.CdFanOutExecution, targets []*pipelineConfig.CdFanOutExecutionTarget, wave int, userId int32) error {
+	if wave == 0 {
+		return impl.finishExecution(execution, pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_SUCCEEDED, "")
+	}
+	config, err := impl.cdFanOutRepository.FindConfigById(execution.CdFanOutConfigId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching fan-out config", "id", execution.CdFanOutConfigId, "err", err)
+		return err
+	}
+	configTargets, err := impl.cdFanOutRepository.FindActiveTargetsByConfigId(execution.CdFanOutConfigId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out targets", "configId", execution.CdFanOutConfigId, "err", err)
+		return err
+	}
+	configTargetMap := make(map[int]*pipelineConfig.CdFanOutTarget)
+	for _, configTarget := range configTargets {
+		configTargetMap[configTarget.EnvironmentId] = configTarget
+	}
+	execution.CurrentWave = wave
+	execution.Status = pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_RUNNING
+	err = impl.updateExecution(execution, userId)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if target.Wave != wave || target.Status != pipelineConfig.CD_FAN_OUT_TARGET_STATUS_PENDING {
+			continue
+		}
+		configTarget, ok := configTargetMap[target.EnvironmentId]
+		if !ok {
+			err = impl.updateExecutionTarget(target, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_SKIPPED, "environment removed from fan-out")
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err = impl.triggerTarget(ctx, config.PipelineId, execution, target, configTarget, userId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// triggerTarget deploys the target environment as a fan-out runner of the rollout's cd workflow, the target is
+// marked triggered only once the runner and its release are created
+func (impl *CdFanOutServiceImpl) triggerTarget(ctx context.Context, pipelineId int, execution *pipelineConfig.CdFanOutExecution, target *pipelineConfig.CdFanOutExecutionTarget, configTarget *pipelineConfig.CdFanOutTarget, userId int32) error {
+	runner, releaseId, err := impl.workflowDagExecutor.TriggerFanOutDeployment(execution.CdWorkflowId, target.EnvironmentId, configTarget.DeploymentAppCreated, userId, ctx)
+	if runner != nil {
+		target.CdWorkflowRunnerId = runner.Id
+	}
+	if err == nil && (runner == nil || runner.Id == 0 || releaseId == 0) {
+		err = fmt.Errorf("deployment was not started")
+	}
+	var pipelineOverrides []*chartConfig.PipelineOverride
+	if err == nil {
+		pipelineOverrides, err = impl.pipelineOverrideRepository.GetByPipelineIdAndReleaseNo(pipelineId, releaseId)
+		if err == nil && len(pipelineOverrides) == 0 {
+			err = fmt.Errorf("release %d not found", releaseId)
+		}
+	}
+	if err != nil {
+		impl.logger.Errorw("error in triggering fan-out target", "executionId", execution.Id, "envId", target.EnvironmentId, "err", err)
+		return impl.updateExecutionTarget(target, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_FAILED, err.Error())
+	}
+	target.PipelineOverrideId = pipelineOverrides[0].Id
+	err = impl.updateExecutionTarget(target, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_TRIGGERED, "")
+	if err != nil {
+		return err
+	}
+	if !configTarget.DeploymentAppCreated {
+		configTarget.DeploymentAppCreated = true
+		configTarget.UpdatedOn = time.Now()
+		configTarget.UpdatedBy = userId
+		err = impl.cdFanOutRepository.UpdateTarget(configTarget)
+		if err != nil {
+			impl.logger.Errorw("error in updating fan-out target", "target", configTarget, "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// finishExecution closes the rollout, targets which were not deployed yet are skipped
+func (impl *CdFanOutServiceImpl) finishExecution(execution *pipelineConfig.CdFanOutExecution, status pipelineConfig.CdFanOutExecutionStatus, skipMessage string) error {
+	targets, err := impl.cdFanOutRepository.FindExecutionTargetsByExecutionId(execution.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching fan-out execution targets", "executionId", execution.Id, "err", err)
+		return err
+	}
+	for _, target := range targets {
+		if target.Status != pipelineConfig.CD_FAN_OUT_TARGET_STATUS_PENDING {
+			continue
+		}
+		err = impl.updateExecutionTarget(target, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_SKIPPED, skipMessage)
+		if err != nil {
+			return err
+		}
+	}
+	execution.Status = status
+	impl.logger.Infow("fan-out rollout finished", "executionId", execution.Id, "status", status)
+	return impl.updateExecution(execution, cdFanOutSystemUserId)
+}
+
+func (impl *CdFanOutServiceImpl) updateExecution(execution *pipelineConfig.CdFanOutExecution, userId int32) error {
+	execution.UpdatedOn = time.Now()
+	execution.UpdatedBy = userId
+	err := impl.cdFanOutRepository.UpdateExecution(execution)
+	if err != nil {
+		impl.logger.Errorw("error in updating fan-out execution", "execution", execution, "err", err)
+	}
+	return err
+}
+
+func (impl *CdFanOutServiceImpl) updateExecutionTarget(target *pipelineConfig.CdFanOutExecutionTarget, status pipelineConfig.CdFanOutTargetStatus, message string) error {
+	target.Status = status
+	target.Message = message
+	target.UpdatedOn = time.Now()
+	target.UpdatedBy = cdFanOutSystemUserId
+	err := impl.cdFanOutRepository.UpdateExecutionTarget(target)
+	if err != nil {
+		impl.logger.Errorw("error in updating fan-out execution target", "target", target, "err", err)
+	}
+	return err
+}
+
+func (impl *CdFanOutServiceImpl) buildContext() (context.Context, error) {
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return nil, err
+	}
+	return context.WithValue(context.Background(), "token", acdToken), nil
+}
+
+func (impl *CdFanOutServiceImpl) getEnvironmentName(envId int) string {
+	env, err := impl.environmentRepository.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching environment", "envId", envId, "err", err)
+		return ""
+	}
+	return env.Name
+}
+
+func isFanOutDeploymentSucceeded(status string) bool {
+	return status == string(health.HealthStatusHealthy)
+}
+
+func isFanOutDeploymentFailed(status string) bool {
+	return status == WorkflowFailed || status == WorkflowAborted || status == string(health.HealthStatusDegraded)
+}
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+)
+
+func TestGetNextFanOutWave(t *testing.T) {
+	impl := &CdFanOutServiceImpl{}
+	target := func(wave int, status pipelineConfig.CdFanOutTargetStatus) *pipelineConfig.CdFanOutExecutionTarget {
+		return &pipelineConfig.CdFanOutExecutionTarget{Wave: wave, Status: status}
+	}
+	targets := []*pipelineConfig.CdFanOutExecutionTarget{
+		target(1, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_SUCCEEDED),
+		target(3, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_PENDING),
+		target(2, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_PENDING),
+		target(2, pipelineConfig.CD_FAN_OUT_TARGET_STATUS_TRIGGERED),
+	}
+	tests := []struct {
+		name        string
+		currentWave int
+		targets     []*pipelineConfig.CdFanOutExecutionTarget
+		nextWave    int
+	}{
+		{name: "pipeline environment deployed", currentWave: 0, targets: targets, nextWave: 2},
+		{name: "lowest pending wave after current", currentWave: 1, targets: targets, nextWave: 2},
+		{name: "pending wave before current is ignored", currentWave: 2, targets: targets, nextWave: 3},
+		{name: "last wave", currentWave: 3, targets: targets, nextWave: 0},
+		{name: "no targets", currentWave: 0, nextWave: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if nextWave := impl.getNextWave(tt.currentWave, tt.targets); nextWave != tt.nextWave {
+				t.Errorf("getNextWave() = %d, want %d", nextWave, tt.nextWave)
+			}
+		})
+	}
+}
+
+func TestFanOutDeploymentStatus(t *testing.T) {
+	tests := []struct {
+		status    string
+		succeeded bool
+		failed    bool
+	}{
+		{status: "Healthy", succeeded: true},
+		{status: "Degraded", failed: true},
+		{status: WorkflowFailed, failed: true},
+		{status: WorkflowAborted, failed: true},
+		{status: WorkflowInProgress},
+		{status: "Progressing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if succeeded := isFanOutDeploymentSucceeded(tt.status); succeeded != tt.succeeded {
+				t.Errorf("isFanOutDeploymentSucceeded() = %v, want %v", succeeded, tt.succeeded)
+			}
+			if failed := isFanOutDeploymentFailed(tt.status); failed != tt.failed {
+				t.Errorf("isFanOutDeploymentFailed() = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
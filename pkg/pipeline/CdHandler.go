@@ -87,6 +87,8 @@ type CdHandlerImpl struct {
 	argoUserService                  argo.ArgoUserService
 	deploymentEventHandler           app.DeploymentEventHandler
 	eventClient                      client2.EventClient
+	autoRollbackService              AutoRollbackService
+	cdFanOutRepository               pipelineConfig.CdFanOutRepository
 }
 
 func NewCdHandlerImpl(Logger *zap.SugaredLogger, cdConfig *CdConfig, userService user.UserService,
@@ -104,7 +106,9 @@ func NewCdHandlerImpl(Logger *zap.SugaredLogger, cdConfig *CdConfig, userService
 	pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository,
 	application application.ServiceClient, argoUserService argo.ArgoUserService,
 	deploymentEventHandler app.DeploymentEventHandler,
-	eventClient client2.EventClient) *CdHandlerImpl {
+	eventClient client2.EventClient,
+	autoRollbackService AutoRollbackService,
+	cdFanOutRepository pipelineConfig.CdFanOutRepository) *CdHandlerImpl {
 	return &CdHandlerImpl{
 		Logger:                           Logger,
 		cdConfig:                         cdConfig,
@@ -128,6 +132,8 @@ func NewCdHandlerImpl(Logger *zap.SugaredLogger, cdConfig *CdConfig, userService
 		argoUserService:                  argoUserService,
 		deploymentEventHandler:           deploymentEventHandler,
 		eventClient:                      eventClient,
+		autoRollbackService:              autoRollbackService,
+		cdFanOutRepository:               cdFanOutRepository,
 	}
 }
 
@@ -304,6 +310,9 @@ func (impl *CdHandlerImpl) UpdatePipelineTimelineAndStatusByLiveResourceTreeFetc
 		//writing pipeline success event
 		impl.deploymentEventHandler.WriteCDDeploymentEvent(cdWfr.CdWorkflow.PipelineId, appId, envId, util2.Success)
 	}
+	if timelineStatus == pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_FAILED || timelineStatus == pipelineConfig.TIMELINE_STATUS_APP_DEGRADED {
+		go impl.autoRollbackService.HandleDeploymentFailure(cdWfr.Id, timelineStatus)
+	}
 	return nil
 }
 
@@ -384,7 +393,9 @@ func (impl *CdHandlerImpl) CheckHelmAppStatusPeriodicallyAndUpdateInDb(timeForDe
 			return err
 		}
 		impl.Logger.Infow("updating workflow runner status for helm app", "cdWf", cdWf)
-		if cdWf.Status == application.Healthy {
+		if cdWf.Status == application.Degraded {
+			go impl.autoRollbackService.HandleDeploymentFailure(cdWf.Id, pipelineConfig.TIMELINE_STATUS_APP_DEGRADED)
+		} else if cdWf.Status == application.Healthy {
 			err = impl.workflowDagExecutor.HandleDeploymentSuccessEvent("", pipelineOverride.Id)
 			if err != nil {
 				impl.Logger.Errorw("error on handling deployment success event", "cdWf", cdWf, "err", err)
@@ -912,8 +923,12 @@ func (impl *CdHandlerImpl) FetchAppWorkflowStatusForTriggerView(appId int) ([]*p
 		return cdWorkflowStatus, err
 	}
 	var wfrIds []int
+	deployWfrIds := make(map[int]int)
 	for _, item := range result {
 		wfrIds = append(wfrIds, item.WfrId)
+		if item.WorkflowType == "DEPLOY" {
+			deployWfrIds[item.PipelineId] = item.WfrId
+		}
 	}
 
 	statusMap := make(map[int]string)
@@ -990,5 +1005,75 @@ func (impl *CdHandlerImpl) FetchAppWorkflowStatusForTriggerView(appId int) ([]*p
 		}
 	}
 
+	err = impl.updateFanOutStatus(cdWorkflowStatus, pipelineIds, deployWfrIds)
 	return cdWorkflowStatus, err
 }
+
+// updateFanOutStatus sets the combined status of the latest rollout on fan-out pipelines along with the status of each
+// target environment, the rollout is considered only if it belongs to the latest deployment of the pipeline
+func (impl *CdHandlerImpl) updateFanOutStatus(cdWorkflowStatus []*pipelineConfig.CdWorkflowStatus, pipelineIds []int, deployWfrIds map[int]int) error {
+	fanOutConfigs, err := impl.cdFanOutRepository.FindActiveConfigsByPipelineIds(pipelineIds)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.Logger.Errorw("error in fetching fan-out configs", "pipelineIds", pipelineIds, "err", err)
+		return err
+	}
+	if len(fanOutConfigs) == 0 {
+		return nil
+	}
+	statusMap := make(map[int]*pipelineConfig.CdWorkflowStatus)
+	for _, item := range cdWorkflowStatus {
+		statusMap[item.PipelineId] = item
+	}
+	for _, fanOutConfig := range fanOutConfigs {
+		item, ok := statusMap[fanOutConfig.PipelineId]
+		if !ok {
+			continue
+		}
+		execution, err := impl.cdFanOutRepository.FindLatestExecutionByConfigId(fanOutConfig.Id)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.Logger.Errorw("error in fetching latest fan-out execution", "configId", fanOutConfig.Id, "err", err)
+			return err
+		}
+		if execution.Id == 0 || execution.CdWorkflowRunnerId != deployWfrIds[fanOutConfig.PipelineId] {
+			continue
+		}
+		targets, err := impl.cdFanOutRepository.FindExecutionTargetsByExecutionId(execution.Id)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.Logger.Errorw("error in fetching fan-out execution targets", "executionId", execution.Id, "err", err)
+			return err
+		}
+		runners, err := impl.cdWorkflowRepository.FindWorkflowRunnerByCdWorkflowId([]int{execution.CdWorkflowId})
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.Logger.Errorw("error in fetching runners of fan-out cd workflow", "cdWorkflowId", execution.CdWorkflowId, "err", err)
+			return err
+		}
+		runnerStatus := make(map[int]string)
+		for _, runner := range runners {
+			runnerStatus[runner.Id] = runner.Status
+		}
+		item.FanOutStatus = string(execution.Status)
+		item.FanOutTargets = make([]*pipelineConfig.FanOutTargetStatus, 0, len(targets))
+		for _, target := range targets {
+			deployStatus := "Not Deployed"
+			if status, ok := runnerStatus[target.CdWorkflowRunnerId]; ok && target.CdWorkflowRunnerId > 0 {
+				deployStatus = status
+			}
+			item.FanOutTargets = append(item.FanOutTargets, &pipelineConfig.FanOutTargetStatus{
+				EnvironmentId: target.EnvironmentId,
+				Wave:          target.Wave,
+				WfrId:         target.CdWorkflowRunnerId,
+				Status:        string(target.Status),
+				DeployStatus:  deployStatus,
+			})
+		}
+		switch execution.Status {
+		case pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_FAILED:
+			item.DeployStatus = WorkflowFailed
+		case pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_RUNNING, pipelineConfig.CD_FAN_OUT_EXECUTION_STATUS_PAUSED:
+			if item.DeployStatus != WorkflowFailed {
+				item.DeployStatus = WorkflowInProgress
+			}
+		}
+	}
+	return nil
+}
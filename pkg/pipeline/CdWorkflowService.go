@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	blob_storage "github.com/devtron-labs/common-lib/blob-storage"
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"net/url"
 	"strconv"
@@ -105,6 +106,8 @@ type CdWorkflowRequest struct {
 	DeploymentTriggeredBy      string                            `json:"deploymentTriggeredBy,omitempty"`
 	DeploymentTriggerTime      time.Time                         `json:"deploymentTriggerTime,omitempty"`
 	DeploymentReleaseCounter   int                               `json:"deploymentReleaseCounter,omitempty"`
+	PrePostDeploySteps         []*bean3.StepObject               `json:"prePostDeploySteps"`
+	RefPlugins                 []*bean3.RefPluginObject          `json:"refPlugins"`
 }
 
 const PRE = "PRE"
@@ -78,6 +78,8 @@ type CiConfig struct {
 	AzureAccountKey                string                       `env:"AZURE_ACCOUNT_KEY"`
 	IgnoreDockerCacheForCI         bool                         `env:"CI_IGNORE_DOCKER_CACHE"`
 	VolumeMountsForCiJson          string                       `env:"CI_VOLUME_MOUNTS_JSON"`
+	SbomEnabled                    bool                         `env:"CI_SBOM_ENABLED" envDefault:"true"`
+	SbomFormat                     string                       `env:"CI_SBOM_FORMAT" envDefault:"cyclonedx-json"`
 	ClusterConfig                  *rest.Config
 	NodeLabel                      map[string]string
 }
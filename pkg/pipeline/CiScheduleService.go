@@ -0,0 +1,312 @@
+package pipeline
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/devtron-labs/devtron/client/gitSensor"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const DefaultCiScheduleTimezone = "UTC"
+
+type CiScheduleService interface {
+	ValidateSchedule(ciPipeline *bean.CiPipeline) error
+	SaveSchedule(ciPipelineId int, schedule *bean.CiPipelineSchedule, userId int32) error
+	GetSchedule(ciPipelineId int) (*bean.CiPipelineSchedule, error)
+	GetSchedules(ciPipelineIds []int) (map[int]*bean.CiPipelineSchedule, error)
+	DeleteSchedule(ciPipelineId int, userId int32) error
+	// TriggerDueSchedules builds every ci pipeline whose schedule is due, runs missed while the
+	// orchestrator was down are caught up with a single build
+	TriggerDueSchedules()
+}
+
+type CiScheduleServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	ciPipelineScheduleRepository pipelineConfig.CiPipelineScheduleRepository
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository
+	ciWorkflowRepository         pipelineConfig.CiWorkflowRepository
+	gitSensorClient              gitSensor.GitSensorClient
+	ciHandler                    CiHandler
+}
+
+func NewCiScheduleServiceImpl(logger *zap.SugaredLogger,
+	ciPipelineScheduleRepository pipelineConfig.CiPipelineScheduleRepository,
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository,
+	ciWorkflowRepository pipelineConfig.CiWorkflowRepository,
+	gitSensorClient gitSensor.GitSensorClient,
+	ciHandler CiHandler) *CiScheduleServiceImpl {
+	return &CiScheduleServiceImpl{
+		logger:                       logger,
+		ciPipelineScheduleRepository: ciPipelineScheduleRepository,
+		ciPipelineMaterialRepository: ciPipelineMaterialRepository,
+		ciWorkflowRepository:         ciWorkflowRepository,
+		gitSensorClient:              gitSensorClient,
+		ciHandler:                    ciHandler,
+	}
+}
+
+func (impl *CiScheduleServiceImpl) ValidateSchedule(ciPipeline *bean.CiPipeline) error {
+	schedule := ciPipeline.Schedule
+	if schedule == nil || !schedule.Active {
+		return nil
+	}
+	if ciPipeline.IsExternal || ciPipeline.ParentCiPipeline != 0 {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "schedule on external or linked ci", UserMessage: "schedule is supported only for ci pipelines building from source"}
+	}
+	if _, err := nextScheduledRun(schedule.CronExpression, schedule.Timezone, time.Now()); err != nil {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: fmt.Sprintf("invalid schedule, %s", err.Error())}
+	}
+	branchFound := len(schedule.Branch) == 0
+	for _, material := range ciPipeline.CiMaterial {
+		if material.Source == nil {
+			continue
+		}
+		if material.Source.Type != pipelineConfig.SOURCE_TYPE_BRANCH_FIXED {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "schedule on non branch material", UserMessage: "schedule is supported only for ci pipelines with branch based materials"}
+		}
+		if material.Source.Value == schedule.Branch {
+			branchFound = true
+		}
+	}
+	if !branchFound {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "schedule branch not configured", UserMessage: fmt.Sprintf("branch %s is not configured on any material of the ci pipeline", schedule.Branch)}
+	}
+	return nil
+}
+
+func (impl *CiScheduleServiceImpl) SaveSchedule(ciPipelineId int, scheduleDto *bean.CiPipelineSchedule, userId int32) error {
+	if scheduleDto == nil {
+		return nil
+	}
+	schedule, err := impl.ciPipelineScheduleRepository.FindByCiPipelineId(ciPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting ci pipeline schedule", "err", err, "ciPipelineId", ciPipelineId)
+		return err
+	}
+	if err == pg.ErrNoRows {
+		if !scheduleDto.Active {
+			return nil
+		}
+		schedule = &pipelineConfig.CiPipelineSchedule{
+			CiPipelineId: ciPipelineId,
+			AuditLog:     sql.AuditLog{CreatedOn: time.Now(), CreatedBy: userId},
+		}
+	}
+	timezone := scheduleDto.Timezone
+	if len(timezone) == 0 {
+		timezone = DefaultCiScheduleTimezone
+	}
+	schedule.Active = scheduleDto.Active
+	if schedule.Active {
+		nextRunOn, err := nextScheduledRun(scheduleDto.CronExpression, timezone, time.Now())
+		if err != nil {
+			return err
+		}
+		schedule.NextRunOn = nextRunOn
+	}
+	schedule.CronExpression = scheduleDto.CronExpression
+	schedule.Timezone = timezone
+	schedule.Branch = scheduleDto.Branch
+	schedule.OnlyOnNewCommits = scheduleDto.OnlyOnNewCommits
+	schedule.UpdatedOn = time.Now()
+	schedule.UpdatedBy = userId
+	if schedule.Id == 0 {
+		err = impl.ciPipelineScheduleRepository.Save(schedule)
+	} else {
+		err = impl.ciPipelineScheduleRepository.Update(schedule)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving ci pipeline schedule", "err", err, "schedule", schedule)
+		return err
+	}
+	return nil
+}
+
+func (impl *CiScheduleServiceImpl) GetSchedule(ciPipelineId int) (*bean.CiPipelineSchedule, error) {
+	schedule, err := impl.ciPipelineScheduleRepository.FindByCiPipelineId(ciPipelineId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting ci pipeline schedule", "err", err, "ciPipelineId", ciPipelineId)
+		return nil, err
+	}
+	return adaptCiPipelineSchedule(schedule), nil
+}
+
+func (impl *CiScheduleServiceImpl) GetSchedules(ciPipelineIds []int) (map[int]*bean.CiPipelineSchedule, error) {
+	schedules, err := impl.ciPipelineScheduleRepository.FindActiveByCiPipelineIds(ciPipelineIds)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci pipeline schedules", "err", err, "ciPipelineIds", ciPipelineIds)
+		return nil, err
+	}
+	scheduleMap := make(map[int]*bean.CiPipelineSchedule, len(schedules))
+	for _, schedule := range schedules {
+		scheduleMap[schedule.CiPipelineId] = adaptCiPipelineSchedule(schedule)
+	}
+	return scheduleMap, nil
+}
+
+func (impl *CiScheduleServiceImpl) DeleteSchedule(ciPipelineId int, userId int32) error {
+	schedule, err := impl.ciPipelineScheduleRepository.FindByCiPipelineId(ciPipelineId)
+	if err == pg.ErrNoRows {
+		return nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting ci pipeline schedule", "err", err, "ciPipelineId", ciPipelineId)
+		return err
+	}
+	schedule.Active = false
+	schedule.UpdatedOn = time.Now()
+	schedule.UpdatedBy = userId
+	err = impl.ciPipelineScheduleRepository.Update(schedule)
+	if err != nil {
+		impl.logger.Errorw("error in deleting ci pipeline schedule", "err", err, "ciPipelineId", ciPipelineId)
+		return err
+	}
+	return nil
+}
+
+func (impl *CiScheduleServiceImpl) TriggerDueSchedules() {
+	now := time.Now()
+	schedules, err := impl.ciPipelineScheduleRepository.FindDueSchedules(now)
+	if err != nil {
+		impl.logger.Errorw("error in getting due ci pipeline schedules", "err", err)
+		return
+	}
+	for _, schedule := range schedules {
+		nextRunOn, err := nextScheduledRun(schedule.CronExpression, schedule.Timezone, now)
+		if err != nil {
+			impl.logger.Errorw("invalid ci pipeline schedule, skipping", "err", err, "scheduleId", schedule.Id)
+			continue
+		}
+		claimed, err := impl.ciPipelineScheduleRepository.ClaimRun(schedule.Id, schedule.NextRunOn, nextRunOn)
+		if err != nil {
+			impl.logger.Errorw("error in claiming ci pipeline schedule run", "err", err, "scheduleId", schedule.Id)
+			continue
+		} else if !claimed {
+			continue
+		}
+		schedule.NextRunOn = nextRunOn
+		impl.triggerScheduledBuild(schedule)
+	}
+}
+
+func (impl *CiScheduleServiceImpl) triggerScheduledBuild(schedule *pipelineConfig.CiPipelineSchedule) {
+	if schedule.LastCiWorkflowId != 0 {
+		lastWorkflow, err := impl.ciWorkflowRepository.FindById(schedule.LastCiWorkflowId)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.logger.Errorw("error in getting last scheduled ci workflow", "err", err, "ciWorkflowId", schedule.LastCiWorkflowId)
+			return
+		}
+		if err == nil && isCiWorkflowInProgress(lastWorkflow.Status) {
+			impl.logger.Infow("previous scheduled build still running, skipping run", "ciPipelineId", schedule.CiPipelineId, "ciWorkflowId", lastWorkflow.Id)
+			return
+		}
+	}
+	materials, err := impl.ciPipelineMaterialRepository.GetByPipelineId(schedule.CiPipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci pipeline materials", "err", err, "ciPipelineId", schedule.CiPipelineId)
+		return
+	}
+	var materialIds []int
+	for _, material := range materials {
+		if material.Type != pipelineConfig.SOURCE_TYPE_BRANCH_FIXED {
+			impl.logger.Warnw("scheduled build supports only branch materials, skipping run", "ciPipelineId", schedule.CiPipelineId, "materialId", material.Id)
+			return
+		}
+		materialIds = append(materialIds, material.Id)
+	}
+	if len(materialIds) == 0 {
+		return
+	}
+	heads, err := impl.gitSensorClient.GetHeadForPipelineMaterials(&gitSensor.HeadRequest{MaterialIds: materialIds})
+	if err != nil {
+		impl.logger.Errorw("error in getting head commits for scheduled build", "err", err, "ciPipelineId", schedule.CiPipelineId)
+		return
+	}
+	var ciPipelineMaterials []bean.CiPipelineMaterial
+	var checkedHeads []string
+	for _, head := range heads {
+		if len(head.GitCommit.Commit) == 0 {
+			impl.logger.Warnw("no commit found for material, skipping scheduled build", "ciPipelineId", schedule.CiPipelineId, "materialId", head.Id)
+			return
+		}
+		ciPipelineMaterials = append(ciPipelineMaterials, bean.CiPipelineMaterial{
+			Id:        head.Id,
+			GitCommit: bean.GitCommit{Commit: head.GitCommit.Commit},
+		})
+		if len(schedule.Branch) == 0 || head.Value == schedule.Branch {
+			checkedHeads = append(checkedHeads, fmt.Sprintf("%d:%s", head.Id, head.GitCommit.Commit))
+		}
+	}
+	sort.Strings(checkedHeads)
+	commitHashes := strings.Join(checkedHeads, ",")
+	if schedule.OnlyOnNewCommits && commitHashes == schedule.LastCommitHashes {
+		impl.logger.Infow("no new commits since last scheduled build, skipping run", "ciPipelineId", schedule.CiPipelineId)
+		return
+	}
+	ciTriggerRequest := bean.CiTriggerRequest{
+		PipelineId:         schedule.CiPipelineId,
+		CiPipelineMaterial: ciPipelineMaterials,
+		TriggeredBy:        1,
+	}
+	ciWorkflowId, err := impl.ciHandler.HandleCIManual(ciTriggerRequest)
+	if err != nil {
+		impl.logger.Errorw("error in triggering scheduled build", "err", err, "ciPipelineId", schedule.CiPipelineId)
+		return
+	}
+	impl.logger.Infow("triggered scheduled build", "ciPipelineId", schedule.CiPipelineId, "ciWorkflowId", ciWorkflowId)
+	err = impl.ciPipelineScheduleRepository.UpdateRunDetails(schedule.Id, ciWorkflowId, commitHashes, time.Now())
+	if err != nil {
+		impl.logger.Errorw("error in updating ci pipeline schedule", "err", err, "scheduleId", schedule.Id)
+	}
+}
+
+func isCiWorkflowInProgress(status string) bool {
+	return status == WorkflowStarting || status == string(v1alpha1.NodePending) || status == string(v1alpha1.NodeRunning)
+}
+
+// nextScheduledRun returns the first run of the cron expression after now, evaluated in the given timezone
+func nextScheduledRun(cronExpression string, timezone string, now time.Time) (time.Time, error) {
+	if len(timezone) == 0 {
+		timezone = DefaultCiScheduleTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %s", timezone)
+	}
+	schedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression %s", cronExpression)
+	}
+	return schedule.Next(now.In(loc)), nil
+}
+
+func adaptCiPipelineSchedule(schedule *pipelineConfig.CiPipelineSchedule) *bean.CiPipelineSchedule {
+	scheduleDto := &bean.CiPipelineSchedule{
+		CronExpression:   schedule.CronExpression,
+		Timezone:         schedule.Timezone,
+		Branch:           schedule.Branch,
+		OnlyOnNewCommits: schedule.OnlyOnNewCommits,
+		Active:           schedule.Active,
+	}
+	if schedule.Active && !schedule.NextRunOn.IsZero() {
+		nextRunOn := schedule.NextRunOn
+		scheduleDto.NextRunOn = &nextRunOn
+	}
+	if !schedule.LastTriggeredOn.IsZero() {
+		lastTriggeredOn := schedule.LastTriggeredOn
+		scheduleDto.LastTriggeredOn = &lastTriggeredOn
+	}
+	return scheduleDto
+}
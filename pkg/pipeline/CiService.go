@@ -450,6 +450,8 @@ func (impl *CiServiceImpl) buildWfRequestForCiPipeline(pipeline *pipelineConfig.
 		TriggeredBy:                savedWf.TriggeredBy,
 		CacheLimit:                 impl.ciConfig.CacheLimit,
 		ScanEnabled:                pipeline.ScanEnabled,
+		SbomEnabled:                impl.ciConfig.SbomEnabled,
+		SbomFormat:                 impl.ciConfig.SbomFormat,
 		CloudProvider:              impl.ciConfig.CloudProvider,
 		DefaultAddressPoolBaseCidr: impl.ciConfig.DefaultAddressPoolBaseCidr,
 		DefaultAddressPoolSize:     impl.ciConfig.DefaultAddressPoolSize,
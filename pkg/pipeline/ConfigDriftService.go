@@ -0,0 +1,530 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	"github.com/caarlos0/env"
+	client2 "github.com/devtron-labs/devtron/api/helm-app"
+	application2 "github.com/devtron-labs/devtron/client/argocdServer/application"
+	client "github.com/devtron-labs/devtron/client/events"
+	k8sApplication "github.com/devtron-labs/devtron/client/k8s/application"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	util2 "github.com/devtron-labs/devtron/util/event"
+	"github.com/devtron-labs/devtron/util/k8s"
+	yamlUtil "github.com/devtron-labs/devtron/util/yaml"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	configDriftScanListLimit          = 20
+	maxDriftedFieldsPerResource       = 25
+	configDriftSystemUserId     int32 = 1
+)
+
+type ConfigDriftConfig struct {
+	NotificationEnabled bool `env:"CONFIG_DRIFT_NOTIFICATION_ENABLED" envDefault:"false"`
+	ScanHistoryLimit    int  `env:"CONFIG_DRIFT_SCAN_HISTORY_LIMIT" envDefault:"10"`
+}
+
+type ConfigDriftService interface {
+	// ScanPipeline compares the manifests rendered for the last deployment of the pipeline, deployment
+	// template along with config maps and secrets, against the live objects and stores the result
+	ScanPipeline(pipelineId int) (*ConfigDriftScanDto, error)
+	ScanAllPipelines()
+	GetLatestScan(pipelineId int) (*ConfigDriftScanDto, error)
+	GetLatestScansByAppId(appId int) ([]*ConfigDriftScanDto, error)
+	GetScanHistory(pipelineId int) ([]*ConfigDriftScanDto, error)
+}
+
+type ConfigDriftServiceImpl struct {
+	logger                *zap.SugaredLogger
+	cfg                   *ConfigDriftConfig
+	configDriftRepository pipelineConfig.ConfigDriftRepository
+	pipelineRepository    pipelineConfig.PipelineRepository
+	helmAppService        client2.HelmAppService
+	acdClient             application2.ServiceClient
+	k8sApplicationService k8s.K8sApplicationService
+	argoUserService       argo.ArgoUserService
+	eventFactory          client.EventFactory
+	eventClient           client.EventClient
+}
+
+func NewConfigDriftServiceImpl(logger *zap.SugaredLogger,
+	configDriftRepository pipelineConfig.ConfigDriftRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	helmAppService client2.HelmAppService,
+	acdClient application2.ServiceClient,
+	k8sApplicationService k8s.K8sApplicationService,
+	argoUserService argo.ArgoUserService,
+	eventFactory client.EventFactory,
+	eventClient client.EventClient) *ConfigDriftServiceImpl {
+	cfg := &ConfigDriftConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing ConfigDriftConfig, so setting to default values", "err", err)
+	}
+	return &ConfigDriftServiceImpl{
+		logger:                logger,
+		cfg:                   cfg,
+		configDriftRepository: configDriftRepository,
+		pipelineRepository:    pipelineRepository,
+		helmAppService:        helmAppService,
+		acdClient:             acdClient,
+		k8sApplicationService: k8sApplicationService,
+		argoUserService:       argoUserService,
+		eventFactory:          eventFactory,
+		eventClient:           eventClient,
+	}
+}
+
+type ConfigDriftScanDto struct {
+	Id                   int                              `json:"id"`
+	PipelineId           int                              `json:"pipelineId"`
+	AppId                int                              `json:"appId"`
+	EnvironmentId        int                              `json:"environmentId"`
+	Status               pipelineConfig.ConfigDriftStatus `json:"status"`
+	DriftedResourceCount int                              `json:"driftedResourceCount"`
+	Message              string                           `json:"message,omitempty"`
+	ScannedOn            time.Time                        `json:"scannedOn"`
+	Resources            []*ConfigDriftResourceDto        `json:"resources,omitempty"`
+}
+
+type ConfigDriftResourceDto struct {
+	Group         string                           `json:"group"`
+	Version       string                           `json:"version"`
+	Kind          string                           `json:"kind"`
+	Name          string                           `json:"name"`
+	Namespace     string                           `json:"namespace"`
+	Status        pipelineConfig.ConfigDriftStatus `json:"status"`
+	DriftedFields []string                         `json:"driftedFields,omitempty"`
+}
+
+func (impl *ConfigDriftServiceImpl) ScanAllPipelines() {
+	pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentIdV2()
+	if err != nil {
+		impl.logger.Errorw("error in getting active cd pipelines for drift detection", "err", err)
+		return
+	}
+	for _, pipeline := range pipelines {
+		if !pipeline.DeploymentAppCreated {
+			continue
+		}
+		_, err = impl.ScanPipeline(pipeline.Id)
+		if err != nil {
+			impl.logger.Errorw("error in scanning pipeline for config drift", "err", err, "pipelineId", pipeline.Id)
+		}
+	}
+}
+
+func (impl *ConfigDriftServiceImpl) ScanPipeline(pipelineId int) (*ConfigDriftScanDto, error) {
+	cdPipeline, err := impl.pipelineRepository.FindById(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	previousScan, err := impl.configDriftRepository.FindLatestScanByPipelineId(pipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting latest config drift scan", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	now := time.Now()
+	scan := &pipelineConfig.ConfigDriftScan{
+		PipelineId:    cdPipeline.Id,
+		AppId:         cdPipeline.AppId,
+		EnvironmentId: cdPipeline.EnvironmentId,
+		Status:        pipelineConfig.CONFIG_DRIFT_STATUS_IN_SYNC,
+		AuditLog:      sql.AuditLog{CreatedOn: now, CreatedBy: configDriftSystemUserId, UpdatedOn: now, UpdatedBy: configDriftSystemUserId},
+	}
+	var resources []*pipelineConfig.ConfigDriftResource
+	desiredManifests, err := impl.getDesiredManifests(cdPipeline)
+	if err != nil {
+		impl.logger.Errorw("error in getting desired manifests for config drift", "err", err, "pipelineId", pipelineId)
+		scan.Status = pipelineConfig.CONFIG_DRIFT_STATUS_FAILED
+		scan.Message = err.Error()
+	} else {
+		for _, desired := range desiredManifests {
+			driftResource := impl.compareWithLive(cdPipeline.Environment.ClusterId, cdPipeline.Environment.Namespace, desired)
+			if driftResource.Status == pipelineConfig.CONFIG_DRIFT_STATUS_DRIFTED || driftResource.Status == pipelineConfig.CONFIG_DRIFT_STATUS_MISSING {
+				scan.DriftedResourceCount += 1
+			}
+			resources = append(resources, driftResource)
+		}
+		if scan.DriftedResourceCount > 0 {
+			scan.Status = pipelineConfig.CONFIG_DRIFT_STATUS_DRIFTED
+		}
+	}
+	err = impl.configDriftRepository.SaveScan(scan, resources)
+	if err != nil {
+		impl.logger.Errorw("error in saving config drift scan", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	err = impl.configDriftRepository.DeleteScansBeforeLatest(pipelineId, impl.cfg.ScanHistoryLimit)
+	if err != nil {
+		impl.logger.Errorw("error in deleting old config drift scans", "err", err, "pipelineId", pipelineId)
+	}
+	previouslyDrifted := previousScan != nil && previousScan.Status == pipelineConfig.CONFIG_DRIFT_STATUS_DRIFTED
+	if impl.cfg.NotificationEnabled && scan.Status == pipelineConfig.CONFIG_DRIFT_STATUS_DRIFTED && !previouslyDrifted {
+		impl.sendDriftNotification(cdPipeline)
+	}
+	return adaptConfigDriftScan(scan, resources), nil
+}
+
+func (impl *ConfigDriftServiceImpl) GetLatestScan(pipelineId int) (*ConfigDriftScanDto, error) {
+	scan, err := impl.configDriftRepository.FindLatestScanByPipelineId(pipelineId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: 404, InternalMessage: err.Error(), UserMessage: "pipeline has not been scanned for config drift yet"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting latest config drift scan", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	resources, err := impl.configDriftRepository.FindResourcesByScanId(scan.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting config drift resources", "err", err, "scanId", scan.Id)
+		return nil, err
+	}
+	return adaptConfigDriftScan(scan, resources), nil
+}
+
+func (impl *ConfigDriftServiceImpl) GetLatestScansByAppId(appId int) ([]*ConfigDriftScanDto, error) {
+	scans, err := impl.configDriftRepository.FindLatestScansByAppId(appId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting latest config drift scans", "err", err, "appId", appId)
+		return nil, err
+	}
+	scanDtos := make([]*ConfigDriftScanDto, 0, len(scans))
+	for _, scan := range scans {
+		scanDtos = append(scanDtos, adaptConfigDriftScan(scan, nil))
+	}
+	return scanDtos, nil
+}
+
+func (impl *ConfigDriftServiceImpl) GetScanHistory(pipelineId int) ([]*ConfigDriftScanDto, error) {
+	scans, err := impl.configDriftRepository.FindScansByPipelineId(pipelineId, configDriftScanListLimit)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting config drift scans", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	scanDtos := make([]*ConfigDriftScanDto, 0, len(scans))
+	for _, scan := range scans {
+		scanDtos = append(scanDtos, adaptConfigDriftScan(scan, nil))
+	}
+	return scanDtos, nil
+}
+
+// getDesiredManifests returns the manifests of the last deployed release, helm releases are read from the manifest
+// stored with the latest revision and argo cd applications are rendered from the gitops repo
+func (impl *ConfigDriftServiceImpl) getDesiredManifests(cdPipeline *pipelineConfig.Pipeline) ([]map[string]interface{}, error) {
+	deploymentAppName := fmt.Sprintf("%s-%s", cdPipeline.App.AppName, cdPipeline.Environment.Name)
+	if util.IsHelmApp(cdPipeline.DeploymentAppType) {
+		appIdentifier := &client2.AppIdentifier{
+			ClusterId:   cdPipeline.Environment.ClusterId,
+			Namespace:   cdPipeline.Environment.Namespace,
+			ReleaseName: deploymentAppName,
+		}
+		releaseManifest, err := impl.getHelmReleaseManifest(appIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		// the release manifest lists every object of the release, so that deleted objects are reported as missing
+		objects, err := yamlUtil.SplitYAMLs([]byte(releaseManifest))
+		if err != nil {
+			impl.logger.Errorw("error in parsing helm release manifest", "err", err, "appIdentifier", appIdentifier)
+			return nil, err
+		}
+		manifests := make([]string, 0, len(objects))
+		for _, object := range objects {
+			manifest, err := object.MarshalJSON()
+			if err != nil {
+				impl.logger.Errorw("error in parsing helm release manifest", "err", err, "appIdentifier", appIdentifier)
+				return nil, err
+			}
+			manifests = append(manifests, string(manifest))
+		}
+		return impl.parseDesiredManifests(manifests)
+	}
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return nil, err
+	}
+	ctx := context.WithValue(context.Background(), "token", acdToken)
+	managedResources, err := impl.acdClient.ManagedResources(ctx, &application.ResourcesQuery{ApplicationName: &deploymentAppName})
+	if err != nil {
+		impl.logger.Errorw("error in getting managed resources of argo app", "err", err, "app", deploymentAppName)
+		return nil, err
+	}
+	var manifests []string
+	for _, item := range managedResources.Items {
+		if item.Hook || len(item.TargetState) == 0 || item.TargetState == "null" {
+			continue
+		}
+		manifests = append(manifests, item.TargetState)
+	}
+	return impl.parseDesiredManifests(manifests)
+}
+
+// parseDesiredManifests parses the manifests the same way for helm releases and argo cd applications, so that
+// numbers are compared with the same type as the live objects
+func (impl *ConfigDriftServiceImpl) parseDesiredManifests(manifests []string) ([]map[string]interface{}, error) {
+	desiredManifests := make([]map[string]interface{}, 0, len(manifests))
+	for _, manifest := range manifests {
+		desired := make(map[string]interface{})
+		err := yaml.Unmarshal([]byte(manifest), &desired)
+		if err != nil {
+			impl.logger.Errorw("error in parsing desired manifest", "err", err)
+			return nil, err
+		}
+		desiredManifests = append(desiredManifests, desired)
+	}
+	return desiredManifests, nil
+}
+
+// getHelmReleaseManifest returns the manifest of the latest revision of the release
+func (impl *ConfigDriftServiceImpl) getHelmReleaseManifest(appIdentifier *client2.AppIdentifier) (string, error) {
+	history, err := impl.helmAppService.GetDeploymentHistory(context.Background(), appIdentifier)
+	if err != nil {
+		impl.logger.Errorw("error in getting helm release history", "err", err, "appIdentifier", appIdentifier)
+		return "", err
+	}
+	var latestVersion int32
+	for _, deployment := range history.GetDeploymentHistory() {
+		if deployment.GetVersion() > latestVersion {
+			latestVersion = deployment.GetVersion()
+		}
+	}
+	if latestVersion == 0 {
+		return "", nil
+	}
+	deploymentDetail, err := impl.helmAppService.GetDeploymentDetail(context.Background(), appIdentifier, latestVersion)
+	if err != nil {
+		impl.logger.Errorw("error in getting helm release manifest", "err", err, "appIdentifier", appIdentifier, "version", latestVersion)
+		return "", err
+	}
+	return deploymentDetail.GetManifest(), nil
+}
+
+// compareWithLive looks the desired object up in the cluster, chart templates don't set the namespace of namespaced
+// objects so it defaults to the namespace of the environment, the namespace is dropped for cluster scoped kinds
+func (impl *ConfigDriftServiceImpl) compareWithLive(clusterId int, defaultNamespace string, desired map[string]interface{}) *pipelineConfig.ConfigDriftResource {
+	apiVersion, _ := desired["apiVersion"].(string)
+	kind, _ := desired["kind"].(string)
+	metadata, _ := desired["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+	if len(namespace) == 0 {
+		namespace = defaultNamespace
+	}
+	gv, _ := schema.ParseGroupVersion(apiVersion)
+	driftResource := &pipelineConfig.ConfigDriftResource{
+		Group:     gv.Group,
+		Version:   gv.Version,
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+		Status:    pipelineConfig.CONFIG_DRIFT_STATUS_IN_SYNC,
+	}
+	request := &k8s.ResourceRequestBean{
+		AppIdentifier: &client2.AppIdentifier{ClusterId: clusterId},
+		K8sRequest: &k8sApplication.K8sRequestBean{
+			ResourceIdentifier: k8sApplication.ResourceIdentifier{
+				Name:             name,
+				Namespace:        namespace,
+				GroupVersionKind: gv.WithKind(kind),
+			},
+		},
+	}
+	liveResource, err := impl.k8sApplicationService.GetResource(request)
+	if k8sErrors.IsNotFound(err) {
+		driftResource.Status = pipelineConfig.CONFIG_DRIFT_STATUS_MISSING
+		return driftResource
+	} else if err != nil {
+		impl.logger.Errorw("error in getting live resource for config drift", "err", err, "kind", kind, "name", name)
+		driftResource.Status = pipelineConfig.CONFIG_DRIFT_STATUS_FAILED
+		driftResource.DriftedFields = []string{err.Error()}
+		return driftResource
+	}
+	driftResource.Namespace = liveResource.Manifest.GetNamespace()
+	// round trip through json so that numbers are compared with the same type on both sides
+	liveJson, err := json.Marshal(liveResource.Manifest.Object)
+	live := make(map[string]interface{})
+	if err == nil {
+		err = json.Unmarshal(liveJson, &live)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in parsing live resource", "err", err, "kind", kind, "name", name)
+		driftResource.Status = pipelineConfig.CONFIG_DRIFT_STATUS_FAILED
+		driftResource.DriftedFields = []string{err.Error()}
+		return driftResource
+	}
+	driftedFields := findDriftedFields(desired, live)
+	if len(driftedFields) > 0 {
+		if len(driftedFields) > maxDriftedFieldsPerResource {
+			driftedFields = driftedFields[:maxDriftedFieldsPerResource]
+		}
+		driftResource.Status = pipelineConfig.CONFIG_DRIFT_STATUS_DRIFTED
+		driftResource.DriftedFields = driftedFields
+	}
+	return driftResource
+}
+
+func (impl *ConfigDriftServiceImpl) sendDriftNotification(cdPipeline *pipelineConfig.Pipeline) {
+	event := impl.eventFactory.Build(util2.ConfigDrift, &cdPipeline.Id, cdPipeline.AppId, &cdPipeline.EnvironmentId, util2.CD)
+	event.UserId = int(configDriftSystemUserId)
+	event.Payload = &client.Payload{}
+	_, evtErr := impl.eventClient.WriteNotificationEvent(event)
+	if evtErr != nil {
+		impl.logger.Errorw("error in writing config drift notification event", "err", evtErr, "pipelineId", cdPipeline.Id)
+	}
+}
+
+// findDriftedFields returns the paths of the fields set in the desired manifest which have a different value
+// in the live object, fields only present on the live object are defaulted by the cluster and are ignored
+func findDriftedFields(desired map[string]interface{}, live map[string]interface{}) []string {
+	var driftedFields []string
+	desired = normalizeDesiredManifest(desired)
+	for _, key := range sortedKeys(desired) {
+		switch key {
+		case "status":
+			continue
+		case "metadata":
+			desiredMetadata, _ := desired[key].(map[string]interface{})
+			liveMetadata, _ := live[key].(map[string]interface{})
+			for _, metadataKey := range []string{"labels", "annotations"} {
+				diffDesiredField(desiredMetadata[metadataKey], liveMetadata[metadataKey], "metadata."+metadataKey, &driftedFields)
+			}
+		default:
+			diffDesiredField(desired[key], live[key], key, &driftedFields)
+		}
+	}
+	return driftedFields
+}
+
+func diffDesiredField(desired interface{}, live interface{}, path string, driftedFields *[]string) {
+	switch desiredValue := desired.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			if len(desiredValue) > 0 {
+				*driftedFields = append(*driftedFields, path)
+			}
+			return
+		}
+		for _, key := range sortedKeys(desiredValue) {
+			diffDesiredField(desiredValue[key], liveValue[key], path+"."+key, driftedFields)
+		}
+	case []interface{}:
+		liveValue, _ := live.([]interface{})
+		if len(desiredValue) != len(liveValue) {
+			*driftedFields = append(*driftedFields, path)
+			return
+		}
+		for i := range desiredValue {
+			diffDesiredField(desiredValue[i], liveValue[i], fmt.Sprintf("%s[%d]", path, i), driftedFields)
+		}
+	default:
+		if !isSameScalar(desiredValue, live) {
+			*driftedFields = append(*driftedFields, path)
+		}
+	}
+}
+
+func isSameScalar(desired interface{}, live interface{}) bool {
+	if fmt.Sprint(desired) == fmt.Sprint(live) {
+		return true
+	}
+	// quantities are stored in their canonical form by the api server, e.g. 1000m is returned as 1
+	desiredString, ok := desired.(string)
+	if !ok {
+		return false
+	}
+	liveString, ok := live.(string)
+	if !ok {
+		return false
+	}
+	desiredQuantity, err := resource.ParseQuantity(desiredString)
+	if err != nil {
+		return false
+	}
+	liveQuantity, err := resource.ParseQuantity(liveString)
+	if err != nil {
+		return false
+	}
+	return desiredQuantity.Cmp(liveQuantity) == 0
+}
+
+// normalizeDesiredManifest moves secret string data into data as the api server never returns string data
+func normalizeDesiredManifest(desired map[string]interface{}) map[string]interface{} {
+	if desired["kind"] != "Secret" {
+		return desired
+	}
+	stringData, ok := desired["stringData"].(map[string]interface{})
+	if !ok {
+		return desired
+	}
+	normalized := make(map[string]interface{}, len(desired))
+	for key, value := range desired {
+		normalized[key] = value
+	}
+	data := make(map[string]interface{})
+	if existingData, ok := desired["data"].(map[string]interface{}); ok {
+		for key, value := range existingData {
+			data[key] = value
+		}
+	}
+	for key, value := range stringData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(value)))
+	}
+	delete(normalized, "stringData")
+	normalized["data"] = data
+	return normalized
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func adaptConfigDriftScan(scan *pipelineConfig.ConfigDriftScan, resources []*pipelineConfig.ConfigDriftResource) *ConfigDriftScanDto {
+	scanDto := &ConfigDriftScanDto{
+		Id:                   scan.Id,
+		PipelineId:           scan.PipelineId,
+		AppId:                scan.AppId,
+		EnvironmentId:        scan.EnvironmentId,
+		Status:               scan.Status,
+		DriftedResourceCount: scan.DriftedResourceCount,
+		Message:              scan.Message,
+		ScannedOn:            scan.CreatedOn,
+	}
+	for _, driftResource := range resources {
+		scanDto.Resources = append(scanDto.Resources, &ConfigDriftResourceDto{
+			Group:         driftResource.Group,
+			Version:       driftResource.Version,
+			Kind:          driftResource.Kind,
+			Name:          driftResource.Name,
+			Namespace:     driftResource.Namespace,
+			Status:        driftResource.Status,
+			DriftedFields: driftResource.DriftedFields,
+		})
+	}
+	return scanDto
+}
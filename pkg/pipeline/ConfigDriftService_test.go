@@ -0,0 +1,198 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	client2 "github.com/devtron-labs/devtron/api/helm-app"
+	openapi "github.com/devtron-labs/devtron/api/helm-app/openapiClient"
+	k8sApplication "github.com/devtron-labs/devtron/client/k8s/application"
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/util/k8s"
+	"go.uber.org/zap"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func TestFindDriftedFields(t *testing.T) {
+	desiredDeployment := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: demo
+  labels:
+    app: demo
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: demo
+          image: demo:v1
+          resources:
+            limits:
+              cpu: 1000m
+              memory: 512Mi
+`
+	tests := []struct {
+		name     string
+		desired  string
+		live     string
+		expected []string
+	}{
+		{
+			name:    "live object only adds defaulted fields",
+			desired: desiredDeployment,
+			live: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"demo","uid":"1","labels":{"app":"demo"}},
+				"spec":{"replicas":2,"progressDeadlineSeconds":600,"template":{"spec":{"containers":[{"name":"demo","image":"demo:v1",
+				"imagePullPolicy":"IfNotPresent","resources":{"limits":{"cpu":"1","memory":"512Mi"}}}]}}},"status":{"replicas":2}}`,
+		},
+		{
+			name:    "scaled and image changed",
+			desired: desiredDeployment,
+			live: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"demo","labels":{"app":"demo"}},
+				"spec":{"replicas":5,"template":{"spec":{"containers":[{"name":"demo","image":"demo:hotfix",
+				"resources":{"limits":{"cpu":"1","memory":"512Mi"}}}]}}}}`,
+			expected: []string{"spec.replicas", "spec.template.spec.containers[0].image"},
+		},
+		{
+			name:     "label removed",
+			desired:  desiredDeployment,
+			live:     `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"demo"},"spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"demo","image":"demo:v1","resources":{"limits":{"cpu":"1","memory":"512Mi"}}}]}}}}`,
+			expected: []string{"metadata.labels"},
+		},
+		{
+			name:    "secret string data compared with data",
+			desired: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: demo\nstringData:\n  password: secret\n",
+			live:    `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"demo"},"data":{"password":"c2VjcmV0"}}`,
+		},
+		{
+			name:     "config map key edited",
+			desired:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: demo\ndata:\n  LOG_LEVEL: info\n",
+			live:     `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"demo"},"data":{"LOG_LEVEL":"debug"}}`,
+			expected: []string{"data.LOG_LEVEL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := make(map[string]interface{})
+			if err := yaml.Unmarshal([]byte(tt.desired), &desired); err != nil {
+				t.Fatalf("invalid desired manifest: %v", err)
+			}
+			live := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(tt.live), &live); err != nil {
+				t.Fatalf("invalid live manifest: %v", err)
+			}
+			driftedFields := findDriftedFields(desired, live)
+			if !reflect.DeepEqual(driftedFields, tt.expected) {
+				t.Errorf("expected drifted fields %v, got %v", tt.expected, driftedFields)
+			}
+		})
+	}
+}
+
+// fakeHelmAppService serves a single revision of a release with the given manifest
+type fakeHelmAppService struct {
+	client2.HelmAppService
+	manifest string
+}
+
+func (impl *fakeHelmAppService) GetDeploymentHistory(ctx context.Context, app *client2.AppIdentifier) (*client2.HelmAppDeploymentHistory, error) {
+	return &client2.HelmAppDeploymentHistory{DeploymentHistory: []*client2.HelmAppDeploymentDetail{{Version: 1}, {Version: 2}}}, nil
+}
+
+func (impl *fakeHelmAppService) GetDeploymentDetail(ctx context.Context, app *client2.AppIdentifier, version int32) (*openapi.HelmAppDeploymentManifestDetail, error) {
+	manifest := ""
+	if version == 2 {
+		manifest = impl.manifest
+	}
+	return &openapi.HelmAppDeploymentManifestDetail{Manifest: &manifest}, nil
+}
+
+// fakeK8sApplicationService serves the live objects keyed by namespace and name
+type fakeK8sApplicationService struct {
+	k8s.K8sApplicationService
+	liveObjects map[string]map[string]interface{}
+}
+
+func (impl *fakeK8sApplicationService) GetResource(request *k8s.ResourceRequestBean) (*k8sApplication.ManifestResponse, error) {
+	identifier := request.K8sRequest.ResourceIdentifier
+	live, ok := impl.liveObjects[identifier.Namespace+"/"+identifier.Name]
+	if !ok {
+		return nil, k8sErrors.NewNotFound(schema.GroupResource{Resource: identifier.GroupVersionKind.Kind}, identifier.Name)
+	}
+	return &k8sApplication.ManifestResponse{Manifest: unstructured.Unstructured{Object: live}}, nil
+}
+
+func TestHelmReleaseConfigDrift(t *testing.T) {
+	releaseManifest := `
+---
+# Source: reference-chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app-service
+spec:
+  ports:
+  - port: 80
+---
+# Source: reference-chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-cm
+data:
+  key: value
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1000000
+`
+	liveObjects := map[string]map[string]interface{}{
+		"app-ns/app-service": {"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "app-service", "namespace": "app-ns"},
+			"spec": map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": float64(80)}}}},
+		"app-ns/app": {"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "app", "namespace": "app-ns"},
+			"spec": map[string]interface{}{"replicas": float64(1000000)}},
+	}
+	impl := &ConfigDriftServiceImpl{
+		logger:                zap.NewNop().Sugar(),
+		helmAppService:        &fakeHelmAppService{manifest: releaseManifest},
+		k8sApplicationService: &fakeK8sApplicationService{liveObjects: liveObjects},
+	}
+	cdPipeline := &pipelineConfig.Pipeline{
+		DeploymentAppType: util.PIPELINE_DEPLOYMENT_TYPE_HELM,
+		App:               app.App{AppName: "app"},
+		Environment:       repository.Environment{Name: "env", ClusterId: 1, Namespace: "app-ns"},
+	}
+	desiredManifests, err := impl.getDesiredManifests(cdPipeline)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statuses := make(map[string]pipelineConfig.ConfigDriftStatus)
+	for _, desired := range desiredManifests {
+		driftResource := impl.compareWithLive(cdPipeline.Environment.ClusterId, cdPipeline.Environment.Namespace, desired)
+		if driftResource.Namespace != "app-ns" {
+			t.Errorf("namespace of %s = %q, want app-ns", driftResource.Name, driftResource.Namespace)
+		}
+		statuses[driftResource.Name] = driftResource.Status
+	}
+	want := map[string]pipelineConfig.ConfigDriftStatus{
+		"app-service": pipelineConfig.CONFIG_DRIFT_STATUS_IN_SYNC,
+		// deleted from the cluster, only known from the release manifest
+		"app-cm": pipelineConfig.CONFIG_DRIFT_STATUS_MISSING,
+		"app":    pipelineConfig.CONFIG_DRIFT_STATUS_IN_SYNC,
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+}
@@ -23,14 +23,19 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
 	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	"github.com/devtron-labs/devtron/pkg/commonService"
 	history2 "github.com/devtron-labs/devtron/pkg/pipeline/history"
 	"github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	util2 "github.com/devtron-labs/devtron/util"
+	"github.com/devtron-labs/devtron/util/rbac"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
+	"net/http"
 	"regexp"
+	"strconv"
 	"time"
 )
 
@@ -147,8 +152,8 @@ type ConfigMapService interface {
 	CSGlobalDeleteByAppId(name string, appId int, userId int32) (bool, error)
 	CSEnvironmentDeleteByAppIdAndEnvId(name string, appId int, envId int, userId int32) (bool, error)
 
-	CSGlobalFetchForEdit(name string, id int) (*ConfigDataRequest, error)
-	CSEnvironmentFetchForEdit(name string, id int, appId int, envId int) (*ConfigDataRequest, error)
+	CSGlobalFetchForEdit(name string, id int, userId int32) (*ConfigDataRequest, error)
+	CSEnvironmentFetchForEdit(name string, id int, appId int, envId int, userId int32) (*ConfigDataRequest, error)
 	ConfigSecretGlobalBulkPatch(bulkPatchRequest *BulkPatchRequest) (*BulkPatchRequest, error)
 	ConfigSecretEnvironmentBulkPatch(bulkPatchRequest *BulkPatchRequest) (*BulkPatchRequest, error)
 }
@@ -164,6 +169,8 @@ type ConfigMapServiceImpl struct {
 	commonService               commonService.CommonService
 	appRepository               app.AppRepository
 	configMapHistoryService     history2.ConfigMapHistoryService
+	auditLogService             auditLog.AuditLogService
+	enforcerUtil                rbac.EnforcerUtil
 }
 
 func NewConfigMapServiceImpl(chartRepository chartRepoRepository.ChartRepository,
@@ -173,7 +180,8 @@ func NewConfigMapServiceImpl(chartRepository chartRepoRepository.ChartRepository
 	pipelineConfigRepository chartConfig.PipelineConfigRepository,
 	configMapRepository chartConfig.ConfigMapRepository, environmentConfigRepository chartConfig.EnvConfigOverrideRepository,
 	commonService commonService.CommonService, appRepository app.AppRepository,
-	configMapHistoryService history2.ConfigMapHistoryService) *ConfigMapServiceImpl {
+	configMapHistoryService history2.ConfigMapHistoryService, auditLogService auditLog.AuditLogService,
+	enforcerUtil rbac.EnforcerUtil) *ConfigMapServiceImpl {
 	return &ConfigMapServiceImpl{
 		chartRepository:             chartRepository,
 		logger:                      logger,
@@ -185,6 +193,8 @@ func NewConfigMapServiceImpl(chartRepository chartRepoRepository.ChartRepository
 		commonService:               commonService,
 		appRepository:               appRepository,
 		configMapHistoryService:     configMapHistoryService,
+		auditLogService:             auditLogService,
+		enforcerUtil:                enforcerUtil,
 	}
 }
 
@@ -219,12 +229,14 @@ func (impl ConfigMapServiceImpl) CMGlobalAddUpdate(configMapRequest *ConfigDataR
 		return configMapRequest, err
 	}
 	var model *chartConfig.ConfigMapAppModel
+	var beforeData string
 	if configMapRequest.Id > 0 {
 		model, err = impl.configMapRepository.GetByIdAppLevel(configMapRequest.Id)
 		if err != nil {
 			impl.logger.Errorw("error while fetching from db", "error", err)
 			return nil, err
 		}
+		beforeData = model.ConfigMapData
 		configsList := &ConfigsList{}
 		found := false
 		var configs []*ConfigData
@@ -297,6 +309,7 @@ func (impl ConfigMapServiceImpl) CMGlobalAddUpdate(configMapRequest *ConfigDataR
 		impl.logger.Errorw("error in creating entry for configmap history", "err", err)
 		return nil, err
 	}
+	impl.saveConfigAuditLog(auditLog.RESOURCE_CONFIG_MAP, model.AppId, 0, beforeData, model.ConfigMapData, configMapRequest.UserId)
 	return configMapRequest, nil
 }
 
@@ -347,6 +360,7 @@ func (impl ConfigMapServiceImpl) CMEnvironmentAddUpdate(configMapRequest *Config
 		return configMapRequest, err
 	}
 	var model *chartConfig.ConfigMapEnvModel
+	var beforeData string
 	if configMapRequest.Id > 0 {
 		model, err = impl.configMapRepository.GetByIdEnvLevel(configMapRequest.Id)
 	} else if configMapRequest.AppId > 0 && configMapRequest.EnvironmentId > 0 {
@@ -357,6 +371,7 @@ func (impl ConfigMapServiceImpl) CMEnvironmentAddUpdate(configMapRequest *Config
 		return nil, err
 	}
 	if err == nil && model.Id > 0 {
+		beforeData = model.ConfigMapData
 		configsList := &ConfigsList{}
 		found := false
 		var configs []*ConfigData
@@ -430,6 +445,7 @@ func (impl ConfigMapServiceImpl) CMEnvironmentAddUpdate(configMapRequest *Config
 		impl.logger.Errorw("error in creating entry for CM/CS history in bulk update", "err", err)
 		return nil, err
 	}
+	impl.saveConfigAuditLog(auditLog.RESOURCE_CONFIG_MAP, model.AppId, model.EnvironmentId, beforeData, model.ConfigMapData, configMapRequest.UserId)
 	return configMapRequest, nil
 }
 
@@ -536,12 +552,14 @@ func (impl ConfigMapServiceImpl) CSGlobalAddUpdate(configMapRequest *ConfigDataR
 		return configMapRequest, err
 	}
 	var model *chartConfig.ConfigMapAppModel
+	var beforeData string
 	if configMapRequest.Id > 0 {
 		model, err = impl.configMapRepository.GetByIdAppLevel(configMapRequest.Id)
 		if err != nil {
 			impl.logger.Errorw("error while fetching from db", "error", err)
 			return nil, err
 		}
+		beforeData = model.SecretData
 		secretsList := &SecretsList{}
 		found := false
 		var configs []*ConfigData
@@ -571,6 +589,10 @@ func (impl ConfigMapServiceImpl) CSGlobalAddUpdate(configMapRequest *ConfigDataR
 		if !found {
 			configs = append(configs, configData)
 		}
+		err = impl.checkSecretAction(configMapRequest.UserId, getSecretAction(found), model.AppId, 0)
+		if err != nil {
+			return nil, err
+		}
 		secretsList.ConfigData = configs
 		configDataByte, err := json.Marshal(secretsList)
 		if err != nil {
@@ -589,6 +611,10 @@ func (impl ConfigMapServiceImpl) CSGlobalAddUpdate(configMapRequest *ConfigDataR
 
 	} else {
 		//creating config map record for first time
+		err = impl.checkSecretAction(configMapRequest.UserId, casbin.ActionCreate, configMapRequest.AppId, 0)
+		if err != nil {
+			return nil, err
+		}
 		secretsList := &SecretsList{
 			ConfigData: configMapRequest.ConfigData,
 		}
@@ -617,6 +643,7 @@ func (impl ConfigMapServiceImpl) CSGlobalAddUpdate(configMapRequest *ConfigDataR
 		impl.logger.Errorw("error in creating entry for secret history", "err", err)
 		return nil, err
 	}
+	impl.saveConfigAuditLog(auditLog.RESOURCE_SECRET, model.AppId, 0, beforeData, model.SecretData, configMapRequest.UserId)
 	return configMapRequest, nil
 }
 
@@ -723,6 +750,7 @@ func (impl ConfigMapServiceImpl) CSEnvironmentAddUpdate(configMapRequest *Config
 		return configMapRequest, err
 	}
 	var model *chartConfig.ConfigMapEnvModel
+	var beforeData string
 	if configMapRequest.Id > 0 {
 		model, err = impl.configMapRepository.GetByIdEnvLevel(configMapRequest.Id)
 	} else if configMapRequest.AppId > 0 && configMapRequest.EnvironmentId > 0 {
@@ -733,6 +761,7 @@ func (impl ConfigMapServiceImpl) CSEnvironmentAddUpdate(configMapRequest *Config
 		return nil, err
 	}
 	if err == nil && model.Id > 0 {
+		beforeData = model.SecretData
 		configsList := &SecretsList{}
 		found := false
 		var configs []*ConfigData
@@ -762,6 +791,10 @@ func (impl ConfigMapServiceImpl) CSEnvironmentAddUpdate(configMapRequest *Config
 		if !found {
 			configs = append(configs, configData)
 		}
+		err = impl.checkSecretAction(configMapRequest.UserId, getSecretAction(found), model.AppId, model.EnvironmentId)
+		if err != nil {
+			return nil, err
+		}
 		configsList.ConfigData = configs
 		secretDataByte, err := json.Marshal(configsList)
 		if err != nil {
@@ -780,6 +813,10 @@ func (impl ConfigMapServiceImpl) CSEnvironmentAddUpdate(configMapRequest *Config
 
 	} else if err == pg.ErrNoRows {
 		//creating config map record for first time
+		err = impl.checkSecretAction(configMapRequest.UserId, casbin.ActionCreate, configMapRequest.AppId, configMapRequest.EnvironmentId)
+		if err != nil {
+			return nil, err
+		}
 		secretsList := &SecretsList{
 			ConfigData: configMapRequest.ConfigData,
 		}
@@ -809,6 +846,7 @@ func (impl ConfigMapServiceImpl) CSEnvironmentAddUpdate(configMapRequest *Config
 		impl.logger.Errorw("error in creating entry for CM/CS history in bulk update", "err", err)
 		return nil, err
 	}
+	impl.saveConfigAuditLog(auditLog.RESOURCE_SECRET, model.AppId, model.EnvironmentId, beforeData, model.SecretData, configMapRequest.UserId)
 	return configMapRequest, nil
 }
 
@@ -1135,6 +1173,10 @@ func (impl ConfigMapServiceImpl) CSGlobalDelete(name string, id int, userId int3
 	}
 
 	if found {
+		err = impl.checkSecretAction(userId, casbin.ActionDelete, model.AppId, 0)
+		if err != nil {
+			return false, err
+		}
 		configsList.ConfigData = configs
 		configDataByte, err := json.Marshal(configsList)
 		if err != nil {
@@ -1187,6 +1229,10 @@ func (impl ConfigMapServiceImpl) CSEnvironmentDelete(name string, id int, userId
 	}
 
 	if found {
+		err = impl.checkSecretAction(userId, casbin.ActionDelete, model.AppId, model.EnvironmentId)
+		if err != nil {
+			return false, err
+		}
 		configsList.ConfigData = configs
 		configDataByte, err := json.Marshal(configsList)
 		if err != nil {
@@ -1333,6 +1379,10 @@ func (impl ConfigMapServiceImpl) CSGlobalDeleteByAppId(name string, appId int, u
 	}
 
 	if found {
+		err = impl.checkSecretAction(userId, casbin.ActionDelete, model.AppId, 0)
+		if err != nil {
+			return false, err
+		}
 		configsList.ConfigData = configs
 		configDataByte, err := json.Marshal(configsList)
 		if err != nil {
@@ -1380,6 +1430,10 @@ func (impl ConfigMapServiceImpl) CSEnvironmentDeleteByAppIdAndEnvId(name string,
 	}
 
 	if found {
+		err = impl.checkSecretAction(userId, casbin.ActionDelete, model.AppId, model.EnvironmentId)
+		if err != nil {
+			return false, err
+		}
 		configsList.ConfigData = configs
 		configDataByte, err := json.Marshal(configsList)
 		if err != nil {
@@ -1403,12 +1457,16 @@ func (impl ConfigMapServiceImpl) CSEnvironmentDeleteByAppIdAndEnvId(name string,
 
 ////
 
-func (impl ConfigMapServiceImpl) CSGlobalFetchForEdit(name string, id int) (*ConfigDataRequest, error) {
+func (impl ConfigMapServiceImpl) CSGlobalFetchForEdit(name string, id int, userId int32) (*ConfigDataRequest, error) {
 	configMapEnvLevel, err := impl.configMapRepository.GetByIdAppLevel(id)
 	if err != nil {
 		impl.logger.Errorw("error while fetching from db", "error", err)
 		return nil, err
 	}
+	err = impl.checkSecretAction(userId, casbin.ActionGet, configMapEnvLevel.AppId, 0)
+	if err != nil {
+		return nil, err
+	}
 
 	configsList := &SecretsList{}
 	var configs []*ConfigData
@@ -1432,7 +1490,11 @@ func (impl ConfigMapServiceImpl) CSGlobalFetchForEdit(name string, id int) (*Con
 	return configDataRequest, nil
 }
 
-func (impl ConfigMapServiceImpl) CSEnvironmentFetchForEdit(name string, id int, appId int, envId int) (*ConfigDataRequest, error) {
+func (impl ConfigMapServiceImpl) CSEnvironmentFetchForEdit(name string, id int, appId int, envId int, userId int32) (*ConfigDataRequest, error) {
+	err := impl.checkSecretAction(userId, casbin.ActionGet, appId, envId)
+	if err != nil {
+		return nil, err
+	}
 	configDataRequest := &ConfigDataRequest{}
 	configsList := &SecretsList{}
 	var configs []*ConfigData
@@ -1485,6 +1547,39 @@ func (impl ConfigMapServiceImpl) CSEnvironmentFetchForEdit(name string, id int,
 	return configDataRequest, nil
 }
 
+// checkSecretAction applies the deny rules on secrets, the caller is allowed to change the app and env through
+// environment rbac already
+func (impl ConfigMapServiceImpl) checkSecretAction(userId int32, action string, appId int, envId int) error {
+	if impl.enforcerUtil.IsSecretActionDenied(userId, action, appId, envId) {
+		impl.logger.Warnw("secret action denied", "userId", userId, "action", action, "appId", appId, "envId", envId)
+		return &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "secret action denied", UserMessage: "unauthorized user"}
+	}
+	return nil
+}
+
+func getSecretAction(exists bool) string {
+	if exists {
+		return casbin.ActionUpdate
+	}
+	return casbin.ActionCreate
+}
+
+// saveConfigAuditLog records the config maps or secrets of an app, or of its env override when envId is set,
+// before and after an add or update
+func (impl ConfigMapServiceImpl) saveConfigAuditLog(resourceType auditLog.ResourceType, appId int, envId int, beforeData string, afterData string, userId int32) {
+	resourceId := strconv.Itoa(appId)
+	if envId > 0 {
+		resourceId = fmt.Sprintf("%d-%d", appId, envId)
+	}
+	action := auditLog.AUDIT_ACTION_CREATE
+	var before interface{}
+	if len(beforeData) > 0 {
+		action = auditLog.AUDIT_ACTION_UPDATE
+		before = json.RawMessage(beforeData)
+	}
+	impl.auditLogService.SaveResourceAuditLog(resourceType, resourceId, action, before, json.RawMessage(afterData), userId)
+}
+
 func (impl ConfigMapServiceImpl) validateConfigData(configData *ConfigData) (bool, error) {
 	dataMap := make(map[string]string)
 	if configData.Data != nil {
@@ -1590,6 +1685,10 @@ func (impl ConfigMapServiceImpl) ConfigSecretGlobalBulkPatch(bulkPatchRequest *B
 			}
 			model.ConfigMapData = string(configDataByte)
 		} else if bulkPatchRequest.Type == "CS" {
+			err = impl.checkSecretAction(bulkPatchRequest.UserId, casbin.ActionUpdate, payload.AppId, 0)
+			if err != nil {
+				return nil, err
+			}
 			secretsList := &SecretsList{}
 			var configs []*ConfigData
 			if len(model.SecretData) > 0 {
@@ -1680,6 +1779,10 @@ func (impl ConfigMapServiceImpl) ConfigSecretEnvironmentBulkPatch(bulkPatchReque
 			}
 			model.ConfigMapData = string(configDataByte)
 		} else if bulkPatchRequest.Type == "CS" {
+			err = impl.checkSecretAction(bulkPatchRequest.UserId, casbin.ActionUpdate, payload.AppId, payload.EnvId)
+			if err != nil {
+				return nil, err
+			}
 			secretsList := &SecretsList{}
 			var configs []*ConfigData
 			if len(model.SecretData) > 0 {
@@ -0,0 +1,478 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	bean2 "github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	"github.com/devtron-labs/devtron/pkg/pipeline/history"
+	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/go-pg/pg"
+	"github.com/juju/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	RESTORED_COMPONENT_DEPLOYMENT_TEMPLATE = "DEPLOYMENT_TEMPLATE"
+	RESTORED_COMPONENT_CONFIGMAP           = "CONFIGMAP"
+	RESTORED_COMPONENT_SECRET              = "SECRET"
+	RESTORED_COMPONENT_PIPELINE_STRATEGY   = "PIPELINE_STRATEGY"
+)
+
+type ConfigSnapshotRestoreRequest struct {
+	AppId      int   `json:"appId"`
+	PipelineId int   `json:"pipelineId"`
+	WfrId      int   `json:"wfrId"`
+	Deploy     bool  `json:"deploy"`
+	UserId     int32 `json:"-"`
+}
+
+type ConfigSnapshotRestoreResponse struct {
+	PipelineId         int      `json:"pipelineId"`
+	WfrId              int      `json:"wfrId"`
+	RestoredComponents []string `json:"restoredComponents"`
+	// NotRestoredComponents are left as they are as the deployment has no snapshot recorded for them
+	NotRestoredComponents []string `json:"notRestoredComponents"`
+	Deployed              bool     `json:"deployed"`
+}
+
+type ConfigSnapshotRestoreService interface {
+	// RestoreSnapshot writes the deployment template, configmaps, secrets and strategy deployed with the given
+	// workflow runner back as the current env level configuration of the pipeline, and triggers a deployment if asked for
+	RestoreSnapshot(request *ConfigSnapshotRestoreRequest, ctx context.Context) (*ConfigSnapshotRestoreResponse, error)
+}
+
+type ConfigSnapshotRestoreServiceImpl struct {
+	logger                              *zap.SugaredLogger
+	pipelineRepository                  pipelineConfig.PipelineRepository
+	cdWorkflowRepository                pipelineConfig.CdWorkflowRepository
+	envConfigOverrideRepository         chartConfig.EnvConfigOverrideRepository
+	chartRefRepository                  chartRepoRepository.ChartRefRepository
+	configMapRepository                 chartConfig.ConfigMapRepository
+	pipelineConfigRepository            chartConfig.PipelineConfigRepository
+	envLevelAppMetricsRepository        repository.EnvLevelAppMetricsRepository
+	appLevelMetricsRepository           repository.AppLevelMetricsRepository
+	deploymentTemplateHistoryRepository repository2.DeploymentTemplateHistoryRepository
+	configMapHistoryRepository          repository2.ConfigMapHistoryRepository
+	strategyHistoryRepository           repository2.PipelineStrategyHistoryRepository
+	deploymentTemplateHistoryService    history.DeploymentTemplateHistoryService
+	configMapHistoryService             history.ConfigMapHistoryService
+	strategyHistoryService              history.PipelineStrategyHistoryService
+	workflowDagExecutor                 WorkflowDagExecutor
+	argoUserService                     argo.ArgoUserService
+	enforcerUtil                        rbac.EnforcerUtil
+}
+
+func NewConfigSnapshotRestoreServiceImpl(logger *zap.SugaredLogger,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	envConfigOverrideRepository chartConfig.EnvConfigOverrideRepository,
+	chartRefRepository chartRepoRepository.ChartRefRepository,
+	configMapRepository chartConfig.ConfigMapRepository,
+	pipelineConfigRepository chartConfig.PipelineConfigRepository,
+	envLevelAppMetricsRepository repository.EnvLevelAppMetricsRepository,
+	appLevelMetricsRepository repository.AppLevelMetricsRepository,
+	deploymentTemplateHistoryRepository repository2.DeploymentTemplateHistoryRepository,
+	configMapHistoryRepository repository2.ConfigMapHistoryRepository,
+	strategyHistoryRepository repository2.PipelineStrategyHistoryRepository,
+	deploymentTemplateHistoryService history.DeploymentTemplateHistoryService,
+	configMapHistoryService history.ConfigMapHistoryService,
+	strategyHistoryService history.PipelineStrategyHistoryService,
+	workflowDagExecutor WorkflowDagExecutor,
+	argoUserService argo.ArgoUserService,
+	enforcerUtil rbac.EnforcerUtil) *ConfigSnapshotRestoreServiceImpl {
+	return &ConfigSnapshotRestoreServiceImpl{
+		logger:                              logger,
+		pipelineRepository:                  pipelineRepository,
+		cdWorkflowRepository:                cdWorkflowRepository,
+		envConfigOverrideRepository:         envConfigOverrideRepository,
+		chartRefRepository:                  chartRefRepository,
+		configMapRepository:                 configMapRepository,
+		pipelineConfigRepository:            pipelineConfigRepository,
+		envLevelAppMetricsRepository:        envLevelAppMetricsRepository,
+		appLevelMetricsRepository:           appLevelMetricsRepository,
+		deploymentTemplateHistoryRepository: deploymentTemplateHistoryRepository,
+		configMapHistoryRepository:          configMapHistoryRepository,
+		strategyHistoryRepository:           strategyHistoryRepository,
+		deploymentTemplateHistoryService:    deploymentTemplateHistoryService,
+		configMapHistoryService:             configMapHistoryService,
+		strategyHistoryService:              strategyHistoryService,
+		workflowDagExecutor:                 workflowDagExecutor,
+		argoUserService:                     argoUserService,
+		enforcerUtil:                        enforcerUtil,
+	}
+}
+
+func (impl *ConfigSnapshotRestoreServiceImpl) RestoreSnapshot(request *ConfigSnapshotRestoreRequest, ctx context.Context) (*ConfigSnapshotRestoreResponse, error) {
+	pipeline, err := impl.pipelineRepository.FindById(request.PipelineId)
+	if err == pg.ErrNoRows || (err == nil && pipeline.AppId != request.AppId) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "pipeline not found", UserMessage: "pipeline not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting pipeline by id", "err", err, "pipelineId", request.PipelineId)
+		return nil, err
+	}
+	templateHistory, err := impl.deploymentTemplateHistoryRepository.GetHistoryByPipelineIdAndWfrId(pipeline.Id, request.WfrId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: err.Error(), UserMessage: "no deployed configuration found for this deployment"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting deployment template history", "err", err, "pipelineId", pipeline.Id, "wfrId", request.WfrId)
+		return nil, err
+	}
+	cmHistory, err := impl.configMapHistoryRepository.GetHistoryByPipelineIdAndWfrId(pipeline.Id, request.WfrId, repository2.CONFIGMAP_TYPE)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting configmap history", "err", err, "pipelineId", pipeline.Id, "wfrId", request.WfrId)
+		return nil, err
+	}
+	csHistory, err := impl.configMapHistoryRepository.GetHistoryByPipelineIdAndWfrId(pipeline.Id, request.WfrId, repository2.SECRET_TYPE)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting secret history", "err", err, "pipelineId", pipeline.Id, "wfrId", request.WfrId)
+		return nil, err
+	}
+	if csHistory != nil && csHistory.Id > 0 && impl.enforcerUtil.IsSecretActionDenied(request.UserId, casbin.ActionUpdate, pipeline.AppId, pipeline.EnvironmentId) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "secret action denied", UserMessage: "secrets of this environment can not be restored by the user"}
+	}
+	strategyHistory, err := impl.strategyHistoryRepository.GetHistoryByPipelineIdAndWfrId(pipeline.Id, request.WfrId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting strategy history", "err", err, "pipelineId", pipeline.Id, "wfrId", request.WfrId)
+		return nil, err
+	}
+
+	dbConnection := impl.pipelineRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+
+	response := &ConfigSnapshotRestoreResponse{PipelineId: pipeline.Id, WfrId: request.WfrId}
+	err = impl.restoreDeploymentTemplate(pipeline, templateHistory, request.UserId, tx)
+	if err != nil {
+		return nil, err
+	}
+	response.RestoredComponents = append(response.RestoredComponents, RESTORED_COMPONENT_DEPLOYMENT_TEMPLATE)
+
+	// a snapshot with empty cm/cs data was deployed without them, so the env level entries are cleared, while a
+	// missing snapshot only means nothing was recorded for the deployment and the current entries are kept
+	restoreCm := cmHistory != nil && cmHistory.Id > 0
+	restoreCs := csHistory != nil && csHistory.Id > 0
+	if restoreCm || restoreCs {
+		var cmData, csData *string
+		if restoreCm {
+			cmData = &cmHistory.Data
+		}
+		if restoreCs {
+			csData = &csHistory.Data
+		}
+		err = impl.restoreConfigMapsAndSecrets(pipeline, cmData, csData, request.UserId, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if restoreCm {
+		response.RestoredComponents = append(response.RestoredComponents, RESTORED_COMPONENT_CONFIGMAP)
+	} else {
+		response.NotRestoredComponents = append(response.NotRestoredComponents, RESTORED_COMPONENT_CONFIGMAP)
+	}
+	if restoreCs {
+		response.RestoredComponents = append(response.RestoredComponents, RESTORED_COMPONENT_SECRET)
+	} else {
+		response.NotRestoredComponents = append(response.NotRestoredComponents, RESTORED_COMPONENT_SECRET)
+	}
+
+	if strategyHistory != nil && strategyHistory.Id > 0 {
+		err = impl.restoreStrategy(pipeline, strategyHistory, request.UserId, tx)
+		if err != nil {
+			return nil, err
+		}
+		response.RestoredComponents = append(response.RestoredComponents, RESTORED_COMPONENT_PIPELINE_STRATEGY)
+	} else {
+		response.NotRestoredComponents = append(response.NotRestoredComponents, RESTORED_COMPONENT_PIPELINE_STRATEGY)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("error in committing restored configuration", "err", err, "pipelineId", pipeline.Id, "wfrId", request.WfrId)
+		return nil, err
+	}
+	impl.logger.Infow("restored configuration snapshot", "pipelineId", pipeline.Id, "wfrId", request.WfrId, "components", response.RestoredComponents)
+
+	if request.Deploy {
+		err = impl.deployRestoredConfig(pipeline, request.UserId, ctx)
+		if err != nil {
+			return nil, err
+		}
+		response.Deployed = true
+	}
+	return response, nil
+}
+
+func (impl *ConfigSnapshotRestoreServiceImpl) restoreDeploymentTemplate(pipeline *pipelineConfig.Pipeline, templateHistory *repository2.DeploymentTemplateHistory, userId int32, tx *pg.Tx) error {
+	envOverride, err := impl.envConfigOverrideRepository.FindLatestChartForAppByAppIdAndEnvId(pipeline.AppId, pipeline.EnvironmentId)
+	if err != nil {
+		impl.logger.Errorw("error in getting env config override", "err", err, "appId", pipeline.AppId, "envId", pipeline.EnvironmentId)
+		return err
+	}
+	chartRef, err := impl.chartRefRepository.FindById(envOverride.Chart.ChartRefId)
+	if err != nil {
+		impl.logger.Errorw("error in getting chart ref", "err", err, "chartRefId", envOverride.Chart.ChartRefId)
+		return err
+	}
+	if len(chartRef.Name) == 0 {
+		chartRef.Name = "Rollout Deployment"
+	}
+	//values of a different chart version can not be applied safely, chart has to be changed first
+	if chartRef.Name != templateHistory.TemplateName || chartRef.Version != templateHistory.TemplateVersion {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusConflict,
+			InternalMessage: "chart of deployed template differs from current chart",
+			UserMessage:     fmt.Sprintf("deployment was done with chart %s %s but environment is now using %s %s, please change the chart version before restoring", templateHistory.TemplateName, templateHistory.TemplateVersion, chartRef.Name, chartRef.Version),
+		}
+	}
+	envOverride.EnvOverrideValues = templateHistory.Template
+	envOverride.IsOverride = true
+	envOverride.UpdatedBy = userId
+	envOverride.UpdatedOn = time.Now()
+	_, err = impl.envConfigOverrideRepository.UpdateWithTxn(envOverride, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating env config override", "err", err, "envOverrideId", envOverride.Id)
+		return err
+	}
+	isAppMetricsEnabled, err := impl.isAppMetricsEnabled(pipeline)
+	if err != nil {
+		return err
+	}
+	err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromEnvOverrideTemplate(envOverride, tx, isAppMetricsEnabled, pipeline.Id)
+	if err != nil {
+		impl.logger.Errorw("error in creating deployment template history", "err", err, "envOverrideId", envOverride.Id)
+		return err
+	}
+	return nil
+}
+
+func (impl *ConfigSnapshotRestoreServiceImpl) isAppMetricsEnabled(pipeline *pipelineConfig.Pipeline) (bool, error) {
+	envLevelAppMetrics, err := impl.envLevelAppMetricsRepository.FindByAppIdAndEnvId(pipeline.AppId, pipeline.EnvironmentId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting env level app metrics", "err", err, "appId", pipeline.AppId, "envId", pipeline.EnvironmentId)
+		return false, err
+	} else if err == nil && envLevelAppMetrics.AppMetrics != nil {
+		return *envLevelAppMetrics.AppMetrics, nil
+	}
+	appLevelAppMetrics, err := impl.appLevelMetricsRepository.FindByAppId(pipeline.AppId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app level app metrics", "err", err, "appId", pipeline.AppId)
+		return false, err
+	} else if err == pg.ErrNoRows {
+		return false, nil
+	}
+	return appLevelAppMetrics.AppMetrics, nil
+}
+
+// restoreConfigMapsAndSecrets writes the deployed (app and env level merged) cm/cs data at env level. Entries
+// which are identical to the current app level entry are left out so that they keep inheriting from base config.
+// A nil data is not restored, an empty one clears the env level entries.
+func (impl *ConfigSnapshotRestoreServiceImpl) restoreConfigMapsAndSecrets(pipeline *pipelineConfig.Pipeline, cmData, csData *string, userId int32, tx *pg.Tx) error {
+	appLevelConfig, err := impl.configMapRepository.GetByAppIdAppLevel(pipeline.AppId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app level config", "err", err, "appId", pipeline.AppId)
+		return err
+	}
+	envLevelConfig, err := impl.configMapRepository.GetByAppIdAndEnvIdEnvLevel(pipeline.AppId, pipeline.EnvironmentId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting env level config", "err", err, "appId", pipeline.AppId, "envId", pipeline.EnvironmentId)
+		return err
+	}
+	var appLevelCmData, appLevelCsData string
+	if appLevelConfig != nil {
+		appLevelCmData = appLevelConfig.ConfigMapData
+		appLevelCsData = appLevelConfig.SecretData
+	}
+	if cmData != nil {
+		snapshot, appLevel := &ConfigsList{}, &ConfigsList{}
+		err = unmarshalConfigData(*cmData, snapshot, appLevelCmData, appLevel)
+		if err != nil {
+			impl.logger.Errorw("error in unmarshalling configmap data", "err", err, "pipelineId", pipeline.Id)
+			return err
+		}
+		restored, err := json.Marshal(&ConfigsList{ConfigData: filterInheritedConfigs(snapshot.ConfigData, appLevel.ConfigData)})
+		if err != nil {
+			return err
+		}
+		envLevelConfig.ConfigMapData = string(restored)
+	}
+	if csData != nil {
+		snapshot, appLevel := &SecretsList{}, &SecretsList{}
+		err = unmarshalConfigData(*csData, snapshot, appLevelCsData, appLevel)
+		if err != nil {
+			impl.logger.Errorw("error in unmarshalling secret data", "err", err, "pipelineId", pipeline.Id)
+			return err
+		}
+		restored, err := json.Marshal(&SecretsList{ConfigData: filterInheritedConfigs(snapshot.ConfigData, appLevel.ConfigData)})
+		if err != nil {
+			return err
+		}
+		envLevelConfig.SecretData = string(restored)
+	}
+	envLevelConfig.UpdatedBy = userId
+	envLevelConfig.UpdatedOn = time.Now()
+	if envLevelConfig.Id > 0 {
+		_, err = impl.configMapRepository.UpdateEnvLevelWithTxn(envLevelConfig, tx)
+	} else {
+		envLevelConfig.AppId = pipeline.AppId
+		envLevelConfig.EnvironmentId = pipeline.EnvironmentId
+		envLevelConfig.CreatedBy = userId
+		envLevelConfig.CreatedOn = time.Now()
+		_, err = impl.configMapRepository.CreateEnvLevelWithTxn(envLevelConfig, tx)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving env level config", "err", err, "appId", pipeline.AppId, "envId", pipeline.EnvironmentId)
+		return err
+	}
+	if cmData != nil {
+		err = impl.configMapHistoryService.CreateHistoryFromEnvLevelConfigWithTxn(envLevelConfig, repository2.CONFIGMAP_TYPE, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating configmap history", "err", err, "appId", pipeline.AppId, "envId", pipeline.EnvironmentId)
+			return err
+		}
+	}
+	if csData != nil {
+		err = impl.configMapHistoryService.CreateHistoryFromEnvLevelConfigWithTxn(envLevelConfig, repository2.SECRET_TYPE, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating secret history", "err", err, "appId", pipeline.AppId, "envId", pipeline.EnvironmentId)
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalConfigData(snapshotData string, snapshot interface{}, appLevelData string, appLevel interface{}) error {
+	if len(snapshotData) > 0 {
+		err := json.Unmarshal([]byte(snapshotData), snapshot)
+		if err != nil {
+			return err
+		}
+	}
+	if len(appLevelData) > 0 {
+		return json.Unmarshal([]byte(appLevelData), appLevel)
+	}
+	return nil
+}
+
+func filterInheritedConfigs(snapshotConfigs []*ConfigData, appLevelConfigs []*ConfigData) []*ConfigData {
+	appLevelConfigMap := make(map[string][]byte)
+	for _, config := range appLevelConfigs {
+		configJson, err := json.Marshal(config)
+		if err == nil {
+			appLevelConfigMap[config.Name] = configJson
+		}
+	}
+	restoredConfigs := make([]*ConfigData, 0)
+	for _, config := range snapshotConfigs {
+		if appLevelConfig, ok := appLevelConfigMap[config.Name]; ok {
+			configJson, err := json.Marshal(config)
+			if err == nil && string(configJson) == string(appLevelConfig) {
+				continue
+			}
+		}
+		restoredConfigs = append(restoredConfigs, config)
+	}
+	return restoredConfigs
+}
+
+func (impl *ConfigSnapshotRestoreServiceImpl) restoreStrategy(pipeline *pipelineConfig.Pipeline, strategyHistory *repository2.PipelineStrategyHistory, userId int32, tx *pg.Tx) error {
+	strategies, err := impl.pipelineConfigRepository.GetAllStrategyByPipelineId(pipeline.Id)
+	if err != nil && !errors.IsNotFound(err) {
+		impl.logger.Errorw("error in getting pipeline strategies", "err", err, "pipelineId", pipeline.Id)
+		return err
+	}
+	var restoredStrategy *chartConfig.PipelineStrategy
+	for _, strategy := range strategies {
+		if strategy.Strategy == strategyHistory.Strategy {
+			restoredStrategy = strategy
+			continue
+		}
+		if !strategy.Default {
+			continue
+		}
+		strategy.Default = false
+		strategy.UpdatedBy = userId
+		strategy.UpdatedOn = time.Now()
+		err = impl.pipelineConfigRepository.Update(strategy, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating strategy", "err", err, "strategy", strategy.Strategy)
+			return err
+		}
+		_, err = impl.strategyHistoryService.CreatePipelineStrategyHistory(strategy, pipeline.TriggerType, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating strategy history entry", "err", err)
+			return err
+		}
+	}
+	if restoredStrategy != nil {
+		restoredStrategy.Config = strategyHistory.Config
+		restoredStrategy.Default = true
+		restoredStrategy.UpdatedBy = userId
+		restoredStrategy.UpdatedOn = time.Now()
+		err = impl.pipelineConfigRepository.Update(restoredStrategy, tx)
+	} else {
+		restoredStrategy = &chartConfig.PipelineStrategy{
+			PipelineId: pipeline.Id,
+			Strategy:   strategyHistory.Strategy,
+			Config:     strategyHistory.Config,
+			Default:    true,
+			Deleted:    false,
+			AuditLog:   sql.AuditLog{UpdatedBy: userId, CreatedBy: userId, UpdatedOn: time.Now(), CreatedOn: time.Now()},
+		}
+		err = impl.pipelineConfigRepository.Save(restoredStrategy, tx)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving restored strategy", "err", err, "strategy", strategyHistory.Strategy)
+		return err
+	}
+	_, err = impl.strategyHistoryService.CreatePipelineStrategyHistory(restoredStrategy, pipeline.TriggerType, tx)
+	if err != nil {
+		impl.logger.Errorw("error in creating strategy history entry", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *ConfigSnapshotRestoreServiceImpl) deployRestoredConfig(pipeline *pipelineConfig.Pipeline, userId int32, ctx context.Context) error {
+	latestWfr, err := impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(pipeline.Id, bean2.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil {
+		impl.logger.Errorw("error in getting latest deployment", "err", err, "pipelineId", pipeline.Id)
+		return err
+	}
+	overrideRequest := &bean2.ValuesOverrideRequest{
+		PipelineId:           pipeline.Id,
+		AppId:                pipeline.AppId,
+		CiArtifactId:         latestWfr.CdWorkflow.CiArtifactId,
+		CdWorkflowType:       bean2.CD_WORKFLOW_TYPE_DEPLOY,
+		DeploymentWithConfig: bean2.DEPLOYMENT_CONFIG_TYPE_LAST_SAVED,
+		UserId:               userId,
+	}
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return err
+	}
+	ctx = context.WithValue(ctx, "token", acdToken)
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("error in deploying restored configuration", "err", err, "overrideRequest", overrideRequest)
+		return err
+	}
+	return nil
+}
@@ -1045,6 +1045,8 @@ func (impl DbPipelineOrchestratorImpl) CreateCDPipelines(pipelineRequest *bean.C
 	if len(pipelineRequest.PreStage.Config) > 0 {
 		preStageConfig = pipelineRequest.PreStage.Config
 		preTriggerType = pipelineRequest.PreStage.TriggerType
+	} else if pipelineRequest.PreDeployStage != nil && len(pipelineRequest.PreDeployStage.Steps) > 0 {
+		preTriggerType = pipelineRequest.PreDeployStage.TriggerType
 	}
 
 	postStageConfig := ""
@@ -1052,6 +1054,8 @@ func (impl DbPipelineOrchestratorImpl) CreateCDPipelines(pipelineRequest *bean.C
 	if len(pipelineRequest.PostStage.Config) > 0 {
 		postStageConfig = pipelineRequest.PostStage.Config
 		postTriggerType = pipelineRequest.PostStage.TriggerType
+	} else if pipelineRequest.PostDeployStage != nil && len(pipelineRequest.PostDeployStage.Steps) > 0 {
+		postTriggerType = pipelineRequest.PostDeployStage.TriggerType
 	}
 
 	preStageConfigMapSecretNames, err := json.Marshal(&pipelineRequest.PreStageConfigMapSecretNames)
@@ -1121,6 +1125,8 @@ func (impl DbPipelineOrchestratorImpl) UpdateCDPipeline(pipelineRequest *bean.CD
 	if len(pipelineRequest.PreStage.Config) > 0 {
 		preStageConfig = pipelineRequest.PreStage.Config
 		preTriggerType = pipelineRequest.PreStage.TriggerType
+	} else if pipelineRequest.PreDeployStage != nil && len(pipelineRequest.PreDeployStage.Steps) > 0 {
+		preTriggerType = pipelineRequest.PreDeployStage.TriggerType
 	}
 
 	postStageConfig := ""
@@ -1128,6 +1134,8 @@ func (impl DbPipelineOrchestratorImpl) UpdateCDPipeline(pipelineRequest *bean.CD
 	if len(pipelineRequest.PostStage.Config) > 0 {
 		postStageConfig = pipelineRequest.PostStage.Config
 		postTriggerType = pipelineRequest.PostStage.TriggerType
+	} else if pipelineRequest.PostDeployStage != nil && len(pipelineRequest.PostDeployStage.Steps) > 0 {
+		postTriggerType = pipelineRequest.PostDeployStage.TriggerType
 	}
 
 	preStageConfigMapSecretNames, err := json.Marshal(&pipelineRequest.PreStageConfigMapSecretNames)
@@ -1236,6 +1244,11 @@ func (impl DbPipelineOrchestratorImpl) GetCdPipelinesForApp(appId int) (cdPipeli
 			PostStageConfigMapSecretNames: postStageConfigmapSecrets,
 			DeploymentAppType:             dbPipeline.DeploymentAppType,
 		}
+		pipeline.PreDeployStage, pipeline.PostDeployStage, err = impl.pipelineStageService.GetCdPipelineStageData(dbPipeline)
+		if err != nil {
+			impl.logger.Errorw("error in getting pre/post deploy stages of cd pipeline", "err", err, "cdPipelineId", dbPipeline.Id)
+			return nil, err
+		}
 		pipelines = append(pipelines, pipeline)
 	}
 	cdPipelines = &bean.CdPipelines{
@@ -1306,6 +1319,11 @@ func (impl DbPipelineOrchestratorImpl) GetCdPipelinesForAppAndEnv(appId int, env
 			RunPostStageInEnv:             dbPipeline.RunPostStageInEnv,
 			CdArgoSetup:                   env.Cluster.CdArgoSetup,
 		}
+		pipeline.PreDeployStage, pipeline.PostDeployStage, err = impl.pipelineStageService.GetCdPipelineStageData(dbPipeline)
+		if err != nil {
+			impl.logger.Errorw("error in getting pre/post deploy stages of cd pipeline", "err", err, "cdPipelineId", dbPipeline.Id)
+			return nil, err
+		}
 		pipelines = append(pipelines, pipeline)
 	}
 	cdPipelines = &bean.CdPipelines{
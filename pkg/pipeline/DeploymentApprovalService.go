@@ -0,0 +1,580 @@
+package pipeline
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/devtron-labs/devtron/client/events"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user"
+	util2 "github.com/devtron-labs/devtron/util/event"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultApprovalRequestExpiryHours = 24
+	approvalRequestListLimit          = 50
+)
+
+type DeploymentApprovalService interface {
+	GetApprovalConfig(pipelineId int) (*DeploymentApprovalConfigDto, error)
+	SaveApprovalConfig(configDto *DeploymentApprovalConfigDto) (*DeploymentApprovalConfigDto, error)
+	RaiseApprovalRequest(requestDto *DeploymentApprovalRequestDto) (*DeploymentApprovalRequestDto, error)
+	ActOnApprovalRequest(actionDto *DeploymentApprovalActionDto) (*DeploymentApprovalRequestDto, error)
+	CancelApprovalRequest(requestId int, userId int32) (*DeploymentApprovalRequestDto, error)
+	GetApprovalRequests(pipelineId int) ([]*DeploymentApprovalRequestDto, error)
+	GetApprovalRequestById(requestId int) (*DeploymentApprovalRequestDto, error)
+	// IsArtifactApproved returns true if the pipeline has no approval gate or the artifact holds a valid approval
+	IsArtifactApproved(pipelineId int, ciArtifactId int) (bool, error)
+	// GetApprovedAutoTriggerRequests returns the approved requests whose parked auto trigger is yet to be deployed
+	GetApprovedAutoTriggerRequests() ([]*pipelineConfig.DeploymentApprovalRequest, error)
+	// MarkAutoTriggered returns false if the auto trigger of the request was already picked up
+	MarkAutoTriggered(requestId int) (bool, error)
+}
+
+type DeploymentApprovalServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	deploymentApprovalRepository pipelineConfig.DeploymentApprovalRepository
+	pipelineRepository           pipelineConfig.PipelineRepository
+	ciArtifactRepository         repository.CiArtifactRepository
+	ciPipelineRepository         pipelineConfig.CiPipelineRepository
+	userService                  user.UserService
+	roleGroupService             user.RoleGroupService
+	eventFactory                 client.EventFactory
+	eventClient                  client.EventClient
+}
+
+func NewDeploymentApprovalServiceImpl(logger *zap.SugaredLogger,
+	deploymentApprovalRepository pipelineConfig.DeploymentApprovalRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	ciArtifactRepository repository.CiArtifactRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	userService user.UserService,
+	roleGroupService user.RoleGroupService,
+	eventFactory client.EventFactory,
+	eventClient client.EventClient) *DeploymentApprovalServiceImpl {
+	return &DeploymentApprovalServiceImpl{
+		logger:                       logger,
+		deploymentApprovalRepository: deploymentApprovalRepository,
+		pipelineRepository:           pipelineRepository,
+		ciArtifactRepository:         ciArtifactRepository,
+		ciPipelineRepository:         ciPipelineRepository,
+		userService:                  userService,
+		roleGroupService:             roleGroupService,
+		eventFactory:                 eventFactory,
+		eventClient:                  eventClient,
+	}
+}
+
+type DeploymentApprovalConfigDto struct {
+	Id                   int   `json:"id"`
+	PipelineId           int   `json:"pipelineId" validate:"required"`
+	RequiredApprovals    int   `json:"requiredApprovals" validate:"min=0"`
+	ApproverUserIds      []int `json:"approverUserIds"`
+	ApproverRoleGroupIds []int `json:"approverRoleGroupIds"`
+	RequestExpiryHours   int   `json:"requestExpiryHours" validate:"min=0"`
+	AllowSelfApproval    bool  `json:"allowSelfApproval"`
+	Active               bool  `json:"active"`
+	UserId               int32 `json:"-"`
+}
+
+type DeploymentApprovalRequestDto struct {
+	Id           int                                     `json:"id"`
+	PipelineId   int                                     `json:"pipelineId" validate:"required"`
+	AppId        int                                     `json:"appId"`
+	EnvId        int                                     `json:"envId"`
+	CiArtifactId int                                     `json:"ciArtifactId" validate:"required"`
+	Image        string                                  `json:"image"`
+	Status       pipelineConfig.DeploymentApprovalStatus `json:"status"`
+	Comment      string                                  `json:"comment"`
+	RequestedBy  int32                                   `json:"requestedBy"`
+	RequestedOn  time.Time                               `json:"requestedOn"`
+	ExpiresOn    time.Time                               `json:"expiresOn"`
+	UserActions  []*DeploymentApprovalUserActionDto      `json:"userActions"`
+	UserId       int32                                   `json:"-"`
+	// AutoTrigger is set when an auto triggered deployment is parked behind the request
+	AutoTrigger bool `json:"-"`
+}
+
+type DeploymentApprovalUserActionDto struct {
+	Id       int                                     `json:"id"`
+	UserId   int32                                   `json:"userId"`
+	Action   pipelineConfig.DeploymentApprovalAction `json:"action"`
+	Comment  string                                  `json:"comment"`
+	ActionOn time.Time                               `json:"actionOn"`
+}
+
+type DeploymentApprovalActionDto struct {
+	ApprovalRequestId int                                     `json:"approvalRequestId" validate:"required"`
+	Action            pipelineConfig.DeploymentApprovalAction `json:"action" validate:"oneof=APPROVE REJECT"`
+	Comment           string                                  `json:"comment"`
+	UserId            int32                                   `json:"-"`
+}
+
+func (impl *DeploymentApprovalServiceImpl) GetApprovalConfig(pipelineId int) (*DeploymentApprovalConfigDto, error) {
+	config, err := impl.deploymentApprovalRepository.FindActiveConfigByPipelineId(pipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting deployment approval config", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		return &DeploymentApprovalConfigDto{PipelineId: pipelineId, RequestExpiryHours: DefaultApprovalRequestExpiryHours}, nil
+	}
+	return adaptApprovalConfig(config), nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) SaveApprovalConfig(configDto *DeploymentApprovalConfigDto) (*DeploymentApprovalConfigDto, error) {
+	_, err := impl.pipelineRepository.FindById(configDto.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", configDto.PipelineId)
+		return nil, err
+	}
+	for _, roleGroupId := range configDto.ApproverRoleGroupIds {
+		_, err := impl.roleGroupService.FetchRoleGroupsById(int32(roleGroupId))
+		if err != nil {
+			impl.logger.Errorw("error in getting approver role group", "err", err, "roleGroupId", roleGroupId)
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: fmt.Sprintf("invalid approver role group %d", roleGroupId)}
+		}
+	}
+	if configDto.RequiredApprovals > 0 && len(configDto.ApproverUserIds) == 0 && len(configDto.ApproverRoleGroupIds) == 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "no approvers configured", UserMessage: "at least one approver user or role group is required"}
+	}
+	if configDto.RequestExpiryHours == 0 {
+		configDto.RequestExpiryHours = DefaultApprovalRequestExpiryHours
+	}
+	config, err := impl.deploymentApprovalRepository.FindActiveConfigByPipelineId(configDto.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting deployment approval config", "err", err, "pipelineId", configDto.PipelineId)
+		return nil, err
+	}
+	// zero required approvals switches the approval gate off for the pipeline
+	active := configDto.RequiredApprovals > 0
+	if err == pg.ErrNoRows {
+		if !active {
+			configDto.Active = false
+			return configDto, nil
+		}
+		config = &pipelineConfig.DeploymentApprovalConfig{
+			PipelineId: configDto.PipelineId,
+			AuditLog:   sql.AuditLog{CreatedOn: time.Now(), CreatedBy: configDto.UserId},
+		}
+	}
+	config.RequiredApprovals = configDto.RequiredApprovals
+	config.ApproverUserIds = configDto.ApproverUserIds
+	config.ApproverRoleGroupIds = configDto.ApproverRoleGroupIds
+	config.RequestExpiryHours = configDto.RequestExpiryHours
+	config.AllowSelfApproval = configDto.AllowSelfApproval
+	config.Active = active
+	config.UpdatedOn = time.Now()
+	config.UpdatedBy = configDto.UserId
+	if config.Id == 0 {
+		err = impl.deploymentApprovalRepository.SaveConfig(config)
+	} else {
+		err = impl.deploymentApprovalRepository.UpdateConfig(config)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment approval config", "err", err, "config", config)
+		return nil, err
+	}
+	return adaptApprovalConfig(config), nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) RaiseApprovalRequest(requestDto *DeploymentApprovalRequestDto) (*DeploymentApprovalRequestDto, error) {
+	config, err := impl.deploymentApprovalRepository.FindActiveConfigByPipelineId(requestDto.PipelineId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "approval not configured", UserMessage: "deployment approval is not configured for this pipeline"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting deployment approval config", "err", err, "pipelineId", requestDto.PipelineId)
+		return nil, err
+	}
+	pipeline, err := impl.pipelineRepository.FindById(requestDto.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", requestDto.PipelineId)
+		return nil, err
+	}
+	artifact, err := impl.ciArtifactRepository.Get(requestDto.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci artifact", "err", err, "ciArtifactId", requestDto.CiArtifactId)
+		return nil, err
+	}
+	ciPipeline, err := impl.ciPipelineRepository.FindById(artifact.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting ci pipeline of artifact", "err", err, "ciPipelineId", artifact.PipelineId)
+		return nil, err
+	}
+	if err == pg.ErrNoRows || ciPipeline.AppId != pipeline.AppId {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "artifact of other app", UserMessage: "artifact is not built by a ci pipeline of this app"}
+	}
+	existing, err := impl.deploymentApprovalRepository.FindLatestRequestByPipelineIdAndArtifactId(requestDto.PipelineId, requestDto.CiArtifactId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting latest approval request", "err", err, "pipelineId", requestDto.PipelineId, "ciArtifactId", requestDto.CiArtifactId)
+		return nil, err
+	}
+	if err == nil && existing.Status == pipelineConfig.DEPLOYMENT_APPROVAL_REQUESTED && time.Now().Before(existing.ExpiresOn) {
+		if requestDto.AutoTrigger {
+			// the auto trigger waits on the pending request, it is deployed once the request is approved
+			return impl.parkAutoTriggerOnRequest(existing, pipeline, artifact.Image, requestDto.UserId)
+		}
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "approval request already pending", UserMessage: fmt.Sprintf("approval request %d is already pending for this artifact", existing.Id)}
+	}
+	now := time.Now()
+	request := &pipelineConfig.DeploymentApprovalRequest{
+		PipelineId:   requestDto.PipelineId,
+		CiArtifactId: requestDto.CiArtifactId,
+		Status:       pipelineConfig.DEPLOYMENT_APPROVAL_REQUESTED,
+		Comment:      requestDto.Comment,
+		ExpiresOn:    now.Add(time.Duration(config.RequestExpiryHours) * time.Hour),
+		AutoTrigger:  requestDto.AutoTrigger,
+		AuditLog:     sql.AuditLog{CreatedOn: now, CreatedBy: requestDto.UserId, UpdatedOn: now, UpdatedBy: requestDto.UserId},
+	}
+	err = impl.deploymentApprovalRepository.SaveRequest(request)
+	if err != nil {
+		impl.logger.Errorw("error in saving approval request", "err", err, "request", request)
+		return nil, err
+	}
+	savedRequestDto := impl.adaptApprovalRequest(request, pipeline, artifact.Image, nil)
+	impl.sendApprovalNotification(util2.Approval, savedRequestDto, requestDto.UserId)
+	return savedRequestDto, nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) parkAutoTriggerOnRequest(request *pipelineConfig.DeploymentApprovalRequest, pipeline *pipelineConfig.Pipeline, image string, userId int32) (*DeploymentApprovalRequestDto, error) {
+	if !request.AutoTrigger {
+		request.AutoTrigger = true
+		request.UpdatedOn = time.Now()
+		request.UpdatedBy = userId
+		err := impl.deploymentApprovalRepository.UpdateRequest(request, nil)
+		if err != nil {
+			impl.logger.Errorw("error in updating approval request", "err", err, "requestId", request.Id)
+			return nil, err
+		}
+	}
+	return impl.adaptApprovalRequest(request, pipeline, image, nil), nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) ActOnApprovalRequest(actionDto *DeploymentApprovalActionDto) (*DeploymentApprovalRequestDto, error) {
+	dbConnection := impl.deploymentApprovalRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	request, err := impl.deploymentApprovalRepository.FindRequestByIdForUpdate(actionDto.ApprovalRequestId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in getting approval request", "err", err, "requestId", actionDto.ApprovalRequestId)
+		return nil, err
+	}
+	if request.Status != pipelineConfig.DEPLOYMENT_APPROVAL_REQUESTED {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "request not pending", UserMessage: fmt.Sprintf("approval request is already %s", request.Status)}
+	}
+	if !time.Now().Before(request.ExpiresOn) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "request expired", UserMessage: "approval request has expired, please raise a new request"}
+	}
+	config, err := impl.deploymentApprovalRepository.FindActiveConfigByPipelineId(request.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment approval config", "err", err, "pipelineId", request.PipelineId)
+		return nil, err
+	}
+	if request.CreatedBy == actionDto.UserId && !config.AllowSelfApproval {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "self approval not allowed", UserMessage: "requester can not act on their own approval request"}
+	}
+	eligible, err := impl.isEligibleApprover(config, actionDto.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !eligible {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "user not an approver", UserMessage: "user is not an approver for this pipeline"}
+	}
+	userActions, err := impl.deploymentApprovalRepository.FindUserActionsByRequestIds([]int{request.Id})
+	if err != nil {
+		impl.logger.Errorw("error in getting approval user actions", "err", err, "requestId", request.Id)
+		return nil, err
+	}
+	for _, userAction := range userActions {
+		if userAction.UserId == actionDto.UserId {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "user already acted", UserMessage: "user has already acted on this approval request"}
+		}
+	}
+	now := time.Now()
+	userAction := &pipelineConfig.DeploymentApprovalUserAction{
+		ApprovalRequestId: request.Id,
+		UserId:            actionDto.UserId,
+		Action:            actionDto.Action,
+		Comment:           actionDto.Comment,
+		AuditLog:          sql.AuditLog{CreatedOn: now, CreatedBy: actionDto.UserId, UpdatedOn: now, UpdatedBy: actionDto.UserId},
+	}
+	err = impl.deploymentApprovalRepository.SaveUserAction(userAction, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving approval user action", "err", err, "userAction", userAction)
+		return nil, err
+	}
+	userActions = append(userActions, userAction)
+	status := computeApprovalStatus(userActions, config.RequiredApprovals)
+	if status != request.Status {
+		request.Status = status
+		request.UpdatedOn = now
+		request.UpdatedBy = actionDto.UserId
+		err = impl.deploymentApprovalRepository.UpdateRequest(request, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating approval request", "err", err, "request", request)
+			return nil, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	requestDto, err := impl.GetApprovalRequestById(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if status != pipelineConfig.DEPLOYMENT_APPROVAL_REQUESTED {
+		impl.sendApprovalNotification(util2.ApprovalActioned, requestDto, actionDto.UserId)
+	}
+	return requestDto, nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) CancelApprovalRequest(requestId int, userId int32) (*DeploymentApprovalRequestDto, error) {
+	request, err := impl.deploymentApprovalRepository.FindRequestById(requestId)
+	if err != nil {
+		impl.logger.Errorw("error in getting approval request", "err", err, "requestId", requestId)
+		return nil, err
+	}
+	if request.CreatedBy != userId {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "not requester", UserMessage: "only the requester can cancel an approval request"}
+	}
+	if request.Status != pipelineConfig.DEPLOYMENT_APPROVAL_REQUESTED {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "request not pending", UserMessage: fmt.Sprintf("approval request is already %s", request.Status)}
+	}
+	request.Status = pipelineConfig.DEPLOYMENT_APPROVAL_CANCELLED
+	request.UpdatedOn = time.Now()
+	request.UpdatedBy = userId
+	err = impl.deploymentApprovalRepository.UpdateRequest(request, nil)
+	if err != nil {
+		impl.logger.Errorw("error in cancelling approval request", "err", err, "requestId", requestId)
+		return nil, err
+	}
+	return impl.GetApprovalRequestById(request.Id)
+}
+
+func (impl *DeploymentApprovalServiceImpl) GetApprovalRequests(pipelineId int) ([]*DeploymentApprovalRequestDto, error) {
+	pipeline, err := impl.pipelineRepository.FindById(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	requests, err := impl.deploymentApprovalRepository.FindRequestsByPipelineId(pipelineId, approvalRequestListLimit)
+	if err != nil {
+		impl.logger.Errorw("error in getting approval requests", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	var requestIds []int
+	for _, request := range requests {
+		requestIds = append(requestIds, request.Id)
+	}
+	userActions, err := impl.deploymentApprovalRepository.FindUserActionsByRequestIds(requestIds)
+	if err != nil {
+		impl.logger.Errorw("error in getting approval user actions", "err", err, "requestIds", requestIds)
+		return nil, err
+	}
+	userActionsByRequestId := make(map[int][]*pipelineConfig.DeploymentApprovalUserAction)
+	for _, userAction := range userActions {
+		userActionsByRequestId[userAction.ApprovalRequestId] = append(userActionsByRequestId[userAction.ApprovalRequestId], userAction)
+	}
+	imageByArtifactId := make(map[int]string)
+	requestDtos := make([]*DeploymentApprovalRequestDto, 0, len(requests))
+	for _, request := range requests {
+		image, ok := imageByArtifactId[request.CiArtifactId]
+		if !ok {
+			artifact, err := impl.ciArtifactRepository.Get(request.CiArtifactId)
+			if err != nil {
+				impl.logger.Errorw("error in getting ci artifact", "err", err, "ciArtifactId", request.CiArtifactId)
+				return nil, err
+			}
+			image = artifact.Image
+			imageByArtifactId[request.CiArtifactId] = image
+		}
+		requestDtos = append(requestDtos, impl.adaptApprovalRequest(request, pipeline, image, userActionsByRequestId[request.Id]))
+	}
+	return requestDtos, nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) GetApprovalRequestById(requestId int) (*DeploymentApprovalRequestDto, error) {
+	request, err := impl.deploymentApprovalRepository.FindRequestById(requestId)
+	if err != nil {
+		impl.logger.Errorw("error in getting approval request", "err", err, "requestId", requestId)
+		return nil, err
+	}
+	pipeline, err := impl.pipelineRepository.FindById(request.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", request.PipelineId)
+		return nil, err
+	}
+	artifact, err := impl.ciArtifactRepository.Get(request.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci artifact", "err", err, "ciArtifactId", request.CiArtifactId)
+		return nil, err
+	}
+	userActions, err := impl.deploymentApprovalRepository.FindUserActionsByRequestIds([]int{request.Id})
+	if err != nil {
+		impl.logger.Errorw("error in getting approval user actions", "err", err, "requestId", request.Id)
+		return nil, err
+	}
+	return impl.adaptApprovalRequest(request, pipeline, artifact.Image, userActions), nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) IsArtifactApproved(pipelineId int, ciArtifactId int) (bool, error) {
+	_, err := impl.deploymentApprovalRepository.FindActiveConfigByPipelineId(pipelineId)
+	if err == pg.ErrNoRows {
+		return true, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting deployment approval config", "err", err, "pipelineId", pipelineId)
+		return false, err
+	}
+	request, err := impl.deploymentApprovalRepository.FindLatestRequestByPipelineIdAndArtifactId(pipelineId, ciArtifactId)
+	if err == pg.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting latest approval request", "err", err, "pipelineId", pipelineId, "ciArtifactId", ciArtifactId)
+		return false, err
+	}
+	return request.Status == pipelineConfig.DEPLOYMENT_APPROVAL_APPROVED && time.Now().Before(request.ExpiresOn), nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) GetApprovedAutoTriggerRequests() ([]*pipelineConfig.DeploymentApprovalRequest, error) {
+	requests, err := impl.deploymentApprovalRepository.FindApprovedAutoTriggerRequests()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting approved auto trigger requests", "err", err)
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) MarkAutoTriggered(requestId int) (bool, error) {
+	marked, err := impl.deploymentApprovalRepository.MarkAutoTriggered(requestId)
+	if err != nil {
+		impl.logger.Errorw("error in marking approval request auto triggered", "err", err, "requestId", requestId)
+		return false, err
+	}
+	return marked, nil
+}
+
+// isEligibleApprover checks the user against the configured approvers, nobody is eligible when no approvers are
+// configured
+func (impl *DeploymentApprovalServiceImpl) isEligibleApprover(config *pipelineConfig.DeploymentApprovalConfig, userId int32) (bool, error) {
+	for _, approverUserId := range config.ApproverUserIds {
+		if int32(approverUserId) == userId {
+			return true, nil
+		}
+	}
+	if len(config.ApproverRoleGroupIds) == 0 {
+		return false, nil
+	}
+	userInfo, err := impl.userService.GetById(userId)
+	if err != nil {
+		impl.logger.Errorw("error in getting user", "err", err, "userId", userId)
+		return false, err
+	}
+	userGroups := make(map[string]bool)
+	for _, group := range userInfo.Groups {
+		userGroups[group] = true
+	}
+	for _, roleGroupId := range config.ApproverRoleGroupIds {
+		roleGroup, err := impl.roleGroupService.FetchRoleGroupsById(int32(roleGroupId))
+		if err != nil {
+			impl.logger.Errorw("error in getting approver role group", "err", err, "roleGroupId", roleGroupId)
+			continue
+		}
+		if userGroups[roleGroup.Name] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sendApprovalNotification notifies on an approval request being raised, or on it being approved or rejected with
+// the user acting last
+func (impl *DeploymentApprovalServiceImpl) sendApprovalNotification(eventType util2.EventType, requestDto *DeploymentApprovalRequestDto, userId int32) {
+	event := impl.eventFactory.Build(eventType, &requestDto.PipelineId, requestDto.AppId, &requestDto.EnvId, util2.CD)
+	event.CiArtifactId = requestDto.CiArtifactId
+	event.UserId = int(userId)
+	payload := &client.Payload{DockerImageUrl: requestDto.Image, ApprovalStatus: string(requestDto.Status)}
+	userInfo, err := impl.userService.GetById(userId)
+	if err != nil {
+		impl.logger.Errorw("error in getting user for approval notification", "err", err, "userId", userId)
+	} else {
+		payload.TriggeredBy = userInfo.EmailId
+	}
+	event.Payload = payload
+	_, evtErr := impl.eventClient.WriteNotificationEvent(event)
+	if evtErr != nil {
+		impl.logger.Errorw("error in writing approval notification event", "err", evtErr, "pipelineId", requestDto.PipelineId, "eventType", eventType)
+	}
+}
+
+// computeApprovalStatus derives request status from user actions, a single reject rejects the request
+func computeApprovalStatus(userActions []*pipelineConfig.DeploymentApprovalUserAction, requiredApprovals int) pipelineConfig.DeploymentApprovalStatus {
+	approvals := 0
+	for _, userAction := range userActions {
+		if userAction.Action == pipelineConfig.DEPLOYMENT_APPROVAL_ACTION_REJECT {
+			return pipelineConfig.DEPLOYMENT_APPROVAL_REJECTED
+		}
+		approvals++
+	}
+	if approvals >= requiredApprovals {
+		return pipelineConfig.DEPLOYMENT_APPROVAL_APPROVED
+	}
+	return pipelineConfig.DEPLOYMENT_APPROVAL_REQUESTED
+}
+
+func adaptApprovalConfig(config *pipelineConfig.DeploymentApprovalConfig) *DeploymentApprovalConfigDto {
+	return &DeploymentApprovalConfigDto{
+		Id:                   config.Id,
+		PipelineId:           config.PipelineId,
+		RequiredApprovals:    config.RequiredApprovals,
+		ApproverUserIds:      config.ApproverUserIds,
+		ApproverRoleGroupIds: config.ApproverRoleGroupIds,
+		RequestExpiryHours:   config.RequestExpiryHours,
+		AllowSelfApproval:    config.AllowSelfApproval,
+		Active:               config.Active,
+	}
+}
+
+func (impl *DeploymentApprovalServiceImpl) adaptApprovalRequest(request *pipelineConfig.DeploymentApprovalRequest, pipeline *pipelineConfig.Pipeline, image string, userActions []*pipelineConfig.DeploymentApprovalUserAction) *DeploymentApprovalRequestDto {
+	status := request.Status
+	if status == pipelineConfig.DEPLOYMENT_APPROVAL_REQUESTED || status == pipelineConfig.DEPLOYMENT_APPROVAL_APPROVED {
+		if !time.Now().Before(request.ExpiresOn) {
+			status = pipelineConfig.DEPLOYMENT_APPROVAL_EXPIRED
+		}
+	}
+	requestDto := &DeploymentApprovalRequestDto{
+		Id:           request.Id,
+		PipelineId:   request.PipelineId,
+		AutoTrigger:  request.AutoTrigger,
+		AppId:        pipeline.AppId,
+		EnvId:        pipeline.EnvironmentId,
+		CiArtifactId: request.CiArtifactId,
+		Image:        image,
+		Status:       status,
+		Comment:      request.Comment,
+		RequestedBy:  request.CreatedBy,
+		RequestedOn:  request.CreatedOn,
+		ExpiresOn:    request.ExpiresOn,
+		UserActions:  make([]*DeploymentApprovalUserActionDto, 0, len(userActions)),
+	}
+	for _, userAction := range userActions {
+		requestDto.UserActions = append(requestDto.UserActions, &DeploymentApprovalUserActionDto{
+			Id:       userAction.Id,
+			UserId:   userAction.UserId,
+			Action:   userAction.Action,
+			Comment:  userAction.Comment,
+			ActionOn: userAction.CreatedOn,
+		})
+	}
+	return requestDto
+}
@@ -0,0 +1,613 @@
+package pipeline
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/go-pg/pg"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// overrideValidity is the time for which a super admin override lets async deployments of the same artifact through
+	overrideValidity         = 1 * time.Hour
+	overrideAuditListLimit   = 100
+	maxNextOpenTimeLookahead = 100
+	// deploymentWindowSystemUserId is the user recorded on queued deployments updated by the queue cron
+	deploymentWindowSystemUserId int32 = 1
+)
+
+type DeploymentWindowService interface {
+	CreateOrUpdatePolicy(policyDto *DeploymentWindowPolicyDto) (*DeploymentWindowPolicyDto, error)
+	DeletePolicy(policyId int, userId int32) error
+	GetPolicy(policyId int) (*DeploymentWindowPolicyDto, error)
+	GetAllPolicies() ([]*DeploymentWindowPolicyDto, error)
+	GetOverrideAudits(policyId int) ([]*DeploymentWindowOverrideAuditDto, error)
+	GetDeploymentWindowState(pipeline *pipelineConfig.Pipeline) (*DeploymentWindowState, error)
+	// CheckManualDeployment returns an error if the window is closed, unless a super admin overrides it with a reason
+	CheckManualDeployment(pipeline *pipelineConfig.Pipeline, ciArtifactId int, userId int32, override bool, overrideReason string) error
+	// IsAutoDeploymentAllowed is true if the window is open or the artifact was recently overridden for the pipeline
+	IsAutoDeploymentAllowed(pipeline *pipelineConfig.Pipeline, ciArtifactId int) (bool, error)
+	QueueDeployment(pipelineId int, ciArtifactId int, triggeredBy int32) error
+	GetQueuedDeployments() ([]*pipelineConfig.DeploymentWindowQueue, error)
+	UpdateQueuedDeploymentStatus(item *pipelineConfig.DeploymentWindowQueue, status pipelineConfig.DeploymentWindowQueueStatus, message string, userId int32) error
+	ClaimQueuedDeployment(item *pipelineConfig.DeploymentWindowQueue, status pipelineConfig.DeploymentWindowQueueStatus, message string, userId int32) (bool, error)
+}
+
+type DeploymentWindowServiceImpl struct {
+	logger                     *zap.SugaredLogger
+	deploymentWindowRepository pipelineConfig.DeploymentWindowRepository
+	envRepository              repository2.EnvironmentRepository
+	clusterRepository          repository2.ClusterRepository
+	userService                user.UserService
+}
+
+func NewDeploymentWindowServiceImpl(logger *zap.SugaredLogger,
+	deploymentWindowRepository pipelineConfig.DeploymentWindowRepository,
+	envRepository repository2.EnvironmentRepository,
+	clusterRepository repository2.ClusterRepository,
+	userService user.UserService) *DeploymentWindowServiceImpl {
+	return &DeploymentWindowServiceImpl{
+		logger:                     logger,
+		deploymentWindowRepository: deploymentWindowRepository,
+		envRepository:              envRepository,
+		clusterRepository:          clusterRepository,
+		userService:                userService,
+	}
+}
+
+type DeploymentWindowPolicyDto struct {
+	Id            int                    `json:"id"`
+	Name          string                 `json:"name" validate:"required"`
+	EnvironmentId int                    `json:"environmentId"`
+	ClusterId     int                    `json:"clusterId"`
+	Timezone      string                 `json:"timezone"`
+	Windows       []*DeploymentWindowDto `json:"windows" validate:"dive"`
+	UserId        int32                  `json:"-"`
+}
+
+type DeploymentWindowDto struct {
+	Id              int                                 `json:"id"`
+	WindowType      pipelineConfig.DeploymentWindowType `json:"windowType" validate:"oneof=ALLOWED BLACKOUT"`
+	CronExpression  string                              `json:"cronExpression,omitempty"`
+	DurationMinutes int                                 `json:"durationMinutes,omitempty"`
+	StartTime       *time.Time                          `json:"startTime,omitempty"`
+	EndTime         *time.Time                          `json:"endTime,omitempty"`
+	Description     string                              `json:"description"`
+}
+
+type DeploymentWindowOverrideAuditDto struct {
+	Id           int       `json:"id"`
+	PolicyId     int       `json:"policyId"`
+	PipelineId   int       `json:"pipelineId"`
+	CiArtifactId int       `json:"ciArtifactId"`
+	Reason       string    `json:"reason"`
+	OverriddenBy int32     `json:"overriddenBy"`
+	OverriddenOn time.Time `json:"overriddenOn"`
+}
+
+type DeploymentWindowState struct {
+	Allowed      bool       `json:"allowed"`
+	PolicyId     int        `json:"policyId,omitempty"`
+	PolicyName   string     `json:"policyName,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	NextOpenTime *time.Time `json:"nextOpenTime,omitempty"`
+}
+
+func (impl *DeploymentWindowServiceImpl) CreateOrUpdatePolicy(policyDto *DeploymentWindowPolicyDto) (*DeploymentWindowPolicyDto, error) {
+	err := impl.validatePolicy(policyDto)
+	if err != nil {
+		return nil, err
+	}
+	var policy *pipelineConfig.DeploymentWindowPolicy
+	if policyDto.Id > 0 {
+		policy, err = impl.deploymentWindowRepository.FindPolicyById(policyDto.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting deployment window policy", "err", err, "policyId", policyDto.Id)
+			return nil, err
+		}
+	} else {
+		policy = &pipelineConfig.DeploymentWindowPolicy{
+			Active:   true,
+			AuditLog: sql.AuditLog{CreatedOn: time.Now(), CreatedBy: policyDto.UserId},
+		}
+	}
+	policy.Name = policyDto.Name
+	policy.EnvironmentId = policyDto.EnvironmentId
+	policy.ClusterId = policyDto.ClusterId
+	policy.Timezone = policyDto.Timezone
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = policyDto.UserId
+
+	dbConnection := impl.deploymentWindowRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	if policy.Id == 0 {
+		err = impl.deploymentWindowRepository.SavePolicy(policy, tx)
+	} else {
+		err = impl.deploymentWindowRepository.UpdatePolicy(policy, tx)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment window policy", "err", err, "policy", policy)
+		return nil, err
+	}
+	// windows are replaced as a whole on every update
+	err = impl.deploymentWindowRepository.DeactivateWindowsByPolicyId(policy.Id, policyDto.UserId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deactivating deployment windows", "err", err, "policyId", policy.Id)
+		return nil, err
+	}
+	var windows []*pipelineConfig.DeploymentWindow
+	for _, windowDto := range policyDto.Windows {
+		window := &pipelineConfig.DeploymentWindow{
+			PolicyId:        policy.Id,
+			WindowType:      windowDto.WindowType,
+			CronExpression:  windowDto.CronExpression,
+			DurationMinutes: windowDto.DurationMinutes,
+			Description:     windowDto.Description,
+			Active:          true,
+			AuditLog:        sql.AuditLog{CreatedOn: time.Now(), CreatedBy: policyDto.UserId, UpdatedOn: time.Now(), UpdatedBy: policyDto.UserId},
+		}
+		if windowDto.StartTime != nil {
+			window.StartTime = *windowDto.StartTime
+		}
+		if windowDto.EndTime != nil {
+			window.EndTime = *windowDto.EndTime
+		}
+		windows = append(windows, window)
+	}
+	err = impl.deploymentWindowRepository.SaveWindows(windows, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment windows", "err", err, "policyId", policy.Id)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return adaptDeploymentWindowPolicy(policy, windows), nil
+}
+
+func (impl *DeploymentWindowServiceImpl) DeletePolicy(policyId int, userId int32) error {
+	policy, err := impl.deploymentWindowRepository.FindPolicyById(policyId)
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment window policy", "err", err, "policyId", policyId)
+		return err
+	}
+	dbConnection := impl.deploymentWindowRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	policy.Active = false
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = userId
+	err = impl.deploymentWindowRepository.UpdatePolicy(policy, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting deployment window policy", "err", err, "policyId", policyId)
+		return err
+	}
+	err = impl.deploymentWindowRepository.DeactivateWindowsByPolicyId(policyId, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deactivating deployment windows", "err", err, "policyId", policyId)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl *DeploymentWindowServiceImpl) GetPolicy(policyId int) (*DeploymentWindowPolicyDto, error) {
+	policy, err := impl.deploymentWindowRepository.FindPolicyById(policyId)
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment window policy", "err", err, "policyId", policyId)
+		return nil, err
+	}
+	windows, err := impl.deploymentWindowRepository.FindActiveWindowsByPolicyId(policy.Id)
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment windows", "err", err, "policyId", policy.Id)
+		return nil, err
+	}
+	return adaptDeploymentWindowPolicy(policy, windows), nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetAllPolicies() ([]*DeploymentWindowPolicyDto, error) {
+	policies, err := impl.deploymentWindowRepository.FindAllActivePolicies()
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment window policies", "err", err)
+		return nil, err
+	}
+	policyDtos := make([]*DeploymentWindowPolicyDto, 0, len(policies))
+	for _, policy := range policies {
+		windows, err := impl.deploymentWindowRepository.FindActiveWindowsByPolicyId(policy.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting deployment windows", "err", err, "policyId", policy.Id)
+			return nil, err
+		}
+		policyDtos = append(policyDtos, adaptDeploymentWindowPolicy(policy, windows))
+	}
+	return policyDtos, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetOverrideAudits(policyId int) ([]*DeploymentWindowOverrideAuditDto, error) {
+	audits, err := impl.deploymentWindowRepository.FindOverrideAuditsByPolicyId(policyId, overrideAuditListLimit)
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment window override audits", "err", err, "policyId", policyId)
+		return nil, err
+	}
+	auditDtos := make([]*DeploymentWindowOverrideAuditDto, 0, len(audits))
+	for _, audit := range audits {
+		auditDtos = append(auditDtos, &DeploymentWindowOverrideAuditDto{
+			Id:           audit.Id,
+			PolicyId:     audit.PolicyId,
+			PipelineId:   audit.PipelineId,
+			CiArtifactId: audit.CiArtifactId,
+			Reason:       audit.Reason,
+			OverriddenBy: audit.CreatedBy,
+			OverriddenOn: audit.CreatedOn,
+		})
+	}
+	return auditDtos, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetDeploymentWindowState(pipeline *pipelineConfig.Pipeline) (*DeploymentWindowState, error) {
+	policy, err := impl.findPolicyForPipeline(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return &DeploymentWindowState{Allowed: true}, nil
+	}
+	windows, err := impl.deploymentWindowRepository.FindActiveWindowsByPolicyId(policy.Id)
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment windows", "err", err, "policyId", policy.Id)
+		return nil, err
+	}
+	loc, err := time.LoadLocation(policy.Timezone)
+	if err != nil {
+		impl.logger.Errorw("invalid timezone in deployment window policy, using UTC", "err", err, "policyId", policy.Id)
+		loc = time.UTC
+	}
+	state, err := evaluateDeploymentWindows(windows, loc, time.Now())
+	if err != nil {
+		impl.logger.Errorw("error in evaluating deployment windows", "err", err, "policyId", policy.Id)
+		return nil, err
+	}
+	state.PolicyId = policy.Id
+	state.PolicyName = policy.Name
+	return state, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) CheckManualDeployment(pipeline *pipelineConfig.Pipeline, ciArtifactId int, userId int32, override bool, overrideReason string) error {
+	state, err := impl.GetDeploymentWindowState(pipeline)
+	if err != nil {
+		return err
+	}
+	if state.Allowed {
+		return nil
+	}
+	if !override {
+		return &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: state.Reason, UserMessage: deploymentWindowClosedMessage(state)}
+	}
+	isSuperAdmin, err := impl.userService.IsSuperAdmin(int(userId))
+	if err != nil {
+		impl.logger.Errorw("error in checking super admin", "err", err, "userId", userId)
+		return err
+	}
+	if !isSuperAdmin {
+		return &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "override by non super admin", UserMessage: "only super admin can override the deployment window"}
+	}
+	if len(overrideReason) == 0 {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "override reason missing", UserMessage: "reason is required to override the deployment window"}
+	}
+	audit := &pipelineConfig.DeploymentWindowOverrideAudit{
+		PolicyId:     state.PolicyId,
+		PipelineId:   pipeline.Id,
+		CiArtifactId: ciArtifactId,
+		Reason:       overrideReason,
+		AuditLog:     sql.AuditLog{CreatedOn: time.Now(), CreatedBy: userId, UpdatedOn: time.Now(), UpdatedBy: userId},
+	}
+	err = impl.deploymentWindowRepository.SaveOverrideAudit(audit)
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment window override audit", "err", err, "audit", audit)
+		return err
+	}
+	impl.logger.Infow("deployment window overridden", "pipelineId", pipeline.Id, "ciArtifactId", ciArtifactId, "userId", userId, "policyId", state.PolicyId)
+	return nil
+}
+
+func (impl *DeploymentWindowServiceImpl) IsAutoDeploymentAllowed(pipeline *pipelineConfig.Pipeline, ciArtifactId int) (bool, error) {
+	state, err := impl.GetDeploymentWindowState(pipeline)
+	if err != nil {
+		return false, err
+	}
+	if state.Allowed {
+		return true, nil
+	}
+	overridden, err := impl.deploymentWindowRepository.ExistsOverrideAuditAfter(pipeline.Id, ciArtifactId, time.Now().Add(-overrideValidity))
+	if err != nil {
+		impl.logger.Errorw("error in checking deployment window override", "err", err, "pipelineId", pipeline.Id, "ciArtifactId", ciArtifactId)
+		return false, err
+	}
+	return overridden, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) QueueDeployment(pipelineId int, ciArtifactId int, triggeredBy int32) error {
+	queuedItems, err := impl.deploymentWindowRepository.FindQueuedItemsByPipelineId(pipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting queued deployments", "err", err, "pipelineId", pipelineId)
+		return err
+	}
+	// only the latest artifact is deployed once the window opens
+	for _, queuedItem := range queuedItems {
+		if queuedItem.CiArtifactId == ciArtifactId {
+			return nil
+		}
+		_, err = impl.ClaimQueuedDeployment(queuedItem, pipelineConfig.DEPLOYMENT_WINDOW_QUEUE_SUPERSEDED, fmt.Sprintf("superseded by artifact %d", ciArtifactId), triggeredBy)
+		if err != nil {
+			return err
+		}
+	}
+	item := &pipelineConfig.DeploymentWindowQueue{
+		PipelineId:   pipelineId,
+		CiArtifactId: ciArtifactId,
+		TriggeredBy:  triggeredBy,
+		Status:       pipelineConfig.DEPLOYMENT_WINDOW_QUEUE_QUEUED,
+		AuditLog:     sql.AuditLog{CreatedOn: time.Now(), CreatedBy: triggeredBy, UpdatedOn: time.Now(), UpdatedBy: triggeredBy},
+	}
+	err = impl.deploymentWindowRepository.SaveQueueItem(item)
+	if err != nil {
+		impl.logger.Errorw("error in queueing deployment", "err", err, "item", item)
+		return err
+	}
+	return nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetQueuedDeployments() ([]*pipelineConfig.DeploymentWindowQueue, error) {
+	items, err := impl.deploymentWindowRepository.FindQueuedItems()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting queued deployments", "err", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) UpdateQueuedDeploymentStatus(item *pipelineConfig.DeploymentWindowQueue, status pipelineConfig.DeploymentWindowQueueStatus, message string, userId int32) error {
+	item.Status = status
+	item.Message = message
+	item.UpdatedOn = time.Now()
+	item.UpdatedBy = userId
+	err := impl.deploymentWindowRepository.UpdateQueueItem(item)
+	if err != nil {
+		impl.logger.Errorw("error in updating queued deployment", "err", err, "item", item)
+		return err
+	}
+	return nil
+}
+
+// ClaimQueuedDeployment moves the item out of the queue only if no other instance has picked it up yet
+func (impl *DeploymentWindowServiceImpl) ClaimQueuedDeployment(item *pipelineConfig.DeploymentWindowQueue, status pipelineConfig.DeploymentWindowQueueStatus, message string, userId int32) (bool, error) {
+	item.Status = status
+	item.Message = message
+	item.UpdatedOn = time.Now()
+	item.UpdatedBy = userId
+	claimed, err := impl.deploymentWindowRepository.UpdateQueueItemIfQueued(item)
+	if err != nil {
+		impl.logger.Errorw("error in claiming queued deployment", "err", err, "item", item)
+		return false, err
+	}
+	return claimed, nil
+}
+
+// findPolicyForPipeline returns the environment policy of the pipeline, falling back to the policy of its cluster
+func (impl *DeploymentWindowServiceImpl) findPolicyForPipeline(pipeline *pipelineConfig.Pipeline) (*pipelineConfig.DeploymentWindowPolicy, error) {
+	policy, err := impl.deploymentWindowRepository.FindActivePolicyByEnvironmentId(pipeline.EnvironmentId)
+	if err == nil {
+		return policy, nil
+	} else if err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting deployment window policy by env", "err", err, "envId", pipeline.EnvironmentId)
+		return nil, err
+	}
+	clusterId := pipeline.Environment.ClusterId
+	if clusterId == 0 {
+		env, err := impl.envRepository.FindById(pipeline.EnvironmentId)
+		if err != nil {
+			impl.logger.Errorw("error in getting environment", "err", err, "envId", pipeline.EnvironmentId)
+			return nil, err
+		}
+		clusterId = env.ClusterId
+	}
+	policy, err = impl.deploymentWindowRepository.FindActivePolicyByClusterId(clusterId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting deployment window policy by cluster", "err", err, "clusterId", clusterId)
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) validatePolicy(policyDto *DeploymentWindowPolicyDto) error {
+	if (policyDto.EnvironmentId > 0) == (policyDto.ClusterId > 0) {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid policy target", UserMessage: "policy must be attached to either an environment or a cluster"}
+	}
+	if len(policyDto.Timezone) == 0 {
+		policyDto.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(policyDto.Timezone); err != nil {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: fmt.Sprintf("invalid timezone %s", policyDto.Timezone)}
+	}
+	var existing *pipelineConfig.DeploymentWindowPolicy
+	var err error
+	if policyDto.EnvironmentId > 0 {
+		if _, err = impl.envRepository.FindById(policyDto.EnvironmentId); err != nil {
+			impl.logger.Errorw("error in getting environment", "err", err, "envId", policyDto.EnvironmentId)
+			return err
+		}
+		existing, err = impl.deploymentWindowRepository.FindActivePolicyByEnvironmentId(policyDto.EnvironmentId)
+	} else {
+		if _, err = impl.clusterRepository.FindById(policyDto.ClusterId); err != nil {
+			impl.logger.Errorw("error in getting cluster", "err", err, "clusterId", policyDto.ClusterId)
+			return err
+		}
+		existing, err = impl.deploymentWindowRepository.FindActivePolicyByClusterId(policyDto.ClusterId)
+	}
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting existing deployment window policy", "err", err, "policy", policyDto)
+		return err
+	}
+	if err == nil && existing.Id != policyDto.Id {
+		return &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "policy already exists", UserMessage: fmt.Sprintf("deployment window policy %s already exists for this target", existing.Name)}
+	}
+	for _, window := range policyDto.Windows {
+		if len(window.CronExpression) > 0 {
+			if _, err := cron.ParseStandard(window.CronExpression); err != nil {
+				return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: fmt.Sprintf("invalid cron expression %s", window.CronExpression)}
+			}
+			if window.DurationMinutes <= 0 {
+				return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid duration", UserMessage: "duration is required for recurring windows"}
+			}
+		} else if window.StartTime == nil || window.EndTime == nil || !window.EndTime.After(*window.StartTime) {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid window range", UserMessage: "window needs either a cron expression or a valid start and end time"}
+		}
+	}
+	return nil
+}
+
+func deploymentWindowClosedMessage(state *DeploymentWindowState) string {
+	message := fmt.Sprintf("deployment is not allowed now by deployment window policy %s", state.PolicyName)
+	if state.NextOpenTime != nil {
+		message = fmt.Sprintf("%s, next window opens at %s", message, state.NextOpenTime.Format(time.RFC3339))
+	}
+	return message
+}
+
+// evaluateDeploymentWindows allows deployment when no blackout is active and, if allowed windows are configured,
+// one of them is active. When not allowed the next open time is looked up by jumping between window boundaries.
+func evaluateDeploymentWindows(windows []*pipelineConfig.DeploymentWindow, loc *time.Location, now time.Time) (*DeploymentWindowState, error) {
+	now = now.In(loc)
+	state := &DeploymentWindowState{}
+	at := now
+	for i := 0; i < maxNextOpenTimeLookahead; i++ {
+		allowed, reason, next, err := evaluateDeploymentWindowsAt(windows, at)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			state.Allowed = allowed
+			state.Reason = reason
+		}
+		if allowed {
+			if i > 0 {
+				state.NextOpenTime = &at
+			}
+			return state, nil
+		}
+		if next.IsZero() {
+			break
+		}
+		at = next
+	}
+	return state, nil
+}
+
+// evaluateDeploymentWindowsAt returns whether deployment is allowed at t, and if not, the next boundary at which it may open
+func evaluateDeploymentWindowsAt(windows []*pipelineConfig.DeploymentWindow, t time.Time) (bool, string, time.Time, error) {
+	var blackoutEnd time.Time
+	var nextAllowedStart time.Time
+	hasAllowedWindow := false
+	inAllowedWindow := false
+	for _, window := range windows {
+		active, start, end, err := windowActiveAt(window, t)
+		if err != nil {
+			return false, "", time.Time{}, err
+		}
+		switch window.WindowType {
+		case pipelineConfig.DEPLOYMENT_WINDOW_TYPE_BLACKOUT:
+			if active && end.After(blackoutEnd) {
+				blackoutEnd = end
+			}
+		case pipelineConfig.DEPLOYMENT_WINDOW_TYPE_ALLOWED:
+			hasAllowedWindow = true
+			if active {
+				inAllowedWindow = true
+			} else if !start.IsZero() && (nextAllowedStart.IsZero() || start.Before(nextAllowedStart)) {
+				nextAllowedStart = start
+			}
+		}
+	}
+	if !blackoutEnd.IsZero() {
+		return false, "blackout period is active", blackoutEnd, nil
+	}
+	if hasAllowedWindow && !inAllowedWindow {
+		return false, "outside allowed deployment windows", nextAllowedStart, nil
+	}
+	return true, "", time.Time{}, nil
+}
+
+// windowActiveAt tells if the window is active at t. For an active window end is when it closes,
+// otherwise start is when it opens next (zero if never).
+func windowActiveAt(window *pipelineConfig.DeploymentWindow, t time.Time) (active bool, start time.Time, end time.Time, err error) {
+	if len(window.CronExpression) == 0 {
+		if !t.Before(window.StartTime) && t.Before(window.EndTime) {
+			return true, window.StartTime, window.EndTime, nil
+		}
+		if t.Before(window.StartTime) {
+			return false, window.StartTime, window.EndTime, nil
+		}
+		return false, time.Time{}, time.Time{}, nil
+	}
+	schedule, err := cron.ParseStandard(window.CronExpression)
+	if err != nil {
+		return false, time.Time{}, time.Time{}, err
+	}
+	duration := time.Duration(window.DurationMinutes) * time.Minute
+	// a window is active if it started within the last duration
+	lastStart := schedule.Next(t.Add(-duration))
+	if !lastStart.After(t) {
+		return true, lastStart, lastStart.Add(duration), nil
+	}
+	nextStart := schedule.Next(t)
+	return false, nextStart, nextStart.Add(duration), nil
+}
+
+func adaptDeploymentWindowPolicy(policy *pipelineConfig.DeploymentWindowPolicy, windows []*pipelineConfig.DeploymentWindow) *DeploymentWindowPolicyDto {
+	policyDto := &DeploymentWindowPolicyDto{
+		Id:            policy.Id,
+		Name:          policy.Name,
+		EnvironmentId: policy.EnvironmentId,
+		ClusterId:     policy.ClusterId,
+		Timezone:      policy.Timezone,
+		Windows:       make([]*DeploymentWindowDto, 0, len(windows)),
+	}
+	for _, window := range windows {
+		windowDto := &DeploymentWindowDto{
+			Id:              window.Id,
+			WindowType:      window.WindowType,
+			CronExpression:  window.CronExpression,
+			DurationMinutes: window.DurationMinutes,
+			Description:     window.Description,
+		}
+		if !window.StartTime.IsZero() {
+			startTime := window.StartTime
+			windowDto.StartTime = &startTime
+		}
+		if !window.EndTime.IsZero() {
+			endTime := window.EndTime
+			windowDto.EndTime = &endTime
+		}
+		policyDto.Windows = append(policyDto.Windows, windowDto)
+	}
+	return policyDto
+}
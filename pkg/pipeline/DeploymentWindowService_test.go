@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+)
+
+func TestEvaluateDeploymentWindows(t *testing.T) {
+	loc := time.UTC
+	// weekdays 09:00 to 17:00
+	businessHours := &pipelineConfig.DeploymentWindow{
+		WindowType:      pipelineConfig.DEPLOYMENT_WINDOW_TYPE_ALLOWED,
+		CronExpression:  "0 9 * * 1-5",
+		DurationMinutes: 8 * 60,
+	}
+	freeze := &pipelineConfig.DeploymentWindow{
+		WindowType: pipelineConfig.DEPLOYMENT_WINDOW_TYPE_BLACKOUT,
+		StartTime:  time.Date(2022, 12, 21, 0, 0, 0, 0, loc),
+		EndTime:    time.Date(2022, 12, 23, 12, 0, 0, 0, loc),
+	}
+	windows := []*pipelineConfig.DeploymentWindow{businessHours, freeze}
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		allowed      bool
+		nextOpenTime time.Time
+	}{
+		{name: "inside business hours", now: time.Date(2022, 12, 19, 10, 0, 0, 0, loc), allowed: true},
+		{name: "before business hours", now: time.Date(2022, 12, 19, 7, 30, 0, 0, loc), nextOpenTime: time.Date(2022, 12, 19, 9, 0, 0, 0, loc)},
+		{name: "weekend", now: time.Date(2022, 12, 17, 12, 0, 0, 0, loc), nextOpenTime: time.Date(2022, 12, 19, 9, 0, 0, 0, loc)},
+		{name: "blackout inside business hours", now: time.Date(2022, 12, 22, 10, 0, 0, 0, loc), nextOpenTime: time.Date(2022, 12, 23, 12, 0, 0, 0, loc)},
+		{name: "blackout ending after business hours", now: time.Date(2022, 12, 21, 16, 0, 0, 0, loc), nextOpenTime: time.Date(2022, 12, 23, 12, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := evaluateDeploymentWindows(windows, loc, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if state.Allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v", state.Allowed, tt.allowed)
+			}
+			if tt.allowed {
+				return
+			}
+			if state.NextOpenTime == nil || !state.NextOpenTime.Equal(tt.nextOpenTime) {
+				t.Errorf("next open time = %v, want %v", state.NextOpenTime, tt.nextOpenTime)
+			}
+		})
+	}
+}
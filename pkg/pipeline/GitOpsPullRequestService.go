@@ -0,0 +1,256 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/app"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"go.uber.org/zap"
+)
+
+const (
+	gitOpsPullRequestSystemUserId int32 = 1
+	// gitOpsPullRequestClaimTimeout is the time after which a merged pull request claimed by an instance which went
+	// down is deployed again
+	gitOpsPullRequestClaimTimeout = 10 * time.Minute
+)
+
+type GitOpsPullRequestConfig struct {
+	// MergeTimeoutMins is the time for which a deployment waits for its pull request to be merged
+	MergeTimeoutMins int `env:"GITOPS_PR_MERGE_TIMEOUT_MINS" envDefault:"1440"`
+}
+
+type GitOpsPullRequestService interface {
+	// SyncOpenPullRequests checks all open gitops pull requests, deployments continue for merged ones and
+	// fail for the ones closed without merge, not merged within the configured timeout or superseded by a later
+	// pull request of the pipeline
+	SyncOpenPullRequests()
+}
+
+type GitOpsPullRequestServiceImpl struct {
+	logger                           *zap.SugaredLogger
+	cfg                              *GitOpsPullRequestConfig
+	gitOpsPullRequestRepository      pipelineConfig.GitOpsPullRequestRepository
+	pipelineOverrideRepository       chartConfig.PipelineOverrideRepository
+	cdWorkflowRepository             pipelineConfig.CdWorkflowRepository
+	pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository
+	gitFactory                       *util.GitFactory
+	appService                       app.AppService
+}
+
+func NewGitOpsPullRequestServiceImpl(logger *zap.SugaredLogger,
+	gitOpsPullRequestRepository pipelineConfig.GitOpsPullRequestRepository,
+	pipelineOverrideRepository chartConfig.PipelineOverrideRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository,
+	gitFactory *util.GitFactory,
+	appService app.AppService) *GitOpsPullRequestServiceImpl {
+	cfg := &GitOpsPullRequestConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing GitOpsPullRequestConfig, so setting to default values", "err", err)
+	}
+	return &GitOpsPullRequestServiceImpl{
+		logger:                           logger,
+		cfg:                              cfg,
+		gitOpsPullRequestRepository:      gitOpsPullRequestRepository,
+		pipelineOverrideRepository:       pipelineOverrideRepository,
+		cdWorkflowRepository:             cdWorkflowRepository,
+		pipelineStatusTimelineRepository: pipelineStatusTimelineRepository,
+		gitFactory:                       gitFactory,
+		appService:                       appService,
+	}
+}
+
+func (impl *GitOpsPullRequestServiceImpl) SyncOpenPullRequests() {
+	// claims of instances which went down while deploying a merged pull request are retried
+	err := impl.gitOpsPullRequestRepository.ReleaseStaleClaims(pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGING,
+		pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN, time.Now().Add(-gitOpsPullRequestClaimTimeout))
+	if err != nil {
+		impl.logger.Errorw("error in releasing stale gitops pull request claims", "err", err)
+	}
+	pullRequests, err := impl.gitOpsPullRequestRepository.FindByStatus(pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN)
+	if err != nil {
+		impl.logger.Errorw("error in fetching open gitops pull requests", "err", err)
+		return
+	}
+	latestPullRequestIds := getLatestPullRequestIds(pullRequests)
+	for _, pullRequest := range pullRequests {
+		err = impl.syncPullRequest(pullRequest, latestPullRequestIds[pullRequest.PipelineId])
+		if err != nil {
+			impl.logger.Errorw("error in syncing gitops pull request", "pullRequest", pullRequest, "err", err)
+		}
+	}
+}
+
+func (impl *GitOpsPullRequestServiceImpl) syncPullRequest(pullRequest *pipelineConfig.GitOpsPullRequest, latestPullRequestId int) error {
+	pr, err := impl.gitFactory.Client.GetPullRequest(pullRequest.RepoName, pullRequest.PrNumber)
+	if err != nil {
+		impl.logger.Errorw("error in getting pull request from git provider", "repo", pullRequest.RepoName, "number", pullRequest.PrNumber, "err", err)
+		return err
+	}
+	timeout := time.Duration(impl.cfg.MergeTimeoutMins) * time.Minute
+	status, message := evaluatePullRequest(pullRequest, pr.State, latestPullRequestId, timeout, time.Now())
+	switch status {
+	case pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED:
+		return impl.handleMerged(pullRequest, pr)
+	case pipelineConfig.GITOPS_PULL_REQUEST_STATUS_CLOSED:
+		return impl.handleNotMerged(pullRequest, status, message, false)
+	case pipelineConfig.GITOPS_PULL_REQUEST_STATUS_TIMED_OUT, pipelineConfig.GITOPS_PULL_REQUEST_STATUS_SUPERSEDED:
+		// closed on the provider so that a late merge does not roll out values of a failed deployment
+		return impl.handleNotMerged(pullRequest, status, message, true)
+	}
+	return nil
+}
+
+// evaluatePullRequest returns the status an open pull request moves to along with the failure message, the status
+// stays open till the pull request is merged, closed, timed out or superseded by a later pull request of the pipeline
+func evaluatePullRequest(pullRequest *pipelineConfig.GitOpsPullRequest, state string, latestPullRequestId int, timeout time.Duration, now time.Time) (pipelineConfig.GitOpsPullRequestStatus, string) {
+	switch state {
+	case util.PULL_REQUEST_STATE_MERGED:
+		return pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED, ""
+	case util.PULL_REQUEST_STATE_CLOSED:
+		return pipelineConfig.GITOPS_PULL_REQUEST_STATUS_CLOSED, fmt.Sprintf("Pull request %s closed without merge.", pullRequest.PrUrl)
+	}
+	if latestPullRequestId > pullRequest.Id {
+		return pipelineConfig.GITOPS_PULL_REQUEST_STATUS_SUPERSEDED, fmt.Sprintf("Pull request %s superseded by a later deployment.", pullRequest.PrUrl)
+	}
+	if now.Sub(pullRequest.CreatedOn) > timeout {
+		return pipelineConfig.GITOPS_PULL_REQUEST_STATUS_TIMED_OUT, fmt.Sprintf("Pull request %s not merged in %d minutes.", pullRequest.PrUrl, int(timeout.Minutes()))
+	}
+	return pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN, ""
+}
+
+// getLatestPullRequestIds returns the id of the latest open pull request of every pipeline
+func getLatestPullRequestIds(pullRequests []*pipelineConfig.GitOpsPullRequest) map[int]int {
+	latestPullRequestIds := make(map[int]int)
+	for _, pullRequest := range pullRequests {
+		if pullRequest.Id > latestPullRequestIds[pullRequest.PipelineId] {
+			latestPullRequestIds[pullRequest.PipelineId] = pullRequest.Id
+		}
+	}
+	return latestPullRequestIds
+}
+
+// handleMerged points the release to the merge commit and updates argo cd to sync master, the usual status tracking
+// takes over from here
+func (impl *GitOpsPullRequestServiceImpl) handleMerged(pullRequest *pipelineConfig.GitOpsPullRequest, pr *util.PullRequestDto) error {
+	claimed, err := impl.gitOpsPullRequestRepository.UpdateStatus(pullRequest.Id, pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN,
+		pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGING, gitOpsPullRequestSystemUserId)
+	if err != nil {
+		impl.logger.Errorw("error in claiming gitops pull request", "pullRequest", pullRequest, "err", err)
+		return err
+	}
+	if !claimed {
+		impl.logger.Infow("gitops pull request already processed by another instance", "pullRequestId", pullRequest.Id)
+		return nil
+	}
+	err = impl.deployMerged(pullRequest, pr)
+	if err != nil {
+		// pull request is opened again so that the deployment is retried on the next sync
+		impl.releaseClaim(pullRequest, pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGING)
+		return err
+	}
+	impl.saveTimeline(pullRequest.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_GIT_COMMIT,
+		fmt.Sprintf("Pull request %s merged, git commit done successfully.", pullRequest.PrUrl))
+	return nil
+}
+
+func (impl *GitOpsPullRequestServiceImpl) deployMerged(pullRequest *pipelineConfig.GitOpsPullRequest, pr *util.PullRequestDto) error {
+	pipelineOverride, err := impl.pipelineOverrideRepository.FindById(pullRequest.PipelineOverrideId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline override", "id", pullRequest.PipelineOverrideId, "err", err)
+		return err
+	}
+	pipelineOverride.GitHash = pr.MergeCommitHash
+	pipelineOverride.CommitTime = time.Now()
+	pipelineOverride.UpdatedOn = time.Now()
+	pipelineOverride.UpdatedBy = gitOpsPullRequestSystemUserId
+	err = impl.pipelineOverrideRepository.Update(pipelineOverride)
+	if err != nil {
+		impl.logger.Errorw("error in updating git hash of pipeline override", "id", pullRequest.PipelineOverrideId, "err", err)
+		return err
+	}
+	err = impl.appService.DeployMergedPullRequest(pipelineOverride)
+	if err != nil {
+		impl.logger.Errorw("error in deploying merged pull request", "pullRequest", pullRequest, "err", err)
+		return err
+	}
+	pullRequest.Status = pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED
+	pullRequest.MergeCommitHash = pr.MergeCommitHash
+	pullRequest.UpdatedOn = time.Now()
+	pullRequest.UpdatedBy = gitOpsPullRequestSystemUserId
+	err = impl.gitOpsPullRequestRepository.Update(pullRequest)
+	if err != nil {
+		impl.logger.Errorw("error in updating gitops pull request", "pullRequest", pullRequest, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *GitOpsPullRequestServiceImpl) handleNotMerged(pullRequest *pipelineConfig.GitOpsPullRequest, status pipelineConfig.GitOpsPullRequestStatus, message string, closePullRequest bool) error {
+	claimed, err := impl.gitOpsPullRequestRepository.UpdateStatus(pullRequest.Id, pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN, status, gitOpsPullRequestSystemUserId)
+	if err != nil {
+		impl.logger.Errorw("error in updating gitops pull request", "pullRequest", pullRequest, "err", err)
+		return err
+	}
+	if !claimed {
+		impl.logger.Infow("gitops pull request already processed by another instance", "pullRequestId", pullRequest.Id)
+		return nil
+	}
+	if closePullRequest {
+		err = impl.gitFactory.Client.ClosePullRequest(pullRequest.RepoName, pullRequest.PrNumber)
+		if err != nil {
+			impl.logger.Errorw("error in closing pull request on git provider", "repo", pullRequest.RepoName, "number", pullRequest.PrNumber, "err", err)
+			impl.releaseClaim(pullRequest, status)
+			return err
+		}
+	}
+	wfr, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(pullRequest.CdWorkflowRunnerId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd workflow runner", "wfrId", pullRequest.CdWorkflowRunnerId, "err", err)
+		return err
+	}
+	wfr.Status = WorkflowFailed
+	wfr.Message = message
+	wfr.FinishedOn = time.Now()
+	err = impl.cdWorkflowRepository.UpdateWorkFlowRunner(wfr)
+	if err != nil {
+		impl.logger.Errorw("error in updating cd workflow runner", "wfrId", wfr.Id, "err", err)
+		return err
+	}
+	impl.saveTimeline(pullRequest.CdWorkflowRunnerId, pipelineConfig.TIMELINE_STATUS_GIT_COMMIT_FAILED, message)
+	return nil
+}
+
+// releaseClaim opens the pull request again so that it is picked up on the next sync
+func (impl *GitOpsPullRequestServiceImpl) releaseClaim(pullRequest *pipelineConfig.GitOpsPullRequest, claimedStatus pipelineConfig.GitOpsPullRequestStatus) {
+	_, err := impl.gitOpsPullRequestRepository.UpdateStatus(pullRequest.Id, claimedStatus, pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN, gitOpsPullRequestSystemUserId)
+	if err != nil {
+		impl.logger.Errorw("error in releasing gitops pull request claim", "pullRequestId", pullRequest.Id, "err", err)
+	}
+}
+
+func (impl *GitOpsPullRequestServiceImpl) saveTimeline(wfrId int, status pipelineConfig.TimelineStatus, statusDetail string) {
+	timeline := &pipelineConfig.PipelineStatusTimeline{
+		CdWorkflowRunnerId: wfrId,
+		Status:             status,
+		StatusDetail:       statusDetail,
+		StatusTime:         time.Now(),
+		AuditLog: sql.AuditLog{
+			CreatedBy: gitOpsPullRequestSystemUserId,
+			CreatedOn: time.Now(),
+			UpdatedBy: gitOpsPullRequestSystemUserId,
+			UpdatedOn: time.Now(),
+		},
+	}
+	err := impl.pipelineStatusTimelineRepository.SaveTimeline(timeline)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for gitops pull request", "err", err, "timeline", timeline)
+	}
+}
@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"go.uber.org/zap"
+)
+
+func TestEvaluatePullRequest(t *testing.T) {
+	now := time.Date(2022, 12, 19, 10, 0, 0, 0, time.UTC)
+	timeout := 60 * time.Minute
+	pullRequest := &pipelineConfig.GitOpsPullRequest{
+		Id:       5,
+		PrUrl:    "https://github.com/org/repo/pull/7",
+		AuditLog: sql.AuditLog{CreatedOn: now.Add(-30 * time.Minute)},
+	}
+	expiredPullRequest := &pipelineConfig.GitOpsPullRequest{
+		Id:       5,
+		PrUrl:    "https://github.com/org/repo/pull/7",
+		AuditLog: sql.AuditLog{CreatedOn: now.Add(-90 * time.Minute)},
+	}
+
+	tests := []struct {
+		name                string
+		pullRequest         *pipelineConfig.GitOpsPullRequest
+		state               string
+		latestPullRequestId int
+		status              pipelineConfig.GitOpsPullRequestStatus
+	}{
+		{name: "open within timeout", pullRequest: pullRequest, state: util.PULL_REQUEST_STATE_OPEN, latestPullRequestId: 5, status: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN},
+		{name: "merged", pullRequest: pullRequest, state: util.PULL_REQUEST_STATE_MERGED, latestPullRequestId: 5, status: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED},
+		{name: "merged after a later pull request", pullRequest: pullRequest, state: util.PULL_REQUEST_STATE_MERGED, latestPullRequestId: 8, status: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED},
+		{name: "merged after timeout", pullRequest: expiredPullRequest, state: util.PULL_REQUEST_STATE_MERGED, latestPullRequestId: 5, status: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED},
+		{name: "closed without merge", pullRequest: pullRequest, state: util.PULL_REQUEST_STATE_CLOSED, latestPullRequestId: 5, status: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_CLOSED},
+		{name: "superseded by a later pull request", pullRequest: pullRequest, state: util.PULL_REQUEST_STATE_OPEN, latestPullRequestId: 8, status: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_SUPERSEDED},
+		{name: "timed out", pullRequest: expiredPullRequest, state: util.PULL_REQUEST_STATE_OPEN, latestPullRequestId: 5, status: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_TIMED_OUT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := evaluatePullRequest(tt.pullRequest, tt.state, tt.latestPullRequestId, timeout, now)
+			if status != tt.status {
+				t.Errorf("status = %v, want %v", status, tt.status)
+			}
+			failed := status != pipelineConfig.GITOPS_PULL_REQUEST_STATUS_OPEN && status != pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED
+			if failed != (len(message) > 0) {
+				t.Errorf("message = %q for status %v", message, status)
+			}
+		})
+	}
+}
+
+func TestGetLatestPullRequestIds(t *testing.T) {
+	pullRequests := []*pipelineConfig.GitOpsPullRequest{
+		{Id: 1, PipelineId: 10},
+		{Id: 4, PipelineId: 20},
+		{Id: 3, PipelineId: 10},
+		{Id: 2, PipelineId: 20},
+	}
+	latestPullRequestIds := getLatestPullRequestIds(pullRequests)
+	if len(latestPullRequestIds) != 2 || latestPullRequestIds[10] != 3 || latestPullRequestIds[20] != 4 {
+		t.Errorf("latest pull request ids = %v, want map[10:3 20:4]", latestPullRequestIds)
+	}
+}
+
+// fakeGitOpsPullRequestRepository keeps the status of pull requests in memory
+type fakeGitOpsPullRequestRepository struct {
+	pipelineConfig.GitOpsPullRequestRepository
+	statuses map[int]pipelineConfig.GitOpsPullRequestStatus
+}
+
+func (impl *fakeGitOpsPullRequestRepository) UpdateStatus(id int, currentStatus pipelineConfig.GitOpsPullRequestStatus, status pipelineConfig.GitOpsPullRequestStatus, updatedBy int32) (bool, error) {
+	if impl.statuses[id] != currentStatus {
+		return false, nil
+	}
+	impl.statuses[id] = status
+	return true, nil
+}
+
+func TestHandleMergedClaimsPullRequest(t *testing.T) {
+	repository := &fakeGitOpsPullRequestRepository{statuses: map[int]pipelineConfig.GitOpsPullRequestStatus{
+		5: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGING,
+		6: pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED,
+	}}
+	// the pipeline override repository and the app service are left nil, deploying would panic
+	impl := &GitOpsPullRequestServiceImpl{logger: zap.NewNop().Sugar(), gitOpsPullRequestRepository: repository}
+	pr := &util.PullRequestDto{MergeCommitHash: "abc"}
+	for _, id := range []int{5, 6} {
+		err := impl.handleMerged(&pipelineConfig.GitOpsPullRequest{Id: id}, pr)
+		if err != nil {
+			t.Errorf("pull request %d: unexpected error: %v", id, err)
+		}
+	}
+	if repository.statuses[5] != pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGING || repository.statuses[6] != pipelineConfig.GITOPS_PULL_REQUEST_STATUS_MERGED {
+		t.Errorf("statuses changed by an instance without the claim: %v", repository.statuses)
+	}
+}
@@ -0,0 +1,608 @@
+package pipeline
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/security"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	util3 "github.com/devtron-labs/devtron/util"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	cosignSignatureType          = "cosign container image signature"
+	imageSigningSecretKeyPrefix  = "image-signing-key-"
+	imageSigningKeyNameMaxLength = 100
+)
+
+var imageSigningKeyNameRegex = regexp.MustCompile("^[a-z0-9]([-a-z0-9]*[a-z0-9])?$")
+
+type ImageSignatureService interface {
+	GetKeys() ([]*ImageSigningKeyDto, error)
+	CreateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error)
+	UpdateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error)
+	DeleteKey(id int, userId int32) error
+
+	GetPolicies() ([]*ImageSignaturePolicyDto, error)
+	SavePolicy(request *ImageSignaturePolicyDto) (*ImageSignaturePolicyDto, error)
+	DeletePolicy(id int, userId int32) error
+
+	// SignArtifact signs the image digest of the artifact with the key configured for signing ci artifacts,
+	// artifact is left unsigned if no such key exists
+	SignArtifact(artifact *repository.CiArtifact) error
+	// VerifyArtifact checks the artifact against the signature policy of the environment, verification only uses
+	// the signature stored on the artifact and trusted public keys so no registry access is required
+	VerifyArtifact(artifact *repository.CiArtifact, envId int) (*ImageSignatureVerification, error)
+}
+
+type ImageSignatureServiceImpl struct {
+	logger                   *zap.SugaredLogger
+	imageSignatureRepository security.ImageSignatureRepository
+	environmentRepository    repository2.EnvironmentRepository
+	K8sUtil                  *util.K8sUtil
+	devtronSecretConfig      *util3.DevtronSecretConfig
+}
+
+func NewImageSignatureServiceImpl(logger *zap.SugaredLogger,
+	imageSignatureRepository security.ImageSignatureRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	K8sUtil *util.K8sUtil,
+	devtronSecretConfig *util3.DevtronSecretConfig) *ImageSignatureServiceImpl {
+	return &ImageSignatureServiceImpl{
+		logger:                   logger,
+		imageSignatureRepository: imageSignatureRepository,
+		environmentRepository:    environmentRepository,
+		K8sUtil:                  K8sUtil,
+		devtronSecretConfig:      devtronSecretConfig,
+	}
+}
+
+// ImageSigningKeyDto with Generate set creates a new key pair, otherwise PrivateKey or PublicKey has to be given in pem format
+type ImageSigningKeyDto struct {
+	Id              int    `json:"id"`
+	Name            string `json:"name" validate:"required"`
+	Generate        bool   `json:"generate,omitempty"`
+	PublicKey       string `json:"publicKey"`
+	PrivateKey      string `json:"privateKey,omitempty"`
+	Fingerprint     string `json:"fingerprint"`
+	HasPrivateKey   bool   `json:"hasPrivateKey"`
+	SignCiArtifacts bool   `json:"signCiArtifacts"`
+	UserId          int32  `json:"-"`
+}
+
+type ImageSignaturePolicyDto struct {
+	Id            int   `json:"id"`
+	ClusterId     int   `json:"clusterId"`
+	EnvironmentId int   `json:"environmentId"`
+	Enforce       bool  `json:"enforce"`
+	TrustedKeyIds []int `json:"trustedKeyIds"`
+	UserId        int32 `json:"-"`
+}
+
+type ImageSignatureVerification struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// simpleSigningPayload is the payload signed by cosign for an image, keeping the same layout lets signatures
+// created by cosign in external ci be verified with the cosign public key
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+func (impl *ImageSignatureServiceImpl) GetKeys() ([]*ImageSigningKeyDto, error) {
+	keys, err := impl.imageSignatureRepository.FindActiveKeys()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting image signing keys", "err", err)
+		return nil, err
+	}
+	keyDtos := make([]*ImageSigningKeyDto, 0, len(keys))
+	for _, key := range keys {
+		keyDtos = append(keyDtos, adaptImageSigningKey(key))
+	}
+	return keyDtos, nil
+}
+
+func (impl *ImageSignatureServiceImpl) CreateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error) {
+	if len(request.Name) > imageSigningKeyNameMaxLength || !imageSigningKeyNameRegex.MatchString(request.Name) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid key name " + request.Name, UserMessage: "key name must consist of lower case alphanumeric characters or '-'"}
+	}
+	_, err := impl.imageSignatureRepository.FindActiveKeyByName(request.Name)
+	if err == nil {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "key already exists " + request.Name, UserMessage: "a signing key with this name already exists"}
+	} else if err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting image signing key", "err", err, "name", request.Name)
+		return nil, err
+	}
+
+	var privateKey crypto.Signer
+	var publicKey crypto.PublicKey
+	if request.Generate {
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			impl.logger.Errorw("error in generating image signing key", "err", err)
+			return nil, err
+		}
+		publicKey = privateKey.Public()
+	} else if len(request.PrivateKey) > 0 {
+		privateKey, err = parseImageSigningPrivateKey(request.PrivateKey)
+		if err != nil {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: "invalid private key, an unencrypted pem encoded ecdsa, rsa or ed25519 key is required"}
+		}
+		publicKey = privateKey.Public()
+	} else {
+		publicKey, err = parseImageSigningPublicKey(request.PublicKey)
+		if err != nil {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: "invalid public key, a pem encoded ecdsa, rsa or ed25519 key is required"}
+		}
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling public key", "err", err)
+		return nil, err
+	}
+	fingerprint := sha256.Sum256(publicKeyDer)
+	key := &security.ImageSigningKey{
+		Name:          request.Name,
+		PublicKey:     string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+		Fingerprint:   hex.EncodeToString(fingerprint[:]),
+		HasPrivateKey: privateKey != nil,
+		Active:        true,
+		AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	}
+	if privateKey != nil {
+		privateKeyDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			impl.logger.Errorw("error in marshaling private key", "err", err)
+			return nil, err
+		}
+		err = impl.updatePrivateKeyInSecret(key.Fingerprint, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDer}))
+		if err != nil {
+			impl.logger.Errorw("error in storing image signing private key", "err", err, "name", request.Name)
+			return nil, err
+		}
+	}
+	err = impl.imageSignatureRepository.SaveKey(key)
+	if err != nil {
+		impl.logger.Errorw("error in saving image signing key", "err", err, "name", request.Name)
+		return nil, err
+	}
+	if request.SignCiArtifacts {
+		request.Id = key.Id
+		return impl.UpdateKey(request)
+	}
+	return adaptImageSigningKey(key), nil
+}
+
+// UpdateKey only changes which key signs ci artifacts, key material can not be changed once created
+func (impl *ImageSignatureServiceImpl) UpdateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error) {
+	key, err := impl.imageSignatureRepository.FindActiveKeyById(request.Id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "key not found", UserMessage: "signing key not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting image signing key", "err", err, "id", request.Id)
+		return nil, err
+	}
+	if request.SignCiArtifacts && !key.HasPrivateKey {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "key has no private key", UserMessage: "only keys with a private key can sign ci artifacts"}
+	}
+	if request.SignCiArtifacts {
+		current, err := impl.imageSignatureRepository.FindCiArtifactSigningKey()
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting ci artifact signing key", "err", err)
+			return nil, err
+		}
+		if err == nil && current.Id != key.Id {
+			current.SignCiArtifacts = false
+			current.UpdatedOn = time.Now()
+			current.UpdatedBy = request.UserId
+			err = impl.imageSignatureRepository.UpdateKey(current)
+			if err != nil {
+				impl.logger.Errorw("error in updating image signing key", "err", err, "id", current.Id)
+				return nil, err
+			}
+		}
+	}
+	key.SignCiArtifacts = request.SignCiArtifacts
+	key.UpdatedOn = time.Now()
+	key.UpdatedBy = request.UserId
+	err = impl.imageSignatureRepository.UpdateKey(key)
+	if err != nil {
+		impl.logger.Errorw("error in updating image signing key", "err", err, "id", key.Id)
+		return nil, err
+	}
+	return adaptImageSigningKey(key), nil
+}
+
+func (impl *ImageSignatureServiceImpl) DeleteKey(id int, userId int32) error {
+	key, err := impl.imageSignatureRepository.FindActiveKeyById(id)
+	if err == pg.ErrNoRows {
+		return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "key not found", UserMessage: "signing key not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting image signing key", "err", err, "id", id)
+		return err
+	}
+	if key.HasPrivateKey {
+		err = impl.updatePrivateKeyInSecret(key.Fingerprint, nil)
+		if err != nil {
+			impl.logger.Errorw("error in removing image signing private key", "err", err, "id", id)
+			return err
+		}
+	}
+	key.Active = false
+	key.SignCiArtifacts = false
+	key.UpdatedOn = time.Now()
+	key.UpdatedBy = userId
+	err = impl.imageSignatureRepository.UpdateKey(key)
+	if err != nil {
+		impl.logger.Errorw("error in deleting image signing key", "err", err, "id", id)
+	}
+	return err
+}
+
+func (impl *ImageSignatureServiceImpl) GetPolicies() ([]*ImageSignaturePolicyDto, error) {
+	policies, err := impl.imageSignatureRepository.FindActivePolicies()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting image signature policies", "err", err)
+		return nil, err
+	}
+	policyDtos := make([]*ImageSignaturePolicyDto, 0, len(policies))
+	for _, policy := range policies {
+		policyDtos = append(policyDtos, adaptImageSignaturePolicy(policy))
+	}
+	return policyDtos, nil
+}
+
+func (impl *ImageSignatureServiceImpl) SavePolicy(request *ImageSignaturePolicyDto) (*ImageSignaturePolicyDto, error) {
+	if (request.ClusterId == 0) == (request.EnvironmentId == 0) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid policy scope", UserMessage: "policy must be scoped to either a cluster or an environment"}
+	}
+	if request.Enforce {
+		keys, err := impl.imageSignatureRepository.FindActiveKeysByIds(request.TrustedKeyIds)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting trusted keys", "err", err, "keyIds", request.TrustedKeyIds)
+			return nil, err
+		}
+		if len(keys) == 0 || len(keys) != len(request.TrustedKeyIds) {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid trusted keys", UserMessage: "an enforced policy needs at least one trusted key, and all trusted keys must exist"}
+		}
+	}
+	policy, err := impl.imageSignatureRepository.FindActivePolicyByScope(request.ClusterId, request.EnvironmentId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting image signature policy", "err", err, "clusterId", request.ClusterId, "envId", request.EnvironmentId)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		policy = &security.ImageSignaturePolicy{
+			ClusterId:     request.ClusterId,
+			EnvironmentId: request.EnvironmentId,
+			Active:        true,
+			AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId},
+		}
+	}
+	policy.Enforce = request.Enforce
+	policy.TrustedKeyIds = request.TrustedKeyIds
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = request.UserId
+	if policy.Id == 0 {
+		err = impl.imageSignatureRepository.SavePolicy(policy)
+	} else {
+		err = impl.imageSignatureRepository.UpdatePolicy(policy)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving image signature policy", "err", err, "policy", policy)
+		return nil, err
+	}
+	return adaptImageSignaturePolicy(policy), nil
+}
+
+func (impl *ImageSignatureServiceImpl) DeletePolicy(id int, userId int32) error {
+	policy, err := impl.imageSignatureRepository.FindActivePolicyById(id)
+	if err == pg.ErrNoRows {
+		return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "policy not found", UserMessage: "image signature policy not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting image signature policy", "err", err, "id", id)
+		return err
+	}
+	policy.Active = false
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = userId
+	err = impl.imageSignatureRepository.UpdatePolicy(policy)
+	if err != nil {
+		impl.logger.Errorw("error in deleting image signature policy", "err", err, "id", id)
+	}
+	return err
+}
+
+func (impl *ImageSignatureServiceImpl) SignArtifact(artifact *repository.CiArtifact) error {
+	if len(artifact.ImageDigest) == 0 || len(artifact.Signature) > 0 {
+		return nil
+	}
+	key, err := impl.imageSignatureRepository.FindCiArtifactSigningKey()
+	if err == pg.ErrNoRows {
+		return nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting ci artifact signing key", "err", err)
+		return err
+	}
+	privateKey, err := impl.getPrivateKeyFromSecret(key.Fingerprint)
+	if err != nil {
+		impl.logger.Errorw("error in getting image signing private key", "err", err, "keyId", key.Id)
+		return err
+	}
+	payload := &simpleSigningPayload{}
+	payload.Critical.Identity.DockerReference = getImageRepository(artifact.Image)
+	payload.Critical.Image.DockerManifestDigest = artifact.ImageDigest
+	payload.Critical.Type = cosignSignatureType
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	signature, err := signImagePayload(privateKey, payloadBytes)
+	if err != nil {
+		impl.logger.Errorw("error in signing image", "err", err, "image", artifact.Image)
+		return err
+	}
+	artifact.Signature = base64.StdEncoding.EncodeToString(signature)
+	artifact.SignaturePayload = string(payloadBytes)
+	artifact.SigningKeyFingerprint = key.Fingerprint
+	artifact.SignedOn = time.Now()
+	return nil
+}
+
+func (impl *ImageSignatureServiceImpl) VerifyArtifact(artifact *repository.CiArtifact, envId int) (*ImageSignatureVerification, error) {
+	env, err := impl.environmentRepository.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environment", "err", err, "envId", envId)
+		return nil, err
+	}
+	policies, err := impl.imageSignatureRepository.FindActivePoliciesForEnv(env.ClusterId, env.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting image signature policies", "err", err, "envId", env.Id)
+		return nil, err
+	}
+	var policy *security.ImageSignaturePolicy
+	for _, p := range policies {
+		if p.EnvironmentId == env.Id || policy == nil {
+			policy = p
+		}
+	}
+	if policy == nil || !policy.Enforce {
+		return &ImageSignatureVerification{Allowed: true}, nil
+	}
+	keys, err := impl.imageSignatureRepository.FindActiveKeysByIds(policy.TrustedKeyIds)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting trusted keys", "err", err, "policyId", policy.Id)
+		return nil, err
+	}
+	verification := verifyArtifactSignature(artifact, keys)
+	if !verification.Allowed {
+		impl.logger.Infow("image signature verification failed", "artifactId", artifact.Id, "envId", env.Id, "reason", verification.Reason)
+	}
+	return verification, nil
+}
+
+// verifyArtifactSignature allows the artifact only if its signature payload names the deployed image and digest and
+// the signature is made by one of the trusted keys, a valid signature of another image must not be reusable
+func verifyArtifactSignature(artifact *repository.CiArtifact, trustedKeys []*security.ImageSigningKey) *ImageSignatureVerification {
+	if len(artifact.Signature) == 0 || len(artifact.SignaturePayload) == 0 {
+		return &ImageSignatureVerification{Reason: "image is not signed"}
+	}
+	payload := &simpleSigningPayload{}
+	err := json.Unmarshal([]byte(artifact.SignaturePayload), payload)
+	if err != nil || len(artifact.ImageDigest) == 0 || payload.Critical.Image.DockerManifestDigest != artifact.ImageDigest {
+		return &ImageSignatureVerification{Reason: "signature does not belong to image digest " + artifact.ImageDigest}
+	}
+	if !isSignedImageReference(payload.Critical.Identity.DockerReference, artifact.Image) {
+		return &ImageSignatureVerification{Reason: "signature does not belong to image " + artifact.Image}
+	}
+	signature, err := base64.StdEncoding.DecodeString(artifact.Signature)
+	if err != nil {
+		return &ImageSignatureVerification{Reason: "image signature is malformed"}
+	}
+	for _, key := range trustedKeys {
+		publicKey, err := parseImageSigningPublicKey(key.PublicKey)
+		if err != nil {
+			continue
+		}
+		if verifyImagePayload(publicKey, []byte(artifact.SignaturePayload), signature) {
+			return &ImageSignatureVerification{Allowed: true, Reason: "signed by trusted key " + key.Name}
+		}
+	}
+	return &ImageSignatureVerification{Reason: "image is signed by an untrusted key"}
+}
+
+// updatePrivateKeyInSecret stores the private key against the key fingerprint in devtron secret, nil key removes it
+func (impl *ImageSignatureServiceImpl) updatePrivateKeyInSecret(fingerprint string, privateKeyPem []byte) error {
+	k8sClient, err := impl.K8sUtil.GetClientForInCluster()
+	if err != nil {
+		impl.logger.Errorw("exception in fetching client", "error", err)
+		return err
+	}
+	updateSuccess := false
+	retryCount := 0
+	for !updateSuccess && retryCount < 3 {
+		retryCount = retryCount + 1
+		secret, err := impl.K8sUtil.GetSecret(argo.DEVTRONCD_NAMESPACE, impl.devtronSecretConfig.DevtronSecretName, k8sClient)
+		if err != nil {
+			impl.logger.Errorw("exception in fetching devtron secret", "error", err)
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		if privateKeyPem == nil {
+			delete(secret.Data, imageSigningSecretKeyPrefix+fingerprint)
+		} else {
+			secret.Data[imageSigningSecretKeyPrefix+fingerprint] = privateKeyPem
+		}
+		_, err = impl.K8sUtil.UpdateSecret(argo.DEVTRONCD_NAMESPACE, secret, k8sClient)
+		if err != nil {
+			impl.logger.Warnw("devtron secret update failed for image signing key", "err", err)
+			continue
+		}
+		updateSuccess = true
+	}
+	if !updateSuccess {
+		return fmt.Errorf("resource version not matched with devtron secret attempted 3 times")
+	}
+	return nil
+}
+
+func (impl *ImageSignatureServiceImpl) getPrivateKeyFromSecret(fingerprint string) (crypto.Signer, error) {
+	k8sClient, err := impl.K8sUtil.GetClientForInCluster()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := impl.K8sUtil.GetSecret(argo.DEVTRONCD_NAMESPACE, impl.devtronSecretConfig.DevtronSecretName, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	privateKeyPem, ok := secret.Data[imageSigningSecretKeyPrefix+fingerprint]
+	if !ok {
+		return nil, fmt.Errorf("private key not found in devtron secret for key %s", fingerprint)
+	}
+	return parseImageSigningPrivateKey(string(privateKeyPem))
+}
+
+func adaptImageSigningKey(key *security.ImageSigningKey) *ImageSigningKeyDto {
+	return &ImageSigningKeyDto{
+		Id:              key.Id,
+		Name:            key.Name,
+		PublicKey:       key.PublicKey,
+		Fingerprint:     key.Fingerprint,
+		HasPrivateKey:   key.HasPrivateKey,
+		SignCiArtifacts: key.SignCiArtifacts,
+	}
+}
+
+func adaptImageSignaturePolicy(policy *security.ImageSignaturePolicy) *ImageSignaturePolicyDto {
+	trustedKeyIds := policy.TrustedKeyIds
+	if trustedKeyIds == nil {
+		trustedKeyIds = []int{}
+	}
+	return &ImageSignaturePolicyDto{
+		Id:            policy.Id,
+		ClusterId:     policy.ClusterId,
+		EnvironmentId: policy.EnvironmentId,
+		Enforce:       policy.Enforce,
+		TrustedKeyIds: trustedKeyIds,
+	}
+}
+
+func parseImageSigningPrivateKey(privateKeyPem string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privateKeyPem))
+	if block == nil {
+		return nil, fmt.Errorf("no pem block found in private key")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("unsupported private key pem type %s", block.Type)
+}
+
+func parseImageSigningPublicKey(publicKeyPem string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return nil, fmt.Errorf("no pem block found in public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", key)
+}
+
+func signImagePayload(privateKey crypto.Signer, payload []byte) ([]byte, error) {
+	if _, ok := privateKey.(ed25519.PrivateKey); ok {
+		return privateKey.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(payload)
+	return privateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func verifyImagePayload(publicKey crypto.PublicKey, payload []byte, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	}
+	return false
+}
+
+// isSignedImageReference matches the docker reference of a signature with the image, cosign signs the repository
+// while a reference with a tag has to name the same tag
+func isSignedImageReference(dockerReference string, image string) bool {
+	if len(dockerReference) == 0 {
+		return false
+	}
+	if dockerReference == getImageRepository(dockerReference) {
+		return dockerReference == getImageRepository(image)
+	}
+	if index := strings.Index(image, "@"); index >= 0 {
+		image = image[:index]
+	}
+	return dockerReference == image
+}
+
+// getImageRepository strips the tag and digest from an image reference, a registry port is kept as is
+func getImageRepository(image string) string {
+	if index := strings.Index(image, "@"); index >= 0 {
+		image = image[:index]
+	}
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		image = image[:index]
+	}
+	return image
+}
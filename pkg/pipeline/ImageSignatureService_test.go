@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/security"
+	"go.uber.org/zap"
+)
+
+const (
+	testSignedImage  = "registry.example.com:5000/team/app:v1"
+	testSignedDigest = "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+)
+
+// fakeImageSignatureService signs with an in memory key in place of the key stored in devtron secret
+type fakeImageSignatureService struct {
+	ImageSignatureService
+	privateKey crypto.Signer
+}
+
+func (impl *fakeImageSignatureService) SignArtifact(artifact *repository.CiArtifact) error {
+	artifact.Signature, artifact.SignaturePayload = signTestImage(impl.privateKey, getImageRepository(artifact.Image), artifact.ImageDigest)
+	return nil
+}
+
+func signTestImage(privateKey crypto.Signer, dockerReference string, digest string) (string, string) {
+	payload := &simpleSigningPayload{}
+	payload.Critical.Identity.DockerReference = dockerReference
+	payload.Critical.Image.DockerManifestDigest = digest
+	payload.Critical.Type = cosignSignatureType
+	payloadBytes, _ := json.Marshal(payload)
+	signature, _ := signImagePayload(privateKey, payloadBytes)
+	return base64.StdEncoding.EncodeToString(signature), string(payloadBytes)
+}
+
+func newTestSigningKey(t *testing.T, name string) (crypto.Signer, *security.ImageSigningKey) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return privateKey, &security.ImageSigningKey{
+		Name:      name,
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+	}
+}
+
+func TestVerifyArtifactSignature(t *testing.T) {
+	trustedPrivateKey, trustedKey := newTestSigningKey(t, "trusted")
+	untrustedPrivateKey, _ := newTestSigningKey(t, "untrusted")
+	trustedKeys := []*security.ImageSigningKey{trustedKey}
+
+	tests := []struct {
+		name            string
+		image           string
+		signingKey      crypto.Signer
+		dockerReference string
+		signedDigest    string
+		allowed         bool
+	}{
+		{name: "signed repository", image: testSignedImage, signingKey: trustedPrivateKey, dockerReference: "registry.example.com:5000/team/app", signedDigest: testSignedDigest, allowed: true},
+		{name: "signed tag", image: testSignedImage, signingKey: trustedPrivateKey, dockerReference: testSignedImage, signedDigest: testSignedDigest, allowed: true},
+		{name: "unsigned", image: testSignedImage},
+		{name: "untrusted key", image: testSignedImage, signingKey: untrustedPrivateKey, dockerReference: "registry.example.com:5000/team/app", signedDigest: testSignedDigest},
+		{name: "other digest", image: testSignedImage, signingKey: trustedPrivateKey, dockerReference: "registry.example.com:5000/team/app", signedDigest: "sha256:0000"},
+		{name: "other repository", image: testSignedImage, signingKey: trustedPrivateKey, dockerReference: "registry.example.com:5000/team/other", signedDigest: testSignedDigest},
+		{name: "other tag", image: testSignedImage, signingKey: trustedPrivateKey, dockerReference: "registry.example.com:5000/team/app:v2", signedDigest: testSignedDigest},
+		{name: "empty reference", image: testSignedImage, signingKey: trustedPrivateKey, signedDigest: testSignedDigest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := &repository.CiArtifact{Image: tt.image, ImageDigest: testSignedDigest}
+			if tt.signingKey != nil {
+				artifact.Signature, artifact.SignaturePayload = signTestImage(tt.signingKey, tt.dockerReference, tt.signedDigest)
+			}
+			verification := verifyArtifactSignature(artifact, trustedKeys)
+			if verification.Allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v, reason %s", verification.Allowed, tt.allowed, verification.Reason)
+			}
+		})
+	}
+}
+
+func TestApplyArtifactSignature(t *testing.T) {
+	trustedPrivateKey, trustedKey := newTestSigningKey(t, "trusted")
+	trustedKeys := []*security.ImageSigningKey{trustedKey}
+	impl := WebhookServiceImpl{
+		logger:                zap.NewNop().Sugar(),
+		imageSignatureService: &fakeImageSignatureService{privateKey: trustedPrivateKey},
+	}
+	externalSignature, externalPayload := signTestImage(trustedPrivateKey, "registry.example.com:5000/team/app", testSignedDigest)
+	// a signature taken from another image of the registry signed by the same key
+	otherSignature, otherPayload := signTestImage(trustedPrivateKey, "registry.example.com:5000/team/other", testSignedDigest)
+
+	tests := []struct {
+		name    string
+		request *CiArtifactWebhookRequest
+		allowed bool
+	}{
+		{name: "devtron ci build is signed", request: &CiArtifactWebhookRequest{DevtronCiBuild: true}, allowed: true},
+		{name: "external ci webhook is not signed", request: &CiArtifactWebhookRequest{}},
+		{name: "external ci webhook with signature", request: &CiArtifactWebhookRequest{Signature: externalSignature, SignaturePayload: externalPayload}, allowed: true},
+		{name: "external ci webhook with signature of other image", request: &CiArtifactWebhookRequest{Signature: otherSignature, SignaturePayload: otherPayload}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := &repository.CiArtifact{Image: testSignedImage, ImageDigest: testSignedDigest}
+			impl.applyArtifactSignature(artifact, tt.request)
+			verification := verifyArtifactSignature(artifact, trustedKeys)
+			if verification.Allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v, reason %s", verification.Allowed, tt.allowed, verification.Reason)
+			}
+		})
+	}
+}
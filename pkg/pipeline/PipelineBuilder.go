@@ -27,13 +27,16 @@ import (
 	"github.com/devtron-labs/devtron/pkg/chart"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline/history"
 	repository4 "github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	repository5 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user"
 	util3 "github.com/devtron-labs/devtron/pkg/util"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -53,6 +56,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/attributes"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
 	"github.com/devtron-labs/devtron/pkg/bean"
 	util2 "github.com/devtron-labs/devtron/util"
 	"github.com/go-pg/pg"
@@ -110,6 +114,7 @@ type PipelineBuilder interface {
 	GetEnvironmentByCdPipelineId(pipelineId int) (int, error)
 	PatchRegexCiPipeline(request *bean.CiRegexPatchRequest) (err error)
 	DeleteCiPipeline(request *bean.CiPatchRequest) (*bean.CiPipeline, error)
+	MigrateCdStageYamlToPipelineStages(appId int, userId int32) error
 }
 
 type PipelineBuilderImpl struct {
@@ -154,6 +159,9 @@ type PipelineBuilderImpl struct {
 	ciPipelineMaterialRepository     pipelineConfig.CiPipelineMaterialRepository
 	userService                      user.UserService
 	ciTemplateOverrideRepository     pipelineConfig.CiTemplateOverrideRepository
+	ciScheduleService                CiScheduleService
+	cdFanOutRepository               pipelineConfig.CdFanOutRepository
+	auditLogService                  auditLog.AuditLogService
 }
 
 func NewPipelineBuilderImpl(logger *zap.SugaredLogger,
@@ -192,7 +200,10 @@ func NewPipelineBuilderImpl(logger *zap.SugaredLogger,
 	deploymentGroupRepository repository.DeploymentGroupRepository,
 	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository,
 	userService user.UserService,
-	ciTemplateOverrideRepository pipelineConfig.CiTemplateOverrideRepository) *PipelineBuilderImpl {
+	ciTemplateOverrideRepository pipelineConfig.CiTemplateOverrideRepository,
+	ciScheduleService CiScheduleService,
+	cdFanOutRepository pipelineConfig.CdFanOutRepository,
+	auditLogService auditLog.AuditLogService) *PipelineBuilderImpl {
 	return &PipelineBuilderImpl{
 		logger:                           logger,
 		dbPipelineOrchestrator:           dbPipelineOrchestrator,
@@ -235,6 +246,9 @@ func NewPipelineBuilderImpl(logger *zap.SugaredLogger,
 		ciPipelineMaterialRepository:     ciPipelineMaterialRepository,
 		userService:                      userService,
 		ciTemplateOverrideRepository:     ciTemplateOverrideRepository,
+		ciScheduleService:                ciScheduleService,
+		cdFanOutRepository:               cdFanOutRepository,
+		auditLogService:                  auditLogService,
 	}
 }
 
@@ -250,13 +264,19 @@ func (impl PipelineBuilderImpl) CreateApp(request *bean.CreateAppDTO) (*bean.Cre
 	res, err := impl.dbPipelineOrchestrator.CreateApp(request)
 	if err != nil {
 		impl.logger.Errorw("error in saving create app req", "req", request, "err", err)
+		return res, err
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_APP, strconv.Itoa(res.Id), auditLog.AUDIT_ACTION_CREATE, nil, impl.getAppSnapshot(res.Id), request.UserId)
 	return res, err
 }
 
 func (impl PipelineBuilderImpl) DeleteApp(appId int, userId int32) error {
 	impl.logger.Debugw("app delete request received", "app", appId)
+	before := impl.getAppSnapshot(appId)
 	err := impl.dbPipelineOrchestrator.DeleteApp(appId, userId)
+	if err == nil {
+		impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_APP, strconv.Itoa(appId), auditLog.AUDIT_ACTION_DELETE, before, nil, userId)
+	}
 	return err
 }
 
@@ -566,6 +586,17 @@ func (impl PipelineBuilderImpl) GetCiPipeline(appId int) (ciConfig *bean.CiConfi
 		ciPipeline.LinkedCount = len(linkedCis)
 		ciPipelineResp = append(ciPipelineResp, ciPipeline)
 	}
+	var ciPipelineIds []int
+	for _, ciPipeline := range ciPipelineResp {
+		ciPipelineIds = append(ciPipelineIds, ciPipeline.Id)
+	}
+	schedules, err := impl.ciScheduleService.GetSchedules(ciPipelineIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, ciPipeline := range ciPipelineResp {
+		ciPipeline.Schedule = schedules[ciPipeline.Id]
+	}
 	ciConfig.CiPipelines = ciPipelineResp
 	//--------pipeline population end
 	return ciConfig, err
@@ -786,6 +817,9 @@ func (impl PipelineBuilderImpl) CreateCiPipeline(createRequest *bean.CiConfigReq
 			return nil, err
 		}
 		impl.logger.Debugw("pipeline created ", "detail", conf)
+		for _, ciPipeline := range conf.CiPipelines {
+			impl.saveCiPipelineAuditLog(ciPipeline.Id, auditLog.AUDIT_ACTION_CREATE, nil, createRequest.UserId)
+		}
 	}
 	createRes := &bean.PipelineCreateResponse{AppName: app.AppName, AppId: createRequest.AppId} //FIXME
 	return createRes, nil
@@ -917,20 +951,52 @@ func (impl PipelineBuilderImpl) PatchCiPipeline(request *bean.CiPatchRequest) (c
 	switch request.Action {
 	case bean.CREATE:
 		impl.logger.Debugw("create patch request")
+		err = impl.ciScheduleService.ValidateSchedule(request.CiPipeline)
+		if err != nil {
+			return nil, err
+		}
 		ciConfig.CiPipelines = []*bean.CiPipeline{request.CiPipeline} //request.CiPipeline
 		res, err := impl.addpipelineToTemplate(ciConfig)
 		if err != nil {
 			impl.logger.Errorw("error in adding pipeline to template", "ciConf", ciConfig, "err", err)
 			return nil, err
 		}
+		for _, ciPipeline := range res.CiPipelines {
+			err = impl.ciScheduleService.SaveSchedule(ciPipeline.Id, ciPipeline.Schedule, request.UserId)
+			if err != nil {
+				impl.logger.Errorw("error in saving ci pipeline schedule", "ciPipelineId", ciPipeline.Id, "err", err)
+				return nil, err
+			}
+			impl.saveCiPipelineAuditLog(ciPipeline.Id, auditLog.AUDIT_ACTION_CREATE, nil, request.UserId)
+		}
 		return res, nil
 	case bean.UPDATE_SOURCE:
-		return impl.patchCiPipelineUpdateSource(ciConfig, request.CiPipeline)
+		err = impl.ciScheduleService.ValidateSchedule(request.CiPipeline)
+		if err != nil {
+			return nil, err
+		}
+		before := impl.getCiPipelineSnapshot(request.CiPipeline.Id)
+		res, err := impl.patchCiPipelineUpdateSource(ciConfig, request.CiPipeline)
+		if err != nil {
+			return nil, err
+		}
+		err = impl.ciScheduleService.SaveSchedule(request.CiPipeline.Id, request.CiPipeline.Schedule, request.UserId)
+		if err != nil {
+			impl.logger.Errorw("error in saving ci pipeline schedule", "ciPipelineId", request.CiPipeline.Id, "err", err)
+			return nil, err
+		}
+		impl.saveCiPipelineAuditLog(request.CiPipeline.Id, auditLog.AUDIT_ACTION_UPDATE, before, request.UserId)
+		return res, nil
 	case bean.DELETE:
 		pipeline, err := impl.DeleteCiPipeline(request)
 		if err != nil {
 			return nil, err
 		}
+		err = impl.ciScheduleService.DeleteSchedule(pipeline.Id, request.UserId)
+		if err != nil {
+			impl.logger.Errorw("error in deleting ci pipeline schedule", "ciPipelineId", pipeline.Id, "err", err)
+			return nil, err
+		}
 		ciConfig.CiPipelines = []*bean.CiPipeline{pipeline}
 		return ciConfig, nil
 	default:
@@ -1005,6 +1071,7 @@ func (impl PipelineBuilderImpl) DeleteCiPipeline(request *bean.CiPatchRequest) (
 			UserMessage:       fmt.Sprintf("cd pipeline exists for this CI")}
 	}
 
+	before := impl.getCiPipelineSnapshot(ciPipelineId)
 	pipeline, err := impl.ciPipelineRepository.FindById(ciPipelineId)
 	if err != nil {
 		impl.logger.Errorw("pipeline fetch err", "id", ciPipelineId, "err", err)
@@ -1059,6 +1126,7 @@ func (impl PipelineBuilderImpl) DeleteCiPipeline(request *bean.CiPatchRequest) (
 	}
 	request.CiPipeline.Deleted = true
 	request.CiPipeline.Name = pipeline.Name
+	impl.saveCiPipelineAuditLog(ciPipelineId, auditLog.AUDIT_ACTION_DELETE, before, request.UserId)
 	return request.CiPipeline, nil
 	//delete pipeline
 	//delete scm
@@ -1137,6 +1205,19 @@ func (impl PipelineBuilderImpl) CreateCdPipelines(pipelineCreateRequest *bean.Cd
 			}
 			return nil, err
 		}
+		fanOutTargets, fErr := impl.cdFanOutRepository.FindActiveTargetsByAppIdAndEnvId(pipelineCreateRequest.AppId, pipeline.EnvironmentId)
+		if fErr != nil && fErr != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching fan-out targets", "err", fErr, "appId", pipelineCreateRequest.AppId, "envId", pipeline.EnvironmentId)
+			return nil, fErr
+		}
+		if len(fanOutTargets) > 0 {
+			err = &util.ApiError{
+				HttpStatusCode:  http.StatusBadRequest,
+				InternalMessage: "env is a fan-out target of a cd-pipeline of this app",
+				UserMessage:     "env is deployed as a fan-out target of another cd-pipeline of this app, remove it from fan-out first",
+			}
+			return nil, err
+		}
 
 		if len(pipeline.PreStage.Config) > 0 && !strings.Contains(pipeline.PreStage.Config, "beforeStages") {
 			err = &util.ApiError{
@@ -1154,6 +1235,10 @@ func (impl PipelineBuilderImpl) CreateCdPipelines(pipelineCreateRequest *bean.Cd
 			}
 			return nil, err
 		}
+		err = validateCdPipelineStageConfig(pipeline)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if isGitOpsConfigured {
@@ -1162,7 +1247,16 @@ func (impl PipelineBuilderImpl) CreateCdPipelines(pipelineCreateRequest *bean.Cd
 		if err != nil && pg.ErrNoRows != err {
 			return nil, err
 		}
-		gitOpsRepoName := impl.chartTemplateService.GetGitOpsRepoName(app.AppName)
+		appWithTeam, err := impl.appRepo.FindAppAndProjectByAppId(app.Id)
+		if err != nil {
+			impl.logger.Errorw("error in fetching app and project", "appId", app.Id, "err", err)
+			return nil, err
+		}
+		gitOpsRepoName, err := impl.chartTemplateService.GetGitOpsRepoNameForApp(app.AppName, appWithTeam.Team.Name)
+		if err != nil {
+			impl.logger.Errorw("error in getting gitops repo name", "appId", app.Id, "err", err)
+			return nil, err
+		}
 		chartGitAttr, err := impl.chartTemplateService.CreateGitRepositoryForApp(gitOpsRepoName, chart.ReferenceTemplate, chart.ChartVersion, pipelineCreateRequest.UserId)
 		if err != nil {
 			impl.logger.Errorw("error in pushing chart to git ", "path", chartGitAttr.ChartLocation, "err", err)
@@ -1210,6 +1304,7 @@ func (impl PipelineBuilderImpl) CreateCdPipelines(pipelineCreateRequest *bean.Cd
 			return nil, err
 		}
 		pipeline.Id = id
+		impl.saveCdPipelineAuditLog(id, auditLog.AUDIT_ACTION_CREATE, nil, pipelineCreateRequest.UserId)
 	}
 
 	return pipelineCreateRequest, nil
@@ -1225,7 +1320,11 @@ func (impl PipelineBuilderImpl) PatchCdPipelines(cdPipelines *bean.CDPatchReques
 	case bean.CD_CREATE:
 		return impl.CreateCdPipelines(pipelineRequest, ctx)
 	case bean.CD_UPDATE:
+		before := impl.getCdPipelineSnapshot(cdPipelines.Pipeline.Id)
 		err := impl.updateCdPipeline(ctx, cdPipelines.Pipeline, cdPipelines.UserId)
+		if err == nil {
+			impl.saveCdPipelineAuditLog(cdPipelines.Pipeline.Id, auditLog.AUDIT_ACTION_UPDATE, before, cdPipelines.UserId)
+		}
 		return pipelineRequest, err
 	case bean.CD_DELETE:
 		pipeline, err := impl.pipelineRepository.FindById(cdPipelines.Pipeline.Id)
@@ -1233,6 +1332,7 @@ func (impl PipelineBuilderImpl) PatchCdPipelines(cdPipelines *bean.CDPatchReques
 			impl.logger.Errorw("error in getting cd pipeline by id", "err", err, "id", cdPipelines.Pipeline.Id)
 			return pipelineRequest, err
 		}
+		pipeline.UpdatedBy = cdPipelines.UserId
 		err = impl.DeleteCdPipeline(pipeline, ctx, cdPipelines.ForceDelete)
 		return pipelineRequest, err
 	default:
@@ -1240,6 +1340,22 @@ func (impl PipelineBuilderImpl) PatchCdPipelines(cdPipelines *bean.CDPatchReques
 	}
 }
 
+// getSharedGitOpsEnvDir resolves <team>/<app>/<env> directory of the pipeline from the source of its argo cd app, as
+// the repo layout may have changed since the app was created. Empty dir is returned for apps in a repo per app
+func (impl PipelineBuilderImpl) getSharedGitOpsEnvDir(ctx context.Context, deploymentAppName string) (repoUrl string, envDir string, err error) {
+	acdApp, err := impl.application.Get(ctx, &application2.ApplicationQuery{Name: &deploymentAppName})
+	if err != nil {
+		impl.logger.Errorw("error in getting argo cd app", "app", deploymentAppName, "err", err)
+		return "", "", err
+	}
+	//chart is placed at <reference template>/<version>, within the env dir for shared repos
+	envDir = filepath.Dir(filepath.Dir(filepath.Clean(acdApp.Spec.Source.Path)))
+	if len(strings.Split(envDir, string(filepath.Separator))) != 3 || filepath.IsAbs(envDir) {
+		return "", "", nil
+	}
+	return acdApp.Spec.Source.RepoURL, envDir, nil
+}
+
 func (impl PipelineBuilderImpl) DeleteCdPipeline(pipeline *pipelineConfig.Pipeline, ctx context.Context, forceDelete bool) (err error) {
 	//getting children CD pipeline details
 	appWorkflowMapping, err := impl.appWorkflowRepository.FindWFCDMappingByParentCDPipelineId(pipeline.Id)
@@ -1250,6 +1366,14 @@ func (impl PipelineBuilderImpl) DeleteCdPipeline(pipeline *pipelineConfig.Pipeli
 		impl.logger.Debugw("cannot delete cd pipeline, contains children cd")
 		return fmt.Errorf("Please delete children CD pipelines before deleting this pipeline.")
 	}
+	fanOutConfig, err := impl.cdFanOutRepository.FindActiveConfigByPipelineId(pipeline.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting fan-out config", "err", err, "pipelineId", pipeline.Id)
+		return err
+	} else if fanOutConfig.Id > 0 {
+		impl.logger.Debugw("cannot delete cd pipeline, fan-out configured", "pipelineId", pipeline.Id)
+		return fmt.Errorf("Please remove fan-out environments before deleting this pipeline.")
+	}
 	//getting deployment group for this pipeline
 	deploymentGroupNames, err := impl.deploymentGroupRepository.GetNamesByAppIdAndEnvId(pipeline.EnvironmentId, pipeline.AppId)
 	if err != nil && err != pg.ErrNoRows {
@@ -1263,6 +1387,7 @@ func (impl PipelineBuilderImpl) DeleteCdPipeline(pipeline *pipelineConfig.Pipeli
 		impl.logger.Debugw("cannot delete cd pipeline, is being used in deployment group")
 		return fmt.Errorf("Please remove this CD pipeline from deployment groups : %s", string(groupNamesByte))
 	}
+	before := impl.getCdPipelineSnapshot(pipeline.Id)
 	dbConnection := impl.pipelineRepository.GetConnection()
 	tx, err := dbConnection.Begin()
 	if err != nil {
@@ -1281,6 +1406,21 @@ func (impl PipelineBuilderImpl) DeleteCdPipeline(pipeline *pipelineConfig.Pipeli
 		impl.logger.Errorw("error in deleting workflow mapping", "err", err)
 		return err
 	}
+	//delete pre/post cd stages, if any
+	preDeployStage, postDeployStage, err := impl.pipelineStageService.GetCdPipelineStageData(pipeline)
+	if err != nil {
+		impl.logger.Errorw("error in getting pre/post cd stages", "err", err, "cdPipelineId", pipeline.Id)
+		return err
+	}
+	for _, stage := range []*bean3.PipelineStageDto{preDeployStage, postDeployStage} {
+		if stage != nil && stage.Id > 0 {
+			err = impl.pipelineStageService.DeleteCdStage(stage, pipeline.UpdatedBy, tx)
+			if err != nil {
+				impl.logger.Errorw("error in deleting cd stage", "err", err, "stage", stage)
+				return err
+			}
+		}
+	}
 
 	if pipeline.PreStageConfig != "" {
 		err = impl.prePostCdScriptHistoryService.CreatePrePostCdScriptHistory(pipeline, tx, repository4.PRE_CD_TYPE, false, 0, time.Time{})
@@ -1300,6 +1440,11 @@ func (impl PipelineBuilderImpl) DeleteCdPipeline(pipeline *pipelineConfig.Pipeli
 	if pipeline.DeploymentAppCreated == true {
 		deploymentAppName := fmt.Sprintf("%s-%s", pipeline.App.AppName, pipeline.Environment.Name)
 		if util.IsAcdApp(pipeline.DeploymentAppType) {
+			//resolving the env dir before the argo cd app is deleted, a missing app is reported by the delete below
+			repoUrl, envDir, err := impl.getSharedGitOpsEnvDir(ctx, deploymentAppName)
+			if err != nil && !forceDelete && !strings.Contains(err.Error(), "code = NotFound") {
+				return err
+			}
 			//todo: provide option for cascading to user
 			cascadeDelete := true
 			req := &application2.ApplicationDeleteRequest{
@@ -1328,6 +1473,15 @@ func (impl PipelineBuilderImpl) DeleteCdPipeline(pipeline *pipelineConfig.Pipeli
 				}
 			}
 			impl.logger.Infow("app deleted from argocd", "id", pipeline.Id, "pipelineName", pipeline.Name, "app", deploymentAppName)
+			if len(envDir) > 0 {
+				err = impl.chartTemplateService.DeleteDirInGitRepo(repoUrl, envDir, pipeline.UpdatedBy)
+				if err != nil {
+					impl.logger.Errorw("error in deleting env dir from shared gitops repo", "pipelineId", pipeline.Id, "envDir", envDir, "err", err)
+					if !forceDelete {
+						return err
+					}
+				}
+			}
 		} else if util.IsHelmApp(pipeline.DeploymentAppType) {
 			appIdentifier := &client.AppIdentifier{
 				ClusterId:   pipeline.Environment.ClusterId,
@@ -1349,9 +1503,57 @@ func (impl PipelineBuilderImpl) DeleteCdPipeline(pipeline *pipelineConfig.Pipeli
 		impl.logger.Errorw("error in committing db transaction", "err", err)
 		return err
 	}
+	impl.saveCdPipelineAuditLog(pipeline.Id, auditLog.AUDIT_ACTION_DELETE, before, pipeline.UpdatedBy)
 	return nil
 }
 
+// getAppSnapshot reads the app as served by the api for the audit log, nil is returned if it can not be read so that
+// the change itself is not failed
+func (impl PipelineBuilderImpl) getAppSnapshot(appId int) interface{} {
+	appDto, err := impl.GetApp(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting app for audit log", "err", err, "appId", appId)
+		return nil
+	}
+	return appDto
+}
+
+func (impl PipelineBuilderImpl) getCiPipelineSnapshot(ciPipelineId int) interface{} {
+	ciPipeline, err := impl.GetCiPipelineById(ciPipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci pipeline for audit log", "err", err, "ciPipelineId", ciPipelineId)
+		return nil
+	}
+	return ciPipeline
+}
+
+func (impl PipelineBuilderImpl) getCdPipelineSnapshot(cdPipelineId int) interface{} {
+	cdPipeline, err := impl.GetCdPipelineById(cdPipelineId)
+	if err != nil || cdPipeline == nil {
+		impl.logger.Errorw("error in getting cd pipeline for audit log", "err", err, "cdPipelineId", cdPipelineId)
+		return nil
+	}
+	return cdPipeline
+}
+
+// saveCiPipelineAuditLog records the ci pipeline before and after a change, the state after is read once the change
+// is saved. Ids of ci and cd pipelines overlap so they are prefixed with the pipeline type
+func (impl PipelineBuilderImpl) saveCiPipelineAuditLog(ciPipelineId int, action string, before interface{}, userId int32) {
+	var after interface{}
+	if action != auditLog.AUDIT_ACTION_DELETE {
+		after = impl.getCiPipelineSnapshot(ciPipelineId)
+	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_PIPELINE, fmt.Sprintf("ci-%d", ciPipelineId), action, before, after, userId)
+}
+
+func (impl PipelineBuilderImpl) saveCdPipelineAuditLog(cdPipelineId int, action string, before interface{}, userId int32) {
+	var after interface{}
+	if action != auditLog.AUDIT_ACTION_DELETE {
+		after = impl.getCdPipelineSnapshot(cdPipelineId)
+	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_PIPELINE, fmt.Sprintf("cd-%d", cdPipelineId), action, before, after, userId)
+}
+
 type DeploymentType struct {
 	Deployment Deployment `json:"deployment"`
 }
@@ -1502,6 +1704,21 @@ func (impl PipelineBuilderImpl) createCdPipeline(ctx context.Context, app *app2.
 
 	}
 
+	if pipeline.PreDeployStage != nil && len(pipeline.PreDeployStage.Steps) > 0 {
+		err = impl.pipelineStageService.CreateCdStage(pipeline.PreDeployStage, repository5.PIPELINE_STAGE_TYPE_PRE_CD, pipelineId, userId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating pre cd stage", "err", err, "preDeployStage", pipeline.PreDeployStage, "cdPipelineId", pipelineId)
+			return 0, err
+		}
+	}
+	if pipeline.PostDeployStage != nil && len(pipeline.PostDeployStage.Steps) > 0 {
+		err = impl.pipelineStageService.CreateCdStage(pipeline.PostDeployStage, repository5.PIPELINE_STAGE_TYPE_POST_CD, pipelineId, userId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating post cd stage", "err", err, "postDeployStage", pipeline.PostDeployStage, "cdPipelineId", pipelineId)
+			return 0, err
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return 0, err
@@ -1529,6 +1746,10 @@ func (impl PipelineBuilderImpl) updateCdPipeline(ctx context.Context, pipeline *
 		}
 		return err
 	}
+	err = validateCdPipelineStageConfig(pipeline)
+	if err != nil {
+		return err
+	}
 	dbConnection := impl.pipelineRepository.GetConnection()
 	tx, err := dbConnection.Begin()
 	if err != nil {
@@ -1618,6 +1839,21 @@ func (impl PipelineBuilderImpl) updateCdPipeline(ctx context.Context, pipeline *
 			}
 		}
 	}
+	//a stage switched back to yaml config gets its steps deleted
+	if pipeline.PreDeployStage != nil || len(pipeline.PreStage.Config) > 0 {
+		err = impl.pipelineStageService.UpdateCdStage(pipeline.PreDeployStage, repository5.PIPELINE_STAGE_TYPE_PRE_CD, pipeline.Id, userID, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating pre cd stage", "err", err, "preDeployStage", pipeline.PreDeployStage, "cdPipelineId", pipeline.Id)
+			return err
+		}
+	}
+	if pipeline.PostDeployStage != nil || len(pipeline.PostStage.Config) > 0 {
+		err = impl.pipelineStageService.UpdateCdStage(pipeline.PostDeployStage, repository5.PIPELINE_STAGE_TYPE_POST_CD, pipeline.Id, userID, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating post cd stage", "err", err, "postDeployStage", pipeline.PostDeployStage, "cdPipelineId", pipeline.Id)
+			return err
+		}
+	}
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -1625,6 +1861,96 @@ func (impl PipelineBuilderImpl) updateCdPipeline(ctx context.Context, pipeline *
 	return nil
 }
 
+// MigrateCdStageYamlToPipelineStages converts legacy yaml based pre/post cd stage configs of all active cd pipelines
+// of an app into plugin based stages with inline shell steps, and clears the yaml config once migrated
+func (impl PipelineBuilderImpl) MigrateCdStageYamlToPipelineStages(appId int, userId int32) error {
+	pipelines, err := impl.pipelineRepository.FindActiveByAppId(appId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching cd pipelines by appId", "err", err, "appId", appId)
+		return err
+	}
+	for _, pipeline := range pipelines {
+		if len(pipeline.PreStageConfig) == 0 && len(pipeline.PostStageConfig) == 0 {
+			continue
+		}
+		err = impl.migrateCdPipelineStageYaml(pipeline, userId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCdPipelineStageYaml migrates the stage yaml of a single cd pipeline in one tx, so that a failure never leaves
+// a stage both as yaml and as steps
+func (impl PipelineBuilderImpl) migrateCdPipelineStageYaml(pipeline *pipelineConfig.Pipeline, userId int32) error {
+	dbConnection := impl.pipelineRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	if len(pipeline.PreStageConfig) > 0 {
+		err = impl.migrateCdStageYaml(pipeline.Id, pipeline.PreStageConfig, pipeline.PreTriggerType, repository5.PIPELINE_STAGE_TYPE_PRE_CD, userId, tx)
+		if err != nil {
+			return err
+		}
+		pipeline.PreStageConfig = ""
+	}
+	if len(pipeline.PostStageConfig) > 0 {
+		err = impl.migrateCdStageYaml(pipeline.Id, pipeline.PostStageConfig, pipeline.PostTriggerType, repository5.PIPELINE_STAGE_TYPE_POST_CD, userId, tx)
+		if err != nil {
+			return err
+		}
+		pipeline.PostStageConfig = ""
+	}
+	pipeline.UpdatedBy = userId
+	pipeline.UpdatedOn = time.Now()
+	err = impl.pipelineRepository.Update(pipeline, tx)
+	if err != nil {
+		impl.logger.Errorw("error in clearing stage yaml of cd pipeline", "err", err, "cdPipelineId", pipeline.Id)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl PipelineBuilderImpl) migrateCdStageYaml(cdPipelineId int, stageYaml string, triggerType pipelineConfig.TriggerType, stageType repository5.PipelineStageType, userId int32, tx *pg.Tx) error {
+	stageConfigured, err := impl.pipelineStageService.IsCdStageConfigured(cdPipelineId, stageType)
+	if err != nil {
+		impl.logger.Errorw("error in checking cd stage", "err", err, "cdPipelineId", cdPipelineId, "stageType", stageType)
+		return err
+	}
+	if stageConfigured {
+		//stage already present, yaml config is stale
+		return nil
+	}
+	stageDto, err := impl.pipelineStageService.BuildCdStageDtoFromStageYaml(stageYaml, stageType)
+	if err != nil {
+		impl.logger.Errorw("error in building cd stage from yaml", "err", err, "cdPipelineId", cdPipelineId, "stageType", stageType)
+		return err
+	}
+	stageDto.TriggerType = triggerType
+	err = impl.pipelineStageService.CreateCdStage(stageDto, stageType, cdPipelineId, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in creating cd stage", "err", err, "cdPipelineId", cdPipelineId, "stageType", stageType)
+		return err
+	}
+	return nil
+}
+
+func validateCdPipelineStageConfig(pipeline *bean.CDPipelineConfigObject) error {
+	if (len(pipeline.PreStage.Config) > 0 && pipeline.PreDeployStage != nil && len(pipeline.PreDeployStage.Steps) > 0) ||
+		(len(pipeline.PostStage.Config) > 0 && pipeline.PostDeployStage != nil && len(pipeline.PostDeployStage.Steps) > 0) {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: "invalid stage config, both yaml config and steps cannot be configured for a stage",
+			UserMessage:     "invalid stage config, both yaml config and steps cannot be configured for a stage",
+		}
+	}
+	return nil
+}
+
 func (impl PipelineBuilderImpl) filterDeploymentTemplate(deploymentTemplate pipelineConfig.DeploymentTemplate, pipelineOverride string) (string, error) {
 	var deploymentType DeploymentType
 	err := json.Unmarshal([]byte(pipelineOverride), &deploymentType)
@@ -1735,6 +2061,8 @@ func (impl PipelineBuilderImpl) GetCdPipelinesForApp(appId int) (cdPipelines *be
 			RunPreStageInEnv:              dbPipeline.RunPreStageInEnv,
 			RunPostStageInEnv:             dbPipeline.RunPostStageInEnv,
 			DeploymentAppType:             dbPipeline.DeploymentAppType,
+			PreDeployStage:                dbPipeline.PreDeployStage,
+			PostDeployStage:               dbPipeline.PostDeployStage,
 		}
 		pipelines = append(pipelines, pipeline)
 	}
@@ -1783,9 +2111,19 @@ func (impl PipelineBuilderImpl) GetArtifactsByCDPipeline(cdPipelineId int, stage
 	if err != nil && err != pg.ErrNoRows {
 		impl.logger.Errorw("Error in getting cd pipeline details", err, "cdPipelineId", cdPipelineId)
 	}
-	if stage == bean2.CD_WORKFLOW_TYPE_DEPLOY && len(pipeline.PreStageConfig) > 0 {
-		parentId = cdPipelineId
-		parentType = bean2.CD_WORKFLOW_TYPE_PRE
+	if stage == bean2.CD_WORKFLOW_TYPE_DEPLOY {
+		preStageExists := len(pipeline.PreStageConfig) > 0
+		if !preStageExists {
+			preStageExists, err = impl.pipelineStageService.IsCdStageConfigured(cdPipelineId, repository5.PIPELINE_STAGE_TYPE_PRE_CD)
+			if err != nil {
+				impl.logger.Errorw("error in checking pre cd stage", "err", err, "cdPipelineId", cdPipelineId)
+				return ciArtifactsResponse, err
+			}
+		}
+		if preStageExists {
+			parentId = cdPipelineId
+			parentType = bean2.CD_WORKFLOW_TYPE_PRE
+		}
 	}
 	if stage == bean2.CD_WORKFLOW_TYPE_POST {
 		parentId = cdPipelineId
@@ -1814,7 +2152,15 @@ func (impl PipelineBuilderImpl) GetCdParentDetails(cdPipelineId int) (parentId i
 			impl.logger.Errorw("Error in fetching cd pipeline details", err, "pipelineId", parentId)
 			return 0, "", err
 		}
-		if len(pipeline.PostStageConfig) > 0 {
+		postStageExists := len(pipeline.PostStageConfig) > 0
+		if !postStageExists {
+			postStageExists, err = impl.pipelineStageService.IsCdStageConfigured(parentId, repository5.PIPELINE_STAGE_TYPE_POST_CD)
+			if err != nil {
+				impl.logger.Errorw("error in checking post cd stage", "err", err, "cdPipelineId", parentId)
+				return 0, "", err
+			}
+		}
+		if postStageExists {
 			return parentId, bean2.CD_WORKFLOW_TYPE_POST, nil
 		} else {
 			return parentId, bean2.CD_WORKFLOW_TYPE_DEPLOY, nil
@@ -2326,6 +2672,11 @@ func (impl PipelineBuilderImpl) GetCdPipelineById(pipelineId int) (cdPipeline *b
 		RunPostStageInEnv:             dbPipeline.RunPostStageInEnv,
 		CdArgoSetup:                   environment.Cluster.CdArgoSetup,
 	}
+	cdPipeline.PreDeployStage, cdPipeline.PostDeployStage, err = impl.pipelineStageService.GetCdPipelineStageData(dbPipeline)
+	if err != nil {
+		impl.logger.Errorw("error in getting pre/post cd stages", "err", err, "cdPipelineId", dbPipeline.Id)
+		return nil, err
+	}
 
 	return cdPipeline, err
 }
@@ -2476,6 +2827,11 @@ func (impl PipelineBuilderImpl) GetCiPipelineById(pipelineId int) (ciPipeline *b
 	}
 	ciPipeline.PreBuildStage = preStageDetail
 	ciPipeline.PostBuildStage = postStageDetail
+	ciPipeline.Schedule, err = impl.ciScheduleService.GetSchedule(ciPipeline.Id)
+	if err != nil {
+		impl.logger.Errorw("error in getting schedule by ciPipelineId", "err", err, "ciPipelineId", ciPipeline.Id)
+		return nil, err
+	}
 	return ciPipeline, err
 }
 
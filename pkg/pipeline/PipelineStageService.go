@@ -1,10 +1,12 @@
 package pipeline
 
 import (
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	repository2 "github.com/devtron-labs/devtron/pkg/plugin/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/ghodss/yaml"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
 	"time"
@@ -18,6 +20,16 @@ type PipelineStageService interface {
 	BuildPrePostAndRefPluginStepsDataForWfRequest(ciPipelineId int) ([]*bean.StepObject, []*bean.StepObject, []*bean.RefPluginObject, error)
 
 	GetCiPipelineStageDataDeepCopy(ciPipelineId int) (preCiStage *bean.PipelineStageDto, postCiStage *bean.PipelineStageDto, err error)
+
+	GetCdPipelineStageData(cdPipeline *pipelineConfig.Pipeline) (preCdStage *bean.PipelineStageDto, postCdStage *bean.PipelineStageDto, err error)
+	CreateCdStage(stageReq *bean.PipelineStageDto, stageType repository.PipelineStageType, cdPipelineId int, userId int32, tx *pg.Tx) error
+	UpdateCdStage(stageReq *bean.PipelineStageDto, stageType repository.PipelineStageType, cdPipelineId int, userId int32, tx *pg.Tx) error
+	DeleteCdStage(stageReq *bean.PipelineStageDto, userId int32, tx *pg.Tx) error
+	IsCdStageConfigured(cdPipelineId int, stageType repository.PipelineStageType) (bool, error)
+	// IsCdPipelineStageConfigured checks for the cd stage configured either as yaml or as plugin based stage
+	IsCdPipelineStageConfigured(cdPipeline *pipelineConfig.Pipeline, stageType repository.PipelineStageType) (bool, error)
+	BuildCdStageStepsAndRefPluginsDataForWfRequest(cdPipelineId int, stageType repository.PipelineStageType) ([]*bean.StepObject, []*bean.RefPluginObject, error)
+	BuildCdStageDtoFromStageYaml(stageYaml string, stageType repository.PipelineStageType) (*bean.PipelineStageDto, error)
 }
 
 func NewPipelineStageService(logger *zap.SugaredLogger,
@@ -490,6 +502,27 @@ func (impl *PipelineStageServiceImpl) CreateCiStage(stageReq *bean.PipelineStage
 }
 
 func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineStageStepDto, stageId int, userId int32, indexNameString map[int]string) error {
+	dbConnection := impl.pipelineStageRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		impl.logger.Errorw("error in starting tx", "err", err)
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.CreateStageStepsWithTxn(steps, stageId, userId, indexNameString, tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("error in tx commit", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *PipelineStageServiceImpl) CreateStageStepsWithTxn(steps []*bean.PipelineStageStepDto, stageId int, userId int32, indexNameString map[int]string, tx *pg.Tx) error {
 	for _, step := range steps {
 		//setting dependentStep detail
 		var dependentOnStep string
@@ -507,7 +540,7 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 		if step.StepType == repository.PIPELINE_STEP_TYPE_INLINE {
 			inlineStepDetail := step.InlineStepDetail
 			//creating script entry first, because step entry needs scriptId
-			scriptEntryId, err := impl.CreateScriptAndMappingForInlineStep(inlineStepDetail, userId)
+			scriptEntryId, err := impl.CreateScriptAndMappingForInlineStepWithTxn(inlineStepDetail, userId, tx)
 			if err != nil {
 				impl.logger.Errorw("error in creating script and mapping for inline step", "err", err, "inlineStepDetail", inlineStepDetail)
 				return err
@@ -529,7 +562,7 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 					UpdatedBy: userId,
 				},
 			}
-			inlineStep, err = impl.pipelineStageRepository.CreatePipelineStageStep(inlineStep)
+			inlineStep, err = impl.pipelineStageRepository.CreatePipelineStageStepWithTxn(inlineStep, tx)
 			if err != nil {
 				impl.logger.Errorw("error in creating inline step", "err", err, "step", inlineStep)
 				return err
@@ -557,7 +590,7 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 					UpdatedBy: userId,
 				},
 			}
-			refPluginStep, err := impl.pipelineStageRepository.CreatePipelineStageStep(refPluginStep)
+			refPluginStep, err := impl.pipelineStageRepository.CreatePipelineStageStepWithTxn(refPluginStep, tx)
 			if err != nil {
 				impl.logger.Errorw("error in creating ref plugin step", "err", err, "step", refPluginStep)
 				return err
@@ -567,13 +600,25 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 			outputVariables = refPluginStepDetail.OutputVariables
 			conditionDetails = refPluginStepDetail.ConditionDetails
 		}
-		inputVariablesRepo, outputVariablesRepo, err := impl.CreateInputAndOutputVariables(stepId, inputVariables, outputVariables, userId)
+		inputVariablesRepo, err := impl.CreateVariablesEntryInDb(stepId, inputVariables, repository.PIPELINE_STAGE_STEP_VARIABLE_TYPE_INPUT, userId, tx)
 		if err != nil {
-			impl.logger.Errorw("error in creating variables for step", "err", err, "stepId", stepId, "inputVariables", inputVariables, "outputVariables", outputVariables)
+			impl.logger.Errorw("error in creating input variables for step", "err", err, "stepId", stepId, "inputVariables", inputVariables)
+			return err
+		}
+		outputVariablesRepo, err := impl.CreateVariablesEntryInDb(stepId, outputVariables, repository.PIPELINE_STAGE_STEP_VARIABLE_TYPE_OUTPUT, userId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating output variables for step", "err", err, "stepId", stepId, "outputVariables", outputVariables)
 			return err
 		}
 		if len(conditionDetails) > 0 {
-			err = impl.CreateConditions(stepId, conditionDetails, inputVariablesRepo, outputVariablesRepo, userId)
+			variableNameIdMap := make(map[string]int)
+			for _, inVar := range inputVariablesRepo {
+				variableNameIdMap[inVar.Name] = inVar.Id
+			}
+			for _, outVar := range outputVariablesRepo {
+				variableNameIdMap[outVar.Name] = outVar.Id
+			}
+			_, err = impl.CreateConditionsEntryInDb(stepId, conditionDetails, variableNameIdMap, userId, tx)
 			if err != nil {
 				impl.logger.Errorw("error in creating conditions", "err", err, "conditionDetails", conditionDetails)
 				return err
@@ -584,6 +629,27 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 }
 
 func (impl *PipelineStageServiceImpl) CreateScriptAndMappingForInlineStep(inlineStepDetail *bean.InlineStepDetailDto, userId int32) (scriptId int, err error) {
+	dbConnection := impl.pipelineStageRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		impl.logger.Errorw("error in starting tx", "err", err)
+		return 0, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	scriptId, err = impl.CreateScriptAndMappingForInlineStepWithTxn(inlineStepDetail, userId, tx)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("error in tx commit", "err", err)
+		return 0, err
+	}
+	return scriptId, nil
+}
+
+func (impl *PipelineStageServiceImpl) CreateScriptAndMappingForInlineStepWithTxn(inlineStepDetail *bean.InlineStepDetailDto, userId int32, tx *pg.Tx) (scriptId int, err error) {
 	scriptEntry := &repository.PluginPipelineScript{
 		Script:                   inlineStepDetail.Script,
 		Type:                     inlineStepDetail.ScriptType,
@@ -604,7 +670,7 @@ func (impl *PipelineStageServiceImpl) CreateScriptAndMappingForInlineStep(inline
 			UpdatedBy: userId,
 		},
 	}
-	scriptEntry, err = impl.pipelineStageRepository.CreatePipelineScript(scriptEntry)
+	scriptEntry, err = impl.pipelineStageRepository.CreatePipelineScriptWithTxn(scriptEntry, tx)
 	if err != nil {
 		impl.logger.Errorw("error in creating script entry for inline step", "err", err, "scriptEntry", scriptEntry)
 		return 0, err
@@ -659,7 +725,7 @@ func (impl *PipelineStageServiceImpl) CreateScriptAndMappingForInlineStep(inline
 		scriptMap = append(scriptMap, repositoryEntry)
 	}
 	if len(scriptMap) > 0 {
-		err = impl.pipelineStageRepository.CreateScriptMapping(scriptMap)
+		err = impl.pipelineStageRepository.CreateScriptMappingWithTxn(scriptMap, tx)
 		if err != nil {
 			impl.logger.Errorw("error in creating script mappings", "err", err, "scriptMappings", scriptMap)
 			return 0, err
@@ -1350,8 +1416,12 @@ func (impl *PipelineStageServiceImpl) DeleteCiStage(stageReq *bean.PipelineStage
 		impl.logger.Errorw("error in marking ci stage deleted", "err", err, "ciStageId", stageReq.Id)
 		return err
 	}
+	return impl.DeleteStageStepsAndRelatedData(stageReq, userId, tx)
+}
+
+func (impl *PipelineStageServiceImpl) DeleteStageStepsAndRelatedData(stageReq *bean.PipelineStageDto, userId int32, tx *pg.Tx) error {
 	//marking all steps deleted
-	err = impl.pipelineStageRepository.MarkCiStageStepsDeletedByStageId(stageReq.Id, userId, tx)
+	err := impl.pipelineStageRepository.MarkCiStageStepsDeletedByStageId(stageReq.Id, userId, tx)
 	if err != nil {
 		impl.logger.Errorw("error in marking ci stage steps deleted by stageId", "err", err, "ciStageId", stageReq.Id)
 		return err
@@ -1650,6 +1720,10 @@ func (impl *PipelineStageServiceImpl) BuildVariableAndConditionDataForWfRequest(
 				variableData.VariableType = bean.VARIABLE_TYPE_REF_POST_CI
 			} else if variable.ReferenceVariableStage == repository.PIPELINE_STAGE_TYPE_PRE_CI {
 				variableData.VariableType = bean.VARIABLE_TYPE_REF_PRE_CI
+			} else if variable.ReferenceVariableStage == repository.PIPELINE_STAGE_TYPE_PRE_CD {
+				variableData.VariableType = bean.VARIABLE_TYPE_REF_PRE_CD
+			} else if variable.ReferenceVariableStage == repository.PIPELINE_STAGE_TYPE_POST_CD {
+				variableData.VariableType = bean.VARIABLE_TYPE_REF_POST_CD
 			}
 		}
 		if variable.VariableType == repository.PIPELINE_STAGE_STEP_VARIABLE_TYPE_INPUT {
@@ -1835,3 +1909,204 @@ func (impl *PipelineStageServiceImpl) BuildPluginVariableAndConditionDataForWfRe
 }
 
 //BuildPrePostAndRefPluginStepsDataForWfRequest and related methods ends
+
+//CD stage methods starts
+
+// cdStageYaml is the legacy yaml format used for pre/post deployment stages before plugin based stages were supported
+type cdStageYaml struct {
+	Version        string `json:"version"`
+	CdPipelineConf []struct {
+		BeforeStages []*cdStageYamlTask `json:"beforeStages"`
+		AfterStages  []*cdStageYamlTask `json:"afterStages"`
+	} `json:"cdPipelineConf"`
+}
+
+type cdStageYamlTask struct {
+	Name           string `json:"name"`
+	Script         string `json:"script"`
+	OutputLocation string `json:"outputLocation"`
+}
+
+func (impl *PipelineStageServiceImpl) GetCdPipelineStageData(cdPipeline *pipelineConfig.Pipeline) (*bean.PipelineStageDto, *bean.PipelineStageDto, error) {
+	cdPipelineId := cdPipeline.Id
+	//getting all stages by cd pipeline id
+	cdStages, err := impl.pipelineStageRepository.GetAllCdStagesByCdPipelineId(cdPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting all cdStages by cdPipelineId", "err", err, "cdPipelineId", cdPipelineId)
+		return nil, nil, err
+	}
+	var preCdStage *bean.PipelineStageDto
+	var postCdStage *bean.PipelineStageDto
+	for _, cdStage := range cdStages {
+		if cdStage.Type == repository.PIPELINE_STAGE_TYPE_PRE_CD {
+			preCdStage, err = impl.BuildCiStageData(cdStage)
+			if err != nil {
+				impl.logger.Errorw("error in getting cd stage data", "err", err, "cdStage", cdStage)
+				return nil, nil, err
+			}
+			preCdStage.TriggerType = cdPipeline.PreTriggerType
+		} else if cdStage.Type == repository.PIPELINE_STAGE_TYPE_POST_CD {
+			postCdStage, err = impl.BuildCiStageData(cdStage)
+			if err != nil {
+				impl.logger.Errorw("error in getting cd stage data", "err", err, "cdStage", cdStage)
+				return nil, nil, err
+			}
+			postCdStage.TriggerType = cdPipeline.PostTriggerType
+		} else {
+			impl.logger.Errorw("found improper stage mapped with cdPipeline", "cdPipelineId", cdPipelineId, "stage", cdStage)
+		}
+	}
+	return preCdStage, postCdStage, nil
+}
+
+func (impl *PipelineStageServiceImpl) CreateCdStage(stageReq *bean.PipelineStageDto, stageType repository.PipelineStageType, cdPipelineId int, userId int32, tx *pg.Tx) error {
+	stage := &repository.PipelineStage{
+		Name:         stageReq.Name,
+		Description:  stageReq.Description,
+		Type:         stageType,
+		Deleted:      false,
+		CdPipelineId: cdPipelineId,
+		AuditLog: sql.AuditLog{
+			CreatedOn: time.Now(),
+			CreatedBy: userId,
+			UpdatedOn: time.Now(),
+			UpdatedBy: userId,
+		},
+	}
+	stage, err := impl.pipelineStageRepository.CreateCdStageWithTxn(stage, tx)
+	if err != nil {
+		impl.logger.Errorw("error in creating entry for cdStage", "err", err, "cdStage", stage)
+		return err
+	}
+	stageReq.Id = stage.Id
+	indexNameString := make(map[int]string)
+	for _, step := range stageReq.Steps {
+		indexNameString[step.Index] = step.Name
+	}
+	//creating stage steps and all related data
+	err = impl.CreateStageStepsWithTxn(stageReq.Steps, stage.Id, userId, indexNameString, tx)
+	if err != nil {
+		impl.logger.Errorw("error in creating stage steps for cd stage", "err", err, "stageId", stage.Id)
+		return err
+	}
+	return nil
+}
+
+// UpdateCdStage replaces the steps stage of the given type of a cd pipeline, the existing stage is deleted and a new one
+// is created from the request if it has any steps, so that a pipeline switched back to yaml config has no steps left
+func (impl *PipelineStageServiceImpl) UpdateCdStage(stageReq *bean.PipelineStageDto, stageType repository.PipelineStageType, cdPipelineId int, userId int32, tx *pg.Tx) error {
+	stageOld, err := impl.pipelineStageRepository.GetCdStageByCdPipelineIdAndStageType(cdPipelineId, stageType)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting stageId by cdPipelineId and stageType", "err", err, "cdPipelineId", cdPipelineId, "stageType", stageType)
+		return err
+	} else if err == nil {
+		err = impl.DeleteCdStage(&bean.PipelineStageDto{Id: stageOld.Id}, userId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in deleting existing cd stage", "err", err, "cdStageId", stageOld.Id)
+			return err
+		}
+	}
+	if stageReq == nil || len(stageReq.Steps) == 0 {
+		return nil
+	}
+	stageReq.Id = 0
+	err = impl.CreateCdStage(stageReq, stageType, cdPipelineId, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in creating new cd stage", "err", err, "cdStageReq", stageReq)
+		return err
+	}
+	return nil
+}
+
+func (impl *PipelineStageServiceImpl) DeleteCdStage(stageReq *bean.PipelineStageDto, userId int32, tx *pg.Tx) error {
+	//marking stage deleted
+	err := impl.pipelineStageRepository.MarkCdStageDeletedById(stageReq.Id, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in marking cd stage deleted", "err", err, "cdStageId", stageReq.Id)
+		return err
+	}
+	return impl.DeleteStageStepsAndRelatedData(stageReq, userId, tx)
+}
+
+func (impl *PipelineStageServiceImpl) IsCdStageConfigured(cdPipelineId int, stageType repository.PipelineStageType) (bool, error) {
+	cdPipelineIds, err := impl.pipelineStageRepository.GetCdPipelineIdsHavingStageSteps([]int{cdPipelineId}, stageType)
+	if err != nil {
+		impl.logger.Errorw("error in checking cd stage steps", "err", err, "cdPipelineId", cdPipelineId, "stageType", stageType)
+		return false, err
+	}
+	return len(cdPipelineIds) > 0, nil
+}
+
+func (impl *PipelineStageServiceImpl) IsCdPipelineStageConfigured(cdPipeline *pipelineConfig.Pipeline, stageType repository.PipelineStageType) (bool, error) {
+	stageYaml := cdPipeline.PreStageConfig
+	if stageType == repository.PIPELINE_STAGE_TYPE_POST_CD {
+		stageYaml = cdPipeline.PostStageConfig
+	}
+	if len(stageYaml) > 0 {
+		return true, nil
+	}
+	return impl.IsCdStageConfigured(cdPipeline.Id, stageType)
+}
+
+func (impl *PipelineStageServiceImpl) BuildCdStageStepsAndRefPluginsDataForWfRequest(cdPipelineId int, stageType repository.PipelineStageType) ([]*bean.StepObject, []*bean.RefPluginObject, error) {
+	cdStage, err := impl.pipelineStageRepository.GetCdStageByCdPipelineIdAndStageType(cdPipelineId, stageType)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting cd stage by cdPipelineId and stageType", "err", err, "cdPipelineId", cdPipelineId, "stageType", stageType)
+		return nil, nil, err
+	} else if err == pg.ErrNoRows {
+		return nil, nil, nil
+	}
+	steps, refPluginIds, err := impl.BuildCiStageDataForWfRequest(cdStage)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd steps data for wf request", "err", err, "cdStage", cdStage)
+		return nil, nil, err
+	}
+	var refPluginsData []*bean.RefPluginObject
+	if len(refPluginIds) > 0 {
+		refPluginsData, err = impl.BuildRefPluginStepDataForWfRequest(refPluginIds)
+		if err != nil {
+			impl.logger.Errorw("error in building ref plugin step data", "err", err, "refPluginIds", refPluginIds)
+			return nil, nil, err
+		}
+	}
+	return steps, refPluginsData, nil
+}
+
+// BuildCdStageDtoFromStageYaml converts legacy yaml stage config of a cd pipeline into a stage having inline shell steps
+func (impl *PipelineStageServiceImpl) BuildCdStageDtoFromStageYaml(stageYaml string, stageType repository.PipelineStageType) (*bean.PipelineStageDto, error) {
+	stageConfig := &cdStageYaml{}
+	err := yaml.Unmarshal([]byte(stageYaml), stageConfig)
+	if err != nil {
+		impl.logger.Errorw("error in unmarshalling cd stage yaml", "err", err, "stageType", stageType)
+		return nil, err
+	}
+	stageDto := &bean.PipelineStageDto{
+		Type: stageType,
+	}
+	var tasks []*cdStageYamlTask
+	for _, conf := range stageConfig.CdPipelineConf {
+		if stageType == repository.PIPELINE_STAGE_TYPE_PRE_CD {
+			tasks = append(tasks, conf.BeforeStages...)
+		} else if stageType == repository.PIPELINE_STAGE_TYPE_POST_CD {
+			tasks = append(tasks, conf.AfterStages...)
+		}
+	}
+	for i, task := range tasks {
+		step := &bean.PipelineStageStepDto{
+			Name:     task.Name,
+			Index:    i + 1,
+			StepType: repository.PIPELINE_STEP_TYPE_INLINE,
+			InlineStepDetail: &bean.InlineStepDetailDto{
+				ScriptType: repository2.SCRIPT_TYPE_SHELL,
+				Script:     task.Script,
+			},
+		}
+		if len(task.OutputLocation) > 0 {
+			step.OutputDirectoryPath = []string{task.OutputLocation}
+		}
+		stageDto.Steps = append(stageDto.Steps, step)
+	}
+	return stageDto, nil
+}
+
+//CD stage methods ends
@@ -0,0 +1,214 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/security"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type SbomService interface {
+	// SaveSbom parses a CycloneDX or SPDX json document and stores it with its packages against the artifact
+	SaveSbom(ciArtifactId int, document []byte, userId int32) (*SbomSummaryDto, error)
+	// GetSbom returns the sbom of the artifact, artifacts of linked ci resolve to the sbom of their parent
+	GetSbom(ciArtifactId int) (*security.CiArtifactSbom, error)
+	SearchDeployedPackages(request *security.SbomPackageSearchRequest) ([]*security.DeployedSbomPackage, error)
+}
+
+type SbomServiceImpl struct {
+	logger               *zap.SugaredLogger
+	sbomRepository       security.SbomRepository
+	ciArtifactRepository repository.CiArtifactRepository
+}
+
+func NewSbomServiceImpl(logger *zap.SugaredLogger,
+	sbomRepository security.SbomRepository,
+	ciArtifactRepository repository.CiArtifactRepository) *SbomServiceImpl {
+	return &SbomServiceImpl{
+		logger:               logger,
+		sbomRepository:       sbomRepository,
+		ciArtifactRepository: ciArtifactRepository,
+	}
+}
+
+type SbomSummaryDto struct {
+	CiArtifactId int       `json:"ciArtifactId"`
+	Format       string    `json:"format"`
+	SpecVersion  string    `json:"specVersion"`
+	PackageCount int       `json:"packageCount"`
+	CreatedOn    time.Time `json:"createdOn"`
+}
+
+type cycloneDxDocument struct {
+	BomFormat   string                `json:"bomFormat"`
+	SpecVersion string                `json:"specVersion"`
+	Components  []*cycloneDxComponent `json:"components"`
+}
+
+type cycloneDxComponent struct {
+	Type       string                `json:"type"`
+	Name       string                `json:"name"`
+	Version    string                `json:"version"`
+	Purl       string                `json:"purl"`
+	Components []*cycloneDxComponent `json:"components"`
+}
+
+type spdxDocument struct {
+	SpdxVersion string         `json:"spdxVersion"`
+	Packages    []*spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	Name         string `json:"name"`
+	VersionInfo  string `json:"versionInfo"`
+	ExternalRefs []struct {
+		ReferenceType    string `json:"referenceType"`
+		ReferenceLocator string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+func (impl *SbomServiceImpl) SaveSbom(ciArtifactId int, document []byte, userId int32) (*SbomSummaryDto, error) {
+	artifact, err := impl.ciArtifactRepository.Get(ciArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci artifact", "err", err, "ciArtifactId", ciArtifactId)
+		return nil, err
+	}
+	if artifact.ParentCiArtifact > 0 {
+		ciArtifactId = artifact.ParentCiArtifact
+	}
+	format, specVersion, packages, err := parseSbomDocument(document)
+	if err != nil {
+		impl.logger.Errorw("error in parsing sbom", "err", err, "ciArtifactId", ciArtifactId)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: "sbom must be a CycloneDX or SPDX json document"}
+	}
+	sbom := &security.CiArtifactSbom{
+		CiArtifactId: ciArtifactId,
+		Format:       format,
+		SpecVersion:  specVersion,
+		Document:     string(document),
+		PackageCount: len(packages),
+		AuditLog:     sql.AuditLog{CreatedOn: time.Now(), CreatedBy: userId, UpdatedOn: time.Now(), UpdatedBy: userId},
+	}
+	err = impl.sbomRepository.SaveSbom(sbom, packages)
+	if err != nil {
+		impl.logger.Errorw("error in saving sbom", "err", err, "ciArtifactId", ciArtifactId)
+		return nil, err
+	}
+	return adaptSbomSummary(sbom), nil
+}
+
+func (impl *SbomServiceImpl) GetSbom(ciArtifactId int) (*security.CiArtifactSbom, error) {
+	artifact, err := impl.ciArtifactRepository.Get(ciArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci artifact", "err", err, "ciArtifactId", ciArtifactId)
+		return nil, err
+	}
+	if artifact.ParentCiArtifact > 0 {
+		ciArtifactId = artifact.ParentCiArtifact
+	}
+	sbom, err := impl.sbomRepository.FindByCiArtifactId(ciArtifactId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "sbom not found", UserMessage: "no sbom found for the artifact"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting sbom", "err", err, "ciArtifactId", ciArtifactId)
+		return nil, err
+	}
+	return sbom, nil
+}
+
+func (impl *SbomServiceImpl) SearchDeployedPackages(request *security.SbomPackageSearchRequest) ([]*security.DeployedSbomPackage, error) {
+	packages, err := impl.sbomRepository.FindDeployedPackages(request)
+	if err != nil {
+		impl.logger.Errorw("error in searching deployed packages", "err", err, "request", request)
+		return nil, err
+	}
+	if packages == nil {
+		packages = []*security.DeployedSbomPackage{}
+	}
+	return packages, nil
+}
+
+func adaptSbomSummary(sbom *security.CiArtifactSbom) *SbomSummaryDto {
+	return &SbomSummaryDto{
+		CiArtifactId: sbom.CiArtifactId,
+		Format:       sbom.Format,
+		SpecVersion:  sbom.SpecVersion,
+		PackageCount: sbom.PackageCount,
+		CreatedOn:    sbom.CreatedOn,
+	}
+}
+
+// parseSbomDocument detects the sbom format and flattens its components or packages
+func parseSbomDocument(document []byte) (string, string, []*security.CiArtifactSbomPackage, error) {
+	var packages []*security.CiArtifactSbomPackage
+	cycloneDx := &cycloneDxDocument{}
+	err := json.Unmarshal(document, cycloneDx)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if strings.EqualFold(cycloneDx.BomFormat, "CycloneDX") {
+		var addComponents func(components []*cycloneDxComponent)
+		addComponents = func(components []*cycloneDxComponent) {
+			for _, component := range components {
+				if len(component.Name) > 0 {
+					packages = append(packages, &security.CiArtifactSbomPackage{
+						Name:        component.Name,
+						Version:     component.Version,
+						PackageType: getPurlType(component.Purl, component.Type),
+						Purl:        component.Purl,
+					})
+				}
+				addComponents(component.Components)
+			}
+		}
+		addComponents(cycloneDx.Components)
+		return security.SBOM_FORMAT_CYCLONEDX, cycloneDx.SpecVersion, packages, nil
+	}
+	spdx := &spdxDocument{}
+	err = json.Unmarshal(document, spdx)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !strings.HasPrefix(spdx.SpdxVersion, "SPDX-") {
+		return "", "", nil, fmt.Errorf("unknown sbom format")
+	}
+	for _, spdxPkg := range spdx.Packages {
+		if len(spdxPkg.Name) == 0 {
+			continue
+		}
+		purl := ""
+		for _, ref := range spdxPkg.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				purl = ref.ReferenceLocator
+				break
+			}
+		}
+		packages = append(packages, &security.CiArtifactSbomPackage{
+			Name:        spdxPkg.Name,
+			Version:     spdxPkg.VersionInfo,
+			PackageType: getPurlType(purl, ""),
+			Purl:        purl,
+		})
+	}
+	return security.SBOM_FORMAT_SPDX, strings.TrimPrefix(spdx.SpdxVersion, "SPDX-"), packages, nil
+}
+
+// getPurlType reads the package type from a package url like pkg:npm/lodash@4.17.21, falling back to the given type
+func getPurlType(purl string, fallback string) string {
+	if !strings.HasPrefix(purl, "pkg:") {
+		return fallback
+	}
+	purlType := strings.TrimPrefix(purl, "pkg:")
+	if index := strings.Index(purlType, "/"); index > 0 {
+		return purlType[:index]
+	}
+	return fallback
+}
@@ -37,13 +37,17 @@ import (
 )
 
 type CiArtifactWebhookRequest struct {
-	Image        string          `json:"image"`
-	ImageDigest  string          `json:"imageDigest"`
-	MaterialInfo json.RawMessage `json:"materialInfo"`
-	DataSource   string          `json:"dataSource"`
-	PipelineName string          `json:"pipelineName"`
-	WorkflowId   *int            `json:"workflowId"`
-	UserId       int32           `json:"userId"`
+	Image            string          `json:"image"`
+	ImageDigest      string          `json:"imageDigest"`
+	MaterialInfo     json.RawMessage `json:"materialInfo"`
+	DataSource       string          `json:"dataSource"`
+	PipelineName     string          `json:"pipelineName"`
+	WorkflowId       *int            `json:"workflowId"`
+	UserId           int32           `json:"userId"`
+	Signature        string          `json:"signature"` //cosign signature created by external ci over the signature payload
+	SignaturePayload string          `json:"signaturePayload"`
+	Sbom             json.RawMessage `json:"sbom,omitempty"` //CycloneDX or SPDX json of the image
+	DevtronCiBuild   bool            `json:"-"`              //set only for images built by devtron ci, only these are signed with the ci signing key
 }
 
 type WebhookService interface {
@@ -52,15 +56,17 @@ type WebhookService interface {
 }
 
 type WebhookServiceImpl struct {
-	ciArtifactRepository repository.CiArtifactRepository
-	logger               *zap.SugaredLogger
-	ciPipelineRepository pipelineConfig.CiPipelineRepository
-	ciWorkflowRepository pipelineConfig.CiWorkflowRepository
-	appService           app.AppService
-	eventClient          client.EventClient
-	eventFactory         client.EventFactory
-	workflowDagExecutor  WorkflowDagExecutor
-	ciHandler            CiHandler
+	ciArtifactRepository  repository.CiArtifactRepository
+	logger                *zap.SugaredLogger
+	ciPipelineRepository  pipelineConfig.CiPipelineRepository
+	ciWorkflowRepository  pipelineConfig.CiWorkflowRepository
+	appService            app.AppService
+	eventClient           client.EventClient
+	eventFactory          client.EventFactory
+	workflowDagExecutor   WorkflowDagExecutor
+	ciHandler             CiHandler
+	imageSignatureService ImageSignatureService
+	sbomService           SbomService
 }
 
 func NewWebhookServiceImpl(
@@ -70,17 +76,20 @@ func NewWebhookServiceImpl(
 	appService app.AppService, eventClient client.EventClient,
 	eventFactory client.EventFactory,
 	ciWorkflowRepository pipelineConfig.CiWorkflowRepository,
-	workflowDagExecutor WorkflowDagExecutor, ciHandler CiHandler) *WebhookServiceImpl {
+	workflowDagExecutor WorkflowDagExecutor, ciHandler CiHandler,
+	imageSignatureService ImageSignatureService, sbomService SbomService) *WebhookServiceImpl {
 	return &WebhookServiceImpl{
-		ciArtifactRepository: ciArtifactRepository,
-		logger:               logger,
-		ciPipelineRepository: ciPipelineRepository,
-		appService:           appService,
-		eventClient:          eventClient,
-		eventFactory:         eventFactory,
-		ciWorkflowRepository: ciWorkflowRepository,
-		workflowDagExecutor:  workflowDagExecutor,
-		ciHandler:            ciHandler,
+		ciArtifactRepository:  ciArtifactRepository,
+		logger:                logger,
+		ciPipelineRepository:  ciPipelineRepository,
+		appService:            appService,
+		eventClient:           eventClient,
+		eventFactory:          eventFactory,
+		ciWorkflowRepository:  ciWorkflowRepository,
+		workflowDagExecutor:   workflowDagExecutor,
+		ciHandler:             ciHandler,
+		imageSignatureService: imageSignatureService,
+		sbomService:           sbomService,
 	}
 }
 
@@ -165,10 +174,17 @@ func (impl WebhookServiceImpl) SaveCiArtifactWebhook(ciPipelineId int, request *
 	if pipeline.ScanEnabled {
 		artifact.Scanned = true
 	}
+	impl.applyArtifactSignature(artifact, request)
 	if err = impl.ciArtifactRepository.Save(artifact); err != nil {
 		impl.logger.Errorw("error in saving material", "err", err)
 		return 0, err
 	}
+	if len(request.Sbom) > 0 {
+		_, err = impl.sbomService.SaveSbom(artifact.Id, request.Sbom, request.UserId)
+		if err != nil {
+			impl.logger.Errorw("error in saving sbom of artifact", "err", err, "artifactId", artifact.Id)
+		}
+	}
 
 	childrenCi, err := impl.ciPipelineRepository.FindByParentCiPipelineId(ciPipelineId)
 	if err != nil && !util2.IsErrNoRows(err) {
@@ -179,15 +195,19 @@ func (impl WebhookServiceImpl) SaveCiArtifactWebhook(ciPipelineId int, request *
 	var ciArtifactArr []*repository.CiArtifact
 	for _, ci := range childrenCi {
 		ciArtifact := &repository.CiArtifact{
-			Image:            request.Image,
-			ImageDigest:      request.ImageDigest,
-			MaterialInfo:     string(materialJson),
-			DataSource:       request.DataSource,
-			PipelineId:       ci.Id,
-			ParentCiArtifact: artifact.Id,
-			ScanEnabled:      ci.ScanEnabled,
-			Scanned:          false,
-			AuditLog:         sql.AuditLog{CreatedBy: request.UserId, UpdatedBy: request.UserId, CreatedOn: time.Now(), UpdatedOn: time.Now()},
+			Image:                 request.Image,
+			ImageDigest:           request.ImageDigest,
+			MaterialInfo:          string(materialJson),
+			DataSource:            request.DataSource,
+			PipelineId:            ci.Id,
+			ParentCiArtifact:      artifact.Id,
+			ScanEnabled:           ci.ScanEnabled,
+			Scanned:               false,
+			Signature:             artifact.Signature,
+			SignaturePayload:      artifact.SignaturePayload,
+			SigningKeyFingerprint: artifact.SigningKeyFingerprint,
+			SignedOn:              artifact.SignedOn,
+			AuditLog:              sql.AuditLog{CreatedBy: request.UserId, UpdatedBy: request.UserId, CreatedOn: time.Now(), UpdatedOn: time.Now()},
 		}
 		if ci.ScanEnabled {
 			ciArtifact.Scanned = true
@@ -231,6 +251,25 @@ func (impl WebhookServiceImpl) SaveCiArtifactWebhook(ciPipelineId int, request *
 	return artifact.Id, err
 }
 
+// applyArtifactSignature keeps the signature created by the ci, an unsigned image is signed with the ci signing key only
+// if devtron built it, an image pushed by an external ci is not known to be built from the reported materials
+func (impl WebhookServiceImpl) applyArtifactSignature(artifact *repository.CiArtifact, request *CiArtifactWebhookRequest) {
+	if len(request.Signature) > 0 {
+		artifact.Signature = request.Signature
+		artifact.SignaturePayload = request.SignaturePayload
+		artifact.SignedOn = time.Now()
+		return
+	}
+	if !request.DevtronCiBuild {
+		return
+	}
+	err := impl.imageSignatureService.SignArtifact(artifact)
+	if err != nil {
+		// signing failure does not fail the build, deployment is blocked later if the environment requires a signature
+		impl.logger.Errorw("error in signing image", "err", err, "image", artifact.Image)
+	}
+}
+
 func (impl *WebhookServiceImpl) WriteCISuccessEvent(request *CiArtifactWebhookRequest, pipeline *pipelineConfig.CiPipeline, artifact *repository.CiArtifact) {
 	event := impl.eventFactory.Build(util.Success, &pipeline.Id, pipeline.AppId, nil, util.CI)
 	event.CiArtifactId = artifact.Id
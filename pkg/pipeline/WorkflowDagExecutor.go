@@ -20,10 +20,12 @@ package pipeline
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/argoproj/gitops-engine/pkg/health"
 	blob_storage "github.com/devtron-labs/common-lib/blob-storage"
 	"github.com/devtron-labs/devtron/util/argo"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +34,7 @@ import (
 	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
 	history2 "github.com/devtron-labs/devtron/pkg/pipeline/history"
 	repository3 "github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	repository4 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	util3 "github.com/devtron-labs/devtron/pkg/util"
@@ -65,7 +68,12 @@ type WorkflowDagExecutor interface {
 	TriggerPostStage(cdWf *pipelineConfig.CdWorkflow, cdPipeline *pipelineConfig.Pipeline, triggeredBy int32) error
 	TriggerDeployment(cdWf *pipelineConfig.CdWorkflow, artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, applyAuth bool, async bool, triggeredBy int32) error
 	ManualCdTrigger(overrideRequest *bean.ValuesOverrideRequest, ctx context.Context) (int, error)
-	TriggerBulkDeploymentAsync(requests []*BulkTriggerRequest, UserId int32) (interface{}, error)
+	// TriggerFanOutDeployment deploys the artifact of a cd workflow on an additional environment of its fan-out pipeline,
+	// the deployment is recorded as a fan-out runner of the same cd workflow. Returns the runner, if created, and the release id
+	TriggerFanOutDeployment(cdWorkflowId int, envId int, deploymentAppCreated bool, triggeredBy int32, ctx context.Context) (*pipelineConfig.CdWorkflowRunner, int, error)
+	TriggerBulkDeploymentAsync(requests []*BulkTriggerRequest, UserId int32, windowOverride *DeploymentWindowOverride) (interface{}, error)
+	TriggerQueuedDeployments()
+	TriggerApprovedDeployments()
 	StopStartApp(stopRequest *StopAppRequest, ctx context.Context) (int, error)
 	TriggerBulkHibernateAsync(request StopDeploymentGroupRequest, ctx context.Context) (interface{}, error)
 }
@@ -97,6 +105,11 @@ type WorkflowDagExecutorImpl struct {
 	prePostCdScriptHistoryService history2.PrePostCdScriptHistoryService
 	argoUserService               argo.ArgoUserService
 	cdPipelineStatusTimelineRepo  pipelineConfig.PipelineStatusTimelineRepository
+	pipelineStageService          PipelineStageService
+	deploymentApprovalService     DeploymentApprovalService
+	deploymentWindowService       DeploymentWindowService
+	imageSignatureService         ImageSignatureService
+	promotionPolicyService        ArtifactPromotionPolicyService
 }
 
 type CiArtifactDTO struct {
@@ -142,7 +155,12 @@ func NewWorkflowDagExecutorImpl(Logger *zap.SugaredLogger, pipelineRepository pi
 	appWorkflowRepository appWorkflow.AppWorkflowRepository,
 	prePostCdScriptHistoryService history2.PrePostCdScriptHistoryService,
 	argoUserService argo.ArgoUserService,
-	cdPipelineStatusTimelineRepo pipelineConfig.PipelineStatusTimelineRepository) *WorkflowDagExecutorImpl {
+	cdPipelineStatusTimelineRepo pipelineConfig.PipelineStatusTimelineRepository,
+	pipelineStageService PipelineStageService,
+	deploymentApprovalService DeploymentApprovalService,
+	deploymentWindowService DeploymentWindowService,
+	imageSignatureService ImageSignatureService,
+	promotionPolicyService ArtifactPromotionPolicyService) *WorkflowDagExecutorImpl {
 	wde := &WorkflowDagExecutorImpl{logger: Logger,
 		pipelineRepository:            pipelineRepository,
 		cdWorkflowRepository:          cdWorkflowRepository,
@@ -169,6 +187,11 @@ func NewWorkflowDagExecutorImpl(Logger *zap.SugaredLogger, pipelineRepository pi
 		prePostCdScriptHistoryService: prePostCdScriptHistoryService,
 		argoUserService:               argoUserService,
 		cdPipelineStatusTimelineRepo:  cdPipelineStatusTimelineRepo,
+		pipelineStageService:          pipelineStageService,
+		deploymentApprovalService:     deploymentApprovalService,
+		deploymentWindowService:       deploymentWindowService,
+		imageSignatureService:         imageSignatureService,
+		promotionPolicyService:        promotionPolicyService,
 	}
 	err := util4.AddStream(wde.pubsubClient.JetStrCtxt, util4.ORCHESTRATOR_STREAM, util4.CI_RUNNER_STREAM)
 	if err != nil {
@@ -251,8 +274,11 @@ func (impl *WorkflowDagExecutorImpl) HandleCiSuccessEvent(artifact *repository.C
 }
 
 func (impl *WorkflowDagExecutorImpl) triggerStage(cdWf *pipelineConfig.CdWorkflow, pipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact, applyAuth bool, async bool, triggeredBy int32) error {
-	var err error
-	if len(pipeline.PreStageConfig) > 0 {
+	preStageExists, err := impl.isPreStageConfigured(pipeline)
+	if err != nil {
+		return err
+	}
+	if preStageExists {
 		// pre stage exists
 		if pipeline.PreTriggerType == pipelineConfig.TRIGGER_TYPE_AUTOMATIC {
 			impl.logger.Debugw("trigger pre stage for pipeline", "artifactId", artifact.Id, "pipelineId", pipeline.Id)
@@ -269,8 +295,11 @@ func (impl *WorkflowDagExecutorImpl) triggerStage(cdWf *pipelineConfig.CdWorkflo
 }
 
 func (impl *WorkflowDagExecutorImpl) triggerStageForBulk(cdWf *pipelineConfig.CdWorkflow, pipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact, applyAuth bool, async bool, triggeredBy int32) error {
-	var err error
-	if len(pipeline.PreStageConfig) > 0 {
+	preStageExists, err := impl.isPreStageConfigured(pipeline)
+	if err != nil {
+		return err
+	}
+	if preStageExists {
 		//pre stage exists
 		impl.logger.Debugw("trigger pre stage for pipeline", "artifactId", artifact.Id, "pipelineId", pipeline.Id)
 		err = impl.TriggerPreStage(cdWf, artifact, pipeline, artifact.UpdatedBy, applyAuth) //TODO handle error here
@@ -282,6 +311,17 @@ func (impl *WorkflowDagExecutorImpl) triggerStageForBulk(cdWf *pipelineConfig.Cd
 		return err
 	}
 }
+
+// isPreStageConfigured checks for pre cd stage configured either as yaml or as plugin based stage
+func (impl *WorkflowDagExecutorImpl) isPreStageConfigured(pipeline *pipelineConfig.Pipeline) (bool, error) {
+	return impl.pipelineStageService.IsCdPipelineStageConfigured(pipeline, repository4.PIPELINE_STAGE_TYPE_PRE_CD)
+}
+
+// isPostStageConfigured checks for post cd stage configured either as yaml or as plugin based stage
+func (impl *WorkflowDagExecutorImpl) isPostStageConfigured(pipeline *pipelineConfig.Pipeline) (bool, error) {
+	return impl.pipelineStageService.IsCdPipelineStageConfigured(pipeline, repository4.PIPELINE_STAGE_TYPE_POST_CD)
+}
+
 func (impl *WorkflowDagExecutorImpl) HandlePreStageSuccessEvent(cdStageCompleteEvent CdStageCompleteEvent) error {
 	wfRunner, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(cdStageCompleteEvent.WorkflowRunnerId)
 	if err != nil {
@@ -570,13 +610,16 @@ func (impl *WorkflowDagExecutorImpl) buildWFRequest(runner *pipelineConfig.CdWor
 	}
 
 	var stageYaml string
+	var stageType repository4.PipelineStageType
 	var deployStageWfr pipelineConfig.CdWorkflowRunner
 	deployStageTriggeredByUser := &bean.UserInfo{}
 	var pipelineReleaseCounter int
 	if runner.WorkflowType == bean.CD_WORKFLOW_TYPE_PRE {
 		stageYaml = cdPipeline.PreStageConfig
+		stageType = repository4.PIPELINE_STAGE_TYPE_PRE_CD
 	} else if runner.WorkflowType == bean.CD_WORKFLOW_TYPE_POST {
 		stageYaml = cdPipeline.PostStageConfig
+		stageType = repository4.PIPELINE_STAGE_TYPE_POST_CD
 		//getting deployment pipeline latest wfr by pipelineId
 		pipelineId := cdPipeline.Id
 		deployStageWfr, err = impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(pipelineId, bean.CD_WORKFLOW_TYPE_DEPLOY)
@@ -643,6 +686,14 @@ func (impl *WorkflowDagExecutorImpl) buildWFRequest(runner *pipelineConfig.CdWor
 	if pipelineReleaseCounter > 0 {
 		cdStageWorkflowRequest.DeploymentReleaseCounter = pipelineReleaseCounter
 	}
+	if len(stageYaml) == 0 {
+		//stage is not configured through yaml, getting plugin based steps
+		cdStageWorkflowRequest.PrePostDeploySteps, cdStageWorkflowRequest.RefPlugins, err = impl.pipelineStageService.BuildCdStageStepsAndRefPluginsDataForWfRequest(cdPipeline.Id, stageType)
+		if err != nil {
+			impl.logger.Errorw("error in getting cd stage steps for wf request", "err", err, "cdPipelineId", cdPipeline.Id, "stageType", stageType)
+			return nil, err
+		}
+	}
 	if cdWorkflowConfig.CdCacheRegion == "" {
 		cdWorkflowConfig.CdCacheRegion = impl.cdConfig.DefaultCdLogsBucketRegion
 	}
@@ -736,7 +787,11 @@ func (impl *WorkflowDagExecutorImpl) HandleDeploymentSuccessEvent(gitHash string
 		impl.logger.Errorw("error in fetching cd workflow by id", "pipelineOverride", pipelineOverride)
 		return err
 	}
-	if len(pipelineOverride.Pipeline.PostStageConfig) > 0 {
+	postStageExists, err := impl.isPostStageConfigured(pipelineOverride.Pipeline)
+	if err != nil {
+		return err
+	}
+	if postStageExists {
 		if pipelineOverride.Pipeline.PostTriggerType == pipelineConfig.TRIGGER_TYPE_AUTOMATIC &&
 			pipelineOverride.DeploymentType != models.DEPLOYMENTTYPE_STOP &&
 			pipelineOverride.DeploymentType != models.DEPLOYMENTTYPE_START {
@@ -811,6 +866,48 @@ func (impl *WorkflowDagExecutorImpl) TriggerDeployment(cdWf *pipelineConfig.CdWo
 		}
 	}
 
+	parkedReason, err := impl.parkAutoTriggerIfRequired(artifact, pipeline, triggeredBy)
+	if err != nil || len(parkedReason) > 0 {
+		return err
+	}
+	return impl.triggerAutoDeployment(cdWf, artifact, pipeline, async)
+}
+
+// parkAutoTriggerIfRequired raises an approval request or queues the deployment for the next deployment window
+// when the artifact can't be deployed right away, returns the reason the trigger was parked for. Artifacts blocked
+// by the promotion policy are rejected with an error as waiting does not unblock them
+func (impl *WorkflowDagExecutorImpl) parkAutoTriggerIfRequired(artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, triggeredBy int32) (string, error) {
+	err := impl.promotionPolicyService.CheckArtifactPromotion(pipeline, artifact)
+	if err != nil {
+		return "", err
+	}
+	approved, err := impl.deploymentApprovalService.IsArtifactApproved(pipeline.Id, artifact.Id)
+	if err != nil {
+		impl.logger.Errorw("error in checking deployment approval", "err", err, "pipelineId", pipeline.Id, "artifactId", artifact.Id)
+		return "", err
+	}
+	if !approved {
+		impl.raiseApprovalRequestForAutoTrigger(pipeline.Id, artifact.Id, triggeredBy)
+		return "artifact awaiting approval", nil
+	}
+
+	windowOpen, err := impl.deploymentWindowService.IsAutoDeploymentAllowed(pipeline, artifact.Id)
+	if err != nil {
+		impl.logger.Errorw("error in checking deployment window", "err", err, "pipelineId", pipeline.Id, "artifactId", artifact.Id)
+		return "", err
+	}
+	if !windowOpen {
+		impl.logger.Infow("deployment window closed, queueing deployment", "pipelineId", pipeline.Id, "artifactId", artifact.Id)
+		err = impl.deploymentWindowService.QueueDeployment(pipeline.Id, artifact.Id, triggeredBy)
+		if err != nil {
+			return "", err
+		}
+		return "deployment window closed, queued again", nil
+	}
+	return "", nil
+}
+
+func (impl *WorkflowDagExecutorImpl) triggerAutoDeployment(cdWf *pipelineConfig.CdWorkflow, artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, async bool) error {
 	//setting triggeredAt variable to have consistent data for various audit log places in db for deployment time
 	triggeredAt := time.Now()
 
@@ -916,6 +1013,10 @@ func (impl *WorkflowDagExecutorImpl) TriggerDeployment(cdWf *pipelineConfig.CdWo
 		}
 		return nil
 	}
+	signatureVerified, err := impl.verifyImageSignature(runner, artifact, pipeline.EnvironmentId)
+	if err != nil || !signatureVerified {
+		return err
+	}
 
 	err = impl.appService.TriggerCD(artifact, cdWf.Id, savedWfr.Id, pipeline, async, triggeredAt)
 	err1 := impl.updatePreviousDeploymentStatus(runner, pipeline.Id, err, triggeredAt)
@@ -926,6 +1027,44 @@ func (impl *WorkflowDagExecutorImpl) TriggerDeployment(cdWf *pipelineConfig.CdWo
 	return nil
 }
 
+// verifyImageSignature checks the artifact against the image signature policy of the environment, a blocked deployment
+// marks the runner failed with the reason recorded on the timeline
+func (impl *WorkflowDagExecutorImpl) verifyImageSignature(runner *pipelineConfig.CdWorkflowRunner, artifact *repository.CiArtifact, envId int) (bool, error) {
+	verification, err := impl.imageSignatureService.VerifyArtifact(artifact, envId)
+	if err != nil {
+		impl.logger.Errorw("error in verifying image signature", "err", err, "artifactId", artifact.Id, "envId", envId)
+		return false, err
+	}
+	if verification.Allowed {
+		return true, nil
+	}
+	runner.Status = WorkflowFailed
+	runner.Message = "Image signature policy violated: " + verification.Reason
+	runner.FinishedOn = time.Now()
+	err = impl.cdWorkflowRepository.UpdateWorkFlowRunner(runner)
+	if err != nil {
+		impl.logger.Errorw("error in updating status", "err", err)
+		return false, err
+	}
+	timeline := &pipelineConfig.PipelineStatusTimeline{
+		CdWorkflowRunnerId: runner.Id,
+		Status:             pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_FAILED,
+		StatusDetail:       fmt.Sprintf("Deployment failed: Image signature policy violated, %s.", verification.Reason),
+		StatusTime:         time.Now(),
+		AuditLog: sql.AuditLog{
+			CreatedBy: 1,
+			CreatedOn: time.Now(),
+			UpdatedBy: 1,
+			UpdatedOn: time.Now(),
+		},
+	}
+	err = impl.cdPipelineStatusTimelineRepo.SaveTimeline(timeline)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for deployment fail - image signature policy violation", "err", err, "timeline", timeline)
+	}
+	return false, nil
+}
+
 func (impl *WorkflowDagExecutorImpl) updatePreviousDeploymentStatus(currentRunner *pipelineConfig.CdWorkflowRunner, pipelineId int, err error, triggeredAt time.Time) error {
 	if err != nil {
 		//creating cd pipeline status timeline for deployment failed
@@ -1064,6 +1203,23 @@ func (impl *WorkflowDagExecutorImpl) StopStartApp(stopRequest *StopAppRequest, c
 	return id, err
 }
 
+// raiseApprovalRequestForAutoTrigger parks an auto triggered deployment behind an approval request,
+// a request is raised only if none is pending for the artifact
+func (impl *WorkflowDagExecutorImpl) raiseApprovalRequestForAutoTrigger(pipelineId int, artifactId int, triggeredBy int32) {
+	request, err := impl.deploymentApprovalService.RaiseApprovalRequest(&DeploymentApprovalRequestDto{
+		PipelineId:   pipelineId,
+		CiArtifactId: artifactId,
+		Comment:      "raised on auto trigger",
+		UserId:       triggeredBy,
+		AutoTrigger:  true,
+	})
+	if err != nil {
+		impl.logger.Infow("deployment skipped, artifact awaiting approval", "pipelineId", pipelineId, "artifactId", artifactId, "err", err)
+		return
+	}
+	impl.logger.Infow("deployment skipped, approval request raised", "pipelineId", pipelineId, "artifactId", artifactId, "approvalRequestId", request.Id)
+}
+
 func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.ValuesOverrideRequest, ctx context.Context) (int, error) {
 	//setting triggeredAt variable to have consistent data for various audit log places in db for deployment time
 	triggeredAt := time.Now()
@@ -1074,6 +1230,18 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 		impl.logger.Errorf("invalid req", "err", err, "req", overrideRequest)
 		return 0, err
 	}
+	// auto rollback restores an artifact which was already deployed, so the promotion policy is not re-applied
+	if !overrideRequest.IsAutoRollback && overrideRequest.CdWorkflowType != bean.CD_WORKFLOW_TYPE_POST {
+		artifact, err := impl.ciArtifactRepository.Get(overrideRequest.CiArtifactId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching artifact", "err", err, "artifactId", overrideRequest.CiArtifactId)
+			return 0, err
+		}
+		err = impl.promotionPolicyService.CheckArtifactPromotion(cdPipeline, artifact)
+		if err != nil {
+			return 0, err
+		}
+	}
 
 	if overrideRequest.CdWorkflowType == bean.CD_WORKFLOW_TYPE_PRE {
 		artifact, err := impl.ciArtifactRepository.Get(overrideRequest.CiArtifactId)
@@ -1090,6 +1258,22 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 		if overrideRequest.DeploymentType == models.DEPLOYMENTTYPE_UNKNOWN {
 			overrideRequest.DeploymentType = models.DEPLOYMENTTYPE_DEPLOY
 		}
+		// auto rollback restores an artifact which was already deployed, so approval and window gates are not re-applied
+		if !overrideRequest.IsAutoRollback {
+			approved, err := impl.deploymentApprovalService.IsArtifactApproved(overrideRequest.PipelineId, overrideRequest.CiArtifactId)
+			if err != nil {
+				impl.logger.Errorw("error in checking deployment approval", "err", err, "pipelineId", overrideRequest.PipelineId, "artifactId", overrideRequest.CiArtifactId)
+				return 0, err
+			}
+			if !approved {
+				return 0, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "deployment approval pending", UserMessage: "artifact is not approved for deployment on this pipeline, please raise an approval request"}
+			}
+			err = impl.deploymentWindowService.CheckManualDeployment(cdPipeline, overrideRequest.CiArtifactId, overrideRequest.UserId, overrideRequest.OverrideDeploymentWindow, overrideRequest.DeploymentWindowOverrideReason)
+			if err != nil {
+				impl.logger.Errorw("deployment blocked by deployment window", "err", err, "pipelineId", overrideRequest.PipelineId)
+				return 0, err
+			}
+		}
 		cdWf, err := impl.cdWorkflowRepository.FindByWorkflowIdAndRunnerType(overrideRequest.CdWorkflowId, bean.CD_WORKFLOW_TYPE_PRE)
 		if err != nil && !util.IsErrNoRows(err) {
 			impl.logger.Errorw("err", "err", err)
@@ -1206,6 +1390,13 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 			}
 			return 0, fmt.Errorf("found vulnerability for image digest %s", artifact.ImageDigest)
 		}
+		signatureVerified, err := impl.verifyImageSignature(runner, artifact, cdPipeline.EnvironmentId)
+		if err != nil {
+			return 0, err
+		}
+		if !signatureVerified {
+			return 0, &util.ApiError{HttpStatusCode: http.StatusPreconditionFailed, InternalMessage: "image signature policy violated", UserMessage: runner.Message}
+		}
 
 		releaseId, err = impl.appService.TriggerRelease(overrideRequest, ctx, triggeredAt, overrideRequest.UserId, savedWfr.Id)
 		//	return after error handling
@@ -1248,12 +1439,163 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 	return releaseId, err
 }
 
+func (impl *WorkflowDagExecutorImpl) TriggerFanOutDeployment(cdWorkflowId int, envId int, deploymentAppCreated bool, triggeredBy int32, ctx context.Context) (*pipelineConfig.CdWorkflowRunner, int, error) {
+	triggeredAt := time.Now()
+	cdWf, err := impl.cdWorkflowRepository.FindById(cdWorkflowId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd workflow", "cdWorkflowId", cdWorkflowId, "err", err)
+		return nil, 0, err
+	}
+	cdPipeline, err := impl.pipelineRepository.FindById(cdWf.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd pipeline", "pipelineId", cdWf.PipelineId, "err", err)
+		return nil, 0, err
+	}
+	env, err := impl.envRepository.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching environment", "envId", envId, "err", err)
+		return nil, 0, err
+	}
+	// the rollout may outlive the approval and the deployment window of its pipeline, so both are checked for every wave
+	approved, err := impl.deploymentApprovalService.IsArtifactApproved(cdPipeline.Id, cdWf.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in checking deployment approval", "err", err, "pipelineId", cdPipeline.Id, "artifactId", cdWf.CiArtifactId)
+		return nil, 0, err
+	}
+	if !approved {
+		return nil, 0, fmt.Errorf("artifact is not approved for deployment on this pipeline")
+	}
+	// the window policy is resolved for the target environment and its cluster, not for the environment of the pipeline
+	targetPipeline := *cdPipeline
+	targetPipeline.EnvironmentId = env.Id
+	targetPipeline.Environment = *env
+	windowOpen, err := impl.deploymentWindowService.IsAutoDeploymentAllowed(&targetPipeline, cdWf.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in checking deployment window", "err", err, "pipelineId", cdPipeline.Id, "envId", env.Id)
+		return nil, 0, err
+	}
+	if !windowOpen {
+		return nil, 0, fmt.Errorf("deployment window of environment %s is closed", env.Name)
+	}
+	runner := &pipelineConfig.CdWorkflowRunner{
+		Name:         fmt.Sprintf("%s-%s", cdPipeline.Name, env.Name),
+		WorkflowType: bean.CD_WORKFLOW_TYPE_FAN_OUT_DEPLOY,
+		ExecutorType: pipelineConfig.WORKFLOW_EXECUTOR_TYPE_AWF,
+		Status:       WorkflowInProgress,
+		TriggeredBy:  triggeredBy,
+		StartedOn:    triggeredAt,
+		Namespace:    impl.cdConfig.DefaultNamespace,
+		CdWorkflowId: cdWf.Id,
+	}
+	_, err = impl.cdWorkflowRepository.SaveWorkFlowRunner(runner)
+	if err != nil {
+		impl.logger.Errorw("error in saving fan-out cd workflow runner", "runner", runner, "err", err)
+		return nil, 0, err
+	}
+	timeline := &pipelineConfig.PipelineStatusTimeline{
+		CdWorkflowRunnerId: runner.Id,
+		Status:             pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_INITIATED,
+		StatusDetail:       "Deployment initiated successfully.",
+		StatusTime:         time.Now(),
+		AuditLog: sql.AuditLog{
+			CreatedBy: 1,
+			CreatedOn: time.Now(),
+			UpdatedBy: 1,
+			UpdatedOn: time.Now(),
+		},
+	}
+	err = impl.cdPipelineStatusTimelineRepo.SaveTimeline(timeline)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for deployment initiation", "err", err, "timeline", timeline)
+	}
+	releaseId, err := impl.triggerFanOutRelease(cdWf, cdPipeline, env, deploymentAppCreated, runner, triggeredAt, ctx)
+	if err != nil {
+		err1 := impl.updatePreviousDeploymentStatus(runner, cdPipeline.Id, err, triggeredAt)
+		if err1 != nil {
+			impl.logger.Errorw("error in marking fan-out cd workflow runner failed", "err", err1, "runner", runner)
+		}
+		return runner, 0, err
+	}
+	return runner, releaseId, nil
+}
+
+// triggerFanOutRelease applies the vulnerability and image signature policies of the target environment and releases
+// the artifact on it
+func (impl *WorkflowDagExecutorImpl) triggerFanOutRelease(cdWf *pipelineConfig.CdWorkflow, cdPipeline *pipelineConfig.Pipeline, env *repository2.Environment,
+	deploymentAppCreated bool, runner *pipelineConfig.CdWorkflowRunner, triggeredAt time.Time, ctx context.Context) (int, error) {
+	artifact, err := impl.ciArtifactRepository.Get(cdWf.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact", "artifactId", cdWf.CiArtifactId, "err", err)
+		return 0, err
+	}
+	if len(artifact.ImageDigest) > 0 {
+		var cveStores []*security.CveStore
+		imageScanResult, err := impl.scanResultRepository.FindByImageDigest(artifact.ImageDigest)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error fetching image digest", "digest", artifact.ImageDigest, "err", err)
+			return 0, err
+		}
+		for _, item := range imageScanResult {
+			cveStores = append(cveStores, &item.CveStore)
+		}
+		blockCveList, err := impl.cvePolicyRepository.GetBlockedCVEList(cveStores, env.ClusterId, env.Id, cdPipeline.AppId, false)
+		if err != nil {
+			impl.logger.Errorw("error while fetching blocked cve list", "err", err)
+			return 0, err
+		}
+		if len(blockCveList) > 0 {
+			return 0, fmt.Errorf("found vulnerability for image digest %s", artifact.ImageDigest)
+		}
+	}
+	signatureVerified, err := impl.verifyImageSignature(runner, artifact, env.Id)
+	if err != nil {
+		return 0, err
+	}
+	if !signatureVerified {
+		return 0, errors.New(runner.Message)
+	}
+	overrideRequest := &bean.ValuesOverrideRequest{
+		PipelineId:                 cdPipeline.Id,
+		AppId:                      cdPipeline.AppId,
+		CiArtifactId:               cdWf.CiArtifactId,
+		CdWorkflowType:             bean.CD_WORKFLOW_TYPE_DEPLOY,
+		CdWorkflowId:               cdWf.Id,
+		DeploymentWithConfig:       bean.DEPLOYMENT_CONFIG_TYPE_LAST_SAVED,
+		DeploymentType:             models.DEPLOYMENTTYPE_DEPLOY,
+		UserId:                     runner.TriggeredBy,
+		TargetEnvironmentId:        env.Id,
+		TargetDeploymentAppCreated: deploymentAppCreated,
+	}
+	return impl.appService.TriggerRelease(overrideRequest, ctx, triggeredAt, runner.TriggeredBy, runner.Id)
+}
+
 type BulkTriggerRequest struct {
 	CiArtifactId int `sql:"ci_artifact_id"`
 	PipelineId   int `sql:"pipeline_id"`
 }
 
-func (impl *WorkflowDagExecutorImpl) TriggerBulkDeploymentAsync(requests []*BulkTriggerRequest, UserId int32) (interface{}, error) {
+// DeploymentWindowOverride carries a super admin request to deploy outside the deployment window
+type DeploymentWindowOverride struct {
+	Override bool
+	Reason   string
+}
+
+func (impl *WorkflowDagExecutorImpl) TriggerBulkDeploymentAsync(requests []*BulkTriggerRequest, UserId int32, windowOverride *DeploymentWindowOverride) (interface{}, error) {
+	if windowOverride == nil {
+		windowOverride = &DeploymentWindowOverride{}
+	}
+	for _, request := range requests {
+		cdPipeline, err := impl.pipelineRepository.FindById(request.PipelineId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching pipeline", "err", err, "pipelineId", request.PipelineId)
+			return nil, err
+		}
+		err = impl.deploymentWindowService.CheckManualDeployment(cdPipeline, request.CiArtifactId, UserId, windowOverride.Override, windowOverride.Reason)
+		if err != nil {
+			impl.logger.Errorw("bulk deployment blocked by deployment window", "err", err, "pipelineId", request.PipelineId)
+			return nil, err
+		}
+	}
 	var cdWorkflows []*pipelineConfig.CdWorkflow
 	for _, request := range requests {
 		cdWf := &pipelineConfig.CdWorkflow{
@@ -1277,6 +1619,92 @@ func (impl *WorkflowDagExecutorImpl) TriggerBulkDeploymentAsync(requests []*Bulk
 	//consume message
 }
 
+// TriggerQueuedDeployments deploys the auto triggers queued while the deployment window was closed
+func (impl *WorkflowDagExecutorImpl) TriggerQueuedDeployments() {
+	queuedItems, err := impl.deploymentWindowService.GetQueuedDeployments()
+	if err != nil {
+		impl.logger.Errorw("error in getting queued deployments", "err", err)
+		return
+	}
+	for _, item := range queuedItems {
+		cdPipeline, err := impl.pipelineRepository.FindById(item.PipelineId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching pipeline for queued deployment", "err", err, "item", item)
+			_ = impl.deploymentWindowService.UpdateQueuedDeploymentStatus(item, pipelineConfig.DEPLOYMENT_WINDOW_QUEUE_FAILED, err.Error(), deploymentWindowSystemUserId)
+			continue
+		}
+		state, err := impl.deploymentWindowService.GetDeploymentWindowState(cdPipeline)
+		if err != nil {
+			impl.logger.Errorw("error in getting deployment window state", "err", err, "pipelineId", item.PipelineId)
+			continue
+		}
+		if !state.Allowed {
+			continue
+		}
+		artifact, err := impl.ciArtifactRepository.Get(item.CiArtifactId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching artifact for queued deployment", "err", err, "item", item)
+			_ = impl.deploymentWindowService.UpdateQueuedDeploymentStatus(item, pipelineConfig.DEPLOYMENT_WINDOW_QUEUE_FAILED, err.Error(), deploymentWindowSystemUserId)
+			continue
+		}
+		// claiming the item first so that it is deployed by a single replica and a window closing meanwhile
+		// re-queues a fresh item instead of this one
+		claimed, err := impl.deploymentWindowService.ClaimQueuedDeployment(item, pipelineConfig.DEPLOYMENT_WINDOW_QUEUE_TRIGGERED, "", deploymentWindowSystemUserId)
+		if err != nil || !claimed {
+			continue
+		}
+		parkedReason, err := impl.parkAutoTriggerIfRequired(artifact, cdPipeline, item.TriggeredBy)
+		if err == nil && len(parkedReason) > 0 {
+			_ = impl.deploymentWindowService.UpdateQueuedDeploymentStatus(item, pipelineConfig.DEPLOYMENT_WINDOW_QUEUE_PARKED, parkedReason, deploymentWindowSystemUserId)
+			continue
+		}
+		if err == nil {
+			err = impl.triggerAutoDeployment(nil, artifact, cdPipeline, false)
+		}
+		if err != nil {
+			impl.logger.Errorw("error in triggering queued deployment", "err", err, "item", item)
+			_ = impl.deploymentWindowService.UpdateQueuedDeploymentStatus(item, pipelineConfig.DEPLOYMENT_WINDOW_QUEUE_FAILED, err.Error(), deploymentWindowSystemUserId)
+		}
+	}
+}
+
+// TriggerApprovedDeployments deploys the auto triggers parked for approval once their request is approved, only the
+// latest approved artifact of a pipeline is deployed
+func (impl *WorkflowDagExecutorImpl) TriggerApprovedDeployments() {
+	requests, err := impl.deploymentApprovalService.GetApprovedAutoTriggerRequests()
+	if err != nil {
+		impl.logger.Errorw("error in getting approved auto trigger requests", "err", err)
+		return
+	}
+	triggeredPipelines := make(map[int]bool)
+	for _, request := range requests {
+		// marking first so that the request is deployed by a single replica
+		marked, err := impl.deploymentApprovalService.MarkAutoTriggered(request.Id)
+		if err != nil || !marked {
+			continue
+		}
+		if triggeredPipelines[request.PipelineId] {
+			impl.logger.Infow("approved auto trigger superseded by a later artifact", "requestId", request.Id, "pipelineId", request.PipelineId)
+			continue
+		}
+		triggeredPipelines[request.PipelineId] = true
+		cdPipeline, err := impl.pipelineRepository.FindById(request.PipelineId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching pipeline for approved deployment", "err", err, "requestId", request.Id)
+			continue
+		}
+		artifact, err := impl.ciArtifactRepository.Get(request.CiArtifactId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching artifact for approved deployment", "err", err, "requestId", request.Id)
+			continue
+		}
+		err = impl.TriggerDeployment(nil, artifact, cdPipeline, false, false, request.CreatedBy)
+		if err != nil {
+			impl.logger.Errorw("error in triggering approved deployment", "err", err, "requestId", request.Id)
+		}
+	}
+}
+
 type DeploymentGroupAppWithEnv struct {
 	EnvironmentId     int         `json:"environmentId"`
 	DeploymentGroupId int         `json:"deploymentGroupId"`
@@ -98,6 +98,8 @@ type WorkflowRequest struct {
 	CiArtifactFileName         string                            `json:"ciArtifactFileName"`
 	CiArtifactRegion           string                            `json:"ciArtifactRegion"`
 	ScanEnabled                bool                              `json:"scanEnabled"`
+	SbomEnabled                bool                              `json:"sbomEnabled"`
+	SbomFormat                 string                            `json:"sbomFormat"`
 	CloudProvider              blob_storage.BlobStorageType      `json:"cloudProvider"`
 	BlobStorageConfigured      bool                              `json:"blobStorageConfigured"`
 	BlobStorageS3Config        *blob_storage.BlobStorageS3Config `json:"blobStorageS3Config"`
@@ -6,6 +6,8 @@ const (
 	VARIABLE_TYPE_VALUE       = "VALUE"
 	VARIABLE_TYPE_REF_PRE_CI  = "REF_PRE_CI"
 	VARIABLE_TYPE_REF_POST_CI = "REF_POST_CI"
+	VARIABLE_TYPE_REF_PRE_CD  = "REF_PRE_CD"
+	VARIABLE_TYPE_REF_POST_CD = "REF_POST_CD"
 	VARIABLE_TYPE_REF_GLOBAL  = "REF_GLOBAL"
 	VARIABLE_TYPE_REF_PLUGIN  = "REF_PLUGIN"
 )
@@ -0,0 +1,389 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	"github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+type ConfigDiffType string
+
+const (
+	CONFIG_DIFF_ADDED     ConfigDiffType = "ADDED"
+	CONFIG_DIFF_REMOVED   ConfigDiffType = "REMOVED"
+	CONFIG_DIFF_CHANGED   ConfigDiffType = "CHANGED"
+	CONFIG_DIFF_UNCHANGED ConfigDiffType = "UNCHANGED"
+)
+
+const hiddenSecretValue = "*****"
+
+type ConfigCompareSide struct {
+	// EnvironmentId is 0 for the base configuration of the app
+	EnvironmentId int `json:"environmentId"`
+	// WfrId selects a past deployment of the environment, latest deployment is used when 0
+	WfrId int `json:"wfrId"`
+}
+
+type ConfigCompareRequest struct {
+	AppId              int                `json:"appId"`
+	Left               *ConfigCompareSide `json:"left" validate:"required"`
+	Right              *ConfigCompareSide `json:"right" validate:"required"`
+	UserHasAdminAccess bool               `json:"-"`
+}
+
+type ConfigCompareSideDetail struct {
+	EnvironmentId int `json:"environmentId"`
+	PipelineId    int `json:"pipelineId,omitempty"`
+	WfrId         int `json:"wfrId,omitempty"`
+}
+
+type ConfigCompareResponse struct {
+	AppId              int                      `json:"appId"`
+	Left               *ConfigCompareSideDetail `json:"left"`
+	Right              *ConfigCompareSideDetail `json:"right"`
+	DeploymentTemplate *ConfigComponentDiff     `json:"deploymentTemplate"`
+	ConfigMaps         []*ConfigComponentDiff   `json:"configMaps"`
+	Secrets            []*ConfigComponentDiff   `json:"secrets"`
+	PipelineStrategy   *ConfigComponentDiff     `json:"pipelineStrategy"`
+}
+
+type ConfigComponentDiff struct {
+	ComponentName string             `json:"componentName,omitempty"`
+	Status        ConfigDiffType     `json:"status"`
+	Diffs         []*ConfigFieldDiff `json:"diffs,omitempty"`
+}
+
+type ConfigFieldDiff struct {
+	Path  string         `json:"path"`
+	Type  ConfigDiffType `json:"type"`
+	Left  interface{}    `json:"left,omitempty"`
+	Right interface{}    `json:"right,omitempty"`
+}
+
+type ConfigComparisonService interface {
+	// CompareConfigurations returns the differences in deployment template, config maps, secrets and pipeline
+	// strategy between two environments of an app, or an environment and the base configuration
+	CompareConfigurations(request *ConfigCompareRequest) (*ConfigCompareResponse, error)
+}
+
+type ConfigComparisonServiceImpl struct {
+	logger                              *zap.SugaredLogger
+	deployedConfigurationHistoryService DeployedConfigurationHistoryService
+	configMapHistoryService             ConfigMapHistoryService
+	pipelineRepository                  pipelineConfig.PipelineRepository
+	chartRepository                     chartRepoRepository.ChartRepository
+	chartRefRepository                  chartRepoRepository.ChartRefRepository
+	configMapRepository                 chartConfig.ConfigMapRepository
+}
+
+func NewConfigComparisonServiceImpl(logger *zap.SugaredLogger,
+	deployedConfigurationHistoryService DeployedConfigurationHistoryService,
+	configMapHistoryService ConfigMapHistoryService,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	chartRepository chartRepoRepository.ChartRepository,
+	chartRefRepository chartRepoRepository.ChartRefRepository,
+	configMapRepository chartConfig.ConfigMapRepository) *ConfigComparisonServiceImpl {
+	return &ConfigComparisonServiceImpl{
+		logger:                              logger,
+		deployedConfigurationHistoryService: deployedConfigurationHistoryService,
+		configMapHistoryService:             configMapHistoryService,
+		pipelineRepository:                  pipelineRepository,
+		chartRepository:                     chartRepository,
+		chartRefRepository:                  chartRefRepository,
+		configMapRepository:                 configMapRepository,
+	}
+}
+
+func (impl *ConfigComparisonServiceImpl) CompareConfigurations(request *ConfigCompareRequest) (*ConfigCompareResponse, error) {
+	leftDetail, leftConfig, err := impl.getConfiguration(request.AppId, request.Left)
+	if err != nil {
+		return nil, err
+	}
+	rightDetail, rightConfig, err := impl.getConfiguration(request.AppId, request.Right)
+	if err != nil {
+		return nil, err
+	}
+	response := &ConfigCompareResponse{
+		AppId: request.AppId,
+		Left:  leftDetail,
+		Right: rightDetail,
+	}
+	response.DeploymentTemplate, err = compareHistoryDetail("", leftConfig.DeploymentTemplateConfig, rightConfig.DeploymentTemplateConfig, false)
+	if err != nil {
+		impl.logger.Errorw("error in comparing deployment templates", "err", err, "request", request)
+		return nil, err
+	}
+	response.PipelineStrategy, err = compareHistoryDetail("", leftConfig.StrategyConfig, rightConfig.StrategyConfig, false)
+	if err != nil {
+		impl.logger.Errorw("error in comparing pipeline strategies", "err", err, "request", request)
+		return nil, err
+	}
+	response.ConfigMaps, err = compareComponents(leftConfig.ConfigMapConfig, rightConfig.ConfigMapConfig, false)
+	if err != nil {
+		impl.logger.Errorw("error in comparing config maps", "err", err, "request", request)
+		return nil, err
+	}
+	// secrets are compared with their values so that changed values are reported, values are then hidden
+	// from users without admin access same as in deployed configuration history
+	response.Secrets, err = compareComponents(leftConfig.SecretConfig, rightConfig.SecretConfig, !request.UserHasAdminAccess)
+	if err != nil {
+		impl.logger.Errorw("error in comparing secrets", "err", err, "request", request)
+		return nil, err
+	}
+	return response, nil
+}
+
+func (impl *ConfigComparisonServiceImpl) getConfiguration(appId int, side *ConfigCompareSide) (*ConfigCompareSideDetail, *AllDeploymentConfigurationDetail, error) {
+	detail := &ConfigCompareSideDetail{EnvironmentId: side.EnvironmentId}
+	if side.EnvironmentId == 0 {
+		config, err := impl.getBaseConfiguration(appId)
+		return detail, config, err
+	}
+	pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(appId, side.EnvironmentId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "appId", appId, "envId", side.EnvironmentId)
+		return nil, nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("no cd pipeline found for environment %d", side.EnvironmentId)}
+	}
+	detail.PipelineId = pipelines[0].Id
+	detail.WfrId = side.WfrId
+	var config *AllDeploymentConfigurationDetail
+	// values of secrets are needed to find changes, they are hidden after comparing if required
+	if side.WfrId > 0 {
+		config, err = impl.deployedConfigurationHistoryService.GetAllDeployedConfigurationByPipelineIdAndWfrId(detail.PipelineId, side.WfrId, true)
+	} else {
+		config, err = impl.deployedConfigurationHistoryService.GetAllDeployedConfigurationByPipelineIdAndLatestWfrId(detail.PipelineId, true)
+		if config != nil {
+			detail.WfrId = config.WfrId
+		}
+	}
+	if err == pg.ErrNoRows || (err == nil && config.DeploymentTemplateConfig == nil) {
+		return nil, nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("no deployed configuration found for environment %d", side.EnvironmentId)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting deployed configuration", "err", err, "pipelineId", detail.PipelineId, "wfrId", side.WfrId)
+		return nil, nil, err
+	}
+	return detail, config, nil
+}
+
+func (impl *ConfigComparisonServiceImpl) getBaseConfiguration(appId int) (*AllDeploymentConfigurationDetail, error) {
+	chart, err := impl.chartRepository.FindLatestChartForAppByAppId(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting base chart of app", "err", err, "appId", appId)
+		return nil, err
+	}
+	templateName := chart.ChartName
+	chartRef, err := impl.chartRefRepository.FindById(chart.ChartRefId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting chart ref", "err", err, "chartRefId", chart.ChartRefId)
+		return nil, err
+	} else if chartRef != nil && len(chartRef.Name) > 0 {
+		templateName = chartRef.Name
+	}
+	config := &AllDeploymentConfigurationDetail{
+		DeploymentTemplateConfig: &HistoryDetailDto{
+			TemplateName:    templateName,
+			TemplateVersion: chart.ChartVersion,
+			CodeEditorValue: &HistoryDetailConfig{
+				DisplayName: "values.yaml",
+				Value:       chart.GlobalOverride,
+			},
+		},
+	}
+	appLevelConfig, err := impl.configMapRepository.GetByAppIdAppLevel(appId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app level config maps and secrets", "err", err, "appId", appId)
+		return nil, err
+	}
+	if appLevelConfig == nil || appLevelConfig.Id == 0 {
+		return config, nil
+	}
+	configList := &ConfigList{}
+	if len(appLevelConfig.ConfigMapData) > 0 {
+		err = json.Unmarshal([]byte(appLevelConfig.ConfigMapData), configList)
+		if err != nil {
+			impl.logger.Errorw("error in unmarshalling app level config maps", "err", err, "appId", appId)
+			return nil, err
+		}
+	}
+	secretList := &SecretList{}
+	if len(appLevelConfig.SecretData) > 0 {
+		err = json.Unmarshal([]byte(appLevelConfig.SecretData), secretList)
+		if err != nil {
+			impl.logger.Errorw("error in unmarshalling app level secrets", "err", err, "appId", appId)
+			return nil, err
+		}
+	}
+	for _, item := range configList.ConfigData {
+		componentLevelData, err := impl.configMapHistoryService.ConvertConfigDataToComponentLevelDto(item, repository.CONFIGMAP_TYPE, true)
+		if err != nil {
+			return nil, err
+		}
+		config.ConfigMapConfig = append(config.ConfigMapConfig, componentLevelData)
+	}
+	for _, item := range secretList.ConfigData {
+		componentLevelData, err := impl.configMapHistoryService.ConvertConfigDataToComponentLevelDto(item, repository.SECRET_TYPE, true)
+		if err != nil {
+			return nil, err
+		}
+		config.SecretConfig = append(config.SecretConfig, componentLevelData)
+	}
+	return config, nil
+}
+
+func compareComponents(left []*ComponentLevelHistoryDetailDto, right []*ComponentLevelHistoryDetailDto, hideValues bool) ([]*ConfigComponentDiff, error) {
+	leftByName := make(map[string]*HistoryDetailDto)
+	rightByName := make(map[string]*HistoryDetailDto)
+	var names []string
+	for _, component := range left {
+		if component == nil {
+			continue
+		}
+		leftByName[component.ComponentName] = component.HistoryConfig
+		names = append(names, component.ComponentName)
+	}
+	for _, component := range right {
+		if component == nil {
+			continue
+		}
+		if _, ok := leftByName[component.ComponentName]; !ok {
+			names = append(names, component.ComponentName)
+		}
+		rightByName[component.ComponentName] = component.HistoryConfig
+	}
+	sort.Strings(names)
+	componentDiffs := make([]*ConfigComponentDiff, 0, len(names))
+	for _, name := range names {
+		componentDiff, err := compareHistoryDetail(name, leftByName[name], rightByName[name], hideValues)
+		if err != nil {
+			return nil, err
+		}
+		componentDiffs = append(componentDiffs, componentDiff)
+	}
+	return componentDiffs, nil
+}
+
+func compareHistoryDetail(name string, left *HistoryDetailDto, right *HistoryDetailDto, hideValues bool) (*ConfigComponentDiff, error) {
+	componentDiff := &ConfigComponentDiff{ComponentName: name, Status: CONFIG_DIFF_UNCHANGED}
+	if left == nil && right == nil {
+		return componentDiff, nil
+	} else if left == nil {
+		componentDiff.Status = CONFIG_DIFF_ADDED
+		return componentDiff, nil
+	} else if right == nil {
+		componentDiff.Status = CONFIG_DIFF_REMOVED
+		return componentDiff, nil
+	}
+	leftValue, err := comparableHistoryDetail(left)
+	if err != nil {
+		return nil, err
+	}
+	rightValue, err := comparableHistoryDetail(right)
+	if err != nil {
+		return nil, err
+	}
+	diffs := diffConfigValues("", leftValue, rightValue)
+	if len(diffs) > 0 {
+		componentDiff.Status = CONFIG_DIFF_CHANGED
+		componentDiff.Diffs = diffs
+	}
+	if hideValues {
+		for _, diff := range diffs {
+			if strings.HasPrefix(diff.Path, "data") {
+				diff.Left, diff.Right = hideValue(diff.Left), hideValue(diff.Right)
+			}
+		}
+	}
+	return componentDiff, nil
+}
+
+// comparableHistoryDetail converts the history detail into a map with the code editor value parsed under data
+func comparableHistoryDetail(detail *HistoryDetailDto) (map[string]interface{}, error) {
+	value := make(map[string]interface{})
+	detailJson, err := json.Marshal(detail)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(detailJson, &value)
+	if err != nil {
+		return nil, err
+	}
+	delete(value, "codeEditorValue")
+	if detail.CodeEditorValue != nil && len(detail.CodeEditorValue.Value) > 0 {
+		var data interface{}
+		err = yaml.Unmarshal([]byte(detail.CodeEditorValue.Value), &data)
+		if err != nil {
+			return nil, err
+		}
+		value["data"] = data
+	}
+	return value, nil
+}
+
+// diffConfigValues returns the differences between two json values, maps are compared key by key and any
+// other value is compared as a whole
+func diffConfigValues(path string, left interface{}, right interface{}) []*ConfigFieldDiff {
+	leftMap, leftIsMap := left.(map[string]interface{})
+	rightMap, rightIsMap := right.(map[string]interface{})
+	if !leftIsMap || !rightIsMap {
+		if reflect.DeepEqual(left, right) {
+			return nil
+		}
+		return []*ConfigFieldDiff{{Path: path, Type: CONFIG_DIFF_CHANGED, Left: left, Right: right}}
+	}
+	keys := make([]string, 0, len(leftMap)+len(rightMap))
+	for key := range leftMap {
+		keys = append(keys, key)
+	}
+	for key := range rightMap {
+		if _, ok := leftMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var diffs []*ConfigFieldDiff
+	for _, key := range keys {
+		keyPath := key
+		if len(path) > 0 {
+			keyPath = path + "." + key
+		}
+		leftValue, inLeft := leftMap[key]
+		rightValue, inRight := rightMap[key]
+		if !inLeft {
+			diffs = append(diffs, &ConfigFieldDiff{Path: keyPath, Type: CONFIG_DIFF_ADDED, Right: rightValue})
+		} else if !inRight {
+			diffs = append(diffs, &ConfigFieldDiff{Path: keyPath, Type: CONFIG_DIFF_REMOVED, Left: leftValue})
+		} else {
+			diffs = append(diffs, diffConfigValues(keyPath, leftValue, rightValue)...)
+		}
+	}
+	return diffs
+}
+
+func hideValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if valueMap, ok := value.(map[string]interface{}); ok {
+		hidden := make(map[string]interface{}, len(valueMap))
+		for key := range valueMap {
+			hidden[key] = hiddenSecretValue
+		}
+		return hidden
+	}
+	return hiddenSecretValue
+}
@@ -16,6 +16,7 @@ import (
 type ConfigMapHistoryService interface {
 	CreateHistoryFromAppLevelConfig(appLevelConfig *chartConfig.ConfigMapAppModel, configType repository.ConfigType) error
 	CreateHistoryFromEnvLevelConfig(envLevelConfig *chartConfig.ConfigMapEnvModel, configType repository.ConfigType) error
+	CreateHistoryFromEnvLevelConfigWithTxn(envLevelConfig *chartConfig.ConfigMapEnvModel, configType repository.ConfigType, tx *pg.Tx) error
 	CreateCMCSHistoryForDeploymentTrigger(pipeline *pipelineConfig.Pipeline, deployedOn time.Time, deployedBy int32) error
 	MergeAppLevelAndEnvLevelConfigs(appLevelConfig *chartConfig.ConfigMapAppModel, envLevelConfig *chartConfig.ConfigMapEnvModel, configType repository.ConfigType, configMapSecretNames []string) (string, error)
 	GetDeploymentDetailsForDeployedCMCSHistory(pipelineId int, configType repository.ConfigType) ([]*ConfigMapAndSecretHistoryDto, error)
@@ -114,6 +115,10 @@ func (impl ConfigMapHistoryServiceImpl) CreateHistoryFromAppLevelConfig(appLevel
 }
 
 func (impl ConfigMapHistoryServiceImpl) CreateHistoryFromEnvLevelConfig(envLevelConfig *chartConfig.ConfigMapEnvModel, configType repository.ConfigType) error {
+	return impl.CreateHistoryFromEnvLevelConfigWithTxn(envLevelConfig, configType, nil)
+}
+
+func (impl ConfigMapHistoryServiceImpl) CreateHistoryFromEnvLevelConfigWithTxn(envLevelConfig *chartConfig.ConfigMapEnvModel, configType repository.ConfigType, tx *pg.Tx) error {
 	pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(envLevelConfig.AppId, envLevelConfig.EnvironmentId)
 	if err != nil {
 		impl.logger.Errorw("err in getting pipelines, CreateHistoryFromEnvLevelConfig", "err", err, "envLevelConfig", envLevelConfig)
@@ -143,7 +148,11 @@ func (impl ConfigMapHistoryServiceImpl) CreateHistoryFromEnvLevelConfig(envLevel
 				UpdatedOn: envLevelConfig.UpdatedOn,
 			},
 		}
-		_, err = impl.configMapHistoryRepository.CreateHistory(historyModel)
+		if tx != nil {
+			_, err = impl.configMapHistoryRepository.CreateHistoryWithTxn(historyModel, tx)
+		} else {
+			_, err = impl.configMapHistoryRepository.CreateHistory(historyModel)
+		}
 		if err != nil {
 			impl.logger.Errorw("error in creating new entry for CM/CS history", "historyModel", historyModel)
 			return err
@@ -17,10 +17,14 @@ const (
 
 type ConfigMapHistoryRepository interface {
 	CreateHistory(model *ConfigmapAndSecretHistory) (*ConfigmapAndSecretHistory, error)
+	CreateHistoryWithTxn(model *ConfigmapAndSecretHistory, tx *pg.Tx) (*ConfigmapAndSecretHistory, error)
 	GetHistoryForDeployedCMCSById(id, pipelineId int, configType ConfigType) (*ConfigmapAndSecretHistory, error)
 	GetDeploymentDetailsForDeployedCMCSHistory(pipelineId int, configType ConfigType) ([]*ConfigmapAndSecretHistory, error)
 	GetHistoryByPipelineIdAndWfrId(pipelineId, wfrId int, configType ConfigType) (*ConfigmapAndSecretHistory, error)
 	GetDeployedHistoryList(pipelineId, baseConfigId int, configType ConfigType, componentName string) ([]*ConfigmapAndSecretHistory, error)
+	GetLatestHistoryId() (int, error)
+	GetHistoryById(id int) (*ConfigmapAndSecretHistory, error)
+	GetLatestSavedHistoryByAppIdAndPipelineId(appId, pipelineId int, configType ConfigType, maxHistoryId int) (*ConfigmapAndSecretHistory, error)
 }
 
 type ConfigMapHistoryRepositoryImpl struct {
@@ -57,6 +61,15 @@ func (impl ConfigMapHistoryRepositoryImpl) CreateHistory(model *ConfigmapAndSecr
 	return model, nil
 }
 
+func (impl ConfigMapHistoryRepositoryImpl) CreateHistoryWithTxn(model *ConfigmapAndSecretHistory, tx *pg.Tx) (*ConfigmapAndSecretHistory, error) {
+	err := tx.Insert(model)
+	if err != nil {
+		impl.logger.Errorw("err in creating env config map/secret history entry", "err", err)
+		return model, err
+	}
+	return model, nil
+}
+
 func (impl ConfigMapHistoryRepositoryImpl) GetHistoryForDeployedCMCSById(id, pipelineId int, configType ConfigType) (*ConfigmapAndSecretHistory, error) {
 	var history ConfigmapAndSecretHistory
 	err := impl.dbConnection.Model(&history).Where("id = ?", id).
@@ -110,3 +123,46 @@ func (impl ConfigMapHistoryRepositoryImpl) GetDeployedHistoryList(pipelineId, ba
 	}
 	return histories, nil
 }
+
+func (impl ConfigMapHistoryRepositoryImpl) GetLatestHistoryId() (int, error) {
+	var id int
+	query := "SELECT COALESCE(MAX(id), 0) FROM config_map_history;"
+	_, err := impl.dbConnection.Query(&id, query)
+	if err != nil {
+		impl.logger.Errorw("error in getting latest configmap/secret history id", "err", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetLatestSavedHistoryByAppIdAndPipelineId returns the latest non deployment history entry not newer than maxHistoryId,
+// pipelineId = 0 points to the app level configs
+func (impl ConfigMapHistoryRepositoryImpl) GetLatestSavedHistoryByAppIdAndPipelineId(appId, pipelineId int, configType ConfigType, maxHistoryId int) (*ConfigmapAndSecretHistory, error) {
+	var history ConfigmapAndSecretHistory
+	query := impl.dbConnection.Model(&history).
+		Where("app_id = ?", appId).
+		Where("data_type = ?", configType).
+		Where("deployed = ?", false).
+		Where("id <= ?", maxHistoryId)
+	if pipelineId > 0 {
+		query = query.Where("pipeline_id = ?", pipelineId)
+	} else {
+		query = query.Where("(pipeline_id IS NULL OR pipeline_id = 0)")
+	}
+	err := query.Order("id DESC").Limit(1).Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting latest saved configmap/secret history", "err", err, "appId", appId, "pipelineId", pipelineId)
+		return &history, err
+	}
+	return &history, nil
+}
+
+func (impl ConfigMapHistoryRepositoryImpl) GetHistoryById(id int) (*ConfigmapAndSecretHistory, error) {
+	var history ConfigmapAndSecretHistory
+	err := impl.dbConnection.Model(&history).Where("id = ?", id).Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting configmap/secret history by id", "err", err, "id", id)
+		return &history, err
+	}
+	return &history, nil
+}
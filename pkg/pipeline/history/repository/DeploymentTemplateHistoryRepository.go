@@ -14,6 +14,9 @@ type DeploymentTemplateHistoryRepository interface {
 	GetDeploymentDetailsForDeployedTemplateHistory(pipelineId, offset, limit int) ([]*DeploymentTemplateHistory, error)
 	GetHistoryByPipelineIdAndWfrId(pipelineId, wfrId int) (*DeploymentTemplateHistory, error)
 	GetDeployedHistoryList(pipelineId, baseConfigId int) ([]*DeploymentTemplateHistory, error)
+	GetLatestHistoryId() (int, error)
+	GetHistoryById(id int) (*DeploymentTemplateHistory, error)
+	GetLatestSavedHistoryByAppIdAndEnvId(appId, envId, maxHistoryId int) (*DeploymentTemplateHistory, error)
 }
 
 type DeploymentTemplateHistoryRepositoryImpl struct {
@@ -116,3 +119,46 @@ func (impl DeploymentTemplateHistoryRepositoryImpl) GetDeployedHistoryList(pipel
 	}
 	return histories, nil
 }
+
+func (impl DeploymentTemplateHistoryRepositoryImpl) GetLatestHistoryId() (int, error) {
+	var id int
+	query := "SELECT COALESCE(MAX(id), 0) FROM deployment_template_history;"
+	_, err := impl.dbConnection.Query(&id, query)
+	if err != nil {
+		impl.logger.Errorw("error in getting latest deployment template history id", "err", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetLatestSavedHistoryByAppIdAndEnvId returns the latest non deployment history entry not newer than maxHistoryId,
+// envId = 0 points to the app level template
+func (impl DeploymentTemplateHistoryRepositoryImpl) GetLatestSavedHistoryByAppIdAndEnvId(appId, envId, maxHistoryId int) (*DeploymentTemplateHistory, error) {
+	var history DeploymentTemplateHistory
+	query := impl.dbConnection.Model(&history).
+		Where("app_id = ?", appId).
+		Where("deployed = ?", false).
+		Where("id <= ?", maxHistoryId)
+	if envId > 0 {
+		query = query.Where("target_environment = ?", envId)
+	} else {
+		query = query.Where("(pipeline_id IS NULL OR pipeline_id = 0)").
+			Where("(target_environment IS NULL OR target_environment = 0)")
+	}
+	err := query.Order("id DESC").Limit(1).Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting latest saved deployment template history", "err", err, "appId", appId, "envId", envId)
+		return &history, err
+	}
+	return &history, nil
+}
+
+func (impl DeploymentTemplateHistoryRepositoryImpl) GetHistoryById(id int) (*DeploymentTemplateHistory, error) {
+	var history DeploymentTemplateHistory
+	err := impl.dbConnection.Model(&history).Where("id = ?", id).Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment template history by id", "err", err, "id", id)
+		return &history, err
+	}
+	return &history, nil
+}
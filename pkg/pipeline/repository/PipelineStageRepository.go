@@ -142,8 +142,15 @@ type PipelineStageRepository interface {
 	GetAllCiStagesByCiPipelineId(ciPipelineId int) ([]*PipelineStage, error)
 	GetCiStageByCiPipelineIdAndStageType(ciPipelineId int, stageType PipelineStageType) (*PipelineStage, error)
 
+	CreateCdStageWithTxn(cdStage *PipelineStage, tx *pg.Tx) (*PipelineStage, error)
+	MarkCdStageDeletedById(cdStageId int, updatedBy int32, tx *pg.Tx) error
+	GetAllCdStagesByCdPipelineId(cdPipelineId int) ([]*PipelineStage, error)
+	GetCdStageByCdPipelineIdAndStageType(cdPipelineId int, stageType PipelineStageType) (*PipelineStage, error)
+	GetCdPipelineIdsHavingStageSteps(cdPipelineIds []int, stageType PipelineStageType) ([]int, error)
+
 	GetStepIdsByStageId(stageId int) ([]int, error)
 	CreatePipelineStageStep(step *PipelineStageStep) (*PipelineStageStep, error)
+	CreatePipelineStageStepWithTxn(step *PipelineStageStep, tx *pg.Tx) (*PipelineStageStep, error)
 	UpdatePipelineStageStep(step *PipelineStageStep) (*PipelineStageStep, error)
 	MarkCiStageStepsDeletedByStageId(ciStageId int, updatedBy int32, tx *pg.Tx) error
 	GetAllStepsByStageId(stageId int) ([]*PipelineStageStep, error)
@@ -152,6 +159,7 @@ type PipelineStageRepository interface {
 	MarkStepsDeletedExcludingActiveStepsInUpdateReq(activeStepIdsPresentInReq []int, stageId int) error
 
 	CreatePipelineScript(pipelineScript *PluginPipelineScript) (*PluginPipelineScript, error)
+	CreatePipelineScriptWithTxn(pipelineScript *PluginPipelineScript, tx *pg.Tx) (*PluginPipelineScript, error)
 	UpdatePipelineScript(pipelineScript *PluginPipelineScript) (*PluginPipelineScript, error)
 	GetScriptIdsByStageId(stageId int) ([]int, error)
 	MarkPipelineScriptsDeletedByIds(ids []int, updatedBy int32, tx *pg.Tx) error
@@ -160,6 +168,7 @@ type PipelineStageRepository interface {
 
 	MarkScriptMappingDeletedByScriptId(scriptId int) error
 	CreateScriptMapping(mappings []ScriptPathArgPortMapping) error
+	CreateScriptMappingWithTxn(mappings []ScriptPathArgPortMapping, tx *pg.Tx) error
 	GetScriptMappingIdsByStageId(stageId int) ([]int, error)
 	MarkPipelineScriptMappingsDeletedByIds(ids []int, updatedBy int32, tx *pg.Tx) error
 	GetScriptMappingDetailByScriptId(scriptId int) ([]*ScriptPathArgPortMapping, error)
@@ -258,6 +267,68 @@ func (impl *PipelineStageRepositoryImpl) MarkCiStageDeletedById(ciStageId int, u
 	return nil
 }
 
+func (impl *PipelineStageRepositoryImpl) GetAllCdStagesByCdPipelineId(cdPipelineId int) ([]*PipelineStage, error) {
+	var pipelineStages []*PipelineStage
+	err := impl.dbConnection.Model(&pipelineStages).
+		Where("cd_pipeline_id = ?", cdPipelineId).
+		Where("deleted = ?", false).Select()
+	if err != nil {
+		impl.logger.Errorw("err in getting all cd stages by cdPipelineId", "err", err, "cdPipelineId", cdPipelineId)
+		return nil, err
+	}
+	return pipelineStages, nil
+}
+
+func (impl *PipelineStageRepositoryImpl) GetCdStageByCdPipelineIdAndStageType(cdPipelineId int, stageType PipelineStageType) (*PipelineStage, error) {
+	var pipelineStage PipelineStage
+	err := impl.dbConnection.Model(&pipelineStage).
+		Where("cd_pipeline_id = ?", cdPipelineId).
+		Where("type = ?", stageType).
+		Where("deleted = ?", false).Select()
+	if err != nil {
+		impl.logger.Errorw("err in getting cd stage by cdPipelineId", "err", err, "cdPipelineId", cdPipelineId)
+		return nil, err
+	}
+	return &pipelineStage, nil
+}
+
+// GetCdPipelineIdsHavingStageSteps returns the cd pipelines, out of the given ones, having at least one active step in
+// their stage of the given type
+func (impl *PipelineStageRepositoryImpl) GetCdPipelineIdsHavingStageSteps(cdPipelineIds []int, stageType PipelineStageType) ([]int, error) {
+	var ids []int
+	if len(cdPipelineIds) == 0 {
+		return ids, nil
+	}
+	query := "SELECT DISTINCT ps.cd_pipeline_id FROM pipeline_stage ps INNER JOIN pipeline_stage_step pss ON pss.pipeline_stage_id = ps.id" +
+		" WHERE ps.cd_pipeline_id IN (?) AND ps.type = ? AND ps.deleted = false AND pss.deleted = false;"
+	_, err := impl.dbConnection.Query(&ids, query, pg.In(cdPipelineIds), stageType)
+	if err != nil {
+		impl.logger.Errorw("err in getting cd pipelines having stage steps", "err", err, "cdPipelineIds", cdPipelineIds, "stageType", stageType)
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (impl *PipelineStageRepositoryImpl) CreateCdStageWithTxn(cdStage *PipelineStage, tx *pg.Tx) (*PipelineStage, error) {
+	err := tx.Insert(cdStage)
+	if err != nil {
+		impl.logger.Errorw("error in creating cd stage entry", "err", err, "cdStage", cdStage)
+		return nil, err
+	}
+	return cdStage, nil
+}
+
+func (impl *PipelineStageRepositoryImpl) MarkCdStageDeletedById(cdStageId int, updatedBy int32, tx *pg.Tx) error {
+	var stage PipelineStage
+	_, err := tx.Model(&stage).Set("deleted = ?", true).Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", updatedBy).Where("id = ?", cdStageId).Update()
+	if err != nil {
+		impl.logger.Errorw("error in marking cd stage deleted", "err", err, "cdStageId", cdStageId)
+		return err
+	}
+	return nil
+}
+
 func (impl *PipelineStageRepositoryImpl) GetStepIdsByStageId(stageId int) ([]int, error) {
 	var ids []int
 	query := "SELECT pss.id from pipeline_stage_step pss where pss.pipeline_stage_id = ? and pss.deleted = false"
@@ -278,6 +349,15 @@ func (impl *PipelineStageRepositoryImpl) CreatePipelineStageStep(step *PipelineS
 	return step, nil
 }
 
+func (impl *PipelineStageRepositoryImpl) CreatePipelineStageStepWithTxn(step *PipelineStageStep, tx *pg.Tx) (*PipelineStageStep, error) {
+	err := tx.Insert(step)
+	if err != nil {
+		impl.logger.Errorw("error in creating pipeline stage step", "err", err, "step", step)
+		return nil, err
+	}
+	return step, nil
+}
+
 func (impl *PipelineStageRepositoryImpl) UpdatePipelineStageStep(step *PipelineStageStep) (*PipelineStageStep, error) {
 	err := impl.dbConnection.Update(step)
 	if err != nil {
@@ -355,6 +435,15 @@ func (impl *PipelineStageRepositoryImpl) CreatePipelineScript(pipelineScript *Pl
 	return pipelineScript, nil
 }
 
+func (impl *PipelineStageRepositoryImpl) CreatePipelineScriptWithTxn(pipelineScript *PluginPipelineScript, tx *pg.Tx) (*PluginPipelineScript, error) {
+	err := tx.Insert(pipelineScript)
+	if err != nil {
+		impl.logger.Errorw("error in creating pipeline script", "err", err, "scriptEntry", pipelineScript)
+		return nil, err
+	}
+	return pipelineScript, nil
+}
+
 func (impl *PipelineStageRepositoryImpl) UpdatePipelineScript(pipelineScript *PluginPipelineScript) (*PluginPipelineScript, error) {
 	err := impl.dbConnection.Update(pipelineScript)
 	if err != nil {
@@ -434,6 +523,15 @@ func (impl *PipelineStageRepositoryImpl) CreateScriptMapping(mappings []ScriptPa
 	return nil
 }
 
+func (impl *PipelineStageRepositoryImpl) CreateScriptMappingWithTxn(mappings []ScriptPathArgPortMapping, tx *pg.Tx) error {
+	err := tx.Insert(&mappings)
+	if err != nil {
+		impl.logger.Errorw("error in creating pipeline script mappings", "err", err, "mappings", mappings)
+		return err
+	}
+	return nil
+}
+
 func (impl *PipelineStageRepositoryImpl) GetScriptMappingIdsByStageId(stageId int) ([]int, error) {
 	var ids []int
 	query := "SELECT spapm.id from script_path_arg_port_mapping spapm INNER JOIN plugin_pipeline_script pps ON pps.id = spapm.script_id " +
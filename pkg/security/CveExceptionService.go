@@ -0,0 +1,351 @@
+package security
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/caarlos0/env"
+	client "github.com/devtron-labs/devtron/client/events"
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/security"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user"
+	util2 "github.com/devtron-labs/devtron/util/event"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const cveExceptionSystemUserId = int32(1)
+
+type CveExceptionConfig struct {
+	MaxValidityDays  int `env:"CVE_EXCEPTION_MAX_VALIDITY_DAYS" envDefault:"90"`
+	ExpiryNotifyDays int `env:"CVE_EXCEPTION_EXPIRY_NOTIFY_DAYS" envDefault:"7"`
+}
+
+func GetCveExceptionConfig() (*CveExceptionConfig, error) {
+	cfg := &CveExceptionConfig{}
+	err := env.Parse(cfg)
+	return cfg, err
+}
+
+type CveExceptionService interface {
+	RequestException(request *CveExceptionDto) (*CveExceptionDto, error)
+	// ReviewException approves or rejects a pending exception, the requester can not review their own exception
+	ReviewException(request *CveExceptionReviewDto) (*CveExceptionDto, error)
+	RevokeException(id int, userId int32) (*CveExceptionDto, error)
+	GetException(id int) (*CveExceptionDto, error)
+	GetExceptions(filter *security.CveExceptionFilter) ([]*CveExceptionDto, error)
+	// GetExpiringExceptions returns approved exceptions which expire within the given number of days
+	GetExpiringExceptions(days int) ([]*CveExceptionDto, error)
+	NotifyExpiringExceptions()
+}
+
+type CveExceptionServiceImpl struct {
+	logger                 *zap.SugaredLogger
+	cfg                    *CveExceptionConfig
+	cveExceptionRepository security.CveExceptionRepository
+	cveStoreRepository     security.CveStoreRepository
+	appRepository          app.AppRepository
+	environmentService     cluster.EnvironmentService
+	pipelineRepository     pipelineConfig.PipelineRepository
+	userService            user.UserService
+	eventFactory           client.EventFactory
+	eventClient            client.EventClient
+}
+
+func NewCveExceptionServiceImpl(logger *zap.SugaredLogger,
+	cfg *CveExceptionConfig,
+	cveExceptionRepository security.CveExceptionRepository,
+	cveStoreRepository security.CveStoreRepository,
+	appRepository app.AppRepository,
+	environmentService cluster.EnvironmentService,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	userService user.UserService,
+	eventFactory client.EventFactory,
+	eventClient client.EventClient) *CveExceptionServiceImpl {
+	return &CveExceptionServiceImpl{
+		logger:                 logger,
+		cfg:                    cfg,
+		cveExceptionRepository: cveExceptionRepository,
+		cveStoreRepository:     cveStoreRepository,
+		appRepository:          appRepository,
+		environmentService:     environmentService,
+		pipelineRepository:     pipelineRepository,
+		userService:            userService,
+		eventFactory:           eventFactory,
+		eventClient:            eventClient,
+	}
+}
+
+type CveExceptionDto struct {
+	Id            int                         `json:"id"`
+	CveName       string                      `json:"cveName" validate:"required"`
+	AppId         int                         `json:"appId" validate:"required"`
+	EnvId         int                         `json:"envId" validate:"required"`
+	AppName       string                      `json:"appName,omitempty"`
+	EnvName       string                      `json:"envName,omitempty"`
+	Justification string                      `json:"justification" validate:"required"`
+	ExpiresOn     time.Time                   `json:"expiresOn" validate:"required"`
+	Status        security.CveExceptionStatus `json:"status"`
+	Expired       bool                        `json:"expired"`
+	RequestedBy   string                      `json:"requestedBy,omitempty"`
+	RequestedOn   time.Time                   `json:"requestedOn"`
+	ReviewedBy    string                      `json:"reviewedBy,omitempty"`
+	ReviewedOn    *time.Time                  `json:"reviewedOn,omitempty"`
+	ReviewComment string                      `json:"reviewComment,omitempty"`
+	UserId        int32                       `json:"-"`
+}
+
+type CveExceptionReviewDto struct {
+	Id      int    `json:"id" validate:"required"`
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment"`
+	UserId  int32  `json:"-"`
+}
+
+func (impl *CveExceptionServiceImpl) RequestException(request *CveExceptionDto) (*CveExceptionDto, error) {
+	if !request.ExpiresOn.After(time.Now()) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "expiry in past", UserMessage: "expiry must be in the future"}
+	}
+	maxExpiry := time.Now().AddDate(0, 0, impl.cfg.MaxValidityDays)
+	if request.ExpiresOn.After(maxExpiry) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "expiry beyond max validity",
+			UserMessage: fmt.Sprintf("exception can not be valid for more than %d days", impl.cfg.MaxValidityDays)}
+	}
+	_, err := impl.cveStoreRepository.FindByName(request.CveName)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "cve not found", UserMessage: fmt.Sprintf("cve %s not found", request.CveName)}
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching cve", "err", err, "cveName", request.CveName)
+		return nil, err
+	}
+	openExceptions, err := impl.cveExceptionRepository.FindOpenByScope(request.CveName, request.AppId, request.EnvId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching open cve exceptions", "err", err, "cveName", request.CveName, "appId", request.AppId, "envId", request.EnvId)
+		return nil, err
+	}
+	if len(openExceptions) > 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "exception already exists",
+			UserMessage: fmt.Sprintf("a pending or active exception already exists for %s", request.CveName)}
+	}
+	exception := &security.CveException{
+		CveStoreName:  request.CveName,
+		AppId:         request.AppId,
+		EnvironmentId: request.EnvId,
+		Justification: request.Justification,
+		Status:        security.CVE_EXCEPTION_STATUS_PENDING,
+		RequestedBy:   request.UserId,
+		ExpiresOn:     request.ExpiresOn,
+		AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	}
+	err = impl.cveExceptionRepository.Save(exception)
+	if err != nil {
+		impl.logger.Errorw("error in saving cve exception", "err", err, "cveName", request.CveName)
+		return nil, err
+	}
+	return impl.buildExceptionDtos([]*security.CveException{exception})[0], nil
+}
+
+func (impl *CveExceptionServiceImpl) ReviewException(request *CveExceptionReviewDto) (*CveExceptionDto, error) {
+	exception, err := impl.getException(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if exception.Status != security.CVE_EXCEPTION_STATUS_PENDING {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "exception not pending", UserMessage: fmt.Sprintf("exception is already %s", exception.Status)}
+	}
+	if exception.RequestedBy == request.UserId {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "self review", UserMessage: "exception can not be reviewed by its requester"}
+	}
+	if request.Approve && !exception.ExpiresOn.After(time.Now()) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "exception expired", UserMessage: "exception has already expired"}
+	}
+	if request.Approve {
+		exception.Status = security.CVE_EXCEPTION_STATUS_APPROVED
+	} else {
+		exception.Status = security.CVE_EXCEPTION_STATUS_REJECTED
+	}
+	exception.ReviewedBy = request.UserId
+	exception.ReviewedOn = time.Now()
+	exception.ReviewComment = request.Comment
+	exception.UpdatedOn = time.Now()
+	exception.UpdatedBy = request.UserId
+	err = impl.cveExceptionRepository.Update(exception)
+	if err != nil {
+		impl.logger.Errorw("error in updating cve exception", "err", err, "id", exception.Id)
+		return nil, err
+	}
+	return impl.buildExceptionDtos([]*security.CveException{exception})[0], nil
+}
+
+func (impl *CveExceptionServiceImpl) RevokeException(id int, userId int32) (*CveExceptionDto, error) {
+	exception, err := impl.getException(id)
+	if err != nil {
+		return nil, err
+	}
+	if exception.Status != security.CVE_EXCEPTION_STATUS_PENDING && exception.Status != security.CVE_EXCEPTION_STATUS_APPROVED {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "exception not open", UserMessage: fmt.Sprintf("exception is already %s", exception.Status)}
+	}
+	exception.Status = security.CVE_EXCEPTION_STATUS_REVOKED
+	exception.UpdatedOn = time.Now()
+	exception.UpdatedBy = userId
+	err = impl.cveExceptionRepository.Update(exception)
+	if err != nil {
+		impl.logger.Errorw("error in revoking cve exception", "err", err, "id", exception.Id)
+		return nil, err
+	}
+	return impl.buildExceptionDtos([]*security.CveException{exception})[0], nil
+}
+
+func (impl *CveExceptionServiceImpl) GetException(id int) (*CveExceptionDto, error) {
+	exception, err := impl.getException(id)
+	if err != nil {
+		return nil, err
+	}
+	return impl.buildExceptionDtos([]*security.CveException{exception})[0], nil
+}
+
+func (impl *CveExceptionServiceImpl) GetExceptions(filter *security.CveExceptionFilter) ([]*CveExceptionDto, error) {
+	exceptions, err := impl.cveExceptionRepository.FindByFilter(filter)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cve exceptions", "err", err, "filter", filter)
+		return nil, err
+	}
+	return impl.buildExceptionDtos(exceptions), nil
+}
+
+func (impl *CveExceptionServiceImpl) GetExpiringExceptions(days int) ([]*CveExceptionDto, error) {
+	if days <= 0 {
+		days = impl.cfg.ExpiryNotifyDays
+	}
+	exceptions, err := impl.cveExceptionRepository.FindActiveExpiringBefore(time.Now().AddDate(0, 0, days))
+	if err != nil {
+		impl.logger.Errorw("error in fetching expiring cve exceptions", "err", err, "days", days)
+		return nil, err
+	}
+	return impl.buildExceptionDtos(exceptions), nil
+}
+
+func (impl *CveExceptionServiceImpl) NotifyExpiringExceptions() {
+	exceptions, err := impl.cveExceptionRepository.FindActiveExpiringBefore(time.Now().AddDate(0, 0, impl.cfg.ExpiryNotifyDays))
+	if err != nil {
+		impl.logger.Errorw("error in fetching expiring cve exceptions", "err", err)
+		return
+	}
+	for _, exception := range exceptions {
+		if exception.ExpiryNotified {
+			continue
+		}
+		err = impl.sendExpiryNotification(exception)
+		if err != nil {
+			// left un-notified so that the next run retries it
+			impl.logger.Errorw("error in sending cve exception expiry notification", "err", err, "id", exception.Id)
+			continue
+		}
+		exception.ExpiryNotified = true
+		exception.UpdatedOn = time.Now()
+		exception.UpdatedBy = cveExceptionSystemUserId
+		err = impl.cveExceptionRepository.Update(exception)
+		if err != nil {
+			impl.logger.Errorw("error in marking cve exception expiry notified", "err", err, "id", exception.Id)
+		}
+	}
+}
+
+// sendExpiryNotification raises the event for the cd pipeline of the exception, it errors when the app has no cd pipeline
+// on the environment or the event could not be written
+func (impl *CveExceptionServiceImpl) sendExpiryNotification(exception *security.CveException) error {
+	// notification settings are configured on pipelines, so the event is raised for the cd pipeline of the app on the environment
+	pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(exception.AppId, exception.EnvironmentId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd pipeline for cve exception expiry notification", "err", err, "id", exception.Id)
+		return err
+	}
+	if len(pipelines) == 0 {
+		return fmt.Errorf("no cd pipeline found for app %d on environment %d", exception.AppId, exception.EnvironmentId)
+	}
+	cdPipeline := pipelines[0]
+	event := impl.eventFactory.Build(util2.CveExceptionExpiring, &cdPipeline.Id, exception.AppId, &exception.EnvironmentId, util2.CD)
+	event.UserId = int(cveExceptionSystemUserId)
+	event.Payload = &client.Payload{
+		CveName:   exception.CveStoreName,
+		ExpiresOn: exception.ExpiresOn.Format(time.RFC1123),
+	}
+	_, err = impl.eventClient.WriteNotificationEvent(event)
+	return err
+}
+
+func (impl *CveExceptionServiceImpl) getException(id int) (*security.CveException, error) {
+	exception, err := impl.cveExceptionRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "cve exception not found", UserMessage: "cve exception not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching cve exception", "err", err, "id", id)
+		return nil, err
+	}
+	return exception, nil
+}
+
+// buildExceptionDtos resolves app, environment and user names, lookup failures only leave the names empty
+func (impl *CveExceptionServiceImpl) buildExceptionDtos(exceptions []*security.CveException) []*CveExceptionDto {
+	appNames := make(map[int]string)
+	envNames := make(map[int]string)
+	userEmails := make(map[int32]string)
+	var userIds []int32
+	for _, exception := range exceptions {
+		if _, ok := appNames[exception.AppId]; !ok {
+			appNames[exception.AppId] = ""
+			if app, err := impl.appRepository.FindById(exception.AppId); err == nil {
+				appNames[exception.AppId] = app.AppName
+			}
+		}
+		if _, ok := envNames[exception.EnvironmentId]; !ok {
+			envNames[exception.EnvironmentId] = ""
+			if env, err := impl.environmentService.FindById(exception.EnvironmentId); err == nil {
+				envNames[exception.EnvironmentId] = env.Environment
+			}
+		}
+		userIds = append(userIds, exception.RequestedBy)
+		if exception.ReviewedBy > 0 {
+			userIds = append(userIds, exception.ReviewedBy)
+		}
+	}
+	if len(userIds) > 0 {
+		users, err := impl.userService.GetByIds(userIds)
+		if err != nil {
+			impl.logger.Errorw("error in fetching users of cve exceptions", "err", err)
+		}
+		for _, user := range users {
+			userEmails[user.Id] = user.EmailId
+		}
+	}
+	dtos := make([]*CveExceptionDto, 0, len(exceptions))
+	for _, exception := range exceptions {
+		dto := &CveExceptionDto{
+			Id:            exception.Id,
+			CveName:       exception.CveStoreName,
+			AppId:         exception.AppId,
+			EnvId:         exception.EnvironmentId,
+			AppName:       appNames[exception.AppId],
+			EnvName:       envNames[exception.EnvironmentId],
+			Justification: exception.Justification,
+			ExpiresOn:     exception.ExpiresOn,
+			Status:        exception.Status,
+			Expired:       !exception.ExpiresOn.After(time.Now()),
+			RequestedBy:   userEmails[exception.RequestedBy],
+			RequestedOn:   exception.CreatedOn,
+			ReviewedBy:    userEmails[exception.ReviewedBy],
+			ReviewComment: exception.ReviewComment,
+		}
+		if !exception.ReviewedOn.IsZero() {
+			reviewedOn := exception.ReviewedOn
+			dto.ReviewedOn = &reviewedOn
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos
+}
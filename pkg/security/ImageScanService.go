@@ -18,6 +18,7 @@
 package security
 
 import (
+	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	repository2 "github.com/devtron-labs/devtron/pkg/team"
 	"time"
@@ -54,6 +55,7 @@ type ImageScanServiceImpl struct {
 	policyService                 PolicyService
 	pipelineRepository            pipelineConfig.PipelineRepository
 	ciPipelineRepository          pipelineConfig.CiPipelineRepository
+	sbomRepository                security.SbomRepository
 }
 
 type ImageScanRequest struct {
@@ -125,7 +127,8 @@ func NewImageScanServiceImpl(Logger *zap.SugaredLogger, scanHistoryRepository se
 	userService user.UserService, teamRepository repository2.TeamRepository,
 	appRepository app.AppRepository,
 	envService cluster.EnvironmentService, ciArtifactRepository repository.CiArtifactRepository, policyService PolicyService,
-	pipelineRepository pipelineConfig.PipelineRepository, ciPipelineRepository pipelineConfig.CiPipelineRepository) *ImageScanServiceImpl {
+	pipelineRepository pipelineConfig.PipelineRepository, ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	sbomRepository security.SbomRepository) *ImageScanServiceImpl {
 	return &ImageScanServiceImpl{Logger: Logger, scanHistoryRepository: scanHistoryRepository, scanResultRepository: scanResultRepository,
 		scanObjectMetaRepository: scanObjectMetaRepository, cveStoreRepository: cveStoreRepository,
 		imageScanDeployInfoRepository: imageScanDeployInfoRepository,
@@ -137,6 +140,7 @@ func NewImageScanServiceImpl(Logger *zap.SugaredLogger, scanHistoryRepository se
 		policyService:                 policyService,
 		pipelineRepository:            pipelineRepository,
 		ciPipelineRepository:          ciPipelineRepository,
+		sbomRepository:                sbomRepository,
 	}
 }
 
@@ -527,6 +531,21 @@ func (impl ImageScanServiceImpl) VulnerabilityExposure(request *security.Vulnera
 	}
 
 	cveStores = append(cveStores, cveStore)
+	deployedPackageVersions := make(map[string]string)
+	if len(cveStore.Package) > 0 {
+		deployedPackages, err := impl.sbomRepository.FindDeployedPackages(&security.SbomPackageSearchRequest{
+			PackageName: cveStore.Package,
+			EnvIds:      request.EnvIds,
+			ClusterIds:  request.ClusterIds,
+		})
+		if err != nil {
+			impl.Logger.Errorw("error while fetching deployed sbom packages", "err", err, "package", cveStore.Package)
+			return nil, err
+		}
+		for _, deployedPackage := range deployedPackages {
+			deployedPackageVersions[fmt.Sprintf("%d-%d", deployedPackage.AppId, deployedPackage.EnvId)] = deployedPackage.PackageVersion
+		}
+	}
 	for _, item := range vulnerabilityExposureList {
 		envId := 0
 		if item.AppStore {
@@ -545,6 +564,10 @@ func (impl ImageScanServiceImpl) VulnerabilityExposure(request *security.Vulnera
 		if len(blockCveList) > 0 {
 			item.Blocked = true
 		}
+		if packageVersion, ok := deployedPackageVersions[fmt.Sprintf("%d-%d", item.AppId, envId)]; ok && !item.AppStore {
+			item.ContainsPackage = true
+			item.PackageVersion = packageVersion
+		}
 	}
 	vulnerabilityExposureListingResponse.VulnerabilityExposure = vulnerabilityExposureList
 	return vulnerabilityExposureListingResponse, nil
@@ -24,6 +24,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/internal/sql/repository/security"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
 	"github.com/go-pg/pg"
@@ -66,6 +68,8 @@ type PolicyServiceImpl struct {
 	scanHistoryRepository         security.ImageScanHistoryRepository
 	cveStoreRepository            security.CveStoreRepository
 	ciTemplateRepository          pipelineConfig.CiTemplateRepository
+	cveExceptionRepository        security.CveExceptionRepository
+	auditLogService               auditLog.AuditLogService
 }
 
 func NewPolicyServiceImpl(environmentService cluster.EnvironmentService,
@@ -80,7 +84,8 @@ func NewPolicyServiceImpl(environmentService cluster.EnvironmentService,
 	imageScanObjectMetaRepository security.ImageScanObjectMetaRepository, client *http.Client,
 	ciArtifactRepository repository.CiArtifactRepository, ciConfig *pipeline.CiConfig,
 	scanHistoryRepository security.ImageScanHistoryRepository, cveStoreRepository security.CveStoreRepository,
-	ciTemplateRepository pipelineConfig.CiTemplateRepository) *PolicyServiceImpl {
+	ciTemplateRepository pipelineConfig.CiTemplateRepository, cveExceptionRepository security.CveExceptionRepository,
+	auditLogService auditLog.AuditLogService) *PolicyServiceImpl {
 	return &PolicyServiceImpl{
 		environmentService:            environmentService,
 		logger:                        logger,
@@ -98,6 +103,8 @@ func NewPolicyServiceImpl(environmentService cluster.EnvironmentService,
 		scanHistoryRepository:         scanHistoryRepository,
 		cveStoreRepository:            cveStoreRepository,
 		ciTemplateRepository:          ciTemplateRepository,
+		cveExceptionRepository:        cveExceptionRepository,
+		auditLogService:               auditLogService,
 	}
 }
 
@@ -365,7 +372,19 @@ func (impl *PolicyServiceImpl) GetApplicablePolicy(clusterId, envId, appId int,
 	}
 
 	cvePolicy, severityPolicy, err := impl.getPolicies(policyLevel, clusterId, envId, appId)
-	return cvePolicy, severityPolicy, err
+	if err != nil {
+		return nil, nil, err
+	}
+	if appId > 0 && envId > 0 {
+		// approved exceptions which have not expired allow the cve for the app on the environment
+		exceptions, err := impl.cveExceptionRepository.FindActiveByAppAndEnv(appId, envId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching active cve exceptions", "err", err, "appId", appId, "envId", envId)
+			return nil, nil, err
+		}
+		security.ApplyCveExceptions(cvePolicy, exceptions)
+	}
+	return cvePolicy, severityPolicy, nil
 }
 func (impl *PolicyServiceImpl) getApplicablePolicies(policies []*security.CvePolicy) (map[string]*security.CvePolicy, map[security.Severity]*security.CvePolicy) {
 	cvePolicy := make(map[string][]*security.CvePolicy)
@@ -493,6 +512,7 @@ func (impl *PolicyServiceImpl) SavePolicy(request bean.CreateVulnerabilityPolicy
 		impl.logger.Errorw("error in saving policy", "err", err)
 		return nil, fmt.Errorf("error in saving policy")
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_POLICY, strconv.Itoa(policy.Id), auditLog.AUDIT_ACTION_CREATE, nil, policy, userId)
 	return &bean.IdVulnerabilityPolicyResult{Id: policy.Id}, nil
 }
 
@@ -513,6 +533,7 @@ func (impl *PolicyServiceImpl) UpdatePolicy(updatePolicyParams bean.UpdatePolicy
 			impl.logger.Errorw("error in fetching policy ", "id", updatePolicyParams.Id)
 			return nil, err
 		}
+		before := *policy
 		policy.Action = policyAction
 		policy.UpdatedOn = time.Now()
 		policy.UpdatedBy = userId
@@ -520,6 +541,7 @@ func (impl *PolicyServiceImpl) UpdatePolicy(updatePolicyParams bean.UpdatePolicy
 		if err != nil {
 			return nil, err
 		} else {
+			impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_POLICY, strconv.Itoa(policy.Id), auditLog.AUDIT_ACTION_UPDATE, before, policy, userId)
 			return &bean.IdVulnerabilityPolicyResult{Id: policy.Id}, nil
 		}
 	}
@@ -538,6 +560,7 @@ func (impl *PolicyServiceImpl) DeletePolicy(id int, userId int32) (*bean.IdVulne
 	if policy.Global && policy.CVEStoreId == "" {
 		return nil, fmt.Errorf("global severity policy can't be changed to inherit")
 	}
+	before := *policy
 	policy.Deleted = true
 	policy.UpdatedOn = time.Now()
 	policy.UpdatedBy = userId
@@ -545,6 +568,7 @@ func (impl *PolicyServiceImpl) DeletePolicy(id int, userId int32) (*bean.IdVulne
 	if err != nil {
 		return nil, err
 	} else {
+		impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_POLICY, strconv.Itoa(policy.Id), auditLog.AUDIT_ACTION_DELETE, before, nil, userId)
 		return &bean.IdVulnerabilityPolicyResult{Id: policy.Id}, nil
 	}
 }
@@ -0,0 +1,303 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	casbin2 "github.com/devtron-labs/devtron/pkg/user/casbin"
+	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+var customRoleNameRegex = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+// reservedRoleActions are the actions of the default and entity roles, a custom role is assigned through the action
+// of a role filter so it can't take one of these names
+var reservedRoleActions = map[string]bool{
+	string(repository2.MANAGER_TYPE): true,
+	string(repository2.ADMIN_TYPE):   true,
+	string(repository2.TRIGGER_TYPE): true,
+	string(repository2.VIEW_TYPE):    true,
+	"update":                         true,
+	"edit":                           true,
+	"super-admin":                    true,
+}
+
+// rbacActions are the actions custom roles and deny rules can be defined with
+var rbacActions = map[string]bool{
+	"*":                   true,
+	casbin2.ActionGet:     true,
+	casbin2.ActionCreate:  true,
+	casbin2.ActionUpdate:  true,
+	casbin2.ActionDelete:  true,
+	casbin2.ActionSync:    true,
+	casbin2.ActionTrigger: true,
+	casbin2.ActionNotify:  true,
+	casbin2.ActionExec:    true,
+}
+
+type CustomRoleDto struct {
+	Id          int                                 `json:"id"`
+	Name        string                              `json:"name" validate:"required,max=100"`
+	Description string                              `json:"description"`
+	Permissions []*repository2.CustomRolePermission `json:"permissions" validate:"required,min=1,dive"`
+	UserId      int32                               `json:"-"`
+}
+
+type CustomRoleService interface {
+	CreateCustomRole(request *CustomRoleDto) (*CustomRoleDto, error)
+	// UpdateCustomRole updates the permissions of the custom role and of the roles already created for it
+	UpdateCustomRole(request *CustomRoleDto) (*CustomRoleDto, error)
+	GetCustomRole(id int) (*CustomRoleDto, error)
+	GetAllCustomRoles() ([]*CustomRoleDto, error)
+	// DeleteCustomRole deletes the roles created for the custom role, removing them from users and groups
+	DeleteCustomRole(id int, userId int32) error
+}
+
+type CustomRoleServiceImpl struct {
+	logger               *zap.SugaredLogger
+	customRoleRepository repository2.CustomRoleRepository
+	userAuthRepository   repository2.UserAuthRepository
+	roleGroupRepository  repository2.RoleGroupRepository
+	enforcer             casbin2.Enforcer
+}
+
+func NewCustomRoleServiceImpl(logger *zap.SugaredLogger,
+	customRoleRepository repository2.CustomRoleRepository,
+	userAuthRepository repository2.UserAuthRepository,
+	roleGroupRepository repository2.RoleGroupRepository,
+	enforcer casbin2.Enforcer) *CustomRoleServiceImpl {
+	return &CustomRoleServiceImpl{
+		logger:               logger,
+		customRoleRepository: customRoleRepository,
+		userAuthRepository:   userAuthRepository,
+		roleGroupRepository:  roleGroupRepository,
+		enforcer:             enforcer,
+	}
+}
+
+func (impl CustomRoleServiceImpl) CreateCustomRole(request *CustomRoleDto) (*CustomRoleDto, error) {
+	if !customRoleNameRegex.MatchString(request.Name) || reservedRoleActions[request.Name] {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid custom role name",
+			UserMessage: "custom role name must be lowercase alphanumerics separated by '-' and not a default role name"}
+	}
+	err := impl.validatePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := impl.customRoleRepository.FindActiveByName(request.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching custom role", "err", err, "name", request.Name)
+		return nil, err
+	}
+	if existing.Id > 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "custom role exists",
+			UserMessage: fmt.Sprintf("custom role %s already exists", request.Name)}
+	}
+	customRole := &repository2.CustomRole{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: request.Permissions,
+		Active:      true,
+		AuditLog:    sql.AuditLog{CreatedBy: request.UserId, CreatedOn: time.Now(), UpdatedBy: request.UserId, UpdatedOn: time.Now()},
+	}
+	err = impl.customRoleRepository.Save(customRole)
+	if err != nil {
+		impl.logger.Errorw("error in saving custom role", "err", err, "name", request.Name)
+		return nil, err
+	}
+	return adaptCustomRole(customRole), nil
+}
+
+func (impl CustomRoleServiceImpl) UpdateCustomRole(request *CustomRoleDto) (*CustomRoleDto, error) {
+	customRole, err := impl.getCustomRole(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Name != customRole.Name {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "custom role name changed",
+			UserMessage: "custom role name can not be changed"}
+	}
+	err = impl.validatePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	roleModels, err := impl.getCustomRoleModels(customRole.Name)
+	if err != nil {
+		return nil, err
+	}
+	oldPermissions := customRole.Permissions
+	customRole.Description = request.Description
+	customRole.Permissions = request.Permissions
+	customRole.UpdatedBy = request.UserId
+	customRole.UpdatedOn = time.Now()
+	dbConnection := impl.customRoleRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.customRoleRepository.Update(customRole, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating custom role", "err", err, "id", customRole.Id)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	// policies are synced only once the permissions are saved, a failed update leaves casbin as it was
+	for _, roleModel := range roleModels {
+		rolePolicyDetails := repository2.GetRolePolicyDetails(roleModel.Team, roleModel.EntityName, roleModel.Environment)
+		oldPolicies, err := repository2.GetCustomRolePolicies(roleModel.Role, oldPermissions, rolePolicyDetails)
+		if err != nil {
+			impl.logger.Errorw("error in getting custom role policies", "err", err, "role", roleModel.Role)
+			return nil, err
+		}
+		newPolicies, err := repository2.GetCustomRolePolicies(roleModel.Role, request.Permissions, rolePolicyDetails)
+		if err != nil {
+			impl.logger.Errorw("error in getting custom role policies", "err", err, "role", roleModel.Role)
+			return nil, err
+		}
+		casbin2.RemovePolicy(oldPolicies)
+		casbin2.AddPolicy(newPolicies)
+	}
+	// policies are updated on the roles, members of the roles are cached by their own email
+	impl.enforcer.InvalidateCompleteCache()
+	return adaptCustomRole(customRole), nil
+}
+
+func (impl CustomRoleServiceImpl) GetCustomRole(id int) (*CustomRoleDto, error) {
+	customRole, err := impl.getCustomRole(id)
+	if err != nil {
+		return nil, err
+	}
+	return adaptCustomRole(customRole), nil
+}
+
+func (impl CustomRoleServiceImpl) GetAllCustomRoles() ([]*CustomRoleDto, error) {
+	customRoles, err := impl.customRoleRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching custom roles", "err", err)
+		return nil, err
+	}
+	result := make([]*CustomRoleDto, 0, len(customRoles))
+	for _, customRole := range customRoles {
+		result = append(result, adaptCustomRole(customRole))
+	}
+	return result, nil
+}
+
+func (impl CustomRoleServiceImpl) DeleteCustomRole(id int, userId int32) error {
+	customRole, err := impl.getCustomRole(id)
+	if err != nil {
+		return err
+	}
+	roleModels, err := impl.getCustomRoleModels(customRole.Name)
+	if err != nil {
+		return err
+	}
+	dbConnection := impl.customRoleRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	for _, roleModel := range roleModels {
+		//deleting user_roles for this role_id (foreign key constraint)
+		err = impl.userAuthRepository.DeleteUserRoleByRoleId(roleModel.Id, tx)
+		if err != nil {
+			impl.logger.Errorw("error in deleting user_roles by role id", "err", err, "roleId", roleModel.Id)
+			return err
+		}
+		//deleting role_group_role_mapping for this role_id (foreign key constraint)
+		err = impl.roleGroupRepository.DeleteRoleGroupRoleMappingByRoleId(roleModel.Id, tx)
+		if err != nil {
+			impl.logger.Errorw("error in deleting role_group_role_mapping by role id", "err", err, "roleId", roleModel.Id)
+			return err
+		}
+		err = impl.userAuthRepository.DeleteRole(roleModel, tx)
+		if err != nil {
+			impl.logger.Errorw("error in deleting role of custom role", "err", err, "role", roleModel.Role)
+			return err
+		}
+	}
+	customRole.Active = false
+	customRole.UpdatedBy = userId
+	customRole.UpdatedOn = time.Now()
+	err = impl.customRoleRepository.Update(customRole, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting custom role", "err", err, "id", id)
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	// policies are removed only once the roles are deleted, a failed delete leaves the roles working as before
+	for _, roleModel := range roleModels {
+		success := casbin2.RemovePoliciesByRoles(roleModel.Role)
+		if !success {
+			impl.logger.Warnw("error in deleting casbin policy for role", "role", roleModel.Role)
+		}
+	}
+	impl.enforcer.InvalidateCompleteCache()
+	return nil
+}
+
+func (impl CustomRoleServiceImpl) getCustomRole(id int) (*repository2.CustomRole, error) {
+	customRole, err := impl.customRoleRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "custom role not found", UserMessage: "custom role not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching custom role", "err", err, "id", id)
+		return nil, err
+	}
+	return customRole, nil
+}
+
+// getCustomRoleModels returns the roles created for the custom role on teams, envs and apps
+func (impl CustomRoleServiceImpl) getCustomRoleModels(name string) ([]*repository2.RoleModel, error) {
+	roleModels, err := impl.userAuthRepository.GetRolesByActionAndAccessType(name, "")
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching roles of custom role", "err", err, "name", name)
+		return nil, err
+	}
+	var result []*repository2.RoleModel
+	for i := range roleModels {
+		if len(roleModels[i].Entity) == 0 {
+			result = append(result, &roleModels[i])
+		}
+	}
+	return result, nil
+}
+
+func (impl CustomRoleServiceImpl) validatePermissions(permissions []*repository2.CustomRolePermission) error {
+	for _, permission := range permissions {
+		if _, ok := repository2.ScopedResourceObjectTemplates[permission.Resource]; !ok || permission.Resource == casbin2.ResourceSecret {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "unsupported resource",
+				UserMessage: fmt.Sprintf("resource %s is not supported in custom roles", permission.Resource)}
+		}
+		if !rbacActions[permission.Action] {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "unsupported action",
+				UserMessage: fmt.Sprintf("action %s is not supported", permission.Action)}
+		}
+	}
+	return nil
+}
+
+func adaptCustomRole(customRole *repository2.CustomRole) *CustomRoleDto {
+	return &CustomRoleDto{
+		Id:          customRole.Id,
+		Name:        customRole.Name,
+		Description: customRole.Description,
+		Permissions: customRole.Permissions,
+	}
+}
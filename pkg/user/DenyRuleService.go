@@ -0,0 +1,224 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	casbin2 "github.com/devtron-labs/devtron/pkg/user/casbin"
+	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
+	util2 "github.com/devtron-labs/devtron/util"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+// DenyRuleDto subject is the email of a user or the name of a role group, team, environment and app left empty
+// match all
+type DenyRuleDto struct {
+	Id          int                             `json:"id"`
+	SubjectType repository2.DenyRuleSubjectType `json:"subjectType" validate:"oneof=USER GROUP"`
+	Subject     string                          `json:"subject" validate:"required"`
+	Resource    string                          `json:"resource" validate:"required"`
+	Action      string                          `json:"action" validate:"required"`
+	Team        string                          `json:"team"`
+	Environment string                          `json:"environment"`
+	EntityName  string                          `json:"entityName"`
+	Object      string                          `json:"object,omitempty"`
+	Description string                          `json:"description"`
+	UserId      int32                           `json:"-"`
+}
+
+type DenyRuleService interface {
+	// CreateDenyRule adds a deny policy in casbin which overrides the roles of the subject allowing the same action
+	CreateDenyRule(request *DenyRuleDto) (*DenyRuleDto, error)
+	GetDenyRule(id int) (*DenyRuleDto, error)
+	GetAllDenyRules() ([]*DenyRuleDto, error)
+	DeleteDenyRule(id int, userId int32) error
+}
+
+type DenyRuleServiceImpl struct {
+	logger              *zap.SugaredLogger
+	denyRuleRepository  repository2.DenyRuleRepository
+	userRepository      repository2.UserRepository
+	roleGroupRepository repository2.RoleGroupRepository
+	enforcer            casbin2.Enforcer
+}
+
+func NewDenyRuleServiceImpl(logger *zap.SugaredLogger,
+	denyRuleRepository repository2.DenyRuleRepository,
+	userRepository repository2.UserRepository,
+	roleGroupRepository repository2.RoleGroupRepository,
+	enforcer casbin2.Enforcer) *DenyRuleServiceImpl {
+	return &DenyRuleServiceImpl{
+		logger:              logger,
+		denyRuleRepository:  denyRuleRepository,
+		userRepository:      userRepository,
+		roleGroupRepository: roleGroupRepository,
+		enforcer:            enforcer,
+	}
+}
+
+func (impl DenyRuleServiceImpl) CreateDenyRule(request *DenyRuleDto) (*DenyRuleDto, error) {
+	objTemplate, ok := repository2.ScopedResourceObjectTemplates[request.Resource]
+	if !ok {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "unsupported resource",
+			UserMessage: fmt.Sprintf("resource %s is not supported in deny rules", request.Resource)}
+	}
+	if !rbacActions[request.Action] {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "unsupported action",
+			UserMessage: fmt.Sprintf("action %s is not supported", request.Action)}
+	}
+	casbinSubject, err := impl.getCasbinSubject(request.SubjectType, request.Subject)
+	if err != nil {
+		return nil, err
+	}
+	object, err := util2.Tprintf(objTemplate, repository2.GetRolePolicyDetails(request.Team, request.EntityName, request.Environment))
+	if err != nil {
+		impl.logger.Errorw("error in building deny rule object", "err", err, "resource", request.Resource)
+		return nil, err
+	}
+	denyRule := &repository2.DenyRule{
+		SubjectType:   request.SubjectType,
+		Subject:       request.Subject,
+		CasbinSubject: casbinSubject,
+		Resource:      request.Resource,
+		Action:        request.Action,
+		Team:          request.Team,
+		Environment:   request.Environment,
+		EntityName:    request.EntityName,
+		Object:        strings.ToLower(object),
+		Description:   request.Description,
+		Active:        true,
+		AuditLog:      sql.AuditLog{CreatedBy: request.UserId, CreatedOn: time.Now(), UpdatedBy: request.UserId, UpdatedOn: time.Now()},
+	}
+	err = impl.denyRuleRepository.Save(denyRule)
+	if err != nil {
+		impl.logger.Errorw("error in saving deny rule", "err", err, "subject", request.Subject)
+		return nil, err
+	}
+	failed := casbin2.AddPolicy([]casbin2.Policy{getDenyRulePolicy(denyRule)})
+	if len(failed) > 0 {
+		// same rule is already in casbin through another deny rule
+		impl.logger.Warnw("deny policy not added", "denyRuleId", denyRule.Id, "policy", failed)
+	}
+	// groups are cached by the email of their members
+	impl.enforcer.InvalidateCompleteCache()
+	return adaptDenyRule(denyRule), nil
+}
+
+func (impl DenyRuleServiceImpl) GetDenyRule(id int) (*DenyRuleDto, error) {
+	denyRule, err := impl.getDenyRule(id)
+	if err != nil {
+		return nil, err
+	}
+	return adaptDenyRule(denyRule), nil
+}
+
+func (impl DenyRuleServiceImpl) GetAllDenyRules() ([]*DenyRuleDto, error) {
+	denyRules, err := impl.denyRuleRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching deny rules", "err", err)
+		return nil, err
+	}
+	result := make([]*DenyRuleDto, 0, len(denyRules))
+	for _, denyRule := range denyRules {
+		result = append(result, adaptDenyRule(denyRule))
+	}
+	return result, nil
+}
+
+func (impl DenyRuleServiceImpl) DeleteDenyRule(id int, userId int32) error {
+	denyRule, err := impl.getDenyRule(id)
+	if err != nil {
+		return err
+	}
+	denyRule.Active = false
+	denyRule.UpdatedBy = userId
+	denyRule.UpdatedOn = time.Now()
+	err = impl.denyRuleRepository.Update(denyRule)
+	if err != nil {
+		impl.logger.Errorw("error in deleting deny rule", "err", err, "id", id)
+		return err
+	}
+	// the policy stays while another active rule denies the same
+	activeRules, err := impl.denyRuleRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching deny rules", "err", err)
+		return err
+	}
+	policy := getDenyRulePolicy(denyRule)
+	for _, activeRule := range activeRules {
+		if getDenyRulePolicy(activeRule) == policy {
+			return nil
+		}
+	}
+	casbin2.RemovePolicy([]casbin2.Policy{policy})
+	impl.enforcer.InvalidateCompleteCache()
+	return nil
+}
+
+func (impl DenyRuleServiceImpl) getDenyRule(id int) (*repository2.DenyRule, error) {
+	denyRule, err := impl.denyRuleRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "deny rule not found", UserMessage: "deny rule not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching deny rule", "err", err, "id", id)
+		return nil, err
+	}
+	return denyRule, nil
+}
+
+// getCasbinSubject returns the email of the user or the casbin name of the role group
+func (impl DenyRuleServiceImpl) getCasbinSubject(subjectType repository2.DenyRuleSubjectType, subject string) (string, error) {
+	switch subjectType {
+	case repository2.DENY_RULE_SUBJECT_TYPE_USER:
+		user, err := impl.userRepository.FetchActiveUserByEmail(subject)
+		if err != nil {
+			impl.logger.Errorw("error in fetching user", "err", err, "emailId", subject)
+			return "", err
+		}
+		if user.Id == 0 {
+			return "", &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "user not found", UserMessage: "user not found"}
+		}
+		return strings.ToLower(user.EmailId), nil
+	case repository2.DENY_RULE_SUBJECT_TYPE_GROUP:
+		roleGroup, err := impl.roleGroupRepository.GetRoleGroupByName(subject)
+		if err == pg.ErrNoRows {
+			return "", &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "role group not found", UserMessage: "role group not found"}
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching role group", "err", err, "name", subject)
+			return "", err
+		}
+		return strings.ToLower(roleGroup.CasbinName), nil
+	}
+	return "", &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "invalid subject type", UserMessage: "subject type must be USER or GROUP"}
+}
+
+func getDenyRulePolicy(denyRule *repository2.DenyRule) casbin2.Policy {
+	return casbin2.Policy{
+		Type: "p",
+		Sub:  casbin2.Subject(denyRule.CasbinSubject),
+		Res:  casbin2.Resource(denyRule.Resource),
+		Act:  casbin2.Action(denyRule.Action),
+		Obj:  casbin2.Object(denyRule.Object),
+		Eft:  casbin2.EffectDeny,
+	}
+}
+
+func adaptDenyRule(denyRule *repository2.DenyRule) *DenyRuleDto {
+	return &DenyRuleDto{
+		Id:          denyRule.Id,
+		SubjectType: denyRule.SubjectType,
+		Subject:     denyRule.Subject,
+		Resource:    denyRule.Resource,
+		Action:      denyRule.Action,
+		Team:        denyRule.Team,
+		Environment: denyRule.Environment,
+		EntityName:  denyRule.EntityName,
+		Object:      denyRule.Object,
+		Description: denyRule.Description,
+	}
+}
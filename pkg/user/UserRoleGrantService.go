@@ -0,0 +1,590 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	casbin2 "github.com/devtron-labs/devtron/pkg/user/casbin"
+	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const userRoleGrantSystemUserId = int32(1)
+
+type UserRoleGrantConfig struct {
+	MaxDurationMinutes int  `env:"USER_ROLE_GRANT_MAX_DURATION_MINUTES" envDefault:"1440"`
+	ApprovalRequired   bool `env:"USER_ROLE_GRANT_APPROVAL_REQUIRED" envDefault:"false"`
+}
+
+func GetUserRoleGrantConfig() (*UserRoleGrantConfig, error) {
+	cfg := &UserRoleGrantConfig{}
+	err := env.Parse(cfg)
+	return cfg, err
+}
+
+type UserRoleGrantService interface {
+	// RequestGrant activates the grant right away when the requester can assign the role filter and no approval
+	// is needed, otherwise the grant waits for a super admin to approve it
+	RequestGrant(request *UserRoleGrantRequest) (*UserRoleGrantDto, error)
+	ReviewGrant(request *UserRoleGrantReviewRequest) (*UserRoleGrantDto, error)
+	RevokeGrant(id int, userId int32, comment string) (*UserRoleGrantDto, error)
+	GetGrant(id int) (*UserRoleGrantDto, error)
+	GetGrants(userId int32, status repository2.UserRoleGrantStatus) ([]*UserRoleGrantDto, error)
+	GetGrantAudits(id int) ([]*UserRoleGrantAuditDto, error)
+	// ExpireGrants removes the roles of active grants whose expiry has passed
+	ExpireGrants()
+}
+
+type UserRoleGrantServiceImpl struct {
+	logger                  *zap.SugaredLogger
+	cfg                     *UserRoleGrantConfig
+	userRoleGrantRepository repository2.UserRoleGrantRepository
+	userAuthRepository      repository2.UserAuthRepository
+	userRepository          repository2.UserRepository
+	enforcer                casbin2.Enforcer
+}
+
+func NewUserRoleGrantServiceImpl(logger *zap.SugaredLogger,
+	cfg *UserRoleGrantConfig,
+	userRoleGrantRepository repository2.UserRoleGrantRepository,
+	userAuthRepository repository2.UserAuthRepository,
+	userRepository repository2.UserRepository,
+	enforcer casbin2.Enforcer) *UserRoleGrantServiceImpl {
+	return &UserRoleGrantServiceImpl{
+		logger:                  logger,
+		cfg:                     cfg,
+		userRoleGrantRepository: userRoleGrantRepository,
+		userAuthRepository:      userAuthRepository,
+		userRepository:          userRepository,
+		enforcer:                enforcer,
+	}
+}
+
+type UserRoleGrantRequest struct {
+	UserId          int32           `json:"userId" validate:"required"`
+	RoleFilter      bean.RoleFilter `json:"roleFilter"`
+	DurationMinutes int             `json:"durationMinutes" validate:"required,min=1"`
+	Reason          string          `json:"reason" validate:"required"`
+	RequireApproval bool            `json:"requireApproval"`
+	RequestedBy     int32           `json:"-"`
+	// CanGrant is set when the requester is allowed to assign the role filter without approval
+	CanGrant bool `json:"-"`
+}
+
+type UserRoleGrantReviewRequest struct {
+	Id         int    `json:"id" validate:"required"`
+	Approve    bool   `json:"approve"`
+	Comment    string `json:"comment"`
+	ReviewedBy int32  `json:"-"`
+}
+
+type UserRoleGrantDto struct {
+	Id               int                             `json:"id"`
+	UserId           int32                           `json:"userId"`
+	EmailId          string                          `json:"emailId"`
+	RoleFilter       bean.RoleFilter                 `json:"roleFilter"`
+	Reason           string                          `json:"reason"`
+	DurationMinutes  int                             `json:"durationMinutes"`
+	Status           repository2.UserRoleGrantStatus `json:"status"`
+	RequiresApproval bool                            `json:"requiresApproval"`
+	RequestedBy      int32                           `json:"requestedBy"`
+	RequestedOn      time.Time                       `json:"requestedOn"`
+	ReviewedBy       int32                           `json:"reviewedBy,omitempty"`
+	ReviewedOn       *time.Time                      `json:"reviewedOn,omitempty"`
+	ActivatedOn      *time.Time                      `json:"activatedOn,omitempty"`
+	ExpiresOn        *time.Time                      `json:"expiresOn,omitempty"`
+	RevokedOn        *time.Time                      `json:"revokedOn,omitempty"`
+}
+
+type UserRoleGrantAuditDto struct {
+	Action      repository2.UserRoleGrantAuditAction `json:"action"`
+	PerformedBy int32                                `json:"performedBy"`
+	Comment     string                               `json:"comment,omitempty"`
+	CreatedOn   time.Time                            `json:"createdOn"`
+}
+
+func (impl UserRoleGrantServiceImpl) RequestGrant(request *UserRoleGrantRequest) (*UserRoleGrantDto, error) {
+	if request.DurationMinutes > impl.cfg.MaxDurationMinutes {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "duration beyond max",
+			UserMessage: fmt.Sprintf("role can not be granted for more than %d minutes", impl.cfg.MaxDurationMinutes)}
+	}
+	if len(request.RoleFilter.Action) == 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "action missing", UserMessage: "role filter action is required"}
+	}
+	user, err := impl.userRepository.GetById(request.UserId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "user not found", UserMessage: "user not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching user", "err", err, "userId", request.UserId)
+		return nil, err
+	}
+	requiresApproval := request.RequireApproval || impl.cfg.ApprovalRequired || !request.CanGrant
+	grant := &repository2.UserRoleGrant{
+		UserId:           user.Id,
+		Entity:           request.RoleFilter.Entity,
+		Team:             request.RoleFilter.Team,
+		EntityName:       request.RoleFilter.EntityName,
+		Environment:      request.RoleFilter.Environment,
+		Action:           request.RoleFilter.Action,
+		AccessType:       request.RoleFilter.AccessType,
+		Reason:           request.Reason,
+		DurationMinutes:  request.DurationMinutes,
+		Status:           repository2.USER_ROLE_GRANT_STATUS_PENDING,
+		RequiresApproval: requiresApproval,
+		RequestedBy:      request.RequestedBy,
+		AuditLog:         sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.RequestedBy, UpdatedOn: time.Now(), UpdatedBy: request.RequestedBy},
+	}
+	tx, err := impl.userRoleGrantRepository.GetConnection().Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.userRoleGrantRepository.Save(grant, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving user role grant", "err", err, "userId", user.Id)
+		return nil, err
+	}
+	err = impl.saveAudit(grant, repository2.USER_ROLE_GRANT_AUDIT_REQUESTED, request.RequestedBy, request.Reason, tx)
+	if err != nil {
+		return nil, err
+	}
+	var policies []casbin2.Policy
+	if !requiresApproval {
+		policies, err = impl.activateGrant(grant, user, request.RequestedBy, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	impl.syncPolicies(user.EmailId, policies, nil)
+	return adaptUserRoleGrant(grant, user.EmailId), nil
+}
+
+func (impl UserRoleGrantServiceImpl) ReviewGrant(request *UserRoleGrantReviewRequest) (*UserRoleGrantDto, error) {
+	grant, err := impl.getGrant(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != repository2.USER_ROLE_GRANT_STATUS_PENDING {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "grant not pending", UserMessage: fmt.Sprintf("grant is already %s", grant.Status)}
+	}
+	if grant.RequestedBy == request.ReviewedBy || grant.UserId == request.ReviewedBy {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "self review", UserMessage: "grant can not be reviewed by its requester or grantee"}
+	}
+	user, err := impl.userRepository.GetById(grant.UserId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching user", "err", err, "userId", grant.UserId)
+		return nil, err
+	}
+	tx, err := impl.userRoleGrantRepository.GetConnection().Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	policies, err := impl.reviewGrant(grant, user, request, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	impl.syncPolicies(user.EmailId, policies, nil)
+	return adaptUserRoleGrant(grant, user.EmailId), nil
+}
+
+func (impl UserRoleGrantServiceImpl) RevokeGrant(id int, userId int32, comment string) (*UserRoleGrantDto, error) {
+	grant, err := impl.getGrant(id)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != repository2.USER_ROLE_GRANT_STATUS_PENDING && grant.Status != repository2.USER_ROLE_GRANT_STATUS_ACTIVE {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "grant not open", UserMessage: fmt.Sprintf("grant is already %s", grant.Status)}
+	}
+	return impl.endGrant(grant, repository2.USER_ROLE_GRANT_STATUS_REVOKED, repository2.USER_ROLE_GRANT_AUDIT_REVOKED, userId, comment)
+}
+
+func (impl UserRoleGrantServiceImpl) GetGrant(id int) (*UserRoleGrantDto, error) {
+	grant, err := impl.getGrant(id)
+	if err != nil {
+		return nil, err
+	}
+	user, err := impl.userRepository.GetByIdIncludeDeleted(grant.UserId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching user", "err", err, "userId", grant.UserId)
+		return nil, err
+	}
+	return adaptUserRoleGrant(grant, user.EmailId), nil
+}
+
+func (impl UserRoleGrantServiceImpl) GetGrants(userId int32, status repository2.UserRoleGrantStatus) ([]*UserRoleGrantDto, error) {
+	grants, err := impl.userRoleGrantRepository.FindByUserIdAndStatus(userId, status)
+	if err != nil {
+		impl.logger.Errorw("error in fetching user role grants", "err", err, "userId", userId, "status", status)
+		return nil, err
+	}
+	var userIds []int32
+	for _, grant := range grants {
+		userIds = append(userIds, grant.UserId)
+	}
+	emailIds := make(map[int32]string)
+	if len(userIds) > 0 {
+		users, err := impl.userRepository.GetByIds(userIds)
+		if err != nil {
+			impl.logger.Errorw("error in fetching users of grants", "err", err)
+			return nil, err
+		}
+		for _, user := range users {
+			emailIds[user.Id] = user.EmailId
+		}
+	}
+	grantDtos := make([]*UserRoleGrantDto, 0, len(grants))
+	for _, grant := range grants {
+		grantDtos = append(grantDtos, adaptUserRoleGrant(grant, emailIds[grant.UserId]))
+	}
+	return grantDtos, nil
+}
+
+func (impl UserRoleGrantServiceImpl) GetGrantAudits(id int) ([]*UserRoleGrantAuditDto, error) {
+	audits, err := impl.userRoleGrantRepository.FindAuditsByGrantId(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching user role grant audits", "err", err, "id", id)
+		return nil, err
+	}
+	auditDtos := make([]*UserRoleGrantAuditDto, 0, len(audits))
+	for _, audit := range audits {
+		auditDtos = append(auditDtos, &UserRoleGrantAuditDto{
+			Action:      audit.Action,
+			PerformedBy: audit.PerformedBy,
+			Comment:     audit.Comment,
+			CreatedOn:   audit.CreatedOn,
+		})
+	}
+	return auditDtos, nil
+}
+
+func (impl UserRoleGrantServiceImpl) ExpireGrants() {
+	grants, err := impl.userRoleGrantRepository.FindActiveExpiredBefore(time.Now())
+	if err != nil {
+		impl.logger.Errorw("error in fetching expired user role grants", "err", err)
+		return
+	}
+	for _, grant := range grants {
+		_, err = impl.endGrant(grant, repository2.USER_ROLE_GRANT_STATUS_EXPIRED, repository2.USER_ROLE_GRANT_AUDIT_EXPIRED, userRoleGrantSystemUserId, "")
+		if apiErr, ok := err.(*util.ApiError); ok && apiErr.HttpStatusCode == http.StatusConflict {
+			// already ended by a revocation or by the cron of another replica
+			continue
+		} else if err != nil {
+			impl.logger.Errorw("error in expiring user role grant", "err", err, "id", grant.Id)
+		}
+	}
+}
+
+// reviewGrant approves or rejects the pending grant within the tx, it fails with a conflict when the grant was
+// reviewed or revoked concurrently
+func (impl UserRoleGrantServiceImpl) reviewGrant(grant *repository2.UserRoleGrant, user *repository2.UserModel, request *UserRoleGrantReviewRequest, tx *pg.Tx) ([]casbin2.Policy, error) {
+	grant.ReviewedBy = request.ReviewedBy
+	grant.ReviewedOn = time.Now()
+	if request.Approve {
+		err := impl.saveAudit(grant, repository2.USER_ROLE_GRANT_AUDIT_APPROVED, request.ReviewedBy, request.Comment, tx)
+		if err != nil {
+			return nil, err
+		}
+		return impl.activateGrant(grant, user, request.ReviewedBy, tx)
+	}
+	grant.Status = repository2.USER_ROLE_GRANT_STATUS_REJECTED
+	grant.UpdatedOn = time.Now()
+	grant.UpdatedBy = request.ReviewedBy
+	err := impl.updateGrant(grant, repository2.USER_ROLE_GRANT_STATUS_PENDING, tx)
+	if err != nil {
+		return nil, err
+	}
+	return nil, impl.saveAudit(grant, repository2.USER_ROLE_GRANT_AUDIT_REJECTED, request.ReviewedBy, request.Comment, tx)
+}
+
+// activateGrant maps the roles of the grant which the user does not have yet and returns the casbin policies to add
+func (impl UserRoleGrantServiceImpl) activateGrant(grant *repository2.UserRoleGrant, user *repository2.UserModel, userId int32, tx *pg.Tx) ([]casbin2.Policy, error) {
+	roles, err := impl.resolveRoles(grant, tx)
+	if err != nil {
+		return nil, err
+	}
+	userRoles, err := impl.userAuthRepository.GetUserRoleMappingByUserId(user.Id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching user roles", "err", err, "userId", user.Id)
+		return nil, err
+	}
+	existingRoleIds := make(map[int]bool)
+	for _, userRole := range userRoles {
+		existingRoleIds[userRole.RoleId] = true
+	}
+	var policies []casbin2.Policy
+	var roleIds []int
+	for _, role := range roles {
+		if existingRoleIds[role.Id] {
+			continue
+		}
+		existingRoleIds[role.Id] = true
+		_, err = impl.userAuthRepository.CreateUserRoleMapping(&repository2.UserRoleModel{UserId: user.Id, RoleId: role.Id}, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating user role mapping", "err", err, "userId", user.Id, "roleId", role.Id)
+			return nil, err
+		}
+		roleIds = append(roleIds, role.Id)
+		policies = append(policies, casbin2.Policy{Type: "g", Sub: casbin2.Subject(user.EmailId), Obj: casbin2.Object(role.Role)})
+	}
+	grant.RoleIds = roleIds
+	grant.Status = repository2.USER_ROLE_GRANT_STATUS_ACTIVE
+	grant.ActivatedOn = time.Now()
+	grant.ExpiresOn = grant.ActivatedOn.Add(time.Duration(grant.DurationMinutes) * time.Minute)
+	grant.UpdatedOn = time.Now()
+	grant.UpdatedBy = userId
+	// role ids of a concurrently activated grant are left as is, this tx is rolled back along with its role mappings
+	err = impl.updateGrant(grant, repository2.USER_ROLE_GRANT_STATUS_PENDING, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = impl.saveAudit(grant, repository2.USER_ROLE_GRANT_AUDIT_ACTIVATED, userId, fmt.Sprintf("expires on %s", grant.ExpiresOn.Format(time.RFC3339)), tx)
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// endGrant removes the roles mapped by the grant unless another active grant of the user holds the same role
+func (impl UserRoleGrantServiceImpl) endGrant(grant *repository2.UserRoleGrant, status repository2.UserRoleGrantStatus,
+	auditAction repository2.UserRoleGrantAuditAction, userId int32, comment string) (*UserRoleGrantDto, error) {
+	user, err := impl.userRepository.GetByIdIncludeDeleted(grant.UserId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching user", "err", err, "userId", grant.UserId)
+		return nil, err
+	}
+	var policies []casbin2.Policy
+	var eliminatedUserRoles []*repository2.UserRoleModel
+	if grant.Status == repository2.USER_ROLE_GRANT_STATUS_ACTIVE && len(grant.RoleIds) > 0 {
+		activeGrants, err := impl.userRoleGrantRepository.FindByUserIdAndStatus(grant.UserId, repository2.USER_ROLE_GRANT_STATUS_ACTIVE)
+		if err != nil {
+			impl.logger.Errorw("error in fetching active user role grants", "err", err, "userId", grant.UserId)
+			return nil, err
+		}
+		heldRoleIds := make(map[int]bool)
+		for _, activeGrant := range activeGrants {
+			if activeGrant.Id == grant.Id {
+				continue
+			}
+			for _, roleId := range activeGrant.RoleIds {
+				heldRoleIds[roleId] = true
+			}
+		}
+		grantRoleIds := make(map[int]bool)
+		for _, roleId := range grant.RoleIds {
+			if !heldRoleIds[roleId] {
+				grantRoleIds[roleId] = true
+			}
+		}
+		userRoles, err := impl.userAuthRepository.GetUserRoleMappingByUserId(grant.UserId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching user roles", "err", err, "userId", grant.UserId)
+			return nil, err
+		}
+		for _, userRole := range userRoles {
+			if !grantRoleIds[userRole.RoleId] {
+				continue
+			}
+			role, err := impl.userAuthRepository.GetRoleById(userRole.RoleId)
+			if err != nil {
+				impl.logger.Errorw("error in fetching role", "err", err, "roleId", userRole.RoleId)
+				return nil, err
+			}
+			eliminatedUserRoles = append(eliminatedUserRoles, userRole)
+			policies = append(policies, casbin2.Policy{Type: "g", Sub: casbin2.Subject(user.EmailId), Obj: casbin2.Object(role.Role)})
+		}
+	}
+	tx, err := impl.userRoleGrantRepository.GetConnection().Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.closeGrant(grant, eliminatedUserRoles, status, auditAction, userId, comment, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	impl.syncPolicies(user.EmailId, nil, policies)
+	return adaptUserRoleGrant(grant, user.EmailId), nil
+}
+
+// closeGrant ends the grant and deletes the given role mappings within the tx, it fails with a conflict when the
+// grant was ended or reviewed concurrently
+func (impl UserRoleGrantServiceImpl) closeGrant(grant *repository2.UserRoleGrant, userRoles []*repository2.UserRoleModel, status repository2.UserRoleGrantStatus,
+	auditAction repository2.UserRoleGrantAuditAction, userId int32, comment string, tx *pg.Tx) error {
+	currentStatus := grant.Status
+	grant.Status = status
+	grant.RevokedOn = time.Now()
+	grant.UpdatedOn = time.Now()
+	grant.UpdatedBy = userId
+	err := impl.updateGrant(grant, currentStatus, tx)
+	if err != nil {
+		return err
+	}
+	for _, userRole := range userRoles {
+		_, err = impl.userAuthRepository.DeleteUserRoleMapping(userRole, tx)
+		if err != nil {
+			impl.logger.Errorw("error in deleting user role mapping", "err", err, "userId", grant.UserId, "roleId", userRole.RoleId)
+			return err
+		}
+	}
+	return impl.saveAudit(grant, auditAction, userId, comment, tx)
+}
+
+// updateGrant saves the grant only while it is still in currentStatus
+func (impl UserRoleGrantServiceImpl) updateGrant(grant *repository2.UserRoleGrant, currentStatus repository2.UserRoleGrantStatus, tx *pg.Tx) error {
+	updated, err := impl.userRoleGrantRepository.UpdateIfStatus(grant, currentStatus, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating user role grant", "err", err, "id", grant.Id)
+		return err
+	}
+	if !updated {
+		return &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: "grant updated concurrently", UserMessage: "role grant was updated in the meantime, please refresh and retry"}
+	}
+	return nil
+}
+
+// resolveRoles finds the roles of the role filter of the grant, creating the default roles of a team or
+// chart group the same way user creation does
+func (impl UserRoleGrantServiceImpl) resolveRoles(grant *repository2.UserRoleGrant, tx *pg.Tx) ([]repository2.RoleModel, error) {
+	var roles []repository2.RoleModel
+	for _, environment := range splitRoleFilterValue(grant.Environment) {
+		for _, entityName := range splitRoleFilterValue(grant.EntityName) {
+			roleModel, err := impl.userAuthRepository.GetRoleByFilter(grant.Entity, grant.Team, entityName, environment, grant.Action, grant.AccessType)
+			if err != nil {
+				impl.logger.Errorw("error in fetching role by filter", "err", err, "grantId", grant.Id)
+				return nil, err
+			}
+			if roleModel.Id == 0 && (len(grant.Team) > 0 || grant.Entity == repository2.CHART_GROUP_TYPE) {
+				var flag bool
+				if len(grant.Team) > 0 && grant.AccessType == bean.APP_ACCESS_TYPE_HELM {
+					flag, err = impl.userAuthRepository.CreateDefaultHelmPolicies(grant.Team, entityName, environment, tx)
+				} else if len(grant.Team) > 0 {
+					flag, err = impl.userAuthRepository.CreateDefaultPolicies(grant.Team, entityName, environment, tx)
+				} else {
+					flag, err = impl.userAuthRepository.CreateDefaultPoliciesForGlobalEntity(grant.Entity, entityName, grant.Action, tx)
+				}
+				if err != nil || !flag {
+					impl.logger.Errorw("error in creating default policies", "err", err, "grantId", grant.Id)
+					return nil, fmt.Errorf("error in creating default policies for role filter")
+				}
+				roleModel, err = impl.userAuthRepository.GetRoleByFilter(grant.Entity, grant.Team, entityName, environment, grant.Action, grant.AccessType)
+				if err != nil {
+					impl.logger.Errorw("error in fetching role by filter", "err", err, "grantId", grant.Id)
+					return nil, err
+				}
+			}
+			if roleModel.Id == 0 {
+				return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "role not found",
+					UserMessage: "role not found for given filter: " + grant.Team + "," + environment + "," + entityName + "," + grant.Action}
+			}
+			roles = append(roles, roleModel)
+		}
+	}
+	return roles, nil
+}
+
+func (impl UserRoleGrantServiceImpl) syncPolicies(emailId string, addedPolicies []casbin2.Policy, removedPolicies []casbin2.Policy) {
+	if len(addedPolicies) > 0 {
+		casbin2.AddPolicy(addedPolicies)
+	}
+	if len(removedPolicies) > 0 {
+		casbin2.RemovePolicy(removedPolicies)
+	}
+	impl.enforcer.InvalidateCache(emailId)
+}
+
+func (impl UserRoleGrantServiceImpl) saveAudit(grant *repository2.UserRoleGrant, action repository2.UserRoleGrantAuditAction, userId int32, comment string, tx *pg.Tx) error {
+	audit := &repository2.UserRoleGrantAudit{
+		GrantId:     grant.Id,
+		UserId:      grant.UserId,
+		Action:      action,
+		PerformedBy: userId,
+		Comment:     comment,
+		CreatedOn:   time.Now(),
+	}
+	err := impl.userRoleGrantRepository.SaveAudit(audit, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving user role grant audit", "err", err, "grantId", grant.Id, "action", action)
+	}
+	return err
+}
+
+func (impl UserRoleGrantServiceImpl) getGrant(id int) (*repository2.UserRoleGrant, error) {
+	grant, err := impl.userRoleGrantRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "grant not found", UserMessage: "role grant not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching user role grant", "err", err, "id", id)
+		return nil, err
+	}
+	return grant, nil
+}
+
+func splitRoleFilterValue(value string) []string {
+	if len(value) == 0 {
+		return []string{""}
+	}
+	return strings.Split(value, ",")
+}
+
+func adaptUserRoleGrant(grant *repository2.UserRoleGrant, emailId string) *UserRoleGrantDto {
+	grantDto := &UserRoleGrantDto{
+		Id:      grant.Id,
+		UserId:  grant.UserId,
+		EmailId: emailId,
+		RoleFilter: bean.RoleFilter{
+			Entity:      grant.Entity,
+			Team:        grant.Team,
+			EntityName:  grant.EntityName,
+			Environment: grant.Environment,
+			Action:      grant.Action,
+			AccessType:  grant.AccessType,
+		},
+		Reason:           grant.Reason,
+		DurationMinutes:  grant.DurationMinutes,
+		Status:           grant.Status,
+		RequiresApproval: grant.RequiresApproval,
+		RequestedBy:      grant.RequestedBy,
+		RequestedOn:      grant.CreatedOn,
+		ReviewedBy:       grant.ReviewedBy,
+	}
+	if !grant.ReviewedOn.IsZero() {
+		reviewedOn := grant.ReviewedOn
+		grantDto.ReviewedOn = &reviewedOn
+	}
+	if !grant.ActivatedOn.IsZero() {
+		activatedOn := grant.ActivatedOn
+		grantDto.ActivatedOn = &activatedOn
+	}
+	if !grant.ExpiresOn.IsZero() {
+		expiresOn := grant.ExpiresOn
+		grantDto.ExpiresOn = &expiresOn
+	}
+	if !grant.RevokedOn.IsZero() {
+		revokedOn := grant.RevokedOn
+		grantDto.RevokedOn = &revokedOn
+	}
+	return grantDto
+}
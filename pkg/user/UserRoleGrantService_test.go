@@ -0,0 +1,178 @@
+package user
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type fakeUserRoleGrantRepository struct {
+	repository.UserRoleGrantRepository
+	grants map[int]*repository.UserRoleGrant
+	audits []repository.UserRoleGrantAuditAction
+}
+
+func (impl *fakeUserRoleGrantRepository) UpdateIfStatus(grant *repository.UserRoleGrant, currentStatus repository.UserRoleGrantStatus, tx *pg.Tx) (bool, error) {
+	if impl.grants[grant.Id].Status != currentStatus {
+		return false, nil
+	}
+	saved := *grant
+	impl.grants[grant.Id] = &saved
+	return true, nil
+}
+
+func (impl *fakeUserRoleGrantRepository) SaveAudit(audit *repository.UserRoleGrantAudit, tx *pg.Tx) error {
+	impl.audits = append(impl.audits, audit.Action)
+	return nil
+}
+
+type fakeUserAuthRepository struct {
+	repository.UserAuthRepository
+	roles          map[string]repository.RoleModel
+	userRoles      []*repository.UserRoleModel
+	deletedRoleIds []int
+}
+
+func (impl *fakeUserAuthRepository) GetRoleByFilter(entity string, team string, app string, env string, act string, accessType string) (repository.RoleModel, error) {
+	return impl.roles[env], nil
+}
+
+func (impl *fakeUserAuthRepository) GetUserRoleMappingByUserId(userId int32) ([]*repository.UserRoleModel, error) {
+	return impl.userRoles, nil
+}
+
+func (impl *fakeUserAuthRepository) CreateUserRoleMapping(userRoleModel *repository.UserRoleModel, tx *pg.Tx) (*repository.UserRoleModel, error) {
+	impl.userRoles = append(impl.userRoles, userRoleModel)
+	return userRoleModel, nil
+}
+
+func (impl *fakeUserAuthRepository) DeleteUserRoleMapping(userRoleModel *repository.UserRoleModel, tx *pg.Tx) (bool, error) {
+	impl.deletedRoleIds = append(impl.deletedRoleIds, userRoleModel.RoleId)
+	return true, nil
+}
+
+func isConflict(err error) bool {
+	apiErr, ok := err.(*util.ApiError)
+	return ok && apiErr.HttpStatusCode == http.StatusConflict
+}
+
+func TestReviewGrant(t *testing.T) {
+	user := &repository.UserModel{Id: 3, EmailId: "user@example.com"}
+	tests := []struct {
+		name          string
+		storedStatus  repository.UserRoleGrantStatus
+		approve       bool
+		wantConflict  bool
+		wantStatus    repository.UserRoleGrantStatus
+		wantRoleIds   []int
+		wantPolicies  int
+		wantMappedIds []int
+	}{
+		{name: "approve pending grant", storedStatus: repository.USER_ROLE_GRANT_STATUS_PENDING, approve: true,
+			wantStatus: repository.USER_ROLE_GRANT_STATUS_ACTIVE, wantRoleIds: []int{12}, wantPolicies: 1, wantMappedIds: []int{11, 12}},
+		{name: "reject pending grant", storedStatus: repository.USER_ROLE_GRANT_STATUS_PENDING,
+			wantStatus: repository.USER_ROLE_GRANT_STATUS_REJECTED, wantMappedIds: []int{11}},
+		{name: "approve grant approved concurrently", storedStatus: repository.USER_ROLE_GRANT_STATUS_ACTIVE, approve: true,
+			wantConflict: true, wantStatus: repository.USER_ROLE_GRANT_STATUS_ACTIVE, wantRoleIds: []int{12}},
+		{name: "reject grant revoked concurrently", storedStatus: repository.USER_ROLE_GRANT_STATUS_REVOKED,
+			wantConflict: true, wantStatus: repository.USER_ROLE_GRANT_STATUS_REVOKED, wantRoleIds: []int{12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &repository.UserRoleGrant{Id: 1, UserId: user.Id, Status: tt.storedStatus}
+			if tt.storedStatus != repository.USER_ROLE_GRANT_STATUS_PENDING {
+				stored.RoleIds = []int{12}
+			}
+			grantRepository := &fakeUserRoleGrantRepository{grants: map[int]*repository.UserRoleGrant{1: stored}}
+			userAuthRepository := &fakeUserAuthRepository{
+				roles:     map[string]repository.RoleModel{"env1": {Id: 11, Role: "role-env1"}, "env2": {Id: 12, Role: "role-env2"}},
+				userRoles: []*repository.UserRoleModel{{Id: 1, UserId: user.Id, RoleId: 11}},
+			}
+			impl := NewUserRoleGrantServiceImpl(zap.NewNop().Sugar(), &UserRoleGrantConfig{}, grantRepository, userAuthRepository, nil, nil)
+			grant := &repository.UserRoleGrant{Id: 1, UserId: user.Id, Team: "team", EntityName: "app", Environment: "env1,env2",
+				Action: "trigger", DurationMinutes: 30, Status: repository.USER_ROLE_GRANT_STATUS_PENDING}
+
+			policies, err := impl.reviewGrant(grant, user, &UserRoleGrantReviewRequest{Id: 1, Approve: tt.approve, ReviewedBy: 2}, nil)
+			if isConflict(err) != tt.wantConflict {
+				t.Fatalf("reviewGrant() err = %v, want conflict %v", err, tt.wantConflict)
+			}
+			if !tt.wantConflict && err != nil {
+				t.Fatalf("reviewGrant() err = %v", err)
+			}
+			saved := grantRepository.grants[1]
+			if saved.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", saved.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(saved.RoleIds, tt.wantRoleIds) {
+				t.Errorf("stored role ids = %v, want %v", saved.RoleIds, tt.wantRoleIds)
+			}
+			if len(policies) != tt.wantPolicies {
+				t.Errorf("policies = %v, want %d", policies, tt.wantPolicies)
+			}
+			if tt.wantMappedIds != nil {
+				var mappedIds []int
+				for _, userRole := range userAuthRepository.userRoles {
+					mappedIds = append(mappedIds, userRole.RoleId)
+				}
+				if !reflect.DeepEqual(mappedIds, tt.wantMappedIds) {
+					t.Errorf("mapped role ids = %v, want %v", mappedIds, tt.wantMappedIds)
+				}
+			}
+		})
+	}
+}
+
+func TestCloseGrant(t *testing.T) {
+	tests := []struct {
+		name           string
+		storedStatus   repository.UserRoleGrantStatus
+		status         repository.UserRoleGrantStatus
+		auditAction    repository.UserRoleGrantAuditAction
+		wantConflict   bool
+		wantStatus     repository.UserRoleGrantStatus
+		wantDeletedIds []int
+	}{
+		{name: "revoke active grant", storedStatus: repository.USER_ROLE_GRANT_STATUS_ACTIVE, status: repository.USER_ROLE_GRANT_STATUS_REVOKED,
+			auditAction: repository.USER_ROLE_GRANT_AUDIT_REVOKED, wantStatus: repository.USER_ROLE_GRANT_STATUS_REVOKED, wantDeletedIds: []int{12}},
+		{name: "expire active grant", storedStatus: repository.USER_ROLE_GRANT_STATUS_ACTIVE, status: repository.USER_ROLE_GRANT_STATUS_EXPIRED,
+			auditAction: repository.USER_ROLE_GRANT_AUDIT_EXPIRED, wantStatus: repository.USER_ROLE_GRANT_STATUS_EXPIRED, wantDeletedIds: []int{12}},
+		{name: "expire grant revoked concurrently", storedStatus: repository.USER_ROLE_GRANT_STATUS_REVOKED, status: repository.USER_ROLE_GRANT_STATUS_EXPIRED,
+			auditAction: repository.USER_ROLE_GRANT_AUDIT_EXPIRED, wantConflict: true, wantStatus: repository.USER_ROLE_GRANT_STATUS_REVOKED},
+		{name: "expire grant expired by another replica", storedStatus: repository.USER_ROLE_GRANT_STATUS_EXPIRED, status: repository.USER_ROLE_GRANT_STATUS_EXPIRED,
+			auditAction: repository.USER_ROLE_GRANT_AUDIT_EXPIRED, wantConflict: true, wantStatus: repository.USER_ROLE_GRANT_STATUS_EXPIRED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grantRepository := &fakeUserRoleGrantRepository{grants: map[int]*repository.UserRoleGrant{
+				1: {Id: 1, UserId: 3, Status: tt.storedStatus, RoleIds: []int{12}},
+			}}
+			userAuthRepository := &fakeUserAuthRepository{}
+			impl := NewUserRoleGrantServiceImpl(zap.NewNop().Sugar(), &UserRoleGrantConfig{}, grantRepository, userAuthRepository, nil, nil)
+			// the grant is loaded as active, as by the expiry cron, before another transition ends it
+			grant := &repository.UserRoleGrant{Id: 1, UserId: 3, Status: repository.USER_ROLE_GRANT_STATUS_ACTIVE, RoleIds: []int{12}}
+			userRoles := []*repository.UserRoleModel{{Id: 5, UserId: 3, RoleId: 12}}
+
+			err := impl.closeGrant(grant, userRoles, tt.status, tt.auditAction, 1, "", nil)
+			if isConflict(err) != tt.wantConflict {
+				t.Fatalf("closeGrant() err = %v, want conflict %v", err, tt.wantConflict)
+			}
+			if !tt.wantConflict && err != nil {
+				t.Fatalf("closeGrant() err = %v", err)
+			}
+			if status := grantRepository.grants[1].Status; status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(userAuthRepository.deletedRoleIds, tt.wantDeletedIds) {
+				t.Errorf("deleted role ids = %v, want %v", userAuthRepository.deletedRoleIds, tt.wantDeletedIds)
+			}
+			if !tt.wantConflict && !reflect.DeepEqual(grantRepository.audits, []repository.UserRoleGrantAuditAction{tt.auditAction}) {
+				t.Errorf("audits = %v, want %v", grantRepository.audits, []repository.UserRoleGrantAuditAction{tt.auditAction})
+			}
+		})
+	}
+}
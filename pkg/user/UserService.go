@@ -24,6 +24,7 @@ import (
 	"github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/internal/constants"
 	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auditLog"
 	casbin2 "github.com/devtron-labs/devtron/pkg/user/casbin"
 	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
 	util2 "github.com/devtron-labs/devtron/util"
@@ -31,6 +32,7 @@ import (
 	"github.com/gorilla/sessions"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -65,13 +67,15 @@ type UserServiceImpl struct {
 	sessionManager2     *middleware.SessionManager
 	userCommonService   UserCommonService
 	userAuditService    UserAuditService
+	auditLogService     auditLog.AuditLogService
 }
 
 func NewUserServiceImpl(userAuthRepository repository2.UserAuthRepository,
 	logger *zap.SugaredLogger,
 	userRepository repository2.UserRepository,
 	userGroupRepository repository2.RoleGroupRepository,
-	sessionManager2 *middleware.SessionManager, userCommonService UserCommonService, userAuditService UserAuditService) *UserServiceImpl {
+	sessionManager2 *middleware.SessionManager, userCommonService UserCommonService, userAuditService UserAuditService,
+	auditLogService auditLog.AuditLogService) *UserServiceImpl {
 	serviceImpl := &UserServiceImpl{
 		userAuthRepository:  userAuthRepository,
 		logger:              logger,
@@ -80,6 +84,7 @@ func NewUserServiceImpl(userAuthRepository repository2.UserAuthRepository,
 		sessionManager2:     sessionManager2,
 		userCommonService:   userCommonService,
 		userAuditService:    userAuditService,
+		auditLogService:     auditLogService,
 	}
 	cStore = sessions.NewCookieStore(randKey())
 	return serviceImpl
@@ -449,6 +454,7 @@ func (impl UserServiceImpl) createUserIfNotExists(userInfo *bean.UserInfo, email
 	if err != nil {
 		return nil, err
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_USER, strconv.Itoa(int(model.Id)), auditLog.AUDIT_ACTION_CREATE, nil, userInfo, userInfo.UserId)
 	return userInfo, nil
 }
 
@@ -517,6 +523,11 @@ func (impl UserServiceImpl) UpdateUser(userInfo *bean.UserInfo, token string, ma
 			return nil, false, false, nil, err
 		}
 	}
+	// inactive users being re-activated have no previous state to audit
+	before, err := impl.GetById(userInfo.Id)
+	if err != nil && err != pg.ErrNoRows {
+		return nil, false, false, nil, err
+	}
 
 	dbConnection := impl.userRepository.GetConnection()
 	tx, err := dbConnection.Begin()
@@ -762,6 +773,7 @@ func (impl UserServiceImpl) UpdateUser(userInfo *bean.UserInfo, token string, ma
 	if err != nil {
 		return nil, false, false, nil, err
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_USER, strconv.Itoa(int(model.Id)), auditLog.AUDIT_ACTION_UPDATE, before, userInfo, userInfo.UserId)
 
 	return userInfo, rolesChanged, groupsModified, restrictedGroups, nil
 }
@@ -984,7 +996,8 @@ func (impl UserServiceImpl) GetUserByEmail(emailId string) (*bean.UserInfo, erro
 func (impl UserServiceImpl) GetLoggedInUser(r *http.Request) (int32, error) {
 	token := r.Header.Get("token")
 	userId, userType, err := impl.GetUserByToken(token)
-	// if user is of api-token type, then update lastUsedBy and lastUsedAt
+	// if user is of api-token type, then update lastUsedBy and lastUsedAt, the ip allow-list is checked on enforce
+	// and inactive tokens are rejected by the api token middleware
 	if err == nil && userType == bean.USER_TYPE_API_TOKEN {
 		go impl.saveUserAudit(r, userId)
 	}
@@ -1084,6 +1097,10 @@ func (impl UserServiceImpl) DeleteUser(bean *bean.UserInfo) (bool, error) {
 		impl.logger.Errorw("error while fetching user from db", "error", err)
 		return false, err
 	}
+	before, err := impl.GetById(bean.Id)
+	if err != nil {
+		return false, err
+	}
 	urm, err := impl.userAuthRepository.GetUserRoleMappingByUserId(bean.Id)
 	if err != nil {
 		impl.logger.Errorw("error while fetching user from db", "error", err)
@@ -1108,6 +1125,7 @@ func (impl UserServiceImpl) DeleteUser(bean *bean.UserInfo) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	impl.auditLogService.SaveResourceAuditLog(auditLog.RESOURCE_USER, strconv.Itoa(int(model.Id)), auditLog.AUDIT_ACTION_DELETE, before, nil, bean.UserId)
 
 	groups, err := casbin2.GetRolesForUser(model.EmailId)
 	if err != nil {
@@ -187,6 +187,7 @@ func TestUserUpdateService(t *testing.T) {
 			roleGroupRepositoryMocked,
 			nil,
 			nil,
+			nil,
 			nil)
 
 		token := ""
@@ -36,13 +36,21 @@ type Resource string
 type Action string
 type Object string
 type PolicyType string
+type Effect string
 
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// Policy is allowed unless Eft is deny, a deny policy overrides all policies allowing the same request
 type Policy struct {
 	Type PolicyType `json:"type"`
 	Sub  Subject    `json:"sub"`
 	Res  Resource   `json:"res"`
 	Act  Action     `json:"act"`
 	Obj  Object     `json:"obj"`
+	Eft  Effect     `json:"eft,omitempty"`
 }
 
 func Create() *casbin.SyncedEnforcer {
@@ -87,7 +95,11 @@ func AddPolicy(policies []Policy) []Policy {
 			res := strings.ToLower(string(p.Res))
 			act := strings.ToLower(string(p.Act))
 			obj := strings.ToLower(string(p.Obj))
-			success = e.AddPolicy([]string{sub, res, act, obj, "allow"})
+			eft := EffectAllow
+			if strings.ToLower(string(p.Eft)) == string(EffectDeny) {
+				eft = EffectDeny
+			}
+			success = e.AddPolicy([]string{sub, res, act, obj, string(eft)})
 		} else if strings.ToLower(string(p.Type)) == "g" && p.Sub != "" && p.Obj != "" {
 			sub := strings.ToLower(string(p.Sub))
 			obj := strings.ToLower(string(p.Obj))
@@ -126,7 +138,11 @@ func RemovePolicy(policies []Policy) []Policy {
 	for _, p := range policies {
 		success := false
 		if strings.ToLower(string(p.Type)) == "p" && p.Sub != "" && p.Res != "" && p.Act != "" && p.Obj != "" {
-			success = e.RemovePolicy([]string{strings.ToLower(string(p.Sub)), strings.ToLower(string(p.Res)), strings.ToLower(string(p.Act)), strings.ToLower(string(p.Obj))})
+			rule := []string{strings.ToLower(string(p.Sub)), strings.ToLower(string(p.Res)), strings.ToLower(string(p.Act)), strings.ToLower(string(p.Obj))}
+			if p.Eft != "" {
+				rule = append(rule, strings.ToLower(string(p.Eft)))
+			}
+			success = e.RemovePolicy(rule)
 		} else if strings.ToLower(string(p.Type)) == "g" && p.Sub != "" && p.Obj != "" {
 			success = e.RemoveGroupingPolicy([]string{strings.ToLower(string(p.Sub)), strings.ToLower(string(p.Obj))})
 		}
@@ -0,0 +1,126 @@
+package casbin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin"
+	"github.com/casbin/casbin/model"
+	"github.com/casbin/casbin/persist"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const testAuthModel = `
+[request_definition]
+r = sub, res, act, obj
+
+[policy_definition]
+p = sub, res, act, obj, eft
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[role_definition]
+g = _, _
+
+[matchers]
+m = g(r.sub, p.sub) && matchKeyByPart(r.res, p.res) && matchKeyByPart(r.act, p.act) && matchKeyByPart(r.obj, p.obj)
+`
+
+// memoryAdapter keeps the policy rules in memory, in place of the casbin_rule table
+type memoryAdapter struct {
+	rules []string
+}
+
+func (a *memoryAdapter) LoadPolicy(model model.Model) error {
+	for _, rule := range a.rules {
+		persist.LoadPolicyLine(rule, model)
+	}
+	return nil
+}
+
+func (a *memoryAdapter) SavePolicy(model model.Model) error {
+	return nil
+}
+
+func (a *memoryAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	a.rules = append(a.rules, ptype+", "+strings.Join(rule, ", "))
+	return nil
+}
+
+func (a *memoryAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	line := ptype + ", " + strings.Join(rule, ", ")
+	for i, existing := range a.rules {
+		if existing == line {
+			a.rules = append(a.rules[:i], a.rules[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (a *memoryAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return nil
+}
+
+func newTestEnforcer(rules ...string) (*EnforcerImpl, *memoryAdapter) {
+	adapter := &memoryAdapter{rules: rules}
+	syncedEnforcer := casbin.NewSyncedEnforcer(casbin.NewModel(testAuthModel), adapter)
+	syncedEnforcer.AddFunction("matchKeyByPart", MatchKeyByPartFunc)
+	e = syncedEnforcer
+	return NewEnforcerImpl(syncedEnforcer, nil, zap.NewNop().Sugar()), adapter
+}
+
+func TestAddPolicyEffect(t *testing.T) {
+	enforcer, adapter := newTestEnforcer("g, user@example.com, role:dev")
+
+	failed := AddPolicy([]Policy{
+		{Type: "p", Sub: "role:dev", Res: "environment", Act: "*", Obj: "*/*"},
+		{Type: "p", Sub: "role:dev", Res: "environment", Act: "update", Obj: "prod/*", Eft: EffectDeny},
+	})
+	assert.Empty(t, failed)
+	assert.Contains(t, adapter.rules, "p, role:dev, environment, *, */*, allow")
+	assert.Contains(t, adapter.rules, "p, role:dev, environment, update, prod/*, deny")
+
+	assert.True(t, enforcer.EnforceByEmail("user@example.com", "environment", "update", "dev/app"))
+	assert.False(t, enforcer.EnforceByEmail("user@example.com", "environment", "update", "prod/app"))
+	assert.True(t, enforcer.EnforceByEmail("user@example.com", "environment", "get", "prod/app"))
+
+	failed = RemovePolicy([]Policy{{Type: "p", Sub: "role:dev", Res: "environment", Act: "update", Obj: "prod/*", Eft: EffectDeny}})
+	assert.Empty(t, failed)
+	assert.NotContains(t, adapter.rules, "p, role:dev, environment, update, prod/*, deny")
+	enforcer.InvalidateCompleteCache()
+	assert.True(t, enforcer.EnforceByEmail("user@example.com", "environment", "update", "prod/app"))
+}
+
+func TestIsDeniedByEmail(t *testing.T) {
+	enforcer, _ := newTestEnforcer(
+		"g, user@example.com, role:dev",
+		"g, other@example.com, role:ops",
+		"p, role:dev, environment, *, */*, allow",
+		"p, role:dev, secret, update, prod/*, deny",
+		"p, user@example.com, secret, delete, */payments, deny",
+		"p, role:ops, secret, *, *, allow",
+	)
+	tests := []struct {
+		name   string
+		email  string
+		action string
+		object string
+		denied bool
+	}{
+		{name: "denied through role", email: "user@example.com", action: "update", object: "prod/app", denied: true},
+		{name: "denied through role case insensitive email", email: "User@Example.com", action: "update", object: "prod/app", denied: true},
+		{name: "other env not denied", email: "user@example.com", action: "update", object: "dev/app", denied: false},
+		{name: "other action not denied", email: "user@example.com", action: "get", object: "prod/app", denied: false},
+		{name: "denied on user", email: "user@example.com", action: "delete", object: "dev/payments", denied: true},
+		{name: "allow policies are not deny", email: "other@example.com", action: "update", object: "prod/app", denied: false},
+		{name: "base secrets are not denied by env rules", email: "user@example.com", action: "update", object: "*/app", denied: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.denied, enforcer.IsDeniedByEmail(tt.email, ResourceSecret, tt.action, tt.object))
+		})
+	}
+}
@@ -24,23 +24,46 @@ import (
 	"github.com/casbin/casbin"
 	"github.com/devtron-labs/authenticator/jwt"
 	"github.com/devtron-labs/authenticator/middleware"
+	jwt2 "github.com/golang-jwt/jwt/v4"
 	"github.com/patrickmn/go-cache"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log"
 	"math"
+	"net"
+	"net/http"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+const xForwardedForHeaderName = "X-Forwarded-For"
+
+const (
+	// ApiTokenScopedRolesClaim holds the casbin roles a scoped api token is restricted to
+	ApiTokenScopedRolesClaim = "scopedRoles"
+	// ApiTokenAllowedIpsClaim holds the ips and cidrs a scoped api token can be used from
+	ApiTokenAllowedIpsClaim = "allowedIps"
+)
+
 type Enforcer interface {
 	Enforce(emailId string, resource string, action string, resourceItem string) bool
 	EnforceErr(emailId string, resource string, action string, resourceItem string) error
 	EnforceByEmail(emailId string, resource string, action string, resourceItem string) bool
 	EnforceByEmailInBatch(emailId string, resource string, action string, vals []string) map[string]bool
+	// EnforceInBatch enforces the token user in batch, with the ip allow-list and the scoped roles of api tokens
+	// applied the same way as on Enforce
+	EnforceInBatch(token string, resource string, action string, vals []string) map[string]bool
+	// ClientIpMiddleware records the client ip of each request against its token, for the ip allow-list of scoped api
+	// tokens checked on enforce. The client ip is the remote address, forwarded-for entries are only taken when added
+	// by configured trusted proxies
+	ClientIpMiddleware(next http.Handler) http.Handler
+	// IsDenied checks only the deny policies of the token user and its roles and groups, for resources which are
+	// allowed through another resource
+	IsDenied(token string, resource string, action string, resourceItem string) bool
+	IsDeniedByEmail(emailId string, resource string, action string, resourceItem string) bool
 	InvalidateCache(emailId string) bool
 	InvalidateCompleteCache()
 	ReloadPolicy() error
@@ -55,7 +78,8 @@ func NewEnforcerImpl(
 	batchRequestLock := make(map[string]*sync.Mutex)
 	enforcerConfig := getConfig()
 	enf := &EnforcerImpl{lockCacheData: lock, enforcerRWLock: &sync.RWMutex{}, batchRequestLock: batchRequestLock, enforcerConfig: enforcerConfig,
-		Cache: getEnforcerCache(logger, enforcerConfig), SyncedEnforcer: enforcer, logger: logger, SessionManager: sessionManager}
+		Cache: getEnforcerCache(logger, enforcerConfig), SyncedEnforcer: enforcer, logger: logger, SessionManager: sessionManager,
+		clientIps: make(map[string]map[string]int), clientIpsLock: &sync.Mutex{}}
 	setEnforcerImpl(enf)
 	return enf
 }
@@ -70,6 +94,9 @@ type EnforcerConfig struct {
 	CacheEnabled          bool `env:"ENFORCER_CACHE" envDefault:"false"`
 	CacheExpirationInSecs int  `env:"ENFORCER_CACHE_EXPIRATION_IN_SEC" envDefault:"86400"`
 	EnforcerBatchSize     int  `env:"ENFORCER_MAX_BATCH_SIZE" envDefault:"1"`
+	// ApiTokenTrustedProxies are the ips and cidrs of the proxies whose X-Forwarded-For entries are trusted when
+	// checking the ip allow-list of api tokens
+	ApiTokenTrustedProxies []string `env:"API_TOKEN_TRUSTED_PROXIES" envSeparator:","`
 }
 
 func getConfig() *EnforcerConfig {
@@ -106,6 +133,9 @@ type EnforcerImpl struct {
 	logger         *zap.SugaredLogger
 	enforcerConfig *EnforcerConfig
 	enforcerRWLock *sync.RWMutex
+	// clientIps counts the requests being served per token and client ip
+	clientIps     map[string]map[string]int
+	clientIpsLock *sync.Mutex
 }
 
 // Enforce is a wrapper around casbin.Enforce to additionally enforce a default role and a custom
@@ -222,6 +252,31 @@ func (e *EnforcerImpl) EnforceByEmailInBatch(emailId string, resource string, ac
 	return result
 }
 
+func (e *EnforcerImpl) EnforceInBatch(token string, resource string, action string, vals []string) map[string]bool {
+	mapClaims, invalid := e.verifyTokenAndGetClaims(token)
+	if invalid {
+		return make(map[string]bool)
+	}
+	return e.enforceClaimsInBatch(token, mapClaims, resource, action, vals)
+}
+
+func (e *EnforcerImpl) enforceClaimsInBatch(token string, mapClaims jwt2.MapClaims, resource string, action string, vals []string) map[string]bool {
+	if !e.isClientIpAllowed(token, mapClaims) {
+		return make(map[string]bool)
+	}
+	result := e.EnforceByEmailInBatch(strings.ToLower(getEmailFromClaims(mapClaims)), resource, action, vals)
+	scopedRoles, isScoped := getApiTokenClaimValues(mapClaims, ApiTokenScopedRolesClaim)
+	if !isScoped {
+		return result
+	}
+	// the batch result is cached for the user, so the scoped result is built separately
+	scopedResult := make(map[string]bool, len(result))
+	for resourceItem, allowed := range result {
+		scopedResult[resourceItem] = allowed && e.enforceScopedRoles(scopedRoles, resource, action, resourceItem)
+	}
+	return scopedResult
+}
+
 func (e *EnforcerImpl) getBatchRequestLock(emailId string) *sync.Mutex {
 	emailBatchRequestMutex, found := e.batchRequestLock[getLockKey(emailId)]
 	if !found {
@@ -354,11 +409,119 @@ func (e *EnforcerImpl) GetCacheDump() string {
 // enforce is a helper to additionally check a default role and invoke a custom claims enforcement function
 func (e *EnforcerImpl) enforce(token string, resource string, action string, resourceItem string) bool {
 	// check the default role
-	email, invalid := e.verifyTokenAndGetEmail(token)
+	mapClaims, invalid := e.verifyTokenAndGetClaims(token)
 	if invalid {
 		return false
 	}
-	return e.enforceByEmail(strings.ToLower(email), resource, action, resourceItem)
+	email := getEmailFromClaims(mapClaims)
+	if !e.enforceByEmail(strings.ToLower(email), resource, action, resourceItem) {
+		return false
+	}
+	if !e.isClientIpAllowed(token, mapClaims) {
+		return false
+	}
+	// scoped api tokens stay restricted to the roles they were issued with, whatever else their user is granted later
+	if scopedRoles, isScoped := getApiTokenClaimValues(mapClaims, ApiTokenScopedRolesClaim); isScoped {
+		return e.enforceScopedRoles(scopedRoles, resource, action, resourceItem)
+	}
+	return true
+}
+
+func (e *EnforcerImpl) ClientIpMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("token")
+		if len(token) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		clientIp := r.RemoteAddr
+		if ip := getClientIp(r.RemoteAddr, r.Header.Get(xForwardedForHeaderName), e.enforcerConfig.ApiTokenTrustedProxies); ip != nil {
+			clientIp = ip.String()
+		}
+		e.addClientIp(token, clientIp)
+		defer e.removeClientIp(token, clientIp)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (e *EnforcerImpl) addClientIp(token string, clientIp string) {
+	e.clientIpsLock.Lock()
+	defer e.clientIpsLock.Unlock()
+	if e.clientIps[token] == nil {
+		e.clientIps[token] = make(map[string]int)
+	}
+	e.clientIps[token][clientIp]++
+}
+
+func (e *EnforcerImpl) removeClientIp(token string, clientIp string) {
+	e.clientIpsLock.Lock()
+	defer e.clientIpsLock.Unlock()
+	e.clientIps[token][clientIp]--
+	if e.clientIps[token][clientIp] <= 0 {
+		delete(e.clientIps[token], clientIp)
+	}
+	if len(e.clientIps[token]) == 0 {
+		delete(e.clientIps, token)
+	}
+}
+
+// isClientIpAllowed checks the ip allow-list of scoped api tokens against the client ips of all requests being served
+// with the token, as concurrent requests can't be told apart. A restricted token is denied outside of a request
+func (e *EnforcerImpl) isClientIpAllowed(token string, mapClaims jwt2.MapClaims) bool {
+	allowedIps, isRestricted := getApiTokenClaimValues(mapClaims, ApiTokenAllowedIpsClaim)
+	if !isRestricted {
+		return true
+	}
+	e.clientIpsLock.Lock()
+	defer e.clientIpsLock.Unlock()
+	clientIps := e.clientIps[token]
+	if len(clientIps) == 0 {
+		e.logger.Warnw("ip restricted api token used without a client ip")
+		return false
+	}
+	for clientIp := range clientIps {
+		ip := net.ParseIP(clientIp)
+		if ip == nil || !isIpAllowed(ip, allowedIps) {
+			e.logger.Warnw("api token used from ip outside its allow-list", "clientIp", clientIp)
+			return false
+		}
+	}
+	return true
+}
+
+func (e *EnforcerImpl) IsDenied(token string, resource string, action string, resourceItem string) bool {
+	mapClaims, invalid := e.verifyTokenAndGetClaims(token)
+	if invalid {
+		return true
+	}
+	return e.IsDeniedByEmail(getEmailFromClaims(mapClaims), resource, action, resourceItem)
+}
+
+func (e *EnforcerImpl) IsDeniedByEmail(emailId string, resource string, action string, resourceItem string) bool {
+	email := strings.ToLower(emailId)
+	subjects := map[string]bool{email: true}
+	for _, role := range e.SyncedEnforcer.GetImplicitRolesForUser(email) {
+		subjects[role] = true
+	}
+	for _, policy := range e.SyncedEnforcer.GetFilteredPolicy(4, string(EffectDeny)) {
+		if len(policy) < 4 || !subjects[policy[0]] {
+			continue
+		}
+		if MatchKeyByPart(resource, policy[1]) && MatchKeyByPart(action, policy[2]) && MatchKeyByPart(resourceItem, policy[3]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *EnforcerImpl) enforceScopedRoles(scopedRoles []string, resource string, action string, resourceItem string) bool {
+	for _, role := range scopedRoles {
+		allowed, err := e.enforcerEnforce(role, resource, action, resourceItem)
+		if err == nil && allowed {
+			return true
+		}
+	}
+	return false
 }
 
 func (e *EnforcerImpl) enforceAndUpdateCache(email string, resource string, action string, resourceItem string) bool {
@@ -392,21 +555,89 @@ func (e *EnforcerImpl) enforcerEnforce(email string, resource string, action str
 	return response, err
 }
 
-func (e *EnforcerImpl) verifyTokenAndGetEmail(tokenString string) (string, bool) {
+func (e *EnforcerImpl) verifyTokenAndGetClaims(tokenString string) (jwt2.MapClaims, bool) {
 	claims, err := e.SessionManager.VerifyToken(tokenString)
 	if err != nil {
-		return "", true
+		return nil, true
 	}
 	mapClaims, err := jwt.MapClaims(claims)
 	if err != nil {
-		return "", true
+		return nil, true
 	}
+	return mapClaims, false
+}
+
+func getEmailFromClaims(mapClaims jwt2.MapClaims) string {
 	email := jwt.GetField(mapClaims, "email")
 	sub := jwt.GetField(mapClaims, "sub")
 	if email == "" && (sub == "admin" || sub == "admin:login") {
 		email = "admin"
 	}
-	return email, false
+	return email
+}
+
+// getApiTokenClaimValues returns the values of a list claim and whether it is present, only claims of tokens issued
+// as api tokens are considered
+func getApiTokenClaimValues(mapClaims jwt2.MapClaims, claim string) ([]string, bool) {
+	if jwt.GetField(mapClaims, "iss") != middleware.ApiTokenClaimIssuer {
+		return nil, false
+	}
+	rawValues, ok := mapClaims[claim].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	values := make([]string, 0, len(rawValues))
+	for _, rawValue := range rawValues {
+		if value, ok := rawValue.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values, true
+}
+
+// getClientIp returns the remote address unless it is a trusted proxy, in which case the forwarded-for entries are
+// walked from the right, the entries left of the first untrusted one are written by the client and can't be trusted
+func getClientIp(remoteAddr string, forwardedFor string, trustedProxies []string) net.IP {
+	ip := parseIp(remoteAddr)
+	if ip == nil || !isIpAllowed(ip, trustedProxies) || len(forwardedFor) == 0 {
+		return ip
+	}
+	forwardedIps := strings.Split(forwardedFor, ",")
+	for i := len(forwardedIps) - 1; i >= 0; i-- {
+		forwardedIp := parseIp(forwardedIps[i])
+		if forwardedIp == nil {
+			// a malformed entry can't be attributed, stop at the last trusted hop
+			return ip
+		}
+		ip = forwardedIp
+		if !isIpAllowed(ip, trustedProxies) {
+			return ip
+		}
+	}
+	return ip
+}
+
+func parseIp(address string) net.IP {
+	address = strings.TrimSpace(address)
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return net.ParseIP(address)
+}
+
+// isIpAllowed matches the ip against a list of ips and cidrs
+func isIpAllowed(ip net.IP, allowedIps []string) bool {
+	for _, allowedIp := range allowedIps {
+		allowedIp = strings.TrimSpace(allowedIp)
+		if _, allowedNet, err := net.ParseCIDR(allowedIp); err == nil {
+			if allowedNet.Contains(ip) {
+				return true
+			}
+		} else if ip.Equal(net.ParseIP(allowedIp)) {
+			return true
+		}
+	}
+	return false
 }
 
 // enforce is a helper to additionally check a default role and invoke a custom claims enforcement function
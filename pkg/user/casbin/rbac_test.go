@@ -3,8 +3,15 @@ package casbin
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/devtron-labs/authenticator/middleware"
+	jwt2 "github.com/golang-jwt/jwt/v4"
 	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
 	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -132,3 +139,142 @@ func getEnforcerCacheLock_123(lock map[string]*CacheData, emailId string) *Cache
 func enforce(randomeKey string) bool {
 	return len(randomeKey)%2 == 0
 }
+
+func TestGetClientIp(t *testing.T) {
+	trustedProxies := []string{"10.0.0.0/8", "192.168.1.1"}
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		want         string
+	}{
+		{name: "no proxy", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "spoofed header from untrusted remote", remoteAddr: "203.0.113.7:51234", forwardedFor: "198.51.100.1", want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:443", forwardedFor: "198.51.100.1", want: "198.51.100.1"},
+		{name: "spoofed left-most entry behind trusted proxy", remoteAddr: "10.1.2.3:443", forwardedFor: "198.51.100.1, 203.0.113.7", want: "203.0.113.7"},
+		{name: "chain of trusted proxies", remoteAddr: "10.1.2.3:443", forwardedFor: "203.0.113.7, 192.168.1.1, 10.9.9.9", want: "203.0.113.7"},
+		{name: "malformed entry behind trusted proxy", remoteAddr: "10.1.2.3:443", forwardedFor: "203.0.113.7, junk", want: "10.1.2.3"},
+		{name: "ipv6 remote", remoteAddr: "[2001:db8::1]:443", forwardedFor: "198.51.100.1", want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getClientIp(tt.remoteAddr, tt.forwardedFor, trustedProxies)
+			if got == nil || got.String() != tt.want {
+				t.Errorf("getClientIp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsIpAllowed(t *testing.T) {
+	allowedIps := []string{"198.51.100.0/24", "203.0.113.7", "2001:db8::/32"}
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "198.51.100.25", want: true},
+		{ip: "198.51.101.25", want: false},
+		{ip: "203.0.113.7", want: true},
+		{ip: "203.0.113.8", want: false},
+		{ip: "2001:db8::10", want: true},
+		{ip: "2001:db9::10", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := isIpAllowed(net.ParseIP(tt.ip), allowedIps); got != tt.want {
+				t.Errorf("isIpAllowed(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsClientIpAllowed(t *testing.T) {
+	enforcer := &EnforcerImpl{
+		logger:         zap.NewNop().Sugar(),
+		enforcerConfig: &EnforcerConfig{ApiTokenTrustedProxies: []string{"10.0.0.0/8"}},
+		clientIps:      make(map[string]map[string]int),
+		clientIpsLock:  &sync.Mutex{},
+	}
+	restrictedClaims := jwt2.MapClaims{"iss": middleware.ApiTokenClaimIssuer, ApiTokenAllowedIpsClaim: []interface{}{"198.51.100.0/24"}}
+	unrestrictedClaims := jwt2.MapClaims{"iss": middleware.ApiTokenClaimIssuer}
+	tests := []struct {
+		name         string
+		claims       jwt2.MapClaims
+		remoteAddr   string
+		forwardedFor string
+		want         bool
+	}{
+		{name: "allowed ip", claims: restrictedClaims, remoteAddr: "198.51.100.7:51234", want: true},
+		{name: "denied ip", claims: restrictedClaims, remoteAddr: "203.0.113.7:51234", want: false},
+		{name: "allowed ip behind trusted proxy", claims: restrictedClaims, remoteAddr: "10.1.2.3:443", forwardedFor: "198.51.100.7", want: true},
+		{name: "denied ip behind trusted proxy", claims: restrictedClaims, remoteAddr: "10.1.2.3:443", forwardedFor: "203.0.113.7", want: false},
+		{name: "spoofed header from untrusted remote", claims: restrictedClaims, remoteAddr: "203.0.113.7:51234", forwardedFor: "198.51.100.7", want: false},
+		{name: "unrestricted token", claims: unrestrictedClaims, remoteAddr: "203.0.113.7:51234", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := "token-" + tt.name
+			var got bool
+			handler := enforcer.ClientIpMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = enforcer.isClientIpAllowed(token, tt.claims)
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/orchestrator/app/list", nil)
+			r.RemoteAddr = tt.remoteAddr
+			r.Header.Set("token", token)
+			if len(tt.forwardedFor) > 0 {
+				r.Header.Set(xForwardedForHeaderName, tt.forwardedFor)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+			if got != tt.want {
+				t.Errorf("isClientIpAllowed() = %v, want %v", got, tt.want)
+			}
+			if len(enforcer.clientIps) != 0 {
+				t.Errorf("client ips left after request: %v", enforcer.clientIps)
+			}
+		})
+	}
+	// a restricted token is only allowed while a request with it is being served
+	if enforcer.isClientIpAllowed("token-allowed ip", restrictedClaims) {
+		t.Errorf("isClientIpAllowed() outside of a request = true, want false")
+	}
+}
+
+func TestEnforceClaimsInBatch(t *testing.T) {
+	enforcer, _ := newTestEnforcer(
+		"g, api-token:ci, role:dev",
+		"g, api-token:ci, role:ops",
+		"p, role:dev, team, get, dev, allow",
+		"p, role:ops, team, get, ops, allow",
+	)
+	enforcer.enforcerConfig = &EnforcerConfig{EnforcerBatchSize: 1}
+	userClaims := jwt2.MapClaims{"iss": middleware.ApiTokenClaimIssuer, "email": "api-token:ci"}
+	scopedClaims := jwt2.MapClaims{"iss": middleware.ApiTokenClaimIssuer, "email": "api-token:ci", ApiTokenScopedRolesClaim: []interface{}{"role:dev"}}
+	restrictedClaims := jwt2.MapClaims{"iss": middleware.ApiTokenClaimIssuer, "email": "api-token:ci", ApiTokenAllowedIpsClaim: []interface{}{"198.51.100.0/24"}}
+	tests := []struct {
+		name       string
+		claims     jwt2.MapClaims
+		remoteAddr string
+		want       map[string]bool
+	}{
+		{name: "unscoped token", claims: userClaims, remoteAddr: "203.0.113.7:51234", want: map[string]bool{"dev": true, "ops": true, "prod": false}},
+		{name: "scoped token", claims: scopedClaims, remoteAddr: "203.0.113.7:51234", want: map[string]bool{"dev": true, "ops": false, "prod": false}},
+		{name: "allowed ip", claims: restrictedClaims, remoteAddr: "198.51.100.7:51234", want: map[string]bool{"dev": true, "ops": true, "prod": false}},
+		{name: "denied ip", claims: restrictedClaims, remoteAddr: "203.0.113.7:51234", want: map[string]bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := "token-" + tt.name
+			var got map[string]bool
+			handler := enforcer.ClientIpMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = enforcer.enforceClaimsInBatch(token, tt.claims, "team", "get", []string{"dev", "ops", "prod"})
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/orchestrator/team/autocomplete", nil)
+			r.RemoteAddr = tt.remoteAddr
+			r.Header.Set("token", token)
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("enforceClaimsInBatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
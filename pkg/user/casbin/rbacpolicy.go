@@ -28,6 +28,7 @@ const (
 	ResourceNotification      = "notification"
 	ResourceTemplate          = "template"
 	ResourceTerminal          = "terminal"
+	ResourceSecret            = "secret" // allowed through environment, only deny rules are checked on it
 
 	ResourceProjects     = "projects"
 	ResourceApplications = "applications"
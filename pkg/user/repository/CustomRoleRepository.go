@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/go-pg/pg"
+)
+
+// ScopedResourceObjectTemplates are the casbin object templates, rendered with RolePolicyDetails, of the resources
+// custom roles and deny rules can be defined on. They follow the objects built by EnforcerUtil for these resources.
+var ScopedResourceObjectTemplates = map[string]string{
+	casbin.ResourceApplications:      "{{.TeamObj}}/{{.AppObj}}",
+	casbin.ResourceEnvironment:       "{{.EnvObj}}/{{.AppObj}}",
+	casbin.ResourceSecret:            "{{.EnvObj}}/{{.AppObj}}",
+	casbin.ResourceGlobalEnvironment: "{{.EnvObj}}",
+	casbin.ResourceTeam:              "{{.TeamObj}}",
+	casbin.ResourceUser:              "{{.TeamObj}}",
+	casbin.ResourceNotification:      "{{.TeamObj}}",
+}
+
+// CustomRolePermission is a resource and action of a custom role, the object is derived from the team, env and app
+// the role is assigned on
+type CustomRolePermission struct {
+	Resource string `json:"resource" validate:"required"`
+	Action   string `json:"action" validate:"required"`
+}
+
+// CustomRole is a named set of permissions which can be assigned as the action of a role filter, like the default
+// manager, admin, trigger and view roles
+type CustomRole struct {
+	TableName   struct{}                `sql:"custom_role" pg:",discard_unknown_columns"`
+	Id          int                     `sql:"id,pk"`
+	Name        string                  `sql:"name,notnull"`
+	Description string                  `sql:"description"`
+	Permissions []*CustomRolePermission `sql:"permissions,notnull"`
+	Active      bool                    `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type CustomRoleRepository interface {
+	GetConnection() *pg.DB
+	Save(customRole *CustomRole) error
+	Update(customRole *CustomRole, tx *pg.Tx) error
+	FindById(id int) (*CustomRole, error)
+	FindActiveByName(name string) (*CustomRole, error)
+	FindAllActive() ([]*CustomRole, error)
+}
+
+type CustomRoleRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewCustomRoleRepositoryImpl(dbConnection *pg.DB) *CustomRoleRepositoryImpl {
+	return &CustomRoleRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl CustomRoleRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl CustomRoleRepositoryImpl) Save(customRole *CustomRole) error {
+	return impl.dbConnection.Insert(customRole)
+}
+
+func (impl CustomRoleRepositoryImpl) Update(customRole *CustomRole, tx *pg.Tx) error {
+	return tx.Update(customRole)
+}
+
+func (impl CustomRoleRepositoryImpl) FindById(id int) (*CustomRole, error) {
+	customRole := &CustomRole{}
+	err := impl.dbConnection.Model(customRole).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return customRole, err
+}
+
+func (impl CustomRoleRepositoryImpl) FindActiveByName(name string) (*CustomRole, error) {
+	customRole := &CustomRole{}
+	err := impl.dbConnection.Model(customRole).
+		Where("name = ?", name).
+		Where("active = ?", true).
+		Select()
+	return customRole, err
+}
+
+func (impl CustomRoleRepositoryImpl) FindAllActive() ([]*CustomRole, error) {
+	var customRoles []*CustomRole
+	err := impl.dbConnection.Model(&customRoles).
+		Where("active = ?", true).
+		Order("name ASC").
+		Select()
+	return customRoles, err
+}
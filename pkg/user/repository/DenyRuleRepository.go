@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type DenyRuleSubjectType string
+
+const (
+	DENY_RULE_SUBJECT_TYPE_USER  DenyRuleSubjectType = "USER"
+	DENY_RULE_SUBJECT_TYPE_GROUP DenyRuleSubjectType = "GROUP"
+)
+
+// DenyRule is an explicit deny of an action on a resource for a user or a role group, it is kept in casbin as a
+// policy with deny effect on CasbinSubject which overrides any role allowing the same
+type DenyRule struct {
+	TableName     struct{}            `sql:"rbac_deny_rule" pg:",discard_unknown_columns"`
+	Id            int                 `sql:"id,pk"`
+	SubjectType   DenyRuleSubjectType `sql:"subject_type,notnull"`
+	Subject       string              `sql:"subject,notnull"`
+	CasbinSubject string              `sql:"casbin_subject,notnull"`
+	Resource      string              `sql:"resource,notnull"`
+	Action        string              `sql:"action,notnull"`
+	Team          string              `sql:"team"`
+	Environment   string              `sql:"environment"`
+	EntityName    string              `sql:"entity_name"`
+	Object        string              `sql:"object,notnull"`
+	Description   string              `sql:"description"`
+	Active        bool                `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type DenyRuleRepository interface {
+	Save(denyRule *DenyRule) error
+	Update(denyRule *DenyRule) error
+	FindById(id int) (*DenyRule, error)
+	FindAllActive() ([]*DenyRule, error)
+}
+
+type DenyRuleRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewDenyRuleRepositoryImpl(dbConnection *pg.DB) *DenyRuleRepositoryImpl {
+	return &DenyRuleRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl DenyRuleRepositoryImpl) Save(denyRule *DenyRule) error {
+	return impl.dbConnection.Insert(denyRule)
+}
+
+func (impl DenyRuleRepositoryImpl) Update(denyRule *DenyRule) error {
+	return impl.dbConnection.Update(denyRule)
+}
+
+func (impl DenyRuleRepositoryImpl) FindById(id int) (*DenyRule, error) {
+	denyRule := &DenyRule{}
+	err := impl.dbConnection.Model(denyRule).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return denyRule, err
+}
+
+func (impl DenyRuleRepositoryImpl) FindAllActive() ([]*DenyRule, error) {
+	var denyRules []*DenyRule
+	err := impl.dbConnection.Model(&denyRules).
+		Where("active = ?", true).
+		Order("id DESC").
+		Select()
+	return denyRules, err
+}
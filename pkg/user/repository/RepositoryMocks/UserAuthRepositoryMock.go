@@ -35,6 +35,27 @@ func (_m *UserAuthRepository) CreateDefaultHelmPolicies(team string, entityName
 	return r0, r1
 }
 
+// CreateCustomRolePolicies provides a mock function with given fields: team, entityName, env, tx
+func (_m *UserAuthRepository) CreateCustomRolePolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error) {
+	ret := _m.Called(team, entityName, env, tx)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, string, *pg.Tx) bool); ok {
+		r0 = rf(team, entityName, env, tx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string, *pg.Tx) error); ok {
+		r1 = rf(team, entityName, env, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateDefaultPolicies provides a mock function with given fields: team, entityName, env, tx
 func (_m *UserAuthRepository) CreateDefaultPolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error) {
 	ret := _m.Called(team, entityName, env, tx)
@@ -54,6 +54,7 @@ type UserAuthRepository interface {
 	DeleteUserRoleByRoleId(roleId int, tx *pg.Tx) error
 
 	CreateDefaultPolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error)
+	CreateCustomRolePolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error)
 	CreateDefaultHelmPolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error)
 	CreateDefaultPoliciesForGlobalEntity(entity string, entityName string, action string, tx *pg.Tx) (bool, error)
 	CreateRoleForSuperAdminIfNotExists(tx *pg.Tx) (bool, error)
@@ -71,16 +72,19 @@ type UserAuthRepositoryImpl struct {
 	Logger                      *zap.SugaredLogger
 	defaultAuthPolicyRepository DefaultAuthPolicyRepository
 	defaultAuthRoleRepository   DefaultAuthRoleRepository
+	customRoleRepository        CustomRoleRepository
 }
 
 func NewUserAuthRepositoryImpl(dbConnection *pg.DB, Logger *zap.SugaredLogger,
 	defaultAuthPolicyRepository DefaultAuthPolicyRepository,
-	defaultAuthRoleRepository DefaultAuthRoleRepository) *UserAuthRepositoryImpl {
+	defaultAuthRoleRepository DefaultAuthRoleRepository,
+	customRoleRepository CustomRoleRepository) *UserAuthRepositoryImpl {
 	return &UserAuthRepositoryImpl{
 		dbConnection:                dbConnection,
 		Logger:                      Logger,
 		defaultAuthPolicyRepository: defaultAuthPolicyRepository,
 		defaultAuthRoleRepository:   defaultAuthRoleRepository,
+		customRoleRepository:        customRoleRepository,
 	}
 }
 
@@ -319,7 +323,77 @@ func (impl UserAuthRepositoryImpl) DeleteUserRoleByRoleId(roleId int, tx *pg.Tx)
 	return nil
 }
 
+// CreateDefaultPolicies creates the default roles of the team, env and app along with the roles of the active custom
+// roles, default roles are created only when missing as this is also called when just a custom role is missing
 func (impl UserAuthRepositoryImpl) CreateDefaultPolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error) {
+	viewRole, err := impl.GetRoleByFilter("", team, entityName, env, string(VIEW_TYPE), "")
+	if err != nil {
+		return false, err
+	}
+	if viewRole.Id == 0 {
+		flag, err := impl.createDefaultPolicies(team, entityName, env, tx)
+		if err != nil || flag == false {
+			return flag, err
+		}
+	}
+	return impl.CreateCustomRolePolicies(team, entityName, env, tx)
+}
+
+// CreateCustomRolePolicies creates the roles, with their casbin policies, of the active custom roles which do not
+// exist yet for the team, env and app
+func (impl UserAuthRepositoryImpl) CreateCustomRolePolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error) {
+	customRoles, err := impl.customRoleRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.Logger.Errorw("error in getting custom roles", "err", err)
+		return false, err
+	}
+	if len(customRoles) == 0 {
+		return true, nil
+	}
+	transaction, err := impl.dbConnection.Begin()
+	if err != nil {
+		return false, err
+	}
+	// Rollback tx on error.
+	defer transaction.Rollback()
+	rolePolicyDetails := GetRolePolicyDetails(team, entityName, env)
+	for _, customRole := range customRoles {
+		roleModel, err := impl.GetRoleByFilter("", team, entityName, env, customRole.Name, "")
+		if err != nil {
+			return false, err
+		}
+		if roleModel.Id > 0 {
+			continue
+		}
+		role := GetCustomRoleName(customRole.Name, rolePolicyDetails)
+		policies, err := GetCustomRolePolicies(role, customRole.Permissions, rolePolicyDetails)
+		if err != nil {
+			impl.Logger.Errorw("error in getting custom role policies", "err", err, "customRole", customRole.Name)
+			return false, err
+		}
+		impl.Logger.Debugw("add policy request", "policies", policies)
+		casbin.AddPolicy(policies)
+		roleData := &bean.RoleData{
+			Role:        role,
+			Team:        team,
+			EntityName:  entityName,
+			Environment: env,
+			Action:      customRole.Name,
+		}
+		_, err = impl.createRole(roleData, transaction)
+		if err != nil {
+			impl.Logger.Errorw("error in creating custom role", "err", err, "role", role)
+			return false, err
+		}
+	}
+	err = transaction.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (impl UserAuthRepositoryImpl) createDefaultPolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error) {
 	transaction, err := impl.dbConnection.Begin()
 	if err != nil {
 		return false, err
@@ -539,6 +613,56 @@ func (impl UserAuthRepositoryImpl) CreateDefaultPolicies(team string, entityName
 	return true, nil
 }
 
+func GetRolePolicyDetails(team string, entityName string, env string) RolePolicyDetails {
+	rolePolicyDetails := RolePolicyDetails{
+		Team:    team,
+		App:     entityName,
+		Env:     env,
+		TeamObj: team,
+		EnvObj:  env,
+		AppObj:  entityName,
+	}
+	if rolePolicyDetails.TeamObj == "" {
+		rolePolicyDetails.TeamObj = "*"
+	}
+	if rolePolicyDetails.EnvObj == "" {
+		rolePolicyDetails.EnvObj = "*"
+	}
+	if rolePolicyDetails.AppObj == "" {
+		rolePolicyDetails.AppObj = "*"
+	}
+	return rolePolicyDetails
+}
+
+// GetCustomRoleName follows the naming of the default roles, custom role names can't clash with them as they are
+// not allowed to take a default role name
+func GetCustomRoleName(customRoleName string, rolePolicyDetails RolePolicyDetails) string {
+	return fmt.Sprintf("role:%s_%s_%s_%s", customRoleName, rolePolicyDetails.Team, rolePolicyDetails.Env, rolePolicyDetails.App)
+}
+
+func GetCustomRolePolicies(role string, permissions []*CustomRolePermission, rolePolicyDetails RolePolicyDetails) ([]casbin.Policy, error) {
+	policies := make([]casbin.Policy, 0, len(permissions))
+	for _, permission := range permissions {
+		objTemplate, ok := ScopedResourceObjectTemplates[permission.Resource]
+		if !ok {
+			return nil, fmt.Errorf("resource %s is not supported", permission.Resource)
+		}
+		obj, err := util.Tprintf(objTemplate, rolePolicyDetails)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, casbin.Policy{
+			Type: "p",
+			Sub:  casbin.Subject(role),
+			Res:  casbin.Resource(permission.Resource),
+			Act:  casbin.Action(permission.Action),
+			Obj:  casbin.Object(obj),
+			Eft:  casbin.EffectAllow,
+		})
+	}
+	return policies, nil
+}
+
 func (impl UserAuthRepositoryImpl) CreateDefaultHelmPolicies(team string, entityName string, env string, tx *pg.Tx) (bool, error) {
 	transaction, err := impl.dbConnection.Begin()
 	if err != nil {
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCustomRolePolicies(t *testing.T) {
+	permissions := []*CustomRolePermission{
+		{Resource: casbin.ResourceApplications, Action: casbin.ActionGet},
+		{Resource: casbin.ResourceEnvironment, Action: casbin.ActionTrigger},
+		{Resource: casbin.ResourceGlobalEnvironment, Action: casbin.ActionGet},
+		{Resource: casbin.ResourceTeam, Action: casbin.ActionGet},
+	}
+
+	t.Run("scoped on team, env and app", func(t *testing.T) {
+		rolePolicyDetails := GetRolePolicyDetails("payments", "api", "prod")
+		role := GetCustomRoleName("deployer", rolePolicyDetails)
+		assert.Equal(t, "role:deployer_payments_prod_api", role)
+		policies, err := GetCustomRolePolicies(role, permissions, rolePolicyDetails)
+		assert.Nil(t, err)
+		assert.Equal(t, []casbin.Policy{
+			{Type: "p", Sub: casbin.Subject(role), Res: casbin.ResourceApplications, Act: casbin.ActionGet, Obj: "payments/api", Eft: casbin.EffectAllow},
+			{Type: "p", Sub: casbin.Subject(role), Res: casbin.ResourceEnvironment, Act: casbin.ActionTrigger, Obj: "prod/api", Eft: casbin.EffectAllow},
+			{Type: "p", Sub: casbin.Subject(role), Res: casbin.ResourceGlobalEnvironment, Act: casbin.ActionGet, Obj: "prod", Eft: casbin.EffectAllow},
+			{Type: "p", Sub: casbin.Subject(role), Res: casbin.ResourceTeam, Act: casbin.ActionGet, Obj: "payments", Eft: casbin.EffectAllow},
+		}, policies)
+	})
+
+	t.Run("empty env and app match all", func(t *testing.T) {
+		rolePolicyDetails := GetRolePolicyDetails("payments", "", "")
+		role := GetCustomRoleName("deployer", rolePolicyDetails)
+		policies, err := GetCustomRolePolicies(role, permissions[:2], rolePolicyDetails)
+		assert.Nil(t, err)
+		assert.Equal(t, casbin.Object("payments/*"), policies[0].Obj)
+		assert.Equal(t, casbin.Object("*/*"), policies[1].Obj)
+	})
+
+	t.Run("unsupported resource", func(t *testing.T) {
+		_, err := GetCustomRolePolicies("role:deployer___", []*CustomRolePermission{{Resource: "cluster", Action: casbin.ActionGet}},
+			GetRolePolicyDetails("", "", ""))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type UserRoleGrantStatus string
+
+const (
+	USER_ROLE_GRANT_STATUS_PENDING  UserRoleGrantStatus = "PENDING"
+	USER_ROLE_GRANT_STATUS_ACTIVE   UserRoleGrantStatus = "ACTIVE"
+	USER_ROLE_GRANT_STATUS_REJECTED UserRoleGrantStatus = "REJECTED"
+	USER_ROLE_GRANT_STATUS_REVOKED  UserRoleGrantStatus = "REVOKED"
+	USER_ROLE_GRANT_STATUS_EXPIRED  UserRoleGrantStatus = "EXPIRED"
+)
+
+type UserRoleGrantAuditAction string
+
+const (
+	USER_ROLE_GRANT_AUDIT_REQUESTED UserRoleGrantAuditAction = "REQUESTED"
+	USER_ROLE_GRANT_AUDIT_APPROVED  UserRoleGrantAuditAction = "APPROVED"
+	USER_ROLE_GRANT_AUDIT_REJECTED  UserRoleGrantAuditAction = "REJECTED"
+	USER_ROLE_GRANT_AUDIT_ACTIVATED UserRoleGrantAuditAction = "ACTIVATED"
+	USER_ROLE_GRANT_AUDIT_REVOKED   UserRoleGrantAuditAction = "REVOKED"
+	USER_ROLE_GRANT_AUDIT_EXPIRED   UserRoleGrantAuditAction = "EXPIRED"
+)
+
+// UserRoleGrant is a role filter granted to a user for a limited duration, RoleIds holds only the roles which
+// were mapped to the user by this grant so that roles the user already had are left untouched on revocation
+type UserRoleGrant struct {
+	TableName        struct{}            `sql:"user_role_grant" pg:",discard_unknown_columns"`
+	Id               int                 `sql:"id,pk"`
+	UserId           int32               `sql:"user_id,notnull"`
+	Entity           string              `sql:"entity"`
+	Team             string              `sql:"team"`
+	EntityName       string              `sql:"entity_name"`
+	Environment      string              `sql:"environment"`
+	Action           string              `sql:"action,notnull"`
+	AccessType       string              `sql:"access_type"`
+	Reason           string              `sql:"reason,notnull"`
+	DurationMinutes  int                 `sql:"duration_minutes,notnull"`
+	Status           UserRoleGrantStatus `sql:"status,notnull"`
+	RequiresApproval bool                `sql:"requires_approval,notnull"`
+	RequestedBy      int32               `sql:"requested_by,notnull"`
+	ReviewedBy       int32               `sql:"reviewed_by"`
+	ReviewedOn       time.Time           `sql:"reviewed_on"`
+	ActivatedOn      time.Time           `sql:"activated_on"`
+	ExpiresOn        time.Time           `sql:"expires_on"`
+	RevokedOn        time.Time           `sql:"revoked_on"`
+	RoleIds          []int               `sql:"role_ids" pg:",array"`
+	sql.AuditLog
+}
+
+type UserRoleGrantAudit struct {
+	TableName   struct{}                 `sql:"user_role_grant_audit" pg:",discard_unknown_columns"`
+	Id          int                      `sql:"id,pk"`
+	GrantId     int                      `sql:"grant_id,notnull"`
+	UserId      int32                    `sql:"user_id,notnull"`
+	Action      UserRoleGrantAuditAction `sql:"action,notnull"`
+	PerformedBy int32                    `sql:"performed_by,notnull"`
+	Comment     string                   `sql:"comment"`
+	CreatedOn   time.Time                `sql:"created_on,notnull"`
+}
+
+type UserRoleGrantRepository interface {
+	GetConnection() *pg.DB
+	Save(grant *UserRoleGrant, tx *pg.Tx) error
+	// UpdateIfStatus updates the grant only while it is still in currentStatus, so that a concurrent review,
+	// revocation or expiry of the grant is not overwritten, it returns false when no row was updated
+	UpdateIfStatus(grant *UserRoleGrant, currentStatus UserRoleGrantStatus, tx *pg.Tx) (bool, error)
+	FindById(id int) (*UserRoleGrant, error)
+	FindByUserIdAndStatus(userId int32, status UserRoleGrantStatus) ([]*UserRoleGrant, error)
+	FindActiveExpiredBefore(expiredBefore time.Time) ([]*UserRoleGrant, error)
+	SaveAudit(audit *UserRoleGrantAudit, tx *pg.Tx) error
+	FindAuditsByGrantId(grantId int) ([]*UserRoleGrantAudit, error)
+}
+
+type UserRoleGrantRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewUserRoleGrantRepositoryImpl(dbConnection *pg.DB) *UserRoleGrantRepositoryImpl {
+	return &UserRoleGrantRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl UserRoleGrantRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl UserRoleGrantRepositoryImpl) Save(grant *UserRoleGrant, tx *pg.Tx) error {
+	return tx.Insert(grant)
+}
+
+func (impl UserRoleGrantRepositoryImpl) UpdateIfStatus(grant *UserRoleGrant, currentStatus UserRoleGrantStatus, tx *pg.Tx) (bool, error) {
+	res, err := tx.Model(grant).
+		Where("id = ?id").
+		Where("status = ?", currentStatus).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl UserRoleGrantRepositoryImpl) FindById(id int) (*UserRoleGrant, error) {
+	grant := &UserRoleGrant{}
+	err := impl.dbConnection.Model(grant).
+		Where("id = ?", id).
+		Select()
+	return grant, err
+}
+
+// FindByUserIdAndStatus filters on whichever of user and status are set
+func (impl UserRoleGrantRepositoryImpl) FindByUserIdAndStatus(userId int32, status UserRoleGrantStatus) ([]*UserRoleGrant, error) {
+	var grants []*UserRoleGrant
+	query := impl.dbConnection.Model(&grants)
+	if userId > 0 {
+		query = query.Where("user_id = ?", userId)
+	}
+	if len(status) > 0 {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id DESC").Select()
+	return grants, err
+}
+
+func (impl UserRoleGrantRepositoryImpl) FindActiveExpiredBefore(expiredBefore time.Time) ([]*UserRoleGrant, error) {
+	var grants []*UserRoleGrant
+	err := impl.dbConnection.Model(&grants).
+		Where("status = ?", USER_ROLE_GRANT_STATUS_ACTIVE).
+		Where("expires_on <= ?", expiredBefore).
+		Order("expires_on ASC").
+		Select()
+	return grants, err
+}
+
+func (impl UserRoleGrantRepositoryImpl) SaveAudit(audit *UserRoleGrantAudit, tx *pg.Tx) error {
+	return tx.Insert(audit)
+}
+
+func (impl UserRoleGrantRepositoryImpl) FindAuditsByGrantId(grantId int) ([]*UserRoleGrantAudit, error) {
+	var audits []*UserRoleGrantAudit
+	err := impl.dbConnection.Model(&audits).
+		Where("grant_id = ?", grantId).
+		Order("id ASC").
+		Select()
+	return audits, err
+}
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS gitops_pull_request_status_idx;
+
+DROP TABLE IF EXISTS "public"."gitops_pull_request";
+
+DROP SEQUENCE IF EXISTS id_seq_gitops_pull_request;
+
+---- ALTER TABLE environment - drop column
+ALTER TABLE environment
+    DROP COLUMN IF EXISTS gitops_pr_required;
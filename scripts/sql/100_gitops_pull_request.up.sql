@@ -0,0 +1,29 @@
+---- ALTER TABLE environment - add column
+ALTER TABLE environment
+    ADD COLUMN IF NOT EXISTS gitops_pr_required bool NOT NULL DEFAULT false;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_gitops_pull_request;
+
+-- Table Definition
+CREATE TABLE "public"."gitops_pull_request"
+(
+    "id"                    integer     NOT NULL DEFAULT nextval('id_seq_gitops_pull_request'::regclass),
+    "cd_workflow_runner_id" integer     NOT NULL,
+    "pipeline_override_id"  integer     NOT NULL,
+    "pipeline_id"           integer     NOT NULL,
+    "repo_name"             text        NOT NULL,
+    "branch"                text        NOT NULL,
+    "pr_number"             integer     NOT NULL,
+    "pr_url"                text,
+    "status"                varchar(50) NOT NULL,
+    "merge_commit_hash"     varchar(250),
+    "created_on"            timestamptz NOT NULL,
+    "created_by"            int4        NOT NULL,
+    "updated_on"            timestamptz NOT NULL,
+    "updated_by"            int4        NOT NULL,
+    CONSTRAINT "gitops_pull_request_cd_workflow_runner_id_fkey" FOREIGN KEY ("cd_workflow_runner_id") REFERENCES "public"."cd_workflow_runner" ("id"),
+    CONSTRAINT "gitops_pull_request_pipeline_override_id_fkey" FOREIGN KEY ("pipeline_override_id") REFERENCES "public"."pipeline_config_override" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS gitops_pull_request_status_idx ON public.gitops_pull_request (status);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "public"."cd_fan_out_execution_target";
+DROP SEQUENCE IF EXISTS id_seq_cd_fan_out_execution_target;
+DROP INDEX IF EXISTS cd_fan_out_execution_status_idx;
+DROP TABLE IF EXISTS "public"."cd_fan_out_execution";
+DROP SEQUENCE IF EXISTS id_seq_cd_fan_out_execution;
+DROP TABLE IF EXISTS "public"."cd_fan_out_target";
+DROP SEQUENCE IF EXISTS id_seq_cd_fan_out_target;
+DROP TABLE IF EXISTS "public"."cd_fan_out_config";
+DROP SEQUENCE IF EXISTS id_seq_cd_fan_out_config;
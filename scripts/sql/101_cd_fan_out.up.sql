@@ -0,0 +1,84 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_cd_fan_out_config;
+
+-- Table Definition
+CREATE TABLE "public"."cd_fan_out_config"
+(
+    "id"                         integer     NOT NULL DEFAULT nextval('id_seq_cd_fan_out_config'::regclass),
+    "pipeline_id"                integer     NOT NULL,
+    "strategy"                   varchar(50) NOT NULL,
+    "pause_between_waves"        bool        NOT NULL DEFAULT false,
+    "last_cd_workflow_runner_id" integer,
+    "active"                     bool        NOT NULL DEFAULT true,
+    "created_on"                 timestamptz NOT NULL,
+    "created_by"                 int4        NOT NULL,
+    "updated_on"                 timestamptz NOT NULL,
+    "updated_by"                 int4        NOT NULL,
+    CONSTRAINT "cd_fan_out_config_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_cd_fan_out_target;
+
+-- Table Definition
+CREATE TABLE "public"."cd_fan_out_target"
+(
+    "id"                     integer     NOT NULL DEFAULT nextval('id_seq_cd_fan_out_target'::regclass),
+    "cd_fan_out_config_id"   integer     NOT NULL,
+    "environment_id"         integer     NOT NULL,
+    "wave"                   integer     NOT NULL,
+    "deployment_app_created" bool        NOT NULL DEFAULT false,
+    "active"                 bool        NOT NULL DEFAULT true,
+    "created_on"             timestamptz NOT NULL,
+    "created_by"             int4        NOT NULL,
+    "updated_on"             timestamptz NOT NULL,
+    "updated_by"             int4        NOT NULL,
+    CONSTRAINT "cd_fan_out_target_cd_fan_out_config_id_fkey" FOREIGN KEY ("cd_fan_out_config_id") REFERENCES "public"."cd_fan_out_config" ("id"),
+    CONSTRAINT "cd_fan_out_target_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_cd_fan_out_execution;
+
+-- Table Definition
+CREATE TABLE "public"."cd_fan_out_execution"
+(
+    "id"                    integer     NOT NULL DEFAULT nextval('id_seq_cd_fan_out_execution'::regclass),
+    "cd_fan_out_config_id"  integer     NOT NULL,
+    "cd_workflow_id"        integer     NOT NULL,
+    "cd_workflow_runner_id" integer     NOT NULL,
+    "ci_artifact_id"        integer     NOT NULL,
+    "current_wave"          integer     NOT NULL DEFAULT 0,
+    "status"                varchar(50) NOT NULL,
+    "created_on"            timestamptz NOT NULL,
+    "created_by"            int4        NOT NULL,
+    "updated_on"            timestamptz NOT NULL,
+    "updated_by"            int4        NOT NULL,
+    CONSTRAINT "cd_fan_out_execution_cd_fan_out_config_id_fkey" FOREIGN KEY ("cd_fan_out_config_id") REFERENCES "public"."cd_fan_out_config" ("id"),
+    CONSTRAINT "cd_fan_out_execution_cd_workflow_id_fkey" FOREIGN KEY ("cd_workflow_id") REFERENCES "public"."cd_workflow" ("id"),
+    CONSTRAINT "cd_fan_out_execution_cd_workflow_runner_id_fkey" FOREIGN KEY ("cd_workflow_runner_id") REFERENCES "public"."cd_workflow_runner" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS cd_fan_out_execution_status_idx ON public.cd_fan_out_execution (status);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_cd_fan_out_execution_target;
+
+-- Table Definition
+CREATE TABLE "public"."cd_fan_out_execution_target"
+(
+    "id"                      integer     NOT NULL DEFAULT nextval('id_seq_cd_fan_out_execution_target'::regclass),
+    "cd_fan_out_execution_id" integer     NOT NULL,
+    "environment_id"          integer     NOT NULL,
+    "wave"                    integer     NOT NULL,
+    "cd_workflow_runner_id"   integer,
+    "pipeline_override_id"    integer,
+    "status"                  varchar(50) NOT NULL,
+    "message"                 text,
+    "created_on"              timestamptz NOT NULL,
+    "created_by"              int4        NOT NULL,
+    "updated_on"              timestamptz NOT NULL,
+    "updated_by"              int4        NOT NULL,
+    CONSTRAINT "cd_fan_out_execution_target_cd_fan_out_execution_id_fkey" FOREIGN KEY ("cd_fan_out_execution_id") REFERENCES "public"."cd_fan_out_execution" ("id"),
+    CONSTRAINT "cd_fan_out_execution_target_cd_workflow_runner_id_fkey" FOREIGN KEY ("cd_workflow_runner_id") REFERENCES "public"."cd_workflow_runner" ("id"),
+    PRIMARY KEY ("id")
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS canary_analysis_run_status_idx;
+
+DROP TABLE IF EXISTS "public"."canary_analysis_run";
+
+DROP SEQUENCE IF EXISTS id_seq_canary_analysis_run;
+
+DROP TABLE IF EXISTS "public"."canary_analysis_config";
+
+DROP SEQUENCE IF EXISTS id_seq_canary_analysis_config;
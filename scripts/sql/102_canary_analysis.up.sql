@@ -0,0 +1,45 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_canary_analysis_config;
+
+-- Table Definition
+CREATE TABLE "public"."canary_analysis_config"
+(
+    "id"               integer     NOT NULL DEFAULT nextval('id_seq_canary_analysis_config'::regclass),
+    "pipeline_id"      integer     NOT NULL,
+    "interval_seconds" integer     NOT NULL,
+    "interval_count"   integer     NOT NULL,
+    "failure_limit"    integer     NOT NULL DEFAULT 0,
+    "metrics"          text        NOT NULL,
+    "active"           bool        NOT NULL,
+    "created_on"       timestamptz NOT NULL,
+    "created_by"       int4        NOT NULL,
+    "updated_on"       timestamptz NOT NULL,
+    "updated_by"       int4        NOT NULL,
+    CONSTRAINT "canary_analysis_config_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_canary_analysis_run;
+
+-- Table Definition
+CREATE TABLE "public"."canary_analysis_run"
+(
+    "id"                             integer     NOT NULL DEFAULT nextval('id_seq_canary_analysis_run'::regclass),
+    "pipeline_id"                    integer     NOT NULL,
+    "cd_workflow_runner_id"          integer     NOT NULL,
+    "rollback_cd_workflow_runner_id" integer,
+    "status"                         varchar(50) NOT NULL,
+    "intervals_completed"            integer     NOT NULL DEFAULT 0,
+    "intervals_failed"               integer     NOT NULL DEFAULT 0,
+    "next_analysis_on"               timestamptz NOT NULL,
+    "message"                        text,
+    "created_on"                     timestamptz NOT NULL,
+    "created_by"                     int4        NOT NULL,
+    "updated_on"                     timestamptz NOT NULL,
+    "updated_by"                     int4        NOT NULL,
+    CONSTRAINT "canary_analysis_run_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    CONSTRAINT "canary_analysis_run_cd_workflow_runner_id_fkey" FOREIGN KEY ("cd_workflow_runner_id") REFERENCES "public"."cd_workflow_runner" ("id"),
+    CONSTRAINT "canary_analysis_run_cd_workflow_runner_id_key" UNIQUE ("cd_workflow_runner_id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS canary_analysis_run_status_idx ON public.canary_analysis_run (status);
@@ -0,0 +1,15 @@
+ALTER TABLE ci_artifact
+    DROP COLUMN IF EXISTS signature,
+    DROP COLUMN IF EXISTS signature_payload,
+    DROP COLUMN IF EXISTS signing_key_fingerprint,
+    DROP COLUMN IF EXISTS signed_on;
+
+DROP TABLE IF EXISTS "public"."image_signature_policy";
+
+DROP SEQUENCE IF EXISTS id_seq_image_signature_policy;
+
+DROP INDEX IF EXISTS image_signing_key_name_active_idx;
+
+DROP TABLE IF EXISTS "public"."image_signing_key";
+
+DROP SEQUENCE IF EXISTS id_seq_image_signing_key;
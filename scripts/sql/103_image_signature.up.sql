@@ -0,0 +1,46 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_image_signing_key;
+
+-- Table Definition
+CREATE TABLE "public"."image_signing_key"
+(
+    "id"                integer      NOT NULL DEFAULT nextval('id_seq_image_signing_key'::regclass),
+    "name"              varchar(100) NOT NULL,
+    "public_key"        text         NOT NULL,
+    "fingerprint"       varchar(64)  NOT NULL,
+    "has_private_key"   bool         NOT NULL DEFAULT false,
+    "sign_ci_artifacts" bool         NOT NULL DEFAULT false,
+    "active"            bool         NOT NULL DEFAULT true,
+    "created_on"        timestamptz  NOT NULL,
+    "created_by"        int4         NOT NULL,
+    "updated_on"        timestamptz  NOT NULL,
+    "updated_by"        int4         NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS image_signing_key_name_active_idx ON image_signing_key (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_image_signature_policy;
+
+-- Table Definition
+CREATE TABLE "public"."image_signature_policy"
+(
+    "id"              integer     NOT NULL DEFAULT nextval('id_seq_image_signature_policy'::regclass),
+    "cluster_id"      integer,
+    "environment_id"  integer,
+    "enforce"         bool        NOT NULL DEFAULT false,
+    "trusted_key_ids" integer[],
+    "active"          bool        NOT NULL DEFAULT true,
+    "created_on"      timestamptz NOT NULL,
+    "created_by"      int4        NOT NULL,
+    "updated_on"      timestamptz NOT NULL,
+    "updated_by"      int4        NOT NULL,
+    CONSTRAINT "image_signature_policy_cluster_id_fkey" FOREIGN KEY ("cluster_id") REFERENCES "public"."cluster" ("id"),
+    CONSTRAINT "image_signature_policy_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    PRIMARY KEY ("id")
+);
+
+ALTER TABLE ci_artifact
+    ADD COLUMN IF NOT EXISTS signature text,
+    ADD COLUMN IF NOT EXISTS signature_payload text,
+    ADD COLUMN IF NOT EXISTS signing_key_fingerprint varchar(64),
+    ADD COLUMN IF NOT EXISTS signed_on timestamptz;
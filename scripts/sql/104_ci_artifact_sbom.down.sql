@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS ci_artifact_sbom_package_ci_artifact_id_idx;
+
+DROP INDEX IF EXISTS ci_artifact_sbom_package_name_idx;
+
+DROP TABLE IF EXISTS "public"."ci_artifact_sbom_package";
+
+DROP SEQUENCE IF EXISTS id_seq_ci_artifact_sbom_package;
+
+DROP TABLE IF EXISTS "public"."ci_artifact_sbom";
+
+DROP SEQUENCE IF EXISTS id_seq_ci_artifact_sbom;
@@ -0,0 +1,39 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_artifact_sbom;
+
+-- Table Definition
+CREATE TABLE "public"."ci_artifact_sbom"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_ci_artifact_sbom'::regclass),
+    "ci_artifact_id" integer     NOT NULL,
+    "format"         varchar(50) NOT NULL,
+    "spec_version"   varchar(50),
+    "document"       text        NOT NULL,
+    "package_count"  integer     NOT NULL DEFAULT 0,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     int4        NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     int4        NOT NULL,
+    CONSTRAINT "ci_artifact_sbom_ci_artifact_id_fkey" FOREIGN KEY ("ci_artifact_id") REFERENCES "public"."ci_artifact" ("id"),
+    UNIQUE ("ci_artifact_id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_artifact_sbom_package;
+
+-- Table Definition
+CREATE TABLE "public"."ci_artifact_sbom_package"
+(
+    "id"                  integer      NOT NULL DEFAULT nextval('id_seq_ci_artifact_sbom_package'::regclass),
+    "ci_artifact_sbom_id" integer      NOT NULL,
+    "ci_artifact_id"      integer      NOT NULL,
+    "name"                varchar(500) NOT NULL,
+    "version"             varchar(250),
+    "package_type"        varchar(100),
+    "purl"                text,
+    CONSTRAINT "ci_artifact_sbom_package_ci_artifact_sbom_id_fkey" FOREIGN KEY ("ci_artifact_sbom_id") REFERENCES "public"."ci_artifact_sbom" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS ci_artifact_sbom_package_name_idx ON ci_artifact_sbom_package (LOWER(name), version);
+
+CREATE INDEX IF NOT EXISTS ci_artifact_sbom_package_ci_artifact_id_idx ON ci_artifact_sbom_package (ci_artifact_id);
@@ -0,0 +1,7 @@
+DELETE FROM "public"."notification_templates" WHERE event_type_id = 8;
+
+DELETE FROM "public"."event" WHERE id = 8;
+
+DROP TABLE IF EXISTS "public"."cve_exception";
+
+DROP SEQUENCE IF EXISTS public.id_seq_cve_exception;
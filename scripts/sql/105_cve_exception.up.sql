@@ -0,0 +1,83 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_cve_exception;
+
+-- Table Definition
+CREATE TABLE "public"."cve_exception"
+(
+    "id"              integer      NOT NULL DEFAULT nextval('id_seq_cve_exception'::regclass),
+    "cve_store_name"  varchar(255) NOT NULL,
+    "app_id"          integer      NOT NULL,
+    "environment_id"  integer      NOT NULL,
+    "justification"   text         NOT NULL,
+    "status"          varchar(50)  NOT NULL,
+    "requested_by"    int4         NOT NULL,
+    "reviewed_by"     int4,
+    "reviewed_on"     timestamptz,
+    "review_comment"  text,
+    "expires_on"      timestamptz  NOT NULL,
+    "expiry_notified" bool         NOT NULL DEFAULT false,
+    "created_on"      timestamptz  NOT NULL,
+    "created_by"      int4         NOT NULL,
+    "updated_on"      timestamptz  NOT NULL,
+    "updated_by"      int4         NOT NULL,
+    CONSTRAINT "cve_exception_cve_store_name_fkey" FOREIGN KEY ("cve_store_name") REFERENCES "public"."cve_store" ("name"),
+    CONSTRAINT "cve_exception_app_id_fkey" FOREIGN KEY ("app_id") REFERENCES "public"."app" ("id"),
+    CONSTRAINT "cve_exception_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS cve_exception_app_id_environment_id_idx ON public.cve_exception (app_id, environment_id);
+
+CREATE INDEX IF NOT EXISTS cve_exception_status_expires_on_idx ON public.cve_exception (status, expires_on);
+
+INSERT INTO "public"."event" ("id", "event_type", "description") VALUES ('8', 'CVE_EXCEPTION_EXPIRING', '');
+
+INSERT INTO "public"."notification_templates" ("channel_type", "node_type", "event_type_id", "template_name", "template_payload") VALUES
+('slack', 'CD', '8', 'CD cve exception expiring slack template', '{
+    "text": ":hourglass: CVE exception expiring | {{cveName}} | Application > {{appName}} | Environment > {{envName}}",
+    "blocks": [{
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": "\n"
+            }
+        },
+        {
+            "type": "divider"
+        },
+        {
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": ":hourglass: *CVE exception for {{cveName}} expires on {{expiresOn}}*\n<!date^{{eventTime}}^{date_long} {time} | \"-\">"
+            }
+        },
+        {
+            "type": "section",
+            "fields": [{
+                    "type": "mrkdwn",
+                    "text": "*Application*\n{{appName}}\n*Pipeline*\n{{pipelineName}}"
+                },
+                {
+                    "type": "mrkdwn",
+                    "text": "*Environment*\n{{envName}}"
+                }
+            ]
+        },
+        {
+            "type": "actions",
+            "elements": [{
+                "type": "button",
+                "text": {
+                    "type": "plain_text",
+                    "text": "App Details",
+                    "emoji": true
+                },
+                "url": "{{& appDetailsLink}}"
+            }]
+        }
+    ]
+}'),
+('ses', 'CD', '8', 'CD cve exception expiring ses template', '{"from": "{{fromEmail}}",
+ "to": "{{toEmail}}",
+ "subject": "CVE exception for {{cveName}} expires on {{expiresOn}} for app: {{appName}} on environment: {{envName}}",
+ "html": "<h2 style=\"color:#767d84;\">CVE Exception Expiring</h2><span>{{eventTime}}</span><br><br>{{#appDetailsLink}}<a href=\"{{& appDetailsLink}}\" style=\"height:32px;padding:7px 12px;line-height:32px;font-size:12px;font-weight:600;border-radius:4px;text-decoration:none;outline:none;min-width:64px;text-transform:capitalize;text-align:center;background:#0066cc;color:#fff;border:1px solid transparent;cursor:pointer;\">App Details</a><br><br>{{/appDetailsLink}}<hr><br><span>CVE: <strong>{{cveName}}</strong></span>&nbsp;&nbsp;|&nbsp;&nbsp;<span>Expires On: <strong>{{expiresOn}}</strong></span><br><br><span>Application: <strong>{{appName}}</strong></span>&nbsp;&nbsp;|&nbsp;&nbsp;<span>Pipeline: <strong>{{pipelineName}}</strong></span><br><br><span>Environment: <strong>{{envName}}</strong></span><br>"}');
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "public"."user_role_grant_audit";
+
+DROP SEQUENCE IF EXISTS public.id_seq_user_role_grant_audit;
+
+DROP TABLE IF EXISTS "public"."user_role_grant";
+
+DROP SEQUENCE IF EXISTS public.id_seq_user_role_grant;
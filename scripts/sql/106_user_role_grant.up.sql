@@ -0,0 +1,53 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_user_role_grant;
+
+-- Table Definition
+CREATE TABLE "public"."user_role_grant"
+(
+    "id"                integer      NOT NULL DEFAULT nextval('id_seq_user_role_grant'::regclass),
+    "user_id"           integer      NOT NULL,
+    "entity"            varchar(100),
+    "team"              varchar(100),
+    "entity_name"       text,
+    "environment"       text,
+    "action"            varchar(100) NOT NULL,
+    "access_type"       varchar(100),
+    "reason"            text         NOT NULL,
+    "duration_minutes"  integer      NOT NULL,
+    "status"            varchar(50)  NOT NULL,
+    "requires_approval" bool         NOT NULL,
+    "requested_by"      integer      NOT NULL,
+    "reviewed_by"       integer,
+    "reviewed_on"       timestamptz,
+    "activated_on"      timestamptz,
+    "expires_on"        timestamptz,
+    "revoked_on"        timestamptz,
+    "role_ids"          integer[],
+    "created_on"        timestamptz  NOT NULL,
+    "created_by"        int4         NOT NULL,
+    "updated_on"        timestamptz  NOT NULL,
+    "updated_by"        int4         NOT NULL,
+    CONSTRAINT "user_role_grant_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS user_role_grant_user_id_idx ON public.user_role_grant (user_id);
+
+CREATE INDEX IF NOT EXISTS user_role_grant_status_expires_on_idx ON public.user_role_grant (status, expires_on);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_user_role_grant_audit;
+
+-- Table Definition
+CREATE TABLE "public"."user_role_grant_audit"
+(
+    "id"           integer     NOT NULL DEFAULT nextval('id_seq_user_role_grant_audit'::regclass),
+    "grant_id"     integer     NOT NULL,
+    "user_id"      integer     NOT NULL,
+    "action"       varchar(50) NOT NULL,
+    "performed_by" integer     NOT NULL,
+    "comment"      text,
+    "created_on"   timestamptz NOT NULL,
+    CONSTRAINT "user_role_grant_audit_grant_id_fkey" FOREIGN KEY ("grant_id") REFERENCES "public"."user_role_grant" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS user_role_grant_audit_grant_id_idx ON public.user_role_grant_audit (grant_id);
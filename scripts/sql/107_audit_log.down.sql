@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."audit_log";
+
+DROP SEQUENCE IF EXISTS public.id_seq_audit_log;
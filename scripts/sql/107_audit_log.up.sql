@@ -0,0 +1,29 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_audit_log;
+
+-- Table Definition
+CREATE TABLE "public"."audit_log"
+(
+    "id"            integer      NOT NULL DEFAULT nextval('id_seq_audit_log'::regclass),
+    "source"        varchar(20)  NOT NULL,
+    "user_id"       integer,
+    "email_id"      varchar(250),
+    "resource_type" varchar(50)  NOT NULL,
+    "resource_id"   varchar(250),
+    "action"        varchar(50)  NOT NULL,
+    "method"        varchar(10),
+    "path"          text,
+    "status_code"   integer,
+    "client_ip"     varchar(100),
+    "request_body"  text,
+    "before_state"  text,
+    "after_state"   text,
+    "duration_ms"   integer,
+    "created_on"    timestamptz  NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_on_idx ON public.audit_log (created_on);
+
+CREATE INDEX IF NOT EXISTS audit_log_user_id_idx ON public.audit_log (user_id);
+
+CREATE INDEX IF NOT EXISTS audit_log_resource_idx ON public.audit_log (resource_type, resource_id);
@@ -0,0 +1,4 @@
+ALTER TABLE "public"."api_token"
+    DROP COLUMN IF EXISTS "role_filters",
+    DROP COLUMN IF EXISTS "scoped_roles",
+    DROP COLUMN IF EXISTS "allowed_ips";
@@ -0,0 +1,4 @@
+ALTER TABLE "public"."api_token"
+    ADD COLUMN IF NOT EXISTS "role_filters" jsonb,
+    ADD COLUMN IF NOT EXISTS "scoped_roles" text[],
+    ADD COLUMN IF NOT EXISTS "allowed_ips"  text[];
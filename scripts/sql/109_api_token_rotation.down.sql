@@ -0,0 +1,6 @@
+DELETE FROM "public"."event" WHERE id = 9;
+
+ALTER TABLE "public"."api_token"
+    DROP COLUMN IF EXISTS "previous_token",
+    DROP COLUMN IF EXISTS "previous_token_expire_at",
+    DROP COLUMN IF EXISTS "expiry_notified";
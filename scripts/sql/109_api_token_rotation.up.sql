@@ -0,0 +1,6 @@
+ALTER TABLE "public"."api_token"
+    ADD COLUMN IF NOT EXISTS "previous_token"           text,
+    ADD COLUMN IF NOT EXISTS "previous_token_expire_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "expiry_notified"          bool NOT NULL DEFAULT false;
+
+INSERT INTO "public"."event" ("id", "event_type", "description") VALUES ('9', 'API_TOKEN_EXPIRING', '');
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "public"."rbac_deny_rule";
+
+DROP SEQUENCE IF EXISTS public.id_seq_rbac_deny_rule;
+
+DROP TABLE IF EXISTS "public"."custom_role";
+
+DROP SEQUENCE IF EXISTS public.id_seq_custom_role;
@@ -0,0 +1,44 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_custom_role;
+
+-- Table Definition
+CREATE TABLE "public"."custom_role"
+(
+    "id"          integer      NOT NULL DEFAULT nextval('id_seq_custom_role'::regclass),
+    "name"        varchar(100) NOT NULL,
+    "description" text,
+    "permissions" jsonb        NOT NULL,
+    "active"      bool         NOT NULL,
+    "created_on"  timestamptz  NOT NULL,
+    "created_by"  int4         NOT NULL,
+    "updated_on"  timestamptz  NOT NULL,
+    "updated_by"  int4         NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS custom_role_name_active_idx ON public.custom_role (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_rbac_deny_rule;
+
+-- Table Definition
+CREATE TABLE "public"."rbac_deny_rule"
+(
+    "id"             integer      NOT NULL DEFAULT nextval('id_seq_rbac_deny_rule'::regclass),
+    "subject_type"   varchar(50)  NOT NULL,
+    "subject"        varchar(250) NOT NULL,
+    "casbin_subject" varchar(250) NOT NULL,
+    "resource"       varchar(100) NOT NULL,
+    "action"         varchar(100) NOT NULL,
+    "team"           varchar(100),
+    "environment"    text,
+    "entity_name"    text,
+    "object"         text         NOT NULL,
+    "description"    text,
+    "active"         bool         NOT NULL,
+    "created_on"     timestamptz  NOT NULL,
+    "created_by"     int4         NOT NULL,
+    "updated_on"     timestamptz  NOT NULL,
+    "updated_by"     int4         NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS rbac_deny_rule_casbin_subject_idx ON public.rbac_deny_rule (casbin_subject) WHERE active = true;
@@ -0,0 +1,3 @@
+DELETE FROM "public"."notification_templates" WHERE event_type_id = 10;
+
+DELETE FROM "public"."event" WHERE id = 10;
@@ -0,0 +1,59 @@
+INSERT INTO "public"."event" ("id", "event_type", "description") VALUES ('10', 'APPROVAL_ACTIONED', '');
+
+INSERT INTO "public"."notification_templates" ("channel_type", "node_type", "event_type_id", "template_name", "template_payload") VALUES
+('slack', 'CD', '10', 'CD approval actioned slack template', '{
+    "text": ":white_check_mark: Deployment approval {{approvalStatus}} | Application > {{appName}} | Environment > {{envName}}",
+    "blocks": [{
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": "\n"
+            }
+        },
+        {
+            "type": "divider"
+        },
+        {
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": ":white_check_mark: *Deployment approval {{approvalStatus}}*\n<!date^{{eventTime}}^{date_long} {time} | \"-\"> \n By {{triggeredBy}}"
+            }
+        },
+        {
+            "type": "section",
+            "fields": [{
+                    "type": "mrkdwn",
+                    "text": "*Application*\n{{appName}}\n*Pipeline*\n{{pipelineName}}"
+                },
+                {
+                    "type": "mrkdwn",
+                    "text": "*Environment*\n{{envName}}"
+                }
+            ]
+        },
+        {
+            "type": "section",
+            "fields": [{
+                "type": "mrkdwn",
+                "text": "*Docker Image*\n`{{dockerImageUrl}}`"
+            }]
+        },
+        {
+            "type": "actions",
+            "elements": [{
+                "type": "button",
+                "text": {
+                    "type": "plain_text",
+                    "text": "View Pipeline",
+                    "emoji": true
+                },
+                "url": "{{& appDetailsLink}}"
+            }]
+        }
+    ]
+}'),
+('ses', 'CD', '10', 'CD approval actioned ses template', '{"from": "{{fromEmail}}",
+ "to": "{{toEmail}}",
+ "subject": "Deployment approval {{approvalStatus}} for app: {{appName}} on environment: {{envName}}",
+ "html": "<h2 style=\"color:#767d84;\">Deployment Approval {{approvalStatus}}</h2><span>{{eventTime}}</span><br><span>By <strong>{{triggeredBy}}</strong></span><br><br>{{#appDetailsLink}}<a href=\"{{& appDetailsLink}}\" style=\"height:32px;padding:7px 12px;line-height:32px;font-size:12px;font-weight:600;border-radius:4px;text-decoration:none;outline:none;min-width:64px;text-transform:capitalize;text-align:center;background:#0066cc;color:#fff;border:1px solid transparent;cursor:pointer;\">App Details</a><br><br>{{/appDetailsLink}}<hr><br><span>Application: <strong>{{appName}}</strong></span>&nbsp;&nbsp;|&nbsp;&nbsp;<span>Pipeline: <strong>{{pipelineName}}</strong></span><br><br><span>Environment: <strong>{{envName}}</strong></span><br><br><hr><h3>Image</h3><span>Docker Image: <strong>{{dockerImageUrl}}</strong></span><br>"}');
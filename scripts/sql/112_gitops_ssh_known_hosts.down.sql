@@ -0,0 +1,3 @@
+---- ALTER TABLE gitops_config - drop column
+ALTER TABLE gitops_config
+    DROP COLUMN IF EXISTS ssh_known_hosts;
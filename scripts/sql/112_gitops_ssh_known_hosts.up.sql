@@ -0,0 +1,3 @@
+---- ALTER TABLE gitops_config - add column
+ALTER TABLE gitops_config
+    ADD COLUMN IF NOT EXISTS ssh_known_hosts TEXT;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "public"."bulk_operation_job_item";
+
+DROP SEQUENCE IF EXISTS id_seq_bulk_operation_job_item;
+
+DROP TABLE IF EXISTS "public"."bulk_operation_job";
+
+DROP SEQUENCE IF EXISTS id_seq_bulk_operation_job;
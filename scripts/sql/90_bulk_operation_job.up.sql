@@ -0,0 +1,43 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_bulk_operation_job;
+
+-- Table Definition
+CREATE TABLE "public"."bulk_operation_job"
+(
+    "id"                integer     NOT NULL DEFAULT nextval('id_seq_bulk_operation_job'::regclass),
+    "operation"         varchar(50) NOT NULL,
+    "status"            varchar(50) NOT NULL,
+    "payload"           text,
+    "reverted_job_id"   integer,
+    "created_on"        timestamptz NOT NULL,
+    "created_by"        int4        NOT NULL,
+    "updated_on"        timestamptz NOT NULL,
+    "updated_by"        int4        NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_bulk_operation_job_item;
+
+-- Table Definition
+CREATE TABLE "public"."bulk_operation_job_item"
+(
+    "id"            integer     NOT NULL DEFAULT nextval('id_seq_bulk_operation_job_item'::regclass),
+    "job_id"        integer     NOT NULL,
+    "item_type"     varchar(50) NOT NULL,
+    "app_id"        integer,
+    "app_name"      text,
+    "env_id"        integer,
+    "pipeline_id"   integer,
+    "pipeline_name" text,
+    "names"         text,
+    "history_id"    integer,
+    "status"        varchar(50) NOT NULL,
+    "message"       text,
+    "created_on"    timestamptz NOT NULL,
+    "created_by"    int4        NOT NULL,
+    "updated_on"    timestamptz NOT NULL,
+    "updated_by"    int4        NOT NULL,
+    CONSTRAINT "bulk_operation_job_item_job_id_fkey" FOREIGN KEY ("job_id") REFERENCES "public"."bulk_operation_job" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS bulk_operation_job_item_job_id_idx ON public.bulk_operation_job_item (job_id);
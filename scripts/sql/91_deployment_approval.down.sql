@@ -0,0 +1,15 @@
+DELETE FROM "public"."notification_templates" WHERE event_type_id = 4;
+
+DELETE FROM "public"."event" WHERE id = 4;
+
+DROP TABLE IF EXISTS "public"."deployment_approval_user_action";
+
+DROP SEQUENCE IF EXISTS public.id_seq_deployment_approval_user_action;
+
+DROP TABLE IF EXISTS "public"."deployment_approval_request";
+
+DROP SEQUENCE IF EXISTS public.id_seq_deployment_approval_request;
+
+DROP TABLE IF EXISTS "public"."deployment_approval_config";
+
+DROP SEQUENCE IF EXISTS public.id_seq_deployment_approval_config;
@@ -0,0 +1,122 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_approval_config;
+
+-- Table Definition
+CREATE TABLE "public"."deployment_approval_config"
+(
+    "id"                      integer   NOT NULL DEFAULT nextval('id_seq_deployment_approval_config'::regclass),
+    "pipeline_id"             integer   NOT NULL,
+    "required_approvals"      integer   NOT NULL,
+    "approver_user_ids"       integer[],
+    "approver_role_group_ids" integer[],
+    "request_expiry_hours"    integer   NOT NULL,
+    "allow_self_approval"     bool      NOT NULL DEFAULT false,
+    "active"                  bool      NOT NULL,
+    "created_on"              timestamptz NOT NULL,
+    "created_by"              int4      NOT NULL,
+    "updated_on"              timestamptz NOT NULL,
+    "updated_by"              int4      NOT NULL,
+    CONSTRAINT "deployment_approval_config_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_approval_request;
+
+-- Table Definition
+CREATE TABLE "public"."deployment_approval_request"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_deployment_approval_request'::regclass),
+    "pipeline_id"    integer     NOT NULL,
+    "ci_artifact_id" integer     NOT NULL,
+    "status"         varchar(50) NOT NULL,
+    "comment"        text,
+    "expires_on"     timestamptz NOT NULL,
+    "auto_trigger"   bool        NOT NULL DEFAULT false,
+    "auto_triggered" bool        NOT NULL DEFAULT false,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     int4        NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     int4        NOT NULL,
+    CONSTRAINT "deployment_approval_request_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    CONSTRAINT "deployment_approval_request_ci_artifact_id_fkey" FOREIGN KEY ("ci_artifact_id") REFERENCES "public"."ci_artifact" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS deployment_approval_request_pipeline_id_ci_artifact_id_idx ON public.deployment_approval_request (pipeline_id, ci_artifact_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_approval_user_action;
+
+-- Table Definition
+CREATE TABLE "public"."deployment_approval_user_action"
+(
+    "id"                  integer     NOT NULL DEFAULT nextval('id_seq_deployment_approval_user_action'::regclass),
+    "approval_request_id" integer     NOT NULL,
+    "user_id"             integer     NOT NULL,
+    "action"              varchar(50) NOT NULL,
+    "comment"             text,
+    "created_on"          timestamptz NOT NULL,
+    "created_by"          int4        NOT NULL,
+    "updated_on"          timestamptz NOT NULL,
+    "updated_by"          int4        NOT NULL,
+    CONSTRAINT "deployment_approval_user_action_approval_request_id_fkey" FOREIGN KEY ("approval_request_id") REFERENCES "public"."deployment_approval_request" ("id"),
+    PRIMARY KEY ("id")
+);
+
+INSERT INTO "public"."event" ("id", "event_type", "description") VALUES ('4', 'APPROVAL', '');
+
+INSERT INTO "public"."notification_templates" ("channel_type", "node_type", "event_type_id", "template_name", "template_payload") VALUES
+('slack', 'CD', '4', 'CD approval slack template', '{
+    "text": ":raised_hand: Deployment approval requested | Application > {{appName}} | Environment > {{envName}}",
+    "blocks": [{
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": "\n"
+            }
+        },
+        {
+            "type": "divider"
+        },
+        {
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": ":raised_hand: *Deployment approval requested*\n<!date^{{eventTime}}^{date_long} {time} | \"-\"> \n Requested by {{triggeredBy}}"
+            }
+        },
+        {
+            "type": "section",
+            "fields": [{
+                    "type": "mrkdwn",
+                    "text": "*Application*\n{{appName}}\n*Pipeline*\n{{pipelineName}}"
+                },
+                {
+                    "type": "mrkdwn",
+                    "text": "*Environment*\n{{envName}}"
+                }
+            ]
+        },
+        {
+            "type": "section",
+            "fields": [{
+                "type": "mrkdwn",
+                "text": "*Docker Image*\n`{{dockerImageUrl}}`"
+            }]
+        },
+        {
+            "type": "actions",
+            "elements": [{
+                "type": "button",
+                "text": {
+                    "type": "plain_text",
+                    "text": "View Pipeline",
+                    "emoji": true
+                },
+                "url": "{{& appDetailsLink}}"
+            }]
+        }
+    ]
+}'),
+('ses', 'CD', '4', 'CD approval ses template', '{"from": "{{fromEmail}}",
+ "to": "{{toEmail}}",
+ "subject": "Deployment approval requested for app: {{appName}} on environment: {{envName}}",
+ "html": "<h2 style=\"color:#767d84;\">Deployment Approval Requested</h2><span>{{eventTime}}</span><br><span>Requested by <strong>{{triggeredBy}}</strong></span><br><br>{{#appDetailsLink}}<a href=\"{{& appDetailsLink}}\" style=\"height:32px;padding:7px 12px;line-height:32px;font-size:12px;font-weight:600;border-radius:4px;text-decoration:none;outline:none;min-width:64px;text-transform:capitalize;text-align:center;background:#0066cc;color:#fff;border:1px solid transparent;cursor:pointer;\">App Details</a><br><br>{{/appDetailsLink}}<hr><br><span>Application: <strong>{{appName}}</strong></span>&nbsp;&nbsp;|&nbsp;&nbsp;<span>Pipeline: <strong>{{pipelineName}}</strong></span><br><br><span>Environment: <strong>{{envName}}</strong></span><br><br><hr><h3>Image</h3><span>Docker Image: <strong>{{dockerImageUrl}}</strong></span><br>"}');
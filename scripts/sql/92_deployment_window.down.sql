@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "public"."deployment_window_queue";
+DROP TABLE IF EXISTS "public"."deployment_window_override_audit";
+DROP TABLE IF EXISTS "public"."deployment_window";
+DROP TABLE IF EXISTS "public"."deployment_window_policy";
+
+DROP SEQUENCE IF EXISTS id_seq_deployment_window_queue;
+DROP SEQUENCE IF EXISTS id_seq_deployment_window_override_audit;
+DROP SEQUENCE IF EXISTS id_seq_deployment_window;
+DROP SEQUENCE IF EXISTS id_seq_deployment_window_policy;
@@ -0,0 +1,84 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_window_policy;
+
+-- Table Definition
+CREATE TABLE "public"."deployment_window_policy"
+(
+    "id"             integer      NOT NULL DEFAULT nextval('id_seq_deployment_window_policy'::regclass),
+    "name"           varchar(250) NOT NULL,
+    "environment_id" integer,
+    "cluster_id"     integer,
+    "timezone"       varchar(100) NOT NULL DEFAULT 'UTC',
+    "active"         bool         NOT NULL,
+    "created_on"     timestamptz  NOT NULL,
+    "created_by"     int4         NOT NULL,
+    "updated_on"     timestamptz  NOT NULL,
+    "updated_by"     int4         NOT NULL,
+    CONSTRAINT "deployment_window_policy_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    CONSTRAINT "deployment_window_policy_cluster_id_fkey" FOREIGN KEY ("cluster_id") REFERENCES "public"."cluster" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_window;
+
+-- Table Definition
+CREATE TABLE "public"."deployment_window"
+(
+    "id"               integer     NOT NULL DEFAULT nextval('id_seq_deployment_window'::regclass),
+    "policy_id"        integer     NOT NULL,
+    "window_type"      varchar(50) NOT NULL,
+    "cron_expression"  varchar(100),
+    "duration_minutes" integer,
+    "start_time"       timestamptz,
+    "end_time"         timestamptz,
+    "description"      text,
+    "active"           bool        NOT NULL,
+    "created_on"       timestamptz NOT NULL,
+    "created_by"       int4        NOT NULL,
+    "updated_on"       timestamptz NOT NULL,
+    "updated_by"       int4        NOT NULL,
+    CONSTRAINT "deployment_window_policy_id_fkey" FOREIGN KEY ("policy_id") REFERENCES "public"."deployment_window_policy" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_window_override_audit;
+
+-- Table Definition
+CREATE TABLE "public"."deployment_window_override_audit"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_deployment_window_override_audit'::regclass),
+    "policy_id"      integer     NOT NULL,
+    "pipeline_id"    integer     NOT NULL,
+    "ci_artifact_id" integer     NOT NULL,
+    "reason"         text        NOT NULL,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     int4        NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     int4        NOT NULL,
+    CONSTRAINT "deployment_window_override_audit_policy_id_fkey" FOREIGN KEY ("policy_id") REFERENCES "public"."deployment_window_policy" ("id"),
+    CONSTRAINT "deployment_window_override_audit_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS deployment_window_override_audit_pipeline_id_ci_artifact_id_idx ON public.deployment_window_override_audit (pipeline_id, ci_artifact_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_window_queue;
+
+-- Table Definition
+CREATE TABLE "public"."deployment_window_queue"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_deployment_window_queue'::regclass),
+    "pipeline_id"    integer     NOT NULL,
+    "ci_artifact_id" integer     NOT NULL,
+    "triggered_by"   int4        NOT NULL,
+    "status"         varchar(50) NOT NULL,
+    "message"        text,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     int4        NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     int4        NOT NULL,
+    CONSTRAINT "deployment_window_queue_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    CONSTRAINT "deployment_window_queue_ci_artifact_id_fkey" FOREIGN KEY ("ci_artifact_id") REFERENCES "public"."ci_artifact" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS deployment_window_queue_status_idx ON public.deployment_window_queue (status);
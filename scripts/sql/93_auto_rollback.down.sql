@@ -0,0 +1,11 @@
+DELETE FROM "public"."notification_templates" WHERE event_type_id = 5;
+
+DELETE FROM "public"."event" WHERE id = 5;
+
+DROP TABLE IF EXISTS "public"."auto_rollback_audit";
+
+DROP SEQUENCE IF EXISTS public.id_seq_auto_rollback_audit;
+
+DROP TABLE IF EXISTS "public"."auto_rollback_policy";
+
+DROP SEQUENCE IF EXISTS public.id_seq_auto_rollback_policy;
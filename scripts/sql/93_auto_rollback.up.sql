@@ -0,0 +1,103 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_auto_rollback_policy;
+
+-- Table Definition
+CREATE TABLE "public"."auto_rollback_policy"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_auto_rollback_policy'::regclass),
+    "pipeline_id"    integer     NOT NULL,
+    "on_degraded"    bool        NOT NULL DEFAULT true,
+    "on_failed"      bool        NOT NULL DEFAULT true,
+    "window_minutes" integer     NOT NULL,
+    "active"         bool        NOT NULL,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     int4        NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     int4        NOT NULL,
+    CONSTRAINT "auto_rollback_policy_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_auto_rollback_audit;
+
+-- Table Definition
+CREATE TABLE "public"."auto_rollback_audit"
+(
+    "id"                             integer     NOT NULL DEFAULT nextval('id_seq_auto_rollback_audit'::regclass),
+    "pipeline_id"                    integer     NOT NULL,
+    "source_cd_workflow_runner_id"   integer     NOT NULL,
+    "target_cd_workflow_runner_id"   integer,
+    "rollback_cd_workflow_runner_id" integer,
+    "ci_artifact_id"                 integer,
+    "trigger_status"                 varchar(50) NOT NULL,
+    "status"                         varchar(50) NOT NULL,
+    "message"                        text,
+    "created_on"                     timestamptz NOT NULL,
+    "created_by"                     int4        NOT NULL,
+    "updated_on"                     timestamptz NOT NULL,
+    "updated_by"                     int4        NOT NULL,
+    CONSTRAINT "auto_rollback_audit_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    CONSTRAINT "auto_rollback_audit_source_cd_workflow_runner_id_key" UNIQUE ("source_cd_workflow_runner_id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS auto_rollback_audit_rollback_cd_workflow_runner_id_idx ON public.auto_rollback_audit (rollback_cd_workflow_runner_id);
+
+INSERT INTO "public"."event" ("id", "event_type", "description") VALUES ('5', 'AUTO_ROLLBACK', '');
+
+INSERT INTO "public"."notification_templates" ("channel_type", "node_type", "event_type_id", "template_name", "template_payload") VALUES
+('slack', 'CD', '5', 'CD auto rollback slack template', '{
+    "text": ":rewind: Deployment rolled back automatically | Application > {{appName}} | Environment > {{envName}}",
+    "blocks": [{
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": "\n"
+            }
+        },
+        {
+            "type": "divider"
+        },
+        {
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": ":rewind: *Deployment rolled back automatically*\n<!date^{{eventTime}}^{date_long} {time} | \"-\">"
+            }
+        },
+        {
+            "type": "section",
+            "fields": [{
+                    "type": "mrkdwn",
+                    "text": "*Application*\n{{appName}}\n*Pipeline*\n{{pipelineName}}"
+                },
+                {
+                    "type": "mrkdwn",
+                    "text": "*Environment*\n{{envName}}"
+                }
+            ]
+        },
+        {
+            "type": "section",
+            "fields": [{
+                "type": "mrkdwn",
+                "text": "*Rolled back to*\n`{{dockerImageUrl}}`"
+            }]
+        },
+        {
+            "type": "actions",
+            "elements": [{
+                "type": "button",
+                "text": {
+                    "type": "plain_text",
+                    "text": "App Details",
+                    "emoji": true
+                },
+                "url": "{{& appDetailsLink}}"
+            }]
+        }
+    ]
+}'),
+('ses', 'CD', '5', 'CD auto rollback ses template', '{"from": "{{fromEmail}}",
+ "to": "{{toEmail}}",
+ "subject": "Deployment rolled back automatically for app: {{appName}} on environment: {{envName}}",
+ "html": "<h2 style=\"color:#767d84;\">Deployment Rolled Back Automatically</h2><span>{{eventTime}}</span><br><br>{{#appDetailsLink}}<a href=\"{{& appDetailsLink}}\" style=\"height:32px;padding:7px 12px;line-height:32px;font-size:12px;font-weight:600;border-radius:4px;text-decoration:none;outline:none;min-width:64px;text-transform:capitalize;text-align:center;background:#0066cc;color:#fff;border:1px solid transparent;cursor:pointer;\">App Details</a><br><br>{{/appDetailsLink}}<hr><br><span>Application: <strong>{{appName}}</strong></span>&nbsp;&nbsp;|&nbsp;&nbsp;<span>Pipeline: <strong>{{pipelineName}}</strong></span><br><br><span>Environment: <strong>{{envName}}</strong></span><br><br><hr><h3>Rolled back to</h3><span>Docker Image: <strong>{{dockerImageUrl}}</strong></span><br>"}');
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "public"."artifact_promotion_audit";
+
+DROP SEQUENCE IF EXISTS public.id_seq_artifact_promotion_audit;
+
+DROP TABLE IF EXISTS "public"."artifact_promotion_policy";
+
+DROP SEQUENCE IF EXISTS public.id_seq_artifact_promotion_policy;
@@ -0,0 +1,39 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_artifact_promotion_policy;
+
+-- Table Definition
+CREATE TABLE "public"."artifact_promotion_policy"
+(
+    "id"                     integer     NOT NULL DEFAULT nextval('id_seq_artifact_promotion_policy'::regclass),
+    "pipeline_id"            integer     NOT NULL,
+    "source_environment_ids" integer[],
+    "require_post_cd"        bool        NOT NULL DEFAULT true,
+    "require_image_scan"     bool        NOT NULL DEFAULT false,
+    "active"                 bool        NOT NULL,
+    "created_on"             timestamptz NOT NULL,
+    "created_by"             int4        NOT NULL,
+    "updated_on"             timestamptz NOT NULL,
+    "updated_by"             int4        NOT NULL,
+    CONSTRAINT "artifact_promotion_policy_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_artifact_promotion_audit;
+
+-- Table Definition
+CREATE TABLE "public"."artifact_promotion_audit"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_artifact_promotion_audit'::regclass),
+    "pipeline_id"    integer     NOT NULL,
+    "ci_artifact_id" integer     NOT NULL,
+    "status"         varchar(50) NOT NULL,
+    "reason"         text,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     int4        NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     int4        NOT NULL,
+    CONSTRAINT "artifact_promotion_audit_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    CONSTRAINT "artifact_promotion_audit_ci_artifact_id_fkey" FOREIGN KEY ("ci_artifact_id") REFERENCES "public"."ci_artifact" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS artifact_promotion_audit_pipeline_id_idx ON public.artifact_promotion_audit (pipeline_id);
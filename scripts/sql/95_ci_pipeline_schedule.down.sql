@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."ci_pipeline_schedule";
+
+DROP SEQUENCE IF EXISTS public.id_seq_ci_pipeline_schedule;
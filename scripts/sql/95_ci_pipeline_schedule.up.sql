@@ -0,0 +1,27 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_pipeline_schedule;
+
+-- Table Definition
+CREATE TABLE "public"."ci_pipeline_schedule"
+(
+    "id"                  integer      NOT NULL DEFAULT nextval('id_seq_ci_pipeline_schedule'::regclass),
+    "ci_pipeline_id"      integer      NOT NULL,
+    "cron_expression"     varchar(100) NOT NULL,
+    "timezone"            varchar(100) NOT NULL DEFAULT 'UTC',
+    "branch"              varchar(250),
+    "only_on_new_commits" bool         NOT NULL DEFAULT false,
+    "active"              bool         NOT NULL,
+    "next_run_on"         timestamptz,
+    "last_triggered_on"   timestamptz,
+    "last_ci_workflow_id" integer,
+    "last_commit_hashes"  text,
+    "created_on"          timestamptz  NOT NULL,
+    "created_by"          int4         NOT NULL,
+    "updated_on"          timestamptz  NOT NULL,
+    "updated_by"          int4         NOT NULL,
+    CONSTRAINT "ci_pipeline_schedule_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_pipeline_schedule_ci_pipeline_id_idx ON public.ci_pipeline_schedule (ci_pipeline_id);
+
+CREATE INDEX IF NOT EXISTS ci_pipeline_schedule_next_run_on_idx ON public.ci_pipeline_schedule (next_run_on) WHERE active = true;
@@ -0,0 +1,11 @@
+DELETE FROM "public"."notification_templates" WHERE event_type_id = 6;
+
+DELETE FROM "public"."event" WHERE id = 6;
+
+DROP TABLE IF EXISTS "public"."hibernation_schedule_run";
+
+DROP SEQUENCE IF EXISTS public.id_seq_hibernation_schedule_run;
+
+DROP TABLE IF EXISTS "public"."hibernation_schedule";
+
+DROP SEQUENCE IF EXISTS public.id_seq_hibernation_schedule;
@@ -0,0 +1,99 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_hibernation_schedule;
+
+-- Table Definition
+CREATE TABLE "public"."hibernation_schedule"
+(
+    "id"                 integer      NOT NULL DEFAULT nextval('id_seq_hibernation_schedule'::regclass),
+    "name"               varchar(250) NOT NULL,
+    "environment_id"     integer      NOT NULL,
+    "app_label_selector" jsonb,
+    "hibernate_cron"     varchar(100) NOT NULL,
+    "wake_cron"          varchar(100),
+    "timezone"           varchar(100) NOT NULL DEFAULT 'UTC',
+    "skip_until"         timestamptz,
+    "next_hibernate_on"  timestamptz,
+    "next_wake_on"       timestamptz,
+    "active"             bool         NOT NULL,
+    "created_on"         timestamptz  NOT NULL,
+    "created_by"         int4         NOT NULL,
+    "updated_on"         timestamptz  NOT NULL,
+    "updated_by"         int4         NOT NULL,
+    CONSTRAINT "hibernation_schedule_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_hibernation_schedule_run;
+
+-- Table Definition
+CREATE TABLE "public"."hibernation_schedule_run"
+(
+    "id"                    integer     NOT NULL DEFAULT nextval('id_seq_hibernation_schedule_run'::regclass),
+    "schedule_id"           integer     NOT NULL,
+    "action"                varchar(50) NOT NULL,
+    "scheduled_on"          timestamptz NOT NULL,
+    "status"                varchar(50) NOT NULL,
+    "bulk_operation_job_id" integer,
+    "message"               text,
+    "created_on"            timestamptz NOT NULL,
+    "created_by"            int4        NOT NULL,
+    "updated_on"            timestamptz NOT NULL,
+    "updated_by"            int4        NOT NULL,
+    CONSTRAINT "hibernation_schedule_run_schedule_id_fkey" FOREIGN KEY ("schedule_id") REFERENCES "public"."hibernation_schedule" ("id"),
+    CONSTRAINT "hibernation_schedule_run_bulk_operation_job_id_fkey" FOREIGN KEY ("bulk_operation_job_id") REFERENCES "public"."bulk_operation_job" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS hibernation_schedule_run_schedule_id_idx ON public.hibernation_schedule_run (schedule_id);
+
+INSERT INTO "public"."event" ("id", "event_type", "description") VALUES ('6', 'HIBERNATION_FAILED', '');
+
+INSERT INTO "public"."notification_templates" ("channel_type", "node_type", "event_type_id", "template_name", "template_payload") VALUES
+('slack', 'CD', '6', 'CD scheduled hibernation failed slack template', '{
+    "text": ":zzz: Scheduled {{stage}} failed | Application > {{appName}} | Environment > {{envName}}",
+    "blocks": [{
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": "\n"
+            }
+        },
+        {
+            "type": "divider"
+        },
+        {
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": ":x: *Scheduled {{stage}} failed*\n<!date^{{eventTime}}^{date_long} {time} | \"-\">"
+            }
+        },
+        {
+            "type": "section",
+            "fields": [{
+                    "type": "mrkdwn",
+                    "text": "*Application*\n{{appName}}\n*Pipeline*\n{{pipelineName}}"
+                },
+                {
+                    "type": "mrkdwn",
+                    "text": "*Environment*\n{{envName}}"
+                }
+            ]
+        },
+        {
+            "type": "actions",
+            "elements": [{
+                "type": "button",
+                "text": {
+                    "type": "plain_text",
+                    "text": "App Details",
+                    "emoji": true
+                },
+                "url": "{{& appDetailsLink}}"
+            }]
+        }
+    ]
+}'),
+('ses', 'CD', '6', 'CD scheduled hibernation failed ses template', '{"from": "{{fromEmail}}",
+ "to": "{{toEmail}}",
+ "subject": "Scheduled {{stage}} failed for app: {{appName}} on environment: {{envName}}",
+ "html": "<h2 style=\"color:#767d84;\">Scheduled {{stage}} Failed</h2><span>{{eventTime}}</span><br><br>{{#appDetailsLink}}<a href=\"{{& appDetailsLink}}\" style=\"height:32px;padding:7px 12px;line-height:32px;font-size:12px;font-weight:600;border-radius:4px;text-decoration:none;outline:none;min-width:64px;text-transform:capitalize;text-align:center;background:#0066cc;color:#fff;border:1px solid transparent;cursor:pointer;\">App Details</a><br><br>{{/appDetailsLink}}<hr><br><span>Application: <strong>{{appName}}</strong></span>&nbsp;&nbsp;|&nbsp;&nbsp;<span>Pipeline: <strong>{{pipelineName}}</strong></span><br><br><span>Environment: <strong>{{envName}}</strong></span><br>"}');
@@ -0,0 +1,11 @@
+DELETE FROM "public"."notification_templates" WHERE event_type_id = 7;
+
+DELETE FROM "public"."event" WHERE id = 7;
+
+DROP TABLE IF EXISTS "public"."config_drift_resource";
+
+DROP SEQUENCE IF EXISTS public.id_seq_config_drift_resource;
+
+DROP TABLE IF EXISTS "public"."config_drift_scan";
+
+DROP SEQUENCE IF EXISTS public.id_seq_config_drift_scan;
@@ -0,0 +1,94 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_config_drift_scan;
+
+-- Table Definition
+CREATE TABLE "public"."config_drift_scan"
+(
+    "id"                     integer     NOT NULL DEFAULT nextval('id_seq_config_drift_scan'::regclass),
+    "pipeline_id"            integer     NOT NULL,
+    "app_id"                 integer     NOT NULL,
+    "environment_id"         integer     NOT NULL,
+    "status"                 varchar(50) NOT NULL,
+    "drifted_resource_count" integer     NOT NULL DEFAULT 0,
+    "message"                text,
+    "created_on"             timestamptz NOT NULL,
+    "created_by"             int4        NOT NULL,
+    "updated_on"             timestamptz NOT NULL,
+    "updated_by"             int4        NOT NULL,
+    CONSTRAINT "config_drift_scan_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS config_drift_scan_pipeline_id_idx ON public.config_drift_scan (pipeline_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_config_drift_resource;
+
+-- Table Definition
+CREATE TABLE "public"."config_drift_resource"
+(
+    "id"             integer      NOT NULL DEFAULT nextval('id_seq_config_drift_resource'::regclass),
+    "scan_id"        integer      NOT NULL,
+    "group"          varchar(250),
+    "version"        varchar(50)  NOT NULL,
+    "kind"           varchar(250) NOT NULL,
+    "name"           varchar(250) NOT NULL,
+    "namespace"      varchar(250),
+    "status"         varchar(50)  NOT NULL,
+    "drifted_fields" text[],
+    CONSTRAINT "config_drift_resource_scan_id_fkey" FOREIGN KEY ("scan_id") REFERENCES "public"."config_drift_scan" ("id") ON DELETE CASCADE,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS config_drift_resource_scan_id_idx ON public.config_drift_resource (scan_id);
+
+INSERT INTO "public"."event" ("id", "event_type", "description") VALUES ('7', 'CONFIG_DRIFT', '');
+
+INSERT INTO "public"."notification_templates" ("channel_type", "node_type", "event_type_id", "template_name", "template_payload") VALUES
+('slack', 'CD', '7', 'CD config drift slack template', '{
+    "text": ":warning: Config drift detected | Application > {{appName}} | Environment > {{envName}}",
+    "blocks": [{
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": "\n"
+            }
+        },
+        {
+            "type": "divider"
+        },
+        {
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": ":warning: *Config drift detected*\n<!date^{{eventTime}}^{date_long} {time} | \"-\">"
+            }
+        },
+        {
+            "type": "section",
+            "fields": [{
+                    "type": "mrkdwn",
+                    "text": "*Application*\n{{appName}}\n*Pipeline*\n{{pipelineName}}"
+                },
+                {
+                    "type": "mrkdwn",
+                    "text": "*Environment*\n{{envName}}"
+                }
+            ]
+        },
+        {
+            "type": "actions",
+            "elements": [{
+                "type": "button",
+                "text": {
+                    "type": "plain_text",
+                    "text": "App Details",
+                    "emoji": true
+                },
+                "url": "{{& appDetailsLink}}"
+            }]
+        }
+    ]
+}'),
+('ses', 'CD', '7', 'CD config drift ses template', '{"from": "{{fromEmail}}",
+ "to": "{{toEmail}}",
+ "subject": "Config drift detected for app: {{appName}} on environment: {{envName}}",
+ "html": "<h2 style=\"color:#767d84;\">Config Drift Detected</h2><span>{{eventTime}}</span><br><br>{{#appDetailsLink}}<a href=\"{{& appDetailsLink}}\" style=\"height:32px;padding:7px 12px;line-height:32px;font-size:12px;font-weight:600;border-radius:4px;text-decoration:none;outline:none;min-width:64px;text-transform:capitalize;text-align:center;background:#0066cc;color:#fff;border:1px solid transparent;cursor:pointer;\">App Details</a><br><br>{{/appDetailsLink}}<hr><br><span>Application: <strong>{{appName}}</strong></span>&nbsp;&nbsp;|&nbsp;&nbsp;<span>Pipeline: <strong>{{pipelineName}}</strong></span><br><br><span>Environment: <strong>{{envName}}</strong></span><br>"}');
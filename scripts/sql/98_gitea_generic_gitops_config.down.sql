@@ -0,0 +1,4 @@
+---- ALTER TABLE gitops_config - drop column
+ALTER TABLE gitops_config
+    DROP COLUMN IF EXISTS gitea_org_id,
+    DROP COLUMN IF EXISTS ssh_private_key;
\ No newline at end of file
@@ -0,0 +1,4 @@
+---- ALTER TABLE gitops_config - add column
+ALTER TABLE gitops_config
+    ADD COLUMN gitea_org_id TEXT,
+    ADD COLUMN ssh_private_key TEXT;
\ No newline at end of file
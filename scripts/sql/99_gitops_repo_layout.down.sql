@@ -0,0 +1,4 @@
+---- ALTER TABLE gitops_config - drop column
+ALTER TABLE gitops_config
+    DROP COLUMN IF EXISTS repo_layout,
+    DROP COLUMN IF EXISTS mono_repo_name;
@@ -0,0 +1,4 @@
+---- ALTER TABLE gitops_config - add column
+ALTER TABLE gitops_config
+    ADD COLUMN repo_layout VARCHAR(50) DEFAULT 'REPO_PER_APP',
+    ADD COLUMN mono_repo_name TEXT;
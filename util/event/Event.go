@@ -22,6 +22,13 @@ type EventType int
 const Trigger EventType = 1
 const Success EventType = 2
 const Fail EventType = 3
+const Approval EventType = 4
+const AutoRollback EventType = 5
+const HibernationFailed EventType = 6
+const ConfigDrift EventType = 7
+const CveExceptionExpiring EventType = 8
+const ApiTokenExpiring EventType = 9
+const ApprovalActioned EventType = 10
 
 type PipelineType string
 
@@ -19,10 +19,15 @@ package rbac
 
 import (
 	"fmt"
+	"net/http"
+
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/devtron-labs/devtron/pkg/team"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
 	"go.uber.org/zap"
 	"strings"
 )
@@ -47,6 +52,15 @@ type EnforcerUtil interface {
 	GetHelmObjectByProjectIdAndEnvId(teamId int, envId int) (string, string)
 	GetEnvRBACNameByCdPipelineIdAndEnvId(cdPipelineId int, envId int) string
 	GetAppRBACNameByTeamIdAndAppId(teamId int, appId int) string
+	// IsSecretActionDenied checks the deny rules of the user on the secrets of the app, base secrets (envId 0) are
+	// denied only by rules on all environments. Secrets are allowed through environment, this is checked wherever
+	// secrets are written so that the deny applies to every path changing them
+	IsSecretActionDenied(userId int32, action string, appId int, envId int) bool
+	// CheckAppEnvAuth enforces the action on both the app and its environment
+	CheckAppEnvAuth(token string, appId int, envId int, action string) bool
+	// CheckCdPipelineAuth enforces the action on both the app and the environment of the cd pipeline, an api error
+	// with forbidden status is returned when the user is not authorized
+	CheckCdPipelineAuth(token string, pipelineId int, action string) (*pipelineConfig.Pipeline, error)
 }
 type EnforcerUtilImpl struct {
 	logger                *zap.SugaredLogger
@@ -56,13 +70,16 @@ type EnforcerUtilImpl struct {
 	pipelineRepository    pipelineConfig.PipelineRepository
 	ciPipelineRepository  pipelineConfig.CiPipelineRepository
 	clusterRepository     repository.ClusterRepository
+	userRepository        repository2.UserRepository
+	enforcer              casbin.Enforcer
 	*EnforcerUtilHelmImpl
 }
 
 func NewEnforcerUtilImpl(logger *zap.SugaredLogger, teamRepository team.TeamRepository,
 	appRepo app.AppRepository, environmentRepository repository.EnvironmentRepository,
 	pipelineRepository pipelineConfig.PipelineRepository, ciPipelineRepository pipelineConfig.CiPipelineRepository,
-	clusterRepository repository.ClusterRepository) *EnforcerUtilImpl {
+	clusterRepository repository.ClusterRepository, userRepository repository2.UserRepository,
+	enforcer casbin.Enforcer) *EnforcerUtilImpl {
 	return &EnforcerUtilImpl{
 		logger:                logger,
 		teamRepository:        teamRepository,
@@ -71,6 +88,8 @@ func NewEnforcerUtilImpl(logger *zap.SugaredLogger, teamRepository team.TeamRepo
 		pipelineRepository:    pipelineRepository,
 		ciPipelineRepository:  ciPipelineRepository,
 		clusterRepository:     clusterRepository,
+		userRepository:        userRepository,
+		enforcer:              enforcer,
 		EnforcerUtilHelmImpl: &EnforcerUtilHelmImpl{
 			logger:            logger,
 			clusterRepository: clusterRepository,
@@ -416,3 +435,40 @@ func (impl EnforcerUtilImpl) GetAppRBACNameByTeamIdAndAppId(teamId int, appId in
 	}
 	return fmt.Sprintf("%s/%s", strings.ToLower(team.Name), strings.ToLower(application.AppName))
 }
+
+func (impl EnforcerUtilImpl) IsSecretActionDenied(userId int32, action string, appId int, envId int) bool {
+	user, err := impl.userRepository.GetByIdIncludeDeleted(userId)
+	if err != nil {
+		// secrets are not written for unknown users
+		impl.logger.Errorw("error in fetching user for secret deny check", "err", err, "userId", userId)
+		return true
+	}
+	object := fmt.Sprintf("%s/%s", "*", "")
+	if envId > 0 {
+		object = impl.GetEnvRBACNameByAppId(appId, envId)
+	} else if application, err := impl.appRepo.FindById(appId); err == nil {
+		object = fmt.Sprintf("%s/%s", "*", strings.ToLower(application.AppName))
+	}
+	return impl.enforcer.IsDeniedByEmail(user.EmailId, casbin.ResourceSecret, action, object)
+}
+
+func (impl EnforcerUtilImpl) CheckAppEnvAuth(token string, appId int, envId int, action string) bool {
+	object := impl.GetAppRBACNameByAppId(appId)
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceApplications, action, object); !ok {
+		return false
+	}
+	object = impl.GetEnvRBACNameByAppId(appId, envId)
+	return impl.enforcer.Enforce(token, casbin.ResourceEnvironment, action, object)
+}
+
+func (impl EnforcerUtilImpl) CheckCdPipelineAuth(token string, pipelineId int, action string) (*pipelineConfig.Pipeline, error) {
+	cdPipeline, err := impl.pipelineRepository.FindById(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cd pipeline", "err", err, "pipelineId", pipelineId)
+		return nil, err
+	}
+	if ok := impl.CheckAppEnvAuth(token, cdPipeline.AppId, cdPipeline.EnvironmentId, action); !ok {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: "unauthorized user", UserMessage: "Unauthorized User"}
+	}
+	return cdPipeline, nil
+}